@@ -0,0 +1,174 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+// Package client is a Go client of the dcrdata HTTP API. The methods of Client
+// are generated from the OpenAPI document of the API, openapi.json, which is
+// also served by dcrdata at /api/openapi.json.
+package client
+
+//go:generate go run ./internal/gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxErrorSize is the most bytes of an error response body read into an
+// Error.
+const maxErrorSize = 1 << 12
+
+// Opts are the options of a Client.
+type Opts struct {
+	// HTTPClient is the client of the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// AdminPassword is the password of the admin API, for the operations
+	// that require it.
+	AdminPassword string
+	// APIKey is the API key sent with every request, if set, for the rate
+	// limit and quota of the key instead of the per-IP rate limit.
+	APIKey string
+}
+
+// Client is a client of the dcrdata API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	adminPass  string
+	apiKey     string
+}
+
+// New creates a Client of the API at baseURL, such as
+// https://dcrdata.decred.org/api.
+func New(baseURL string, opts *Opts) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	if opts != nil {
+		if opts.HTTPClient != nil {
+			c.httpClient = opts.HTTPClient
+		}
+		c.adminPass = opts.AdminPassword
+		c.apiKey = opts.APIKey
+	}
+	return c
+}
+
+// Error is the error of a request with a response status other than the
+// expected status of the operation.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("dcrdata API error %d: %s", e.StatusCode, e.Message)
+}
+
+// request describes a request of an operation.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	admin  bool
+	status int
+}
+
+// do makes the request, and returns the response body if the response status
+// is the expected status. The caller must close the body.
+func (c *Client) do(ctx context.Context, req *request) (io.ReadCloser, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		b, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if req.body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.admin {
+		httpReq.SetBasicAuth("admin", c.adminPass)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != req.status {
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+		return nil, &Error{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+	return resp.Body, nil
+}
+
+// doJSON makes the request and decodes the JSON response body into resp, if
+// resp is not nil.
+func (c *Client) doJSON(ctx context.Context, req *request, resp interface{}) error {
+	body, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(body).Decode(resp)
+}
+
+// doText makes the request and returns the text response body.
+func (c *Client) doText(ctx context.Context, req *request) (string, error) {
+	body, err := c.do(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	return string(b), err
+}
+
+// doInt makes the request and parses the text response body as an integer.
+func (c *Client) doInt(ctx context.Context, req *request) (int64, error) {
+	s, err := c.doText(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+}
+
+// pathInt formats an integer path parameter.
+func pathInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+// pathString escapes a string path parameter.
+func pathString(s string) string {
+	return url.PathEscape(s)
+}
@@ -0,0 +1,2470 @@
+// Code generated by go run ./internal/gen; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	dbtypes "github.com/decred/dcrdata/v6/db/dbtypes"
+	txhelpers "github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// Root calls GET /.
+// API running message.
+func (c *Client) Root(ctx context.Context) (string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/",
+		status: 200,
+	}
+	return c.doText(ctx, req)
+}
+
+// RichList calls GET /address/rich.
+// Addresses with the largest balances.
+func (c *Client) RichList(ctx context.Context) (*apitypes.RichList, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/rich",
+		status: 200,
+	}
+	var resp apitypes.RichList
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RichListCount calls GET /address/rich/{N}.
+// The N addresses with the largest balances.
+func (c *Client) RichListCount(ctx context.Context, n int64) (*apitypes.RichList, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/rich/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.RichList
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressTransactions calls GET /address/{address}.
+// Transactions of the address.
+func (c *Client) AddressTransactions(ctx context.Context, address string) (*apitypes.Address, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address),
+		status: 200,
+	}
+	var resp apitypes.Address
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressAmountFlow calls GET /address/{address}/amountflow/{chartgrouping}.
+// Amount flow chart of the address.
+func (c *Client) AddressAmountFlow(ctx context.Context, address string, chartgrouping string) (*dbtypes.ChartsData, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/amountflow/" + pathString(chartgrouping),
+		status: 200,
+	}
+	var resp dbtypes.ChartsData
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressTransactionsCount calls GET /address/{address}/count/{N}.
+// Transactions of the address, the N most recent.
+func (c *Client) AddressTransactionsCount(ctx context.Context, address string, n int64) (*apitypes.Address, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.Address
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressTransactionsRawCount calls GET /address/{address}/count/{N}/raw.
+// Verbose transactions of the address, the N most recent.
+func (c *Client) AddressTransactionsRawCount(ctx context.Context, address string, n int64) ([]apitypes.AddressTxRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/count/" + pathInt(n) + "/raw",
+		status: 200,
+	}
+	var resp []apitypes.AddressTxRaw
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressTransactionsCountSkip calls GET /address/{address}/count/{N}/skip/{M}.
+// Transactions of the address, the N most recent after skipping M.
+func (c *Client) AddressTransactionsCountSkip(ctx context.Context, address string, n int64, m int64) (*apitypes.Address, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.Address
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressTransactionsRawCountSkip calls GET /address/{address}/count/{N}/skip/{M}/raw.
+// Verbose transactions of the address, the N most recent after skipping M.
+func (c *Client) AddressTransactionsRawCountSkip(ctx context.Context, address string, n int64, m int64) ([]apitypes.AddressTxRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/count/" + pathInt(n) + "/skip/" + pathInt(m) + "/raw",
+		status: 200,
+	}
+	var resp []apitypes.AddressTxRaw
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressExists calls GET /address/{address}/exists.
+// Whether each address of the comma-separated list of up to 64 addresses has transactions.
+func (c *Client) AddressExists(ctx context.Context, address string) ([]bool, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/exists",
+		status: 200,
+	}
+	var resp []bool
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressProposalVotes calls GET /address/{address}/proposalvotes.
+// Most recent votes on the Politeia proposals of the tickets of the address.
+func (c *Client) AddressProposalVotes(ctx context.Context, address string) ([]dbtypes.ProposalTicketVote, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/proposalvotes",
+		status: 200,
+	}
+	var resp []dbtypes.ProposalTicketVote
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressTransactionsRaw calls GET /address/{address}/raw.
+// Verbose transactions of the address.
+func (c *Client) AddressTransactionsRaw(ctx context.Context, address string) ([]apitypes.AddressTxRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/raw",
+		status: 200,
+	}
+	var resp []apitypes.AddressTxRaw
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressTotals calls GET /address/{address}/totals.
+// Totals of the address.
+func (c *Client) AddressTotals(ctx context.Context, address string) (*apitypes.AddressTotals, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/totals",
+		status: 200,
+	}
+	var resp apitypes.AddressTotals
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressTxTypes calls GET /address/{address}/types/{chartgrouping}.
+// Transaction type chart of the address.
+func (c *Client) AddressTxTypes(ctx context.Context, address string, chartgrouping string) (*dbtypes.ChartsData, error) {
+	req := &request{
+		method: "GET",
+		path:   "/address/" + pathString(address) + "/types/" + pathString(chartgrouping),
+		status: 200,
+	}
+	var resp dbtypes.ChartsData
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Agenda calls GET /agenda/{agendaId}.
+// Vote charts of the agenda.
+func (c *Client) Agenda(ctx context.Context, agendaId string) (*apitypes.AgendaAPIResponse, error) {
+	req := &request{
+		method: "GET",
+		path:   "/agenda/" + pathString(agendaId),
+		status: 200,
+	}
+	var resp apitypes.AgendaAPIResponse
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Agendas calls GET /agendas.
+// Consensus agendas.
+func (c *Client) Agendas(ctx context.Context) ([]apitypes.AgendasInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/agendas",
+		status: 200,
+	}
+	var resp []apitypes.AgendasInfo
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// ApiKeysParams are the query parameters of ApiKeys.
+type ApiKeysParams struct {
+	// Month: month of the usage in YYYY-MM format, the current month by default.
+	Month string
+}
+
+// ApiKeys calls GET /apikeys.
+// API keys and their usage.
+// It requires the admin password.
+func (c *Client) ApiKeys(ctx context.Context, params *ApiKeysParams) (*apitypes.APIKeys, error) {
+	req := &request{
+		method: "GET",
+		path:   "/apikeys",
+		status: 200,
+		admin:  true,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Month != "" {
+			req.query.Set("month", params.Month)
+		}
+	}
+	var resp apitypes.APIKeys
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateAPIKey calls POST /apikeys.
+// Issue an API key.
+// It requires the admin password.
+func (c *Client) CreateAPIKey(ctx context.Context, body *apitypes.APIKeyRequest) (*dbtypes.APIKey, error) {
+	req := &request{
+		method: "POST",
+		path:   "/apikeys",
+		status: 201,
+		body:   body,
+		admin:  true,
+	}
+	var resp dbtypes.APIKey
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RevokeAPIKey calls DELETE /apikeys/{keyid}.
+// Revoke the API key.
+// It requires the admin password.
+func (c *Client) RevokeAPIKey(ctx context.Context, keyid string) error {
+	req := &request{
+		method: "DELETE",
+		path:   "/apikeys/" + pathString(keyid),
+		status: 204,
+		admin:  true,
+	}
+	return c.doJSON(ctx, req, nil)
+}
+
+// BestBlockSummaryParams are the query parameters of BestBlockSummary.
+type BestBlockSummaryParams struct {
+	// Txtotals: include the transaction totals.
+	Txtotals bool
+}
+
+// BestBlockSummary calls GET /block/best.
+// Summary of the block at the best height.
+func (c *Client) BestBlockSummary(ctx context.Context, params *BestBlockSummaryParams) (*apitypes.BlockDataBasic, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Txtotals {
+			req.query.Set("txtotals", "true")
+		}
+	}
+	var resp apitypes.BlockDataBasic
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockHash calls GET /block/best/hash.
+// Hash of the block at the best height.
+func (c *Client) BestBlockHash(ctx context.Context) (string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/hash",
+		status: 200,
+	}
+	return c.doText(ctx, req)
+}
+
+// BestBlockHeader calls GET /block/best/header.
+// Header of the block at the best height.
+func (c *Client) BestBlockHeader(ctx context.Context) (*chainjson.GetBlockHeaderVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/header",
+		status: 200,
+	}
+	var resp chainjson.GetBlockHeaderVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockHeaderRaw calls GET /block/best/header/raw.
+// Serialized header of the block at the best height.
+func (c *Client) BestBlockHeaderRaw(ctx context.Context) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/header/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockHeight calls GET /block/best/height.
+// Height of the block at the best height.
+func (c *Client) BestBlockHeight(ctx context.Context) (int64, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/height",
+		status: 200,
+	}
+	return c.doInt(ctx, req)
+}
+
+// BestBlockStakeInfo calls GET /block/best/pos.
+// Stake info of the block at the best height.
+func (c *Client) BestBlockStakeInfo(ctx context.Context) (*apitypes.StakeInfoExtended, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/pos",
+		status: 200,
+	}
+	var resp apitypes.StakeInfoExtended
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockRaw calls GET /block/best/raw.
+// Serialized block at the best height.
+func (c *Client) BestBlockRaw(ctx context.Context) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockSize calls GET /block/best/size.
+// Size of the block in bytes at the best height.
+func (c *Client) BestBlockSize(ctx context.Context) (int32, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/size",
+		status: 200,
+	}
+	var resp int32
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BestBlockSubsidies calls GET /block/best/subsidy.
+// Subsidies of the block at the best height.
+func (c *Client) BestBlockSubsidies(ctx context.Context) (*apitypes.BlockSubsidies, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/subsidy",
+		status: 200,
+	}
+	var resp apitypes.BlockSubsidies
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockTransactions calls GET /block/best/tx.
+// Transactions of the block at the best height.
+func (c *Client) BestBlockTransactions(ctx context.Context) (*apitypes.BlockTransactions, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/tx",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactions
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockTransactionCounts calls GET /block/best/tx/count.
+// Transaction counts of the block at the best height.
+func (c *Client) BestBlockTransactionCounts(ctx context.Context) (*apitypes.BlockTransactionCounts, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/tx/count",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactionCounts
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BestBlockVerbose calls GET /block/best/verbose.
+// Verbose block data of the node at the best height.
+func (c *Client) BestBlockVerbose(ctx context.Context) (*chainjson.GetBlockVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/best/verbose",
+		status: 200,
+	}
+	var resp chainjson.GetBlockVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockSummaryByHashParams are the query parameters of BlockSummaryByHash.
+type BlockSummaryByHashParams struct {
+	// Txtotals: include the transaction totals.
+	Txtotals bool
+}
+
+// BlockSummaryByHash calls GET /block/hash/{blockhash}.
+// Summary of the block with the hash.
+func (c *Client) BlockSummaryByHash(ctx context.Context, blockhash string, params *BlockSummaryByHashParams) (*apitypes.BlockDataBasic, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Txtotals {
+			req.query.Set("txtotals", "true")
+		}
+	}
+	var resp apitypes.BlockDataBasic
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockHeaderByHash calls GET /block/hash/{blockhash}/header.
+// Header of the block with the hash.
+func (c *Client) BlockHeaderByHash(ctx context.Context, blockhash string) (*chainjson.GetBlockHeaderVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/header",
+		status: 200,
+	}
+	var resp chainjson.GetBlockHeaderVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockHeaderRawByHash calls GET /block/hash/{blockhash}/header/raw.
+// Serialized header of the block with the hash.
+func (c *Client) BlockHeaderRawByHash(ctx context.Context, blockhash string) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/header/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockHeightByHash calls GET /block/hash/{blockhash}/height.
+// Height of the block with the hash.
+func (c *Client) BlockHeightByHash(ctx context.Context, blockhash string) (int64, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/height",
+		status: 200,
+	}
+	return c.doInt(ctx, req)
+}
+
+// BlockStakeInfoByHash calls GET /block/hash/{blockhash}/pos.
+// Stake info of the block with the hash.
+func (c *Client) BlockStakeInfoByHash(ctx context.Context, blockhash string) (*apitypes.StakeInfoExtended, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/pos",
+		status: 200,
+	}
+	var resp apitypes.StakeInfoExtended
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockRawByHash calls GET /block/hash/{blockhash}/raw.
+// Serialized block with the hash.
+func (c *Client) BlockRawByHash(ctx context.Context, blockhash string) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockSizeByHash calls GET /block/hash/{blockhash}/size.
+// Size of the block in bytes with the hash.
+func (c *Client) BlockSizeByHash(ctx context.Context, blockhash string) (int32, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/size",
+		status: 200,
+	}
+	var resp int32
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockSubsidiesByHash calls GET /block/hash/{blockhash}/subsidy.
+// Subsidies of the block with the hash.
+func (c *Client) BlockSubsidiesByHash(ctx context.Context, blockhash string) (*apitypes.BlockSubsidies, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/subsidy",
+		status: 200,
+	}
+	var resp apitypes.BlockSubsidies
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockTransactionsByHash calls GET /block/hash/{blockhash}/tx.
+// Transactions of the block with the hash.
+func (c *Client) BlockTransactionsByHash(ctx context.Context, blockhash string) (*apitypes.BlockTransactions, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/tx",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactions
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockTransactionCountsByHash calls GET /block/hash/{blockhash}/tx/count.
+// Transaction counts of the block with the hash.
+func (c *Client) BlockTransactionCountsByHash(ctx context.Context, blockhash string) (*apitypes.BlockTransactionCounts, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/tx/count",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactionCounts
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockVerboseByHash calls GET /block/hash/{blockhash}/verbose.
+// Verbose block data of the node with the hash.
+func (c *Client) BlockVerboseByHash(ctx context.Context, blockhash string) (*chainjson.GetBlockVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/hash/" + pathString(blockhash) + "/verbose",
+		status: 200,
+	}
+	var resp chainjson.GetBlockVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockRangeSummary calls GET /block/range/{idx0}/{idx}.
+// Summaries of the blocks in the height range.
+func (c *Client) BlockRangeSummary(ctx context.Context, idx0 int64, idx int64) ([]apitypes.BlockDataBasic, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/range/" + pathInt(idx0) + "/" + pathInt(idx),
+		status: 200,
+	}
+	var resp []apitypes.BlockDataBasic
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockRangeSize calls GET /block/range/{idx0}/{idx}/size.
+// Sizes of the blocks in the height range.
+func (c *Client) BlockRangeSize(ctx context.Context, idx0 int64, idx int64) ([]int32, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/range/" + pathInt(idx0) + "/" + pathInt(idx) + "/size",
+		status: 200,
+	}
+	var resp []int32
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockRangeSteppedSummary calls GET /block/range/{idx0}/{idx}/{step}.
+// Summaries of every step-th block in the height range.
+func (c *Client) BlockRangeSteppedSummary(ctx context.Context, idx0 int64, idx int64, step int64) ([]apitypes.BlockDataBasic, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/range/" + pathInt(idx0) + "/" + pathInt(idx) + "/" + pathInt(step),
+		status: 200,
+	}
+	var resp []apitypes.BlockDataBasic
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockRangeSteppedSize calls GET /block/range/{idx0}/{idx}/{step}/size.
+// Sizes of every step-th block in the height range.
+func (c *Client) BlockRangeSteppedSize(ctx context.Context, idx0 int64, idx int64, step int64) ([]int32, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/range/" + pathInt(idx0) + "/" + pathInt(idx) + "/" + pathInt(step) + "/size",
+		status: 200,
+	}
+	var resp []int32
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockSummaryParams are the query parameters of BlockSummary.
+type BlockSummaryParams struct {
+	// Txtotals: include the transaction totals.
+	Txtotals bool
+}
+
+// BlockSummary calls GET /block/{idx}.
+// Summary of the block at the height.
+func (c *Client) BlockSummary(ctx context.Context, idx int64, params *BlockSummaryParams) (*apitypes.BlockDataBasic, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Txtotals {
+			req.query.Set("txtotals", "true")
+		}
+	}
+	var resp apitypes.BlockDataBasic
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockHash calls GET /block/{idx}/hash.
+// Hash of the block at the height.
+func (c *Client) BlockHash(ctx context.Context, idx int64) (string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/hash",
+		status: 200,
+	}
+	return c.doText(ctx, req)
+}
+
+// BlockHeader calls GET /block/{idx}/header.
+// Header of the block at the height.
+func (c *Client) BlockHeader(ctx context.Context, idx int64) (*chainjson.GetBlockHeaderVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/header",
+		status: 200,
+	}
+	var resp chainjson.GetBlockHeaderVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockHeaderRaw calls GET /block/{idx}/header/raw.
+// Serialized header of the block at the height.
+func (c *Client) BlockHeaderRaw(ctx context.Context, idx int64) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/header/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockStakeInfo calls GET /block/{idx}/pos.
+// Stake info of the block at the height.
+func (c *Client) BlockStakeInfo(ctx context.Context, idx int64) (*apitypes.StakeInfoExtended, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/pos",
+		status: 200,
+	}
+	var resp apitypes.StakeInfoExtended
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockRaw calls GET /block/{idx}/raw.
+// Serialized block at the height.
+func (c *Client) BlockRaw(ctx context.Context, idx int64) (*apitypes.BlockRaw, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/raw",
+		status: 200,
+	}
+	var resp apitypes.BlockRaw
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockSize calls GET /block/{idx}/size.
+// Size of the block in bytes at the height.
+func (c *Client) BlockSize(ctx context.Context, idx int64) (int32, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/size",
+		status: 200,
+	}
+	var resp int32
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// BlockSubsidies calls GET /block/{idx}/subsidy.
+// Subsidies of the block at the height.
+func (c *Client) BlockSubsidies(ctx context.Context, idx int64) (*apitypes.BlockSubsidies, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/subsidy",
+		status: 200,
+	}
+	var resp apitypes.BlockSubsidies
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockTransactions calls GET /block/{idx}/tx.
+// Transactions of the block at the height.
+func (c *Client) BlockTransactions(ctx context.Context, idx int64) (*apitypes.BlockTransactions, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/tx",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactions
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockTransactionCounts calls GET /block/{idx}/tx/count.
+// Transaction counts of the block at the height.
+func (c *Client) BlockTransactionCounts(ctx context.Context, idx int64) (*apitypes.BlockTransactionCounts, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/tx/count",
+		status: 200,
+	}
+	var resp apitypes.BlockTransactionCounts
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockVerbose calls GET /block/{idx}/verbose.
+// Verbose block data of the node at the height.
+func (c *Client) BlockVerbose(ctx context.Context, idx int64) (*chainjson.GetBlockVerboseResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/block/" + pathInt(idx) + "/verbose",
+		status: 200,
+	}
+	var resp chainjson.GetBlockVerboseResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CandlestickChart calls GET /chart/market/{token}/candlestick/{bin}.
+// Candlestick chart of the exchange.
+func (c *Client) CandlestickChart(ctx context.Context, token string, bin string) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/chart/market/" + pathString(token) + "/candlestick/" + pathString(bin),
+		status: 200,
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// DepthChart calls GET /chart/market/{token}/depth.
+// Order book depth chart of the exchange.
+func (c *Client) DepthChart(ctx context.Context, token string) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/chart/market/" + pathString(token) + "/depth",
+		status: 200,
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// ChartParams are the query parameters of Chart.
+type ChartParams struct {
+	// Bin: time binning of the chart, one of block, day, week or month.
+	Bin string
+	// Zoom: deprecated name of bin.
+	Zoom string
+	// Axis: x axis of the chart, one of time or height.
+	Axis string
+}
+
+// Chart calls GET /chart/{charttype}.
+// Chart data.
+func (c *Client) Chart(ctx context.Context, charttype string, params *ChartParams) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/chart/" + pathString(charttype),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Bin != "" {
+			req.query.Set("bin", params.Bin)
+		}
+		if params.Zoom != "" {
+			req.query.Set("zoom", params.Zoom)
+		}
+		if params.Axis != "" {
+			req.query.Set("axis", params.Axis)
+		}
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// ExchangesParams are the query parameters of Exchanges.
+type ExchangesParams struct {
+	// Code: currency code of the converted prices.
+	Code string
+}
+
+// Exchanges calls GET /exchanges.
+// Exchange rates.
+func (c *Client) Exchanges(ctx context.Context, params *ExchangesParams) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/exchanges",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Code != "" {
+			req.query.Set("code", params.Code)
+		}
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// ExchangeCodes calls GET /exchanges/codes.
+// Available currency codes.
+func (c *Client) ExchangeCodes(ctx context.Context) ([]string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/exchanges/codes",
+		status: 200,
+	}
+	var resp []string
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// FeeEstimates calls GET /fees/estimate.
+// Fee rate estimates.
+func (c *Client) FeeEstimates(ctx context.Context) (*apitypes.FeeEstimates, error) {
+	req := &request{
+		method: "GET",
+		path:   "/fees/estimate",
+		status: 200,
+	}
+	var resp apitypes.FeeEstimates
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressLabelsParams are the query parameters of AddressLabels.
+type AddressLabelsParams struct {
+	// Category: return only the labels of the category.
+	Category string
+}
+
+// AddressLabels calls GET /labels.
+// Address labels.
+func (c *Client) AddressLabels(ctx context.Context, params *AddressLabelsParams) ([]dbtypes.AddressLabel, error) {
+	req := &request{
+		method: "GET",
+		path:   "/labels",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Category != "" {
+			req.query.Set("category", params.Category)
+		}
+	}
+	var resp []dbtypes.AddressLabel
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// DeleteAddressLabel calls DELETE /labels/{address}.
+// Delete the label of the address.
+// It requires the admin password.
+func (c *Client) DeleteAddressLabel(ctx context.Context, address string) error {
+	req := &request{
+		method: "DELETE",
+		path:   "/labels/" + pathString(address),
+		status: 204,
+		admin:  true,
+	}
+	return c.doJSON(ctx, req, nil)
+}
+
+// AddressLabel calls GET /labels/{address}.
+// Label of the address.
+func (c *Client) AddressLabel(ctx context.Context, address string) (*dbtypes.AddressLabel, error) {
+	req := &request{
+		method: "GET",
+		path:   "/labels/" + pathString(address),
+		status: 200,
+	}
+	var resp dbtypes.AddressLabel
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetAddressLabel calls PUT /labels/{address}.
+// Set the label of the address.
+// It requires the admin password.
+func (c *Client) SetAddressLabel(ctx context.Context, address string, body *apitypes.AddressLabelRequest) (*dbtypes.AddressLabel, error) {
+	req := &request{
+		method: "PUT",
+		path:   "/labels/" + pathString(address),
+		status: 200,
+		body:   body,
+		admin:  true,
+	}
+	var resp dbtypes.AddressLabel
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// List calls GET /list.
+// Route patterns of the API.
+func (c *Client) List(ctx context.Context) ([]string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/list",
+		status: 200,
+	}
+	var resp []string
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// MempoolOverview calls GET /mempool.
+// Overview of the mempool.
+func (c *Client) MempoolOverview(ctx context.Context) (*apitypes.MempoolOverview, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool",
+		status: 200,
+	}
+	var resp apitypes.MempoolOverview
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTicketFeeInfo calls GET /mempool/sstx.
+// Ticket fee summary of the mempool.
+func (c *Client) MempoolTicketFeeInfo(ctx context.Context) (*apitypes.MempoolTicketFeeInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/sstx",
+		status: 200,
+	}
+	var resp apitypes.MempoolTicketFeeInfo
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTicketDetails calls GET /mempool/sstx/details.
+// Ticket details of the mempool.
+func (c *Client) MempoolTicketDetails(ctx context.Context) (*apitypes.MempoolTicketDetails, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/sstx/details",
+		status: 200,
+	}
+	var resp apitypes.MempoolTicketDetails
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTicketDetailsCount calls GET /mempool/sstx/details/{N}.
+// Details of the N tickets with the largest fees.
+func (c *Client) MempoolTicketDetailsCount(ctx context.Context, n int64) (*apitypes.MempoolTicketDetails, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/sstx/details/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.MempoolTicketDetails
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTicketFees calls GET /mempool/sstx/fees.
+// Ticket fees of the mempool.
+func (c *Client) MempoolTicketFees(ctx context.Context) (*apitypes.MempoolTicketFees, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/sstx/fees",
+		status: 200,
+	}
+	var resp apitypes.MempoolTicketFees
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTicketFeesCount calls GET /mempool/sstx/fees/{N}.
+// The N largest ticket fees of the mempool.
+func (c *Client) MempoolTicketFeesCount(ctx context.Context, n int64) (*apitypes.MempoolTicketFees, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/sstx/fees/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.MempoolTicketFees
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTxs calls GET /mempool/txs/{txtype}.
+// Mempool transactions of the type.
+func (c *Client) MempoolTxs(ctx context.Context, txtype string) (*apitypes.MempoolTxs, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/txs/" + pathString(txtype),
+		status: 200,
+	}
+	var resp apitypes.MempoolTxs
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTxsCount calls GET /mempool/txs/{txtype}/count/{N}.
+// Mempool transactions of the type, the N most recent.
+func (c *Client) MempoolTxsCount(ctx context.Context, txtype string, n int64) (*apitypes.MempoolTxs, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/txs/" + pathString(txtype) + "/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.MempoolTxs
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MempoolTxsCountSkip calls GET /mempool/txs/{txtype}/count/{N}/skip/{M}.
+// Mempool transactions of the type, the N most recent after skipping M.
+func (c *Client) MempoolTxsCountSkip(ctx context.Context, txtype string, n int64, m int64) (*apitypes.MempoolTxs, error) {
+	req := &request{
+		method: "GET",
+		path:   "/mempool/txs/" + pathString(txtype) + "/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.MempoolTxs
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// OpenAPI calls GET /openapi.json.
+// OpenAPI document of the API.
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/openapi.json",
+		status: 200,
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// Proposal calls GET /proposal/{token}.
+// Vote charts of the proposal.
+func (c *Client) Proposal(ctx context.Context, token string) (*dbtypes.ProposalChartsData, error) {
+	req := &request{
+		method: "GET",
+		path:   "/proposal/" + pathString(token),
+		status: 200,
+	}
+	var resp dbtypes.ProposalChartsData
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProposalHistory calls GET /proposal/{token}/history.
+// Versions, status changes and hourly vote tallies of the proposal.
+func (c *Client) ProposalHistory(ctx context.Context, token string) (*dbtypes.ProposalHistory, error) {
+	req := &request{
+		method: "GET",
+		path:   "/proposal/" + pathString(token) + "/history",
+		status: 200,
+	}
+	var resp dbtypes.ProposalHistory
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProposalVoters calls GET /proposal/{token}/voters.
+// Participation in the vote on the proposal, and the tickets that voted.
+func (c *Client) ProposalVoters(ctx context.Context, token string) (*dbtypes.ProposalVoters, error) {
+	req := &request{
+		method: "GET",
+		path:   "/proposal/" + pathString(token) + "/voters",
+		status: 200,
+	}
+	var resp dbtypes.ProposalVoters
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProposalVotersCount calls GET /proposal/{token}/voters/count/{N}.
+// Participation in the vote on the proposal, and the tickets that voted, the N most recent.
+func (c *Client) ProposalVotersCount(ctx context.Context, token string, n int64) (*dbtypes.ProposalVoters, error) {
+	req := &request{
+		method: "GET",
+		path:   "/proposal/" + pathString(token) + "/voters/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp dbtypes.ProposalVoters
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProposalVotersCountSkip calls GET /proposal/{token}/voters/count/{N}/skip/{M}.
+// Participation in the vote on the proposal, and the tickets that voted, the N most recent after skipping M.
+func (c *Client) ProposalVotersCountSkip(ctx context.Context, token string, n int64, m int64) (*dbtypes.ProposalVoters, error) {
+	req := &request{
+		method: "GET",
+		path:   "/proposal/" + pathString(token) + "/voters/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp dbtypes.ProposalVoters
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StakeDiffSummary calls GET /stake/diff.
+// Current and estimated stake difficulty.
+func (c *Client) StakeDiffSummary(ctx context.Context) (*apitypes.StakeDiff, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/diff",
+		status: 200,
+	}
+	var resp apitypes.StakeDiff
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StakeDiff calls GET /stake/diff/b/{idx}.
+// Stake difficulty at the height.
+func (c *Client) StakeDiff(ctx context.Context, idx int64) ([]float64, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/diff/b/" + pathInt(idx),
+		status: 200,
+	}
+	var resp []float64
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// StakeDiffCurrent calls GET /stake/diff/current.
+// Current stake difficulty.
+func (c *Client) StakeDiffCurrent(ctx context.Context) (*chainjson.GetStakeDifficultyResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/diff/current",
+		status: 200,
+	}
+	var resp chainjson.GetStakeDifficultyResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StakeDiffEstimates calls GET /stake/diff/estimates.
+// Stake difficulty estimates.
+func (c *Client) StakeDiffEstimates(ctx context.Context) (*chainjson.EstimateStakeDiffResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/diff/estimates",
+		status: 200,
+	}
+	var resp chainjson.EstimateStakeDiffResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StakeDiffRange calls GET /stake/diff/r/{idx0}/{idx}.
+// Stake difficulty in the height range.
+func (c *Client) StakeDiffRange(ctx context.Context, idx0 int64, idx int64) ([]float64, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/diff/r/" + pathInt(idx0) + "/" + pathInt(idx),
+		status: 200,
+	}
+	var resp []float64
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AddressMisses calls GET /stake/misses/address/{address}.
+// Most recent missed votes of the tickets of the address with their cause.
+func (c *Client) AddressMisses(ctx context.Context, address string) (*dbtypes.MissReport, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/misses/address/" + pathString(address),
+		status: 200,
+	}
+	var resp dbtypes.MissReport
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BlockMisses calls GET /stake/misses/b/{idxorhash}.
+// Missed votes of the block with their cause.
+func (c *Client) BlockMisses(ctx context.Context, idxorhash string) (*dbtypes.MissReport, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/misses/b/" + pathString(idxorhash),
+		status: 200,
+	}
+	var resp dbtypes.MissReport
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolInfo calls GET /stake/pool.
+// Ticket pool info at the best height.
+func (c *Client) TicketPoolInfo(ctx context.Context) (*apitypes.TicketPoolInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/pool",
+		status: 200,
+	}
+	var resp apitypes.TicketPoolInfo
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolAtParams are the query parameters of TicketPoolAt.
+type TicketPoolAtParams struct {
+	// Sort: sort the tickets.
+	Sort bool
+}
+
+// TicketPoolAt calls GET /stake/pool/b/{idxorhash}/full.
+// Tickets in the pool at the block.
+func (c *Client) TicketPoolAt(ctx context.Context, idxorhash string, params *TicketPoolAtParams) ([]string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/pool/b/" + pathString(idxorhash) + "/full",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Sort {
+			req.query.Set("sort", "true")
+		}
+	}
+	var resp []string
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TicketPoolInfoAt calls GET /stake/pool/b/{idx}.
+// Ticket pool info at the height.
+func (c *Client) TicketPoolInfoAt(ctx context.Context, idx int64) (*apitypes.TicketPoolInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/pool/b/" + pathInt(idx),
+		status: 200,
+	}
+	var resp apitypes.TicketPoolInfo
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolParams are the query parameters of TicketPool.
+type TicketPoolParams struct {
+	// Sort: sort the tickets.
+	Sort bool
+}
+
+// TicketPool calls GET /stake/pool/full.
+// Tickets in the pool at the best height.
+func (c *Client) TicketPool(ctx context.Context, params *TicketPoolParams) ([]string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/pool/full",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Sort {
+			req.query.Set("sort", "true")
+		}
+	}
+	var resp []string
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TicketPoolInfoRangeParams are the query parameters of TicketPoolInfoRange.
+type TicketPoolInfoRangeParams struct {
+	// Arrays: return arrays of the pool values and sizes.
+	Arrays bool
+}
+
+// TicketPoolInfoRange calls GET /stake/pool/r/{idx0}/{idx}.
+// Ticket pool info in the height range, as arrays with arrays=true.
+func (c *Client) TicketPoolInfoRange(ctx context.Context, idx0 int64, idx int64, params *TicketPoolInfoRangeParams) (json.RawMessage, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/pool/r/" + pathInt(idx0) + "/" + pathInt(idx),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Arrays {
+			req.query.Set("arrays", "true")
+		}
+	}
+	var resp json.RawMessage
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// PowerlessTickets calls GET /stake/powerless.
+// Missed and expired tickets that have not been revoked.
+func (c *Client) PowerlessTickets(ctx context.Context) (*apitypes.PowerlessTickets, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/powerless",
+		status: 200,
+	}
+	var resp apitypes.PowerlessTickets
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StakeSimulationParams are the query parameters of StakeSimulation.
+type StakeSimulationParams struct {
+	// Amount: amount of DCR to stake.
+	Amount float64
+	// Start: start date in YYYY-MM-DD format, one year ago by default.
+	Start string
+	// End: end date in YYYY-MM-DD format, one year after the start by default.
+	End string
+	// Reinvest: buy tickets with the rewards.
+	Reinvest bool
+}
+
+// StakeSimulation calls GET /stake/simulate.
+// Simulated staking returns of an amount over history and projected.
+func (c *Client) StakeSimulation(ctx context.Context, params *StakeSimulationParams) (*apitypes.StakeSimulation, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/simulate",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Amount != 0 {
+			req.query.Set("amount", strconv.FormatFloat(params.Amount, 'f', -1, 64))
+		}
+		if params.Start != "" {
+			req.query.Set("start", params.Start)
+		}
+		if params.End != "" {
+			req.query.Set("end", params.End)
+		}
+		if params.Reinvest {
+			req.query.Set("reinvest", "true")
+		}
+	}
+	var resp apitypes.StakeSimulation
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RegisterTicketSet calls POST /stake/ticketsets.
+// Register a ticket set for the tickets pubsub event.
+func (c *Client) RegisterTicketSet(ctx context.Context, body *apitypes.Txns) (*apitypes.TicketSet, error) {
+	req := &request{
+		method: "POST",
+		path:   "/stake/ticketsets",
+		status: 200,
+		body:   body,
+	}
+	var resp apitypes.TicketSet
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketSet calls GET /stake/ticketsets/{setid}.
+// Status of the tickets of the ticket set.
+func (c *Client) TicketSet(ctx context.Context, setid string) (*apitypes.TicketSet, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/ticketsets/" + pathString(setid),
+		status: 200,
+	}
+	var resp apitypes.TicketSet
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VoteInfoParams are the query parameters of VoteInfo.
+type VoteInfoParams struct {
+	// Version: stake version, the latest by default.
+	Version int64
+}
+
+// VoteInfo calls GET /stake/vote/info.
+// Vote info of the stake version.
+func (c *Client) VoteInfo(ctx context.Context, params *VoteInfoParams) (*chainjson.GetVoteInfoResult, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/vote/info",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Version != 0 {
+			req.query.Set("version", strconv.FormatInt(params.Version, 10))
+		}
+	}
+	var resp chainjson.GetVoteInfoResult
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VspStats calls GET /stake/vsp.
+// Ticket statistics of the VSPs.
+func (c *Client) VspStats(ctx context.Context) (*dbtypes.VSPAnalytics, error) {
+	req := &request{
+		method: "GET",
+		path:   "/stake/vsp",
+		status: 200,
+	}
+	var resp dbtypes.VSPAnalytics
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Status calls GET /status.
+// Status of the node and dcrdata.
+func (c *Client) Status(ctx context.Context) (*apitypes.APIStatus, error) {
+	req := &request{
+		method: "GET",
+		path:   "/status",
+		status: 200,
+	}
+	var resp apitypes.APIStatus
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StatusHappy calls GET /status/happy.
+// Health check of dcrdata, with status 503 if unhappy.
+func (c *Client) StatusHappy(ctx context.Context) (*apitypes.Happy, error) {
+	req := &request{
+		method: "GET",
+		path:   "/status/happy",
+		status: 200,
+	}
+	var resp apitypes.Happy
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CoinSupply calls GET /supply.
+// Current coin supply.
+func (c *Client) CoinSupply(ctx context.Context) (*apitypes.CoinSupply, error) {
+	req := &request{
+		method: "GET",
+		path:   "/supply",
+		status: 200,
+	}
+	var resp apitypes.CoinSupply
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CoinSupplyCirculatingParams are the query parameters of CoinSupplyCirculating.
+type CoinSupplyCirculatingParams struct {
+	// Dcr: return the supply in DCR.
+	Dcr bool
+}
+
+// CoinSupplyCirculating calls GET /supply/circulating.
+// Circulating coin supply in atoms, or in DCR with dcr=true.
+func (c *Client) CoinSupplyCirculating(ctx context.Context, params *CoinSupplyCirculatingParams) (float64, error) {
+	req := &request{
+		method: "GET",
+		path:   "/supply/circulating",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Dcr {
+			req.query.Set("dcr", "true")
+		}
+	}
+	var resp float64
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// AtomicSwaps calls GET /swaps.
+// Atomic swaps.
+func (c *Client) AtomicSwaps(ctx context.Context) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps",
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressAtomicSwaps calls GET /swaps/address/{address}.
+// Atomic swaps of the address.
+func (c *Client) AddressAtomicSwaps(ctx context.Context, address string) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/address/" + pathString(address),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressAtomicSwapsCount calls GET /swaps/address/{address}/count/{N}.
+// Atomic swaps of the address, the N most recent.
+func (c *Client) AddressAtomicSwapsCount(ctx context.Context, address string, n int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/address/" + pathString(address) + "/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AddressAtomicSwapsCountSkip calls GET /swaps/address/{address}/count/{N}/skip/{M}.
+// Atomic swaps of the address, the N most recent after skipping M.
+func (c *Client) AddressAtomicSwapsCountSkip(ctx context.Context, address string, n int64, m int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/address/" + pathString(address) + "/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsCount calls GET /swaps/count/{N}.
+// Atomic swaps, the N most recent.
+func (c *Client) AtomicSwapsCount(ctx context.Context, n int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsCountSkip calls GET /swaps/count/{N}/skip/{M}.
+// Atomic swaps, the N most recent after skipping M.
+func (c *Client) AtomicSwapsCountSkip(ctx context.Context, n int64, m int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsBySecretHash calls GET /swaps/secret/{secrethash}.
+// Atomic swaps of the secret hash.
+func (c *Client) AtomicSwapsBySecretHash(ctx context.Context, secrethash string) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/secret/" + pathString(secrethash),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsInTimeRange calls GET /swaps/time/{start}/{end}.
+// Atomic swaps in the time range.
+func (c *Client) AtomicSwapsInTimeRange(ctx context.Context, start int64, end int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/time/" + pathInt(start) + "/" + pathInt(end),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsInTimeRangeCount calls GET /swaps/time/{start}/{end}/count/{N}.
+// Atomic swaps in the time range, the N most recent.
+func (c *Client) AtomicSwapsInTimeRangeCount(ctx context.Context, start int64, end int64, n int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/time/" + pathInt(start) + "/" + pathInt(end) + "/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// AtomicSwapsInTimeRangeCountSkip calls GET /swaps/time/{start}/{end}/count/{N}/skip/{M}.
+// Atomic swaps in the time range, the N most recent after skipping M.
+func (c *Client) AtomicSwapsInTimeRangeCountSkip(ctx context.Context, start int64, end int64, n int64, m int64) (*apitypes.AtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/swaps/time/" + pathInt(start) + "/" + pathInt(end) + "/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.AtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolByDate calls GET /ticketpool.
+// Purchase time distribution of the ticket pool, by day.
+func (c *Client) TicketPoolByDate(ctx context.Context) (*apitypes.TicketPoolByDate, error) {
+	req := &request{
+		method: "GET",
+		path:   "/ticketpool",
+		status: 200,
+	}
+	var resp apitypes.TicketPoolByDate
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolByDateGrouped calls GET /ticketpool/bydate/{tp}.
+// Purchase time distribution of the ticket pool.
+func (c *Client) TicketPoolByDateGrouped(ctx context.Context, tp string) (*apitypes.TicketPoolByDate, error) {
+	req := &request{
+		method: "GET",
+		path:   "/ticketpool/bydate/" + pathString(tp),
+		status: 200,
+	}
+	var resp apitypes.TicketPoolByDate
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TicketPoolCharts calls GET /ticketpool/charts.
+// Ticket pool charts.
+func (c *Client) TicketPoolCharts(ctx context.Context) (*apitypes.TicketPoolChartsData, error) {
+	req := &request{
+		method: "GET",
+		path:   "/ticketpool/charts",
+		status: 200,
+	}
+	var resp apitypes.TicketPoolChartsData
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TreasuryIO calls GET /treasury/io/{chartgrouping}.
+// Treasury inflow and outflow chart.
+func (c *Client) TreasuryIO(ctx context.Context, chartgrouping string) (*dbtypes.ChartsData, error) {
+	req := &request{
+		method: "GET",
+		path:   "/treasury/io/" + pathString(chartgrouping),
+		status: 200,
+	}
+	var resp dbtypes.ChartsData
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TreasurySpendReportParams are the query parameters of TreasurySpendReport.
+type TreasurySpendReportParams struct {
+	// Grouping: period of the totals, month (default) or quarter.
+	Grouping string
+}
+
+// TreasurySpendReport calls GET /treasury/spends.
+// Treasury spends by payee address and proposal per month or quarter.
+func (c *Client) TreasurySpendReport(ctx context.Context, params *TreasurySpendReportParams) (*dbtypes.TreasurySpendReport, error) {
+	req := &request{
+		method: "GET",
+		path:   "/treasury/spends",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Grouping != "" {
+			req.query.Set("grouping", params.Grouping)
+		}
+	}
+	var resp dbtypes.TreasurySpendReport
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TreasurySpends calls GET /treasury/tspends.
+// Progress of the votes on the recent treasury spends.
+func (c *Client) TreasurySpends(ctx context.Context) (*apitypes.TSpends, error) {
+	req := &request{
+		method: "GET",
+		path:   "/treasury/tspends",
+		status: 200,
+	}
+	var resp apitypes.TSpends
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// BroadcastTransaction calls POST /tx/broadcast.
+// Validate and broadcast a transaction.
+func (c *Client) BroadcastTransaction(ctx context.Context, body *apitypes.TxBroadcastRequest) (*apitypes.TxBroadcast, error) {
+	req := &request{
+		method: "POST",
+		path:   "/tx/broadcast",
+		status: 200,
+		body:   body,
+	}
+	var resp apitypes.TxBroadcast
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionBroadcast calls GET /tx/broadcast/{txid}.
+// Status of a broadcast transaction.
+func (c *Client) TransactionBroadcast(ctx context.Context, txid string) (*apitypes.TxBroadcast, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/broadcast/" + pathString(txid),
+		status: 200,
+	}
+	var resp apitypes.TxBroadcast
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionDecodedParams are the query parameters of TransactionDecoded.
+type TransactionDecodedParams struct {
+	// Spends: include the spending transaction of each output.
+	Spends bool
+}
+
+// TransactionDecoded calls GET /tx/decoded/{txid}.
+// Decoded transaction.
+func (c *Client) TransactionDecoded(ctx context.Context, txid string, params *TransactionDecodedParams) (*apitypes.TrimmedTx, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/decoded/" + pathString(txid),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Spends {
+			req.query.Set("spends", "true")
+		}
+	}
+	var resp apitypes.TrimmedTx
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionHex calls GET /tx/hex/{txid}.
+// Serialized transaction.
+func (c *Client) TransactionHex(ctx context.Context, txid string) (string, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/hex/" + pathString(txid),
+		status: 200,
+	}
+	return c.doText(ctx, req)
+}
+
+// TransactionSwaps calls GET /tx/swaps/{txid}.
+// Atomic swaps of the transaction.
+func (c *Client) TransactionSwaps(ctx context.Context, txid string) (*txhelpers.TxAtomicSwaps, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/swaps/" + pathString(txid),
+		status: 200,
+	}
+	var resp txhelpers.TxAtomicSwaps
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionParams are the query parameters of Transaction.
+type TransactionParams struct {
+	// Spends: include the spending transaction of each output.
+	Spends bool
+}
+
+// Transaction calls GET /tx/{txid}.
+// Transaction.
+func (c *Client) Transaction(ctx context.Context, txid string, params *TransactionParams) (*apitypes.Tx, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Spends {
+			req.query.Set("spends", "true")
+		}
+	}
+	var resp apitypes.Tx
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionInputs calls GET /tx/{txid}/in.
+// Inputs of the transaction.
+func (c *Client) TransactionInputs(ctx context.Context, txid string) ([]apitypes.TxIn, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/in",
+		status: 200,
+	}
+	var resp []apitypes.TxIn
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TransactionInput calls GET /tx/{txid}/in/{txinoutindex}.
+// Input of the transaction.
+func (c *Client) TransactionInput(ctx context.Context, txid string, txinoutindex int64) (*apitypes.TxIn, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/in/" + pathInt(txinoutindex),
+		status: 200,
+	}
+	var resp apitypes.TxIn
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionOutputs calls GET /tx/{txid}/out.
+// Outputs of the transaction.
+func (c *Client) TransactionOutputs(ctx context.Context, txid string) ([]apitypes.TxOut, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/out",
+		status: 200,
+	}
+	var resp []apitypes.TxOut
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TransactionOutput calls GET /tx/{txid}/out/{txinoutindex}.
+// Output of the transaction.
+func (c *Client) TransactionOutput(ctx context.Context, txid string, txinoutindex int64) (*apitypes.TxOut, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/out/" + pathInt(txinoutindex),
+		status: 200,
+	}
+	var resp apitypes.TxOut
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionProposalVotes calls GET /tx/{txid}/proposalvotes.
+// Votes of the ticket on the Politeia proposals.
+func (c *Client) TransactionProposalVotes(ctx context.Context, txid string) ([]dbtypes.ProposalTicketVote, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/proposalvotes",
+		status: 200,
+	}
+	var resp []dbtypes.ProposalTicketVote
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TransactionTicketInfo calls GET /tx/{txid}/tinfo.
+// Ticket info of the ticket transaction.
+func (c *Client) TransactionTicketInfo(ctx context.Context, txid string) (*apitypes.TicketInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/tinfo",
+		status: 200,
+	}
+	var resp apitypes.TicketInfo
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionTrimmedParams are the query parameters of TransactionTrimmed.
+type TransactionTrimmedParams struct {
+	// Spends: include the spending transaction of each output.
+	Spends bool
+}
+
+// TransactionTrimmed calls GET /tx/{txid}/trimmed.
+// Trimmed transaction.
+func (c *Client) TransactionTrimmed(ctx context.Context, txid string, params *TransactionTrimmedParams) (*apitypes.TrimmedTx, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/trimmed",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Spends {
+			req.query.Set("spends", "true")
+		}
+	}
+	var resp apitypes.TrimmedTx
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionVoteInfo calls GET /tx/{txid}/vinfo.
+// Vote info of the vote transaction.
+func (c *Client) TransactionVoteInfo(ctx context.Context, txid string) (*apitypes.VoteInfo, error) {
+	req := &request{
+		method: "GET",
+		path:   "/tx/" + pathString(txid) + "/vinfo",
+		status: 200,
+	}
+	var resp apitypes.VoteInfo
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// TransactionsParams are the query parameters of Transactions.
+type TransactionsParams struct {
+	// Spends: include the spending transaction of each output.
+	Spends bool
+}
+
+// Transactions calls POST /txs.
+// Transactions.
+func (c *Client) Transactions(ctx context.Context, body *apitypes.Txns, params *TransactionsParams) ([]apitypes.Tx, error) {
+	req := &request{
+		method: "POST",
+		path:   "/txs",
+		status: 200,
+		body:   body,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Spends {
+			req.query.Set("spends", "true")
+		}
+	}
+	var resp []apitypes.Tx
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TransactionsTicketInfo calls POST /txs/tinfo.
+// Ticket info of the ticket transactions.
+func (c *Client) TransactionsTicketInfo(ctx context.Context, body *apitypes.Txns) (map[string]apitypes.TicketInfo, error) {
+	req := &request{
+		method: "POST",
+		path:   "/txs/tinfo",
+		status: 200,
+		body:   body,
+	}
+	var resp map[string]apitypes.TicketInfo
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// TransactionsTrimmed calls POST /txs/trimmed.
+// Trimmed transactions.
+func (c *Client) TransactionsTrimmed(ctx context.Context, body *apitypes.Txns) ([]apitypes.TrimmedTx, error) {
+	req := &request{
+		method: "POST",
+		path:   "/txs/trimmed",
+		status: 200,
+		body:   body,
+	}
+	var resp []apitypes.TrimmedTx
+	err := c.doJSON(ctx, req, &resp)
+	return resp, err
+}
+
+// CreateWebhook calls POST /webhooks.
+// Register a webhook.
+func (c *Client) CreateWebhook(ctx context.Context, body *apitypes.WebhookRequest) (*dbtypes.Webhook, error) {
+	req := &request{
+		method: "POST",
+		path:   "/webhooks",
+		status: 201,
+		body:   body,
+	}
+	var resp dbtypes.Webhook
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteWebhook calls DELETE /webhooks/{webhookid}.
+// Delete the webhook.
+func (c *Client) DeleteWebhook(ctx context.Context, webhookid string) error {
+	req := &request{
+		method: "DELETE",
+		path:   "/webhooks/" + pathString(webhookid),
+		status: 204,
+	}
+	return c.doJSON(ctx, req, nil)
+}
+
+// Webhook calls GET /webhooks/{webhookid}.
+// Webhook.
+func (c *Client) Webhook(ctx context.Context, webhookid string) (*dbtypes.Webhook, error) {
+	req := &request{
+		method: "GET",
+		path:   "/webhooks/" + pathString(webhookid),
+		status: 200,
+	}
+	var resp dbtypes.Webhook
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WebhookDeliveries calls GET /webhooks/{webhookid}/deliveries.
+// Deliveries of the webhook.
+func (c *Client) WebhookDeliveries(ctx context.Context, webhookid string) (*apitypes.WebhookDeliveries, error) {
+	req := &request{
+		method: "GET",
+		path:   "/webhooks/" + pathString(webhookid) + "/deliveries",
+		status: 200,
+	}
+	var resp apitypes.WebhookDeliveries
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WebhookDeliveriesCount calls GET /webhooks/{webhookid}/deliveries/count/{N}.
+// Deliveries of the webhook, the N most recent.
+func (c *Client) WebhookDeliveriesCount(ctx context.Context, webhookid string, n int64) (*apitypes.WebhookDeliveries, error) {
+	req := &request{
+		method: "GET",
+		path:   "/webhooks/" + pathString(webhookid) + "/deliveries/count/" + pathInt(n),
+		status: 200,
+	}
+	var resp apitypes.WebhookDeliveries
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WebhookDeliveriesCountSkip calls GET /webhooks/{webhookid}/deliveries/count/{N}/skip/{M}.
+// Deliveries of the webhook, the N most recent after skipping M.
+func (c *Client) WebhookDeliveriesCountSkip(ctx context.Context, webhookid string, n int64, m int64) (*apitypes.WebhookDeliveries, error) {
+	req := &request{
+		method: "GET",
+		path:   "/webhooks/" + pathString(webhookid) + "/deliveries/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	var resp apitypes.WebhookDeliveries
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ReplayWebhookParams are the query parameters of ReplayWebhook.
+type ReplayWebhookParams struct {
+	// Since: UNIX time of the first delivery to replay.
+	Since int64
+}
+
+// ReplayWebhook calls POST /webhooks/{webhookid}/replay.
+// Replay the deliveries of the webhook.
+func (c *Client) ReplayWebhook(ctx context.Context, webhookid string, params *ReplayWebhookParams) (*apitypes.WebhookReplay, error) {
+	req := &request{
+		method: "POST",
+		path:   "/webhooks/" + pathString(webhookid) + "/replay",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Since != 0 {
+			req.query.Set("since", strconv.FormatInt(params.Since, 10))
+		}
+	}
+	var resp apitypes.WebhookReplay
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// XpubBalance calls GET /xpub/{xpub}.
+// Balance of the account.
+func (c *Client) XpubBalance(ctx context.Context, xpub string) (*apitypes.XpubBalance, error) {
+	req := &request{
+		method: "GET",
+		path:   "/xpub/" + pathString(xpub),
+		status: 200,
+	}
+	var resp apitypes.XpubBalance
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// XpubHistoryParams are the query parameters of XpubHistory.
+type XpubHistoryParams struct {
+	// Gap: address gap limit.
+	Gap int64
+}
+
+// XpubHistory calls GET /xpub/{xpub}/txs.
+// Transactions of the account.
+func (c *Client) XpubHistory(ctx context.Context, xpub string, params *XpubHistoryParams) (*apitypes.XpubHistory, error) {
+	req := &request{
+		method: "GET",
+		path:   "/xpub/" + pathString(xpub) + "/txs",
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Gap != 0 {
+			req.query.Set("gap", strconv.FormatInt(params.Gap, 10))
+		}
+	}
+	var resp apitypes.XpubHistory
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// XpubHistoryCountParams are the query parameters of XpubHistoryCount.
+type XpubHistoryCountParams struct {
+	// Gap: address gap limit.
+	Gap int64
+}
+
+// XpubHistoryCount calls GET /xpub/{xpub}/txs/count/{N}.
+// Transactions of the account, the N most recent.
+func (c *Client) XpubHistoryCount(ctx context.Context, xpub string, n int64, params *XpubHistoryCountParams) (*apitypes.XpubHistory, error) {
+	req := &request{
+		method: "GET",
+		path:   "/xpub/" + pathString(xpub) + "/txs/count/" + pathInt(n),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Gap != 0 {
+			req.query.Set("gap", strconv.FormatInt(params.Gap, 10))
+		}
+	}
+	var resp apitypes.XpubHistory
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// XpubHistoryCountSkipParams are the query parameters of XpubHistoryCountSkip.
+type XpubHistoryCountSkipParams struct {
+	// Gap: address gap limit.
+	Gap int64
+}
+
+// XpubHistoryCountSkip calls GET /xpub/{xpub}/txs/count/{N}/skip/{M}.
+// Transactions of the account, the N most recent after skipping M.
+func (c *Client) XpubHistoryCountSkip(ctx context.Context, xpub string, n int64, m int64, params *XpubHistoryCountSkipParams) (*apitypes.XpubHistory, error) {
+	req := &request{
+		method: "GET",
+		path:   "/xpub/" + pathString(xpub) + "/txs/count/" + pathInt(n) + "/skip/" + pathInt(m),
+		status: 200,
+	}
+	if params != nil {
+		req.query = make(url.Values)
+		if params.Gap != 0 {
+			req.query.Set("gap", strconv.FormatInt(params.Gap, 10))
+		}
+	}
+	var resp apitypes.XpubHistory
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// XpubUTXOs calls GET /xpub/{xpub}/utxos.
+// Unspent outputs of the account.
+func (c *Client) XpubUTXOs(ctx context.Context, xpub string) (*apitypes.XpubUTXOs, error) {
+	req := &request{
+		method: "GET",
+		path:   "/xpub/" + pathString(xpub) + "/utxos",
+		status: 200,
+	}
+	var resp apitypes.XpubUTXOs
+	if err := c.doJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
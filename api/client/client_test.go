@@ -0,0 +1,145 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL+"/api/", &Opts{AdminPassword: "pass"})
+}
+
+func TestClientJSON(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/block/100" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("txtotals") != "true" {
+			t.Errorf("txtotals query not set: %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(&apitypes.BlockDataBasic{Height: 100, Hash: "abcd"})
+	})
+
+	block, err := c.BlockSummary(context.Background(), 100, &BlockSummaryParams{Txtotals: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != 100 || block.Hash != "abcd" {
+		t.Errorf("unexpected block %+v", block)
+	}
+
+	_, err = c.BlockSummaryByHash(context.Background(), "abcd", nil)
+	apiErr, ok := err.(*Error)
+	if !ok || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestClientText(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/block/best/height":
+			w.Write([]byte("12345"))
+		case "/api/block/12345/hash":
+			w.Write([]byte("abcd"))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	height, err := c.BestBlockHeight(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 12345 {
+		t.Errorf("height %d, expected 12345", height)
+	}
+	hash, err := c.BlockHash(context.Background(), height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "abcd" {
+		t.Errorf("hash %s, expected abcd", hash)
+	}
+}
+
+func TestClientBodyAndAuth(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/labels/Dsabc" {
+			http.NotFound(w, r)
+			return
+		}
+		if _, pass, ok := r.BasicAuth(); !ok || pass != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var req apitypes.AddressLabelRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"address": "Dsabc",
+				"label":   req.Label,
+			})
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	label, err := c.SetAddressLabel(context.Background(), "Dsabc",
+		&apitypes.AddressLabelRequest{Label: "treasury"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label.Address != "Dsabc" || label.Label != "treasury" {
+		t.Errorf("unexpected label %+v", label)
+	}
+	if err = c.DeleteAddressLabel(context.Background(), "Dsabc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClientErrorMessage(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Exchange monitoring disabled.", http.StatusServiceUnavailable)
+	})
+	_, err := c.Exchanges(context.Background(), nil)
+	apiErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected an *Error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Message != "Exchange monitoring disabled." {
+		t.Errorf("unexpected error %v", apiErr)
+	}
+}
+
+func TestClientAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("12345"))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/api/", &Opts{APIKey: "secret"})
+	if _, err := c.BestBlockHeight(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+// gen generates the methods of the API client from the OpenAPI document of
+// the API. Run it in the client package directory with go generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	specFile = "openapi.json"
+	outFile  = "client_gen.go"
+)
+
+type spec struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]*mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]*struct {
+		Content map[string]*mediaType `json:"content"`
+	} `json:"responses"`
+	Security []map[string][]string `json:"security"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Schema      *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string    `json:"$ref"`
+	Type                 string    `json:"type"`
+	Format               string    `json:"format"`
+	Items                *schema   `json:"items"`
+	AdditionalProperties *schema   `json:"additionalProperties"`
+	OneOf                []*schema `json:"oneOf"`
+	GoType               string    `json:"x-go-type"`
+}
+
+// packages are the import names of the packages of the types the client may
+// use. The responses of other types are returned as json.RawMessage.
+var packages = map[string]string{
+	"github.com/decred/dcrdata/v6/api/types":      "apitypes",
+	"github.com/decred/dcrdata/v6/db/dbtypes":     "dbtypes",
+	"github.com/decred/dcrdata/v6/txhelpers":      "txhelpers",
+	"github.com/decred/dcrd/rpc/jsonrpc/types/v2": "chainjson",
+}
+
+// generator generates the client code.
+type generator struct {
+	spec    *spec
+	imports map[string]string
+	buf     bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// goType returns the Go type of the schema, and whether it is a struct type.
+func (g *generator) goType(s *schema) (string, bool) {
+	switch {
+	case s.Ref != "":
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		c := g.spec.Components.Schemas[name]
+		if c == nil {
+			panic("unknown schema " + s.Ref)
+		}
+		idx := strings.LastIndex(c.GoType, ".")
+		pkg, typ := c.GoType[:idx], c.GoType[idx+1:]
+		alias, found := packages[pkg]
+		if !found {
+			return g.rawMessage(), false
+		}
+		g.imports[pkg] = alias
+		return alias + "." + typ, true
+	case len(s.OneOf) > 0:
+		return g.rawMessage(), false
+	}
+
+	switch s.Type {
+	case "boolean":
+		return "bool", false
+	case "integer":
+		if s.Format == "int32" {
+			return "int32", false
+		}
+		return "int64", false
+	case "number":
+		if s.Format == "float" {
+			return "float32", false
+		}
+		return "float64", false
+	case "string":
+		if s.Format == "byte" {
+			return "[]byte", false
+		}
+		return "string", false
+	case "array":
+		elem, _ := g.goType(s.Items)
+		if elem == "json.RawMessage" {
+			return elem, false
+		}
+		return "[]" + elem, false
+	case "object":
+		if s.AdditionalProperties != nil {
+			elem, _ := g.goType(s.AdditionalProperties)
+			if elem == "json.RawMessage" {
+				return elem, false
+			}
+			return "map[string]" + elem, false
+		}
+	}
+	return g.rawMessage(), false
+}
+
+func (g *generator) rawMessage() string {
+	g.imports["encoding/json"] = ""
+	return "json.RawMessage"
+}
+
+func upperFirst(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+var pathParamRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+// pathExpr returns the Go expression of the path with the parameters.
+func pathExpr(path string, params map[string]*parameter) string {
+	var parts []string
+	last := 0
+	for _, m := range pathParamRegexp.FindAllStringSubmatchIndex(path, -1) {
+		if m[0] > last {
+			parts = append(parts, fmt.Sprintf("%q", path[last:m[0]]))
+		}
+		p := params[path[m[2]:m[3]]]
+		if p.Schema.Type == "integer" {
+			parts = append(parts, "pathInt("+lowerFirst(p.Name)+")")
+		} else {
+			parts = append(parts, "pathString("+lowerFirst(p.Name)+")")
+		}
+		last = m[1]
+	}
+	if last < len(path) {
+		parts = append(parts, fmt.Sprintf("%q", path[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func (g *generator) operation(method, path string, op *operation) {
+	name := upperFirst(op.OperationID)
+
+	var status string
+	for code := range op.Responses {
+		if code != "default" {
+			status = code
+		}
+	}
+	resp := op.Responses[status]
+
+	pathParams := make(map[string]*parameter)
+	var args []string
+	var queryParams []*parameter
+	for _, p := range op.Parameters {
+		typ, _ := g.goType(p.Schema)
+		switch p.In {
+		case "path":
+			pathParams[p.Name] = p
+			args = append(args, lowerFirst(p.Name)+" "+typ)
+		case "query":
+			queryParams = append(queryParams, p)
+		}
+	}
+	if op.RequestBody != nil {
+		typ, isStruct := g.goType(op.RequestBody.Content["application/json"].Schema)
+		if isStruct {
+			typ = "*" + typ
+		}
+		args = append(args, "body "+typ)
+	}
+	paramsType := name + "Params"
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+paramsType)
+
+		g.printf("// %s are the query parameters of %s.\n", paramsType, name)
+		g.printf("type %s struct {\n", paramsType)
+		for _, p := range queryParams {
+			typ, _ := g.goType(p.Schema)
+			g.printf("// %s: %s.\n", upperFirst(p.Name), p.Description)
+			g.printf("%s %s\n", upperFirst(p.Name), typ)
+		}
+		g.printf("}\n\n")
+	}
+
+	// The return type and the call of the request.
+	var ret, call string
+	var retStruct bool
+	switch {
+	case resp.Content == nil:
+		call = "return c.doJSON(ctx, req, nil)"
+	case resp.Content["text/plain"] != nil:
+		if resp.Content["text/plain"].Schema.Type == "integer" {
+			ret, call = "int64", "return c.doInt(ctx, req)"
+		} else {
+			ret, call = "string", "return c.doText(ctx, req)"
+		}
+	default:
+		ret, retStruct = g.goType(resp.Content["application/json"].Schema)
+	}
+
+	g.printf("// %s calls %s %s.\n// %s.\n", name, strings.ToUpper(method), path, op.Summary)
+	if len(op.Security) > 0 {
+		g.printf("// It requires the admin password.\n")
+	}
+	retList := "error"
+	if ret != "" {
+		if retStruct {
+			retList = fmt.Sprintf("(*%s, error)", ret)
+		} else {
+			retList = fmt.Sprintf("(%s, error)", ret)
+		}
+	}
+	g.printf("func (c *Client) %s(%s) %s {\n", name,
+		strings.Join(append([]string{"ctx context.Context"}, args...), ", "), retList)
+
+	g.printf("req := &request{\nmethod: %q,\npath: %s,\nstatus: %s,\n",
+		strings.ToUpper(method), pathExpr(path, pathParams), status)
+	if op.RequestBody != nil {
+		g.printf("body: body,\n")
+	}
+	if len(op.Security) > 0 {
+		g.printf("admin: true,\n")
+	}
+	g.printf("}\n")
+
+	if len(queryParams) > 0 {
+		g.imports["net/url"] = ""
+		g.printf("if params != nil {\nreq.query = make(url.Values)\n")
+		for _, p := range queryParams {
+			field := "params." + upperFirst(p.Name)
+			switch p.Schema.Type {
+			case "boolean":
+				g.printf("if %s {\nreq.query.Set(%q, \"true\")\n}\n", field, p.Name)
+			case "integer":
+				g.imports["strconv"] = ""
+				g.printf("if %s != 0 {\nreq.query.Set(%q, strconv.FormatInt(%s, 10))\n}\n", field, p.Name, field)
+			case "number":
+				g.imports["strconv"] = ""
+				g.printf("if %s != 0 {\nreq.query.Set(%q, strconv.FormatFloat(%s, 'f', -1, 64))\n}\n", field, p.Name, field)
+			default:
+				g.printf("if %s != \"\" {\nreq.query.Set(%q, %s)\n}\n", field, p.Name, field)
+			}
+		}
+		g.printf("}\n")
+	}
+
+	if call != "" {
+		g.printf("%s\n}\n\n", call)
+		return
+	}
+	g.printf("var resp %s\n", ret)
+	if retStruct {
+		g.printf("if err := c.doJSON(ctx, req, &resp); err != nil {\nreturn nil, err\n}\nreturn &resp, nil\n}\n\n")
+	} else {
+		g.printf("err := c.doJSON(ctx, req, &resp)\nreturn resp, err\n}\n\n")
+	}
+}
+
+// generate returns the formatted client code of the OpenAPI document.
+func generate(specJSON []byte) ([]byte, error) {
+	g := &generator{
+		spec:    new(spec),
+		imports: map[string]string{"context": ""},
+	}
+	if err := json.Unmarshal(specJSON, g.spec); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(g.spec.Paths))
+	for path := range g.spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(g.spec.Paths[path]))
+		for method := range g.spec.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			g.operation(method, path, g.spec.Paths[path][method])
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by go run ./internal/gen; DO NOT EDIT.\n\npackage client\n\nimport (\n")
+	imports := make([]string, 0, len(g.imports))
+	for pkg := range g.imports {
+		imports = append(imports, pkg)
+	}
+	sort.Strings(imports)
+	// The standard library imports go first.
+	for _, std := range []bool{true, false} {
+		for _, pkg := range imports {
+			if !strings.Contains(strings.Split(pkg, "/")[0], ".") == std {
+				fmt.Fprintf(&out, "%s %q\n", g.imports[pkg], pkg)
+			}
+		}
+		if std {
+			fmt.Fprintf(&out, "\n")
+		}
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+func main() {
+	specJSON, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	code, err := generate(specJSON)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile(outFile, code, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// TestGeneratedCode checks that the client code is generated from the current
+// OpenAPI document.
+func TestGeneratedCode(t *testing.T) {
+	spec, err := ioutil.ReadFile("../../" + specFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := ioutil.ReadFile("../../" + outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, current) {
+		t.Errorf("%s is out of date, run go generate in api/client", outFile)
+	}
+}
@@ -87,6 +87,9 @@ type Tx struct {
 	TxShort
 	Confirmations int64    `json:"confirmations"`
 	Block         *BlockID `json:"block,omitempty"`
+	// FirstSeen is the UNIX time the transaction was first seen in mempool,
+	// or zero if it was not observed in mempool.
+	FirstSeen int64 `json:"first_seen,omitempty"`
 }
 
 // TxShort models info about transaction TxID
@@ -304,11 +307,13 @@ type ScriptPubKey struct {
 	CommitAmt *float64 `json:"commitamt,omitempty"`
 }
 
-// TxOut defines a decred transaction output.
+// TxOut defines a decred transaction output. Labels are the labels of the
+// output's addresses that have one.
 type TxOut struct {
-	Value               float64      `json:"value"`
-	Version             uint16       `json:"version"`
-	ScriptPubKeyDecoded ScriptPubKey `json:"scriptPubKey"`
+	Value               float64                 `json:"value"`
+	Version             uint16                  `json:"version"`
+	ScriptPubKeyDecoded ScriptPubKey            `json:"scriptPubKey"`
+	Labels              []*dbtypes.AddressLabel `json:"labels,omitempty"`
 }
 
 // TxIn defines a decred transaction input.
@@ -673,6 +678,101 @@ type StakeDiff struct {
 	PriceWindowNum   int                               `json:"window_number"`
 }
 
+// The actions of the events of a StakeSimulation.
+const (
+	StakeSimBuy    = "buy"
+	StakeSimVote   = "vote"
+	StakeSimReward = "reward"
+)
+
+// StakeSimEvent is a ticket purchase, the vote of the tickets, or the maturity
+// of the vote's returned ticket price and reward in a StakeSimulation. Balance
+// is the unspent amount after the event. Projected events are after the best
+// block, and use the estimated ticket price and an estimated time.
+type StakeSimEvent struct {
+	Height      int64   `json:"height"`
+	Time        int64   `json:"time"`
+	Action      string  `json:"action"`
+	Tickets     int64   `json:"tickets"`
+	TicketPrice float64 `json:"ticket_price"`
+	Reward      float64 `json:"reward"`
+	Balance     float64 `json:"balance"`
+	Projected   bool    `json:"projected"`
+}
+
+// StakeSimulation is the result of staking an amount of DCR from a start block
+// to an end block, buying as many tickets as the balance allows, voting the
+// tickets after the mean time to vote given the ticket pool size, and buying
+// tickets again when the returned funds mature. With Reinvest, the rewards are
+// used to buy tickets too. Ticket fees and missed votes are not modeled. The
+// value of the tickets that are live at the end block, and of the vote
+// outputs that are immature, is Locked. Return is the relative gain of
+// FinalValue over Amount, and AnnualReturn is the same gain per year.
+type StakeSimulation struct {
+	Amount       float64          `json:"amount"`
+	Reinvest     bool             `json:"reinvest"`
+	StartHeight  int64            `json:"start_height"`
+	EndHeight    int64            `json:"end_height"`
+	BestHeight   int64            `json:"best_height"`
+	Balance      float64          `json:"balance"`
+	Locked       float64          `json:"locked"`
+	Rewards      float64          `json:"rewards"`
+	FinalValue   float64          `json:"final_value"`
+	Return       float64          `json:"return"`
+	AnnualReturn float64          `json:"annual_return"`
+	Timeline     []*StakeSimEvent `json:"timeline"`
+}
+
+// The statuses of a TSpendProgress. A treasury spend in mempool waits for its
+// voting window to start, and is voting until it is mined on a treasury vote
+// interval with enough votes, or expires at the end of the window.
+const (
+	TSpendMempool = "mempool"
+	TSpendVoting  = "voting"
+	TSpendMined   = "mined"
+	TSpendExpired = "expired"
+)
+
+// The projected outcomes of a TSpendProgress.
+const (
+	TSpendPass = "pass"
+	TSpendFail = "fail"
+)
+
+// TSpendProgress is the progress of the stakeholder vote on a treasury spend
+// (tspend). The votes are cast in the blocks of the voting window from
+// VoteStart to VoteEnd, and the tspend may be mined when Quorum votes were
+// cast, of which the fraction RequiredApproval or more are yes votes, which
+// makes it Approved. Projected is the outcome when the votes of the rest of
+// the window are cast at the same rate and with the same approval as the
+// votes so far, and is empty if no votes were cast yet.
+type TSpendProgress struct {
+	TxID             string  `json:"txid"`
+	Status           string  `json:"status"`
+	Amount           float64 `json:"amount"`
+	FirstSeen        int64   `json:"first_seen,omitempty"`
+	Expiry           int64   `json:"expiry"`
+	VoteStart        int64   `json:"vote_start"`
+	VoteEnd          int64   `json:"vote_end"`
+	YesVotes         int64   `json:"yes_votes"`
+	NoVotes          int64   `json:"no_votes"`
+	MaxVotes         int64   `json:"max_votes"`
+	Quorum           int64   `json:"quorum"`
+	RequiredApproval float64 `json:"required_approval"`
+	Approval         float64 `json:"approval"`
+	Approved         bool    `json:"approved"`
+	Projected        string  `json:"projected,omitempty"`
+	BlockHash        string  `json:"block_hash,omitempty"`
+	BlockHeight      int64   `json:"block_height,omitempty"`
+}
+
+// TSpends is the progress of the treasury spends in mempool, and of those
+// mined or expired within the last voting window, as of the best block.
+type TSpends struct {
+	Height  int64             `json:"height"`
+	TSpends []*TSpendProgress `json:"tspends"`
+}
+
 // StakeInfoExtended models data about the fee, pool and stake difficulty
 type StakeInfoExtended struct {
 	Hash             string                 `json:"hash"`
@@ -754,6 +854,14 @@ type TicketInfo struct {
 	Revocation       *string    `json:"revocation"`
 }
 
+// TicketSet is a set of tickets registered for tracking, with the status of
+// each ticket by ticket hash. The status transitions of the tickets are
+// signaled by the pubsub tickets event of the set ID.
+type TicketSet struct {
+	ID      string            `json:"id"`
+	Tickets map[string]string `json:"tickets"`
+}
+
 // TinyBlock is the hash and height of a block.
 type TinyBlock struct {
 	Hash   string `json:"hash"`
@@ -770,6 +878,13 @@ type TicketPoolChartsData struct {
 	Mempool      *PriceCountTime          `json:"mempool"`
 }
 
+// TicketPoolByDate is the purchase time distribution of the ticket pool at
+// /ticketpool/bydate/{tp}.
+type TicketPoolByDate struct {
+	Height    int64                    `json:"height"`
+	TimeChart *dbtypes.PoolTicketsData `json:"time_chart"`
+}
+
 // PowerlessTicket is the purchase block height and value of a missed or expired
 // ticket.
 type PowerlessTicket struct {
@@ -790,3 +905,266 @@ type PriceCountTime struct {
 	Count int             `json:"count"`
 	Time  dbtypes.TimeDef `json:"time"`
 }
+
+// AtomicSwaps is a page of spent atomic swap contracts. Total is the number of
+// swaps matching the query, and is omitted when not counted.
+type AtomicSwaps struct {
+	Total int64                 `json:"total,omitempty"`
+	Swaps []*dbtypes.AtomicSwap `json:"swaps"`
+}
+
+// MempoolOverview summarizes the current mempool by transaction type, along
+// with a fee rate histogram and the totals for the transactions likely to be
+// mined in the next block.
+type MempoolOverview struct {
+	LastBlockHeight  int64              `json:"block_height"`
+	LastBlockHash    string             `json:"block_hash"`
+	LastBlockTime    int64              `json:"block_time"`
+	Time             int64              `json:"time"`
+	Count            int                `json:"count"`
+	Size             int32              `json:"size"`
+	Total            float64            `json:"total"`
+	Fees             float64            `json:"fees"`
+	Regular          MempoolTypeSummary `json:"regular"`
+	Tickets          MempoolTypeSummary `json:"tickets"`
+	Votes            MempoolTypeSummary `json:"votes"`
+	Revokes          MempoolTypeSummary `json:"revokes"`
+	TSpends          MempoolTypeSummary `json:"tspends"`
+	TAdds            MempoolTypeSummary `json:"tadds"`
+	FeeRateHistogram []FeeRateBin       `json:"fee_rate_histogram"`
+	LikelyMined      MempoolLikelyMined `json:"likely_mined"`
+}
+
+// MempoolTypeSummary is the count, size, total output value, and total fees of
+// the mempool transactions of a certain type. Amounts are in DCR.
+type MempoolTypeSummary struct {
+	Count int     `json:"count"`
+	Size  int32   `json:"size"`
+	Total float64 `json:"total"`
+	Fees  float64 `json:"fees"`
+}
+
+// FeeRateBin is a fee rate histogram bin. Fee rates are in DCR/kB. MaxFeeRate
+// is omitted for the last bin, which has no upper bound.
+type FeeRateBin struct {
+	MinFeeRate float64 `json:"min_fee_rate"`
+	MaxFeeRate float64 `json:"max_fee_rate,omitempty"`
+	Count      int     `json:"count"`
+	Size       int32   `json:"size"`
+}
+
+// MempoolLikelyMined holds the totals for the mempool transactions that are
+// likely to be mined in the next block, which excludes votes on blocks other
+// than the best block and duplicate votes spending the same ticket.
+type MempoolLikelyMined struct {
+	Count        int     `json:"count"`
+	Size         int32   `json:"size"`
+	Total        float64 `json:"total"`
+	RegularTotal float64 `json:"regular_total"`
+	TicketTotal  float64 `json:"ticket_total"`
+	VoteTotal    float64 `json:"vote_total"`
+	RevokeTotal  float64 `json:"revoke_total"`
+	TSpendTotal  float64 `json:"tspend_total"`
+	TAddTotal    float64 `json:"tadd_total"`
+}
+
+// MempoolTxs is a page of mempool transactions of a certain type. Total is the
+// number of mempool transactions of the type.
+type MempoolTxs struct {
+	Type         string          `json:"type"`
+	Total        int             `json:"total"`
+	Transactions []MempoolTxInfo `json:"transactions"`
+}
+
+// MempoolTxInfo describes a mempool transaction. Amounts are in DCR, and the
+// fee rate is in DCR/kB.
+type MempoolTxInfo struct {
+	TxID      string  `json:"txid"`
+	Type      string  `json:"type"`
+	Time      int64   `json:"time"`
+	Size      int32   `json:"size"`
+	Fees      float64 `json:"fees"`
+	FeeRate   float64 `json:"fee_rate"`
+	TotalOut  float64 `json:"total"`
+	VinCount  int     `json:"vin_count"`
+	VoutCount int     `json:"vout_count"`
+}
+
+// FeeEstimates are the fee rate estimates for several confirmation targets,
+// computed at the given best block height and time.
+type FeeEstimates struct {
+	Height    int64         `json:"height"`
+	Time      int64         `json:"time"`
+	Estimates []FeeEstimate `json:"estimates"`
+}
+
+// FeeEstimate is the fee rate, in DCR/kB, estimated for a transaction to be
+// mined within TargetBlocks blocks. MempoolFeeRate and BlocksFeeRate are the
+// rates required by the current mempool backlog and by the recently mined
+// blocks, respectively. Confidence is the estimated probability, based on the
+// recent blocks, that a transaction paying FeeRate is mined within the target.
+type FeeEstimate struct {
+	TargetBlocks   int     `json:"target_blocks"`
+	FeeRate        float64 `json:"fee_rate"`
+	Confidence     float64 `json:"confidence"`
+	MempoolFeeRate float64 `json:"mempool_fee_rate"`
+	BlocksFeeRate  float64 `json:"blocks_fee_rate"`
+}
+
+// WebhookRequest is the body of a webhook registration request. The URL is
+// sent the events for transactions involving any of the addresses. If
+// Confirmations is greater than one, a confirmations event is also sent when a
+// transaction is mined that deep.
+type WebhookRequest struct {
+	URL           string   `json:"url"`
+	Addresses     []string `json:"addresses"`
+	Confirmations int32    `json:"confirmations"`
+}
+
+// APIKeyRequest is the body of a request to issue an API key. RateLimit is the
+// sustained request rate in requests per second, and Burst is the number of
+// requests that may be made at once. The monthly quota is unlimited if
+// MonthlyQuota is zero.
+type APIKeyRequest struct {
+	Name         string  `json:"name"`
+	RateLimit    float64 `json:"rate_limit"`
+	Burst        int32   `json:"burst"`
+	MonthlyQuota int64   `json:"monthly_quota"`
+}
+
+// APIKeys is the list of the issued API keys, with their usage in the month.
+type APIKeys struct {
+	Month string            `json:"month"`
+	Keys  []*dbtypes.APIKey `json:"keys"`
+}
+
+// TxBroadcastRequest is the body of a request to broadcast a transaction.
+type TxBroadcastRequest struct {
+	Hex string `json:"hex"`
+}
+
+// TxBroadcast is the status of a transaction broadcast. The fee is in DCR and
+// the fee rate in DCR/kB. Submitted and Updated are UNIX times. The block is
+// set for a mined transaction, and ConflictTxID is the transaction spending an
+// input of a double spent transaction. Message is the reason a transaction
+// was rejected. Rejected transactions are not tracked, and have no status.
+type TxBroadcast struct {
+	TxID          string  `json:"txid"`
+	Status        string  `json:"status"`
+	Message       string  `json:"message,omitempty"`
+	Size          int32   `json:"size,omitempty"`
+	Fee           float64 `json:"fee,omitempty"`
+	FeeRate       float64 `json:"fee_rate,omitempty"`
+	Submitted     int64   `json:"submitted,omitempty"`
+	Updated       int64   `json:"updated,omitempty"`
+	BlockHash     string  `json:"block_hash,omitempty"`
+	BlockHeight   int64   `json:"block_height,omitempty"`
+	Confirmations int64   `json:"confirmations"`
+	ConflictTxID  string  `json:"conflict_txid,omitempty"`
+	Tracked       bool    `json:"tracked"`
+}
+
+// WebhookDeliveries is a page of a webhook's deliveries, most recent first.
+// Total is the number of deliveries for the webhook.
+type WebhookDeliveries struct {
+	WebhookID  string                     `json:"webhook_id"`
+	Total      int64                      `json:"total"`
+	Deliveries []*dbtypes.WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookReplay is the result of a request to send again a webhook's
+// deliveries created since a UNIX time.
+type WebhookReplay struct {
+	WebhookID string `json:"webhook_id"`
+	Since     int64  `json:"since"`
+	Replayed  int64  `json:"replayed"`
+}
+
+// WebhookEvent is the body of a webhook delivery. Created is the UNIX time of
+// the event, and Attempt is the delivery attempt number starting at 1.
+type WebhookEvent struct {
+	DeliveryID    int64  `json:"delivery_id"`
+	WebhookID     string `json:"webhook_id"`
+	Event         string `json:"event"`
+	Address       string `json:"address"`
+	TxID          string `json:"txid"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	BlockHeight   int64  `json:"block_height,omitempty"`
+	Confirmations int32  `json:"confirmations"`
+	Created       int64  `json:"created"`
+	Attempt       int32  `json:"attempt"`
+}
+
+// XpubAddress is an address derived from an extended public key at the given
+// branch and index, with its balance. Amounts are in atoms.
+type XpubAddress struct {
+	Address      string `json:"address"`
+	Branch       uint32 `json:"branch"`
+	Index        uint32 `json:"index"`
+	NumSpent     int64  `json:"num_stxos"`
+	NumUnspent   int64  `json:"num_utxos"`
+	TotalSpent   int64  `json:"amount_spent"`
+	TotalUnspent int64  `json:"amount_unspent"`
+}
+
+// XpubBalance is the aggregated balance of the used addresses of an account
+// extended public key, discovered with the given gap limit. NextExternal and
+// NextInternal are the indexes following the last used address of each
+// branch. Amounts are in atoms, except Balance, which is in DCR.
+type XpubBalance struct {
+	Height       int64          `json:"height"`
+	GapLimit     uint32         `json:"gap_limit"`
+	NextExternal uint32         `json:"next_external_index"`
+	NextInternal uint32         `json:"next_internal_index"`
+	NumSpent     int64          `json:"num_stxos"`
+	NumUnspent   int64          `json:"num_utxos"`
+	TotalSpent   int64          `json:"amount_spent"`
+	TotalUnspent int64          `json:"amount_unspent"`
+	Balance      float64        `json:"balance"`
+	Addresses    []*XpubAddress `json:"addresses"`
+}
+
+// XpubUTXOs is the set of unspent outputs paying to the addresses of an
+// account extended public key.
+type XpubUTXOs struct {
+	Height int64               `json:"height"`
+	UTXOs  []*AddressTxnOutput `json:"utxos"`
+}
+
+// XpubTx is a transaction in the history of an account extended public key.
+// Credit and Debit, in atoms, are summed over the account's addresses, so
+// transfers between the account's own addresses only count the fee as a net
+// debit.
+type XpubTx struct {
+	TxID           string   `json:"txid"`
+	Type           string   `json:"type"`
+	Time           int64    `json:"time"`
+	Credit         uint64   `json:"credit"`
+	Debit          uint64   `json:"debit"`
+	ValidMainChain bool     `json:"valid_mainchain"`
+	Addresses      []string `json:"addresses"`
+}
+
+// XpubHistory is a page of the merged transaction history of an account
+// extended public key, most recent first. Total is the number of transactions
+// in the history.
+type XpubHistory struct {
+	Height       int64     `json:"height"`
+	Total        int64     `json:"total"`
+	Transactions []*XpubTx `json:"transactions"`
+}
+
+// RichList is the top addresses by balance as of the last rich list refresh.
+// Distribution describes the balances of all addresses, and is omitted if it
+// has not been computed since startup.
+type RichList struct {
+	Distribution *dbtypes.AddressDistribution `json:"distribution,omitempty"`
+	Addresses    []*dbtypes.RichListEntry     `json:"addresses"`
+}
+
+// AddressLabelRequest is the body of an admin request to set the label of an
+// address. Category is one of the dbtypes.AddressTag constants.
+type AddressLabelRequest struct {
+	Label    string `json:"label"`
+	Category string `json:"category"`
+}
@@ -5,6 +5,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -28,6 +29,14 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	// chi router
 	mux := stackedMux(useRealIP)
 
+	// Limit the requests with an API key by the key's rate limit and quota,
+	// and the requests without a key by the per-IP limit, if any.
+	var limiter *m.Limiter
+	if app.reqRateLimit > 0 {
+		limiter = m.NewIPLimiter(app.reqRateLimit, useRealIP)
+	}
+	mux.Use(app.apiKeys.Limit(m.APIKeyGroupAPI, limiter))
+
 	// Check for and validate the "indent" URL query. Each API request handler
 	// may now access the configured indentation string if indent was specified
 	// and parsed as a boolean, otherwise the empty string, from
@@ -139,6 +148,17 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			rd.With(m.BlockIndex0PathCtx, m.BlockIndexPathCtx).Get("/r/{idx0}/{idx}", app.getStakeDiffRange)
 		})
 		r.Get("/powerless", app.getPowerlessTickets)
+		r.Route("/misses", func(rd chi.Router) {
+			rd.With(m.BlockIndexOrHashPathCtx).Get("/b/{idxorhash}", app.getBlockMisses)
+			rd.With(m.AddressPathCtxN(1)).Get("/address/{address}", app.getAddressMisses)
+		})
+		r.Get("/simulate", app.simulateStaking)
+		r.Get("/vsp", app.getVSPStats)
+		r.Route("/ticketsets", func(rd chi.Router) {
+			rd.With(middleware.AllowContentType("application/json"),
+				m.ValidateTxnsPostCtx, m.PostTxnsCtx).Post("/", app.registerTicketSet)
+			rd.With(m.TicketSetIDPathCtx).Get("/{setid}", app.getTicketSet)
+		})
 	})
 
 	mux.Route("/tx", func(r chi.Router) {
@@ -157,11 +177,14 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 				})
 				rd.Get("/vinfo", app.getTxVoteInfo)
 				rd.Get("/tinfo", app.getTxTicketInfo)
+				rd.Get("/proposalvotes", app.getTicketProposalVotes)
 			})
 		})
 		r.With(m.TransactionHashCtx).Get("/hex/{txid}", app.getTransactionHex)
 		r.With(m.TransactionHashCtx).Get("/decoded/{txid}", app.getDecodedTx)
 		r.With(m.TransactionHashCtx).Get("/swaps/{txid}", app.getTxSwapsInfo)
+		r.With(middleware.AllowContentType("application/json")).Post("/broadcast", app.broadcastTx)
+		r.With(m.TransactionHashCtx).Get("/broadcast/{txid}", app.getTxBroadcast)
 	})
 
 	mux.Route("/txs", func(r chi.Router) {
@@ -169,6 +192,7 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 			m.ValidateTxnsPostCtx, m.PostTxnsCtx)
 		r.Post("/", app.getTransactions)
 		r.Post("/trimmed", app.getDecodedTransactions)
+		r.Post("/tinfo", app.getTicketsInfo)
 	})
 
 	// DO NOT CHANGE maxExistAddrs.
@@ -176,11 +200,14 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	const maxExistAddrs = 64
 
 	mux.Route("/address", func(r chi.Router) {
+		r.Get("/rich", app.getRichList)
+		r.With(m.NPathCtx).Get("/rich/{N}", app.getRichList)
 		r.Route("/{address}", func(rd chi.Router) {
 			rd.With(m.AddressPathCtxN(maxExistAddrs)).Get("/exists", app.addressExists)
 			rd.Group(func(re chi.Router) {
 				re.Use(m.AddressPathCtxN(1))
 				re.Get("/totals", app.addressTotals)
+				re.Get("/proposalvotes", app.getAddressProposalVotes)
 				re.Get("/", app.getAddressTransactions)
 				re.With(m.ChartGroupingCtx).Get("/types/{chartgrouping}", app.getAddressTxTypesData)
 				re.With(m.ChartGroupingCtx).Get("/amountflow/{chartgrouping}", app.getAddressTxAmountFlowData)
@@ -200,9 +227,70 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		})
 	})
 
+	// Address labels. Setting and deleting labels requires admin
+	// authentication.
+	mux.Route("/labels", func(r chi.Router) {
+		r.Get("/", app.getAddressLabels)
+		r.Route("/{address}", func(rd chi.Router) {
+			rd.Use(m.AddressPathCtxN(1))
+			rd.Get("/", app.getAddressLabel)
+			rd.Group(func(ra chi.Router) {
+				ra.Use(m.AdminAuth(app.adminPass))
+				ra.With(middleware.AllowContentType("application/json")).Put("/", app.setAddressLabel)
+				ra.Delete("/", app.deleteAddressLabel)
+			})
+		})
+	})
+
+	// Account extended public keys
+	mux.Route("/xpub/{xpub}", func(r chi.Router) {
+		r.Use(m.XpubPathCtx)
+		r.Get("/", app.getXpubBalance)
+		r.Get("/utxos", app.getXpubUTXOs)
+		r.Route("/txs", func(rt chi.Router) {
+			rt.Get("/", app.getXpubHistory)
+			rt.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getXpubHistory)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getXpubHistory)
+			})
+		})
+	})
+
+	// Atomic swaps
+	mux.Route("/swaps", func(r chi.Router) {
+		r.Get("/", app.getAtomicSwaps)
+		r.Route("/count/{N}", func(ri chi.Router) {
+			ri.Use(m.NPathCtx)
+			ri.Get("/", app.getAtomicSwaps)
+			ri.With(m.MPathCtx).Get("/skip/{M}", app.getAtomicSwaps)
+		})
+		r.Route("/address/{address}", func(rd chi.Router) {
+			rd.Use(m.AddressPathCtxN(1))
+			rd.Get("/", app.getAddressAtomicSwaps)
+			rd.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getAddressAtomicSwaps)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getAddressAtomicSwaps)
+			})
+		})
+		r.With(m.SecretHashPathCtx).Get("/secret/{secrethash}", app.getAtomicSwapsBySecretHash)
+		r.Route("/time/{start}/{end}", func(rd chi.Router) {
+			rd.Use(m.TimeRangePathCtx)
+			rd.Get("/", app.getAtomicSwapsInTimeRange)
+			rd.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getAtomicSwapsInTimeRange)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getAtomicSwapsInTimeRange)
+			})
+		})
+	})
+
 	// Treasury
 	mux.Route("/treasury", func(r chi.Router) {
 		r.With(m.ChartGroupingCtx).Get("/io/{chartgrouping}", app.getTreasuryIO)
+		r.Get("/tspends", app.getTSpends)
+		r.Get("/spends", app.getTreasurySpendReport)
 	})
 
 	// Returns agenda data like; description, name, lockedin activated and other
@@ -217,7 +305,17 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	})
 
 	mux.Route("/mempool", func(r chi.Router) {
-		r.Get("/", http.NotFound /*app.getMempoolOverview*/)
+		r.Get("/", app.getMempoolOverview)
+		// paged transaction lists by type
+		r.Route("/txs/{txtype}", func(rd chi.Router) {
+			rd.Use(m.TxTypePathCtx)
+			rd.Get("/", app.getMempoolTxs)
+			rd.Route("/count/{N}", func(ri chi.Router) {
+				ri.Use(m.NPathCtx)
+				ri.Get("/", app.getMempoolTxs)
+				ri.With(m.MPathCtx).Get("/skip/{M}", app.getMempoolTxs)
+			})
+		})
 		// ticket purchases
 		r.Route("/sstx", func(rd chi.Router) {
 			rd.Get("/", app.getSSTxSummary)
@@ -228,6 +326,37 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		})
 	})
 
+	mux.Route("/fees", func(r chi.Router) {
+		r.Get("/estimate", app.getFeeEstimates)
+	})
+
+	mux.Route("/webhooks", func(r chi.Router) {
+		r.With(middleware.AllowContentType("application/json")).Post("/", app.createWebhook)
+		r.Route("/{webhookid}", func(rd chi.Router) {
+			rd.Use(m.WebhookIDPathCtx)
+			rd.Get("/", app.getWebhook)
+			rd.Delete("/", app.deleteWebhook)
+			rd.Route("/deliveries", func(rr chi.Router) {
+				rr.Get("/", app.getWebhookDeliveries)
+				rr.Route("/count/{N}", func(ri chi.Router) {
+					ri.Use(m.NPathCtx)
+					ri.Get("/", app.getWebhookDeliveries)
+					ri.With(m.MPathCtx).Get("/skip/{M}", app.getWebhookDeliveries)
+				})
+			})
+			rd.Post("/replay", app.replayWebhookDeliveries)
+		})
+	})
+
+	// API keys. Issuing, listing and revoking keys requires admin
+	// authentication.
+	mux.Route("/apikeys", func(r chi.Router) {
+		r.Use(m.AdminAuth(app.adminPass))
+		r.Get("/", app.getAPIKeys)
+		r.With(middleware.AllowContentType("application/json")).Post("/", app.createAPIKey)
+		r.With(m.APIKeyIDPathCtx).Delete("/{keyid}", app.revokeAPIKey)
+	})
+
 	mux.Route("/chart", func(r chi.Router) {
 		// Return default chart data (ticket price)
 		r.Route("/market/{token}", func(rd chi.Router) {
@@ -245,7 +374,19 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 	})
 
 	mux.Route("/proposal", func(r chi.Router) {
-		r.With(m.ProposalTokenCtx).Get("/{token}", app.getProposalChartData)
+		r.Route("/{token}", func(rd chi.Router) {
+			rd.Use(m.ProposalTokenCtx)
+			rd.Get("/", app.getProposalChartData)
+			rd.Get("/history", app.getProposalHistory)
+			rd.Route("/voters", func(rv chi.Router) {
+				rv.Get("/", app.getProposalVoters)
+				rv.Route("/count/{N}", func(ri chi.Router) {
+					ri.Use(m.NPathCtx)
+					ri.Get("/", app.getProposalVoters)
+					ri.With(m.MPathCtx).Get("/skip/{M}", app.getProposalVoters)
+				})
+			})
+		})
 	})
 
 	mux.Route("/exchanges", func(r chi.Router) {
@@ -287,11 +428,21 @@ func NewAPIRouter(app *appContext, JSONIndent string, useRealIP, compressLarge b
 		return patterns
 	}
 
-	mux.HandleFunc("/list", func(w http.ResponseWriter, _ *http.Request) {
+	mux.Get("/list", func(w http.ResponseWriter, _ *http.Request) {
 		routeList := listRoutePatterns(mux.Routes())
 		writeJSON(w, routeList, JSONIndent)
 	})
 
+	// The OpenAPI document of the routes. The tests check that it describes
+	// every route of the router.
+	openAPI, err := newOpenAPIDoc(apiOperations())
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI document: %v", err))
+	}
+	mux.Get("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, openAPI, m.GetIndentCtx(r))
+	})
+
 	return apiMux{mux}
 }
 
@@ -308,6 +459,14 @@ func NewFileRouter(app *appContext, useRealIP bool) fileMux {
 		rd.With(m.AddressPathCtxN(1)).Get("/io/{address}/win", app.addressIoCsvCR)
 	})
 
+	mux.Route("/treasury", func(rd chi.Router) {
+		rd.Use(m.CacheControl(180))
+		rd.Get("/payees", app.treasuryPayeesCsvNoCR)
+		rd.Get("/payees/win", app.treasuryPayeesCsvCR)
+		rd.Get("/proposals", app.treasuryProposalsCsvNoCR)
+		rd.Get("/proposals/win", app.treasuryProposalsCsvCR)
+	})
+
 	return fileMux{mux}
 }
 
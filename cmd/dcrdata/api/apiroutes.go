@@ -35,7 +35,10 @@ import (
 	apitypes "github.com/decred/dcrdata/v6/api/types"
 	"github.com/decred/dcrdata/v6/db/cache"
 	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	"github.com/decred/dcrdata/v6/pubsub/webhook"
 	"github.com/decred/dcrdata/v6/rpcutils"
+	"github.com/decred/dcrdata/v6/stakedb"
 	"github.com/decred/dcrdata/v6/txhelpers"
 )
 
@@ -62,14 +65,34 @@ type DataSource interface {
 	TxHistoryData(address string, addrChart dbtypes.HistoryChart,
 		chartGroupings dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, error)
 	BinnedTreasuryIO(chartGroupings dbtypes.TimeBasedGrouping) (*dbtypes.ChartsData, error)
+	TreasurySpendReport(grouping string) (*dbtypes.TreasurySpendReport, error)
+	AtomicSwaps(n, offset int64) ([]*dbtypes.AtomicSwap, error)
+	AtomicSwapsCount(address string) (int64, error)
+	AtomicSwapsForAddress(address string, n, offset int64) ([]*dbtypes.AtomicSwap, error)
+	AtomicSwapsBySecretHash(secretHash string) ([]*dbtypes.AtomicSwap, error)
+	AtomicSwapsInTimeRange(start, end, n, offset int64) ([]*dbtypes.AtomicSwap, error)
 	TicketPoolVisualization(interval dbtypes.TimeBasedGrouping) (
 		*dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, *dbtypes.PoolTicketsData, int64, error)
 	AgendaVotes(agendaID string, chartType int) (*dbtypes.AgendaVoteChoices, error)
 	AddressRowsCompact(address string) ([]*dbtypes.AddressRowCompact, error)
+	AddressRowsMerged(address string) ([]*dbtypes.AddressRowMerged, error)
+	AddressBalance(address string) (*dbtypes.AddressBalance, bool, error)
+	AddressUTXO(address string) ([]*dbtypes.AddressTxnOutput, bool, error)
+	RichList(n int64) ([]*dbtypes.RichListEntry, *dbtypes.AddressDistribution, error)
+	VSPStats() *dbtypes.VSPAnalytics
+	AddressLabel(addr string) *dbtypes.AddressLabel
+	AllAddressLabels(category string) []*dbtypes.AddressLabel
+	SetAddressLabel(l *dbtypes.AddressLabel) error
+	DeleteAddressLabel(addr string) (bool, error)
 	Height() int64
 	AllAgendas() (map[string]dbtypes.MileStone, error)
 	GetTicketInfo(txid string) (*apitypes.TicketInfo, error)
+	GetTicketsInfo(txids []string) (map[string]*apitypes.TicketInfo, error)
 	ProposalVotes(proposalToken string) (*dbtypes.ProposalChartsData, error)
+	ProposalHistory(token string) (*dbtypes.ProposalHistory, error)
+	ProposalVoters(token string, N, offset int64) (*dbtypes.ProposalVoters, error)
+	TicketProposalVotes(ticket string) ([]*dbtypes.ProposalTicketVote, error)
+	AddressProposalVotes(address string) ([]*dbtypes.ProposalTicketVote, error)
 	PowerlessTickets() (*apitypes.PowerlessTickets, error)
 	GetStakeInfoExtendedByHash(hash string) *apitypes.StakeInfoExtended
 	GetStakeInfoExtendedByHeight(idx int) *apitypes.StakeInfoExtended
@@ -101,6 +124,10 @@ type DataSource interface {
 	GetBlockSizeRange(idx0, idx1 int) ([]int32, error)
 	GetSDiff(idx int) float64
 	GetSDiffRange(idx0, idx1 int) []float64
+	BlockHeightAtTime(timestamp int64) (int64, error)
+	BlockMissCauses(blockHash string) ([]*dbtypes.MissedVote, error)
+	AddressMissCauses(address string) ([]*dbtypes.MissedVote, error)
+	BlockTimes(heights []int64) (map[int64]int64, error)
 	GetMempoolSSTxSummary() *apitypes.MempoolTicketFeeInfo
 	GetMempoolSSTxFeeRates(N int) *apitypes.MempoolTicketFees
 	GetMempoolSSTxDetails(N int) *apitypes.MempoolTicketDetails
@@ -108,17 +135,64 @@ type DataSource interface {
 	GetMempoolPriceCountTime() *apitypes.PriceCountTime
 }
 
+// MempoolSource provides the current mempool inventory maintained by the
+// mempool monitor.
+type MempoolSource interface {
+	MempoolInventory() *exptypes.MempoolInfo
+}
+
+// FeeEstimator provides the current fee rate estimates.
+type FeeEstimator interface {
+	FeeEstimates() *apitypes.FeeEstimates
+}
+
+// TxBroadcaster validates and relays transactions, and tracks their status.
+type TxBroadcaster interface {
+	Broadcast(txHex string) (*apitypes.TxBroadcast, error)
+	BroadcastStatus(txHash string) (*apitypes.TxBroadcast, error)
+}
+
+// TicketSetTracker tracks the status of the tickets of registered ticket sets.
+type TicketSetTracker interface {
+	Register(tickets []string) (*apitypes.TicketSet, error)
+	TicketSet(id string) *apitypes.TicketSet
+}
+
+// TSpendTracker tracks the progress of the votes on treasury spends.
+type TSpendTracker interface {
+	TSpends() *apitypes.TSpends
+}
+
+// WebhookSource manages the registered webhooks and their deliveries.
+type WebhookSource interface {
+	CreateWebhook(wh *dbtypes.Webhook) error
+	Webhook(id string) (*dbtypes.Webhook, error)
+	DeleteWebhook(id string) (bool, error)
+	WebhookDeliveries(id string, N, offset int64) ([]*dbtypes.WebhookDelivery, int64, error)
+	ReplayWebhookDeliveries(id string, since time.Time) (int64, error)
+}
+
 // dcrdata application context used by all route handlers
 type appContext struct {
 	nodeClient   *rpcclient.Client
 	Params       *chaincfg.Params
 	DataSource   DataSource
+	Mempool      MempoolSource
+	FeeEstimator FeeEstimator
+	Broadcaster  TxBroadcaster
+	TicketSets   TicketSetTracker
+	TSpends      TSpendTracker
+	Webhooks     WebhookSource
 	Status       *apitypes.Status
 	xcBot        *exchanges.ExchangeBot
 	AgendaDB     *agendas.AgendaDB
 	maxCSVAddrs  int
 	charts       *cache.ChartData
 	isPiDisabled bool // is piparser disabled
+	adminPass    string
+	apiKeys      *m.APIKeys
+	reqRateLimit float64
+	xpubs        *xpubCache
 }
 
 // AppContextConfig is the configuration for the appContext and the only
@@ -127,12 +201,27 @@ type AppContextConfig struct {
 	Client             *rpcclient.Client
 	Params             *chaincfg.Params
 	DataSource         DataSource
+	MempoolSource      MempoolSource
+	FeeEstimator       FeeEstimator
+	Broadcaster        TxBroadcaster
+	TicketSets         TicketSetTracker
+	TSpends            TSpendTracker
+	WebhookSource      WebhookSource
 	XcBot              *exchanges.ExchangeBot
 	AgendasDBInstance  *agendas.AgendaDB
 	MaxAddrs           int
 	Charts             *cache.ChartData
 	IsPiparserDisabled bool
 	AppVer             string
+	// AdminPass is the password of the admin API, which is disabled if it is
+	// empty.
+	AdminPass string
+	// APIKeys are the API keys with their own rate limits and quotas, which
+	// are disabled if nil.
+	APIKeys *m.APIKeys
+	// ReqRateLimit is the rate limit in requests per second per client IP for
+	// requests without an API key. There is no limit if it is zero.
+	ReqRateLimit float64
 }
 
 // NewContext constructs a new appContext from the RPC client and database, and
@@ -151,12 +240,22 @@ func NewContext(cfg *AppContextConfig) *appContext {
 		nodeClient:   cfg.Client,
 		Params:       cfg.Params,
 		DataSource:   cfg.DataSource,
+		Mempool:      cfg.MempoolSource,
+		FeeEstimator: cfg.FeeEstimator,
+		Broadcaster:  cfg.Broadcaster,
+		TicketSets:   cfg.TicketSets,
+		TSpends:      cfg.TSpends,
+		Webhooks:     cfg.WebhookSource,
 		xcBot:        cfg.XcBot,
 		AgendaDB:     cfg.AgendasDBInstance,
 		Status:       apitypes.NewStatus(uint32(nodeHeight), conns, APIVersion, cfg.AppVer, cfg.Params.Name),
 		maxCSVAddrs:  cfg.MaxAddrs,
 		charts:       cfg.Charts,
 		isPiDisabled: cfg.IsPiparserDisabled,
+		adminPass:    cfg.AdminPass,
+		apiKeys:      cfg.APIKeys,
+		reqRateLimit: cfg.ReqRateLimit,
+		xpubs:        newXpubCache(),
 	}
 }
 
@@ -899,6 +998,99 @@ func (c *appContext) getTxTicketInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, tinfo, m.GetIndentCtx(r))
 }
 
+// maxTicketsPerRequest is the maximum number of tickets of a batch ticket info
+// request or ticket set, which is the maximum size of a ticket set.
+const maxTicketsPerRequest = stakedb.MaxTicketSetSize
+
+// ticketHashStrings checks the number of tickets of a batch request, writing
+// the error response if there are too many, and returns the ticket hash
+// strings.
+func ticketHashStrings(w http.ResponseWriter, txids []*chainhash.Hash) ([]string, bool) {
+	if len(txids) > maxTicketsPerRequest {
+		http.Error(w, fmt.Sprintf("at most %d tickets are allowed", maxTicketsPerRequest),
+			http.StatusBadRequest)
+		return nil, false
+	}
+	tickets := make([]string, 0, len(txids))
+	for _, txid := range txids {
+		tickets = append(tickets, txid.String())
+	}
+	return tickets, true
+}
+
+// getTicketsInfo serves the ticket info of each ticket of the batch, by ticket
+// hash. Tickets that are not found are omitted.
+func (c *appContext) getTicketsInfo(w http.ResponseWriter, r *http.Request) {
+	txids, err := m.GetTxnsCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	tickets, ok := ticketHashStrings(w, txids)
+	if !ok {
+		return
+	}
+
+	tinfos, err := c.DataSource.GetTicketsInfo(tickets)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("GetTicketsInfo: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("GetTicketsInfo: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, tinfos, m.GetIndentCtx(r))
+}
+
+// registerTicketSet registers the tickets of the request as a ticket set, and
+// serves the ticket set with the current status of each ticket.
+func (c *appContext) registerTicketSet(w http.ResponseWriter, r *http.Request) {
+	if c.TicketSets == nil {
+		http.Error(w, "Ticket sets are not available.", http.StatusServiceUnavailable)
+		return
+	}
+	txids, err := m.GetTxnsCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	tickets, ok := ticketHashStrings(w, txids)
+	if !ok {
+		return
+	}
+
+	set, err := c.TicketSets.Register(tickets)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Register(ticket set): %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Register(ticket set): %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, set, m.GetIndentCtx(r))
+}
+
+// getTicketSet serves the registered ticket set with the current status of
+// each ticket.
+func (c *appContext) getTicketSet(w http.ResponseWriter, r *http.Request) {
+	if c.TicketSets == nil {
+		http.Error(w, "Ticket sets are not available.", http.StatusServiceUnavailable)
+		return
+	}
+	set := c.TicketSets.TicketSet(m.GetTicketSetIDCtx(r))
+	if set == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, set, m.GetIndentCtx(r))
+}
+
 // getTransactionInputs serves []TxIn
 func (c *appContext) getTransactionInputs(w http.ResponseWriter, r *http.Request) {
 	txid, err := m.GetTxIDCtx(r)
@@ -1060,6 +1252,18 @@ func (c *appContext) getPowerlessTickets(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, tickets, m.GetIndentCtx(r))
 }
 
+// getVSPStats writes the ticket statistics of the VSPs as of the last
+// refresh.
+func (c *appContext) getVSPStats(w http.ResponseWriter, r *http.Request) {
+	stats := c.DataSource.VSPStats()
+	if stats == nil {
+		http.Error(w, "VSP statistics are being computed.", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeJSON(w, stats, m.GetIndentCtx(r))
+}
+
 func (c *appContext) getStakeDiffCurrent(w http.ResponseWriter, r *http.Request) {
 	stakeDiff := c.DataSource.GetStakeDiffEstimates()
 	if stakeDiff == nil {
@@ -1087,231 +1291,879 @@ func (c *appContext) getStakeDiffEstimates(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, stakeDiff.Estimates, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getSSTxSummary(w http.ResponseWriter, r *http.Request) {
-	sstxSummary := c.DataSource.GetMempoolSSTxSummary()
-	if sstxSummary == nil {
-		apiLog.Errorf("Unable to get SSTx info from mempool")
-		http.Error(w, http.StatusText(422), 422)
-		return
+// mempoolFeeRateBinEdges are the lower bounds, in DCR/kB, of the bins of the
+// mempool fee rate histogram. The last bin has no upper bound.
+var mempoolFeeRateBinEdges = []float64{0, 0.0001, 0.0002, 0.0005, 0.001, 0.002, 0.005, 0.01}
+
+// mempoolTxLists returns the transaction lists of the mempool inventory keyed
+// by the type names accepted by the /mempool/txs/{txtype} endpoint. The caller
+// must hold the inventory's read lock while using the returned slices.
+func mempoolTxLists(inv *exptypes.MempoolInfo) map[string][]exptypes.MempoolTx {
+	return map[string][]exptypes.MempoolTx{
+		"regular": inv.Transactions,
+		"tickets": inv.Tickets,
+		"votes":   inv.Votes,
+		"revokes": inv.Revocations,
+		"tspends": inv.TSpends,
+		"tadds":   inv.TAdds,
+	}
+}
+
+func summarizeMempoolTxs(txs []exptypes.MempoolTx) (sum apitypes.MempoolTypeSummary) {
+	sum.Count = len(txs)
+	for i := range txs {
+		sum.Size += txs[i].Size
+		sum.Total += txs[i].TotalOut
+		sum.Fees += txs[i].Fees
+	}
+	return
+}
+
+// makeMempoolOverview summarizes the mempool inventory. Votes are excluded
+// from the fee rate histogram since they pay no fees.
+func makeMempoolOverview(inv *exptypes.MempoolInfo) *apitypes.MempoolOverview {
+	inv.RLock()
+	defer inv.RUnlock()
+
+	lm := &inv.LikelyMineable
+	overview := &apitypes.MempoolOverview{
+		LastBlockHeight: inv.LastBlockHeight,
+		LastBlockHash:   inv.LastBlockHash,
+		LastBlockTime:   inv.LastBlockTime,
+		Time:            inv.Time,
+		Count:           inv.NumAll,
+		Size:            inv.TotalSize,
+		Total:           inv.TotalOut,
+		Regular:         summarizeMempoolTxs(inv.Transactions),
+		Tickets:         summarizeMempoolTxs(inv.Tickets),
+		Votes:           summarizeMempoolTxs(inv.Votes),
+		Revokes:         summarizeMempoolTxs(inv.Revocations),
+		TSpends:         summarizeMempoolTxs(inv.TSpends),
+		TAdds:           summarizeMempoolTxs(inv.TAdds),
+		LikelyMined: apitypes.MempoolLikelyMined{
+			Count:        lm.Count,
+			Size:         lm.Size,
+			Total:        lm.Total,
+			RegularTotal: lm.RegularTotal,
+			TicketTotal:  lm.TicketTotal,
+			VoteTotal:    lm.VoteTotal,
+			RevokeTotal:  lm.RevokeTotal,
+			TSpendTotal:  lm.TSpendTotal,
+			TAddTotal:    lm.TAddTotal,
+		},
+	}
+	overview.Fees = overview.Regular.Fees + overview.Tickets.Fees + overview.Votes.Fees +
+		overview.Revokes.Fees + overview.TSpends.Fees + overview.TAdds.Fees
+
+	bins := make([]apitypes.FeeRateBin, len(mempoolFeeRateBinEdges))
+	for i, edge := range mempoolFeeRateBinEdges {
+		bins[i].MinFeeRate = edge
+		if i+1 < len(mempoolFeeRateBinEdges) {
+			bins[i].MaxFeeRate = mempoolFeeRateBinEdges[i+1]
+		}
+	}
+	for _, txs := range [][]exptypes.MempoolTx{inv.Transactions, inv.Tickets,
+		inv.Revocations, inv.TSpends, inv.TAdds} {
+		for i := range txs {
+			// Find the last bin with a lower bound not exceeding the fee rate.
+			b := sort.SearchFloat64s(mempoolFeeRateBinEdges, txs[i].FeeRate)
+			if b == len(mempoolFeeRateBinEdges) || mempoolFeeRateBinEdges[b] != txs[i].FeeRate {
+				b--
+			}
+			if b < 0 {
+				b = 0
+			}
+			bins[b].Count++
+			bins[b].Size += txs[i].Size
+		}
 	}
+	overview.FeeRateHistogram = bins
 
-	writeJSON(w, sstxSummary, m.GetIndentCtx(r))
+	return overview
 }
 
-func (c *appContext) getSSTxFees(w http.ResponseWriter, r *http.Request) {
-	N := m.GetNCtx(r)
-	sstxFees := c.DataSource.GetMempoolSSTxFeeRates(N)
-	if sstxFees == nil {
-		apiLog.Errorf("Unable to get SSTx fees from mempool")
-		http.Error(w, http.StatusText(422), 422)
+func (c *appContext) getMempoolOverview(w http.ResponseWriter, r *http.Request) {
+	inv := c.Mempool.MempoolInventory()
+	if inv == nil {
+		apiLog.Errorf("Unable to get mempool inventory")
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
 
-	writeJSON(w, sstxFees, m.GetIndentCtx(r))
+	writeJSON(w, makeMempoolOverview(inv), m.GetIndentCtx(r))
 }
 
-func (c *appContext) getSSTxDetails(w http.ResponseWriter, r *http.Request) {
-	N := m.GetNCtx(r)
-	sstxDetails := c.DataSource.GetMempoolSSTxDetails(N)
-	if sstxDetails == nil {
-		apiLog.Errorf("Unable to get SSTx details from mempool")
-		http.Error(w, http.StatusText(422), 422)
-		return
-	}
+func (c *appContext) getMempoolTxs(w http.ResponseWriter, r *http.Request) {
+	txType := m.GetTxTypeCtx(r)
 
-	writeJSON(w, sstxDetails, m.GetIndentCtx(r))
-}
+	count := int64(m.GetNCtx(r))
+	skip := int64(m.GetMCtx(r))
+	if count <= 0 {
+		count = 100
+	} else if count > 1000 {
+		count = 1000
+	}
+	if skip <= 0 {
+		skip = 0
+	}
 
-// getTicketPoolCharts pulls the initial data to populate the /ticketpool page
-// charts.
-func (c *appContext) getTicketPoolCharts(w http.ResponseWriter, r *http.Request) {
-	timeChart, priceChart, outputsChart, height, err := c.DataSource.TicketPoolVisualization(dbtypes.AllGrouping)
-	if dbtypes.IsTimeoutErr(err) {
-		apiLog.Errorf("TicketPoolVisualization: %v", err)
-		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+	inv := c.Mempool.MempoolInventory()
+	if inv == nil {
+		apiLog.Errorf("Unable to get mempool inventory")
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 		return
 	}
-	if err != nil {
-		apiLog.Errorf("Unable to get ticket pool charts: %v", err)
-		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
-		return
+
+	inv.RLock()
+	txs, ok := mempoolTxLists(inv)[txType]
+	if !ok {
+		inv.RUnlock()
+		http.Error(w, "invalid transaction type", http.StatusUnprocessableEntity)
+		return
+	}
+
+	page := &apitypes.MempoolTxs{
+		Type:         txType,
+		Total:        len(txs),
+		Transactions: []apitypes.MempoolTxInfo{},
+	}
+	for i := skip; i < int64(len(txs)) && i < skip+count; i++ {
+		tx := &txs[i]
+		page.Transactions = append(page.Transactions, apitypes.MempoolTxInfo{
+			TxID:      tx.TxID,
+			Type:      tx.Type,
+			Time:      tx.Time,
+			Size:      tx.Size,
+			Fees:      tx.Fees,
+			FeeRate:   tx.FeeRate,
+			TotalOut:  tx.TotalOut,
+			VinCount:  tx.VinCount,
+			VoutCount: tx.VoutCount,
+		})
 	}
+	inv.RUnlock()
 
-	mp := c.DataSource.GetMempoolPriceCountTime()
+	writeJSON(w, page, m.GetIndentCtx(r))
+}
 
-	response := &apitypes.TicketPoolChartsData{
-		ChartHeight:  uint64(height),
-		TimeChart:    timeChart,
-		PriceChart:   priceChart,
-		OutputsChart: outputsChart,
-		Mempool:      mp,
+func (c *appContext) getFeeEstimates(w http.ResponseWriter, r *http.Request) {
+	estimates := c.FeeEstimator.FeeEstimates()
+	if estimates == nil {
+		apiLog.Errorf("Fee estimates are not yet available")
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
 	}
 
-	writeJSON(w, response, m.GetIndentCtx(r))
+	writeJSON(w, estimates, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getTicketPoolByDate(w http.ResponseWriter, r *http.Request) {
-	tp := m.GetTpCtx(r)
-	// default to day if no grouping was sent
-	if tp == "" {
-		tp = "day"
+// maxBroadcastRequestSize is the maximum size of a transaction broadcast
+// request body, which allows for the hex encoding of a standard transaction.
+const maxBroadcastRequestSize = 2*txhelpers.MaxStandardTxSize + 1024
+
+// broadcastTx validates and relays the hex-encoded transaction in the request
+// body. A rejected transaction has the 422 status code, with the reason in the
+// response.
+func (c *appContext) broadcastTx(w http.ResponseWriter, r *http.Request) {
+	var req apitypes.TxBroadcastRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBroadcastRequestSize)).Decode(&req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse request: %v", err), http.StatusBadRequest)
+		return
 	}
 
-	// The db queries are fast enough that it makes sense to call
-	// TicketPoolVisualization here even though it returns a lot of data not
-	// needed by this request.
-	interval := dbtypes.TimeGroupingFromStr(tp)
-	timeChart, _, _, height, err := c.DataSource.TicketPoolVisualization(interval)
+	res, err := c.Broadcaster.Broadcast(req.Hex)
 	if dbtypes.IsTimeoutErr(err) {
-		apiLog.Errorf("TicketPoolVisualization: %v", err)
+		apiLog.Errorf("Broadcast: %v", err)
 		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
 	if err != nil {
-		apiLog.Errorf("Unable to get ticket pool by date: %v", err)
-		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		apiLog.Errorf("Broadcast: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	tpResponse := struct {
-		Height    int64                    `json:"height"`
-		TimeChart *dbtypes.PoolTicketsData `json:"time_chart"`
-	}{
-		height,
-		timeChart, // purchase time distribution
+	code := http.StatusOK
+	if res.Status == dbtypes.TxBroadcastRejected {
+		code = http.StatusUnprocessableEntity
 	}
-
-	writeJSON(w, tpResponse, m.GetIndentCtx(r))
+	writeJSONWithStatus(w, res, code, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getProposalChartData(w http.ResponseWriter, r *http.Request) {
-	if c.isPiDisabled {
-		errMsg := "piparser is disabled."
-		apiLog.Errorf("%s. Remove the disable-piparser flag to activate it.", errMsg)
-		http.Error(w, errMsg, http.StatusServiceUnavailable)
+// getTxBroadcast writes the status of a transaction relayed by broadcastTx.
+func (c *appContext) getTxBroadcast(w http.ResponseWriter, r *http.Request) {
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
 		return
 	}
 
-	token := m.GetProposalTokenCtx(r)
-	votesData, err := c.DataSource.ProposalVotes(token)
+	res, err := c.Broadcaster.BroadcastStatus(txid.String())
 	if dbtypes.IsTimeoutErr(err) {
-		apiLog.Errorf("ProposalVotes: %v", err)
+		apiLog.Errorf("BroadcastStatus: %v", err)
 		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
 	if err != nil {
-		apiLog.Errorf("Unable to get proposals votes for token %s : %v", token, err)
-		http.Error(w, http.StatusText(http.StatusUnprocessableEntity),
-			http.StatusUnprocessableEntity)
+		apiLog.Errorf("BroadcastStatus: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if res == nil {
+		http.Error(w, "transaction was not broadcast", http.StatusNotFound)
 		return
 	}
 
-	writeJSON(w, votesData, m.GetIndentCtx(r))
+	writeJSON(w, res, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getBlockSize(w http.ResponseWriter, r *http.Request) {
-	idx, err := c.getBlockHeightCtx(r)
-	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
-		return
+// maxWebhookRequestSize is the maximum size of a webhook registration request
+// body, which allows for webhook.MaxAddresses addresses.
+const maxWebhookRequestSize = 1 << 16
+
+// webhooksEnabled writes an error response and returns false if the webhooks
+// API is not enabled.
+func (c *appContext) webhooksEnabled(w http.ResponseWriter) bool {
+	if c.Webhooks == nil {
+		http.Error(w, "webhooks are not enabled", http.StatusServiceUnavailable)
+		return false
 	}
+	return true
+}
 
-	blockSize, err := c.DataSource.GetBlockSize(int(idx))
+// getWebhookCtx retrieves the webhook with the ID in the request context,
+// writing an error response if it cannot be found.
+func (c *appContext) getWebhookCtx(w http.ResponseWriter, r *http.Request) *dbtypes.Webhook {
+	wh, err := c.Webhooks.Webhook(m.GetWebhookIDCtx(r))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("Webhook: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return nil
+	}
 	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
-		return
+		apiLog.Errorf("Webhook: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
 	}
-
-	writeJSON(w, blockSize, "")
+	if wh == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return nil
+	}
+	return wh
 }
 
-func (c *appContext) blockSubsidies(w http.ResponseWriter, r *http.Request) {
-	idx, err := c.getBlockHeightCtx(r)
-	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+// createWebhook registers a webhook for the addresses and URL in the JSON
+// request body. The response includes the webhook's ID and its secret for
+// verifying the signature of the deliveries. The secret is not retrievable
+// later.
+func (c *appContext) createWebhook(w http.ResponseWriter, r *http.Request) {
+	if !c.webhooksEnabled(w) {
 		return
 	}
-	hash, err := c.getBlockHashCtx(r)
+
+	var req apitypes.WebhookRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookRequestSize)).Decode(&req)
 	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+		http.Error(w, fmt.Sprintf("failed to parse request: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Unless this is a mined block, assume all votes.
-	numVotes := int16(c.Params.TicketsPerBlock)
-	if hash != "" {
-		var err error
-		numVotes, err = c.DataSource.VotesInBlock(hash)
-		if dbtypes.IsTimeoutErr(err) {
-			apiLog.Errorf("VotesInBlock: %v", err)
-			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
-			return
+	// Remove duplicate addresses, and check them for this network.
+	addrs := make([]string, 0, len(req.Addresses))
+	seen := make(map[string]struct{}, len(req.Addresses))
+	for _, addr := range req.Addresses {
+		if _, found := seen[addr]; found {
+			continue
 		}
-		if err != nil {
-			http.NotFound(w, r)
+		if _, err = dcrutil.DecodeAddress(addr, c.Params); err != nil {
+			http.Error(w, fmt.Sprintf("invalid address '%v' for this network: %v",
+				addr, err), http.StatusUnprocessableEntity)
 			return
 		}
+		seen[addr] = struct{}{}
+		addrs = append(addrs, addr)
 	}
+	req.Addresses = addrs
 
-	work, stake, tax := txhelpers.RewardsAtBlock(idx, uint16(numVotes), c.Params)
-	rewards := apitypes.BlockSubsidies{
-		BlockNum:   idx,
-		BlockHash:  hash,
-		Work:       work,
-		Stake:      stake,
-		NumVotes:   numVotes,
-		TotalStake: stake * int64(numVotes),
-		Tax:        tax,
-		Total:      work + stake*int64(numVotes) + tax,
-	}
-
-	writeJSON(w, rewards, m.GetIndentCtx(r))
-}
-
-func (c *appContext) getBlockRangeSize(w http.ResponseWriter, r *http.Request) {
-	idx0 := m.GetBlockIndex0Ctx(r)
-	if idx0 < 0 {
-		http.Error(w, http.StatusText(422), 422)
+	wh, err := webhook.NewWebhook(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	idx := m.GetBlockIndexCtx(r)
-	if idx < 0 || idx < idx0 {
-		http.Error(w, http.StatusText(422), 422)
+	err = c.Webhooks.CreateWebhook(wh)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("CreateWebhook: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
-
-	blockSizes, err := c.DataSource.GetBlockSizeRange(idx0, idx)
 	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+		apiLog.Errorf("CreateWebhook: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	apiLog.Debugf("Created webhook %s for %d addresses.", wh.ID, len(wh.Addresses))
 
-	writeJSON(w, blockSizes, "")
+	writeJSONWithStatus(w, wh, http.StatusCreated, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getBlockRangeSteppedSize(w http.ResponseWriter, r *http.Request) {
-	idx0 := m.GetBlockIndex0Ctx(r)
-	if idx0 < 0 {
-		http.Error(w, http.StatusText(422), 422)
+func (c *appContext) getWebhook(w http.ResponseWriter, r *http.Request) {
+	if !c.webhooksEnabled(w) {
 		return
 	}
-
-	idx := m.GetBlockIndexCtx(r)
-	if idx < 0 || idx < idx0 {
-		http.Error(w, http.StatusText(422), 422)
+	wh := c.getWebhookCtx(w, r)
+	if wh == nil {
 		return
 	}
+	writeJSON(w, wh, m.GetIndentCtx(r))
+}
 
-	step := m.GetBlockStepCtx(r)
-	if step <= 0 {
-		http.Error(w, "Yeaaah, that step's not gonna work with me.", 422)
+func (c *appContext) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if !c.webhooksEnabled(w) {
 		return
 	}
-
-	blockSizesFull, err := c.DataSource.GetBlockSizeRange(idx0, idx)
-	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+	found, err := c.Webhooks.DeleteWebhook(m.GetWebhookIDCtx(r))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("DeleteWebhook: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("DeleteWebhook: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *appContext) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !c.webhooksEnabled(w) {
+		return
+	}
+	wh := c.getWebhookCtx(w, r)
+	if wh == nil {
+		return
+	}
+
+	count, skip := listPageCtx(r)
+	deliveries, total, err := c.Webhooks.WebhookDeliveries(wh.ID, count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("WebhookDeliveries: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("WebhookDeliveries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &apitypes.WebhookDeliveries{
+		WebhookID:  wh.ID,
+		Total:      total,
+		Deliveries: deliveries,
+	}, m.GetIndentCtx(r))
+}
+
+// replayWebhookDeliveries schedules the webhook's deliveries created since the
+// UNIX time in the "since" URL query (default 0, all deliveries) to be sent
+// again, regardless of their delivery state.
+func (c *appContext) replayWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !c.webhooksEnabled(w) {
+		return
+	}
+
+	var since int64
+	if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+		var err error
+		since, err = strconv.ParseInt(sinceParam, 10, 64)
+		if err != nil || since < 0 {
+			http.Error(w, "invalid since time", http.StatusBadRequest)
+			return
+		}
+	}
+
+	wh := c.getWebhookCtx(w, r)
+	if wh == nil {
+		return
+	}
+
+	replayed, err := c.Webhooks.ReplayWebhookDeliveries(wh.ID, time.Unix(since, 0))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ReplayWebhookDeliveries: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("ReplayWebhookDeliveries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &apitypes.WebhookReplay{
+		WebhookID: wh.ID,
+		Since:     since,
+		Replayed:  replayed,
+	}, m.GetIndentCtx(r))
+}
+
+// maxAPIKeyRequestSize is the maximum size of a request body to issue an API
+// key.
+const maxAPIKeyRequestSize = 1 << 12
+
+// apiKeysEnabled writes an error response and returns false if the API keys
+// are not enabled.
+func (c *appContext) apiKeysEnabled(w http.ResponseWriter) bool {
+	if c.apiKeys == nil {
+		http.Error(w, "API keys are not enabled", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// getAPIKeys lists the issued API keys with their usage in the month given by
+// the "month" URL query in YYYY-MM format, the current month by default.
+func (c *appContext) getAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if !c.apiKeysEnabled(w) {
+		return
+	}
+
+	month := time.Now().UTC()
+	if monthParam := r.URL.Query().Get("month"); monthParam != "" {
+		var err error
+		month, err = time.Parse("2006-01", monthParam)
+		if err != nil {
+			http.Error(w, "invalid month", http.StatusBadRequest)
+			return
+		}
+	}
+
+	keys, err := c.apiKeys.APIKeys(month)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("APIKeys: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("APIKeys: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []*dbtypes.APIKey{}
+	}
+
+	writeJSON(w, &apitypes.APIKeys{
+		Month: month.Format("2006-01"),
+		Keys:  keys,
+	}, m.GetIndentCtx(r))
+}
+
+// createAPIKey issues an API key with the rate limit and quota in the JSON
+// request body. The response includes the key, which is not retrievable later.
+func (c *appContext) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	if !c.apiKeysEnabled(w) {
+		return
+	}
+
+	var req apitypes.APIKeyRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIKeyRequestSize)).Decode(&req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	key, err := m.NewAPIKey(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = c.apiKeys.CreateAPIKey(key)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("CreateAPIKey: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("CreateAPIKey: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	apiLog.Infof("Issued API key %s (%s).", key.ID, key.Name)
+
+	writeJSONWithStatus(w, key, http.StatusCreated, m.GetIndentCtx(r))
+}
+
+func (c *appContext) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if !c.apiKeysEnabled(w) {
+		return
+	}
+	id := m.GetAPIKeyIDCtx(r)
+	found, err := c.apiKeys.RevokeAPIKey(id)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("RevokeAPIKey: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("RevokeAPIKey: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	apiLog.Infof("Revoked API key %s.", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *appContext) getSSTxSummary(w http.ResponseWriter, r *http.Request) {
+	sstxSummary := c.DataSource.GetMempoolSSTxSummary()
+	if sstxSummary == nil {
+		apiLog.Errorf("Unable to get SSTx info from mempool")
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, sstxSummary, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getSSTxFees(w http.ResponseWriter, r *http.Request) {
+	N := m.GetNCtx(r)
+	sstxFees := c.DataSource.GetMempoolSSTxFeeRates(N)
+	if sstxFees == nil {
+		apiLog.Errorf("Unable to get SSTx fees from mempool")
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, sstxFees, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getSSTxDetails(w http.ResponseWriter, r *http.Request) {
+	N := m.GetNCtx(r)
+	sstxDetails := c.DataSource.GetMempoolSSTxDetails(N)
+	if sstxDetails == nil {
+		apiLog.Errorf("Unable to get SSTx details from mempool")
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, sstxDetails, m.GetIndentCtx(r))
+}
+
+// getTicketPoolCharts pulls the initial data to populate the /ticketpool page
+// charts.
+func (c *appContext) getTicketPoolCharts(w http.ResponseWriter, r *http.Request) {
+	timeChart, priceChart, outputsChart, height, err := c.DataSource.TicketPoolVisualization(dbtypes.AllGrouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketPoolVisualization: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get ticket pool charts: %v", err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	mp := c.DataSource.GetMempoolPriceCountTime()
+
+	response := &apitypes.TicketPoolChartsData{
+		ChartHeight:  uint64(height),
+		TimeChart:    timeChart,
+		PriceChart:   priceChart,
+		OutputsChart: outputsChart,
+		Mempool:      mp,
+	}
+
+	writeJSON(w, response, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolByDate(w http.ResponseWriter, r *http.Request) {
+	tp := m.GetTpCtx(r)
+	// default to day if no grouping was sent
+	if tp == "" {
+		tp = "day"
+	}
+
+	// The db queries are fast enough that it makes sense to call
+	// TicketPoolVisualization here even though it returns a lot of data not
+	// needed by this request.
+	interval := dbtypes.TimeGroupingFromStr(tp)
+	timeChart, _, _, height, err := c.DataSource.TicketPoolVisualization(interval)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketPoolVisualization: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get ticket pool by date: %v", err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+		return
+	}
+
+	tpResponse := &apitypes.TicketPoolByDate{
+		Height:    height,
+		TimeChart: timeChart, // purchase time distribution
+	}
+
+	writeJSON(w, tpResponse, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getProposalChartData(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		errMsg := "piparser is disabled."
+		apiLog.Errorf("%s. Remove the disable-piparser flag to activate it.", errMsg)
+		http.Error(w, errMsg, http.StatusServiceUnavailable)
+		return
+	}
+
+	token := m.GetProposalTokenCtx(r)
+	votesData, err := c.DataSource.ProposalVotes(token)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ProposalVotes: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get proposals votes for token %s : %v", token, err)
+		http.Error(w, http.StatusText(http.StatusUnprocessableEntity),
+			http.StatusUnprocessableEntity)
+		return
+	}
+
+	writeJSON(w, votesData, m.GetIndentCtx(r))
+}
+
+// getProposalHistory serves the versions, status changes and hourly vote
+// tallies of the proposal polled from Politeia.
+func (c *appContext) getProposalHistory(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		http.Error(w, "piparser is disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	token := m.GetProposalTokenCtx(r)
+	history, err := c.DataSource.ProposalHistory(token)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ProposalHistory: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the history of proposal %s: %v", token, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, history, m.GetIndentCtx(r))
+}
+
+// getProposalVoters serves the participation in the vote on the proposal, with
+// a page of the tickets that voted on it.
+func (c *appContext) getProposalVoters(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		http.Error(w, "piparser is disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	count := int64(m.GetNCtx(r))
+	skip := int64(m.GetMCtx(r))
+	if count <= 0 {
+		count = 100
+	} else if count > 1000 {
+		count = 1000
+	}
+	if skip <= 0 {
+		skip = 0
+	}
+
+	token := m.GetProposalTokenCtx(r)
+	voters, err := c.DataSource.ProposalVoters(token, count, skip)
+	if err == sql.ErrNoRows {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("ProposalVoters: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("Unable to get the voters of proposal %s: %v", token, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, voters, m.GetIndentCtx(r))
+}
+
+// getTicketProposalVotes serves the votes of the ticket on the proposals.
+func (c *appContext) getTicketProposalVotes(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		http.Error(w, "piparser is disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	txid, err := m.GetTxIDCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	votes, err := c.DataSource.TicketProposalVotes(txid.String())
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TicketProposalVotes: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("TicketProposalVotes: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, votes, m.GetIndentCtx(r))
+}
+
+// getAddressProposalVotes serves the votes on the proposals of the tickets of
+// the address.
+func (c *appContext) getAddressProposalVotes(w http.ResponseWriter, r *http.Request) {
+	if c.isPiDisabled {
+		http.Error(w, "piparser is disabled.", http.StatusServiceUnavailable)
+		return
+	}
+
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	votes, err := c.DataSource.AddressProposalVotes(addresses[0])
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressProposalVotes: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AddressProposalVotes: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, votes, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getBlockSize(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	blockSize, err := c.DataSource.GetBlockSize(int(idx))
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, blockSize, "")
+}
+
+func (c *appContext) blockSubsidies(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	hash, err := c.getBlockHashCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	// Unless this is a mined block, assume all votes.
+	numVotes := int16(c.Params.TicketsPerBlock)
+	if hash != "" {
+		var err error
+		numVotes, err = c.DataSource.VotesInBlock(hash)
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("VotesInBlock: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	work, stake, tax := txhelpers.RewardsAtBlock(idx, uint16(numVotes), c.Params)
+	rewards := apitypes.BlockSubsidies{
+		BlockNum:   idx,
+		BlockHash:  hash,
+		Work:       work,
+		Stake:      stake,
+		NumVotes:   numVotes,
+		TotalStake: stake * int64(numVotes),
+		Tax:        tax,
+		Total:      work + stake*int64(numVotes) + tax,
+	}
+
+	writeJSON(w, rewards, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getBlockRangeSize(w http.ResponseWriter, r *http.Request) {
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < 0 || idx < idx0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	blockSizes, err := c.DataSource.GetBlockSizeRange(idx0, idx)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, blockSizes, "")
+}
+
+func (c *appContext) getBlockRangeSteppedSize(w http.ResponseWriter, r *http.Request) {
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < 0 || idx < idx0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	step := m.GetBlockStepCtx(r)
+	if step <= 0 {
+		http.Error(w, "Yeaaah, that step's not gonna work with me.", 422)
+		return
+	}
+
+	blockSizesFull, err := c.DataSource.GetBlockSizeRange(idx0, idx)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
@@ -1400,145 +2252,449 @@ func (c *appContext) getTicketPool(w http.ResponseWriter, r *http.Request) {
 		sortPool = val
 	}
 
-	// getBlockHeightCtx falls back to try hash if height fails
-	idx, err := c.getBlockHeightCtx(r)
+	// getBlockHeightCtx falls back to try hash if height fails
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	tp, err := c.DataSource.GetPool(idx)
+	if err != nil {
+		apiLog.Errorf("Unable to fetch ticket pool: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	if sortPool {
+		sort.Strings(tp)
+	}
+	writeJSON(w, tp, m.GetIndentCtx(r))
+}
+
+// getBlockMisses writes the missed votes of the block with their cause.
+func (c *appContext) getBlockMisses(w http.ResponseWriter, r *http.Request) {
+	hash, err := c.getBlockHashCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	misses, err := c.DataSource.BlockMissCauses(hash)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("BlockMissCauses: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("BlockMissCauses: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, dbtypes.NewMissReport(misses), m.GetIndentCtx(r))
+}
+
+// getAddressMisses writes the most recent missed votes of the tickets with the
+// address as the stake submission or a commitment address, with their cause.
+func (c *appContext) getAddressMisses(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	misses, err := c.DataSource.AddressMissCauses(addresses[0])
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressMissCauses: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("AddressMissCauses: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, dbtypes.NewMissReport(misses), m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolInfo(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	tpi := c.DataSource.GetPoolInfo(int(idx))
+	writeJSON(w, tpi, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolInfoRange(w http.ResponseWriter, r *http.Request) {
+	if useArray := r.URL.Query().Get("arrays"); useArray != "" {
+		_, err := strconv.ParseBool(useArray)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		c.getTicketPoolValAndSizeRange(w, r)
+		return
+	}
+
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	tpis := c.DataSource.GetPoolInfoRange(idx0, idx)
+	if tpis == nil {
+		http.Error(w, "invalid range", http.StatusUnprocessableEntity)
+		return
+	}
+	writeJSON(w, tpis, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getTicketPoolValAndSizeRange(w http.ResponseWriter, r *http.Request) {
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	pvs, pss := c.DataSource.GetPoolValAndSizeRange(idx0, idx)
+	if pvs == nil || pss == nil {
+		http.Error(w, "invalid range", http.StatusUnprocessableEntity)
+		return
+	}
+
+	tPVS := apitypes.TicketPoolValsAndSizes{
+		StartHeight: uint32(idx0),
+		EndHeight:   uint32(idx),
+		Value:       pvs,
+		Size:        pss,
+	}
+	writeJSON(w, tPVS, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getStakeDiff(w http.ResponseWriter, r *http.Request) {
+	idx, err := c.getBlockHeightCtx(r)
+	if err != nil {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	sdiff := c.DataSource.GetSDiff(int(idx))
+	writeJSON(w, []float64{sdiff}, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getStakeDiffRange(w http.ResponseWriter, r *http.Request) {
+	idx0 := m.GetBlockIndex0Ctx(r)
+	if idx0 < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	idx := m.GetBlockIndexCtx(r)
+	if idx < 0 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	sdiffs := c.DataSource.GetSDiffRange(idx0, idx)
+	writeJSON(w, sdiffs, m.GetIndentCtx(r))
+}
+
+// maxStakeSimProjection is the maximum time after the current time that a
+// staking simulation may end.
+const maxStakeSimProjection = 5 * 365 * 24 * time.Hour
+
+// simulateStaking replays staking the DCR amount in the "amount" URL query
+// from the date in the "start" URL query, one year ago by default, to the date
+// in the "end" URL query, one year after the start by default. The rewards are
+// used to buy tickets if the "reinvest" URL query is true. The ticket
+// prices after the best block are the current and expected stake difficulty.
+func (c *appContext) simulateStaking(w http.ResponseWriter, r *http.Request) {
+	const dateLayout = "2006-01-02"
+	query := r.URL.Query()
+	amountDCR, err := strconv.ParseFloat(query.Get("amount"), 64)
+	if err != nil || amountDCR <= 0 {
+		http.Error(w, "invalid amount", http.StatusBadRequest)
+		return
+	}
+	amount, err := dcrutil.NewAmount(amountDCR)
+	if err != nil {
+		http.Error(w, "invalid amount", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	start := now.AddDate(-1, 0, 0)
+	if startParam := query.Get("start"); startParam != "" {
+		start, err = time.Parse(dateLayout, startParam)
+		if err != nil {
+			http.Error(w, "invalid start date", http.StatusBadRequest)
+			return
+		}
+	}
+	end := start.AddDate(1, 0, 0)
+	if endParam := query.Get("end"); endParam != "" {
+		end, err = time.Parse(dateLayout, endParam)
+		if err != nil {
+			http.Error(w, "invalid end date", http.StatusBadRequest)
+			return
+		}
+	}
+	if !end.After(start) || end.After(now.Add(maxStakeSimProjection)) {
+		http.Error(w, "invalid end date", http.StatusBadRequest)
+		return
+	}
+
+	var reinvest bool
+	if reinvestParam := query.Get("reinvest"); reinvestParam != "" {
+		reinvest, err = strconv.ParseBool(reinvestParam)
+		if err != nil {
+			http.Error(w, "invalid reinvest", http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeErr := func(name string, err error) {
+		apiLog.Errorf("%s: %v", name, err)
+		if dbtypes.IsTimeoutErr(err) {
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	startHeight, err := c.DataSource.BlockHeightAtTime(start.Unix())
+	if err == sql.ErrNoRows {
+		http.Error(w, "no blocks after the start date", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeErr("BlockHeightAtTime", err)
+		return
+	}
+	best := c.DataSource.Height()
+	if startHeight >= best {
+		http.Error(w, "no blocks after the start date", http.StatusBadRequest)
+		return
+	}
+	endHeight := best
+	if endTime := end.Unix(); endTime < now.Unix() {
+		endHeight, err = c.DataSource.BlockHeightAtTime(endTime)
+		if err != nil && err != sql.ErrNoRows {
+			writeErr("BlockHeightAtTime", err)
+			return
+		}
+		if err != nil || endHeight > best {
+			endHeight = best
+		}
+	} else {
+		endHeight += int64(end.Sub(now) / c.Params.TargetTimePerBlock)
+	}
+
+	chain := &stakeSimChain{
+		params:      c.Params,
+		startHeight: startHeight,
+		sdiffs:      c.DataSource.GetSDiffRange(int(startHeight), int(best)),
+	}
+	_, chain.poolSizes = c.DataSource.GetPoolValAndSizeRange(int(startHeight), int(best))
+	if chain.sdiffs == nil || chain.poolSizes == nil {
+		writeErr("simulateStaking", fmt.Errorf("no stake difficulties or pool sizes from height %d", startHeight))
+		return
+	}
+	chain.currentSDiff = chain.sdiffs[len(chain.sdiffs)-1]
+	chain.expectedSDiff = chain.currentSDiff
+	if stakeDiff := c.DataSource.GetStakeDiffEstimates(); stakeDiff != nil {
+		chain.currentSDiff = stakeDiff.CurrentStakeDifficulty
+		chain.expectedSDiff = stakeDiff.Estimates.Expected
+	}
+
+	sim, err := simulateStaking(chain, amount, reinvest, endHeight)
 	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	tp, err := c.DataSource.GetPool(idx)
+	heights := []int64{best}
+	for _, e := range sim.Timeline {
+		if !e.Projected {
+			heights = append(heights, e.Height)
+		}
+	}
+	blockTimes, err := c.DataSource.BlockTimes(heights)
 	if err != nil {
-		apiLog.Errorf("Unable to fetch ticket pool: %v", err)
-		http.Error(w, http.StatusText(422), 422)
+		writeErr("BlockTimes", err)
 		return
 	}
+	setStakeSimTimes(sim, blockTimes, c.Params)
 
-	if sortPool {
-		sort.Strings(tp)
-	}
-	writeJSON(w, tp, m.GetIndentCtx(r))
+	writeJSON(w, sim, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getTicketPoolInfo(w http.ResponseWriter, r *http.Request) {
-	idx, err := c.getBlockHeightCtx(r)
-	if err != nil {
+func (c *appContext) addressTotals(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
-	tpi := c.DataSource.GetPoolInfo(int(idx))
-	writeJSON(w, tpi, m.GetIndentCtx(r))
-}
-
-func (c *appContext) getTicketPoolInfoRange(w http.ResponseWriter, r *http.Request) {
-	if useArray := r.URL.Query().Get("arrays"); useArray != "" {
-		_, err := strconv.ParseBool(useArray)
-		if err != nil {
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return
-		}
-		c.getTicketPoolValAndSizeRange(w, r)
+	address := addresses[0]
+	totals, err := c.DataSource.AddressTotals(address)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AddressTotals: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
-
-	idx0 := m.GetBlockIndex0Ctx(r)
-	if idx0 < 0 {
+	if err != nil {
+		log.Warnf("failed to get address totals (%s): %v", address, err)
 		http.Error(w, http.StatusText(422), 422)
 		return
 	}
 
-	idx := m.GetBlockIndexCtx(r)
-	if idx < 0 {
-		http.Error(w, http.StatusText(422), 422)
-		return
+	writeJSON(w, totals, m.GetIndentCtx(r))
+}
+
+// getRichList writes the top N addresses by balance, 100 by default, with the
+// distribution of the balances of all addresses.
+func (c *appContext) getRichList(w http.ResponseWriter, r *http.Request) {
+	n := int64(m.GetNCtx(r))
+	if n <= 0 {
+		n = 100
 	}
 
-	tpis := c.DataSource.GetPoolInfoRange(idx0, idx)
-	if tpis == nil {
-		http.Error(w, "invalid range", http.StatusUnprocessableEntity)
+	entries, dist, err := c.DataSource.RichList(n)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("RichList: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
-	writeJSON(w, tpis, m.GetIndentCtx(r))
-}
-
-func (c *appContext) getTicketPoolValAndSizeRange(w http.ResponseWriter, r *http.Request) {
-	idx0 := m.GetBlockIndex0Ctx(r)
-	if idx0 < 0 {
-		http.Error(w, http.StatusText(422), 422)
+	if err != nil {
+		apiLog.Errorf("RichList: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	if entries == nil {
+		entries = []*dbtypes.RichListEntry{}
+	}
 
-	idx := m.GetBlockIndexCtx(r)
-	if idx < 0 {
-		http.Error(w, http.StatusText(422), 422)
+	writeJSON(w, &apitypes.RichList{
+		Distribution: dist,
+		Addresses:    entries,
+	}, m.GetIndentCtx(r))
+}
+
+// maxAddressLabelRequestSize is the maximum size of a request to set the
+// label of an address.
+const maxAddressLabelRequestSize = 1 << 10
+
+func (c *appContext) getAddressLabels(w http.ResponseWriter, r *http.Request) {
+	category := r.URL.Query().Get("category")
+	if category != "" && !dbtypes.IsValidAddressLabelCategory(category) {
+		http.Error(w, fmt.Sprintf("invalid category %q", category), http.StatusUnprocessableEntity)
 		return
 	}
+	labels := c.DataSource.AllAddressLabels(category)
+	if labels == nil {
+		labels = []*dbtypes.AddressLabel{}
+	}
+	writeJSON(w, labels, m.GetIndentCtx(r))
+}
 
-	pvs, pss := c.DataSource.GetPoolValAndSizeRange(idx0, idx)
-	if pvs == nil || pss == nil {
-		http.Error(w, "invalid range", http.StatusUnprocessableEntity)
+func (c *appContext) getAddressLabel(w http.ResponseWriter, r *http.Request) {
+	address, err := m.GetAddressCtx(r, c.Params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
-
-	tPVS := apitypes.TicketPoolValsAndSizes{
-		StartHeight: uint32(idx0),
-		EndHeight:   uint32(idx),
-		Value:       pvs,
-		Size:        pss,
+	label := c.DataSource.AddressLabel(address[0])
+	if label == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
 	}
-	writeJSON(w, tPVS, m.GetIndentCtx(r))
+	writeJSON(w, label, m.GetIndentCtx(r))
 }
 
-func (c *appContext) getStakeDiff(w http.ResponseWriter, r *http.Request) {
-	idx, err := c.getBlockHeightCtx(r)
+// setAddressLabel sets the label of an address, replacing any label from the
+// labels file or a built-in label. It requires admin authentication.
+func (c *appContext) setAddressLabel(w http.ResponseWriter, r *http.Request) {
+	address, err := m.GetAddressCtx(r, c.Params)
 	if err != nil {
-		http.Error(w, http.StatusText(422), 422)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	sdiff := c.DataSource.GetSDiff(int(idx))
-	writeJSON(w, []float64{sdiff}, m.GetIndentCtx(r))
-}
+	var req apitypes.AddressLabelRequest
+	err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAddressLabelRequestSize)).Decode(&req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse request: %v", err), http.StatusBadRequest)
+		return
+	}
 
-func (c *appContext) getStakeDiffRange(w http.ResponseWriter, r *http.Request) {
-	idx0 := m.GetBlockIndex0Ctx(r)
-	if idx0 < 0 {
-		http.Error(w, http.StatusText(422), 422)
+	label := &dbtypes.AddressLabel{
+		Address:  address[0],
+		Label:    strings.TrimSpace(req.Label),
+		Category: req.Category,
+	}
+	if err = label.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	idx := m.GetBlockIndexCtx(r)
-	if idx < 0 {
-		http.Error(w, http.StatusText(422), 422)
+	err = c.DataSource.SetAddressLabel(label)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("SetAddressLabel: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Errorf("SetAddressLabel: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	apiLog.Infof("Set the label of address %s to %q (%s).", label.Address, label.Label, label.Category)
 
-	sdiffs := c.DataSource.GetSDiffRange(idx0, idx)
-	writeJSON(w, sdiffs, m.GetIndentCtx(r))
+	writeJSON(w, label, m.GetIndentCtx(r))
 }
 
-func (c *appContext) addressTotals(w http.ResponseWriter, r *http.Request) {
-	addresses, err := m.GetAddressCtx(r, c.Params)
-	if err != nil || len(addresses) > 1 {
-		http.Error(w, http.StatusText(422), 422)
+// deleteAddressLabel deletes the stored label of an address. It requires admin
+// authentication.
+func (c *appContext) deleteAddressLabel(w http.ResponseWriter, r *http.Request) {
+	address, err := m.GetAddressCtx(r, c.Params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
-
-	address := addresses[0]
-	totals, err := c.DataSource.AddressTotals(address)
+	found, err := c.DataSource.DeleteAddressLabel(address[0])
 	if dbtypes.IsTimeoutErr(err) {
-		apiLog.Errorf("AddressTotals: %v", err)
+		apiLog.Errorf("DeleteAddressLabel: %v", err)
 		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
 		return
 	}
 	if err != nil {
-		log.Warnf("failed to get address totals (%s): %v", address, err)
-		http.Error(w, http.StatusText(422), 422)
+		apiLog.Errorf("DeleteAddressLabel: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
-
-	writeJSON(w, totals, m.GetIndentCtx(r))
+	if !found {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	apiLog.Infof("Deleted the label of address %s.", address[0])
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // addressExists provides access to the existsaddresses RPC call and parses the
@@ -1658,6 +2814,66 @@ func (c *appContext) addressIoCsv(crlf bool, w http.ResponseWriter, r *http.Requ
 	}
 }
 
+func (c *appContext) treasuryPayeesCsvNoCR(w http.ResponseWriter, r *http.Request) {
+	c.treasurySpendsCsv(false, false, w, r)
+}
+func (c *appContext) treasuryPayeesCsvCR(w http.ResponseWriter, r *http.Request) {
+	c.treasurySpendsCsv(false, true, w, r)
+}
+func (c *appContext) treasuryProposalsCsvNoCR(w http.ResponseWriter, r *http.Request) {
+	c.treasurySpendsCsv(true, false, w, r)
+}
+func (c *appContext) treasuryProposalsCsvCR(w http.ResponseWriter, r *http.Request) {
+	c.treasurySpendsCsv(true, true, w, r)
+}
+
+// Handler for treasury spend report CSV file download, by payee address or by
+// proposal.
+// /download/treasury/{payees|proposals}[/win]?grouping={month|quarter}
+func (c *appContext) treasurySpendsCsv(byProposal, crlf bool, w http.ResponseWriter, r *http.Request) {
+	report := c.treasurySpendReport(w, r)
+	if report == nil {
+		return
+	}
+
+	kind, header := "payees", []string{"period", "address", "proposal", "amount", "tspends"}
+	if byProposal {
+		kind, header = "proposals", []string{"period", "proposal", "amount", "payees", "tspends"}
+	}
+	filename := fmt.Sprintf("treasury-%s-%s-%d-%s.csv", kind, report.Grouping,
+		c.Status.Height(), strconv.FormatInt(time.Now().Unix(), 10))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment;filename=%s", filename))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = crlf
+
+	amount := func(atoms int64) string {
+		return strconv.FormatFloat(dcrutil.Amount(atoms).ToCoin(), 'f', -1, 64)
+	}
+	if err := writer.Write(header); err != nil {
+		return // too late to write an error code
+	}
+	if byProposal {
+		for _, prs := range report.Proposals {
+			err := writer.Write([]string{prs.Period, prs.Proposal, amount(prs.Amount),
+				strconv.FormatInt(prs.Payees, 10), strconv.FormatInt(prs.TSpends, 10)})
+			if err != nil {
+				return // too late to write an error code
+			}
+		}
+	} else {
+		for _, ps := range report.Payees {
+			err := writer.Write([]string{ps.Period, ps.Address, ps.Proposal,
+				amount(ps.Amount), strconv.FormatInt(ps.TSpends, 10)})
+			if err != nil {
+				return // too late to write an error code
+			}
+		}
+	}
+	writer.Flush()
+}
+
 func (c *appContext) getAddressTxTypesData(w http.ResponseWriter, r *http.Request) {
 	addresses, err := m.GetAddressCtx(r, c.Params)
 	if err != nil || len(addresses) > 1 {
@@ -1740,6 +2956,174 @@ func (c *appContext) getTreasuryIO(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, data, m.GetIndentCtx(r))
 }
 
+// getTSpends serves the progress of the votes on the treasury spends in
+// mempool, and on those mined or expired within the last voting window.
+func (c *appContext) getTSpends(w http.ResponseWriter, r *http.Request) {
+	if c.TSpends == nil {
+		http.Error(w, "Treasury spends are not available.", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, c.TSpends.TSpends(), m.GetIndentCtx(r))
+}
+
+// treasurySpendReport retrieves the treasury spend report with the grouping of
+// the "grouping" URL query parameter, month by default. It writes the error
+// response and returns nil if the report cannot be retrieved.
+func (c *appContext) treasurySpendReport(w http.ResponseWriter, r *http.Request) *dbtypes.TreasurySpendReport {
+	grouping := r.URL.Query().Get("grouping")
+	switch grouping {
+	case "":
+		grouping = dbtypes.TreasuryReportMonth
+	case dbtypes.TreasuryReportMonth, dbtypes.TreasuryReportQuarter:
+	default:
+		http.Error(w, "grouping must be month or quarter", http.StatusBadRequest)
+		return nil
+	}
+
+	report, err := c.DataSource.TreasurySpendReport(grouping)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("TreasurySpendReport: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return nil
+	}
+	if err != nil {
+		apiLog.Errorf("TreasurySpendReport: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil
+	}
+	return report
+}
+
+// getTreasurySpendReport serves the amounts paid by the treasury spends to
+// each payee address and for each proposal by month or quarter.
+func (c *appContext) getTreasurySpendReport(w http.ResponseWriter, r *http.Request) {
+	report := c.treasurySpendReport(w, r)
+	if report == nil {
+		return
+	}
+	writeJSON(w, report, m.GetIndentCtx(r))
+}
+
+// listPageCtx gets the count and skip values for a page of a list, such as the
+// atomic swaps or webhook deliveries, from the request context, applying
+// defaults and limits.
+func listPageCtx(r *http.Request) (count, skip int64) {
+	count = int64(m.GetNCtx(r))
+	skip = int64(m.GetMCtx(r))
+	if count <= 0 {
+		count = 20
+	} else if count > 1000 {
+		count = 1000
+	}
+	if skip <= 0 {
+		skip = 0
+	}
+	return
+}
+
+func (c *appContext) getAtomicSwaps(w http.ResponseWriter, r *http.Request) {
+	count, skip := listPageCtx(r)
+
+	swaps, err := c.DataSource.AtomicSwaps(count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AtomicSwaps: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Warnf("failed to get atomic swaps: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	total, err := c.DataSource.AtomicSwapsCount("")
+	if err != nil {
+		apiLog.Errorf("AtomicSwapsCount: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &apitypes.AtomicSwaps{Total: total, Swaps: swaps}, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getAddressAtomicSwaps(w http.ResponseWriter, r *http.Request) {
+	addresses, err := m.GetAddressCtx(r, c.Params)
+	if err != nil || len(addresses) > 1 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+	address := addresses[0]
+
+	count, skip := listPageCtx(r)
+
+	swaps, err := c.DataSource.AtomicSwapsForAddress(address, count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AtomicSwapsForAddress: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Warnf("failed to get atomic swaps for %s: %v", address, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	total, err := c.DataSource.AtomicSwapsCount(address)
+	if err != nil {
+		apiLog.Errorf("AtomicSwapsCount: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, &apitypes.AtomicSwaps{Total: total, Swaps: swaps}, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getAtomicSwapsBySecretHash(w http.ResponseWriter, r *http.Request) {
+	secretHash := m.GetSecretHashCtx(r)
+	if len(secretHash) != 64 {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	swaps, err := c.DataSource.AtomicSwapsBySecretHash(secretHash)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AtomicSwapsBySecretHash: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Warnf("failed to get atomic swaps for secret hash %s: %v", secretHash, err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, &apitypes.AtomicSwaps{Total: int64(len(swaps)), Swaps: swaps}, m.GetIndentCtx(r))
+}
+
+func (c *appContext) getAtomicSwapsInTimeRange(w http.ResponseWriter, r *http.Request) {
+	start, end, ok := m.GetTimeRangeCtx(r)
+	if !ok || end < start {
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	count, skip := listPageCtx(r)
+
+	swaps, err := c.DataSource.AtomicSwapsInTimeRange(start, end, count, skip)
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("AtomicSwapsInTimeRange: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		apiLog.Warnf("failed to get atomic swaps in time range: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return
+	}
+
+	writeJSON(w, &apitypes.AtomicSwaps{Swaps: swaps}, m.GetIndentCtx(r))
+}
+
 func (c *appContext) ChartTypeData(w http.ResponseWriter, r *http.Request) {
 	chartType := m.GetChartTypeCtx(r)
 	bin := r.URL.Query().Get("bin")
@@ -0,0 +1,189 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/middleware"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+)
+
+type mempoolSource struct {
+	inv *exptypes.MempoolInfo
+}
+
+func (ms *mempoolSource) MempoolInventory() *exptypes.MempoolInfo {
+	return ms.inv
+}
+
+func TestMakeMempoolOverview(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeRate float64
+		wantBin int
+	}{
+		{"zero", 0, 0},
+		{"negative", -1, 0},
+		{"first bin", 0.00005, 0},
+		{"second bin edge", 0.0001, 1},
+		{"below third bin edge", 0.000199, 1},
+		{"third bin edge", 0.0002, 2},
+		{"middle bin", 0.0015, 4},
+		{"last bin edge", 0.01, 7},
+		{"unbounded last bin", 0.5, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &exptypes.MempoolInfo{
+				Transactions: []exptypes.MempoolTx{
+					{TxID: "tx", FeeRate: tt.feeRate, Fees: 0.001, Size: 250, TotalOut: 10},
+				},
+				// Votes are not in the fee rate histogram.
+				Votes: []exptypes.MempoolTx{
+					{TxID: "vote", FeeRate: 0.001, Size: 300, TotalOut: 2},
+				},
+			}
+			overview := makeMempoolOverview(inv)
+
+			if len(overview.FeeRateHistogram) != len(mempoolFeeRateBinEdges) {
+				t.Fatalf("got %d bins, expected %d", len(overview.FeeRateHistogram),
+					len(mempoolFeeRateBinEdges))
+			}
+			for i, bin := range overview.FeeRateHistogram {
+				want := apitypes.FeeRateBin{MinFeeRate: mempoolFeeRateBinEdges[i]}
+				if i+1 < len(mempoolFeeRateBinEdges) {
+					want.MaxFeeRate = mempoolFeeRateBinEdges[i+1]
+				}
+				if i == tt.wantBin {
+					want.Count, want.Size = 1, 250
+				}
+				if bin != want {
+					t.Errorf("bin %d is %+v, expected %+v", i, bin, want)
+				}
+			}
+
+			if overview.Regular.Count != 1 || overview.Regular.Size != 250 ||
+				overview.Votes.Count != 1 || overview.Votes.Total != 2 ||
+				overview.Fees != 0.001 {
+				t.Errorf("unexpected summaries %+v", overview)
+			}
+		})
+	}
+}
+
+func TestGetMempoolTxs(t *testing.T) {
+	inv := new(exptypes.MempoolInfo)
+	for i := 0; i < 5; i++ {
+		inv.Tickets = append(inv.Tickets, exptypes.MempoolTx{
+			TxID: fmt.Sprintf("ticket%d", i),
+			Type: "Ticket",
+		})
+	}
+	c := &appContext{Mempool: &mempoolSource{inv}}
+
+	router := chi.NewRouter()
+	router.Route("/mempool/txs/{txtype}", func(rd chi.Router) {
+		rd.Use(m.TxTypePathCtx)
+		rd.Get("/", c.getMempoolTxs)
+		rd.Route("/count/{N}", func(ri chi.Router) {
+			ri.Use(m.NPathCtx)
+			ri.Get("/", c.getMempoolTxs)
+			ri.With(m.MPathCtx).Get("/skip/{M}", c.getMempoolTxs)
+		})
+	})
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantTxIDs  []string
+	}{
+		{"/mempool/txs/tickets", http.StatusOK,
+			[]string{"ticket0", "ticket1", "ticket2", "ticket3", "ticket4"}},
+		{"/mempool/txs/tickets/count/2", http.StatusOK, []string{"ticket0", "ticket1"}},
+		{"/mempool/txs/tickets/count/2/skip/3", http.StatusOK, []string{"ticket3", "ticket4"}},
+		{"/mempool/txs/tickets/count/2/skip/4", http.StatusOK, []string{"ticket4"}},
+		{"/mempool/txs/tickets/count/2/skip/10", http.StatusOK, []string{}},
+		// A count of zero is the default count, and a negative skip is zero.
+		{"/mempool/txs/tickets/count/0/skip/-1", http.StatusOK,
+			[]string{"ticket0", "ticket1", "ticket2", "ticket3", "ticket4"}},
+		{"/mempool/txs/regular", http.StatusOK, []string{}},
+		{"/mempool/txs/coinbase", http.StatusUnprocessableEntity, nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: got status %d, expected %d", tt.path, w.Code, tt.wantStatus)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		var page apitypes.MempoolTxs
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		txIDs := []string{}
+		for _, tx := range page.Transactions {
+			txIDs = append(txIDs, tx.TxID)
+		}
+		if !reflect.DeepEqual(txIDs, tt.wantTxIDs) {
+			t.Errorf("%s: got %v, expected %v", tt.path, txIDs, tt.wantTxIDs)
+		}
+		wantTotal := len(inv.Tickets)
+		if page.Type == "regular" {
+			wantTotal = 0
+		}
+		if page.Total != wantTotal {
+			t.Errorf("%s: got a total of %d, expected %d", tt.path, page.Total, wantTotal)
+		}
+	}
+}
+
+// proposalsDataSource is a DataSource with the voters of one proposal.
+type proposalsDataSource struct {
+	DataSource
+	token string
+}
+
+func (ds *proposalsDataSource) ProposalVoters(token string, N, offset int64) (*dbtypes.ProposalVoters, error) {
+	if token != ds.token {
+		return nil, sql.ErrNoRows
+	}
+	return &dbtypes.ProposalVoters{Token: token, Voters: []*dbtypes.ProposalVoter{}}, nil
+}
+
+func TestGetProposalVoters(t *testing.T) {
+	c := &appContext{DataSource: &proposalsDataSource{token: "known"}}
+
+	router := chi.NewRouter()
+	router.With(m.ProposalTokenCtx).Get("/proposal/{token}/voters", c.getProposalVoters)
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{"/proposal/known/voters", http.StatusOK},
+		{"/proposal/unknown/voters", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: got status %d, expected %d", tt.path, w.Code, tt.wantStatus)
+		}
+	}
+}
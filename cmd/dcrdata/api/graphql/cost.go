@@ -0,0 +1,241 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is the estimated length of a list field without a limit.
+const defaultListSize = 10
+
+// listSizes are the estimated lengths of the list fields without a limit
+// argument, keyed by type and field name.
+var listSizes = map[string]int{
+	"Block.transactions": 20,
+	"Transaction.vins":   4,
+	"Transaction.vouts":  4,
+	"Query.agendas":      10,
+	"Agenda.choices":     4,
+	"Vote.choices":       4,
+	"Vote.tspendVotes":   4,
+	"Proposal.results":   3,
+	"Vout.addresses":     1,
+}
+
+// fieldCosts are the costs of the scalar fields that need a database lookup,
+// keyed by type and field name. Every object field costs one lookup.
+var fieldCosts = map[string]int{
+	"Vout.spendingTxHash": 1,
+}
+
+// errCostLimit is the error of queryCost when the cost exceeds the limit.
+var errCostLimit = errors.New("query cost exceeds the limit")
+
+// costWalker computes the cost of an operation. The cost of each fragment on
+// each type is computed once, and the walk stops as soon as the cost exceeds
+// limit, so that the computation is bounded by the size of the document.
+type costWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	fragCosts map[string]int
+	vars      map[string]interface{}
+	visiting  map[string]bool
+	limit     int
+}
+
+// queryCost estimates the number of database lookups needed to resolve the
+// operation op of the document. The cost of the fields of a list of objects is
+// multiplied by the list's limit argument or estimated length. errCostLimit is
+// returned if the cost exceeds the server's limit.
+func (s *Server) queryCost(doc *ast.Document, op *ast.OperationDefinition, vars map[string]interface{}) (int, error) {
+	w := &costWalker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		fragCosts: make(map[string]int),
+		vars:      make(map[string]interface{}, len(vars)),
+		visiting:  make(map[string]bool),
+		limit:     s.maxCost,
+	}
+	for name, v := range vars {
+		w.vars[name] = v
+	}
+	// The executor uses the default values of the variables not given.
+	for _, vd := range op.VariableDefinitions {
+		name := vd.Variable.Name.Value
+		if _, found := w.vars[name]; found || vd.DefaultValue == nil {
+			continue
+		}
+		switch dv := vd.DefaultValue.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(dv.Value); err == nil {
+				w.vars[name] = n
+			}
+		case *ast.ListValue:
+			w.vars[name] = make([]interface{}, len(dv.Values))
+		}
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			w.fragments[frag.Name.Value] = frag
+		}
+	}
+
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		root = s.schema.QueryType()
+	case ast.OperationTypeMutation:
+		root = s.schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = s.schema.SubscriptionType()
+	}
+	if root == nil {
+		return 0, fmt.Errorf("unsupported operation %q", op.Operation)
+	}
+	return w.selectionSetCost(op.SelectionSet, root)
+}
+
+func (w *costWalker) selectionSetCost(set *ast.SelectionSet, parent *graphql.Object) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+	var cost int
+	for _, sel := range set.Selections {
+		var c int
+		var err error
+		switch sel := sel.(type) {
+		case *ast.Field:
+			c, err = w.fieldCost(sel, parent)
+		case *ast.InlineFragment:
+			c, err = w.selectionSetCost(sel.SelectionSet, parent)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			frag, found := w.fragments[name]
+			if !found {
+				return 0, fmt.Errorf("unknown fragment %q", name)
+			}
+			if w.visiting[name] {
+				return 0, fmt.Errorf("fragment %q spreads itself", name)
+			}
+			key := name + " on " + parent.Name()
+			var known bool
+			if c, known = w.fragCosts[key]; !known {
+				w.visiting[name] = true
+				c, err = w.selectionSetCost(frag.SelectionSet, parent)
+				w.visiting[name] = false
+				w.fragCosts[key] = c
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		cost += c
+		if cost > w.limit {
+			return 0, errCostLimit
+		}
+	}
+	return cost, nil
+}
+
+func (w *costWalker) fieldCost(field *ast.Field, parent *graphql.Object) (int, error) {
+	name := field.Name.Value
+	def, found := parent.Fields()[name]
+	if !found {
+		// Introspection fields such as __typename.
+		return 0, nil
+	}
+	key := parent.Name() + "." + name
+	cost := fieldCosts[key]
+
+	size := 1
+	typ := def.Type
+	if nn, ok := typ.(*graphql.NonNull); ok {
+		typ = nn.OfType
+	}
+	if list, ok := typ.(*graphql.List); ok {
+		size = w.listSize(field, def, key)
+		typ = list.OfType
+		if nn, ok := typ.(*graphql.NonNull); ok {
+			typ = nn.OfType
+		}
+	}
+
+	obj, ok := typ.(*graphql.Object)
+	if !ok {
+		return cost, nil
+	}
+	if size <= 0 {
+		return cost + 1, nil
+	}
+	childCost, err := w.selectionSetCost(field.SelectionSet, obj)
+	if err != nil {
+		return 0, err
+	}
+	// Check the product against the limit before computing it, so that it
+	// cannot overflow.
+	if childCost > 0 && size > (w.limit-cost-1)/childCost {
+		return 0, errCostLimit
+	}
+	return cost + 1 + size*childCost, nil
+}
+
+// listSize returns the length of a list field from its limit or hashes
+// argument, or its estimated length.
+func (w *costWalker) listSize(field *ast.Field, def *graphql.FieldDefinition, key string) int {
+	for _, arg := range field.Arguments {
+		switch arg.Name.Value {
+		case "limit":
+			if n, ok := w.intValue(arg.Value); ok {
+				return n
+			}
+		case "hashes":
+			if n, ok := w.listLength(arg.Value); ok {
+				return n
+			}
+		}
+	}
+	for _, arg := range def.Args {
+		if arg.Name() == "limit" {
+			if n, ok := arg.DefaultValue.(int); ok {
+				return n
+			}
+		}
+	}
+	if n, found := listSizes[key]; found {
+		return n
+	}
+	return defaultListSize
+}
+
+func (w *costWalker) intValue(v ast.Value) (int, bool) {
+	switch v := v.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(v.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := w.vars[v.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+func (w *costWalker) listLength(v ast.Value) (int, bool) {
+	switch v := v.(type) {
+	case *ast.ListValue:
+		return len(v.Values), true
+	case *ast.Variable:
+		if list, ok := w.vars[v.Name.Value].([]interface{}); ok {
+			return len(list), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+// Package graphql implements a GraphQL API over the PostgreSQL data model of
+// dcrdata. Queries are served over HTTP, and subscriptions to new blocks,
+// mempool transactions and address activity over websocket.
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/decred/dcrdata/gov/v4/agendas"
+	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/decred/dcrdata/v6/pubsub"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+const (
+	// costPerSecond is the query cost allowed for each second of the query
+	// timeout. The cost of a query estimates the number of database lookups.
+	costPerSecond = 5
+
+	// minMaxCost is the lowest query cost limit, used with short timeouts.
+	minMaxCost = 500
+
+	// maxRequestSize is the largest request body accepted.
+	maxRequestSize = 1 << 16
+)
+
+// DataSource is the PostgreSQL data source of the GraphQL API.
+type DataSource interface {
+	GetBestBlockSummary() *apitypes.BlockDataBasic
+	BlockSummary(ind int64) (*apitypes.BlockDataBasic, error)
+	BlockSummaryByHash(hash string) (*apitypes.BlockDataBasic, error)
+	BlockSummaryRange(idx0, idx1 int64) ([]*apitypes.BlockDataBasic, error)
+	BlockTransactions(blockHash string) ([]string, []uint32, []int8, error)
+	Transaction(txHash string) ([]*dbtypes.Tx, error)
+	TransactionsByHashes(txHashes []string) (map[string]*dbtypes.Tx, error)
+	VinsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.VinTxProperty, error)
+	VoutsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.Vout, error)
+	SpendingTransaction(fundingTxID string, fundingTxVout uint32) (string, uint32, int8, error)
+	AddressBalance(address string) (bal *dbtypes.AddressBalance, cacheUpdated bool, err error)
+	AddressHistory(address string, N, offset int64, txnView dbtypes.AddrTxnViewType) ([]*dbtypes.AddressRow, *dbtypes.AddressBalance, error)
+	AddressLabel(addr string) *dbtypes.AddressLabel
+	GetTicketInfo(txid string) (*apitypes.TicketInfo, error)
+	GetVoteInfo(txhash *chainhash.Hash) (*apitypes.VoteInfo, error)
+	AgendasVotesSummary(agendaID string) (summary *dbtypes.AgendaSummary, err error)
+	TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error)
+	TreasuryBalance() (*dbtypes.TreasuryBalance, error)
+}
+
+// AgendaSource provides the consensus agendas.
+type AgendaSource interface {
+	AgendaInfo(agendaID string) (*agendas.AgendaTagged, error)
+	AllAgendas() (agendas []*agendas.AgendaTagged, err error)
+}
+
+// ProposalSource provides the Politeia proposals.
+type ProposalSource interface {
+	AllProposals(offset, rowsCount int, filterByVoteStatus ...int) (proposals []*pitypes.ProposalInfo, totalCount int, err error)
+	ProposalByToken(proposalToken string) (*pitypes.ProposalInfo, error)
+}
+
+// PubSubSource provides the events for subscriptions.
+type PubSubSource interface {
+	Subscribe(msgs ...pstypes.HubMessage) (*pubsub.Subscription, error)
+}
+
+// Config is the configuration of the GraphQL server.
+type Config struct {
+	DataSource   DataSource
+	AgendaSource AgendaSource
+	// ProposalSource may be nil if proposals are disabled.
+	ProposalSource ProposalSource
+	// PubSubSource may be nil to disable subscriptions.
+	PubSubSource PubSubSource
+	Params       *chaincfg.Params
+	// QueryTimeout is the time limit for executing a query, usually the
+	// PostgreSQL query timeout.
+	QueryTimeout time.Duration
+	// MaxCost is the query cost limit. If zero, the limit is derived from the
+	// QueryTimeout.
+	MaxCost int
+}
+
+// Server is the http.Handler of the GraphQL API.
+type Server struct {
+	ds        DataSource
+	agendas   AgendaSource
+	proposals ProposalSource
+	hub       PubSubSource
+	params    *chaincfg.Params
+	schema    graphql.Schema
+	timeout   time.Duration
+	maxCost   int
+}
+
+// NewServer creates a GraphQL server.
+func NewServer(cfg *Config) (*Server, error) {
+	s := &Server{
+		ds:        cfg.DataSource,
+		agendas:   cfg.AgendaSource,
+		proposals: cfg.ProposalSource,
+		hub:       cfg.PubSubSource,
+		params:    cfg.Params,
+		timeout:   cfg.QueryTimeout,
+		maxCost:   cfg.MaxCost,
+	}
+	if s.maxCost <= 0 {
+		s.maxCost = int(s.timeout.Seconds()) * costPerSecond
+		if s.maxCost < minMaxCost {
+			s.maxCost = minMaxCost
+		}
+	}
+
+	schema, err := s.newSchema()
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// request is a GraphQL request.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP serves queries with GET and POST requests, and subscriptions over
+// websocket.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.serveWebsocket(w, r)
+		return
+	}
+
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	res := s.execute(r.Context(), &req)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Warnf("JSON encode error: %v", err)
+	}
+}
+
+// parse parses and validates a query, and returns its operation with the given
+// name.
+func (s *Server) parse(query, operationName string) (*ast.Document, *ast.OperationDefinition, []gqlerrors.FormattedError) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err != nil {
+		return nil, nil, gqlerrors.FormatErrors(err)
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return nil, nil, validation.Errors
+	}
+
+	op, err := operation(doc, operationName)
+	if err != nil {
+		return nil, nil, gqlerrors.FormatErrors(err)
+	}
+	return doc, op, nil
+}
+
+// prepare parses and validates the request's query, and checks its cost.
+func (s *Server) prepare(req *request) (*ast.Document, *ast.OperationDefinition, []gqlerrors.FormattedError) {
+	doc, op, errs := s.parse(req.Query, req.OperationName)
+	if errs != nil {
+		return nil, nil, errs
+	}
+
+	if _, err := s.queryCost(doc, op, req.Variables); err != nil {
+		if errors.Is(err, errCostLimit) {
+			err = fmt.Errorf("%w of %d", err, s.maxCost)
+		}
+		return nil, nil, gqlerrors.FormatErrors(err)
+	}
+	return doc, op, nil
+}
+
+// execute runs a query, with a time limit of the query timeout.
+func (s *Server) execute(ctx context.Context, req *request) *graphql.Result {
+	doc, op, errs := s.prepare(req)
+	if errs != nil {
+		return &graphql.Result{Errors: errs}
+	}
+	if op.Operation == ast.OperationTypeSubscription {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(
+			errors.New("subscriptions are only available over websocket"))}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, s.newLoaders(ctx)),
+	})
+}
+
+// operation returns the operation of the document with the given name, or the
+// only operation if name is empty.
+func operation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		opDef, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if op != nil {
+				return nil, errors.New("an operation name is required for documents with more than one operation")
+			}
+			op = opDef
+		} else if opDef.Name != nil && opDef.Name.Value == name {
+			return opDef, nil
+		}
+	}
+	if op == nil {
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+	return op, nil
+}
+
+// newLoaders creates the loaders of a request. The loaders do not load after
+// ctx is done.
+func (s *Server) newLoaders(ctx context.Context) *loaders {
+	ls := &loaders{
+		newLoaderSet: func() *loaderSet {
+			return s.newLoaderSet(ctx)
+		},
+	}
+	ls.reset()
+	return ls
+}
+
+func (s *Server) newLoaderSet(ctx context.Context) *loaderSet {
+	return &loaderSet{
+		txns: newLoader(func(keys []string, _ []interface{}) (map[string]interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			txns, err := s.ds.TransactionsByHashes(keys)
+			if err != nil {
+				return nil, dbError(err)
+			}
+			vals := make(map[string]interface{}, len(txns))
+			for hash, tx := range txns {
+				vals[hash] = tx
+			}
+			return vals, nil
+		}),
+		vins: newLoader(func(keys []string, args []interface{}) (map[string]interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			dbTxs := make([]*dbtypes.Tx, len(args))
+			for i := range args {
+				dbTxs[i] = args[i].(*dbtypes.Tx)
+			}
+			vins, err := s.ds.VinsForTxs(dbTxs)
+			if err != nil {
+				return nil, dbError(err)
+			}
+			vals := make(map[string]interface{}, len(keys))
+			for i, key := range keys {
+				ptrs := make([]*dbtypes.VinTxProperty, len(vins[i]))
+				for j := range vins[i] {
+					ptrs[j] = &vins[i][j]
+				}
+				vals[key] = ptrs
+			}
+			return vals, nil
+		}),
+		vouts: newLoader(func(keys []string, args []interface{}) (map[string]interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			dbTxs := make([]*dbtypes.Tx, len(args))
+			for i := range args {
+				dbTxs[i] = args[i].(*dbtypes.Tx)
+			}
+			vouts, err := s.ds.VoutsForTxs(dbTxs)
+			if err != nil {
+				return nil, dbError(err)
+			}
+			vals := make(map[string]interface{}, len(keys))
+			for i, key := range keys {
+				ptrs := make([]*dbtypes.Vout, len(vouts[i]))
+				for j := range vouts[i] {
+					ptrs[j] = &vouts[i][j]
+				}
+				vals[key] = ptrs
+			}
+			return vals, nil
+		}),
+		// Block summaries are usually cached, so they are retrieved one at a
+		// time, but only once per request.
+		blocks: newLoader(func(keys []string, _ []interface{}) (map[string]interface{}, error) {
+			vals := make(map[string]interface{}, len(keys))
+			for _, hash := range keys {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				block, err := s.ds.BlockSummaryByHash(hash)
+				if err != nil {
+					if errors.Is(err, sql.ErrNoRows) {
+						continue
+					}
+					return nil, dbError(err)
+				}
+				vals[hash] = block
+			}
+			return vals, nil
+		}),
+	}
+}
+
+// dbError replaces database timeout errors with a plain error for clients.
+func dbError(err error) error {
+	if dbtypes.IsTimeoutErr(err) {
+		return errors.New("database timeout")
+	}
+	return err
+}
+
+// notFound returns a nil error for sql.ErrNoRows, so that a missing record is
+// null rather than an error.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return dbError(err)
+}
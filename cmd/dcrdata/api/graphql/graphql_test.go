@@ -0,0 +1,350 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	"github.com/decred/dcrdata/gov/v4/agendas"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// stubSource is a DataSource of two transactions in block 100, where the
+// input of tx2 spends the output of tx1.
+type stubSource struct {
+	mtx   sync.Mutex
+	calls map[string]int
+}
+
+func (s *stubSource) called(method string) {
+	s.mtx.Lock()
+	s.calls[method]++
+	s.mtx.Unlock()
+}
+
+var stubBlock = &apitypes.BlockDataBasic{
+	Height: 100,
+	Hash:   "block100",
+	NumTx:  2,
+}
+
+var stubTxns = map[string]*dbtypes.Tx{
+	"tx1": {TxID: "tx1", BlockHash: "block100", BlockHeight: 100, NumVout: 1, Sent: 5e8},
+	"tx2": {TxID: "tx2", BlockHash: "block100", BlockHeight: 100, BlockIndex: 1, NumVin: 1, NumVout: 1, Spent: 5e8, Sent: 4e8},
+}
+
+func (s *stubSource) GetBestBlockSummary() *apitypes.BlockDataBasic {
+	return stubBlock
+}
+
+func (s *stubSource) BlockSummary(ind int64) (*apitypes.BlockDataBasic, error) {
+	if ind != int64(stubBlock.Height) {
+		return nil, sql.ErrNoRows
+	}
+	return stubBlock, nil
+}
+
+func (s *stubSource) BlockSummaryByHash(hash string) (*apitypes.BlockDataBasic, error) {
+	s.called("BlockSummaryByHash")
+	if hash != stubBlock.Hash {
+		return nil, sql.ErrNoRows
+	}
+	return stubBlock, nil
+}
+
+func (s *stubSource) BlockSummaryRange(idx0, idx1 int64) ([]*apitypes.BlockDataBasic, error) {
+	return []*apitypes.BlockDataBasic{stubBlock}, nil
+}
+
+func (s *stubSource) BlockTransactions(blockHash string) ([]string, []uint32, []int8, error) {
+	return []string{"tx1", "tx2"}, []uint32{0, 1}, []int8{0, 0}, nil
+}
+
+func (s *stubSource) Transaction(txHash string) ([]*dbtypes.Tx, error) {
+	tx, found := stubTxns[txHash]
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return []*dbtypes.Tx{tx}, nil
+}
+
+func (s *stubSource) TransactionsByHashes(txHashes []string) (map[string]*dbtypes.Tx, error) {
+	s.called("TransactionsByHashes")
+	txns := make(map[string]*dbtypes.Tx)
+	for _, hash := range txHashes {
+		if tx, found := stubTxns[hash]; found {
+			txns[hash] = tx
+		}
+	}
+	return txns, nil
+}
+
+func (s *stubSource) VinsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.VinTxProperty, error) {
+	s.called("VinsForTxs")
+	vins := make([][]dbtypes.VinTxProperty, len(dbTxs))
+	for i, tx := range dbTxs {
+		if tx.TxID == "tx2" {
+			vins[i] = []dbtypes.VinTxProperty{{TxID: "tx2", PrevTxHash: "tx1", ValueIn: 5e8}}
+		}
+	}
+	return vins, nil
+}
+
+func (s *stubSource) VoutsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.Vout, error) {
+	s.called("VoutsForTxs")
+	vouts := make([][]dbtypes.Vout, len(dbTxs))
+	for i, tx := range dbTxs {
+		vouts[i] = []dbtypes.Vout{{TxHash: tx.TxID, Value: uint64(tx.Sent)}}
+	}
+	return vouts, nil
+}
+
+func (s *stubSource) SpendingTransaction(fundingTxID string, fundingTxVout uint32) (string, uint32, int8, error) {
+	if fundingTxID == "tx1" {
+		return "tx2", 0, 0, nil
+	}
+	return "", 0, 0, sql.ErrNoRows
+}
+
+func (s *stubSource) AddressBalance(address string) (*dbtypes.AddressBalance, bool, error) {
+	return &dbtypes.AddressBalance{}, false, nil
+}
+
+func (s *stubSource) AddressHistory(address string, N, offset int64, txnView dbtypes.AddrTxnViewType) ([]*dbtypes.AddressRow, *dbtypes.AddressBalance, error) {
+	return nil, nil, nil
+}
+
+func (s *stubSource) AddressLabel(addr string) *dbtypes.AddressLabel {
+	return nil
+}
+
+func (s *stubSource) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (s *stubSource) GetVoteInfo(txhash *chainhash.Hash) (*apitypes.VoteInfo, error) {
+	return nil, nil
+}
+
+func (s *stubSource) AgendasVotesSummary(agendaID string) (*dbtypes.AgendaSummary, error) {
+	return &dbtypes.AgendaSummary{Yes: 3}, nil
+}
+
+func (s *stubSource) TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error) {
+	return nil, nil
+}
+
+func (s *stubSource) TreasuryBalance() (*dbtypes.TreasuryBalance, error) {
+	return &dbtypes.TreasuryBalance{Balance: 1 << 40}, nil
+}
+
+type stubAgendas struct{}
+
+func (stubAgendas) AgendaInfo(agendaID string) (*agendas.AgendaTagged, error) {
+	return &agendas.AgendaTagged{ID: agendaID}, nil
+}
+
+func (stubAgendas) AllAgendas() ([]*agendas.AgendaTagged, error) {
+	return []*agendas.AgendaTagged{{ID: "treasury"}}, nil
+}
+
+func newTestServer(t *testing.T, maxCost int) (*Server, *stubSource) {
+	t.Helper()
+	ds := &stubSource{calls: make(map[string]int)}
+	s, err := NewServer(&Config{
+		DataSource:   ds,
+		AgendaSource: stubAgendas{},
+		Params:       chaincfg.MainNetParams(),
+		QueryTimeout: time.Minute,
+		MaxCost:      maxCost,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, ds
+}
+
+func TestBatching(t *testing.T) {
+	s, ds := newTestServer(t, 0)
+	res := s.execute(context.Background(), &request{
+		Query: `{
+			block(height: 100) {
+				transactions {
+					hash
+					block { height }
+					vins { amountIn prevTransaction { hash sent } }
+					vouts { value spendingTxHash }
+				}
+			}
+		}`,
+	})
+	if res.HasErrors() {
+		t.Fatal(res.Errors)
+	}
+
+	want := map[string]int{
+		// The previous transaction is one of the block's, so it is cached.
+		"TransactionsByHashes": 1,
+		"VinsForTxs":           1,
+		"VoutsForTxs":          1,
+		"BlockSummaryByHash":   1,
+	}
+	for method, n := range want {
+		if ds.calls[method] != n {
+			t.Errorf("%s called %d times, expected %d", method, ds.calls[method], n)
+		}
+	}
+
+	b, _ := json.Marshal(res.Data)
+	const wantData = `{"block":{"transactions":[` +
+		`{"block":{"height":100},"hash":"tx1","vins":[],"vouts":[{"spendingTxHash":"tx2","value":500000000}]},` +
+		`{"block":{"height":100},"hash":"tx2","vins":[{"amountIn":500000000,"prevTransaction":{"hash":"tx1","sent":500000000}}],"vouts":[{"spendingTxHash":null,"value":400000000}]}]}}`
+	if string(b) != wantData {
+		t.Errorf("unexpected data:\n%s\nexpected:\n%s", b, wantData)
+	}
+}
+
+func TestQueryCostLimit(t *testing.T) {
+	s, _ := newTestServer(t, 100)
+	res := s.execute(context.Background(), &request{
+		Query: `query ($n: Int = 50) { blocks(from: 0, limit: $n) { transactions { vouts { value } } } }`,
+	})
+	if !res.HasErrors() || !strings.Contains(res.Errors[0].Message, "exceeds the limit of 100") {
+		t.Fatalf("expected a cost limit error, got %v", res.Errors)
+	}
+
+	res = s.execute(context.Background(), &request{
+		Query:     `query ($n: Int = 50) { blocks(from: 0, limit: $n) { transactions { vouts { value } } } }`,
+		Variables: map[string]interface{}{"n": float64(2)},
+	})
+	if res.HasErrors() {
+		t.Fatal(res.Errors)
+	}
+}
+
+func TestQueryCost(t *testing.T) {
+	s, _ := newTestServer(t, 0)
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{`{ bestBlock { hash height } }`, 1},
+		{`{ treasuryBalance { balance } agendas { id votes { yes } } }`, 1 + 1 + 10*1},
+		// Each of the 5 transactions has 4 estimated outputs.
+		{`{ transactions(hashes: ["a", "b", "c", "d", "e"]) { hash vouts { value spendingTxHash } } }`, 1 + 5*(1+4*1)},
+		{`query { block(height: 1) { ...f } } fragment f on Block { transactions { hash } }`, 1 + 1},
+		{`{ address(address: "x") { transactions(limit: 50) { transaction { hash } } } }`, 1 + 1 + 50},
+	}
+	for _, tt := range tests {
+		doc, op, errs := s.parse(tt.query, "")
+		if errs != nil {
+			t.Fatal(errs)
+		}
+		cost, err := s.queryCost(doc, op, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost != tt.want {
+			t.Errorf("cost of %s is %d, expected %d", tt.query, cost, tt.want)
+		}
+	}
+}
+
+func TestQueryCostFragments(t *testing.T) {
+	s, _ := newTestServer(t, 100)
+	// nestedFragments makes a query of fragments that each spread the next
+	// one twice, with a cost of 2^depth.
+	nestedFragments := func(depth int) string {
+		var b strings.Builder
+		b.WriteString("query { ...f0 }\n")
+		for i := 0; i < depth; i++ {
+			fmt.Fprintf(&b, "fragment f%d on Query { ...f%d ...f%d }\n", i, i+1, i+1)
+		}
+		fmt.Fprintf(&b, "fragment f%d on Query { bestBlock { hash } }\n", depth)
+		return b.String()
+	}
+
+	doc, op, errs := s.parse(nestedFragments(6), "")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	cost, err := s.queryCost(doc, op, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost != 64 {
+		t.Errorf("cost of 6 nested fragments is %d, expected 64", cost)
+	}
+
+	doc, op, errs = s.parse(nestedFragments(60), "")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	start := time.Now()
+	if _, err = s.queryCost(doc, op, nil); !errors.Is(err, errCostLimit) {
+		t.Fatalf("expected errCostLimit, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cost of 60 nested fragments took %v", elapsed)
+	}
+
+	// The limit is checked before the cost of a long list is computed.
+	doc, op, errs = s.parse(`{ blocks(from: 0, limit: 2000000000) { transactions { hash } } }`, "")
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if _, err = s.queryCost(doc, op, nil); !errors.Is(err, errCostLimit) {
+		t.Fatalf("expected errCostLimit, got %v", err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s, _ := newTestServer(t, 0)
+
+	tests := []struct {
+		method, query, body string
+		wantStatus          int
+		wantBody            string
+	}{
+		{http.MethodGet, "query=" + url.QueryEscape("{ bestBlock { height } }"), "", http.StatusOK, `{"data":{"bestBlock":{"height":100}}}`},
+		{http.MethodPost, "", `{"query": "{ treasuryBalance { balance } }"}`, http.StatusOK, `{"data":{"treasuryBalance":{"balance":1099511627776}}}`},
+		{http.MethodPost, "", `{"query": ""}`, http.StatusBadRequest, ""},
+		{http.MethodPut, "", `{"query": "{ bestBlock { height } }"}`, http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/graphql?"+tt.query, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s %s: status %d, expected %d", tt.method, tt.query, rec.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantBody != "" && strings.TrimSpace(rec.Body.String()) != tt.wantBody {
+			t.Errorf("%s %s: body %s, expected %s", tt.method, tt.query, rec.Body.String(), tt.wantBody)
+		}
+	}
+}
+
+func TestSubscriptionsDisabled(t *testing.T) {
+	s, _ := newTestServer(t, 0)
+	if s.schema.SubscriptionType() != nil {
+		t.Error("the schema has subscriptions without a PubSubSource")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// batchFunc loads the values for many keys at once. args holds the argument
+// given with each key to (*loader).load. Keys without a value should not be in
+// the returned map.
+type batchFunc func(keys []string, args []interface{}) (map[string]interface{}, error)
+
+// loadResult is the cached outcome of loading a key.
+type loadResult struct {
+	val  interface{}
+	err  error
+	done bool
+}
+
+// loader batches and caches the loading of values by key for the duration of
+// a request. Resolvers call load, which queues the key and returns a thunk.
+// Since the executor resolves every field at one depth of the query before
+// calling the thunks, the first thunk called loads the values of all the keys
+// queued at that depth with one call of the batch function.
+type loader struct {
+	mtx     sync.Mutex
+	batch   batchFunc
+	cache   map[string]*loadResult
+	keys    []string
+	args    []interface{}
+	batches int
+}
+
+func newLoader(batch batchFunc) *loader {
+	return &loader{
+		batch: batch,
+		cache: make(map[string]*loadResult),
+	}
+}
+
+// load queues the key, if it is not already loaded or queued, and returns a
+// thunk for the executor that returns the key's value. arg is passed to the
+// batch function with the key.
+func (l *loader) load(key string, arg interface{}) func() (interface{}, error) {
+	l.mtx.Lock()
+	if _, found := l.cache[key]; !found {
+		l.cache[key] = new(loadResult)
+		l.keys = append(l.keys, key)
+		l.args = append(l.args, arg)
+	}
+	l.mtx.Unlock()
+
+	return func() (interface{}, error) {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		res := l.cache[key]
+		if !res.done {
+			l.dispatch()
+		}
+		return res.val, res.err
+	}
+}
+
+// dispatch loads the queued keys. The loader must be locked.
+func (l *loader) dispatch() {
+	keys, args := l.keys, l.args
+	l.keys, l.args = nil, nil
+
+	vals, err := l.batch(keys, args)
+	l.batches++
+	for _, key := range keys {
+		res := l.cache[key]
+		res.val, res.err, res.done = vals[key], err, true
+	}
+}
+
+// loaders are the loaders of a request.
+type loaders struct {
+	mtx          sync.Mutex
+	newLoaderSet func() *loaderSet
+	set          *loaderSet
+}
+
+// loaderSet holds one loader of each kind.
+type loaderSet struct {
+	txns   *loader
+	vins   *loader
+	vouts  *loader
+	blocks *loader
+}
+
+// get returns the current loaders.
+func (ls *loaders) get() *loaderSet {
+	ls.mtx.Lock()
+	defer ls.mtx.Unlock()
+	return ls.set
+}
+
+// reset replaces the loaders, discarding the cached values. Subscriptions
+// reset the loaders for each event so that no value is reused from an earlier
+// event.
+func (ls *loaders) reset() {
+	ls.mtx.Lock()
+	ls.set = ls.newLoaderSet()
+	ls.mtx.Unlock()
+}
+
+type ctxKey int
+
+const ctxLoaders ctxKey = iota
+
+func withLoaders(ctx context.Context, ls *loaders) context.Context {
+	return context.WithValue(ctx, ctxLoaders, ls)
+}
+
+func loadersFromContext(ctx context.Context) *loaderSet {
+	ls, ok := ctx.Value(ctxLoaders).(*loaders)
+	if !ok {
+		return nil
+	}
+	return ls.get()
+}
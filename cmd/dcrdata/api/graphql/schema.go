@@ -0,0 +1,1005 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package graphql
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/decred/dcrdata/gov/v4/agendas"
+	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// maxLimit is the largest limit of a list field, and the most transactions
+// that may be requested by hash.
+const maxLimit = 100
+
+// zeroHash is the previous outpoint hash of coinbase and stakebase inputs.
+var zeroHash = chainhash.Hash{}.String()
+
+// int64Type is a scalar for integers that do not fit in the 32-bit Int type of
+// GraphQL, such as amounts in atoms and UNIX timestamps.
+var int64Type = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Int64",
+	Description: "A 64-bit signed integer, such as an amount in atoms or a UNIX timestamp.",
+	Serialize:   coerceInt64,
+	ParseValue:  coerceInt64,
+	ParseLiteral: func(v ast.Value) interface{} {
+		if iv, ok := v.(*ast.IntValue); ok {
+			var n int64
+			if _, err := fmt.Sscan(iv.Value, &n); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+func coerceInt64(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return nil
+}
+
+// ticket is a ticket and its status.
+type ticket struct {
+	hash string
+	info *apitypes.TicketInfo
+}
+
+// vote is a vote and its choices.
+type vote struct {
+	hash string
+	info *apitypes.VoteInfo
+}
+
+// address is a Decred address.
+type address struct {
+	address string
+}
+
+func limitArg(def int) *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: def,
+		Description:  fmt.Sprintf("The maximum number of items, up to %d.", maxLimit),
+	}
+}
+
+var offsetArg = &graphql.ArgumentConfig{
+	Type:         graphql.Int,
+	DefaultValue: 0,
+	Description:  "The number of items to skip.",
+}
+
+// limitOffset returns the limit and offset arguments.
+func limitOffset(p graphql.ResolveParams) (int64, int64, error) {
+	limit, _ := p.Args["limit"].(int)
+	offset, _ := p.Args["offset"].(int)
+	if limit < 1 || limit > maxLimit {
+		return 0, 0, fmt.Errorf("limit must be from 1 to %d", maxLimit)
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset must not be negative")
+	}
+	return int64(limit), int64(offset), nil
+}
+
+// field returns a field of the given type resolved by fn.
+func field(typ graphql.Output, description string, fn func(p graphql.ResolveParams) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{
+		Type:        typ,
+		Description: description,
+		Resolve:     fn,
+	}
+}
+
+// newSchema creates the GraphQL schema.
+func (s *Server) newSchema() (graphql.Schema, error) {
+	var blockType, txType, vinType, voutType, ticketType, voteType *graphql.Object
+
+	blockRefType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BlockRef",
+		Description: "The hash and height of a block.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash":   &graphql.Field{Type: graphql.String},
+				"height": &graphql.Field{Type: graphql.Int},
+				"block": field(blockType, "The block.", func(p graphql.ResolveParams) (interface{}, error) {
+					b := p.Source.(*apitypes.TinyBlock)
+					return loadersFromContext(p.Context).blocks.load(b.Hash, nil), nil
+				}),
+			}
+		}),
+	})
+
+	addressLabelType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressLabel",
+		Description: "The label of a well-known address.",
+		Fields: graphql.Fields{
+			"label":    &graphql.Field{Type: graphql.String},
+			"category": &graphql.Field{Type: graphql.String},
+			"source":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	blockType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Block",
+		Description: "A mainchain or side chain block.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash":            &graphql.Field{Type: graphql.String},
+				"height":          &graphql.Field{Type: graphql.Int},
+				"size":            &graphql.Field{Type: graphql.Int},
+				"difficulty":      &graphql.Field{Type: graphql.Float},
+				"stakeDifficulty": field(graphql.Float, "The ticket price in DCR.", s.blockStakeDifficulty),
+				"time":            field(int64Type, "The UNIX time of the block.", s.blockTime),
+				"numTx":           &graphql.Field{Type: graphql.Int},
+				"transactions": &graphql.Field{
+					Type:        graphql.NewList(txType),
+					Description: "The transactions of the block, optionally only those of a tree (0 regular, 1 stake).",
+					Args: graphql.FieldConfigArgument{
+						"tree": &graphql.ArgumentConfig{Type: graphql.Int},
+					},
+					Resolve: s.blockTransactions,
+				},
+			}
+		}),
+	})
+
+	txType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transaction",
+		Description: "A transaction in a block. A transaction in more than one block is in the valid mainchain block, if any.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash":        field(graphql.String, "", txField(func(tx *dbtypes.Tx) interface{} { return tx.TxID })),
+				"type":        field(graphql.String, "The type, such as Regular, Ticket or Vote.", txField(func(tx *dbtypes.Tx) interface{} { return txhelpers.TxTypeToString(int(tx.TxType)) })),
+				"typeId":      field(graphql.Int, "The stake transaction type.", txField(func(tx *dbtypes.Tx) interface{} { return tx.TxType })),
+				"version":     &graphql.Field{Type: graphql.Int},
+				"tree":        &graphql.Field{Type: graphql.Int},
+				"blockHash":   &graphql.Field{Type: graphql.String},
+				"blockHeight": &graphql.Field{Type: graphql.Int},
+				"blockIndex":  &graphql.Field{Type: graphql.Int},
+				"blockTime":   field(int64Type, "The UNIX time of the block.", txField(func(tx *dbtypes.Tx) interface{} { return tx.BlockTime.UNIX() })),
+				"time":        field(int64Type, "The UNIX time of the transaction.", txField(func(tx *dbtypes.Tx) interface{} { return tx.Time.UNIX() })),
+				"lockTime":    &graphql.Field{Type: int64Type},
+				"expiry":      &graphql.Field{Type: int64Type},
+				"size":        &graphql.Field{Type: graphql.Int},
+				"spent":       field(int64Type, "The total input amount in atoms.", nil),
+				"sent":        field(int64Type, "The total output amount in atoms.", nil),
+				"fees":        field(int64Type, "The fees in atoms.", nil),
+				"mixCount":    &graphql.Field{Type: graphql.Int},
+				"mixDenom":    &graphql.Field{Type: int64Type},
+				"numVin":      &graphql.Field{Type: graphql.Int},
+				"numVout":     &graphql.Field{Type: graphql.Int},
+				"isValid":     field(graphql.Boolean, "If the transaction's block is valid.", nil),
+				"isMainchain": field(graphql.Boolean, "If the transaction's block is in the main chain.", txField(func(tx *dbtypes.Tx) interface{} { return tx.IsMainchainBlock })),
+				"block": field(blockType, "The transaction's block.", func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(*dbtypes.Tx)
+					return loadersFromContext(p.Context).blocks.load(tx.BlockHash, nil), nil
+				}),
+				"vins": field(graphql.NewList(vinType), "The inputs.", func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(*dbtypes.Tx)
+					return loadersFromContext(p.Context).vins.load(tx.TxID+tx.BlockHash, tx), nil
+				}),
+				"vouts": field(graphql.NewList(voutType), "The outputs.", func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(*dbtypes.Tx)
+					return loadersFromContext(p.Context).vouts.load(tx.TxID+tx.BlockHash, tx), nil
+				}),
+				"ticket": field(ticketType, "The ticket, if the transaction is a ticket purchase.", func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(*dbtypes.Tx)
+					if stake.TxType(tx.TxType) != stake.TxTypeSStx {
+						return nil, nil
+					}
+					return s.ticket(tx.TxID)
+				}),
+				"vote": field(voteType, "The vote, if the transaction is a vote.", func(p graphql.ResolveParams) (interface{}, error) {
+					tx := p.Source.(*dbtypes.Tx)
+					if stake.TxType(tx.TxType) != stake.TxTypeSSGen {
+						return nil, nil
+					}
+					return s.vote(tx.TxID)
+				}),
+			}
+		}),
+	})
+
+	vinType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Vin",
+		Description: "A transaction input.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"index":       field(graphql.Int, "The index of the input in the transaction.", vinField(func(vin *dbtypes.VinTxProperty) interface{} { return vin.TxIndex })),
+				"prevTxHash":  &graphql.Field{Type: graphql.String},
+				"prevTxIndex": &graphql.Field{Type: graphql.Int},
+				"prevTxTree":  &graphql.Field{Type: graphql.Int},
+				"amountIn":    field(int64Type, "The amount in atoms.", vinField(func(vin *dbtypes.VinTxProperty) interface{} { return vin.ValueIn })),
+				"isValid":     &graphql.Field{Type: graphql.Boolean},
+				"isMainchain": &graphql.Field{Type: graphql.Boolean},
+				"prevTransaction": field(txType, "The transaction of the previous outpoint, or null for coinbase and stakebase inputs.", func(p graphql.ResolveParams) (interface{}, error) {
+					vin := p.Source.(*dbtypes.VinTxProperty)
+					if vin.PrevTxHash == zeroHash {
+						return nil, nil
+					}
+					return loadersFromContext(p.Context).txns.load(vin.PrevTxHash, nil), nil
+				}),
+			}
+		}),
+	})
+
+	voutType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Vout",
+		Description: "A transaction output.",
+		Fields: graphql.Fields{
+			"index":      field(graphql.Int, "The index of the output in the transaction.", voutField(func(vout *dbtypes.Vout) interface{} { return vout.TxIndex })),
+			"value":      field(int64Type, "The amount in atoms.", nil),
+			"version":    &graphql.Field{Type: graphql.Int},
+			"tree":       field(graphql.Int, "", voutField(func(vout *dbtypes.Vout) interface{} { return vout.TxTree })),
+			"scriptType": field(graphql.String, "", voutField(func(vout *dbtypes.Vout) interface{} { return vout.ScriptPubKeyData.Type })),
+			"reqSigs":    field(graphql.Int, "", voutField(func(vout *dbtypes.Vout) interface{} { return vout.ScriptPubKeyData.ReqSigs })),
+			"addresses":  field(graphql.NewList(graphql.String), "", voutField(func(vout *dbtypes.Vout) interface{} { return vout.ScriptPubKeyData.Addresses })),
+			"pkScript":   field(graphql.String, "The hex encoded pkScript.", voutField(func(vout *dbtypes.Vout) interface{} { return hex.EncodeToString(vout.ScriptPubKey) })),
+			"mixed":      &graphql.Field{Type: graphql.Boolean},
+			"spendingTxHash": field(graphql.String, "The hash of the spending transaction, or null if the output is unspent.", func(p graphql.ResolveParams) (interface{}, error) {
+				vout := p.Source.(*dbtypes.Vout)
+				if err := p.Context.Err(); err != nil {
+					return nil, err
+				}
+				hash, _, _, err := s.ds.SpendingTransaction(vout.TxHash, vout.TxIndex)
+				if err != nil {
+					return nil, notFound(err)
+				}
+				return hash, nil
+			}),
+		},
+	})
+
+	addressBalanceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressBalance",
+		Description: "The balance of an address. Amounts are in atoms.",
+		Fields: graphql.Fields{
+			"numSpent":     &graphql.Field{Type: int64Type},
+			"numUnspent":   &graphql.Field{Type: int64Type},
+			"totalSpent":   &graphql.Field{Type: int64Type},
+			"totalUnspent": &graphql.Field{Type: int64Type},
+			"fromStake":    &graphql.Field{Type: graphql.Float},
+			"toStake":      &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	addressTxType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressTx",
+		Description: "An input or output of an address.",
+		Fields: graphql.Fields{
+			"txHash":         &graphql.Field{Type: graphql.String},
+			"index":          field(graphql.Int, "The index of the input or output.", addressRowField(func(row *dbtypes.AddressRow) interface{} { return row.TxVinVoutIndex })),
+			"isFunding":      field(graphql.Boolean, "If this is an output, rather than an input.", nil),
+			"value":          field(int64Type, "The amount in atoms.", nil),
+			"blockTime":      field(int64Type, "The UNIX time of the block.", addressRowField(func(row *dbtypes.AddressRow) interface{} { return row.TxBlockTime.UNIX() })),
+			"validMainchain": field(graphql.Boolean, "", addressRowField(func(row *dbtypes.AddressRow) interface{} { return row.ValidMainChain })),
+			"matchingTxHash": field(graphql.String, "The spending transaction of an output, or the funding transaction of an input.", nil),
+			"transaction": field(txType, "", func(p graphql.ResolveParams) (interface{}, error) {
+				row := p.Source.(*dbtypes.AddressRow)
+				return loadersFromContext(p.Context).txns.load(row.TxHash, nil), nil
+			}),
+		},
+	})
+
+	addressType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Address",
+		Description: "A Decred address.",
+		Fields: graphql.Fields{
+			"address": field(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*address).address, nil
+			}),
+			"label": field(addressLabelType, "The label of a well-known address.", func(p graphql.ResolveParams) (interface{}, error) {
+				return s.ds.AddressLabel(p.Source.(*address).address), nil
+			}),
+			"balance": field(addressBalanceType, "", func(p graphql.ResolveParams) (interface{}, error) {
+				if err := p.Context.Err(); err != nil {
+					return nil, err
+				}
+				bal, _, err := s.ds.AddressBalance(p.Source.(*address).address)
+				return bal, dbError(err)
+			}),
+			"transactions": &graphql.Field{
+				Type:        graphql.NewList(addressTxType),
+				Description: "The inputs and outputs of the address, newest first.",
+				Args: graphql.FieldConfigArgument{
+					"limit":  limitArg(10),
+					"offset": offsetArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, offset, err := limitOffset(p)
+					if err != nil {
+						return nil, err
+					}
+					if err = p.Context.Err(); err != nil {
+						return nil, err
+					}
+					rows, _, err := s.ds.AddressHistory(p.Source.(*address).address,
+						limit, offset, dbtypes.AddrTxnAll)
+					return rows, notFound(err)
+				},
+			},
+		},
+	})
+
+	ticketType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Ticket",
+		Description: "A ticket and its status.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash":             field(graphql.String, "", ticketField(func(t *ticket) interface{} { return t.hash })),
+				"status":           field(graphql.String, "The status, such as immature, live, voted, missed, expired or revoked.", ticketField(func(t *ticket) interface{} { return t.info.Status })),
+				"purchaseBlock":    field(blockRefType, "", ticketField(func(t *ticket) interface{} { return t.info.PurchaseBlock })),
+				"maturityHeight":   field(graphql.Int, "", ticketField(func(t *ticket) interface{} { return t.info.MaturityHeight })),
+				"expirationHeight": field(graphql.Int, "", ticketField(func(t *ticket) interface{} { return t.info.ExpirationHeight })),
+				"lotteryBlock":     field(blockRefType, "The block in which the ticket was called to vote.", ticketField(func(t *ticket) interface{} { return t.info.LotteryBlock })),
+				"transaction": field(txType, "The ticket purchase transaction.", func(p graphql.ResolveParams) (interface{}, error) {
+					return loadersFromContext(p.Context).txns.load(p.Source.(*ticket).hash, nil), nil
+				}),
+				"vote": field(voteType, "The vote, if the ticket voted.", func(p graphql.ResolveParams) (interface{}, error) {
+					t := p.Source.(*ticket)
+					if t.info.Vote == nil {
+						return nil, nil
+					}
+					return s.vote(*t.info.Vote)
+				}),
+				"revocation": field(txType, "The revocation, if the ticket was revoked.", func(p graphql.ResolveParams) (interface{}, error) {
+					t := p.Source.(*ticket)
+					if t.info.Revocation == nil {
+						return nil, nil
+					}
+					return loadersFromContext(p.Context).txns.load(*t.info.Revocation, nil), nil
+				}),
+			}
+		}),
+	})
+
+	blockValidationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BlockValidation",
+		Description: "The vote on the validity of the regular transactions of the previous block.",
+		Fields: graphql.Fields{
+			"hash":     &graphql.Field{Type: graphql.String},
+			"height":   &graphql.Field{Type: graphql.Int},
+			"validity": &graphql.Field{Type: graphql.Boolean},
+		},
+	})
+
+	voteChoiceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "VoteChoice",
+		Description: "The choice of a vote on a consensus agenda.",
+		Fields: graphql.Fields{
+			"agendaId": field(graphql.String, "", voteChoiceField(func(c *txhelpers.VoteChoice) interface{} { return c.ID })),
+			"choice": field(graphql.String, "", voteChoiceField(func(c *txhelpers.VoteChoice) interface{} {
+				if c.Choice == nil {
+					return nil
+				}
+				return c.Choice.Id
+			})),
+		},
+	})
+
+	tspendVoteType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TSpendVote",
+		Description: "The choice of a vote on a treasury spend.",
+		Fields: graphql.Fields{
+			"tspend": &graphql.Field{Type: graphql.String},
+			"choice": field(graphql.String, "yes or no.", func(p graphql.ResolveParams) (interface{}, error) {
+				switch stake.TreasuryVoteT(p.Source.(*apitypes.TSpendVote).Choice) {
+				case stake.TreasuryVoteYes:
+					return "yes", nil
+				case stake.TreasuryVoteNo:
+					return "no", nil
+				}
+				return nil, nil
+			}),
+		},
+	})
+
+	voteType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Vote",
+		Description: "A vote and its choices.",
+		Fields: graphql.Fields{
+			"hash": field(graphql.String, "", voteField(func(v *vote) interface{} { return v.hash })),
+			"transaction": field(txType, "The vote transaction.", func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFromContext(p.Context).txns.load(p.Source.(*vote).hash, nil), nil
+			}),
+			"version":         field(graphql.Int, "", voteField(func(v *vote) interface{} { return v.info.Version })),
+			"bits":            field(graphql.Int, "", voteField(func(v *vote) interface{} { return v.info.Bits })),
+			"blockValidation": field(blockValidationType, "", voteField(func(v *vote) interface{} { return &v.info.Validation })),
+			"choices":         field(graphql.NewList(voteChoiceType), "The choices on consensus agendas.", voteField(func(v *vote) interface{} { return v.info.Choices })),
+			"tspendVotes":     field(graphql.NewList(tspendVoteType), "The choices on treasury spends.", voteField(func(v *vote) interface{} { return v.info.TSpends })),
+		},
+	})
+
+	agendaChoiceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AgendaChoice",
+		Description: "A choice of a consensus agenda.",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"bits":        &graphql.Field{Type: graphql.Int},
+			"isAbstain":   &graphql.Field{Type: graphql.Boolean},
+			"isNo":        &graphql.Field{Type: graphql.Boolean},
+		},
+	})
+
+	agendaVotesType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AgendaVotes",
+		Description: "The vote tally of a consensus agenda.",
+		Fields: graphql.Fields{
+			"yes":           &graphql.Field{Type: graphql.Int},
+			"no":            &graphql.Field{Type: graphql.Int},
+			"abstain":       &graphql.Field{Type: graphql.Int},
+			"votingStarted": field(int64Type, "The UNIX time the voting started.", nil),
+			"lockedIn":      field(int64Type, "The UNIX time the agenda locked in.", nil),
+		},
+	})
+
+	agendaType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Agenda",
+		Description: "A consensus agenda.",
+		Fields: graphql.Fields{
+			"id":             &graphql.Field{Type: graphql.String},
+			"description":    &graphql.Field{Type: graphql.String},
+			"mask":           &graphql.Field{Type: graphql.Int},
+			"startTime":      &graphql.Field{Type: int64Type},
+			"expireTime":     &graphql.Field{Type: int64Type},
+			"status":         field(graphql.String, "", agendaField(func(a *agendas.AgendaTagged) interface{} { return a.Status.String() })),
+			"quorumProgress": &graphql.Field{Type: graphql.Float},
+			"voteVersion":    &graphql.Field{Type: graphql.Int},
+			"choices":        field(graphql.NewList(agendaChoiceType), "", agendaField(func(a *agendas.AgendaTagged) interface{} { return choicePointers(a.Choices) })),
+			"votes": field(agendaVotesType, "The vote tally.", func(p graphql.ResolveParams) (interface{}, error) {
+				if err := p.Context.Err(); err != nil {
+					return nil, err
+				}
+				summary, err := s.ds.AgendasVotesSummary(p.Source.(*agendas.AgendaTagged).ID)
+				return summary, notFound(err)
+			}),
+		},
+	})
+
+	proposalResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ProposalResult",
+		Description: "The votes received by a vote option of a proposal.",
+		Fields: graphql.Fields{
+			"option":      field(graphql.String, "", proposalResultField(func(r *pitypes.Results) interface{} { return r.Option.OptionID })),
+			"description": field(graphql.String, "", proposalResultField(func(r *pitypes.Results) interface{} { return r.Option.Description })),
+			"bits":        field(graphql.Int, "", proposalResultField(func(r *pitypes.Results) interface{} { return r.Option.Bits })),
+			"votes":       field(int64Type, "", proposalResultField(func(r *pitypes.Results) interface{} { return r.VotesReceived })),
+		},
+	})
+
+	proposalType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Proposal",
+		Description: "A Politeia proposal.",
+		Fields: graphql.Fields{
+			"token":            field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.TokenVal })),
+			"refId":            field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.RefID })),
+			"name":             &graphql.Field{Type: graphql.String},
+			"state":            field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.State.String() })),
+			"status":           field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.Status.String() })),
+			"timestamp":        &graphql.Field{Type: int64Type},
+			"username":         &graphql.Field{Type: graphql.String},
+			"numComments":      &graphql.Field{Type: graphql.Int},
+			"publishedDate":    &graphql.Field{Type: int64Type},
+			"censoredDate":     &graphql.Field{Type: int64Type},
+			"abandonedDate":    &graphql.Field{Type: int64Type},
+			"voteStatus":       field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.VoteStatus.ShortDesc() })),
+			"totalVotes":       field(int64Type, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.TotalVotes })),
+			"eligibleVotes":    field(int64Type, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.NumOfEligibleVotes })),
+			"quorumPercentage": field(graphql.Int, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.QuorumPercentage })),
+			"passPercentage":   field(graphql.Int, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.PassPercentage })),
+			"endHeight":        field(graphql.String, "", proposalField(func(pi *pitypes.ProposalInfo) interface{} { return pi.Endheight })),
+			"results": field(graphql.NewList(proposalResultType), "", proposalField(func(pi *pitypes.ProposalInfo) interface{} {
+				results := make([]*pitypes.Results, len(pi.VoteResults))
+				for i := range pi.VoteResults {
+					results[i] = &pi.VoteResults[i]
+				}
+				return results
+			})),
+		},
+	})
+
+	treasuryTxTypeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "TreasuryTxType",
+		Description: "The type of a treasury transaction.",
+		Values: graphql.EnumValueConfigMap{
+			"TADD":         &graphql.EnumValueConfig{Value: int(stake.TxTypeTAdd)},
+			"TSPEND":       &graphql.EnumValueConfig{Value: int(stake.TxTypeTSpend)},
+			"TREASURYBASE": &graphql.EnumValueConfig{Value: int(stake.TxTypeTreasuryBase)},
+		},
+	})
+
+	treasuryTxType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TreasuryTx",
+		Description: "A treasury add, spend or treasurybase transaction.",
+		Fields: graphql.Fields{
+			"hash":        field(graphql.String, "", treasuryTxField(func(tx *dbtypes.TreasuryTx) interface{} { return tx.TxID })),
+			"type":        field(graphql.String, "", treasuryTxField(func(tx *dbtypes.TreasuryTx) interface{} { return txhelpers.TxTypeToString(tx.Type) })),
+			"amount":      field(int64Type, "The amount in atoms, negative for spends.", nil),
+			"blockHash":   &graphql.Field{Type: graphql.String},
+			"blockHeight": &graphql.Field{Type: graphql.Int},
+			"blockTime":   field(int64Type, "", treasuryTxField(func(tx *dbtypes.TreasuryTx) interface{} { return tx.BlockTime.UNIX() })),
+			"transaction": field(txType, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFromContext(p.Context).txns.load(p.Source.(*dbtypes.TreasuryTx).TxID, nil), nil
+			}),
+		},
+	})
+
+	treasuryBalanceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "TreasuryBalance",
+		Description: "The treasury balance and totals. Amounts are in atoms.",
+		Fields: graphql.Fields{
+			"balance":       &graphql.Field{Type: int64Type},
+			"txCount":       &graphql.Field{Type: int64Type},
+			"addCount":      &graphql.Field{Type: int64Type},
+			"added":         &graphql.Field{Type: int64Type},
+			"spendCount":    &graphql.Field{Type: int64Type},
+			"spent":         &graphql.Field{Type: int64Type},
+			"tgenCount":     &graphql.Field{Type: int64Type},
+			"tgen":          &graphql.Field{Type: int64Type},
+			"immatureCount": &graphql.Field{Type: int64Type},
+			"immature":      &graphql.Field{Type: int64Type},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"bestBlock": field(blockType, "The best mainchain block.", func(p graphql.ResolveParams) (interface{}, error) {
+				return s.ds.GetBestBlockSummary(), nil
+			}),
+			"block": &graphql.Field{
+				Type:        blockType,
+				Description: "A block by height or hash.",
+				Args: graphql.FieldConfigArgument{
+					"height": &graphql.ArgumentConfig{Type: graphql.Int},
+					"hash":   &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: s.block,
+			},
+			"blocks": &graphql.Field{
+				Type:        graphql.NewList(blockType),
+				Description: "Consecutive mainchain blocks, from a height up.",
+				Args: graphql.FieldConfigArgument{
+					"from":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"limit": limitArg(10),
+				},
+				Resolve: s.blocks,
+			},
+			"transaction": &graphql.Field{
+				Type: txType,
+				Args: graphql.FieldConfigArgument{
+					"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.transaction,
+			},
+			"transactions": &graphql.Field{
+				Type:        graphql.NewList(txType),
+				Description: fmt.Sprintf("Transactions by hash, up to %d.", maxLimit),
+				Args: graphql.FieldConfigArgument{
+					"hashes": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				},
+				Resolve: s.transactions,
+			},
+			"address": &graphql.Field{
+				Type: addressType,
+				Args: graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.address,
+			},
+			"ticket": &graphql.Field{
+				Type: ticketType,
+				Args: graphql.FieldConfigArgument{
+					"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := p.Context.Err(); err != nil {
+						return nil, err
+					}
+					return s.ticket(p.Args["hash"].(string))
+				},
+			},
+			"vote": &graphql.Field{
+				Type: voteType,
+				Args: graphql.FieldConfigArgument{
+					"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.vote(p.Args["hash"].(string))
+				},
+			},
+			"agendas": field(graphql.NewList(agendaType), "The consensus agendas.", func(p graphql.ResolveParams) (interface{}, error) {
+				return s.agendas.AllAgendas()
+			}),
+			"agenda": &graphql.Field{
+				Type: agendaType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					agenda, err := s.agendas.AgendaInfo(p.Args["id"].(string))
+					if err != nil {
+						// Unknown agenda.
+						return nil, nil
+					}
+					return agenda, nil
+				},
+			},
+			"proposals": &graphql.Field{
+				Type:        graphql.NewList(proposalType),
+				Description: "The Politeia proposals, newest first.",
+				Args: graphql.FieldConfigArgument{
+					"limit":  limitArg(20),
+					"offset": offsetArg,
+				},
+				Resolve: s.allProposals,
+			},
+			"proposal": &graphql.Field{
+				Type: proposalType,
+				Args: graphql.FieldConfigArgument{
+					"token": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: s.proposal,
+			},
+			"treasuryTxs": &graphql.Field{
+				Type:        graphql.NewList(treasuryTxType),
+				Description: "The treasury transactions, newest first, optionally only those of a type.",
+				Args: graphql.FieldConfigArgument{
+					"type":   &graphql.ArgumentConfig{Type: treasuryTxTypeEnum},
+					"limit":  limitArg(20),
+					"offset": offsetArg,
+				},
+				Resolve: s.treasuryTxs,
+			},
+			"treasuryBalance": field(treasuryBalanceType, "", func(p graphql.ResolveParams) (interface{}, error) {
+				if err := p.Context.Err(); err != nil {
+					return nil, err
+				}
+				bal, err := s.ds.TreasuryBalance()
+				return bal, dbError(err)
+			}),
+		},
+	})
+
+	mempoolTxType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "MempoolTx",
+		Description: "A transaction that entered the mempool.",
+		Fields: graphql.Fields{
+			"hash":      &graphql.Field{Type: graphql.String},
+			"type":      &graphql.Field{Type: graphql.String},
+			"size":      &graphql.Field{Type: graphql.Int},
+			"time":      field(int64Type, "The UNIX time the transaction entered the mempool.", nil),
+			"fees":      field(graphql.Float, "The fees in DCR.", nil),
+			"feeRate":   field(graphql.Float, "The fee rate in DCR/kB.", nil),
+			"totalOut":  field(graphql.Float, "The total output amount in DCR.", nil),
+			"vinCount":  &graphql.Field{Type: graphql.Int},
+			"voutCount": &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	addressEventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AddressEvent",
+		Description: "A new transaction paying to or spending from an address.",
+		Fields: graphql.Fields{
+			"address": &graphql.Field{Type: graphql.String},
+			"txHash": field(graphql.String, "", func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*pstypes.AddressMessage).TxHash, nil
+			}),
+			"transaction": field(txType, "The transaction, or null while it is in the mempool.", func(p graphql.ResolveParams) (interface{}, error) {
+				return loadersFromContext(p.Context).txns.load(p.Source.(*pstypes.AddressMessage).TxHash, nil), nil
+			}),
+		},
+	})
+
+	if s.hub == nil {
+		return graphql.NewSchema(graphql.SchemaConfig{
+			Query: queryType,
+			Types: []graphql.Type{int64Type},
+		})
+	}
+
+	subscriptionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"newBlock": &graphql.Field{
+				Type:        blockType,
+				Description: "New mainchain blocks.",
+				Subscribe:   s.subscribe(pstypes.SigNewBlock),
+				Resolve: eventResolver(func(p graphql.ResolveParams) (interface{}, error) {
+					wb, ok := p.Source.(*exptypes.WebsocketBlock)
+					if !ok || wb.Block == nil {
+						return nil, nil
+					}
+					return loadersFromContext(p.Context).blocks.load(wb.Block.Hash, nil), nil
+				}),
+			},
+			"newTransactions": &graphql.Field{
+				Type:        graphql.NewList(mempoolTxType),
+				Description: "Batches of new mempool transactions.",
+				Subscribe:   s.subscribe(pstypes.SigNewTxs),
+				Resolve: eventResolver(func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				}),
+			},
+			"addressTransaction": &graphql.Field{
+				Type:        addressEventType,
+				Description: "New transactions paying to or spending from an address.",
+				Args: graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Subscribe: s.subscribe(pstypes.SigAddressTx),
+				Resolve: eventResolver(func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				}),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        queryType,
+		Subscription: subscriptionType,
+		Types:        []graphql.Type{int64Type},
+	})
+}
+
+// The *Field functions make resolvers of fields computed from the source.
+
+func txField(fn func(tx *dbtypes.Tx) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*dbtypes.Tx)), nil
+	}
+}
+
+func vinField(fn func(vin *dbtypes.VinTxProperty) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*dbtypes.VinTxProperty)), nil
+	}
+}
+
+func voutField(fn func(vout *dbtypes.Vout) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*dbtypes.Vout)), nil
+	}
+}
+
+func addressRowField(fn func(row *dbtypes.AddressRow) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*dbtypes.AddressRow)), nil
+	}
+}
+
+func ticketField(fn func(t *ticket) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*ticket)), nil
+	}
+}
+
+func voteField(fn func(v *vote) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*vote)), nil
+	}
+}
+
+func voteChoiceField(fn func(c *txhelpers.VoteChoice) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*txhelpers.VoteChoice)), nil
+	}
+}
+
+func agendaField(fn func(a *agendas.AgendaTagged) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*agendas.AgendaTagged)), nil
+	}
+}
+
+func proposalField(fn func(pi *pitypes.ProposalInfo) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*pitypes.ProposalInfo)), nil
+	}
+}
+
+func proposalResultField(fn func(r *pitypes.Results) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*pitypes.Results)), nil
+	}
+}
+
+func treasuryTxField(fn func(tx *dbtypes.TreasuryTx) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*dbtypes.TreasuryTx)), nil
+	}
+}
+
+func choicePointers(choices []chainjson.Choice) []*chainjson.Choice {
+	ptrs := make([]*chainjson.Choice, len(choices))
+	for i := range choices {
+		ptrs[i] = &choices[i]
+	}
+	return ptrs
+}
+
+func (s *Server) blockStakeDifficulty(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*apitypes.BlockDataBasic).StakeDiff, nil
+}
+
+func (s *Server) blockTime(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*apitypes.BlockDataBasic).Time.UNIX(), nil
+}
+
+func (s *Server) blockTransactions(p graphql.ResolveParams) (interface{}, error) {
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+	block := p.Source.(*apitypes.BlockDataBasic)
+	hashes, _, trees, err := s.ds.BlockTransactions(block.Hash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	tree, filter := p.Args["tree"].(int)
+	ls := loadersFromContext(p.Context)
+	txns := make([]interface{}, 0, len(hashes))
+	for i, hash := range hashes {
+		if filter && int(trees[i]) != tree {
+			continue
+		}
+		txns = append(txns, ls.txns.load(hash, nil))
+	}
+	return txns, nil
+}
+
+func (s *Server) block(p graphql.ResolveParams) (interface{}, error) {
+	height, byHeight := p.Args["height"].(int)
+	hash, byHash := p.Args["hash"].(string)
+	if byHeight == byHash {
+		return nil, errors.New("either height or hash is required")
+	}
+	if byHash {
+		return loadersFromContext(p.Context).blocks.load(hash, nil), nil
+	}
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+	block, err := s.ds.BlockSummary(int64(height))
+	return block, notFound(err)
+}
+
+func (s *Server) blocks(p graphql.ResolveParams) (interface{}, error) {
+	from := p.Args["from"].(int)
+	limit, _, err := limitOffset(p)
+	if err != nil {
+		return nil, err
+	}
+	if from < 0 {
+		return nil, errors.New("from must not be negative")
+	}
+	if err = p.Context.Err(); err != nil {
+		return nil, err
+	}
+	blocks, err := s.ds.BlockSummaryRange(int64(from), int64(from)+limit-1)
+	return blocks, dbError(err)
+}
+
+func (s *Server) transaction(p graphql.ResolveParams) (interface{}, error) {
+	if err := p.Context.Err(); err != nil {
+		return nil, err
+	}
+	// The transactions in valid mainchain blocks are first.
+	txns, err := s.ds.Transaction(p.Args["hash"].(string))
+	if err != nil || len(txns) == 0 {
+		return nil, notFound(err)
+	}
+	return txns[0], nil
+}
+
+func (s *Server) transactions(p graphql.ResolveParams) (interface{}, error) {
+	hashes := p.Args["hashes"].([]interface{})
+	if len(hashes) > maxLimit {
+		return nil, fmt.Errorf("at most %d hashes may be given", maxLimit)
+	}
+	ls := loadersFromContext(p.Context)
+	txns := make([]interface{}, 0, len(hashes))
+	for _, hash := range hashes {
+		txns = append(txns, ls.txns.load(hash.(string), nil))
+	}
+	return txns, nil
+}
+
+func (s *Server) address(p graphql.ResolveParams) (interface{}, error) {
+	addr := p.Args["address"].(string)
+	_, _, addrErr := txhelpers.AddressValidation(addr, s.params)
+	if addrErr != nil && addrErr != txhelpers.AddressErrorZeroAddress {
+		return nil, fmt.Errorf("invalid address: %v", addrErr)
+	}
+	return &address{address: addr}, nil
+}
+
+func (s *Server) ticket(hash string) (interface{}, error) {
+	info, err := s.ds.GetTicketInfo(hash)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &ticket{hash: hash, info: info}, nil
+}
+
+func (s *Server) vote(hash string) (interface{}, error) {
+	txHash, err := chainhash.NewHashFromStr(hash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %v", err)
+	}
+	info, err := s.ds.GetVoteInfo(txHash)
+	if err != nil || info == nil {
+		// Not a vote, or not found.
+		return nil, nil
+	}
+	return &vote{hash: hash, info: info}, nil
+}
+
+func (s *Server) allProposals(p graphql.ResolveParams) (interface{}, error) {
+	if s.proposals == nil {
+		return nil, errors.New("proposals are disabled")
+	}
+	limit, offset, err := limitOffset(p)
+	if err != nil {
+		return nil, err
+	}
+	proposals, _, err := s.proposals.AllProposals(int(offset), int(limit))
+	return proposals, err
+}
+
+func (s *Server) proposal(p graphql.ResolveParams) (interface{}, error) {
+	if s.proposals == nil {
+		return nil, errors.New("proposals are disabled")
+	}
+	proposal, err := s.proposals.ProposalByToken(p.Args["token"].(string))
+	if err != nil {
+		// Unknown proposal.
+		return nil, nil
+	}
+	return proposal, nil
+}
+
+func (s *Server) treasuryTxs(p graphql.ResolveParams) (interface{}, error) {
+	limit, offset, err := limitOffset(p)
+	if err != nil {
+		return nil, err
+	}
+	txType := stake.TxType(-1)
+	if t, ok := p.Args["type"].(int); ok {
+		txType = stake.TxType(t)
+	}
+	if err = p.Context.Err(); err != nil {
+		return nil, err
+	}
+	txns, err := s.ds.TreasuryTxns(limit, offset, txType)
+	return txns, dbError(err)
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"golang.org/x/net/websocket"
+
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+const (
+	// wsProtocol is the GraphQL over WebSocket subprotocol of
+	// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+	wsProtocol = "graphql-transport-ws"
+
+	// maxSubscriptions is the most operations a websocket client may run at
+	// once.
+	maxSubscriptions = 10
+)
+
+// The message types of the graphql-transport-ws protocol.
+const (
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// wsMessage is a message of the graphql-transport-ws protocol.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// subscribe returns the Subscribe function of a subscription field, which
+// subscribes to the hub signal and forwards the events until the subscription's
+// context is done.
+func (s *Server) subscribe(sig pstypes.HubSignal) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		msg := pstypes.HubMessage{Signal: sig}
+		if sig == pstypes.SigAddressTx {
+			addr := p.Args["address"].(string)
+			_, _, addrErr := txhelpers.AddressValidation(addr, s.params)
+			if addrErr != nil && addrErr != txhelpers.AddressErrorZeroAddress {
+				return nil, fmt.Errorf("invalid address: %v", addrErr)
+			}
+			msg.Msg = &pstypes.AddressMessage{Address: addr}
+		}
+		sub, err := s.hub.Subscribe(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		events := make(chan interface{})
+		go func() {
+			defer close(events)
+			defer sub.Close()
+			for {
+				select {
+				case ev, ok := <-sub.C:
+					if !ok {
+						return
+					}
+					select {
+					case events <- ev.Msg:
+					case <-p.Context.Done():
+						return
+					}
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}()
+		return events, nil
+	}
+}
+
+// eventResolver wraps the resolver of a subscription field. The executor
+// resolves each event with the same context, so the loaders are reset to avoid
+// reusing the values loaded for an earlier event.
+func eventResolver(fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if ls, ok := p.Context.Value(ctxLoaders).(*loaders); ok {
+			ls.reset()
+		}
+		return fn(p)
+	}
+}
+
+// wsConn is a websocket connection of the graphql-transport-ws protocol.
+type wsConn struct {
+	ws      *websocket.Conn
+	sendMtx sync.Mutex
+
+	opsMtx sync.Mutex
+	ops    map[string]context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (c *wsConn) send(msg *wsMessage) {
+	c.sendMtx.Lock()
+	defer c.sendMtx.Unlock()
+	if err := websocket.JSON.Send(c.ws, msg); err != nil {
+		log.Debugf("websocket send failed: %v", err)
+	}
+}
+
+func (c *wsConn) sendPayload(id, typ string, payload interface{}) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("JSON marshal error: %v", err)
+		return
+	}
+	c.send(&wsMessage{ID: id, Type: typ, Payload: b})
+}
+
+// serveWebsocket serves queries and subscriptions over websocket with the
+// graphql-transport-ws protocol.
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	wsServer := websocket.Server{
+		// Require the protocol, and do not check Origin.
+		Handshake: func(cfg *websocket.Config, _ *http.Request) error {
+			for _, p := range cfg.Protocol {
+				if p == wsProtocol {
+					cfg.Protocol = []string{wsProtocol}
+					return nil
+				}
+			}
+			return websocket.ErrBadWebSocketProtocol
+		},
+		Handler: s.wsHandler,
+	}
+	wsServer.ServeHTTP(w, r)
+}
+
+func (s *Server) wsHandler(ws *websocket.Conn) {
+	defer ws.Close()
+
+	c := &wsConn{
+		ws:  ws,
+		ops: make(map[string]context.CancelFunc),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		// Stop the operations and wait for them before closing.
+		cancel()
+		c.wg.Wait()
+	}()
+
+	var acked bool
+	for {
+		var msg wsMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			log.Tracef("websocket receive: %v", err)
+			return
+		}
+
+		switch msg.Type {
+		case msgConnectionInit:
+			if acked {
+				// Too many initialisation requests.
+				return
+			}
+			acked = true
+			c.send(&wsMessage{Type: msgConnectionAck})
+		case msgPing:
+			c.send(&wsMessage{Type: msgPong})
+		case msgPong:
+		case msgSubscribe:
+			if !acked || msg.ID == "" {
+				return
+			}
+			var req request
+			if err := json.Unmarshal(msg.Payload, &req); err != nil {
+				return
+			}
+			s.startOperation(ctx, c, msg.ID, &req)
+		case msgComplete:
+			c.opsMtx.Lock()
+			if stop, found := c.ops[msg.ID]; found {
+				stop()
+				delete(c.ops, msg.ID)
+			}
+			c.opsMtx.Unlock()
+		default:
+			return
+		}
+	}
+}
+
+// startOperation runs the operation of a subscribe message, sending its results
+// to the client.
+func (s *Server) startOperation(ctx context.Context, c *wsConn, id string, req *request) {
+	doc, op, errs := s.prepare(req)
+	if errs != nil {
+		c.sendPayload(id, msgError, errs)
+		return
+	}
+
+	c.opsMtx.Lock()
+	if _, found := c.ops[id]; found {
+		c.opsMtx.Unlock()
+		c.sendPayload(id, msgError, gqlerrors.FormatErrors(
+			fmt.Errorf("an operation with id %q is already running", id)))
+		return
+	}
+	if len(c.ops) >= maxSubscriptions {
+		c.opsMtx.Unlock()
+		c.sendPayload(id, msgError, gqlerrors.FormatErrors(
+			fmt.Errorf("at most %d operations may run at once", maxSubscriptions)))
+		return
+	}
+	var stop context.CancelFunc
+	if op.Operation == ast.OperationTypeSubscription {
+		ctx, stop = context.WithCancel(ctx)
+	} else {
+		ctx, stop = context.WithTimeout(ctx, s.timeout)
+	}
+	c.ops[id] = stop
+	c.opsMtx.Unlock()
+
+	params := graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, s.newLoaders(ctx)),
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.opsMtx.Lock()
+			_, running := c.ops[id]
+			delete(c.ops, id)
+			c.opsMtx.Unlock()
+			stop()
+			// The client completed the operation itself if it is no
+			// longer running.
+			if running {
+				c.send(&wsMessage{ID: id, Type: msgComplete})
+			}
+		}()
+
+		if op.Operation != ast.OperationTypeSubscription {
+			c.sendPayload(id, msgNext, graphql.Execute(params))
+			return
+		}
+		// Drain the results until the executor closes the channel.
+		for res := range graphql.ExecuteSubscription(params) {
+			if ctx.Err() == nil {
+				c.sendPayload(id, msgNext, res)
+			}
+		}
+	}()
+}
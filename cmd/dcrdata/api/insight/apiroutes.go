@@ -79,6 +79,7 @@ type InsightApi struct {
 	status          *apitypes.Status
 	JSONIndent      string
 	ReqPerSecLimit  float64
+	apiKeys         *m.APIKeys
 	inflightUTXOs   int64
 	inflightLimiter sync.Mutex
 }
@@ -104,6 +105,12 @@ func (iapi *InsightApi) SetReqRateLimit(reqPerSecLimit float64) {
 	iapi.ReqPerSecLimit = reqPerSecLimit
 }
 
+// SetAPIKeys sets the API keys that may be used instead of the per-IP rate
+// limit.
+func (iapi *InsightApi) SetAPIKeys(keys *m.APIKeys) {
+	iapi.apiKeys = keys
+}
+
 // Insight API successful response for JSON return items.
 func writeJSON(w http.ResponseWriter, thing interface{}, indent string) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -0,0 +1,401 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+)
+
+// openAPIVersion is the version of the OpenAPI specification of the API
+// document.
+const openAPIVersion = "3.0.3"
+
+// openAPIDoc is an OpenAPI 3 document describing the API.
+type openAPIDoc struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `json:"operationId"`
+	Summary     string                      `json:"summary"`
+	Tags        []string                    `json:"tags"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+// openAPISchema is an OpenAPI schema object. GoType is the import path and
+// name of the Go type of a component schema, for client code generators.
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+	GoType               string                    `json:"x-go-type,omitempty"`
+}
+
+// schemaPrefixes are the prefixes of the component schema names of the types
+// of each package. The types of the api/types package have no prefix.
+var schemaPrefixes = map[string]string{
+	"github.com/decred/dcrdata/v6/api/types":      "",
+	"github.com/decred/dcrdata/v6/db/dbtypes":     "dbtypes.",
+	"github.com/decred/dcrdata/v6/explorer/types": "exptypes.",
+	"github.com/decred/dcrdata/v6/txhelpers":      "txhelpers.",
+	"github.com/decred/dcrd/rpc/jsonrpc/types/v2": "chainjson.",
+	"github.com/decred/dcrd/chaincfg/v3":          "chaincfg.",
+	"github.com/decred/dcrdata/exchanges/v3":      "exchanges.",
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	jsonMarshalType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// marshalerSchemas are the schemas of the types with a MarshalJSON method.
+var marshalerSchemas = map[reflect.Type]*openAPISchema{
+	reflect.TypeOf(apitypes.TimeAPI{}):          {Type: "integer", Format: "int64", Description: "UNIX time"},
+	reflect.TypeOf(dbtypes.TimeDef{}):           {Type: "string", Format: "date-time"},
+	reflect.TypeOf(exptypes.TimeDef{}):          {Type: "string", Format: "date-time"},
+	reflect.TypeOf(dbtypes.AgendaStatusType(0)): {Type: "string"},
+}
+
+// schemaBuilder builds the schemas of Go types from their JSON encoding. The
+// schemas of named struct types are components, referenced by name.
+type schemaBuilder struct {
+	schemas map[string]*openAPISchema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]*openAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schema returns the schema of the JSON encoding of a value of type t.
+func (b *schemaBuilder) schema(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if s, found := marshalerSchemas[t]; found {
+		c := *s
+		return &c
+	}
+	switch {
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case t.Implements(jsonMarshalType) || reflect.PtrTo(t).Implements(jsonMarshalType):
+		// Unknown encoding.
+		return &openAPISchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Array:
+		return &openAPISchema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + b.component(t)}
+	}
+	// Interfaces may hold anything.
+	return &openAPISchema{}
+}
+
+// component adds the schema of the named struct type t to the components, if
+// it is not already added, and returns its name.
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, found := b.names[t]; found {
+		return name
+	}
+	prefix, found := schemaPrefixes[t.PkgPath()]
+	if !found {
+		prefix = path.Base(t.PkgPath()) + "."
+	}
+	name := prefix + t.Name()
+	if _, found = b.schemas[name]; found {
+		panic(fmt.Sprintf("schema %s of %v is already the schema of another type", name, t))
+	}
+	// Register the name first for recursive types.
+	b.names[t] = name
+	b.schemas[name] = nil
+	s := b.structSchema(t)
+	s.GoType = t.PkgPath() + "." + t.Name()
+	b.schemas[name] = s
+	return name
+}
+
+// structSchema returns the object schema of the struct type t.
+func (b *schemaBuilder) structSchema(t reflect.Type) *openAPISchema {
+	s := &openAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*openAPISchema),
+	}
+	b.addFields(s, t)
+	return s
+}
+
+// addFields adds the properties of the JSON encoding of the fields of the
+// struct type t to s, including the fields of embedded structs.
+func (b *schemaBuilder) addFields(s *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if _, found := marshalerSchemas[ft]; !found {
+				b.addFields(s, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := b.schema(f.Type)
+		if strings.Contains(opts, "string") {
+			prop = &openAPISchema{Type: "string"}
+		}
+		if f.Type.Kind() == reflect.Ptr && prop.Ref == "" {
+			prop.Nullable = true
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// pathParamRegexp matches the parameters of a path.
+var pathParamRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+// newOpenAPIDoc creates the OpenAPI document of the operations.
+func newOpenAPIDoc(ops []*apiOperation) (*openAPIDoc, error) {
+	b := newSchemaBuilder()
+	doc := &openAPIDoc{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title: "dcrdata API",
+			Description: "The JSON HTTP API of dcrdata. All JSON endpoints accept the URL " +
+				"query indent=[true|false] to indent the response.",
+			Version: fmt.Sprint(APIVersion),
+		},
+		Servers: []openAPIServer{{URL: "/api"}},
+		Paths:   make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: b.schemas,
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				"adminAuth": {Type: "http", Scheme: "basic"},
+			},
+		},
+	}
+
+	ids := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		if ids[op.id] {
+			return nil, fmt.Errorf("duplicate operation ID %s", op.id)
+		}
+		ids[op.id] = true
+
+		o := &openAPIOperation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Tags:        []string{strings.SplitN(strings.TrimPrefix(op.path, "/"), "/", 2)[0]},
+			Responses:   make(map[string]*openAPIResponse),
+		}
+		if o.Tags[0] == "" {
+			o.Tags[0] = "status"
+		}
+
+		for _, m := range pathParamRegexp.FindAllStringSubmatch(op.path, -1) {
+			p, found := pathParams[m[1]]
+			if !found {
+				return nil, fmt.Errorf("%s: unknown path parameter %s", op.path, m[1])
+			}
+			o.Parameters = append(o.Parameters, &openAPIParameter{
+				Name:        m[1],
+				In:          "path",
+				Description: p.desc,
+				Required:    true,
+				Schema:      p.schema(),
+			})
+		}
+		for _, p := range op.query {
+			o.Parameters = append(o.Parameters, &openAPIParameter{
+				Name:        p.name,
+				In:          "query",
+				Description: p.desc,
+				Schema:      p.schema(),
+			})
+		}
+
+		if op.body != nil {
+			o.RequestBody = &openAPIRequestBody{
+				Required: true,
+				Content: map[string]*openAPIMediaType{
+					"application/json": {Schema: b.schema(reflect.TypeOf(op.body))},
+				},
+			}
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := &openAPIResponse{Description: http.StatusText(status)}
+		var schema *openAPISchema
+		switch {
+		case len(op.respOneOf) > 0:
+			schema = new(openAPISchema)
+			for _, resp := range op.respOneOf {
+				schema.OneOf = append(schema.OneOf, b.schema(reflect.TypeOf(resp)))
+			}
+		case op.resp != nil:
+			schema = b.schema(reflect.TypeOf(op.resp))
+		}
+		if schema != nil {
+			contentType := op.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			resp.Content = map[string]*openAPIMediaType{
+				contentType: {Schema: schema},
+			}
+		}
+		o.Responses[fmt.Sprint(status)] = resp
+		o.Responses["default"] = &openAPIResponse{
+			Description: "An error",
+			Content: map[string]*openAPIMediaType{
+				"text/plain": {Schema: &openAPISchema{Type: "string"}},
+			},
+		}
+
+		if op.admin {
+			o.Security = []map[string][]string{{"adminAuth": {}}}
+		}
+
+		methods := doc.Paths[op.path]
+		if methods == nil {
+			methods = make(map[string]*openAPIOperation)
+			doc.Paths[op.path] = methods
+		}
+		method := strings.ToLower(op.method)
+		if _, found := methods[method]; found {
+			return nil, fmt.Errorf("duplicate operation %s %s", op.method, op.path)
+		}
+		methods[method] = o
+	}
+	return doc, nil
+}
+
+// openAPIPaths returns the method and path of each operation of the document,
+// sorted, such as "GET /block/best".
+func (doc *openAPIDoc) openAPIPaths() []string {
+	var paths []string
+	for p, methods := range doc.Paths {
+		for method := range methods {
+			paths = append(paths, strings.ToUpper(method)+" "+p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
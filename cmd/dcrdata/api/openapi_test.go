@@ -0,0 +1,91 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var updateSpec = flag.Bool("update", false, "update the OpenAPI document of the client")
+
+// clientSpecFile is the OpenAPI document the client is generated from.
+const clientSpecFile = "../../../api/client/openapi.json"
+
+// routerPaths returns the method and path of each route of the router, in the
+// form of the paths of the OpenAPI document.
+func routerPaths(t *testing.T, mux chi.Routes) []string {
+	t.Helper()
+	var paths []string
+	err := chi.Walk(mux, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = strings.Replace(route, "/*/", "/", -1)
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		paths = append(paths, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	mux := NewAPIRouter(&appContext{}, "", false, false)
+	doc, err := newOpenAPIDoc(apiOperations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := make(map[string]bool)
+	for _, p := range doc.openAPIPaths() {
+		documented[p] = true
+	}
+	routed := make(map[string]bool)
+	for _, p := range routerPaths(t, mux) {
+		routed[p] = true
+		if !documented[p] {
+			t.Errorf("route %s is not in the OpenAPI document", p)
+		}
+	}
+	for p := range documented {
+		if !routed[p] {
+			t.Errorf("operation %s of the OpenAPI document is not routed", p)
+		}
+	}
+}
+
+func TestOpenAPIClientSpec(t *testing.T) {
+	mux := NewAPIRouter(&appContext{}, "\t", false, false)
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json?indent=true", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	spec := rec.Body.Bytes()
+
+	if *updateSpec {
+		if err := ioutil.WriteFile(clientSpecFile, spec, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	clientSpec, err := ioutil.ReadFile(clientSpecFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spec, clientSpec) {
+		t.Errorf("%s is out of date, run go test -run TestOpenAPIClientSpec -update "+
+			"and go generate in api/client", clientSpecFile)
+	}
+}
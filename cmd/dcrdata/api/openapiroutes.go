@@ -0,0 +1,378 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrdata/exchanges/v3"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// apiOperation describes an operation of the API router for the OpenAPI
+// document. The request and response bodies are described by values of their
+// Go types.
+type apiOperation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	query   []*queryParam
+	admin   bool
+
+	// body is the request body, if any.
+	body interface{}
+	// resp is the response body, if any. respOneOf lists the alternatives of a
+	// response that depends on the query.
+	resp      interface{}
+	respOneOf []interface{}
+	// contentType is the response content type, application/json if empty.
+	contentType string
+	// status is the response status code, http.StatusOK if zero.
+	status int
+}
+
+// apiParam describes the value of a path or query parameter.
+type apiParam struct {
+	typ  string
+	desc string
+}
+
+func (p apiParam) schema() *openAPISchema {
+	s := &openAPISchema{Type: p.typ}
+	if p.typ == "integer" {
+		s.Format = "int64"
+	}
+	return s
+}
+
+type queryParam struct {
+	name string
+	apiParam
+}
+
+// pathParams are the parameters of the router paths.
+var pathParams = map[string]apiParam{
+	"idx":          {"integer", "block height"},
+	"idx0":         {"integer", "first block height of the range"},
+	"step":         {"integer", "block height step of the range"},
+	"blockhash":    {"string", "block hash"},
+	"idxorhash":    {"string", "block height or hash"},
+	"txid":         {"string", "transaction hash"},
+	"txinoutindex": {"integer", "transaction input or output index"},
+	"address":      {"string", "address"},
+	"N":            {"integer", "number of items"},
+	"M":            {"integer", "number of items to skip"},
+	"chartgrouping": {"string", "time grouping of the chart data, one of " +
+		"year, month, week, day or all"},
+	"agendaId":   {"string", "agenda ID"},
+	"txtype":     {"string", "transaction type, one of all, regular, tickets, votes, revocations or treasury"},
+	"webhookid":  {"string", "webhook ID"},
+	"keyid":      {"string", "API key ID"},
+	"setid":      {"string", "ticket set ID"},
+	"token":      {"string", "proposal token or exchange token"},
+	"bin":        {"string", "candlestick width"},
+	"charttype":  {"string", "chart type"},
+	"tp":         {"string", "time grouping of the ticket pool, one of day, wk or mo"},
+	"xpub":       {"string", "account extended public key"},
+	"secrethash": {"string", "atomic swap secret hash"},
+	"start":      {"integer", "UNIX time of the start of the range"},
+	"end":        {"integer", "UNIX time of the end of the range"},
+}
+
+var (
+	spendsQuery = &queryParam{"spends", apiParam{"boolean", "include the spending transaction of each output"}}
+	sortQuery   = &queryParam{"sort", apiParam{"boolean", "sort the tickets"}}
+)
+
+// blockOperations are the operations of a block at /block/best,
+// /block/hash/{blockhash} and /block/{idx}.
+var blockOperations = []struct {
+	suffix, id, summary string
+	resp                interface{}
+	contentType         string
+	query               []*queryParam
+	best, hash, idx     bool
+}{
+	{"", "blockSummary", "Summary of the block", new(apitypes.BlockDataBasic), "",
+		[]*queryParam{{"txtotals", apiParam{"boolean", "include the transaction totals"}}}, true, true, true},
+	{"/hash", "blockHash", "Hash of the block", "", "text/plain", nil, true, false, true},
+	{"/height", "blockHeight", "Height of the block", int64(0), "text/plain", nil, true, true, false},
+	{"/header", "blockHeader", "Header of the block", new(chainjson.GetBlockHeaderVerboseResult), "", nil, true, true, true},
+	{"/header/raw", "blockHeaderRaw", "Serialized header of the block", new(apitypes.BlockRaw), "", nil, true, true, true},
+	{"/raw", "blockRaw", "Serialized block", new(apitypes.BlockRaw), "", nil, true, true, true},
+	{"/size", "blockSize", "Size of the block in bytes", int32(0), "", nil, true, true, true},
+	{"/subsidy", "blockSubsidies", "Subsidies of the block", apitypes.BlockSubsidies{}, "", nil, true, true, true},
+	{"/verbose", "blockVerbose", "Verbose block data of the node", new(chainjson.GetBlockVerboseResult), "", nil, true, true, true},
+	{"/pos", "blockStakeInfo", "Stake info of the block", new(apitypes.StakeInfoExtended), "", nil, true, true, true},
+	{"/tx", "blockTransactions", "Transactions of the block", new(apitypes.BlockTransactions), "", nil, true, true, true},
+	{"/tx/count", "blockTransactionCounts", "Transaction counts of the block", new(apitypes.BlockTransactionCounts), "", nil, true, true, true},
+}
+
+// blockOps returns the operations of blockOperations for each way of
+// identifying the block.
+func blockOps() []*apiOperation {
+	var ops []*apiOperation
+	for _, b := range blockOperations {
+		newOp := func(path, id, summary string) *apiOperation {
+			return &apiOperation{
+				method:      http.MethodGet,
+				path:        path + b.suffix,
+				id:          id,
+				summary:     summary,
+				query:       b.query,
+				resp:        b.resp,
+				contentType: b.contentType,
+			}
+		}
+		if b.best {
+			ops = append(ops, newOp("/block/best", "best"+upperFirst(b.id),
+				b.summary+" at the best height"))
+		}
+		if b.hash {
+			ops = append(ops, newOp("/block/hash/{blockhash}", b.id+"ByHash",
+				b.summary+" with the hash"))
+		}
+		if b.idx {
+			ops = append(ops, newOp("/block/{idx}", b.id, b.summary+" at the height"))
+		}
+	}
+	return ops
+}
+
+// pagedOps returns the GET operations of a list at path, with the count and
+// skip path parameters, followed by suffix.
+func pagedOps(path, suffix, id, summary string, resp interface{}, query ...*queryParam) []*apiOperation {
+	return []*apiOperation{
+		{method: http.MethodGet, path: path + suffix, id: id, summary: summary,
+			resp: resp, query: query},
+		{method: http.MethodGet, path: path + "/count/{N}" + suffix, id: id + "Count",
+			summary: summary + ", the N most recent", resp: resp, query: query},
+		{method: http.MethodGet, path: path + "/count/{N}/skip/{M}" + suffix, id: id + "CountSkip",
+			summary: summary + ", the N most recent after skipping M", resp: resp, query: query},
+	}
+}
+
+func get(path, id, summary string, resp interface{}, query ...*queryParam) *apiOperation {
+	return &apiOperation{
+		method:  http.MethodGet,
+		path:    path,
+		id:      id,
+		summary: summary,
+		resp:    resp,
+		query:   query,
+	}
+}
+
+func upperFirst(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// apiOperations returns the operations of the router of NewAPIRouter. Each
+// route of the router must have an operation, which is checked by the tests.
+func apiOperations() []*apiOperation {
+	ops := []*apiOperation{
+		&apiOperation{method: http.MethodGet, path: "/", id: "root", summary: "API running message",
+			resp: "", contentType: "text/plain"},
+		get("/status", "status", "Status of the node and dcrdata", apitypes.APIStatus{}),
+		get("/status/happy", "statusHappy", "Health check of dcrdata, with status 503 if unhappy", apitypes.Happy{}),
+		get("/supply", "coinSupply", "Current coin supply", new(apitypes.CoinSupply)),
+		get("/supply/circulating", "coinSupplyCirculating", "Circulating coin supply in atoms, or in DCR with dcr=true",
+			float64(0), &queryParam{"dcr", apiParam{"boolean", "return the supply in DCR"}}),
+		get("/list", "list", "Route patterns of the API", []string{}),
+		get("/openapi.json", "openAPI", "OpenAPI document of the API", json.RawMessage{}),
+	}
+
+	ops = append(ops, blockOps()...)
+	ops = append(ops,
+		get("/block/range/{idx0}/{idx}", "blockRangeSummary", "Summaries of the blocks in the height range",
+			[]*apitypes.BlockDataBasic{}),
+		get("/block/range/{idx0}/{idx}/size", "blockRangeSize", "Sizes of the blocks in the height range",
+			[]int32{}),
+		get("/block/range/{idx0}/{idx}/{step}", "blockRangeSteppedSummary",
+			"Summaries of every step-th block in the height range", []*apitypes.BlockDataBasic{}),
+		get("/block/range/{idx0}/{idx}/{step}/size", "blockRangeSteppedSize",
+			"Sizes of every step-th block in the height range", []int32{}),
+
+		get("/stake/vote/info", "voteInfo", "Vote info of the stake version", new(chainjson.GetVoteInfoResult),
+			&queryParam{"version", apiParam{"integer", "stake version, the latest by default"}}),
+		get("/stake/pool", "ticketPoolInfo", "Ticket pool info at the best height", new(apitypes.TicketPoolInfo)),
+		get("/stake/pool/full", "ticketPool", "Tickets in the pool at the best height", []string{}, sortQuery),
+		get("/stake/pool/b/{idx}", "ticketPoolInfoAt", "Ticket pool info at the height", new(apitypes.TicketPoolInfo)),
+		get("/stake/pool/b/{idxorhash}/full", "ticketPoolAt", "Tickets in the pool at the block", []string{}, sortQuery),
+		&apiOperation{method: http.MethodGet, path: "/stake/pool/r/{idx0}/{idx}", id: "ticketPoolInfoRange",
+			summary:   "Ticket pool info in the height range, as arrays with arrays=true",
+			query:     []*queryParam{{"arrays", apiParam{"boolean", "return arrays of the pool values and sizes"}}},
+			respOneOf: []interface{}{[]apitypes.TicketPoolInfo{}, new(apitypes.TicketPoolValsAndSizes)}},
+		get("/stake/diff", "stakeDiffSummary", "Current and estimated stake difficulty", new(apitypes.StakeDiff)),
+		get("/stake/diff/current", "stakeDiffCurrent", "Current stake difficulty", chainjson.GetStakeDifficultyResult{}),
+		get("/stake/diff/estimates", "stakeDiffEstimates", "Stake difficulty estimates", chainjson.EstimateStakeDiffResult{}),
+		get("/stake/diff/b/{idx}", "stakeDiff", "Stake difficulty at the height", []float64{}),
+		get("/stake/diff/r/{idx0}/{idx}", "stakeDiffRange", "Stake difficulty in the height range", []float64{}),
+		get("/stake/powerless", "powerlessTickets", "Missed and expired tickets that have not been revoked",
+			new(apitypes.PowerlessTickets)),
+		get("/stake/misses/b/{idxorhash}", "blockMisses", "Missed votes of the block with their cause",
+			new(dbtypes.MissReport)),
+		get("/stake/misses/address/{address}", "addressMisses",
+			"Most recent missed votes of the tickets of the address with their cause", new(dbtypes.MissReport)),
+		get("/stake/simulate", "stakeSimulation", "Simulated staking returns of an amount over history and projected",
+			new(apitypes.StakeSimulation),
+			&queryParam{"amount", apiParam{"number", "amount of DCR to stake"}},
+			&queryParam{"start", apiParam{"string", "start date in YYYY-MM-DD format, one year ago by default"}},
+			&queryParam{"end", apiParam{"string", "end date in YYYY-MM-DD format, one year after the start by default"}},
+			&queryParam{"reinvest", apiParam{"boolean", "buy tickets with the rewards"}}),
+		get("/stake/vsp", "vspStats", "Ticket statistics of the VSPs", new(dbtypes.VSPAnalytics)),
+		&apiOperation{method: http.MethodPost, path: "/stake/ticketsets", id: "registerTicketSet",
+			summary: "Register a ticket set for the tickets pubsub event", body: apitypes.Txns{},
+			resp: new(apitypes.TicketSet)},
+		get("/stake/ticketsets/{setid}", "ticketSet", "Status of the tickets of the ticket set",
+			new(apitypes.TicketSet)),
+
+		get("/tx/{txid}", "transaction", "Transaction", new(apitypes.Tx), spendsQuery),
+		get("/tx/{txid}/trimmed", "transactionTrimmed", "Trimmed transaction", new(apitypes.TrimmedTx), spendsQuery),
+		get("/tx/{txid}/out", "transactionOutputs", "Outputs of the transaction", []*apitypes.TxOut{}),
+		get("/tx/{txid}/out/{txinoutindex}", "transactionOutput", "Output of the transaction", apitypes.TxOut{}),
+		get("/tx/{txid}/in", "transactionInputs", "Inputs of the transaction", []*apitypes.TxIn{}),
+		get("/tx/{txid}/in/{txinoutindex}", "transactionInput", "Input of the transaction", apitypes.TxIn{}),
+		get("/tx/{txid}/vinfo", "transactionVoteInfo", "Vote info of the vote transaction", new(apitypes.VoteInfo)),
+		get("/tx/{txid}/tinfo", "transactionTicketInfo", "Ticket info of the ticket transaction", new(apitypes.TicketInfo)),
+		get("/tx/{txid}/proposalvotes", "transactionProposalVotes", "Votes of the ticket on the Politeia proposals",
+			[]*dbtypes.ProposalTicketVote{}),
+		&apiOperation{method: http.MethodGet, path: "/tx/hex/{txid}", id: "transactionHex", summary: "Serialized transaction",
+			resp: "", contentType: "text/plain"},
+		get("/tx/decoded/{txid}", "transactionDecoded", "Decoded transaction", new(apitypes.TrimmedTx), spendsQuery),
+		get("/tx/swaps/{txid}", "transactionSwaps", "Atomic swaps of the transaction", new(txhelpers.TxAtomicSwaps)),
+		&apiOperation{method: http.MethodPost, path: "/tx/broadcast", id: "broadcastTransaction",
+			summary: "Validate and broadcast a transaction", body: apitypes.TxBroadcastRequest{},
+			resp: new(apitypes.TxBroadcast)},
+		get("/tx/broadcast/{txid}", "transactionBroadcast", "Status of a broadcast transaction", new(apitypes.TxBroadcast)),
+
+		&apiOperation{method: http.MethodPost, path: "/txs", id: "transactions", summary: "Transactions",
+			body: apitypes.Txns{}, resp: []*apitypes.Tx{}, query: []*queryParam{spendsQuery}},
+		&apiOperation{method: http.MethodPost, path: "/txs/trimmed", id: "transactionsTrimmed", summary: "Trimmed transactions",
+			body: apitypes.Txns{}, resp: []*apitypes.TrimmedTx{}},
+		&apiOperation{method: http.MethodPost, path: "/txs/tinfo", id: "transactionsTicketInfo",
+			summary: "Ticket info of the ticket transactions", body: apitypes.Txns{},
+			resp: map[string]*apitypes.TicketInfo{}},
+
+		get("/address/rich", "richList", "Addresses with the largest balances", new(apitypes.RichList)),
+		get("/address/rich/{N}", "richListCount", "The N addresses with the largest balances", new(apitypes.RichList)),
+		get("/address/{address}/exists", "addressExists",
+			"Whether each address of the comma-separated list of up to 64 addresses has transactions", []bool{}),
+		get("/address/{address}/totals", "addressTotals", "Totals of the address", new(apitypes.AddressTotals)),
+		get("/address/{address}/proposalvotes", "addressProposalVotes",
+			"Most recent votes on the Politeia proposals of the tickets of the address", []*dbtypes.ProposalTicketVote{}),
+		get("/address/{address}/types/{chartgrouping}", "addressTxTypes", "Transaction type chart of the address",
+			new(dbtypes.ChartsData)),
+		get("/address/{address}/amountflow/{chartgrouping}", "addressAmountFlow", "Amount flow chart of the address",
+			new(dbtypes.ChartsData)),
+	)
+	ops = append(ops, pagedOps("/address/{address}", "", "addressTransactions",
+		"Transactions of the address", new(apitypes.Address))...)
+	ops = append(ops, pagedOps("/address/{address}", "/raw", "addressTransactionsRaw",
+		"Verbose transactions of the address", []*apitypes.AddressTxRaw{})...)
+
+	ops = append(ops,
+		get("/labels", "addressLabels", "Address labels", []*dbtypes.AddressLabel{},
+			&queryParam{"category", apiParam{"string", "return only the labels of the category"}}),
+		get("/labels/{address}", "addressLabel", "Label of the address", new(dbtypes.AddressLabel)),
+		&apiOperation{method: http.MethodPut, path: "/labels/{address}", id: "setAddressLabel", summary: "Set the label of the address",
+			body: apitypes.AddressLabelRequest{}, resp: new(dbtypes.AddressLabel), admin: true},
+		&apiOperation{method: http.MethodDelete, path: "/labels/{address}", id: "deleteAddressLabel",
+			summary: "Delete the label of the address", status: http.StatusNoContent, admin: true},
+
+		get("/xpub/{xpub}", "xpubBalance", "Balance of the account", new(apitypes.XpubBalance)),
+		get("/xpub/{xpub}/utxos", "xpubUTXOs", "Unspent outputs of the account", new(apitypes.XpubUTXOs)),
+	)
+	gapQuery := &queryParam{"gap", apiParam{"integer", "address gap limit"}}
+	ops = append(ops, pagedOps("/xpub/{xpub}/txs", "", "xpubHistory",
+		"Transactions of the account", new(apitypes.XpubHistory), gapQuery)...)
+
+	ops = append(ops, pagedOps("/swaps", "", "atomicSwaps", "Atomic swaps", new(apitypes.AtomicSwaps))...)
+	ops = append(ops, pagedOps("/swaps/address/{address}", "", "addressAtomicSwaps",
+		"Atomic swaps of the address", new(apitypes.AtomicSwaps))...)
+	ops = append(ops, pagedOps("/swaps/time/{start}/{end}", "", "atomicSwapsInTimeRange",
+		"Atomic swaps in the time range", new(apitypes.AtomicSwaps))...)
+	ops = append(ops,
+		get("/swaps/secret/{secrethash}", "atomicSwapsBySecretHash", "Atomic swaps of the secret hash",
+			new(apitypes.AtomicSwaps)),
+
+		get("/treasury/io/{chartgrouping}", "treasuryIO", "Treasury inflow and outflow chart", new(dbtypes.ChartsData)),
+		get("/treasury/tspends", "treasurySpends", "Progress of the votes on the recent treasury spends",
+			new(apitypes.TSpends)),
+		get("/treasury/spends", "treasurySpendReport", "Treasury spends by payee address and proposal per month or quarter",
+			new(dbtypes.TreasurySpendReport),
+			&queryParam{"grouping", apiParam{"string", "period of the totals, month (default) or quarter"}}),
+		get("/agendas", "agendas", "Consensus agendas", []apitypes.AgendasInfo{}),
+		get("/agenda/{agendaId}", "agenda", "Vote charts of the agenda", new(apitypes.AgendaAPIResponse)),
+
+		get("/mempool", "mempoolOverview", "Overview of the mempool", new(apitypes.MempoolOverview)),
+	)
+	ops = append(ops, pagedOps("/mempool/txs/{txtype}", "", "mempoolTxs",
+		"Mempool transactions of the type", new(apitypes.MempoolTxs))...)
+	ops = append(ops,
+		get("/mempool/sstx", "mempoolTicketFeeInfo", "Ticket fee summary of the mempool",
+			new(apitypes.MempoolTicketFeeInfo)),
+		get("/mempool/sstx/fees", "mempoolTicketFees", "Ticket fees of the mempool", new(apitypes.MempoolTicketFees)),
+		get("/mempool/sstx/fees/{N}", "mempoolTicketFeesCount", "The N largest ticket fees of the mempool",
+			new(apitypes.MempoolTicketFees)),
+		get("/mempool/sstx/details", "mempoolTicketDetails", "Ticket details of the mempool",
+			new(apitypes.MempoolTicketDetails)),
+		get("/mempool/sstx/details/{N}", "mempoolTicketDetailsCount", "Details of the N tickets with the largest fees",
+			new(apitypes.MempoolTicketDetails)),
+		get("/fees/estimate", "feeEstimates", "Fee rate estimates", new(apitypes.FeeEstimates)),
+
+		&apiOperation{method: http.MethodPost, path: "/webhooks", id: "createWebhook", summary: "Register a webhook",
+			body: apitypes.WebhookRequest{}, resp: new(dbtypes.Webhook), status: http.StatusCreated},
+		get("/webhooks/{webhookid}", "webhook", "Webhook", new(dbtypes.Webhook)),
+		&apiOperation{method: http.MethodDelete, path: "/webhooks/{webhookid}", id: "deleteWebhook", summary: "Delete the webhook",
+			status: http.StatusNoContent},
+	)
+	ops = append(ops, pagedOps("/webhooks/{webhookid}/deliveries", "", "webhookDeliveries",
+		"Deliveries of the webhook", new(apitypes.WebhookDeliveries))...)
+	ops = append(ops,
+		&apiOperation{method: http.MethodPost, path: "/webhooks/{webhookid}/replay", id: "replayWebhook",
+			summary: "Replay the deliveries of the webhook", resp: new(apitypes.WebhookReplay),
+			query: []*queryParam{{"since", apiParam{"integer", "UNIX time of the first delivery to replay"}}}},
+
+		&apiOperation{method: http.MethodGet, path: "/apikeys", id: "apiKeys", summary: "API keys and their usage",
+			resp: new(apitypes.APIKeys), admin: true,
+			query: []*queryParam{{"month", apiParam{"string", "month of the usage in YYYY-MM format, the current month by default"}}}},
+		&apiOperation{method: http.MethodPost, path: "/apikeys", id: "createAPIKey", summary: "Issue an API key",
+			body: apitypes.APIKeyRequest{}, resp: new(dbtypes.APIKey), status: http.StatusCreated, admin: true},
+		&apiOperation{method: http.MethodDelete, path: "/apikeys/{keyid}", id: "revokeAPIKey", summary: "Revoke the API key",
+			status: http.StatusNoContent, admin: true},
+
+		get("/chart/market/{token}/candlestick/{bin}", "candlestickChart", "Candlestick chart of the exchange",
+			json.RawMessage{}),
+		get("/chart/market/{token}/depth", "depthChart", "Order book depth chart of the exchange", json.RawMessage{}),
+		get("/chart/{charttype}", "chart", "Chart data", json.RawMessage{},
+			&queryParam{"bin", apiParam{"string", "time binning of the chart, one of block, day, week or month"}},
+			&queryParam{"zoom", apiParam{"string", "deprecated name of bin"}},
+			&queryParam{"axis", apiParam{"string", "x axis of the chart, one of time or height"}}),
+
+		get("/ticketpool", "ticketPoolByDate", "Purchase time distribution of the ticket pool, by day",
+			new(apitypes.TicketPoolByDate)),
+		get("/ticketpool/bydate/{tp}", "ticketPoolByDateGrouped", "Purchase time distribution of the ticket pool",
+			new(apitypes.TicketPoolByDate)),
+		get("/ticketpool/charts", "ticketPoolCharts", "Ticket pool charts", new(apitypes.TicketPoolChartsData)),
+
+		get("/proposal/{token}", "proposal", "Vote charts of the proposal", new(dbtypes.ProposalChartsData)),
+		get("/proposal/{token}/history", "proposalHistory", "Versions, status changes and hourly vote tallies of the proposal",
+			new(dbtypes.ProposalHistory)),
+	)
+	ops = append(ops, pagedOps("/proposal/{token}/voters", "", "proposalVoters",
+		"Participation in the vote on the proposal, and the tickets that voted", new(dbtypes.ProposalVoters))...)
+	ops = append(ops,
+		get("/exchanges", "exchanges", "Exchange rates", new(exchanges.ExchangeBotState),
+			&queryParam{"code", apiParam{"string", "currency code of the converted prices"}}),
+		get("/exchanges/codes", "exchangeCodes", "Available currency codes", []string{}),
+	)
+	return ops
+}
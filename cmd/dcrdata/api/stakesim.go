@@ -0,0 +1,184 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v3"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// stakeSimChain is the chain data replayed by a staking simulation. The ticket
+// prices and pool sizes are those of each block from startHeight to the best
+// block. After the best block, the ticket price is currentSDiff until the end
+// of the current price window, and expectedSDiff after, and the pool size is
+// that of the best block.
+type stakeSimChain struct {
+	params        *chaincfg.Params
+	startHeight   int64
+	sdiffs        []float64
+	poolSizes     []uint32
+	currentSDiff  float64
+	expectedSDiff float64
+}
+
+// bestHeight is the height of the best block of the chain data.
+func (sc *stakeSimChain) bestHeight() int64 {
+	return sc.startHeight + int64(len(sc.sdiffs)) - 1
+}
+
+// ticketPrice is the historical or projected ticket price at the height.
+func (sc *stakeSimChain) ticketPrice(height int64) dcrutil.Amount {
+	best := sc.bestHeight()
+	sdiff := sc.expectedSDiff
+	switch windowSize := sc.params.StakeDiffWindowSize; {
+	case height <= best:
+		sdiff = sc.sdiffs[height-sc.startHeight]
+	case height/windowSize == best/windowSize:
+		sdiff = sc.currentSDiff
+	}
+	price, _ := dcrutil.NewAmount(sdiff)
+	return price
+}
+
+// meanVoteDelay is the mean number of blocks for a ticket that matures at the
+// height to vote, which is the number of blocks for the tickets of the pool to
+// be called to vote at TicketsPerBlock per block.
+func (sc *stakeSimChain) meanVoteDelay(height int64) int64 {
+	best := sc.bestHeight()
+	if height > best {
+		height = best
+	}
+	poolSize := int64(sc.poolSizes[height-sc.startHeight])
+	if poolSize == 0 {
+		// The pool is still filling up before stake validation.
+		poolSize = int64(sc.params.TicketPoolSize) * int64(sc.params.TicketsPerBlock)
+	}
+	delay := poolSize / int64(sc.params.TicketsPerBlock)
+	if delay > int64(sc.params.TicketExpiry) {
+		delay = int64(sc.params.TicketExpiry)
+	}
+	return delay
+}
+
+// simulateStaking simulates staking the amount from the start height of the
+// chain data to the end height. The tickets bought at each height vote after
+// the ticket maturity and the mean vote delay, and the returned ticket price
+// and reward mature after the coinbase maturity, when the balance is used to
+// buy tickets again. The event times are not set.
+func simulateStaking(sc *stakeSimChain, amount dcrutil.Amount, reinvest bool, endHeight int64) (*apitypes.StakeSimulation, error) {
+	p := sc.params
+	best := sc.bestHeight()
+	if len(sc.poolSizes) != len(sc.sdiffs) || best < sc.startHeight {
+		return nil, fmt.Errorf("no chain data from height %d", sc.startHeight)
+	}
+	if endHeight <= sc.startHeight {
+		return nil, fmt.Errorf("end height %d is not after start height %d",
+			endHeight, sc.startHeight)
+	}
+
+	sim := &apitypes.StakeSimulation{
+		Amount:      amount.ToCoin(),
+		Reinvest:    reinvest,
+		StartHeight: sc.startHeight,
+		EndHeight:   endHeight,
+		BestHeight:  best,
+		Timeline:    []*apitypes.StakeSimEvent{},
+	}
+	balance := amount
+	var locked, rewards, withdrawn dcrutil.Amount
+	addEvent := func(height int64, action string, tickets int64, price, reward dcrutil.Amount) {
+		sim.Timeline = append(sim.Timeline, &apitypes.StakeSimEvent{
+			Height:      height,
+			Action:      action,
+			Tickets:     tickets,
+			TicketPrice: price.ToCoin(),
+			Reward:      reward.ToCoin(),
+			Balance:     balance.ToCoin(),
+			Projected:   height > best,
+		})
+	}
+
+	height := sc.startHeight
+	if height < p.StakeEnabledHeight {
+		height = p.StakeEnabledHeight
+	}
+	for height <= endHeight {
+		price := sc.ticketPrice(height)
+		if price <= 0 {
+			return nil, fmt.Errorf("no ticket price at height %d", height)
+		}
+		tickets := int64(balance / price)
+		if tickets == 0 {
+			if len(sim.Timeline) == 0 {
+				return nil, fmt.Errorf("amount %v is less than the ticket price %v",
+					amount, price)
+			}
+			// Without reinvested rewards, the balance may not buy a ticket
+			// after the ticket price increases.
+			break
+		}
+		cost := price * dcrutil.Amount(tickets)
+		balance -= cost
+		addEvent(height, apitypes.StakeSimBuy, tickets, price, 0)
+
+		maturity := height + int64(p.TicketMaturity)
+		voteHeight := maturity + sc.meanVoteDelay(maturity)
+		if voteHeight < p.StakeValidationHeight {
+			voteHeight = p.StakeValidationHeight
+		}
+		if voteHeight > endHeight {
+			locked = cost
+			break
+		}
+		_, stake, _ := txhelpers.RewardsAtBlock(voteHeight, p.TicketsPerBlock, p)
+		reward := dcrutil.Amount(stake * tickets)
+		rewards += reward
+		addEvent(voteHeight, apitypes.StakeSimVote, tickets, price, reward)
+
+		height = voteHeight + int64(p.CoinbaseMaturity)
+		if height > endHeight {
+			locked = cost + reward
+			break
+		}
+		balance += cost
+		if reinvest {
+			balance += reward
+		} else {
+			withdrawn += reward
+		}
+		addEvent(height, apitypes.StakeSimReward, tickets, price, reward)
+		// The matured funds are spent in the next block.
+		height++
+	}
+
+	final := balance + locked + withdrawn
+	sim.Balance = balance.ToCoin()
+	sim.Locked = locked.ToCoin()
+	sim.Rewards = rewards.ToCoin()
+	sim.FinalValue = final.ToCoin()
+	sim.Return = float64(final)/float64(amount) - 1
+	blocksPerYear := 365 * 24 * 3600 / p.TargetTimePerBlock.Seconds()
+	years := float64(endHeight-sc.startHeight) / blocksPerYear
+	sim.AnnualReturn = math.Pow(1+sim.Return, 1/years) - 1
+	return sim, nil
+}
+
+// setStakeSimTimes sets the times of the events of the simulation, which are
+// the block times of the historical events, by height, and the best block time
+// plus the target time per block after the best block for projected events.
+func setStakeSimTimes(sim *apitypes.StakeSimulation, blockTimes map[int64]int64, p *chaincfg.Params) {
+	bestTime := blockTimes[sim.BestHeight]
+	for _, e := range sim.Timeline {
+		if !e.Projected {
+			e.Time = blockTimes[e.Height]
+			continue
+		}
+		e.Time = bestTime + (e.Height-sim.BestHeight)*int64(p.TargetTimePerBlock.Seconds())
+	}
+}
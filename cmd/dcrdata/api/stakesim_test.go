@@ -0,0 +1,91 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v3"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+func TestSimulateStaking(t *testing.T) {
+	p := chaincfg.MainNetParams()
+	const startHeight, blocks = 500000, 8705
+	chain := &stakeSimChain{
+		params:        p,
+		startHeight:   startHeight,
+		sdiffs:        make([]float64, blocks),
+		poolSizes:     make([]uint32, blocks),
+		currentSDiff:  150,
+		expectedSDiff: 200,
+	}
+	for i := range chain.sdiffs {
+		chain.sdiffs[i] = 100
+		chain.poolSizes[i] = 40960
+	}
+	best := chain.bestHeight()
+
+	amount, _ := dcrutil.NewAmount(1050)
+	// 8192 blocks to vote with 40960 tickets in the pool.
+	voteHeight := int64(startHeight + 256 + 8192)
+	rebuyHeight := voteHeight + 257
+	endHeight := rebuyHeight + 1000
+	sim, err := simulateStaking(chain, amount, true, endHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, stake, _ := txhelpers.RewardsAtBlock(voteHeight, p.TicketsPerBlock, p)
+	reward := dcrutil.Amount(10 * stake)
+	want := []apitypes.StakeSimEvent{
+		{Height: startHeight, Action: apitypes.StakeSimBuy, Tickets: 10, TicketPrice: 100, Balance: 50},
+		{Height: voteHeight, Action: apitypes.StakeSimVote, Tickets: 10, TicketPrice: 100,
+			Reward: reward.ToCoin(), Balance: 50},
+		{Height: rebuyHeight - 1, Action: apitypes.StakeSimReward, Tickets: 10, TicketPrice: 100,
+			Reward: reward.ToCoin(), Balance: (amount + reward).ToCoin()},
+		// The best block's pool size gives the vote delay of the projected
+		// purchase at the current ticket price.
+		{Height: rebuyHeight, Action: apitypes.StakeSimBuy, Tickets: 7, TicketPrice: 150,
+			Balance: (amount + reward - 7*150e8).ToCoin(), Projected: true},
+	}
+	if len(sim.Timeline) != len(want) {
+		t.Fatalf("%d events, expected %d", len(sim.Timeline), len(want))
+	}
+	for i, e := range sim.Timeline {
+		if *e != want[i] {
+			t.Errorf("event %d is %+v, expected %+v", i, *e, want[i])
+		}
+	}
+	if sim.Locked != 1050 || sim.Rewards != reward.ToCoin() || sim.BestHeight != best {
+		t.Errorf("unexpected simulation %+v", sim)
+	}
+	if sim.FinalValue != (amount+reward).ToCoin() || sim.Return <= 0 || sim.AnnualReturn <= 0 {
+		t.Errorf("unexpected simulation returns %+v", sim)
+	}
+
+	// Without reinvesting, the reward is withdrawn, and the next purchase is
+	// of the same tickets.
+	sim, err = simulateStaking(chain, amount, false, endHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := sim.Timeline[2]; e.Balance != 1050 {
+		t.Errorf("balance %v after the reward, expected 1050", e.Balance)
+	}
+	if sim.FinalValue != (amount + reward).ToCoin() {
+		t.Errorf("final value %v, expected %v", sim.FinalValue, (amount + reward).ToCoin())
+	}
+
+	setStakeSimTimes(sim, map[int64]int64{best: 1e9, startHeight: 1e8}, p)
+	if sim.Timeline[0].Time != 1e8 || sim.Timeline[3].Time != 1e9+(rebuyHeight-best)*300 {
+		t.Errorf("unexpected event times %d, %d", sim.Timeline[0].Time, sim.Timeline[3].Time)
+	}
+
+	if _, err = simulateStaking(chain, 50e8, true, endHeight); err == nil {
+		t.Errorf("no error simulating less than the ticket price")
+	}
+}
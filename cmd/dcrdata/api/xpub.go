@@ -0,0 +1,372 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/hdkeychain/v3"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/middleware"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+const (
+	// defaultXpubGapLimit is the number of consecutive unused addresses after
+	// which address discovery stops on a branch, as in BIP0044.
+	defaultXpubGapLimit = 20
+	// maxXpubGapLimit is the largest gap limit that may be requested.
+	maxXpubGapLimit = 200
+	// xpubBranchGapMultiple limits the number of addresses checked on each
+	// branch of an extended public key to this multiple of the gap limit.
+	xpubBranchGapMultiple = 10
+	// xpubDiscoveryTimeout limits the time spent discovering the used
+	// addresses of an extended public key.
+	xpubDiscoveryTimeout = 20 * time.Second
+	// xpubCacheTTL is how long a discovered account is reused at the same
+	// best block height.
+	xpubCacheTTL = time.Minute
+	// xpubCacheSize is the maximum number of discovered accounts cached.
+	xpubCacheSize = 256
+)
+
+// xpubAccount is the set of used addresses of an account extended public key.
+// It is shared by the requests served from the xpubCache, and must not be
+// modified after discovery.
+type xpubAccount struct {
+	gapLimit     uint32
+	addresses    []*apitypes.XpubAddress
+	nextExternal uint32
+	nextInternal uint32
+}
+
+// xpubCacheKey identifies a discovered account by its extended public key and
+// gap limit.
+type xpubCacheKey struct {
+	xpub     string
+	gapLimit uint32
+}
+
+type xpubCacheEntry struct {
+	acct   *xpubAccount
+	height int64
+	expiry time.Time
+}
+
+// xpubCache briefly keeps the discovered accounts, so that the balance, UTXO
+// and history requests for an extended public key do not each repeat the
+// discovery. An account is only reused at the height it was discovered.
+type xpubCache struct {
+	mtx      sync.Mutex
+	accounts map[xpubCacheKey]*xpubCacheEntry
+}
+
+func newXpubCache() *xpubCache {
+	return &xpubCache{accounts: make(map[xpubCacheKey]*xpubCacheEntry)}
+}
+
+// get returns the account discovered at the height, or nil.
+func (xc *xpubCache) get(key xpubCacheKey, height int64) *xpubAccount {
+	xc.mtx.Lock()
+	defer xc.mtx.Unlock()
+	entry, found := xc.accounts[key]
+	if !found {
+		return nil
+	}
+	if entry.height != height || time.Now().After(entry.expiry) {
+		delete(xc.accounts, key)
+		return nil
+	}
+	return entry.acct
+}
+
+// set stores the account discovered at the height. When the cache is full,
+// the expired accounts are removed, or else the one expiring first.
+func (xc *xpubCache) set(key xpubCacheKey, height int64, acct *xpubAccount) {
+	xc.mtx.Lock()
+	defer xc.mtx.Unlock()
+	now := time.Now()
+	if _, found := xc.accounts[key]; !found && len(xc.accounts) >= xpubCacheSize {
+		var oldest xpubCacheKey
+		var oldestExpiry time.Time
+		for k, entry := range xc.accounts {
+			if now.After(entry.expiry) {
+				delete(xc.accounts, k)
+				continue
+			}
+			if oldestExpiry.IsZero() || entry.expiry.Before(oldestExpiry) {
+				oldest, oldestExpiry = k, entry.expiry
+			}
+		}
+		if len(xc.accounts) >= xpubCacheSize {
+			delete(xc.accounts, oldest)
+		}
+	}
+	xc.accounts[key] = &xpubCacheEntry{
+		acct:   acct,
+		height: height,
+		expiry: now.Add(xpubCacheTTL),
+	}
+}
+
+// getXpubCtx parses the account extended public key in the request context
+// and discovers its used addresses on both branches, using the gap limit in
+// the "gap" URL query parameter. An account discovered at the current height
+// in the last minute is reused. An error response is written and nil returned
+// on failure.
+func (c *appContext) getXpubCtx(w http.ResponseWriter, r *http.Request) *xpubAccount {
+	xpubStr := m.GetXpubCtx(r)
+	xpub, err := txhelpers.ParseAccountXpub(xpubStr, c.Params)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid extended public key: %v", err), http.StatusUnprocessableEntity)
+		return nil
+	}
+
+	gapLimit := uint64(defaultXpubGapLimit)
+	if gapParam := r.URL.Query().Get("gap"); gapParam != "" {
+		gapLimit, err = strconv.ParseUint(gapParam, 10, 32)
+		if err != nil || gapLimit == 0 || gapLimit > maxXpubGapLimit {
+			http.Error(w, fmt.Sprintf("gap must be between 1 and %d", maxXpubGapLimit),
+				http.StatusUnprocessableEntity)
+			return nil
+		}
+	}
+
+	key := xpubCacheKey{xpub: xpubStr, gapLimit: uint32(gapLimit)}
+	height := c.DataSource.Height()
+	if acct := c.xpubs.get(key, height); acct != nil {
+		return acct
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), xpubDiscoveryTimeout)
+	defer cancel()
+	acct, err := c.discoverXpubAccount(ctx, xpub, uint32(gapLimit))
+	if dbtypes.IsTimeoutErr(err) {
+		apiLog.Errorf("xpub address discovery: %v", err)
+		http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+		return nil
+	}
+	if err != nil {
+		apiLog.Warnf("failed to discover xpub addresses: %v", err)
+		http.Error(w, http.StatusText(422), 422)
+		return nil
+	}
+	c.xpubs.set(key, height, acct)
+	return acct
+}
+
+// discoverXpubAccount discovers the used addresses on both branches of the
+// account extended public key. The discovery stops with the context's error
+// when it is done.
+func (c *appContext) discoverXpubAccount(ctx context.Context, xpub *hdkeychain.ExtendedKey, gapLimit uint32) (*xpubAccount, error) {
+	acct := &xpubAccount{gapLimit: gapLimit}
+	for _, branch := range []uint32{txhelpers.ExternalBranch, txhelpers.InternalBranch} {
+		next, err := c.discoverXpubBranch(ctx, xpub, branch, acct)
+		if err != nil {
+			return nil, err
+		}
+		if branch == txhelpers.ExternalBranch {
+			acct.nextExternal = next
+		} else {
+			acct.nextInternal = next
+		}
+	}
+	return acct, nil
+}
+
+// discoverXpubBranch appends to acct the addresses on the branch of the
+// account extended public key that have a transaction history, stopping after
+// acct.gapLimit consecutive unused addresses. At most xpubBranchGapMultiple
+// times the gap limit addresses are checked. The index following the last used
+// address is returned.
+func (c *appContext) discoverXpubBranch(ctx context.Context, xpub *hdkeychain.ExtendedKey, branch uint32, acct *xpubAccount) (uint32, error) {
+	branchKey, err := txhelpers.BranchKey(xpub, branch)
+	if err != nil {
+		return 0, err
+	}
+	maxAddresses := xpubBranchGapMultiple * acct.gapLimit
+	var next, unused uint32
+	for start := uint32(0); ; start += acct.gapLimit {
+		if start >= maxAddresses {
+			return 0, fmt.Errorf("more than %d addresses on branch %d",
+				maxAddresses, branch)
+		}
+		addrs, err := txhelpers.DeriveAddresses(branchKey, branch, start, acct.gapLimit, c.Params)
+		if err != nil {
+			return 0, err
+		}
+		for _, da := range addrs {
+			if err = ctx.Err(); err != nil {
+				return 0, err
+			}
+			bal, _, err := c.DataSource.AddressBalance(da.Address)
+			if err != nil {
+				return 0, err
+			}
+			if bal.NumSpent+bal.NumUnspent == 0 {
+				unused++
+				if unused == acct.gapLimit {
+					return next, nil
+				}
+				continue
+			}
+			unused = 0
+			next = da.Index + 1
+			acct.addresses = append(acct.addresses, &apitypes.XpubAddress{
+				Address:      da.Address,
+				Branch:       branch,
+				Index:        da.Index,
+				NumSpent:     bal.NumSpent,
+				NumUnspent:   bal.NumUnspent,
+				TotalSpent:   bal.TotalSpent,
+				TotalUnspent: bal.TotalUnspent,
+			})
+		}
+	}
+}
+
+// getXpubBalance writes the aggregated balance and the used addresses of an
+// account extended public key.
+func (c *appContext) getXpubBalance(w http.ResponseWriter, r *http.Request) {
+	acct := c.getXpubCtx(w, r)
+	if acct == nil {
+		return
+	}
+
+	bal := &apitypes.XpubBalance{
+		Height:       c.DataSource.Height(),
+		GapLimit:     acct.gapLimit,
+		NextExternal: acct.nextExternal,
+		NextInternal: acct.nextInternal,
+		Addresses:    acct.addresses,
+	}
+	if bal.Addresses == nil {
+		bal.Addresses = []*apitypes.XpubAddress{}
+	}
+	for _, addr := range acct.addresses {
+		bal.NumSpent += addr.NumSpent
+		bal.NumUnspent += addr.NumUnspent
+		bal.TotalSpent += addr.TotalSpent
+		bal.TotalUnspent += addr.TotalUnspent
+	}
+	bal.Balance = dcrutil.Amount(bal.TotalUnspent).ToCoin()
+
+	writeJSON(w, bal, m.GetIndentCtx(r))
+}
+
+// getXpubUTXOs writes the unspent outputs paying to the addresses of an
+// account extended public key, most recent first.
+func (c *appContext) getXpubUTXOs(w http.ResponseWriter, r *http.Request) {
+	acct := c.getXpubCtx(w, r)
+	if acct == nil {
+		return
+	}
+
+	height := c.DataSource.Height()
+	utxos := make([]*apitypes.AddressTxnOutput, 0)
+	for _, addr := range acct.addresses {
+		if addr.NumUnspent == 0 {
+			continue
+		}
+		txOuts, _, err := c.DataSource.AddressUTXO(addr.Address)
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("AddressUTXO: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			apiLog.Warnf("failed to get UTXOs for %s: %v", addr.Address, err)
+			http.Error(w, http.StatusText(422), 422)
+			return
+		}
+		for _, txOut := range txOuts {
+			utxo := apitypes.TxOutFromDB(txOut, int32(height))
+			utxo.Atoms = txOut.Atoms
+			utxos = append(utxos, utxo)
+		}
+	}
+	sort.SliceStable(utxos, func(i, j int) bool {
+		return utxos[i].Height > utxos[j].Height
+	})
+
+	writeJSON(w, &apitypes.XpubUTXOs{Height: height, UTXOs: utxos}, m.GetIndentCtx(r))
+}
+
+// getXpubHistory writes a page of the transaction history of an account
+// extended public key. The history of each used address is merged by
+// transaction, summing the credits and debits of the account's addresses.
+func (c *appContext) getXpubHistory(w http.ResponseWriter, r *http.Request) {
+	acct := c.getXpubCtx(w, r)
+	if acct == nil {
+		return
+	}
+
+	count, skip := listPageCtx(r)
+
+	txns := make(map[chainhash.Hash]*apitypes.XpubTx)
+	for _, addr := range acct.addresses {
+		rows, err := c.DataSource.AddressRowsMerged(addr.Address)
+		if dbtypes.IsTimeoutErr(err) {
+			apiLog.Errorf("AddressRowsMerged: %v", err)
+			http.Error(w, "Database timeout.", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			apiLog.Warnf("failed to get history for %s: %v", addr.Address, err)
+			http.Error(w, http.StatusText(422), 422)
+			return
+		}
+		for _, row := range rows {
+			tx, found := txns[row.TxHash]
+			if !found {
+				tx = &apitypes.XpubTx{
+					TxID:           row.TxHash.String(),
+					Type:           txhelpers.TxTypeToString(int(row.TxType)),
+					Time:           row.TxBlockTime,
+					ValidMainChain: row.ValidMainChain,
+				}
+				txns[row.TxHash] = tx
+			}
+			tx.Credit += row.AtomsCredit
+			tx.Debit += row.AtomsDebit
+			tx.Addresses = append(tx.Addresses, addr.Address)
+		}
+	}
+
+	history := make([]*apitypes.XpubTx, 0, len(txns))
+	for _, tx := range txns {
+		history = append(history, tx)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].Time == history[j].Time {
+			return history[i].TxID < history[j].TxID
+		}
+		return history[i].Time > history[j].Time
+	})
+
+	total := int64(len(history))
+	if skip > total {
+		skip = total
+	}
+	end := skip + count
+	if end > total {
+		end = total
+	}
+
+	writeJSON(w, &apitypes.XpubHistory{
+		Height:       c.DataSource.Height(),
+		Total:        total,
+		Transactions: history[skip:end],
+	}, m.GetIndentCtx(r))
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/hdkeychain/v3"
+	"github.com/go-chi/chi/v5"
+
+	m "github.com/decred/dcrdata/cmd/dcrdata/middleware"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// xpubDataSource is a DataSource with the balances of the used addresses.
+type xpubDataSource struct {
+	DataSource
+	height   int64
+	used     map[string]bool
+	balances int
+}
+
+func (ds *xpubDataSource) Height() int64 {
+	return ds.height
+}
+
+func (ds *xpubDataSource) AddressBalance(address string) (*dbtypes.AddressBalance, bool, error) {
+	ds.balances++
+	bal := &dbtypes.AddressBalance{Address: address}
+	if ds.used[address] {
+		bal.NumUnspent, bal.TotalUnspent = 1, 1e8
+	}
+	return bal, false, nil
+}
+
+// testXpub returns the extended public key of account 0 of a fixed seed, and
+// the addresses on its branches.
+func testXpub(t *testing.T, params *chaincfg.Params) (string, [2][]string) {
+	t.Helper()
+	seed := bytes.Repeat([]byte{0x5a}, hdkeychain.RecommendedSeedLen)
+	key, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []uint32{44, params.SLIP0044CoinType, 0} {
+		key, err = key.Child(hdkeychain.HardenedKeyStart + i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	xpub := key.Neuter()
+
+	var addrs [2][]string
+	for _, branch := range []uint32{txhelpers.ExternalBranch, txhelpers.InternalBranch} {
+		branchKey, err := txhelpers.BranchKey(xpub, branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		das, err := txhelpers.DeriveAddresses(branchKey, branch, 0, 100, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, da := range das {
+			addrs[branch] = append(addrs[branch], da.Address)
+		}
+	}
+	return xpub.String(), addrs
+}
+
+func TestXpubDiscovery(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	xpub, addrs := testXpub(t, params)
+	ds := &xpubDataSource{
+		height: 100,
+		used: map[string]bool{
+			addrs[txhelpers.ExternalBranch][0]: true,
+			addrs[txhelpers.ExternalBranch][3]: true,
+			addrs[txhelpers.InternalBranch][1]: true,
+		},
+	}
+	c := &appContext{Params: params, DataSource: ds, xpubs: newXpubCache()}
+
+	var acct *xpubAccount
+	router := chi.NewRouter()
+	router.With(m.XpubPathCtx).Get("/xpub/{xpub}", func(w http.ResponseWriter, r *http.Request) {
+		acct = c.getXpubCtx(w, r)
+	})
+	get := func() {
+		t.Helper()
+		acct = nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/xpub/"+xpub+"?gap=5", nil))
+		if acct == nil {
+			t.Fatalf("discovery failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	// The external branch is checked up to index 8 and the internal branch up
+	// to index 6, after 5 unused addresses.
+	get()
+	if len(acct.addresses) != 3 || acct.nextExternal != 4 || acct.nextInternal != 2 {
+		t.Fatalf("unexpected account %+v", acct)
+	}
+	if ds.balances != 16 {
+		t.Fatalf("checked %d addresses, expected 16", ds.balances)
+	}
+
+	// The account is reused at the same height, and discovered again at the
+	// next.
+	get()
+	if ds.balances != 16 {
+		t.Fatalf("account discovered again at the same height")
+	}
+	ds.height++
+	get()
+	if ds.balances != 32 {
+		t.Fatalf("account not discovered again at a new height")
+	}
+
+	// Discovery stops after xpubBranchGapMultiple times the gap limit.
+	key, err := txhelpers.ParseAccountXpub(xpub, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range addrs[txhelpers.ExternalBranch] {
+		ds.used[addr] = true
+	}
+	ds.balances = 0
+	if _, err = c.discoverXpubAccount(context.Background(), key, 5); err == nil {
+		t.Fatal("discovery of more than the maximum addresses succeeded")
+	}
+	if ds.balances != 5*xpubBranchGapMultiple {
+		t.Fatalf("checked %d addresses, expected %d", ds.balances, 5*xpubBranchGapMultiple)
+	}
+
+	// Discovery stops when the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ds.balances = 0
+	if _, err = c.discoverXpubAccount(ctx, key, 5); err != context.Canceled {
+		t.Fatalf("got error %v, expected context.Canceled", err)
+	}
+	if ds.balances != 0 {
+		t.Fatalf("checked %d addresses after the context was canceled", ds.balances)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/decred/slog"
 	flags "github.com/jessevdk/go-flags"
 
+	"github.com/decred/dcrdata/gov/v4/politeia"
 	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/netparams"
 )
@@ -67,6 +68,7 @@ var (
 	defaultAgendasDBFileName = "agendas.db"
 	defaultProposalsFileName = "proposals.db"
 	defaultPoliteiaAPIURl    = "https://proposals.decred.org"
+	defaultPoliteiaAPI       = "v1"
 	defaultChartsCacheDump   = "chartscache.gob"
 
 	defaultPGHost           = "127.0.0.1:5432"
@@ -115,8 +117,13 @@ type config struct {
 	UseRealIP           bool    `long:"userealip" description:"Use the RealIP middleware from the pressly/chi/middleware package to get the client's real IP from the X-Forwarded-For or X-Real-IP headers, in that order." env:"DCRDATA_USE_REAL_IP"`
 	CacheControlMaxAge  int     `long:"cachecontrol-maxage" description:"Set CacheControl in the HTTP response header to a value in seconds for clients to cache the response. This applies only to FileServer routes." env:"DCRDATA_MAX_CACHE_AGE"`
 	InsightReqRateLimit float64 `long:"insight-limit-rps" description:"Requests/second per client IP for the Insight API's rate limiter." env:"DCRDATA_INSIGHT_RATE_LIMIT"`
+	APIReqRateLimit     float64 `long:"api-limit-rps" description:"Requests/second per client IP for the API and pubsub connections without an API key. There is no limit if 0." env:"DCRDATA_API_RATE_LIMIT"`
+	EnableAPIKeys       bool    `long:"apikeys" description:"Enable API keys with their own rate limits and monthly quotas for the API, Insight API and pubsub, issued with the admin API. Requests without a key use the per-IP rate limits." env:"DCRDATA_ENABLE_API_KEYS"`
 	MaxCSVAddrs         int     `long:"max-api-addrs" description:"Maximum allowed comma-separated addresses for endpoints that accept multiple addresses." env:"DCRDATA_MAX_CSV_ADDRS"`
 	CompressAPI         bool    `long:"compress-api" description:"Use compression for a number of endpoints with commonly large responses." env:"DCRDATA_COMPRESS_API"`
+	EnableWebhooks      bool    `long:"webhooks" description:"Enable the address watch webhooks API and the delivery of webhook events to the registered callback URLs." env:"DCRDATA_ENABLE_WEBHOOKS"`
+	EnableMetrics       bool    `long:"metrics" description:"Serve Prometheus metrics of the subsystems and HTTP request latencies on the /metrics path." env:"DCRDATA_ENABLE_METRICS"`
+	AdminPass           string  `long:"adminpass" description:"Password for HTTP basic authentication with the admin API, which includes editing address labels. The admin API is disabled if no password is set." env:"DCRDATA_ADMIN_PASS"`
 	ServerHeader        string  `long:"server-http-header" description:"Set the HTTP response header Server key value. Valid values are \"off\", \"version\", or a custom string." env:"DCRDATA_SERVER_HEADER"`
 
 	// Mempool
@@ -126,12 +133,17 @@ type config struct {
 
 	// Politeia/proposals and consensus agendas
 	AgendasDBFileName string `long:"agendadbfile" description:"Agendas DB file name (default is agendas.db)." env:"DCRDATA_AGENDAS_DB_FILE_NAME"`
-	ProposalsFileName string `long:"proposalsdbfile" description:"Proposals DB file name (default is proposals.db)." env:"DCRDATA_PROPOSALS_DB_FILE_NAME"`
+	ProposalsFileName string `long:"proposalsdbfile" description:"DEPRECATED: Proposals are stored in PostgreSQL. The file name of the proposals DB of earlier versions, which may be deleted (default is proposals.db)." env:"DCRDATA_PROPOSALS_DB_FILE_NAME"`
 	PoliteiaAPIURL    string `long:"politeiaurl" description:"Defines the root API politeia URL (defaults to https://proposals.decred.org)." env:"DCRDATA_POLITEIA_URL"`
+	PoliteiaAPI       string `long:"politeiaapi" description:"The Politeia API the proposals are synced from: v1 for the legacy www API, or v2 for the records and ticketvote APIs (default is v1)." env:"DCRDATA_POLITEIA_API"`
 	PiPropRepoOwner   string `long:"piproposalsowner" description:"Defines the owner to the github repo where Politeia's proposals are pushed." env:"DCRDATA_PI_REPO_OWNER"`
 	PiPropRepoName    string `long:"piproposalsrepo" description:"Defines the name of the github repo where Politeia's proposals are pushed." env:"DCRDATA_PROPS_REPO"`
 	DisablePiParser   bool   `long:"disable-piparser" description:"Disables the piparser tool from running." env:"DCRDATA_DISABLE_PIPARSER"`
 
+	// Treasury spend payees
+	TreasuryPayees []string `long:"treasurypayee" description:"Payee address of treasury spends, as address:token, paid for the Politeia proposal with the token. May be repeated." env:"DCRDATA_TREASURY_PAYEES" env-delim:","`
+	treasuryPayees map[string]string
+
 	// Caching and optimization.
 	AddrCacheCap     int    `long:"addr-cache-cap" description:"Address cache capacity in bytes." env:"DCRDATA_ADDR_CACHE_CAP"`
 	AddrCacheLimit   int    `long:"addr-cache-address-limit" description:"Maximum number of addresses allowed in the address cache." env:"DCRDATA_ADDR_CACHE_LIMIT"`
@@ -167,6 +179,14 @@ type config struct {
 	RateMaster        string `long:"ratemaster" description:"The address of a DCRRates instance. Exchange monitoring will get all data from a DCRRates subscription." env:"DCRDATA_RATE_MASTER"`
 	RateCertificate   string `long:"ratecert" description:"File containing DCRRates TLS certificate file." env:"DCRDATA_RATE_MASTER"`
 
+	// Address labels
+	LabelsFile    string   `long:"labelsfile" description:"JSON or YAML file of address labels for well-known entities, loaded on startup. Files with a .yaml or .yml extension are parsed as YAML." env:"DCRDATA_LABELS_FILE"`
+	ExchangeAddrs []string `long:"exchangeaddr" description:"Address of a known exchange to label, as address:name, unless it is labeled in the labels file or with the admin API. May be repeated." env:"DCRDATA_EXCHANGE_ADDRS" env-delim:","`
+	VSPFeeAddrs   []string `long:"vspfeeaddr" description:"Fee address of a known VSP, as address:name, to label and to attribute the tickets committing their first output to it. May be repeated." env:"DCRDATA_VSP_FEE_ADDRS" env-delim:","`
+	exchangeAddrs map[string]string
+	vspFeeAddrs   map[string]string
+	addressLabels *dbtypes.AddressLabelsFile
+
 	// Links
 	MainnetLink  string `long:"mainnet-link" description:"When dcrdata is on testnet, this address will be used to direct a user to a dcrdata on mainnet when appropriate." env:"DCRDATA_MAINNET_LINK"`
 	TestnetLink  string `long:"testnet-link" description:"When dcrdata is on mainnet, this address will be used to direct a user to a dcrdata on testnet when appropriate." env:"DCRDATA_TESTNET_LINK"`
@@ -183,6 +203,7 @@ var (
 		AgendasDBFileName:   defaultAgendasDBFileName,
 		ProposalsFileName:   defaultProposalsFileName,
 		PoliteiaAPIURL:      defaultPoliteiaAPIURl,
+		PoliteiaAPI:         defaultPoliteiaAPI,
 		ChartsCacheDump:     defaultChartsCacheDump,
 		DebugLevel:          defaultLogLevel,
 		HTTPProfPath:        defaultHTTPProfPath,
@@ -644,6 +665,11 @@ func loadConfig() (*config, error) {
 	}
 	cfg.PoliteiaAPIURL = urlPath
 
+	if cfg.PoliteiaAPI != politeia.WWWAPI && cfg.PoliteiaAPI != politeia.RecordsAPI {
+		return loadConfigError(fmt.Errorf("invalid politeiaapi %q: must be %s or %s",
+			cfg.PoliteiaAPI, politeia.WWWAPI, politeia.RecordsAPI))
+	}
+
 	// Check the supplied APIListen address
 	if cfg.APIListen == "" {
 		cfg.APIListen = defaultHost + ":" + defaultPort
@@ -668,6 +694,64 @@ func loadConfig() (*config, error) {
 	cfg.RateCertificate = cleanAndExpandPath(cfg.RateCertificate)
 	cfg.ChartsCacheDump = cleanAndExpandPath(cfg.ChartsCacheDump)
 
+	// Parse the known exchange addresses, which are tagged with the exchange
+	// name, or "exchange" if it is omitted.
+	cfg.exchangeAddrs = make(map[string]string, len(cfg.ExchangeAddrs))
+	for _, ea := range cfg.ExchangeAddrs {
+		addr, name := ea, ""
+		if i := strings.Index(ea, ":"); i != -1 {
+			addr, name = ea[:i], ea[i+1:]
+		}
+		if _, err = dcrutil.DecodeAddress(addr, activeChain); err != nil {
+			return loadConfigError(fmt.Errorf("invalid exchangeaddr %q: %v", ea, err))
+		}
+		cfg.exchangeAddrs[addr] = name
+	}
+
+	// Parse the known VSP fee addresses, which are tagged with the VSP name,
+	// or "vsp" if it is omitted.
+	cfg.vspFeeAddrs = make(map[string]string, len(cfg.VSPFeeAddrs))
+	for _, va := range cfg.VSPFeeAddrs {
+		addr, name := va, ""
+		if i := strings.Index(va, ":"); i != -1 {
+			addr, name = va[:i], va[i+1:]
+		}
+		if _, err = dcrutil.DecodeAddress(addr, activeChain); err != nil {
+			return loadConfigError(fmt.Errorf("invalid vspfeeaddr %q: %v", va, err))
+		}
+		cfg.vspFeeAddrs[addr] = name
+	}
+
+	// Parse the treasury spend payee addresses, which are mapped to the tokens
+	// of the proposals they are paid for.
+	cfg.treasuryPayees = make(map[string]string, len(cfg.TreasuryPayees))
+	for _, tp := range cfg.TreasuryPayees {
+		i := strings.Index(tp, ":")
+		if i == -1 || i == len(tp)-1 {
+			return loadConfigError(fmt.Errorf("invalid treasurypayee %q: "+
+				"expected address:token", tp))
+		}
+		addr, token := tp[:i], tp[i+1:]
+		if _, err = dcrutil.DecodeAddress(addr, activeChain); err != nil {
+			return loadConfigError(fmt.Errorf("invalid treasurypayee %q: %v", tp, err))
+		}
+		cfg.treasuryPayees[addr] = token
+	}
+
+	// Read the address labels file, and check the addresses for this network.
+	if cfg.LabelsFile != "" {
+		cfg.LabelsFile = cleanAndExpandPath(cfg.LabelsFile)
+		cfg.addressLabels, err = dbtypes.ReadAddressLabelsFile(cfg.LabelsFile)
+		if err != nil {
+			return loadConfigError(fmt.Errorf("invalid labelsfile %q: %v", cfg.LabelsFile, err))
+		}
+		for _, l := range cfg.addressLabels.Labels {
+			if _, err = dcrutil.DecodeAddress(l.Address, activeChain); err != nil {
+				return loadConfigError(fmt.Errorf("invalid address %q in labelsfile: %v", l.Address, err))
+			}
+		}
+	}
+
 	// Clean up the provided mainnet and testnet links, ensuring there is a single
 	// trailing slash.
 	cfg.MainnetLink = strings.TrimSuffix(cfg.MainnetLink, "/") + "/"
@@ -28,6 +28,7 @@ import (
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v4/agendas"
 	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	"github.com/decred/dcrdata/v6/blockdata"
 	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/explorer/types"
@@ -60,9 +61,24 @@ const (
 
 	MaxTreasuryRows int64 = 200
 
+	// MaxSwapsRows is an upper limit on the number of rows that may be shown
+	// on the atomic swaps page table.
+	MaxSwapsRows int64 = 200
+
+	// defaultRichListRows and MaxRichListRows are the default and maximum
+	// number of addresses shown on the rich list page.
+	defaultRichListRows int64 = 100
+	MaxRichListRows     int64 = 1000
+
 	testnetNetName = "Testnet"
 )
 
+// addressLabelCategories are the categories of address labels, in the order
+// listed on the labels page.
+var addressLabelCategories = []string{dbtypes.AddressTagExchange,
+	dbtypes.AddressTagMixing, dbtypes.AddressTagVSP, dbtypes.AddressTagTreasury,
+	dbtypes.AddressTagDevFund, dbtypes.AddressTagOther}
+
 // explorerDataSource implements extra data retrieval functions that require a
 // faster solution than RPC, or additional functionality.
 type explorerDataSource interface {
@@ -75,12 +91,22 @@ type explorerDataSource interface {
 	PoolStatusForTicket(txid string) (dbtypes.TicketSpendType, dbtypes.TicketPoolStatus, error)
 	TreasuryBalance() (*dbtypes.TreasuryBalance, error)
 	TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error)
+	AtomicSwaps(n, offset int64) ([]*dbtypes.AtomicSwap, error)
+	AtomicSwapsCount(address string) (int64, error)
+	AtomicSwapsForAddress(address string, n, offset int64) ([]*dbtypes.AtomicSwap, error)
+	RichList(n int64) ([]*dbtypes.RichListEntry, *dbtypes.AddressDistribution, error)
+	VSPStats() *dbtypes.VSPAnalytics
+	AddressLabel(addr string) *dbtypes.AddressLabel
+	AddressLabels(addrs []string) map[string]*dbtypes.AddressLabel
+	AllAddressLabels(category string) []*dbtypes.AddressLabel
+	SearchAddressLabels(query string) []*dbtypes.AddressLabel
 	AddressHistory(address string, N, offset int64, txnType dbtypes.AddrTxnViewType) ([]*dbtypes.AddressRow, *dbtypes.AddressBalance, error)
 	AddressData(address string, N, offset int64, txnType dbtypes.AddrTxnViewType) (*dbtypes.AddressInfo, error)
 	DevBalance() (*dbtypes.AddressBalance, error)
 	FillAddressTransactions(addrInfo *dbtypes.AddressInfo) error
-	BlockMissedVotes(blockHash string) ([]string, error)
-	TicketMiss(ticketHash string) (string, int64, error)
+	BlockMissCauses(blockHash string) ([]*dbtypes.MissedVote, error)
+	TicketMissCauses(ticketHash string) ([]*dbtypes.MissedVote, error)
+	RecentAddressProposalVotes(address string, N int64) ([]*dbtypes.ProposalTicketVote, error)
 	SideChainBlocks() ([]*dbtypes.BlockStatus, error)
 	DisapprovedBlocks() ([]*dbtypes.BlockStatus, error)
 	BlockStatus(hash string) (dbtypes.BlockStatus, error)
@@ -124,6 +150,18 @@ type PoliteiaBackend interface {
 	ProposalByRefID(RefID string) (*pitypes.ProposalInfo, error)
 }
 
+// doubleSpendSource provides the double spends of mempool transactions. It is
+// satisfied by *mempool.MempoolMonitor.
+type doubleSpendSource interface {
+	DoubleSpends(txHash string) []*pstypes.DoubleSpend
+}
+
+// tspendSource provides the progress of the votes on treasury spends. It is
+// satisfied by *mempool.TSpendTracker.
+type tspendSource interface {
+	TSpends() *apitypes.TSpends
+}
+
 // agendaBackend implements methods that manage agendas db data.
 type agendaBackend interface {
 	AgendaInfo(agendaID string) (*agendas.AgendaTagged, error)
@@ -223,6 +261,9 @@ type explorerUI struct {
 	invsMtx sync.RWMutex
 	invs    *types.MempoolInfo
 	premine int64
+
+	doubleSpends doubleSpendSource
+	tspends      tspendSource
 }
 
 // AreDBsSyncing is a thread-safe way to fetch the boolean in dbsSyncing.
@@ -361,7 +402,8 @@ func New(cfg *ExplorerConfig) *explorerUI {
 		"rawtx", "status", "parameters", "agenda", "agendas", "charts",
 		"sidechains", "disapproved", "ticketpool", "visualblocks", "statistics",
 		"windows", "timelisting", "addresstable", "proposals", "proposal",
-		"market", "insight_root", "attackcost", "treasury", "treasurytable"}
+		"market", "insight_root", "attackcost", "treasury", "treasurytable", "swaps",
+		"richlist", "labels", "vsps"}
 
 	for _, name := range tmpls {
 		if err := exp.templates.addTemplate(name); err != nil {
@@ -425,6 +467,18 @@ func (exp *explorerUI) MempoolID() uint64 {
 	return exp.invs.ID()
 }
 
+// UseDoubleSpendSource sets the source of the double spends shown on the
+// transaction page. It must be set before the explorer serves requests.
+func (exp *explorerUI) UseDoubleSpendSource(s doubleSpendSource) {
+	exp.doubleSpends = s
+}
+
+// UseTSpendSource sets the source of the treasury spend votes shown on the
+// treasury page. It must be set before the explorer serves requests.
+func (exp *explorerUI) UseTSpendSource(s tspendSource) {
+	exp.tspends = s
+}
+
 // MempoolSignal returns the mempool signal channel, which is to be used by the
 // mempool package's MempoolMonitor as a send-only channel.
 func (exp *explorerUI) MempoolSignal() chan<- pstypes.HubMessage {
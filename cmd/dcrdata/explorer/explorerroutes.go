@@ -14,6 +14,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,8 +30,10 @@ import (
 	"github.com/decred/dcrdata/exchanges/v3"
 	"github.com/decred/dcrdata/gov/v4/agendas"
 	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
 	"github.com/decred/dcrdata/v6/txhelpers"
 
 	humanize "github.com/dustin/go-humanize"
@@ -113,6 +116,9 @@ func (e expStatus) IsSyncing() bool {
 // number of blocks displayed on /visualblocks
 const homePageBlocksMaxCount = 30
 
+// number of the most recent proposal votes displayed on /address
+const addressPageProposalVotes = 20
+
 // netName returns the name used when referring to a decred network.
 func netName(chainParams *chaincfg.Params) string {
 	if chainParams == nil {
@@ -692,8 +698,8 @@ func (exp *explorerUI) Block(w http.ResponseWriter, r *http.Request) {
 
 	// Retrieve missed votes, main/side chain status, and stakeholder approval.
 	var err error
-	data.Misses, err = exp.dataSource.BlockMissedVotes(hash)
-	if exp.timeoutErrorPage(w, err, "BlockMissedVotes") {
+	data.Misses, err = exp.dataSource.BlockMissCauses(hash)
+	if exp.timeoutErrorPage(w, err, "BlockMissCauses") {
 		return
 	}
 	if err != nil && err != sql.ErrNoRows {
@@ -830,6 +836,13 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if dbTxs == nil {
+			// A mempool transaction that was double spent is dropped by dcrd.
+			if dss := exp.txDoubleSpends(hash); len(dss) > 0 {
+				exp.StatusPage(w, defaultErrorCode, fmt.Sprintf("that transaction was "+
+					"double spent by %s, and is no longer in mempool", dss[0].ConflictTxID),
+					"", ExpStatusNotFound)
+				return
+			}
 			exp.StatusPage(w, defaultErrorCode, "that transaction has not been recorded",
 				"", ExpStatusNotFound)
 			return
@@ -1148,17 +1161,24 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 			}
 			tx.TicketInfo.SpendStatus = spendStatus.String()
 
-			// For missed tickets, get the block in which it should have voted.
+			// For missed tickets, get the block in which it should have voted,
+			// and the cause of the miss.
 			if poolStatus == dbtypes.PoolStatusMissed {
-				tx.TicketInfo.LotteryBlock, _, err = exp.dataSource.TicketMiss(hash)
-				if err != nil && err != sql.ErrNoRows {
+				misses, err := exp.dataSource.TicketMissCauses(hash)
+				if exp.timeoutErrorPage(w, err, "TicketMissCauses") {
+					return
+				}
+				if err != nil {
 					log.Errorf("Unable to retrieve miss information for ticket %s: %v",
 						hash, err)
 					exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
 					return
-				} else if err == sql.ErrNoRows {
-					log.Warnf("No mainchain miss data for ticket %s: %v",
-						hash, err)
+				} else if len(misses) == 0 {
+					log.Warnf("No mainchain miss data for ticket %s", hash)
+				} else {
+					miss := misses[len(misses)-1]
+					tx.TicketInfo.LotteryBlock = miss.BlockHash
+					tx.TicketInfo.MissCause = miss.Cause
 				}
 			}
 
@@ -1269,6 +1289,15 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		tx.Time = exp.mempoolTime(tx.TxID)
 	}
 
+	// Get the labels of the well-known input and output addresses.
+	var txAddrs []string
+	for i := range tx.Vin {
+		txAddrs = append(txAddrs, tx.Vin[i].Addresses...)
+	}
+	for i := range tx.Vout {
+		txAddrs = append(txAddrs, tx.Vout[i].Addresses...)
+	}
+
 	pageData := struct {
 		*CommonPageData
 		Data                 *types.TxInfo
@@ -1278,6 +1307,8 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		HighlightInOut       string
 		HighlightInOutID     int64
 		SwapsFound           string
+		AddressLabels        map[string]*dbtypes.AddressLabel
+		DoubleSpends         []*pstypes.DoubleSpend
 		Conversions          struct {
 			Total *exchanges.Conversion
 			Fees  *exchanges.Conversion
@@ -1291,6 +1322,12 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 		HighlightInOut:       inout,
 		HighlightInOutID:     inoutid,
 		SwapsFound:           swapsInfo.Found,
+		AddressLabels:        exp.dataSource.AddressLabels(txAddrs),
+	}
+
+	// Warn of double spends of an unconfirmed transaction.
+	if !isConfirmedMainchain {
+		pageData.DoubleSpends = exp.txDoubleSpends(tx.TxID)
 	}
 
 	// Get a fiat-converted value for the total and the fees.
@@ -1311,6 +1348,15 @@ func (exp *explorerUI) TxPage(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// txDoubleSpends returns the double spends of a mempool transaction, either as
+// the double spent transaction or as the competing spend.
+func (exp *explorerUI) txDoubleSpends(txHash string) []*pstypes.DoubleSpend {
+	if exp.doubleSpends == nil {
+		return nil
+	}
+	return exp.doubleSpends.DoubleSpends(txHash)
+}
+
 func (exp *explorerUI) txAtomicSwapsInfo(tx *types.TxInfo) (*txhelpers.TxAtomicSwaps, error) {
 	// Check if tx is a stake tree tx or coinbase tx and return empty swap info.
 	if tx.Type != txhelpers.TxTypeRegular || tx.Coinbase {
@@ -1365,6 +1411,10 @@ type TreasuryInfo struct {
 	Balance          *dbtypes.TreasuryBalance
 	ConvertedBalance *exchanges.Conversion
 	TypeCount        int64
+
+	// TSpends is the progress of the votes on the treasury spends in mempool,
+	// and on those mined or expired within the last voting window.
+	TSpends *apitypes.TSpends
 }
 
 // TreasuryPage is the page handler for the "/treasury" path
@@ -1435,6 +1485,9 @@ func (exp *explorerUI) TreasuryPage(w http.ResponseWriter, r *http.Request) {
 		Balance:         treasuryBalance,
 		TypeCount:       typeCount,
 	}
+	if exp.tspends != nil {
+		treasuryData.TSpends = exp.tspends.TSpends()
+	}
 
 	xcBot := exp.xcBot
 	if xcBot != nil {
@@ -1467,6 +1520,290 @@ func (exp *explorerUI) TreasuryPage(w http.ResponseWriter, r *http.Request) {
 	io.WriteString(w, str)
 }
 
+// AtomicSwapsPage is the page handler for the "/swaps" path. The optional
+// "address" URL query parameter limits the table to swaps involving that
+// address.
+func (exp *explorerUI) AtomicSwapsPage(w http.ResponseWriter, r *http.Request) {
+	limitN := defaultAddressRows
+	if nParam := r.URL.Query().Get("n"); nParam != "" {
+		val, err := strconv.ParseUint(nParam, 10, 64)
+		if err != nil {
+			exp.StatusPage(w, defaultErrorCode, "invalid n value", "", ExpStatusError)
+			return
+		}
+		if int64(val) > MaxSwapsRows {
+			log.Warnf("AtomicSwapsPage: requested up to %d swap rows, "+
+				"limiting to %d", val, MaxSwapsRows)
+			limitN = MaxSwapsRows
+		} else {
+			limitN = int64(val)
+		}
+	}
+
+	var offset int64
+	if startParam := r.URL.Query().Get("start"); startParam != "" {
+		val, err := strconv.ParseUint(startParam, 10, 64)
+		if err != nil {
+			exp.StatusPage(w, defaultErrorCode, "invalid start value", "", ExpStatusError)
+			return
+		}
+		offset = int64(val)
+	}
+
+	address := r.URL.Query().Get("address")
+	if address != "" {
+		if _, err := dcrutil.DecodeAddress(address, exp.ChainParams); err != nil {
+			exp.StatusPage(w, defaultErrorCode, "invalid address", address, ExpStatusError)
+			return
+		}
+	}
+
+	var swaps []*dbtypes.AtomicSwap
+	var err error
+	if address == "" {
+		swaps, err = exp.dataSource.AtomicSwaps(limitN, offset)
+	} else {
+		swaps, err = exp.dataSource.AtomicSwapsForAddress(address, limitN, offset)
+	}
+	if exp.timeoutErrorPage(w, err, "AtomicSwaps") {
+		return
+	} else if err != nil {
+		exp.StatusPage(w, defaultErrorCode, err.Error(), "", ExpStatusError)
+		return
+	}
+
+	count, err := exp.dataSource.AtomicSwapsCount(address)
+	if exp.timeoutErrorPage(w, err, "AtomicSwapsCount") {
+		return
+	} else if err != nil {
+		exp.StatusPage(w, defaultErrorCode, err.Error(), "", ExpStatusError)
+		return
+	}
+
+	// Execute the HTML template.
+	linkTemplate := fmt.Sprintf("/swaps?start=%%d&n=%d", limitN)
+	if address != "" {
+		linkTemplate += "&address=" + address
+	}
+	pageData := struct {
+		*CommonPageData
+		Swaps   []*dbtypes.AtomicSwap
+		Address string
+		Count   int64
+		Limit   int64
+		Offset  int64
+		Pages   []pageNumber
+	}{
+		CommonPageData: exp.commonData(r),
+		Swaps:          swaps,
+		Address:        address,
+		Count:          count,
+		Limit:          limitN,
+		Offset:         offset,
+		Pages:          calcPages(int(count), int(limitN), int(offset), linkTemplate),
+	}
+	str, err := exp.templates.exec("swaps", pageData)
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// balanceBucketRow is a row of the address balance distribution table on the
+// rich list page.
+type balanceBucketRow struct {
+	Range string
+	dbtypes.BalanceBucket
+}
+
+// balanceBucketRange describes the range of balances of a bucket of the
+// address distribution, where each bucket but the first and last spans a
+// power of ten DCR.
+func balanceBucketRange(minBalance int64) string {
+	const maxBucketMin = 1e6 * dcrutil.AtomsPerCoin
+	if minBalance == 0 {
+		return "less than 0.01"
+	}
+	if minBalance >= maxBucketMin {
+		return humanize.Comma(maxBucketMin/dcrutil.AtomsPerCoin) + " or more"
+	}
+	min := dcrutil.Amount(minBalance).ToCoin()
+	return humanize.Commaf(min) + " to " + humanize.Commaf(min*10)
+}
+
+// RichListPage is the page handler for the "/rich" path. The optional "n" URL
+// query parameter sets the number of addresses shown.
+func (exp *explorerUI) RichListPage(w http.ResponseWriter, r *http.Request) {
+	limitN := defaultRichListRows
+	if nParam := r.URL.Query().Get("n"); nParam != "" {
+		val, err := strconv.ParseUint(nParam, 10, 64)
+		if err != nil {
+			exp.StatusPage(w, defaultErrorCode, "invalid n value", "", ExpStatusError)
+			return
+		}
+		if int64(val) > MaxRichListRows {
+			log.Warnf("RichListPage: requested up to %d addresses, "+
+				"limiting to %d", val, MaxRichListRows)
+			limitN = MaxRichListRows
+		} else {
+			limitN = int64(val)
+		}
+	}
+
+	entries, dist, err := exp.dataSource.RichList(limitN)
+	if exp.timeoutErrorPage(w, err, "RichList") {
+		return
+	} else if err != nil {
+		exp.StatusPage(w, defaultErrorCode, err.Error(), "", ExpStatusError)
+		return
+	}
+
+	var buckets []balanceBucketRow
+	if dist != nil {
+		for _, b := range dist.Buckets {
+			buckets = append(buckets, balanceBucketRow{
+				Range:         balanceBucketRange(b.MinBalance),
+				BalanceBucket: b,
+			})
+		}
+	}
+
+	// Execute the HTML template.
+	pageData := struct {
+		*CommonPageData
+		Addresses    []*dbtypes.RichListEntry
+		Distribution *dbtypes.AddressDistribution
+		Buckets      []balanceBucketRow
+		More         int64
+	}{
+		CommonPageData: exp.commonData(r),
+		Addresses:      entries,
+		Distribution:   dist,
+		Buckets:        buckets,
+	}
+	// Offer to show more addresses if the list may be longer.
+	if int64(len(entries)) == limitN && limitN < MaxRichListRows {
+		pageData.More = 2 * limitN
+		if pageData.More > MaxRichListRows {
+			pageData.More = MaxRichListRows
+		}
+	}
+	str, err := exp.templates.exec("richlist", pageData)
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// LabelsPage is the page handler for the "/labels" path, which lists the
+// address labels. The optional "category" URL query parameter selects a
+// category of labels, and the optional "search" parameter lists only the
+// labels that contain the search string.
+func (exp *explorerUI) LabelsPage(w http.ResponseWriter, r *http.Request) {
+	category := r.URL.Query().Get("category")
+	if category != "" && !dbtypes.IsValidAddressLabelCategory(category) {
+		exp.StatusPage(w, defaultErrorCode, "invalid category", category, ExpStatusError)
+		return
+	}
+	search := strings.TrimSpace(r.URL.Query().Get("search"))
+
+	var labels []*dbtypes.AddressLabel
+	if search != "" {
+		for _, l := range exp.dataSource.SearchAddressLabels(search) {
+			if category == "" || l.Category == category {
+				labels = append(labels, l)
+			}
+		}
+	} else {
+		labels = exp.dataSource.AllAddressLabels(category)
+	}
+
+	pageData := struct {
+		*CommonPageData
+		Labels     []*dbtypes.AddressLabel
+		Category   string
+		Categories []string
+		Search     string
+	}{
+		CommonPageData: exp.commonData(r),
+		Labels:         labels,
+		Category:       category,
+		Categories:     addressLabelCategories,
+		Search:         search,
+	}
+	str, err := exp.templates.exec("labels", pageData)
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
+// VSPsPage is the page handler for the "/vsps" path, which lists the ticket
+// statistics of the VSPs. The optional "sort" URL query parameter set to
+// "missrate" sorts the VSPs by their miss rate instead of their number of
+// tickets.
+func (exp *explorerUI) VSPsPage(w http.ResponseWriter, r *http.Request) {
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != "missrate" {
+		exp.StatusPage(w, defaultErrorCode, "invalid sort", sortBy, ExpStatusError)
+		return
+	}
+
+	stats := exp.dataSource.VSPStats()
+	var vsps []*dbtypes.VSPStats
+	if stats != nil {
+		vsps = make([]*dbtypes.VSPStats, len(stats.VSPs))
+		copy(vsps, stats.VSPs)
+		if sortBy == "missrate" {
+			sort.SliceStable(vsps, func(i, j int) bool {
+				return vsps[i].MissRate > vsps[j].MissRate
+			})
+		}
+	}
+
+	// Execute the HTML template.
+	pageData := struct {
+		*CommonPageData
+		Stats  *dbtypes.VSPAnalytics
+		VSPs   []*dbtypes.VSPStats
+		SortBy string
+	}{
+		CommonPageData: exp.commonData(r),
+		Stats:          stats,
+		VSPs:           vsps,
+		SortBy:         sortBy,
+	}
+	str, err := exp.templates.exec("vsps", pageData)
+	if err != nil {
+		log.Errorf("Template execute failure: %v", err)
+		exp.StatusPage(w, defaultErrorCode, defaultErrorMessage, "", ExpStatusError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Turbolinks-Location", r.URL.RequestURI())
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, str)
+}
+
 // AddressPage is the page handler for the "/address" path.
 func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 	// AddressPageData is the data structure passed to the HTML template
@@ -1476,6 +1813,12 @@ func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 		CRLFDownload bool
 		FiatBalance  *exchanges.Conversion
 		Pages        []pageNumber
+		Label        *dbtypes.AddressLabel
+		// ProposalVotes are the votes on the Politeia proposals of the
+		// address's tickets, when piparser is enabled, and whether there
+		// are more than those shown.
+		ProposalVotes     []*dbtypes.ProposalTicketVote
+		MoreProposalVotes bool
 	}
 
 	// Grab the URL query parameters
@@ -1546,6 +1889,24 @@ func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// The most recent Politeia votes are an optional section of the page,
+	// which is hidden if they cannot be retrieved quickly. The rest are
+	// available from the API.
+	var proposalVotes []*dbtypes.ProposalTicketVote
+	var moreProposalVotes bool
+	if !isZeroAddress && exp.proposalsSource != nil {
+		proposalVotes, err = exp.dataSource.RecentAddressProposalVotes(address,
+			addressPageProposalVotes+1)
+		if err != nil {
+			log.Warnf("Unable to retrieve the proposal votes of address %s: %v", address, err)
+			proposalVotes = nil
+		}
+		if len(proposalVotes) > addressPageProposalVotes {
+			proposalVotes = proposalVotes[:addressPageProposalVotes]
+			moreProposalVotes = true
+		}
+	}
+
 	// Set page parameters.
 	addrData.IsDummyAddress = isZeroAddress // may be redundant
 	addrData.Path = r.URL.Path
@@ -1565,11 +1926,14 @@ func (exp *explorerUI) AddressPage(w http.ResponseWriter, r *http.Request) {
 
 	// Execute the HTML template.
 	pageData := AddressPageData{
-		CommonPageData: exp.commonData(r),
-		Data:           addrData,
-		CRLFDownload:   UseCRLF,
-		FiatBalance:    conversion,
-		Pages:          calcPages(int(addrData.TxnCount), int(limitN), int(offsetAddrOuts), linkTemplate),
+		CommonPageData:    exp.commonData(r),
+		Data:              addrData,
+		CRLFDownload:      UseCRLF,
+		FiatBalance:       conversion,
+		Pages:             calcPages(int(addrData.TxnCount), int(limitN), int(offsetAddrOuts), linkTemplate),
+		Label:             exp.dataSource.AddressLabel(address),
+		ProposalVotes:     proposalVotes,
+		MoreProposalVotes: moreProposalVotes,
 	}
 	str, err := exp.templates.exec("address", pageData)
 	if err != nil {
@@ -1945,6 +2309,16 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Search the address labels. Redirect to the address page for a single
+	// match, or list the matching labels.
+	if labels := exp.dataSource.SearchAddressLabels(searchStr); len(labels) == 1 {
+		http.Redirect(w, r, "/address/"+labels[0].Address, http.StatusFound)
+		return
+	} else if len(labels) > 1 {
+		http.Redirect(w, r, "/labels?search="+url.QueryEscape(searchStr), http.StatusFound)
+		return
+	}
+
 	// Split searchStr to the first part corresponding to a transaction hash and
 	// to the second part corresponding to a transaction output index.
 	searchStrSplit := strings.Split(searchStr, ":")
@@ -1998,7 +2372,7 @@ func (exp *explorerUI) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message := "The search did not find any matching address, block, transaction or proposal token: " + searchStr
+	message := "The search did not find any matching address, address label, block, transaction or proposal token: " + searchStr
 	exp.StatusPage(w, "search failed", message, "", ExpStatusNotFound)
 }
 
@@ -411,3 +411,21 @@ func TestPageNumbers(t *testing.T) {
 // 		}
 // 	}
 // }
+
+func TestBalanceBucketRange(t *testing.T) {
+	tests := []struct {
+		minBalance int64
+		want       string
+	}{
+		{0, "less than 0.01"},
+		{1e6, "0.01 to 0.1"},
+		{1e8, "1 to 10"},
+		{1e12, "10,000 to 100,000"},
+		{1e14, "1,000,000 or more"},
+	}
+	for _, tt := range tests {
+		if got := balanceBucketRange(tt.minBalance); got != tt.want {
+			t.Errorf("balanceBucketRange(%d) = %q, want %q", tt.minBalance, got, tt.want)
+		}
+	}
+}
@@ -614,5 +614,21 @@ func makeTemplateFuncMap(params *chaincfg.Params) template.FuncMap {
 			return intStr
 		},
 		"floor": math.Floor,
+		"missCauseDescription": func(cause string) string {
+			switch cause {
+			case dbtypes.MissCauseDisapproved:
+				return "previous block disapproved"
+			case dbtypes.MissCauseLate:
+				return "vote arrived late"
+			case dbtypes.MissCauseOmitted:
+				return "vote omitted by miner"
+			case dbtypes.MissCauseVSPOffline:
+				return "VSP offline"
+			case dbtypes.MissCauseNotSeen:
+				return "vote never seen"
+			default:
+				return "unknown"
+			}
+		},
 	}
 }
@@ -40,6 +40,7 @@ var (
 	sigNewTxs           = pstypes.SigNewTxs
 	sigAddressTx        = pstypes.SigAddressTx
 	sigSyncStatus       = pstypes.SigSyncStatus
+	sigDoubleSpend      = pstypes.SigDoubleSpend
 )
 
 // WebSocketMessage represents the JSON object used to send and received typed
@@ -240,9 +241,9 @@ func (wsh *WebsocketHub) run() {
 				}
 				log.Tracef("Received new tx %s", newtx.Hash)
 				wsh.maybeSendTxns(newtx)
-			case sigAddressTx, sigSubscribe, sigUnsubscribe:
-				// explorer's WebsocketHub does not have address subscriptions,
-				// so do not relay address signals to any clients.
+			case sigAddressTx, sigDoubleSpend, sigSubscribe, sigUnsubscribe:
+				// explorer's WebsocketHub does not have address or double spend
+				// subscriptions, so do not relay these signals to any clients.
 				break events
 			case sigSyncStatus:
 			default:
@@ -17,6 +17,7 @@ require (
 	github.com/decred/dcrd/chaincfg/v3 v3.0.0
 	github.com/decred/dcrd/dcrec v1.0.0
 	github.com/decred/dcrd/dcrutil/v3 v3.0.0
+	github.com/decred/dcrd/hdkeychain/v3 v3.0.0
 	github.com/decred/dcrd/rpc/jsonrpc/types/v2 v2.3.0
 	github.com/decred/dcrd/rpcclient/v6 v6.0.2
 	github.com/decred/dcrd/txscript/v3 v3.0.0
@@ -33,8 +34,11 @@ require (
 	github.com/go-chi/docgen v1.2.0
 	github.com/google/gops v0.3.17
 	github.com/googollee/go-socket.io v1.4.4
+	github.com/graphql-go/graphql v0.8.1
 	github.com/jessevdk/go-flags v1.4.1-0.20200711081900-c17162fe8fd7
 	github.com/jrick/logrotate v1.0.0
+	github.com/prometheus/client_golang v1.10.0
 	github.com/rs/cors v1.7.1-0.20201213214713-f9bce55a4e61
 	golang.org/x/net v0.0.0-20210119194325-5f4716e94777
+	golang.org/x/time v0.0.0-20200630173020-3af7569d3a1e
 )
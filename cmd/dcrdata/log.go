@@ -14,6 +14,7 @@ import (
 	"github.com/jrick/logrotate/rotator"
 
 	"github.com/decred/dcrdata/cmd/dcrdata/api"
+	"github.com/decred/dcrdata/cmd/dcrdata/api/graphql"
 	"github.com/decred/dcrdata/cmd/dcrdata/api/insight"
 	"github.com/decred/dcrdata/cmd/dcrdata/explorer"
 	"github.com/decred/dcrdata/cmd/dcrdata/middleware"
@@ -27,6 +28,7 @@ import (
 	"github.com/decred/dcrdata/v6/blockdata"
 	"github.com/decred/dcrdata/v6/mempool"
 	"github.com/decred/dcrdata/v6/pubsub"
+	"github.com/decred/dcrdata/v6/pubsub/webhook"
 	"github.com/decred/dcrdata/v6/rpcutils"
 	"github.com/decred/dcrdata/v6/stakedb"
 )
@@ -69,7 +71,9 @@ var (
 	apiLog        = backendLog.Logger("JAPI")
 	log           = backendLog.Logger("DATD")
 	iapiLog       = backendLog.Logger("IAPI")
+	gqlLog        = backendLog.Logger("GQLA")
 	pubsubLog     = backendLog.Logger("PUBS")
+	webhookLog    = backendLog.Logger("HOOK")
 	xcBotLog      = backendLog.Logger("XBOT")
 	agendasLog    = backendLog.Logger("AGDB")
 	proposalsLog  = backendLog.Logger("PRDB")
@@ -86,9 +90,11 @@ func init() {
 	explorer.UseLogger(expLog)
 	api.UseLogger(apiLog)
 	insight.UseLogger(iapiLog)
+	graphql.UseLogger(gqlLog)
 	middleware.UseLogger(apiLog)
 	notify.UseLogger(notifyLog)
 	pubsub.UseLogger(pubsubLog)
+	webhook.UseLogger(webhookLog)
 	exchanges.UseLogger(xcBotLog)
 	agendas.UseLogger(agendasLog)
 	politeia.UseLogger(proposalsLog)
@@ -105,8 +111,10 @@ var subsystemLoggers = map[string]slog.Logger{
 	"EXPR": expLog,
 	"JAPI": apiLog,
 	"IAPI": iapiLog,
+	"GQLA": gqlLog,
 	"DATD": log,
 	"PUBS": pubsubLog,
+	"HOOK": webhookLog,
 	"XBOT": xcBotLog,
 	"AGDB": agendasLog,
 	"PRDB": proposalsLog,
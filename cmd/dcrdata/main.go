@@ -34,18 +34,22 @@ import (
 	"github.com/decred/dcrdata/v6/mempool"
 	"github.com/decred/dcrdata/v6/pubsub"
 	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/pubsub/webhook"
 	"github.com/decred/dcrdata/v6/rpcutils"
 	"github.com/decred/dcrdata/v6/semver"
 	"github.com/decred/dcrdata/v6/stakedb"
 
 	"github.com/decred/dcrdata/cmd/dcrdata/api"
+	"github.com/decred/dcrdata/cmd/dcrdata/api/graphql"
 	"github.com/decred/dcrdata/cmd/dcrdata/api/insight"
 	"github.com/decred/dcrdata/cmd/dcrdata/explorer"
+	"github.com/decred/dcrdata/cmd/dcrdata/metrics"
 	mw "github.com/decred/dcrdata/cmd/dcrdata/middleware"
 	notify "github.com/decred/dcrdata/cmd/dcrdata/notification"
 
 	"github.com/dmigwi/go-piparser/proposals"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/gops/agent"
 )
 
@@ -210,6 +214,9 @@ func _main(ctx context.Context) error {
 		AddrCacheAddrCap:     cfg.AddrCacheLimit,
 		AddrCacheRowCap:      rowCap,
 		AddrCacheUTXOByteCap: cfg.AddrCacheUXTOCap,
+		ExchangeAddresses:    cfg.exchangeAddrs,
+		VSPFeeAddresses:      cfg.vspFeeAddrs,
+		TreasuryPayees:       cfg.treasuryPayees,
 	}
 
 	mpChecker := rpcutils.NewMempoolAddressChecker(dcrdClient, activeChain)
@@ -229,6 +236,12 @@ func _main(ctx context.Context) error {
 		return err
 	}
 
+	// Load the address labels, first replacing any stored labels from the
+	// labels file with the labels of the configured file.
+	if err = chainDB.LoadAddressLabels(cfg.addressLabels); err != nil {
+		return fmt.Errorf("failed to load address labels: %w", err)
+	}
+
 	// Check for missing indexes.
 	missingIndexes, descs, err := chainDB.MissingIndexes()
 	if err != nil {
@@ -462,19 +475,23 @@ func _main(ctx context.Context) error {
 		return fmt.Errorf("failed to create new agendas db instance: %v", err)
 	}
 
-	// Creates a new or loads an existing proposals db instance that helps to
-	// store and retrieve proposals data. Proposals votes is Off-Chain
+	// Creates a proposals db instance that helps to store and retrieve
+	// proposals data in the PostgreSQL database. Proposals votes is Off-Chain
 	// data stored in github repositories away from the decred blockchain. It also
 	// creates a new http client needed to query Politeia API endpoints.
 	// When piparser is disabled, disable the API calls too.
 	var proposalsInstance explorer.PoliteiaBackend
 
 	if !cfg.DisablePiParser {
-		proposalsInstance, err = politeia.NewProposalsDB(cfg.PoliteiaAPIURL,
-			filepath.Join(cfg.DataDir, cfg.ProposalsFileName))
+		proposalsInstance, err = politeia.NewProposalsDB(cfg.PoliteiaAPIURL, cfg.PoliteiaAPI, chainDB)
 		if err != nil {
 			return fmt.Errorf("failed to create new proposals db instance: %v", err)
 		}
+		proposalsFile := filepath.Join(cfg.DataDir, cfg.ProposalsFileName)
+		if _, err = os.Stat(proposalsFile); err == nil {
+			log.Infof("The proposals are now stored in PostgreSQL. "+
+				"The unused proposals DB file %s may be deleted.", proposalsFile)
+		}
 	} else {
 		log.Info("Piparser is disabled. Proposals API has been disabled too")
 	}
@@ -529,6 +546,71 @@ func _main(ctx context.Context) error {
 	blockDataSavers = append(blockDataSavers, explore)
 	mempoolSavers = append(mempoolSavers, explore)
 
+	// The fee estimator combines the mempool fee rates with those of recent
+	// blocks, and signals new estimates to pubsub clients.
+	feeEstimator := mempool.NewFeeEstimator(activeChain, chainDB,
+		mempool.DefaultFeeEstimatorBlocks, []chan<- pstypes.HubMessage{psHub.HubRelay()})
+	mempoolSavers = append(mempoolSavers, feeEstimator)
+
+	// The transaction broadcaster relays the transactions submitted to the
+	// broadcast API, and tracks them with the mempool snapshots, the mempool
+	// monitor's new transaction signals, and new blocks (after they are stored
+	// by chainDB).
+	broadcaster := mempool.NewBroadcaster(activeChain, chainDB, dcrdClient)
+	blockDataSavers = append(blockDataSavers, broadcaster)
+	mempoolSavers = append(mempoolSavers, broadcaster)
+	wg.Add(1)
+	go broadcaster.Run(ctx, &wg)
+
+	// The mempool history recorder stores the first seen time and final
+	// disposition of every mempool transaction, from the mempool snapshots,
+	// the mempool monitor's new transaction and double spend signals, and new
+	// blocks.
+	mempoolHistory := mempool.NewHistoryRecorder(chainDB)
+	blockDataSavers = append(blockDataSavers, mempoolHistory)
+	mempoolSavers = append(mempoolSavers, mempoolHistory)
+	wg.Add(1)
+	go mempoolHistory.Run(ctx, &wg)
+
+	// The ticket set tracker follows the status of the tickets of the sets
+	// registered with the API from the ticket diffs of the connected and
+	// disconnected stakedb blocks, and signals their transitions to pubsub
+	// clients.
+	ticketSets := stakedb.NewTicketSetTracker(chainDB, []chan<- pstypes.HubMessage{psHub.HubRelay()})
+	wg.Add(1)
+	go ticketSets.Run(ctx, &wg, stakeDB.SubscribeTicketDiffs(ctx))
+
+	// The tspend tracker follows the treasury spends from the mempool
+	// snapshots and new blocks, tallies their votes with each block, and
+	// signals their progress to pubsub clients.
+	tspends := mempool.NewTSpendTracker(chainDB, activeChain, []chan<- pstypes.HubMessage{psHub.HubRelay()})
+	blockDataSavers = append(blockDataSavers, tspends)
+	mempoolSavers = append(mempoolSavers, tspends)
+	explore.UseTSpendSource(tspends)
+
+	// The webhook dispatcher creates the deliveries for the watched addresses
+	// from new blocks (after they are stored by chainDB) and from the mempool
+	// monitor's address signals, and sends them to the registered URLs. The
+	// reorg deliveries are created by chainDB's ReorgHandler.
+	var webhooks *webhook.Dispatcher
+	var webhookSource api.WebhookSource
+	if cfg.EnableWebhooks {
+		webhooks = webhook.NewDispatcher(chainDB)
+		webhookSource = chainDB
+		blockDataSavers = append(blockDataSavers, webhooks)
+		wg.Add(1)
+		go webhooks.Run(ctx, &wg)
+	}
+
+	// The API keys of requests are checked against the keys in the database,
+	// and the usage of each key is stored periodically.
+	var apiKeys *mw.APIKeys
+	if cfg.EnableAPIKeys {
+		apiKeys = mw.NewAPIKeys(chainDB)
+		wg.Add(1)
+		go apiKeys.Run(ctx, &wg)
+	}
+
 	// Create the mempool data collector.
 	mpoolCollector := mempool.NewMempoolDataCollector(dcrdClient, activeChain)
 	if mpoolCollector == nil {
@@ -544,7 +626,11 @@ func _main(ctx context.Context) error {
 	// appropriate signal to the underlying WebSocketHub on signalToPSHub.
 	signalToPSHub := psHub.HubRelay()
 	signalToExplorer := explore.MempoolSignal()
-	mempoolSigOuts := []chan<- pstypes.HubMessage{signalToPSHub, signalToExplorer}
+	mempoolSigOuts := []chan<- pstypes.HubMessage{signalToPSHub, signalToExplorer,
+		broadcaster.HubRelay(), mempoolHistory.HubRelay()}
+	if webhooks != nil {
+		mempoolSigOuts = append(mempoolSigOuts, webhooks.HubRelay())
+	}
 	mpm, err := mempool.NewMempoolMonitor(ctx, mpoolCollector, mempoolSavers,
 		activeChain, dcrdClient, mempoolSigOuts, true)
 
@@ -557,6 +643,15 @@ func _main(ctx context.Context) error {
 
 	// Use the MempoolMonitor in aux DB to get unconfirmed transaction data.
 	chainDB.UseMempoolChecker(mpm)
+	// Attribute the missed votes to their cause with the times the
+	// MempoolMonitor first saw the votes.
+	chainDB.UseVoteSightings(mpm)
+
+	// The MempoolMonitor checks new blocks for double spends of mempool
+	// transactions before BlockHandler refreshes the mempool, and the explorer
+	// shows the double spends it detects.
+	blockDataSavers = append(blockDataSavers, mpm)
+	explore.UseDoubleSpendSource(mpm)
 
 	// Prepare for sync by setting up the channels for status/progress updates
 	// (barLoad) or full explorer page updates (latestBlockHash).
@@ -653,11 +748,20 @@ func _main(ctx context.Context) error {
 		Client:             dcrdClient,
 		Params:             activeChain,
 		DataSource:         chainDB,
+		MempoolSource:      psHub,
+		FeeEstimator:       feeEstimator,
+		Broadcaster:        broadcaster,
+		TicketSets:         ticketSets,
+		TSpends:            tspends,
+		WebhookSource:      webhookSource,
 		XcBot:              xcBot,
 		AgendasDBInstance:  agendaDB,
 		MaxAddrs:           cfg.MaxCSVAddrs,
 		Charts:             charts,
 		IsPiparserDisabled: cfg.DisablePiParser,
+		AdminPass:          cfg.AdminPass,
+		APIKeys:            apiKeys,
+		ReqRateLimit:       cfg.APIReqRateLimit,
 	})
 	// Start the notification hander for keeping /status up-to-date.
 	wg.Add(1)
@@ -676,16 +780,48 @@ func _main(ctx context.Context) error {
 
 	// Configure the explorer web pages router.
 	webMux := chi.NewRouter()
+
 	if cfg.ServerHeader != "" {
 		log.Debugf("Using Server HTTP response header %q", cfg.ServerHeader)
 		webMux.Use(mw.Server(cfg.ServerHeader))
 	}
+
+	// The Prometheus metrics, with the request latencies recorded by the top
+	// router.
+	if cfg.EnableMetrics {
+		promMetrics := metrics.New(&metrics.Sources{
+			ChainDB:      chainDB,
+			StakeDB:      stakeDB,
+			Charts:       charts,
+			AddressCache: chainDB.AddressCache,
+			Notifier:     notifier,
+			PubSub:       psHub,
+			Mempool:      psHub,
+			ExchangeBot:  xcBot,
+		})
+		notifier.ObserveHandlers(promMetrics.ObserveNotification)
+		webMux.Use(promMetrics.Middleware)
+		webMux.Handle("/metrics", promMetrics.Handler())
+	}
+
 	webMux.With(explore.SyncStatusPageIntercept).Group(func(r chi.Router) {
 		r.Get("/", explore.Home)
 		r.Get("/visualblocks", explore.VisualBlocks)
 	})
 	webMux.Get("/ws", explore.RootWebsocket)
-	webMux.Get("/ps", psHub.WebSocketHandler)
+	// Limit new pubsub connections by API key, or per client IP without a key.
+	var psLimiter *mw.Limiter
+	if cfg.APIReqRateLimit > 0 {
+		psLimiter = mw.NewIPLimiter(cfg.APIReqRateLimit, cfg.UseRealIP)
+	}
+	webMux.Group(func(r chi.Router) {
+		if cfg.UseRealIP {
+			r.Use(middleware.RealIP)
+		}
+		r.Use(apiKeys.Limit(mw.APIKeyGroupPubSub, psLimiter))
+		r.Get("/ps", psHub.WebSocketHandler)
+		r.Get("/ps/sse", psHub.SSEHandler)
+	})
 
 	// Make the static assets available under a path with the given prefix.
 	mountAssetPaths := func(pathPrefix string) {
@@ -716,15 +852,32 @@ func _main(ctx context.Context) error {
 		webMux.Mount(profPath, http.StripPrefix(profPath, http.DefaultServeMux))
 	}
 
+	// The GraphQL API, with a query cost limit derived from the PostgreSQL
+	// query timeout.
+	gqlServer, err := graphql.NewServer(&graphql.Config{
+		DataSource:     chainDB,
+		AgendaSource:   agendaDB,
+		ProposalSource: proposalsInstance,
+		PubSubSource:   psHub,
+		Params:         activeChain,
+		QueryTimeout:   cfg.PGQueryTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the GraphQL server: %w", err)
+	}
+
 	// SyncStatusAPIIntercept returns a json response if the sync status page is
 	// enabled (no the full explorer while syncing).
 	webMux.With(explore.SyncStatusAPIIntercept).Group(func(r chi.Router) {
 		// Mount the dcrdata's REST API.
 		r.Mount("/api", apiMux.Mux)
+		// Mount the GraphQL API.
+		r.Handle("/graphql", gqlServer)
 		// Setup and mount the Insight API.
 		insightApp := insight.NewInsightAPI(dcrdClient, chainDB,
 			activeChain, mpm, cfg.IndentJSON, app.Status)
 		insightApp.SetReqRateLimit(cfg.InsightReqRateLimit)
+		insightApp.SetAPIKeys(apiKeys)
 		insightMux := insight.NewInsightAPIRouter(insightApp, cfg.UseRealIP,
 			cfg.CompressAPI, cfg.MaxCSVAddrs)
 		r.Mount("/insight/api", insightMux.Mux)
@@ -763,6 +916,10 @@ func _main(ctx context.Context) error {
 		r.With(explorer.AddressPathCtx).Get("/addresstable/{address}", explore.AddressTable)
 		r.Get("/treasury", explore.TreasuryPage)
 		r.Get("/treasurytable", explore.TreasuryTable)
+		r.Get("/swaps", explore.AtomicSwapsPage)
+		r.Get("/rich", explore.RichListPage)
+		r.Get("/labels", explore.LabelsPage)
+		r.Get("/vsps", explore.VSPsPage)
 		r.Get("/agendas", explore.AgendasPage)
 		r.With(explorer.AgendaPathCtx).Get("/agenda/{agendaid}", explore.AgendaPage)
 		r.Get("/proposals", explore.ProposalsPage)
@@ -999,6 +1156,34 @@ func _main(ctx context.Context) error {
 		Saver: charts.TriggerUpdate,
 	})
 
+	// Refresh the rich list and address distribution periodically. This runs
+	// asynchronously since it aggregates the unspent outputs of every address.
+	blockDataSavers = append(blockDataSavers, blockdata.BlockTrigger{
+		Async: true,
+		Saver: chainDB.RefreshRichList,
+	})
+
+	// Refresh the VSP statistics periodically. This runs asynchronously since
+	// it aggregates the tickets of every VSP.
+	blockDataSavers = append(blockDataSavers, blockdata.BlockTrigger{
+		Async: true,
+		Saver: chainDB.RefreshVSPStats,
+	})
+
+	// Refresh the time to confirmation by fee rate chart from the mempool
+	// history periodically.
+	blockDataSavers = append(blockDataSavers, blockdata.BlockTrigger{
+		Async: true,
+		Saver: chainDB.RefreshConfirmationTimes,
+	})
+
+	// Attribute the missed votes of the previous block to their cause, after
+	// the late votes on its parent had a block to arrive.
+	blockDataSavers = append(blockDataSavers, blockdata.BlockTrigger{
+		Async: true,
+		Saver: chainDB.AttributeMisses,
+	})
+
 	// This dumps the cache charts data into a file for future use on system
 	// exit.
 	defer charts.Dump(dumpPath)
@@ -1028,7 +1213,7 @@ func _main(ctx context.Context) error {
 		// Initiate the piparser handler here.
 		chainDB.StartPiparserHandler()
 
-		// Retrieve newly added proposals and add them to the proposals db(storm).
+		// Retrieve newly added proposals and add them to the proposals db.
 		// Proposal db update is made asynchronously to ensure that the system works
 		// even when the Politeia API endpoint set is down.
 		go func() {
@@ -0,0 +1,293 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+// Package metrics exports operational metrics of dcrdata's subsystems in the
+// Prometheus format, including the block heights of the databases, the
+// notification queue, the address cache, pubsub connections, the exchange bot,
+// mempool, and the latency of HTTP requests by route.
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/decred/dcrdata/exchanges/v3"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dcrdata"
+
+// CacheStats is the source of the hit and miss counts of the address cache. It
+// is satisfied by *cache.AddressCache.
+type CacheStats interface {
+	RowStats() (hits, misses int)
+	UtxoStats() (hits, misses int)
+	BalanceStats() (hits, misses int)
+	HistoryStats() (hits, misses int)
+}
+
+// Sources are the subsystems with exported metrics. A nil source is skipped.
+type Sources struct {
+	ChainDB      interface{ Height() int64 }
+	StakeDB      interface{ Height() uint32 }
+	Charts       interface{ Height() int32 }
+	AddressCache CacheStats
+	Notifier     interface{ QueueLength() int }
+	PubSub       interface {
+		NumConnections() (websockets, sse int64)
+	}
+	Mempool interface {
+		MempoolInventory() *exptypes.MempoolInfo
+	}
+	ExchangeBot *exchanges.ExchangeBot
+}
+
+// Metrics is the collection of the metrics of dcrdata. Use New to create a
+// Metrics.
+type Metrics struct {
+	registry     *prometheus.Registry
+	httpDuration *prometheus.HistogramVec
+	ntfnDuration *prometheus.HistogramVec
+}
+
+// New creates the metrics of the sources, and of the Go runtime and process.
+func New(src *Sources) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by route pattern.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"route", "method", "code"}),
+		ntfnDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "notifier",
+			Name:      "handler_duration_seconds",
+			Help:      "Time taken by the handlers of dcrd notifications, by notification type.",
+			Buckets:   []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		}, []string{"notification"}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.ntfnDuration,
+	)
+
+	height := func(subsystem string, f func() float64) {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "block_height",
+			Help:        "Height of the best block of each subsystem.",
+			ConstLabels: prometheus.Labels{"subsystem": subsystem},
+		}, f))
+	}
+	if src.ChainDB != nil {
+		height("chaindb", func() float64 { return float64(src.ChainDB.Height()) })
+	}
+	if src.StakeDB != nil {
+		height("stakedb", func() float64 { return float64(src.StakeDB.Height()) })
+	}
+	if src.Charts != nil {
+		height("charts", func() float64 { return float64(src.Charts.Height()) })
+	}
+
+	if src.Notifier != nil {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "notifier",
+			Name:      "queue_length",
+			Help:      "Number of dcrd notifications waiting to be handled.",
+		}, func() float64 { return float64(src.Notifier.QueueLength()) }))
+	}
+
+	if src.AddressCache != nil {
+		cacheStats := map[string]func() (int, int){
+			"rows":    src.AddressCache.RowStats,
+			"utxos":   src.AddressCache.UtxoStats,
+			"balance": src.AddressCache.BalanceStats,
+			"history": src.AddressCache.HistoryStats,
+		}
+		for cache, stats := range cacheStats {
+			stats := stats
+			m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   "address_cache",
+				Name:        "hits_total",
+				Help:        "Number of address cache hits, by cached data.",
+				ConstLabels: prometheus.Labels{"cache": cache},
+			}, func() float64 {
+				hits, _ := stats()
+				return float64(hits)
+			}))
+			m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace:   namespace,
+				Subsystem:   "address_cache",
+				Name:        "misses_total",
+				Help:        "Number of address cache misses, by cached data.",
+				ConstLabels: prometheus.Labels{"cache": cache},
+			}, func() float64 {
+				_, misses := stats()
+				return float64(misses)
+			}))
+		}
+	}
+
+	if src.PubSub != nil {
+		connections := func(transport string, f func() float64) {
+			m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Subsystem:   "pubsub",
+				Name:        "connections",
+				Help:        "Number of pubsub client connections, by transport.",
+				ConstLabels: prometheus.Labels{"transport": transport},
+			}, f))
+		}
+		connections("websocket", func() float64 {
+			ws, _ := src.PubSub.NumConnections()
+			return float64(ws)
+		})
+		connections("sse", func() float64 {
+			_, sse := src.PubSub.NumConnections()
+			return float64(sse)
+		})
+	}
+
+	if src.Mempool != nil {
+		mempool := func(name, help string, f func(*exptypes.MempoolInfo) float64) {
+			m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "mempool",
+				Name:      name,
+				Help:      help,
+			}, func() float64 {
+				inv := src.Mempool.MempoolInventory()
+				if inv == nil {
+					return 0
+				}
+				inv.RLock()
+				defer inv.RUnlock()
+				return f(inv)
+			}))
+		}
+		mempool("transactions", "Number of transactions in mempool.",
+			func(inv *exptypes.MempoolInfo) float64 { return float64(inv.NumAll) })
+		mempool("size_bytes", "Total size of the transactions in mempool.",
+			func(inv *exptypes.MempoolInfo) float64 { return float64(inv.TotalSize) })
+	}
+
+	if bot := src.ExchangeBot; bot != nil {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "exchange_bot",
+			Name:      "failed",
+			Help:      "Whether the exchange bot has no up-to-date Bitcoin index or Decred exchange (1) or not (0).",
+		}, func() float64 { return boolGauge(bot.IsFailed()) }))
+		for token, xc := range bot.Exchanges {
+			xc := xc
+			m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace:   namespace,
+				Subsystem:   "exchange_bot",
+				Name:        "exchange_failed",
+				Help:        "Whether the last update of the exchange failed (1) or not (0).",
+				ConstLabels: prometheus.Labels{"exchange": token},
+			}, func() float64 { return boolGauge(xc.IsFailed()) }))
+		}
+	}
+
+	return m
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Handler is the http.Handler of the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveNotification records the time taken by the handlers of a dcrd
+// notification. It is a notification.HandlerObserver.
+func (m *Metrics) ObserveNotification(ntfn string, d time.Duration) {
+	m.ntfnDuration.WithLabelValues(ntfn).Observe(d.Seconds())
+}
+
+// statusWriter records the status code of a response, and whether the
+// connection was hijacked.
+type statusWriter struct {
+	http.ResponseWriter
+	status   int
+	hijacked bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for websocket and event stream connections.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking not supported")
+	}
+	w.hijacked = true
+	return hj.Hijack()
+}
+
+// Middleware records the time taken to serve each request by its chi route
+// pattern, method and response status code. The middleware must be used by
+// the top router, so that the patterns of the mounted routers are included.
+// Requests that hijack the connection, such as websockets, are not recorded.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.hijacked {
+			return
+		}
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.httpDuration.WithLabelValues(route, r.Method, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	"github.com/go-chi/chi/v5"
+)
+
+type stubHeight int64
+
+func (h stubHeight) Height() int64 { return int64(h) }
+
+type stubCache struct{}
+
+func (stubCache) RowStats() (int, int)     { return 5, 1 }
+func (stubCache) UtxoStats() (int, int)    { return 0, 0 }
+func (stubCache) BalanceStats() (int, int) { return 2, 3 }
+func (stubCache) HistoryStats() (int, int) { return 0, 0 }
+
+type stubHub struct{}
+
+func (stubHub) NumConnections() (int64, int64) { return 4, 2 }
+
+func (stubHub) MempoolInventory() *exptypes.MempoolInfo {
+	inv := new(exptypes.MempoolInfo)
+	inv.NumAll = 7
+	inv.TotalSize = 2048
+	return inv
+}
+
+func TestMetrics(t *testing.T) {
+	m := New(&Sources{
+		ChainDB:      stubHeight(600000),
+		AddressCache: stubCache{},
+		PubSub:       stubHub{},
+		Mempool:      stubHub{},
+	})
+	m.ObserveNotification("block", 250*time.Millisecond)
+
+	router := chi.NewRouter()
+	router.Use(m.Middleware)
+	router.Handle("/metrics", m.Handler())
+	router.Route("/api", func(r chi.Router) {
+		r.Get("/block/{idx}", func(w http.ResponseWriter, r *http.Request) {})
+		r.Get("/missing", http.NotFound)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, path := range []string{"/api/block/1", "/api/block/2", "/api/missing", "/nope"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(b)
+
+	for _, want := range []string{
+		`dcrdata_block_height{subsystem="chaindb"} 600000`,
+		`dcrdata_address_cache_hits_total{cache="rows"} 5`,
+		`dcrdata_address_cache_misses_total{cache="balance"} 3`,
+		`dcrdata_pubsub_connections{transport="websocket"} 4`,
+		`dcrdata_pubsub_connections{transport="sse"} 2`,
+		`dcrdata_mempool_transactions 7`,
+		`dcrdata_mempool_size_bytes 2048`,
+		`dcrdata_notifier_handler_duration_seconds_count{notification="block"} 1`,
+		`dcrdata_http_request_duration_seconds_count{code="200",method="GET",route="/api/block/{idx}"} 2`,
+		`dcrdata_http_request_duration_seconds_count{code="404",method="GET",route="/api/missing"} 1`,
+		`dcrdata_http_request_duration_seconds_count{code="404",method="GET",route="unmatched"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not include %q", want)
+		}
+	}
+
+	// Sources that are not set are skipped.
+	for _, absent := range []string{`subsystem="stakedb"`, "dcrdata_notifier_queue_length",
+		"dcrdata_exchange_bot_failed"} {
+		if strings.Contains(body, absent) {
+			t.Errorf("metrics unexpectedly include %q", absent)
+		}
+	}
+}
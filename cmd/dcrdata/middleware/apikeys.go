@@ -0,0 +1,423 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// APIKeyHeader is the HTTP header with the API key of a request.
+	APIKeyHeader = "X-API-Key"
+	// APIKeyQuery is the URL query parameter with the API key of a request,
+	// for clients that cannot set headers, such as browser websockets.
+	APIKeyQuery = "apikey"
+
+	// Route groups of the API key usage counters.
+	APIKeyGroupAPI     = "api"
+	APIKeyGroupInsight = "insight"
+	APIKeyGroupPubSub  = "pubsub"
+
+	// apiKeyRefreshInterval is how often a cached key is reloaded, so that
+	// changes made by other instances sharing the database take effect.
+	apiKeyRefreshInterval = 5 * time.Minute
+	// invalidAPIKeyTTL is how long an unknown key is remembered, so that
+	// repeated requests with it do not each query the database.
+	invalidAPIKeyTTL = time.Minute
+	// maxInvalidAPIKeys limits the size of the unknown key cache.
+	maxInvalidAPIKeys = 10000
+	// apiKeyFlushInterval is how often the usage counters are stored.
+	apiKeyFlushInterval = 30 * time.Second
+	// maxAPIKeyNameLen is the maximum length of the name of a key.
+	maxAPIKeyNameLen = 100
+)
+
+// APIKeyStore is the persistent storage of the API keys and their usage. It is
+// satisfied by *dcrpg.ChainDB.
+type APIKeyStore interface {
+	CreateAPIKey(key *dbtypes.APIKey) error
+	APIKeyByHash(keyHash string) (*dbtypes.APIKey, error)
+	APIKeys(month time.Time) ([]*dbtypes.APIKey, error)
+	RevokeAPIKey(id string) (bool, error)
+	AddAPIKeyUsage(usage []*dbtypes.APIKeyUsage) error
+	APIKeyMonthRequests(id string, month time.Time) (int64, error)
+}
+
+// apiKeyState is a cached API key with its token bucket and the number of
+// requests made with it in the current month.
+type apiKeyState struct {
+	key     *dbtypes.APIKey
+	limiter *rate.Limiter
+	month   time.Time
+	used    int64
+	loaded  time.Time
+}
+
+// usageKey identifies the usage counters of a key, month and route group.
+type usageKey struct {
+	id    string
+	month time.Time
+	group string
+}
+
+// APIKeys authenticates the API keys of requests, and applies the rate limit
+// and monthly quota of each key. The usage of the keys is counted per route
+// group and stored periodically by Run. Use NewAPIKeys to create an APIKeys.
+type APIKeys struct {
+	store APIKeyStore
+	now   func() time.Time
+
+	mtx     sync.Mutex
+	keys    map[string]*apiKeyState // by key hash
+	invalid map[string]time.Time    // by key hash
+	pending map[usageKey]*dbtypes.APIKeyUsage
+}
+
+// NewAPIKeys creates a new APIKeys for the keys in the store.
+func NewAPIKeys(store APIKeyStore) *APIKeys {
+	return &APIKeys{
+		store:   store,
+		now:     time.Now,
+		keys:    make(map[string]*apiKeyState),
+		invalid: make(map[string]time.Time),
+		pending: make(map[usageKey]*dbtypes.APIKeyUsage),
+	}
+}
+
+// NewAPIKey validates a request to issue an API key, and creates a key with a
+// random ID and secret key. If the request does not specify the burst, it is
+// the rate limit rounded up.
+func NewAPIKey(req *apitypes.APIKeyRequest) (*dbtypes.APIKey, error) {
+	if req.Name == "" || len(req.Name) > maxAPIKeyNameLen {
+		return nil, fmt.Errorf("a name of up to %d characters is required", maxAPIKeyNameLen)
+	}
+	if !(req.RateLimit > 0) || math.IsInf(req.RateLimit, 1) {
+		return nil, errors.New("rate_limit must be positive")
+	}
+	if req.Burst < 0 {
+		return nil, errors.New("burst may not be negative")
+	}
+	if req.MonthlyQuota < 0 {
+		return nil, errors.New("monthly_quota may not be negative")
+	}
+	burst := req.Burst
+	if burst == 0 {
+		burst = int32(math.Min(math.Ceil(req.RateLimit), math.MaxInt32))
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	key, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	return &dbtypes.APIKey{
+		ID:           id,
+		Key:          key,
+		KeyHash:      hashAPIKey(key),
+		Name:         req.Name,
+		RateLimit:    req.RateLimit,
+		Burst:        burst,
+		MonthlyQuota: req.MonthlyQuota,
+		Created:      dbtypes.NewTimeDef(time.Now()),
+	}, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CreateAPIKey stores a key created by NewAPIKey.
+func (k *APIKeys) CreateAPIKey(key *dbtypes.APIKey) error {
+	if err := k.store.CreateAPIKey(key); err != nil {
+		return err
+	}
+	k.mtx.Lock()
+	delete(k.invalid, key.KeyHash)
+	k.mtx.Unlock()
+	return nil
+}
+
+// APIKeys retrieves all keys with their usage in the month of the given time,
+// after storing the pending usage counters.
+func (k *APIKeys) APIKeys(month time.Time) ([]*dbtypes.APIKey, error) {
+	if err := k.flush(); err != nil {
+		return nil, err
+	}
+	return k.store.APIKeys(month)
+}
+
+// RevokeAPIKey revokes the key with the given ID, which is refused from then
+// on. The returned bool indicates if the key existed and was not already
+// revoked.
+func (k *APIKeys) RevokeAPIKey(id string) (bool, error) {
+	found, err := k.store.RevokeAPIKey(id)
+	if err != nil {
+		return false, err
+	}
+	k.mtx.Lock()
+	for hash, ks := range k.keys {
+		if ks.key.ID == id {
+			delete(k.keys, hash)
+			k.invalid[hash] = k.now()
+		}
+	}
+	k.mtx.Unlock()
+	return found, nil
+}
+
+// lookup returns the state of the key, loading it from the store if it is not
+// cached or the cached key is stale. A nil state is returned for an unknown or
+// revoked key.
+func (k *APIKeys) lookup(key string) (*apiKeyState, error) {
+	hash := hashAPIKey(key)
+	now := k.now()
+
+	k.mtx.Lock()
+	ks := k.keys[hash]
+	if ks != nil && now.Sub(ks.loaded) < apiKeyRefreshInterval {
+		k.mtx.Unlock()
+		return ks, nil
+	}
+	if t, found := k.invalid[hash]; found && now.Sub(t) < invalidAPIKeyTTL {
+		k.mtx.Unlock()
+		return nil, nil
+	}
+	k.mtx.Unlock()
+
+	dbKey, err := k.store.APIKeyByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	var used int64
+	if dbKey != nil && dbKey.Revoked == nil {
+		used, err = k.store.APIKeyMonthRequests(dbKey.ID, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	if dbKey == nil || dbKey.Revoked != nil {
+		delete(k.keys, hash)
+		if len(k.invalid) >= maxInvalidAPIKeys {
+			k.invalid = make(map[string]time.Time)
+		}
+		k.invalid[hash] = now
+		return nil, nil
+	}
+	delete(k.invalid, hash)
+
+	// Add the counts not yet stored.
+	month := monthOf(now)
+	for uk, u := range k.pending {
+		if uk.id == dbKey.ID && uk.month.Equal(month) {
+			used += u.Requests
+		}
+	}
+
+	ks = k.keys[hash]
+	if ks == nil {
+		ks = &apiKeyState{
+			limiter: rate.NewLimiter(rate.Limit(dbKey.RateLimit), int(dbKey.Burst)),
+		}
+		k.keys[hash] = ks
+	} else {
+		ks.limiter.SetLimitAt(now, rate.Limit(dbKey.RateLimit))
+		ks.limiter.SetBurstAt(now, int(dbKey.Burst))
+	}
+	ks.key = dbKey
+	ks.month = month
+	ks.used = used
+	ks.loaded = now
+	return ks, nil
+}
+
+// allow checks the rate limit and quota of the key for a request, counting
+// the request in the usage of the route group. remaining is the number of
+// requests left in the month's quota, or -1 if the key has no quota. If the
+// request is refused, the reason is returned with the time after which the
+// request may be retried.
+func (k *APIKeys) allow(ks *apiKeyState, group string) (remaining int64, retryAfter time.Duration, reason string) {
+	now := k.now()
+	month := monthOf(now)
+
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	if !ks.month.Equal(month) {
+		ks.month = month
+		ks.used = 0
+	}
+
+	uk := usageKey{ks.key.ID, month, group}
+	u := k.pending[uk]
+	if u == nil {
+		u = &dbtypes.APIKeyUsage{KeyID: ks.key.ID, Month: month, RouteGroup: group}
+		k.pending[uk] = u
+	}
+
+	quota := ks.key.MonthlyQuota
+	if quota > 0 && ks.used >= quota {
+		u.Limited++
+		return 0, month.AddDate(0, 1, 0).Sub(now), fmt.Sprintf(
+			"The monthly quota of %d requests for the API key is used up.", quota)
+	}
+	if !ks.limiter.AllowN(now, 1) {
+		u.Limited++
+		return 0, time.Second, fmt.Sprintf(
+			"You have reached the maximum request limit for the API key (%g req/s).",
+			ks.key.RateLimit)
+	}
+
+	ks.used++
+	u.Requests++
+	if quota == 0 {
+		return -1, 0, ""
+	}
+	return quota - ks.used, 0, ""
+}
+
+// flush stores the pending usage counters. Counters that could not be stored
+// are kept for the next flush.
+func (k *APIKeys) flush() error {
+	k.mtx.Lock()
+	if len(k.pending) == 0 {
+		k.mtx.Unlock()
+		return nil
+	}
+	pending := k.pending
+	k.pending = make(map[usageKey]*dbtypes.APIKeyUsage, len(pending))
+	k.mtx.Unlock()
+
+	usage := make([]*dbtypes.APIKeyUsage, 0, len(pending))
+	for _, u := range pending {
+		usage = append(usage, u)
+	}
+	err := k.store.AddAPIKeyUsage(usage)
+	if err == nil {
+		return nil
+	}
+
+	k.mtx.Lock()
+	for uk, u := range pending {
+		if cur := k.pending[uk]; cur != nil {
+			cur.Requests += u.Requests
+			cur.Limited += u.Limited
+		} else {
+			k.pending[uk] = u
+		}
+	}
+	k.mtx.Unlock()
+	return err
+}
+
+// Run stores the usage counters periodically until the context is canceled,
+// when the remaining counters are stored.
+func (k *APIKeys) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(apiKeyFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := k.flush(); err != nil {
+				apiLog.Errorf("Failed to store the API key usage: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := k.flush(); err != nil {
+				apiLog.Errorf("Failed to store the API key usage: %v", err)
+			}
+		}
+	}
+}
+
+// requestAPIKey returns the API key of the request, from the APIKeyHeader
+// header or the APIKeyQuery URL query parameter.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(APIKeyQuery)
+}
+
+// Limit creates a middleware that authenticates the API key of a request, and
+// applies the key's rate limit and monthly quota, counting the request in the
+// key's usage for the route group. Requests with an unknown or revoked key are
+// refused. Requests without a key are limited by the fallback per-IP Limiter,
+// if it is not nil. A nil APIKeys only applies the fallback Limiter.
+func (k *APIKeys) Limit(group string, fallback *Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		keyless := next
+		if fallback != nil {
+			keyless = Tollbooth(fallback)(next)
+		}
+		if k == nil {
+			return keyless
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := requestAPIKey(r)
+			if key == "" {
+				keyless.ServeHTTP(w, r)
+				return
+			}
+
+			ks, err := k.lookup(key)
+			if err != nil {
+				apiLog.Errorf("Failed to retrieve API key: %v", err)
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if ks == nil {
+				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				return
+			}
+
+			remaining, retryAfter, reason := k.allow(ks, group)
+			if reason != "" {
+				secs := int64(math.Ceil(retryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+				http.Error(w, reason, http.StatusTooManyRequests)
+				return
+			}
+			if remaining >= 0 {
+				w.Header().Set("X-API-Quota-Remaining", strconv.FormatInt(remaining, 10))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// memAPIKeyStore is an APIKeyStore in memory.
+type memAPIKeyStore struct {
+	mtx   sync.Mutex
+	keys  map[string]*dbtypes.APIKey // by hash
+	usage map[usageKey]*dbtypes.APIKeyUsage
+}
+
+func newMemAPIKeyStore() *memAPIKeyStore {
+	return &memAPIKeyStore{
+		keys:  make(map[string]*dbtypes.APIKey),
+		usage: make(map[usageKey]*dbtypes.APIKeyUsage),
+	}
+}
+
+func (s *memAPIKeyStore) CreateAPIKey(key *dbtypes.APIKey) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	k := *key
+	k.Key = ""
+	s.keys[key.KeyHash] = &k
+	return nil
+}
+
+func (s *memAPIKeyStore) APIKeyByHash(keyHash string) (*dbtypes.APIKey, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	key := s.keys[keyHash]
+	if key == nil {
+		return nil, nil
+	}
+	k := *key
+	return &k, nil
+}
+
+func (s *memAPIKeyStore) APIKeys(month time.Time) ([]*dbtypes.APIKey, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var keys []*dbtypes.APIKey
+	for _, key := range s.keys {
+		k := *key
+		for uk, u := range s.usage {
+			if uk.id == k.ID && uk.month.Equal(monthOf(month)) {
+				k.Usage = append(k.Usage, u)
+			}
+		}
+		keys = append(keys, &k)
+	}
+	return keys, nil
+}
+
+func (s *memAPIKeyStore) RevokeAPIKey(id string) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, key := range s.keys {
+		if key.ID == id && key.Revoked == nil {
+			revoked := dbtypes.NewTimeDef(time.Now())
+			key.Revoked = &revoked
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memAPIKeyStore) AddAPIKeyUsage(usage []*dbtypes.APIKeyUsage) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, u := range usage {
+		uk := usageKey{u.KeyID, monthOf(u.Month), u.RouteGroup}
+		if cur := s.usage[uk]; cur != nil {
+			cur.Requests += u.Requests
+			cur.Limited += u.Limited
+			continue
+		}
+		uc := *u
+		s.usage[uk] = &uc
+	}
+	return nil
+}
+
+func (s *memAPIKeyStore) APIKeyMonthRequests(id string, month time.Time) (int64, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var N int64
+	for uk, u := range s.usage {
+		if uk.id == id && uk.month.Equal(monthOf(month)) {
+			N += u.Requests
+		}
+	}
+	return N, nil
+}
+
+func TestNewAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     apitypes.APIKeyRequest
+		burst   int32
+		wantErr bool
+	}{
+		{"default burst", apitypes.APIKeyRequest{Name: "partner", RateLimit: 2.5}, 3, false},
+		{"burst", apitypes.APIKeyRequest{Name: "partner", RateLimit: 2, Burst: 10}, 10, false},
+		{"no name", apitypes.APIKeyRequest{RateLimit: 2}, 0, true},
+		{"no rate limit", apitypes.APIKeyRequest{Name: "partner"}, 0, true},
+		{"negative burst", apitypes.APIKeyRequest{Name: "partner", RateLimit: 1, Burst: -1}, 0, true},
+		{"negative quota", apitypes.APIKeyRequest{Name: "partner", RateLimit: 1, MonthlyQuota: -1}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := NewAPIKey(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if key.Burst != tt.burst {
+				t.Errorf("burst %d, expected %d", key.Burst, tt.burst)
+			}
+			if len(key.ID) != 16 || len(key.Key) != 64 || key.KeyHash != hashAPIKey(key.Key) {
+				t.Errorf("unexpected key %+v", key)
+			}
+		})
+	}
+}
+
+func TestAPIKeysLimit(t *testing.T) {
+	store := newMemAPIKeyStore()
+	keys := NewAPIKeys(store)
+	now := time.Date(2021, 6, 30, 23, 59, 0, 0, time.UTC)
+	keys.now = func() time.Time { return now }
+
+	key, err := NewAPIKey(&apitypes.APIKeyRequest{Name: "partner", RateLimit: 1,
+		Burst: 2, MonthlyQuota: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = keys.CreateAPIKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := keys.Limit(APIKeyGroupAPI, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := func(apiKey string, query bool) *httptest.ResponseRecorder {
+		target := "/api/block/best"
+		if query {
+			target += "?" + APIKeyQuery + "=" + apiKey
+		}
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if apiKey != "" && !query {
+			r.Header.Set(APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	expect := func(w *httptest.ResponseRecorder, code int, remaining string) {
+		t.Helper()
+		if w.Code != code {
+			t.Fatalf("status %d, expected %d: %s", w.Code, code, w.Body.String())
+		}
+		if got := w.Header().Get("X-API-Quota-Remaining"); got != remaining {
+			t.Errorf("quota remaining %q, expected %q", got, remaining)
+		}
+	}
+
+	// The burst allows two requests at once.
+	expect(request(key.Key, false), http.StatusOK, "2")
+	expect(request(key.Key, true), http.StatusOK, "1")
+	expect(request(key.Key, false), http.StatusTooManyRequests, "")
+
+	// The last request of the month's quota.
+	now = now.Add(10 * time.Second)
+	expect(request(key.Key, false), http.StatusOK, "0")
+	now = now.Add(10 * time.Second)
+	w := request(key.Key, false)
+	expect(w, http.StatusTooManyRequests, "")
+	if w.Header().Get("Retry-After") != "40" {
+		t.Errorf("Retry-After %q, expected 40", w.Header().Get("Retry-After"))
+	}
+
+	// Unknown keys are refused, and keyless requests are not limited.
+	expect(request("abcd", false), http.StatusUnauthorized, "")
+	expect(request("", false), http.StatusOK, "")
+
+	// The quota is renewed in the new month.
+	now = now.Add(time.Minute)
+	expect(request(key.Key, false), http.StatusOK, "2")
+
+	list, err := keys.APIKeys(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || len(list[0].Usage) != 1 {
+		t.Fatalf("unexpected keys %+v", list)
+	}
+	if u := list[0].Usage[0]; u.RouteGroup != APIKeyGroupAPI || u.Requests != 3 || u.Limited != 2 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+
+	// The stored usage counts towards the quota of a reloaded key.
+	keys = NewAPIKeys(store)
+	keys.now = func() time.Time { return now }
+	handler = keys.Limit(APIKeyGroupAPI, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	expect(request(key.Key, false), http.StatusOK, "1")
+
+	// A revoked key is refused.
+	found, err := keys.RevokeAPIKey(key.ID)
+	if err != nil || !found {
+		t.Fatalf("RevokeAPIKey: %v, %v", found, err)
+	}
+	expect(request(key.Key, false), http.StatusUnauthorized, "")
+}
@@ -7,6 +7,7 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -60,6 +61,14 @@ const (
 	ctxXcToken
 	ctxStickWidth
 	ctxIndent
+	ctxSecretHash
+	ctxTimeStart
+	ctxTimeEnd
+	ctxTxType
+	ctxWebhookID
+	ctxAPIKeyID
+	ctxXpub
+	ctxTicketSetID
 )
 
 type DataSource interface {
@@ -90,6 +99,22 @@ func NewLimiter(max float64) *Limiter {
 	return &Limiter{tollbooth.NewLimiter(max, nil)}
 }
 
+// NewIPLimiter creates a new Limiter of the request rate of each client IP. If
+// useRealIP is true, the RealIP middleware must set the client IP as the
+// request's RemoteAddr before the limiter.
+func NewIPLimiter(max float64, useRealIP bool) *Limiter {
+	l := NewLimiter(max)
+	l.SetMessage(fmt.Sprintf(
+		"You have reached the maximum request limit (%g req/s)", max))
+	if useRealIP {
+		// RealIP sets RemoteAddr
+		l.SetIPLookups([]string{"RemoteAddr"})
+	} else {
+		l.SetIPLookups([]string{"X-Forwarded-For", "X-Real-IP", "RemoteAddr"})
+	}
+	return l
+}
+
 // Tollbooth creates a new rate limiter middleware using the provided Limiter.
 func Tollbooth(l *Limiter) func(http.Handler) http.Handler {
 	// Create a middleware, capturing the Limiter.
@@ -586,6 +611,28 @@ func Server(server string) func(http.Handler) http.Handler {
 	}
 }
 
+// AdminAuth creates a middleware that requires HTTP basic authentication with
+// the admin password, and any user name. If password is empty, the admin API
+// is disabled and all requests are refused.
+func AdminAuth(password string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if password == "" {
+				http.Error(w, "admin API is disabled", http.StatusForbidden)
+				return
+			}
+			_, pass, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+				apiLog.Warnf("Unauthorized admin request to %s from %s", r.URL.Path, r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", `Basic realm="dcrdata admin"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // BlockStepPathCtx returns a http.HandlerFunc that embeds the value at the url
 // part {step} into the request context.
 func BlockStepPathCtx(next http.Handler) http.Handler {
@@ -707,6 +754,163 @@ func ProposalTokenCtx(next http.Handler) http.Handler {
 	})
 }
 
+// SecretHashPathCtx returns a http.HandlerFunc that embeds the value at the url
+// part {secrethash} into the request context.
+func SecretHashPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secretHash := chi.URLParam(r, "secrethash")
+		ctx := context.WithValue(r.Context(), ctxSecretHash, secretHash)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetSecretHashCtx retrieves the ctxSecretHash data from the request context.
+// If the value is not set, an empty string is returned.
+func GetSecretHashCtx(r *http.Request) string {
+	secretHash, ok := r.Context().Value(ctxSecretHash).(string)
+	if !ok {
+		apiLog.Trace("secret hash not set")
+		return ""
+	}
+	return secretHash
+}
+
+// TimeRangePathCtx returns a http.HandlerFunc that embeds the UNIX time values
+// at the url parts {start} and {end} into the request context.
+func TimeRangePathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, err := strconv.ParseInt(chi.URLParam(r, "start"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid start time", 422)
+			return
+		}
+		end, err := strconv.ParseInt(chi.URLParam(r, "end"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid end time", 422)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ctxTimeStart, start)
+		ctx = context.WithValue(ctx, ctxTimeEnd, end)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetTimeRangeCtx retrieves the ctxTimeStart and ctxTimeEnd data from the
+// request context. If either value is not set, ok is false.
+func GetTimeRangeCtx(r *http.Request) (start, end int64, ok bool) {
+	start, okStart := r.Context().Value(ctxTimeStart).(int64)
+	end, okEnd := r.Context().Value(ctxTimeEnd).(int64)
+	if !okStart || !okEnd {
+		apiLog.Trace("time range not set")
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// TxTypePathCtx returns a http.HandlerFunc that embeds the value at the url
+// part {txtype} into the request context.
+func TxTypePathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		txType := chi.URLParam(r, "txtype")
+		ctx := context.WithValue(r.Context(), ctxTxType, txType)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetTxTypeCtx retrieves the ctxTxType data from the request context. If the
+// value is not set, an empty string is returned.
+func GetTxTypeCtx(r *http.Request) string {
+	txType, ok := r.Context().Value(ctxTxType).(string)
+	if !ok {
+		apiLog.Trace("transaction type not set")
+		return ""
+	}
+	return txType
+}
+
+// WebhookIDPathCtx returns a http.HandlerFunc that embeds the value at the url
+// part {webhookid} into the request context.
+func WebhookIDPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "webhookid")
+		ctx := context.WithValue(r.Context(), ctxWebhookID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetWebhookIDCtx retrieves the ctxWebhookID data from the request context. If
+// the value is not set, an empty string is returned.
+func GetWebhookIDCtx(r *http.Request) string {
+	id, ok := r.Context().Value(ctxWebhookID).(string)
+	if !ok {
+		apiLog.Trace("webhook ID not set")
+		return ""
+	}
+	return id
+}
+
+// TicketSetIDPathCtx returns a http.HandlerFunc that embeds the value at the
+// url part {setid} into the request context.
+func TicketSetIDPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "setid")
+		ctx := context.WithValue(r.Context(), ctxTicketSetID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetTicketSetIDCtx retrieves the ctxTicketSetID data from the request
+// context. If the value is not set, an empty string is returned.
+func GetTicketSetIDCtx(r *http.Request) string {
+	id, ok := r.Context().Value(ctxTicketSetID).(string)
+	if !ok {
+		apiLog.Trace("ticket set ID not set")
+		return ""
+	}
+	return id
+}
+
+// APIKeyIDPathCtx returns a http.HandlerFunc that embeds the value at the url
+// part {keyid} into the request context.
+func APIKeyIDPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "keyid")
+		ctx := context.WithValue(r.Context(), ctxAPIKeyID, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetAPIKeyIDCtx retrieves the ctxAPIKeyID data from the request context. If
+// the value is not set, an empty string is returned.
+func GetAPIKeyIDCtx(r *http.Request) string {
+	id, ok := r.Context().Value(ctxAPIKeyID).(string)
+	if !ok {
+		apiLog.Trace("API key ID not set")
+		return ""
+	}
+	return id
+}
+
+// XpubPathCtx embeds "xpub" into the request context.
+func XpubPathCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xpub := chi.URLParam(r, "xpub")
+		ctx := context.WithValue(r.Context(), ctxXpub, xpub)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetXpubCtx retrieves the ctxXpub data from the request context. If the value
+// is not set, an empty string is returned.
+func GetXpubCtx(r *http.Request) string {
+	xpub, ok := r.Context().Value(ctxXpub).(string)
+	if !ok {
+		apiLog.Trace("xpub not set")
+		return ""
+	}
+	return xpub
+}
+
 // BlockHashPathCtx returns a http.HandlerFunc that embeds the value at the url
 // part {blockhash} into the request context.
 func BlockHashPathCtx(next http.Handler) http.Handler {
@@ -132,3 +132,40 @@ func TestGetAddressCtx(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		user     string
+		pass     string
+		noAuth   bool
+		wantCode int
+	}{
+		{"disabled", "", "admin", "", false, http.StatusForbidden},
+		{"no auth", "secret", "", "", true, http.StatusUnauthorized},
+		{"wrong password", "secret", "admin", "secreT", false, http.StatusUnauthorized},
+		{"ok", "secret", "admin", "secret", false, http.StatusOK},
+		{"any user", "secret", "", "secret", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AdminAuth(tt.password)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodPut, "/labels/x", nil)
+			if !tt.noAuth {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, req)
+			if writer.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, writer.Code)
+			}
+			if tt.wantCode == http.StatusUnauthorized && writer.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -75,8 +75,13 @@ type Notifier struct {
 		hash   chainhash.Hash
 		height uint32
 	}
+	observe HandlerObserver
 }
 
+// HandlerObserver is called with the type of a notification, "block", "tx" or
+// "reorg", and the time taken by all of its handlers.
+type HandlerObserver func(ntfn string, d time.Duration)
+
 // NewNotifier is the constructor for a Notifier.
 func NewNotifier() *Notifier {
 	return &Notifier{
@@ -90,6 +95,17 @@ func NewNotifier() *Notifier {
 	}
 }
 
+// ObserveHandlers sets a function to call with the time taken by the handlers
+// of each notification. It must be called before Listen.
+func (notifier *Notifier) ObserveHandlers(observe HandlerObserver) {
+	notifier.observe = observe
+}
+
+// QueueLength returns the number of notifications waiting to be processed.
+func (notifier *Notifier) QueueLength() int {
+	return len(notifier.anyQ)
+}
+
 // DCRDNode is an interface to wrap a dcrd rpcclient.Client. The interface
 // allows testing with a dummy node.
 type DCRDNode interface {
@@ -346,6 +362,9 @@ func (notifier *Notifier) processBlock(bh *wire.BlockHeader) {
 		}
 	}
 	log.Debugf("handlers of Notifier.processBlock() completed in %v", time.Since(start))
+	if notifier.observe != nil {
+		notifier.observe("block", time.Since(start))
+	}
 
 	// Record this block as the best block connected by the collectionQueue.
 	notifier.SetPreviousBlock(hash, height)
@@ -381,6 +400,9 @@ func (notifier *Notifier) processTx(tx *chainjson.TxRawResult) {
 		}
 	}
 	log.Tracef("handlers of Notifier.onTxAcceptedVerbose() completed in %v", time.Since(start))
+	if notifier.observe != nil {
+		notifier.observe("tx", time.Since(start))
+	}
 }
 
 // signalReorg takes the basic reorganization data from dcrd, determines the two
@@ -436,6 +458,9 @@ func (notifier *Notifier) signalReorg(d BranchTips) {
 		}
 	}
 	log.Debugf("handlers of Notifier.signalReorg() completed in %v", time.Since(start))
+	if notifier.observe != nil {
+		notifier.observe("reorg", time.Since(start))
+	}
 
 	// Update prevHash and prevHeight in collectionQueue.
 	notifier.SetPreviousBlock(d.NewChainHead, uint32(d.NewChainHeight))
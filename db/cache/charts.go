@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/semver"
 	"github.com/decred/dcrdata/v6/txhelpers"
 )
@@ -35,6 +36,8 @@ const (
 	TicketPoolValue = "ticket-pool-value"
 	WindMissedVotes = "missed-votes"
 	PercentStaked   = "stake-participation"
+	AddressBalances = "address-balances"
+	ConfirmTimes    = "confirmation-times"
 
 	// Some chartResponse keys
 	heightKey       = "h"
@@ -56,6 +59,10 @@ const (
 	durationKey     = "duration"
 	workKey         = "work"
 	rateKey         = "rate"
+	minBalanceKey   = "minbalance"
+	balanceKey      = "balance"
+	minFeeRateKey   = "minfeerate"
+	confirmTimeKey  = "confirmtime"
 )
 
 // binLevel specifies the granularity of data.
@@ -72,6 +79,11 @@ const (
 	WindowBin  binLevel = "window"
 	HeightAxis axisType = "height"
 	TimeAxis   axisType = "time"
+
+	// SnapshotBin is for charts of the current state, such as the address
+	// balance distribution and the confirmation times, rather than a time
+	// series.
+	SnapshotBin binLevel = "snapshot"
 )
 
 // Check if the chart is window binned.
@@ -95,6 +107,8 @@ func ParseBin(bin string) binLevel {
 		return BlockBin
 	case WindowBin:
 		return WindowBin
+	case SnapshotBin:
+		return SnapshotBin
 	}
 	return DefaultBinLevel
 }
@@ -352,6 +366,27 @@ func newWindowSet(size int) *windowSet {
 	}
 }
 
+// distributionSet is the distribution of address balances in the buckets
+// computed by the database package. Each bucket holds the balances from its
+// MinBalance, in atoms, up to the MinBalance of the next bucket.
+type distributionSet struct {
+	cacheID    uint64
+	MinBalance ChartUints
+	Count      ChartUints
+	Balance    ChartUints
+}
+
+// confirmationSet is the number and median time to confirmation in seconds of
+// the recently mined transactions in the fee rate buckets computed by the
+// database package. Each bucket holds the fee rates from its MinFeeRate, in
+// atoms/kB, up to the MinFeeRate of the next bucket.
+type confirmationSet struct {
+	cacheID    uint64
+	MinFeeRate ChartUints
+	Count      ChartUints
+	MedianTime ChartFloats
+}
+
 // ChartGobject is the storage object for saving to a gob file. ChartData itself
 // has a lot of extraneous fields, and also embeds sync.RWMutex, so is not
 // suitable for gobbing.
@@ -420,6 +455,8 @@ type ChartData struct {
 	Blocks       *zoomSet
 	Windows      *windowSet
 	Days         *zoomSet
+	Distribution *distributionSet
+	ConfirmTimes *confirmationSet
 	cacheMtx     sync.RWMutex
 	cache        map[string]*cachedChart
 	updateMtx    sync.Mutex
@@ -719,6 +756,46 @@ func (charts *ChartData) Dump(dumpPath string) {
 	}
 }
 
+// SetAddressDistribution replaces the address balance distribution data with
+// the balance buckets, which must be in increasing order of MinBalance.
+func (charts *ChartData) SetAddressDistribution(buckets []dbtypes.BalanceBucket) {
+	set := &distributionSet{
+		MinBalance: newChartUints(len(buckets)),
+		Count:      newChartUints(len(buckets)),
+		Balance:    newChartUints(len(buckets)),
+	}
+	for _, b := range buckets {
+		set.MinBalance = append(set.MinBalance, uint64(b.MinBalance))
+		set.Count = append(set.Count, uint64(b.NumAddresses))
+		set.Balance = append(set.Balance, uint64(b.Balance))
+	}
+
+	charts.mtx.Lock()
+	defer charts.mtx.Unlock()
+	set.cacheID = charts.Distribution.cacheID + 1
+	charts.Distribution = set
+}
+
+// SetConfirmationTimes replaces the confirmation time data with the fee rate
+// buckets, which must be in increasing order of MinFeeRate.
+func (charts *ChartData) SetConfirmationTimes(buckets []dbtypes.ConfirmationTimeBucket) {
+	set := &confirmationSet{
+		MinFeeRate: newChartUints(len(buckets)),
+		Count:      newChartUints(len(buckets)),
+		MedianTime: newChartFloats(len(buckets)),
+	}
+	for _, b := range buckets {
+		set.MinFeeRate = append(set.MinFeeRate, uint64(b.MinFeeRate))
+		set.Count = append(set.Count, uint64(b.Count))
+		set.MedianTime = append(set.MedianTime, b.MedianTime)
+	}
+
+	charts.mtx.Lock()
+	defer charts.mtx.Unlock()
+	set.cacheID = charts.ConfirmTimes.cacheID + 1
+	charts.ConfirmTimes = set
+}
+
 // TriggerUpdate triggers (*ChartData).Update.
 func (charts *ChartData) TriggerUpdate(_ string, _ uint32) error {
 	if err := charts.Update(); err != nil {
@@ -909,6 +986,8 @@ func NewChartData(ctx context.Context, height uint32, chainParams *chaincfg.Para
 		Blocks:       newBlockSet(size),
 		Windows:      newWindowSet(windows),
 		Days:         newDaySet(days),
+		Distribution: new(distributionSet),
+		ConfirmTimes: new(confirmationSet),
 		cache:        make(map[string]*cachedChart),
 		updaters:     make([]ChartUpdater, 0),
 	}
@@ -930,6 +1009,10 @@ func (charts *ChartData) cacheID(bin binLevel) uint64 {
 		return charts.Days.cacheID
 	case WindowBin:
 		return charts.Windows.cacheID
+	case SnapshotBin:
+		// Both snapshot sets only ever increase their cacheID, so the sum
+		// changes when either set is replaced.
+		return charts.Distribution.cacheID + charts.ConfirmTimes.cacheID
 	}
 	return 0
 }
@@ -979,6 +1062,8 @@ var chartMakers = map[string]ChartMaker{
 	TicketPoolValue: poolValueChart,
 	WindMissedVotes: missedVotesChart,
 	PercentStaked:   stakedCoinsChart,
+	AddressBalances: addressBalancesChart,
+	ConfirmTimes:    confirmationTimesChart,
 }
 
 // Chart will return a JSON-encoded chartResponse of the provided chart,
@@ -987,6 +1072,8 @@ var chartMakers = map[string]ChartMaker{
 func (charts *ChartData) Chart(chartID, binString, axisString string) ([]byte, error) {
 	if isWindowBin(chartID) {
 		binString = string(WindowBin)
+	} else if chartID == AddressBalances || chartID == ConfirmTimes {
+		binString = string(SnapshotBin)
 	}
 	bin := ParseBin(binString)
 	axis := ParseAxis(axisString)
@@ -1618,3 +1705,19 @@ func stakedCoinsChart(charts *ChartData, bin binLevel, axis axisType) ([]byte, e
 	}
 	return nil, InvalidBinErr
 }
+
+func addressBalancesChart(charts *ChartData, _ binLevel, _ axisType) ([]byte, error) {
+	return encode(lengtherMap{
+		minBalanceKey: charts.Distribution.MinBalance,
+		countKey:      charts.Distribution.Count,
+		balanceKey:    charts.Distribution.Balance,
+	}, nil)
+}
+
+func confirmationTimesChart(charts *ChartData, _ binLevel, _ axisType) ([]byte, error) {
+	return encode(lengtherMap{
+		minFeeRateKey:  charts.ConfirmTimes.MinFeeRate,
+		countKey:       charts.ConfirmTimes.Count,
+		confirmTimeKey: charts.ConfirmTimes.MedianTime,
+	}, nil)
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/txhelpers"
 )
 
@@ -348,3 +349,82 @@ func TestChartReorg(t *testing.T) {
 	resetCharts()
 	testReorg(2, 2, 1, 1, 2)
 }
+
+func TestAddressBalancesChart(t *testing.T) {
+	charts := NewChartData(context.Background(), 0, chaincfg.MainNetParams())
+
+	chart := func() map[string][]uint64 {
+		t.Helper()
+		b, err := charts.Chart(AddressBalances, "", "")
+		if err != nil {
+			t.Fatalf("Chart error: %v", err)
+		}
+		var resp map[string][]uint64
+		if err = json.Unmarshal(b, &resp); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		return resp
+	}
+
+	if resp := chart(); len(resp[countKey]) != 0 {
+		t.Fatalf("expected an empty chart, got %v", resp)
+	}
+
+	charts.SetAddressDistribution([]dbtypes.BalanceBucket{
+		{MinBalance: 0, NumAddresses: 100, Balance: 5e5},
+		{MinBalance: 1e6, NumAddresses: 10, Balance: 3e6},
+	})
+	// The cached empty chart must be replaced.
+	resp := chart()
+	want := map[string][]uint64{
+		minBalanceKey: {0, 1e6},
+		countKey:      {100, 10},
+		balanceKey:    {5e5, 3e6},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("got chart %v, want %v", resp, want)
+	}
+}
+
+func TestConfirmationTimesChart(t *testing.T) {
+	charts := NewChartData(context.Background(), 0, chaincfg.MainNetParams())
+
+	chart := func() map[string][]float64 {
+		t.Helper()
+		b, err := charts.Chart(ConfirmTimes, "", "")
+		if err != nil {
+			t.Fatalf("Chart error: %v", err)
+		}
+		var resp map[string][]float64
+		if err = json.Unmarshal(b, &resp); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		return resp
+	}
+
+	if resp := chart(); len(resp[countKey]) != 0 {
+		t.Fatalf("expected an empty chart, got %v", resp)
+	}
+
+	charts.SetConfirmationTimes([]dbtypes.ConfirmationTimeBucket{
+		{MinFeeRate: 0, Count: 3, MedianTime: 600.5},
+		{MinFeeRate: 1e4, Count: 50, MedianTime: 150},
+	})
+	// The cached empty chart must be replaced.
+	resp := chart()
+	want := map[string][]float64{
+		minFeeRateKey:  {0, 1e4},
+		countKey:       {3, 50},
+		confirmTimeKey: {600.5, 150},
+	}
+	if !reflect.DeepEqual(resp, want) {
+		t.Errorf("got chart %v, want %v", resp, want)
+	}
+
+	// Replacing the address distribution must not return the cached
+	// confirmation times of the other snapshot chart.
+	charts.SetAddressDistribution([]dbtypes.BalanceBucket{{MinBalance: 0, NumAddresses: 1}})
+	if resp = chart(); !reflect.DeepEqual(resp, want) {
+		t.Errorf("got chart %v, want %v", resp, want)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dbtypes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// AddressLabelsFileVersion is the version of the address labels file format
+// understood by ReadAddressLabelsFile.
+const AddressLabelsFileVersion = 1
+
+// MaxAddressLabelLength is the maximum length of the text of an address label.
+const MaxAddressLabelLength = 64
+
+// Sources of address labels. Labels loaded from the labels file are replaced
+// each time the file is loaded, while labels set through the admin API are
+// kept, and take precedence over the file. Built-in labels, such as for the
+// project fund address, are not stored, and apply only to addresses without
+// another label.
+const (
+	AddressLabelSourceFile    = "file"
+	AddressLabelSourceAPI     = "api"
+	AddressLabelSourceBuiltIn = "builtin"
+)
+
+// AddressLabel identifies the well-known entity, such as an exchange, a mixing
+// pool or a VSP, that controls an address. Category is one of the AddressTag
+// constants. Updated is the time the label was stored, and is nil for
+// built-in labels.
+type AddressLabel struct {
+	Address  string   `json:"address" yaml:"address"`
+	Label    string   `json:"label" yaml:"label"`
+	Category string   `json:"category" yaml:"category"`
+	Source   string   `json:"source,omitempty" yaml:"-"`
+	Updated  *TimeDef `json:"updated,omitempty" yaml:"-"`
+}
+
+// IsValidAddressLabelCategory checks if category is one of the AddressTag
+// constants.
+func IsValidAddressLabelCategory(category string) bool {
+	switch category {
+	case AddressTagTreasury, AddressTagDevFund, AddressTagExchange,
+		AddressTagMixing, AddressTagVSP, AddressTagOther:
+		return true
+	}
+	return false
+}
+
+// Validate checks that the label has an address, a label of at most
+// MaxAddressLabelLength characters, and a valid category. The address is not
+// checked for a network.
+func (l *AddressLabel) Validate() error {
+	if l.Address == "" {
+		return fmt.Errorf("missing address")
+	}
+	label := strings.TrimSpace(l.Label)
+	if label == "" {
+		return fmt.Errorf("missing label for address %s", l.Address)
+	}
+	if len(label) > MaxAddressLabelLength {
+		return fmt.Errorf("label for address %s is longer than %d characters",
+			l.Address, MaxAddressLabelLength)
+	}
+	if !IsValidAddressLabelCategory(l.Category) {
+		return fmt.Errorf("invalid category %q for address %s", l.Category, l.Address)
+	}
+	return nil
+}
+
+// AddressLabelsFile is the content of a versioned address labels file.
+type AddressLabelsFile struct {
+	Version int             `json:"version" yaml:"version"`
+	Labels  []*AddressLabel `json:"labels" yaml:"labels"`
+}
+
+// ParseAddressLabels parses and validates the content of an address labels
+// file, in YAML if isYAML is set, or in JSON otherwise. Duplicate addresses
+// are an error.
+func ParseAddressLabels(b []byte, isYAML bool) (*AddressLabelsFile, error) {
+	var file AddressLabelsFile
+	var err error
+	if isYAML {
+		err = yaml.UnmarshalStrict(b, &file)
+	} else {
+		err = json.Unmarshal(b, &file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Version != AddressLabelsFileVersion {
+		return nil, fmt.Errorf("unsupported address labels file version %d, expected %d",
+			file.Version, AddressLabelsFileVersion)
+	}
+
+	seen := make(map[string]struct{}, len(file.Labels))
+	for i, l := range file.Labels {
+		if l == nil {
+			return nil, fmt.Errorf("empty label at index %d", i)
+		}
+		l.Label = strings.TrimSpace(l.Label)
+		if err = l.Validate(); err != nil {
+			return nil, err
+		}
+		if _, found := seen[l.Address]; found {
+			return nil, fmt.Errorf("duplicate label for address %s", l.Address)
+		}
+		seen[l.Address] = struct{}{}
+		l.Source = AddressLabelSourceFile
+	}
+	return &file, nil
+}
+
+// ReadAddressLabelsFile reads an address labels file. Files with a .yaml or
+// .yml extension are parsed as YAML, and all others as JSON.
+func ReadAddressLabelsFile(path string) (*AddressLabelsFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	return ParseAddressLabels(b, ext == ".yaml" || ext == ".yml")
+}
@@ -0,0 +1,100 @@
+package dbtypes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAddressLabels(t *testing.T) {
+	const addr1, addr2 = "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu", "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx"
+	tests := []struct {
+		name    string
+		data    string
+		isYAML  bool
+		wantErr string
+		want    []*AddressLabel
+	}{
+		{
+			name: "json",
+			data: `{"version": 1, "labels": [
+				{"address": "` + addr1 + `", "label": " Some Exchange ", "category": "exchange"},
+				{"address": "` + addr2 + `", "label": "Some VSP", "category": "vsp"}]}`,
+			want: []*AddressLabel{
+				{Address: addr1, Label: "Some Exchange", Category: AddressTagExchange},
+				{Address: addr2, Label: "Some VSP", Category: AddressTagVSP},
+			},
+		},
+		{
+			name: "yaml",
+			data: "version: 1\nlabels:\n" +
+				"- address: " + addr1 + "\n  label: Mixing pool\n  category: mixing\n",
+			isYAML: true,
+			want: []*AddressLabel{
+				{Address: addr1, Label: "Mixing pool", Category: AddressTagMixing},
+			},
+		},
+		{
+			name:    "yaml unknown field",
+			data:    "version: 1\nlabels:\n- address: " + addr1 + "\n  name: x\n",
+			isYAML:  true,
+			wantErr: "not found",
+		},
+		{
+			name:    "no version",
+			data:    `{"labels": []}`,
+			wantErr: "unsupported address labels file version 0",
+		},
+		{
+			name: "bad category",
+			data: `{"version": 1, "labels": [
+				{"address": "` + addr1 + `", "label": "x", "category": "whale"}]}`,
+			wantErr: "invalid category",
+		},
+		{
+			name: "empty label",
+			data: `{"version": 1, "labels": [
+				{"address": "` + addr1 + `", "label": "  ", "category": "other"}]}`,
+			wantErr: "missing label",
+		},
+		{
+			name: "long label",
+			data: `{"version": 1, "labels": [
+				{"address": "` + addr1 + `", "label": "` + strings.Repeat("x", MaxAddressLabelLength+1) + `", "category": "other"}]}`,
+			wantErr: "longer than",
+		},
+		{
+			name: "duplicate",
+			data: `{"version": 1, "labels": [
+				{"address": "` + addr1 + `", "label": "a", "category": "other"},
+				{"address": "` + addr1 + `", "label": "b", "category": "other"}]}`,
+			wantErr: "duplicate label",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseAddressLabels([]byte(tt.data), tt.isYAML)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAddressLabels: %v", err)
+			}
+			if len(file.Labels) != len(tt.want) {
+				t.Fatalf("expected %d labels, got %d", len(tt.want), len(file.Labels))
+			}
+			for i, l := range file.Labels {
+				want := tt.want[i]
+				if l.Address != want.Address || l.Label != want.Label || l.Category != want.Category {
+					t.Errorf("label %d: expected %+v, got %+v", i, want, l)
+				}
+				if l.Source != AddressLabelSourceFile {
+					t.Errorf("label %d: expected source %q, got %q", i, AddressLabelSourceFile, l.Source)
+				}
+			}
+		})
+	}
+}
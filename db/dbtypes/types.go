@@ -648,8 +648,8 @@ func (p *VinTxPropertyARRAY) Scan(src interface{}) error {
 // DeletionSummary provides the number of rows removed from the tables when a
 // block is removed.
 type DeletionSummary struct {
-	Blocks, Vins, Vouts, Addresses, Transactions, Tickets, Votes, Misses int64
-	Timings                                                              *DeletionSummary
+	Blocks, Vins, Vouts, Addresses, Transactions, Tickets, Votes, Misses, Swaps int64
+	Timings                                                                     *DeletionSummary
 }
 
 // String makes a pretty summary of the totals.
@@ -661,7 +661,8 @@ func (s DeletionSummary) String() string {
 	summary += fmt.Sprintf("%9d Transactions purged\n", s.Transactions)
 	summary += fmt.Sprintf("%9d Tickets purged\n", s.Tickets)
 	summary += fmt.Sprintf("%9d Votes purged\n", s.Votes)
-	summary += fmt.Sprintf("%9d Misses purged", s.Misses)
+	summary += fmt.Sprintf("%9d Misses purged\n", s.Misses)
+	summary += fmt.Sprintf("%9d Swaps purged", s.Swaps)
 	return summary
 }
 
@@ -680,6 +681,7 @@ func (ds DeletionSummarySlice) Reduce() DeletionSummary {
 		s.Tickets += ds[i].Tickets
 		s.Votes += ds[i].Votes
 		s.Misses += ds[i].Misses
+		s.Swaps += ds[i].Swaps
 	}
 	return s
 }
@@ -1610,6 +1612,117 @@ type ProposalChartsData struct {
 	Time []TimeDef `json:"time,omitempty"`
 }
 
+// PoliteiaProposal is the latest version of a Politeia proposal as stored in
+// the politeia_proposals table. Data is the JSON encoding of the proposal
+// record, including its vote status, which is decoded by the politeia package.
+// ID is assigned when the proposal is first stored.
+type PoliteiaProposal struct {
+	ID            int64
+	Token         string
+	RefID         string
+	Version       string
+	Timestamp     int64
+	State         int16
+	Status        int16
+	VoteStatus    int16
+	StatusMessage string
+	Data          []byte
+}
+
+// ProposalVersion is a version of a Politeia proposal, first stored at Time.
+// Proposal is the JSON encoding of the proposal record of the version.
+type ProposalVersion struct {
+	Version  string          `json:"version"`
+	Time     TimeDef         `json:"time"`
+	Proposal json.RawMessage `json:"proposal"`
+}
+
+// ProposalStatusChange is a change of the state, status or vote status of a
+// Politeia proposal, first stored at Time. The values are those of the
+// Politeia API.
+type ProposalStatusChange struct {
+	Time       TimeDef `json:"time"`
+	State      int16   `json:"state"`
+	Status     int16   `json:"status"`
+	VoteStatus int16   `json:"vote_status"`
+	Message    string  `json:"message,omitempty"`
+}
+
+// ProposalVoteTally is the tally of the votes on a Politeia proposal polled
+// from Politeia at Time. TicketVotes is the number of votes recorded in the
+// proposal_votes table from the proposals repository by Time.
+type ProposalVoteTally struct {
+	Token         string  `json:"-"`
+	Time          TimeDef `json:"time"`
+	VoteStatus    int16   `json:"vote_status"`
+	Yes           int64   `json:"yes"`
+	No            int64   `json:"no"`
+	TotalVotes    int64   `json:"total_votes"`
+	EligibleVotes int64   `json:"eligible_votes"`
+	TicketVotes   int64   `json:"ticket_votes"`
+}
+
+// ProposalHistory is the stored history of a Politeia proposal: its versions,
+// its status changes, and the last tally of its votes polled in each hour.
+type ProposalHistory struct {
+	Token         string                  `json:"token"`
+	Versions      []*ProposalVersion      `json:"versions"`
+	StatusChanges []*ProposalStatusChange `json:"status_changes"`
+	Tallies       []*ProposalVoteTally    `json:"tallies"`
+}
+
+// ProposalTicketVote is the vote of a ticket on a Politeia proposal, parsed
+// from the proposals repository at Time. The Name and RefID of the proposal are
+// empty if it was not polled from Politeia.
+type ProposalTicketVote struct {
+	Ticket string  `json:"ticket"`
+	Token  string  `json:"token"`
+	Name   string  `json:"name,omitempty"`
+	RefID  string  `json:"ref_id,omitempty"`
+	Choice string  `json:"choice"`
+	Time   TimeDef `json:"time"`
+}
+
+// ProposalVoter is a ticket that voted on a Politeia proposal, with its stake
+// submission address and the height of its purchase, or -1 if the ticket is
+// not a main chain ticket.
+type ProposalVoter struct {
+	Ticket       string `json:"ticket"`
+	Address      string `json:"address"`
+	Choice       string `json:"choice"`
+	TicketHeight int64  `json:"ticket_height"`
+}
+
+// ProposalVoterAges is the number of votes on a Politeia proposal of the
+// tickets with an age, at the end of the vote, from MinDays to MaxDays
+// (exclusive), or of at least MinDays if MaxDays is zero.
+type ProposalVoterAges struct {
+	MinDays int64 `json:"min_days"`
+	MaxDays int64 `json:"max_days,omitempty"`
+	Votes   int64 `json:"votes"`
+	Yes     int64 `json:"yes"`
+	No      int64 `json:"no"`
+}
+
+// ProposalVoters is the participation in the vote on a Politeia proposal.
+// EligibleTickets, VotesCast and EndHeight are from the proposal polled from
+// Politeia, and are zero if it was not polled. ParsedVotes is the number of
+// voters parsed from the proposals repository, of which Voters is a page.
+// Participation is the percent of the eligible tickets that voted. The voters
+// of unknown age, whose ticket or the end of the vote is unknown, are not in
+// AgeBreakdown.
+type ProposalVoters struct {
+	Token           string               `json:"token"`
+	EligibleTickets int64                `json:"eligible_tickets"`
+	VotesCast       int64                `json:"votes_cast"`
+	ParsedVotes     int64                `json:"parsed_votes"`
+	Participation   float64              `json:"participation"`
+	EndHeight       int64                `json:"end_height"`
+	AgeBreakdown    []*ProposalVoterAges `json:"age_breakdown"`
+	UnknownAgeVotes int64                `json:"unknown_age_votes"`
+	Voters          []*ProposalVoter     `json:"voters"`
+}
+
 // ScriptPubKeyData is part of the result of decodescript(ScriptPubKeyHex)
 type ScriptPubKeyData struct {
 	ReqSigs   uint32   `json:"reqSigs"`
@@ -1787,6 +1900,9 @@ type AddressTx struct {
 	MatchedTxIndex uint32
 	MergedTxnCount uint64 `json:",omitempty"`
 	BlockHeight    uint32
+	// ConflictTx is the hash of a transaction competing with an unconfirmed
+	// transaction to spend one of its inputs (a double spend).
+	ConflictTx string `json:",omitempty"`
 }
 
 // IOID formats an identification string for the transaction input (or output)
@@ -1828,6 +1944,199 @@ type TreasuryTx struct {
 	BlockTime   TimeDef
 }
 
+// AtomicSwap models an atomic swap contract and the transaction input that
+// redeemed or refunded it, as stored in the swaps table.
+type AtomicSwap struct {
+	ContractTx       string  `json:"contract_tx"`
+	ContractVout     uint32  `json:"contract_vout"`
+	SpendTx          string  `json:"spend_tx"`
+	SpendVin         uint32  `json:"spend_vin"`
+	SpendHeight      int64   `json:"spend_height"`
+	SpendTime        TimeDef `json:"spend_time"`
+	ContractAddress  string  `json:"contract_address"`
+	RecipientAddress string  `json:"recipient_address"`
+	RefundAddress    string  `json:"refund_address"`
+	Value            int64   `json:"value"`
+	SecretHash       string  `json:"secret_hash"`
+	Secret           string  `json:"secret,omitempty"`
+	LockTime         int64   `json:"lock_time"`
+	IsRefund         bool    `json:"is_refund"`
+}
+
+// Webhook delivery event types.
+const (
+	// WebhookEventMempool is for a transaction involving a watched address
+	// entering mempool.
+	WebhookEventMempool = "mempool"
+	// WebhookEventConfirmed is for a transaction involving a watched address
+	// being mined in a main chain block.
+	WebhookEventConfirmed = "confirmed"
+	// WebhookEventConfirmations is for a mined transaction reaching the
+	// webhook's required number of confirmations.
+	WebhookEventConfirmations = "confirmations"
+	// WebhookEventReorg is for a block containing a previously confirmed
+	// transaction being orphaned by a chain reorganization.
+	WebhookEventReorg = "reorg"
+)
+
+// Webhook delivery states.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a registered callback URL for events involving a set of watched
+// addresses, as stored in the webhooks and webhook_addresses tables. Secret is
+// the HMAC key used to sign the deliveries.
+type Webhook struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Secret        string   `json:"secret,omitempty"`
+	Addresses     []string `json:"addresses"`
+	Confirmations int32    `json:"confirmations"`
+	Created       TimeDef  `json:"created"`
+}
+
+// WebhookDelivery is a webhook event and its delivery state, as stored in the
+// webhook_deliveries table. BlockHash is empty and BlockHeight is zero for
+// mempool events. URL and Secret are only set for deliveries pending dispatch.
+type WebhookDelivery struct {
+	ID            int64   `json:"id"`
+	WebhookID     string  `json:"webhook_id"`
+	Event         string  `json:"event"`
+	Address       string  `json:"address"`
+	TxHash        string  `json:"txid"`
+	BlockHash     string  `json:"block_hash,omitempty"`
+	BlockHeight   int64   `json:"block_height,omitempty"`
+	Confirmations int32   `json:"confirmations"`
+	Created       TimeDef `json:"created"`
+	Status        string  `json:"status"`
+	Attempts      int32   `json:"attempts"`
+	NextAttempt   TimeDef `json:"next_attempt"`
+	ResponseCode  int32   `json:"response_code,omitempty"`
+	LastError     string  `json:"last_error,omitempty"`
+	URL           string  `json:"-"`
+	Secret        string  `json:"-"`
+}
+
+// APIKey is an API key with its rate limit and monthly quota, as stored in the
+// api_keys table. Key is only set when the key is issued, since only KeyHash,
+// its hex-encoded SHA-256 hash, is stored. A MonthlyQuota of zero means there
+// is no quota. Usage is the key's usage in the current month, by route group.
+type APIKey struct {
+	ID           string         `json:"id"`
+	Key          string         `json:"key,omitempty"`
+	KeyHash      string         `json:"-"`
+	Name         string         `json:"name"`
+	RateLimit    float64        `json:"rate_limit"`
+	Burst        int32          `json:"burst"`
+	MonthlyQuota int64          `json:"monthly_quota"`
+	Created      TimeDef        `json:"created"`
+	Revoked      *TimeDef       `json:"revoked,omitempty"`
+	Usage        []*APIKeyUsage `json:"usage,omitempty"`
+}
+
+// APIKeyUsage is the usage of an API key for a route group in a calendar
+// month, as stored in the api_key_usage table. Limited is the number of
+// requests refused by the key's rate limit or quota.
+type APIKeyUsage struct {
+	KeyID      string    `json:"-"`
+	Month      time.Time `json:"-"`
+	RouteGroup string    `json:"route_group"`
+	Requests   int64     `json:"requests"`
+	Limited    int64     `json:"limited"`
+}
+
+// Transaction broadcast states.
+const (
+	// TxBroadcastSubmitted is for a transaction accepted by dcrd, but not yet
+	// seen in mempool.
+	TxBroadcastSubmitted = "submitted"
+	// TxBroadcastMempool is for a transaction in mempool.
+	TxBroadcastMempool = "mempool"
+	// TxBroadcastMined is for a transaction in a main chain block.
+	TxBroadcastMined = "mined"
+	// TxBroadcastEvicted is for a transaction that left mempool without being
+	// mined, for example when it expired.
+	TxBroadcastEvicted = "evicted"
+	// TxBroadcastDoubleSpent is for a transaction with an input spent by
+	// another transaction in mempool or in a main chain block.
+	TxBroadcastDoubleSpent = "double_spent"
+	// TxBroadcastRejected is for a transaction that failed validation, or was
+	// rejected by dcrd. Rejected transactions are not stored.
+	TxBroadcastRejected = "rejected"
+)
+
+// TxBroadcast is a transaction relayed to dcrd with the broadcast API, and its
+// status, as stored in the tx_broadcasts table. FeeRate is in atoms/kB.
+// BlockHash and BlockHeight are set for a mined transaction, and ConflictTx
+// for a double spent transaction. A Final broadcast is no longer tracked.
+type TxBroadcast struct {
+	TxHash      string
+	TxHex       string
+	Size        int32
+	Fee         int64
+	FeeRate     int64
+	Submitted   TimeDef
+	Updated     TimeDef
+	Status      string
+	BlockHash   string
+	BlockHeight int64
+	ConflictTx  string
+	Final       bool
+}
+
+// Mempool transaction dispositions.
+const (
+	// MempoolDispositionMempool is for a transaction still in mempool.
+	MempoolDispositionMempool = "mempool"
+	// MempoolDispositionMined is for a transaction mined in a block.
+	MempoolDispositionMined = "mined"
+	// MempoolDispositionEvicted is for a transaction that left mempool without
+	// being mined, expiring or being double spent.
+	MempoolDispositionEvicted = "evicted"
+	// MempoolDispositionExpired is for a transaction that left mempool at its
+	// expiry height.
+	MempoolDispositionExpired = "expired"
+	// MempoolDispositionConflicted is for a transaction that left mempool
+	// after another transaction spending one of its inputs was accepted or
+	// mined.
+	MempoolDispositionConflicted = "conflicted"
+)
+
+// MempoolHistoryTx is a transaction observed in mempool, as stored in the
+// mempool_history table. FeeRate is in atoms/kB. Resolved is the time the
+// transaction left mempool, which is the block time for a mined transaction,
+// and is zero for a transaction still in mempool. BlockHash and BlockHeight
+// are set for a mined transaction, and ConflictTx for a conflicted
+// transaction if the competing spend is known.
+type MempoolHistoryTx struct {
+	TxHash      string
+	TxType      int
+	Size        int32
+	Fee         int64
+	FeeRate     int64
+	Expiry      uint32
+	FirstSeen   TimeDef
+	LastSeen    TimeDef
+	Disposition string
+	Resolved    TimeDef
+	BlockHash   string
+	BlockHeight int64
+	ConflictTx  string
+}
+
+// ConfirmationTimeBucket is the number of regular transactions mined with a
+// fee rate of at least MinFeeRate atoms/kB, up to the MinFeeRate of the next
+// bucket, and their median time in seconds from first seen in mempool to
+// being mined.
+type ConfirmationTimeBucket struct {
+	MinFeeRate int64
+	Count      int64
+	MedianTime float64
+}
+
 // TreasuryBalance is the current balance, spent amount, and tx count for the
 // treasury.
 type TreasuryBalance struct {
@@ -1844,6 +2153,43 @@ type TreasuryBalance struct {
 	Immature      int64 `json:"immature"`
 }
 
+// The groupings of a TreasurySpendReport. The periods of a month grouping are
+// labeled like "2021-04", and those of a quarter grouping like "2021-Q2".
+const (
+	TreasuryReportMonth   = "month"
+	TreasuryReportQuarter = "quarter"
+)
+
+// TreasuryPayeeSpend is the amount, in atoms, paid to a payee address by the
+// treasury spends mined in a period. Proposal is the token of the Politeia
+// proposal the address is paid for, if known.
+type TreasuryPayeeSpend struct {
+	Period   string `json:"period"`
+	Address  string `json:"address"`
+	Proposal string `json:"proposal,omitempty"`
+	Amount   int64  `json:"amount"`
+	TSpends  int64  `json:"tspends"`
+}
+
+// TreasuryProposalSpend is the amount, in atoms, paid for a Politeia proposal
+// by the treasury spends mined in a period. The payments to the addresses
+// without a known proposal are totaled with an empty Proposal.
+type TreasuryProposalSpend struct {
+	Period   string `json:"period"`
+	Proposal string `json:"proposal"`
+	Amount   int64  `json:"amount"`
+	Payees   int64  `json:"payees"`
+	TSpends  int64  `json:"tspends"`
+}
+
+// TreasurySpendReport is the amount paid by the treasury spends to each payee
+// address and for each proposal in each period of the grouping.
+type TreasurySpendReport struct {
+	Grouping  string                   `json:"grouping"`
+	Payees    []*TreasuryPayeeSpend    `json:"payees"`
+	Proposals []*TreasuryProposalSpend `json:"proposals"`
+}
+
 // AddressTransactions collects the transactions for an address as AddressTx
 // slices.
 type AddressTransactions struct {
@@ -1920,6 +2266,148 @@ func (balance *AddressBalance) HasStakeInputs() bool {
 	return balance.ToStake > 0
 }
 
+// Tags of the well-known holders in the rich list, which are also the
+// categories of address labels.
+const (
+	AddressTagTreasury = "treasury"
+	AddressTagDevFund  = "devfund"
+	AddressTagExchange = "exchange"
+	AddressTagMixing   = "mixing"
+	AddressTagVSP      = "vsp"
+	AddressTagOther    = "other"
+)
+
+// RichListEntry is an address ranked by its balance of unspent outputs, in
+// atoms. Share is the fraction of the balance of all addresses held by the
+// address. Tag identifies well-known holders, with an optional Label such as
+// the name of an exchange.
+type RichListEntry struct {
+	Rank     int64   `json:"rank"`
+	Address  string  `json:"address"`
+	Balance  int64   `json:"balance"`
+	NumUTXOs int64   `json:"num_utxos"`
+	Share    float64 `json:"share"`
+	Tag      string  `json:"tag,omitempty"`
+	Label    string  `json:"label,omitempty"`
+}
+
+// BalancePercentile is the balance, in atoms, at a percentile of the balances
+// of all addresses.
+type BalancePercentile struct {
+	Percentile float64 `json:"percentile"`
+	Balance    int64   `json:"balance"`
+}
+
+// BalanceBucket is the number of addresses with a balance of at least
+// MinBalance atoms and less than the MinBalance of the next bucket, and the
+// sum of their balances.
+type BalanceBucket struct {
+	MinBalance   int64 `json:"min_balance"`
+	NumAddresses int64 `json:"num_addresses"`
+	Balance      int64 `json:"balance"`
+}
+
+// AddressDistribution describes the distribution of the balances of all
+// addresses with unspent outputs at a main chain height. The TopShares are the
+// fractions of TotalBalance held by the top 10, 100 and 1000 addresses. The
+// decentralized treasury has no address, and its balance is given separately.
+type AddressDistribution struct {
+	Height          int64               `json:"height"`
+	NumAddresses    int64               `json:"num_addresses"`
+	TotalBalance    int64               `json:"total_balance"`
+	Gini            float64             `json:"gini"`
+	Percentiles     []BalancePercentile `json:"percentiles"`
+	Top10Share      float64             `json:"top_10_share"`
+	Top100Share     float64             `json:"top_100_share"`
+	Top1000Share    float64             `json:"top_1000_share"`
+	Buckets         []BalanceBucket     `json:"buckets"`
+	TreasuryBalance int64               `json:"treasury_balance"`
+}
+
+// VSPStats are the ticket statistics of a VSP, identified by the fee addresses
+// of the tickets attributed to it. Name is the label of the fee addresses, or
+// empty for an unknown VSP with a single fee address. Known is true if the fee
+// addresses are labeled as VSP addresses. Live excludes the Immature tickets.
+// MissRate is the fraction of the voted and missed tickets that missed, and
+// AvgVoteLatency is the average number of blocks from ticket maturity to vote.
+type VSPStats struct {
+	Name           string   `json:"name"`
+	FeeAddresses   []string `json:"fee_addresses"`
+	Known          bool     `json:"known"`
+	Immature       int64    `json:"immature"`
+	Live           int64    `json:"live"`
+	Voted          int64    `json:"voted"`
+	Missed         int64    `json:"missed"`
+	Expired        int64    `json:"expired"`
+	Revoked        int64    `json:"revoked"`
+	MissRate       float64  `json:"miss_rate"`
+	AvgVoteLatency float64  `json:"avg_vote_latency"`
+}
+
+// VSPAnalytics are the statistics of the VSPs at a main chain height, sorted
+// by the number of tickets.
+type VSPAnalytics struct {
+	Height int64       `json:"height"`
+	VSPs   []*VSPStats `json:"vsps"`
+}
+
+// The causes of missed votes. A ticket called to vote in a block votes on the
+// block's parent.
+const (
+	// MissCauseDisapproved is for a miss in a block that disapproved its
+	// parent, the block the ticket was called to vote on.
+	MissCauseDisapproved = "disapproved"
+	// MissCauseLate is for a ticket whose vote on the parent block was first
+	// seen in mempool after the time of the block.
+	MissCauseLate = "late"
+	// MissCauseOmitted is for a ticket whose vote on the parent block was seen
+	// in mempool before the time of the block, but was left out by the miner.
+	MissCauseOmitted = "omitted"
+	// MissCauseVSPOffline is for a ticket of a VSP whose vote was not seen.
+	MissCauseVSPOffline = "vsp_offline"
+	// MissCauseNotSeen is for a ticket that is not of a known VSP whose vote
+	// was not seen.
+	MissCauseNotSeen = "not_seen"
+	// MissCauseUnknown is for a miss in a block mined while the mempool was
+	// not watched.
+	MissCauseUnknown = "unknown"
+)
+
+// MissedVote is a ticket called to vote in a block that did not vote, and the
+// cause of the miss. VoteSeen is the UNIX time the ticket's vote on the parent
+// block was first seen in mempool, or zero. VSPFeeAddress is the fee address
+// of the ticket's VSP, for a ticket attributed to a VSP.
+type MissedVote struct {
+	TicketHash    string `json:"ticket_hash"`
+	BlockHash     string `json:"block_hash"`
+	BlockHeight   int64  `json:"block_height"`
+	Cause         string `json:"cause"`
+	VoteSeen      int64  `json:"vote_seen,omitempty"`
+	VSPFeeAddress string `json:"vsp_fee_address,omitempty"`
+}
+
+// MissReport is the missed votes of a block or of the tickets of an address,
+// with the number of misses of each cause.
+type MissReport struct {
+	Misses []*MissedVote    `json:"misses"`
+	Causes map[string]int64 `json:"causes"`
+}
+
+// NewMissReport creates the MissReport of the missed votes.
+func NewMissReport(misses []*MissedVote) *MissReport {
+	report := &MissReport{
+		Misses: misses,
+		Causes: make(map[string]int64),
+	}
+	if report.Misses == nil {
+		report.Misses = []*MissedVote{}
+	}
+	for _, m := range misses {
+		report.Causes[m.Cause]++
+	}
+	return report
+}
+
 // ReduceAddressHistory generates a template AddressInfo from a slice of
 // AddressRow. All fields except NumUnconfirmed and Transactions are set
 // completely. Transactions is partially set, with each transaction having only
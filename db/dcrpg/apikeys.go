@@ -0,0 +1,215 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// InsertAPIKey inserts an API key.
+func InsertAPIKey(ctx context.Context, db *sql.DB, key *dbtypes.APIKey) error {
+	_, err := db.ExecContext(ctx, internal.InsertAPIKeyRow, key.ID, key.KeyHash,
+		key.Name, key.RateLimit, key.Burst, key.MonthlyQuota, key.Created)
+	return err
+}
+
+// scanAPIKey scans a row of the api_keys table selected by the SelectAPIKeys
+// and SelectAPIKeyByHash queries.
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*dbtypes.APIKey, error) {
+	key := new(dbtypes.APIKey)
+	var revoked sql.NullTime
+	err := row.Scan(&key.ID, &key.Name, &key.RateLimit, &key.Burst,
+		&key.MonthlyQuota, &key.Created, &revoked)
+	if err != nil {
+		return nil, err
+	}
+	if revoked.Valid {
+		key.Revoked = &dbtypes.TimeDef{T: revoked.Time}
+	}
+	return key, nil
+}
+
+// RetrieveAPIKeyByHash retrieves the API key with the given hash.
+// sql.ErrNoRows is returned if there is no such key.
+func RetrieveAPIKeyByHash(ctx context.Context, db *sql.DB, keyHash string) (*dbtypes.APIKey, error) {
+	key, err := scanAPIKey(db.QueryRowContext(ctx, internal.SelectAPIKeyByHash, keyHash))
+	if err != nil {
+		return nil, err
+	}
+	key.KeyHash = keyHash
+	return key, nil
+}
+
+// RetrieveAPIKeys retrieves all API keys, including revoked keys, in the order
+// they were issued.
+func RetrieveAPIKeys(ctx context.Context, db *sql.DB) ([]*dbtypes.APIKey, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var keys []*dbtypes.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey sets the revocation time of the API key with the given ID. The
+// returned bool indicates if the key existed and was not already revoked.
+func RevokeAPIKey(ctx context.Context, db *sql.DB, id string, revoked time.Time) (bool, error) {
+	res, err := db.ExecContext(ctx, internal.RevokeAPIKey, id, revoked)
+	if err != nil {
+		return false, err
+	}
+	N, err := res.RowsAffected()
+	return N > 0, err
+}
+
+// monthStart returns the start of the UTC calendar month of t, the value of the
+// month column of the api_key_usage table.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// UpsertAPIKeyUsage adds the counts to the usage counters of the API keys.
+func UpsertAPIKeyUsage(ctx context.Context, db *sql.DB, usage []*dbtypes.APIKeyUsage) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, internal.UpsertAPIKeyUsage)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, u := range usage {
+		_, err = stmt.ExecContext(ctx, u.KeyID, monthStart(u.Month),
+			u.RouteGroup, u.Requests, u.Limited)
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// RetrieveAPIKeyUsage retrieves the usage counters of all API keys for the
+// month of the given time.
+func RetrieveAPIKeyUsage(ctx context.Context, db *sql.DB, month time.Time) ([]*dbtypes.APIKeyUsage, error) {
+	month = monthStart(month)
+	rows, err := db.QueryContext(ctx, internal.SelectAPIKeyUsageForMonth, month)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var usage []*dbtypes.APIKeyUsage
+	for rows.Next() {
+		u := &dbtypes.APIKeyUsage{Month: month}
+		if err = rows.Scan(&u.KeyID, &u.RouteGroup, &u.Requests, &u.Limited); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// RetrieveAPIKeyMonthRequests retrieves the number of requests made with the
+// API key in the month of the given time, across all route groups.
+func RetrieveAPIKeyMonthRequests(ctx context.Context, db *sql.DB, id string, month time.Time) (int64, error) {
+	var N int64
+	err := db.QueryRowContext(ctx, internal.SelectAPIKeyMonthRequests, id,
+		monthStart(month)).Scan(&N)
+	return N, err
+}
+
+// CreateAPIKey stores a new API key. The key's ID, hash and creation time must
+// be set by the caller.
+func (pgb *ChainDB) CreateAPIKey(key *dbtypes.APIKey) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := InsertAPIKey(ctx, pgb.db, key)
+	return pgb.replaceCancelError(err)
+}
+
+// APIKeyByHash retrieves the API key with the given hash. A nil key and nil
+// error are returned if there is no such key.
+func (pgb *ChainDB) APIKeyByHash(keyHash string) (*dbtypes.APIKey, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	key, err := RetrieveAPIKeyByHash(ctx, pgb.db, keyHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, pgb.replaceCancelError(err)
+}
+
+// APIKeys retrieves all API keys, with their usage in the month of the given
+// time.
+func (pgb *ChainDB) APIKeys(month time.Time) ([]*dbtypes.APIKey, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	keys, err := RetrieveAPIKeys(ctx, pgb.db)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	usage, err := RetrieveAPIKeyUsage(ctx, pgb.db, month)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	keyMap := make(map[string]*dbtypes.APIKey, len(keys))
+	for _, key := range keys {
+		keyMap[key.ID] = key
+	}
+	for _, u := range usage {
+		if key := keyMap[u.KeyID]; key != nil {
+			key.Usage = append(key.Usage, u)
+		}
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes the API key with the given ID. The returned bool
+// indicates if the key existed and was not already revoked.
+func (pgb *ChainDB) RevokeAPIKey(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	found, err := RevokeAPIKey(ctx, pgb.db, id, time.Now())
+	return found, pgb.replaceCancelError(err)
+}
+
+// AddAPIKeyUsage adds to the usage counters of the API keys.
+func (pgb *ChainDB) AddAPIKeyUsage(usage []*dbtypes.APIKeyUsage) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpsertAPIKeyUsage(ctx, pgb.db, usage)
+	return pgb.replaceCancelError(err)
+}
+
+// APIKeyMonthRequests retrieves the number of requests made with the API key
+// in the month of the given time.
+func (pgb *ChainDB) APIKeyMonthRequests(id string, month time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	N, err := RetrieveAPIKeyMonthRequests(ctx, pgb.db, id, month)
+	return N, pgb.replaceCancelError(err)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// UpsertTxBroadcast stores a transaction broadcast, or updates its status if
+// it is already stored.
+func UpsertTxBroadcast(ctx context.Context, db *sql.DB, b *dbtypes.TxBroadcast) error {
+	_, err := db.ExecContext(ctx, internal.UpsertTxBroadcast, b.TxHash, b.TxHex,
+		b.Size, b.Fee, b.FeeRate, b.Submitted, b.Updated, b.Status,
+		sql.NullString{String: b.BlockHash, Valid: b.BlockHash != ""},
+		sql.NullInt64{Int64: b.BlockHeight, Valid: b.BlockHash != ""},
+		sql.NullString{String: b.ConflictTx, Valid: b.ConflictTx != ""}, b.Final)
+	return err
+}
+
+// scanTxBroadcast scans a row of the tx_broadcasts table selected by the
+// SelectTxBroadcast and SelectTrackedTxBroadcasts queries.
+func scanTxBroadcast(row interface{ Scan(...interface{}) error }) (*dbtypes.TxBroadcast, error) {
+	b := new(dbtypes.TxBroadcast)
+	var blockHash, conflictTx sql.NullString
+	var blockHeight sql.NullInt64
+	err := row.Scan(&b.TxHash, &b.TxHex, &b.Size, &b.Fee, &b.FeeRate,
+		&b.Submitted, &b.Updated, &b.Status, &blockHash, &blockHeight,
+		&conflictTx, &b.Final)
+	if err != nil {
+		return nil, err
+	}
+	b.BlockHash = blockHash.String
+	b.BlockHeight = blockHeight.Int64
+	b.ConflictTx = conflictTx.String
+	return b, nil
+}
+
+// RetrieveTxBroadcast retrieves the broadcast of the transaction with the
+// given hash. sql.ErrNoRows is returned if there is no such broadcast.
+func RetrieveTxBroadcast(ctx context.Context, db *sql.DB, txHash string) (*dbtypes.TxBroadcast, error) {
+	return scanTxBroadcast(db.QueryRowContext(ctx, internal.SelectTxBroadcast, txHash))
+}
+
+// RetrieveTrackedTxBroadcasts retrieves the broadcasts that are not final,
+// submitted after the given time, in the order they were submitted.
+func RetrieveTrackedTxBroadcasts(ctx context.Context, db *sql.DB, since time.Time) ([]*dbtypes.TxBroadcast, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTrackedTxBroadcasts, since)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var broadcasts []*dbtypes.TxBroadcast
+	for rows.Next() {
+		b, err := scanTxBroadcast(rows)
+		if err != nil {
+			return nil, err
+		}
+		broadcasts = append(broadcasts, b)
+	}
+	return broadcasts, rows.Err()
+}
+
+// RetrieveTxOutSpendStatus retrieves the value of the main chain transaction
+// output, and the hash of the main chain transaction spending it, which is
+// empty if the output is unspent. sql.ErrNoRows is returned if there is no
+// such output.
+func RetrieveTxOutSpendStatus(ctx context.Context, db *sql.DB, txHash string, index uint32) (int64, string, error) {
+	var value int64
+	var spendTx sql.NullString
+	err := db.QueryRowContext(ctx, internal.SelectTxOutSpendStatus, txHash,
+		index).Scan(&value, &spendTx)
+	return value, spendTx.String, err
+}
+
+// StoreTxBroadcast stores a transaction broadcast, or updates its status.
+func (pgb *ChainDB) StoreTxBroadcast(b *dbtypes.TxBroadcast) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpsertTxBroadcast(ctx, pgb.db, b)
+	return pgb.replaceCancelError(err)
+}
+
+// TxBroadcast retrieves the broadcast of the transaction with the given hash.
+// A nil broadcast and nil error are returned if there is no such broadcast.
+func (pgb *ChainDB) TxBroadcast(txHash string) (*dbtypes.TxBroadcast, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	b, err := RetrieveTxBroadcast(ctx, pgb.db, txHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return b, pgb.replaceCancelError(err)
+}
+
+// TrackedTxBroadcasts retrieves the broadcasts that are not final, submitted
+// after the given time.
+func (pgb *ChainDB) TrackedTxBroadcasts(since time.Time) ([]*dbtypes.TxBroadcast, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	broadcasts, err := RetrieveTrackedTxBroadcasts(ctx, pgb.db, since)
+	return broadcasts, pgb.replaceCancelError(err)
+}
+
+// TxOutSpendStatus retrieves the value in atoms of a main chain transaction
+// output, and the hash of the main chain transaction spending it, which is
+// empty if the output is unspent. The returned bool indicates if the output
+// was found.
+func (pgb *ChainDB) TxOutSpendStatus(txHash string, index uint32) (int64, string, bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	value, spendTx, err := RetrieveTxOutSpendStatus(ctx, pgb.db, txHash, index)
+	if err == sql.ErrNoRows {
+		return 0, "", false, nil
+	}
+	return value, spendTx, err == nil, pgb.replaceCancelError(err)
+}
@@ -168,6 +168,12 @@ func (p *ChainMonitor) ReorgHandler(reorg *txhelpers.ReorgData) (err error) {
 			newHash))
 	}
 
+	// Notify webhooks of the events in the orphaned blocks and the blocks of
+	// the new main chain.
+	if err0 := p.db.webhooksReorg(reorg); err0 != nil {
+		err = appendError(fmt.Errorf("webhooksReorg failed: %v", err0))
+	}
+
 	p.db.InReorg = false
 	// Freshen project fund balance and clear ALL address cache data.
 	_ = p.db.FreshenAddressCaches(true, nil) // async update
@@ -354,6 +354,16 @@ func DeindexProposalVotesTableOnProposalsID(db *sql.DB) (err error) {
 	return
 }
 
+func IndexProposalVotesTableOnTicket(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexProposalVotesTableOnTicket)
+	return
+}
+
+func DeindexProposalVotesTableOnTicket(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexProposalVotesTableOnTicket)
+	return
+}
+
 // IndexTreasuryTableOnTxHash creates the index for the treasury table over
 // tx_hash.
 func IndexTreasuryTableOnTxHash(db *sql.DB) (err error) {
@@ -382,6 +392,114 @@ func DeindexTreasuryTableOnHeight(db *sql.DB) (err error) {
 	return
 }
 
+// swaps table indexes
+
+// IndexSwapsTableOnSpendTx creates the unique index for the swaps table over
+// the spending tx hash, input index and block hash.
+func IndexSwapsTableOnSpendTx(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexSwapsOnSpendTx)
+	return
+}
+
+// DeindexSwapsTableOnSpendTx drops the index for the swaps table over the
+// spending tx hash, input index and block hash.
+func DeindexSwapsTableOnSpendTx(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexSwapsOnSpendTx)
+	return
+}
+
+// IndexSwapsTableOnSecretHash creates the index for the swaps table over
+// secret hash.
+func IndexSwapsTableOnSecretHash(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexSwapsOnSecretHash)
+	return
+}
+
+// DeindexSwapsTableOnSecretHash drops the index for the swaps table over
+// secret hash.
+func DeindexSwapsTableOnSecretHash(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexSwapsOnSecretHash)
+	return
+}
+
+// IndexSwapsTableOnSpendTime creates the index for the swaps table over spend
+// time.
+func IndexSwapsTableOnSpendTime(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexSwapsOnSpendTime)
+	return
+}
+
+// DeindexSwapsTableOnSpendTime drops the index for the swaps table over spend
+// time.
+func DeindexSwapsTableOnSpendTime(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexSwapsOnSpendTime)
+	return
+}
+
+// IndexSwapsTableOnAddresses creates the indexes for the swaps table over the
+// recipient and refund addresses.
+func IndexSwapsTableOnAddresses(db *sql.DB) (err error) {
+	if _, err = db.Exec(internal.IndexSwapsOnRecipient); err != nil {
+		return
+	}
+	_, err = db.Exec(internal.IndexSwapsOnRefund)
+	return
+}
+
+// DeindexSwapsTableOnAddresses drops the indexes for the swaps table over the
+// recipient and refund addresses.
+func DeindexSwapsTableOnAddresses(db *sql.DB) (err error) {
+	if _, err = db.Exec(internal.DeindexSwapsOnRecipient); err != nil {
+		return
+	}
+	_, err = db.Exec(internal.DeindexSwapsOnRefund)
+	return
+}
+
+// webhook_deliveries table indexes
+
+// IndexWebhookDeliveriesTable creates the indexes for the webhook_deliveries
+// table on next attempt time of pending deliveries, block height of confirmed
+// events, and webhook ID.
+func IndexWebhookDeliveriesTable(db *sql.DB) (err error) {
+	if _, err = db.Exec(internal.IndexWebhookDeliveriesOnPending); err != nil {
+		return
+	}
+	if _, err = db.Exec(internal.IndexWebhookDeliveriesOnBlockHeight); err != nil {
+		return
+	}
+	_, err = db.Exec(internal.IndexWebhookDeliveriesOnWebhookID)
+	return
+}
+
+// DeindexWebhookDeliveriesTable drops the indexes for the webhook_deliveries
+// table.
+func DeindexWebhookDeliveriesTable(db *sql.DB) (err error) {
+	if _, err = db.Exec(internal.DeindexWebhookDeliveriesOnPending); err != nil {
+		return
+	}
+	if _, err = db.Exec(internal.DeindexWebhookDeliveriesOnBlockHeight); err != nil {
+		return
+	}
+	_, err = db.Exec(internal.DeindexWebhookDeliveriesOnWebhookID)
+	return
+}
+
+// mempool_history table indexes
+
+// IndexMempoolHistoryTable creates the index for the mempool_history table on
+// disposition and first seen time.
+func IndexMempoolHistoryTable(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.IndexMempoolHistoryOnDisposition)
+	return
+}
+
+// DeindexMempoolHistoryTable drops the index for the mempool_history table.
+func DeindexMempoolHistoryTable(db *sql.DB) (err error) {
+	_, err = db.Exec(internal.DeindexMempoolHistoryOnDisposition)
+	return
+}
+
 // Delete duplicates
 
 func (pgb *ChainDB) DeleteDuplicateVins() (int64, error) {
@@ -518,6 +636,7 @@ func (pgb *ChainDB) DeindexAll() error {
 
 		// proposal votes table
 		{DeindexProposalVotesTableOnProposalsID},
+		{DeindexProposalVotesTableOnTicket},
 
 		// stats table
 		{DeindexStatsTableOnHeight},
@@ -525,6 +644,18 @@ func (pgb *ChainDB) DeindexAll() error {
 		// treasury table
 		{DeindexTreasuryTableOnTxHash},
 		{DeindexTreasuryTableOnHeight},
+
+		// swaps table
+		{DeindexSwapsTableOnSpendTx},
+		{DeindexSwapsTableOnSecretHash},
+		{DeindexSwapsTableOnSpendTime},
+		{DeindexSwapsTableOnAddresses},
+
+		// webhook_deliveries table
+		{DeindexWebhookDeliveriesTable},
+
+		// mempool_history table
+		{DeindexMempoolHistoryTable},
 	}
 
 	var err error
@@ -597,6 +728,7 @@ func (pgb *ChainDB) IndexAll(barLoad chan *dbtypes.ProgressBarLoad) error {
 
 		// Proposals votes table
 		{Msg: "Proposals votes table on Proposals ID", IndexFunc: IndexProposalVotesTableOnProposalsID},
+		{Msg: "Proposals votes table on ticket", IndexFunc: IndexProposalVotesTableOnTicket},
 
 		// stats table
 		{Msg: "stats table on height", IndexFunc: IndexStatsTableOnHeight},
@@ -604,6 +736,18 @@ func (pgb *ChainDB) IndexAll(barLoad chan *dbtypes.ProgressBarLoad) error {
 		// treasury table
 		{Msg: "treasury on tx hash", IndexFunc: IndexTreasuryTableOnTxHash},
 		{Msg: "treasury on block height", IndexFunc: IndexTreasuryTableOnHeight},
+
+		// swaps table
+		{Msg: "swaps on spending tx", IndexFunc: IndexSwapsTableOnSpendTx},
+		{Msg: "swaps on secret hash", IndexFunc: IndexSwapsTableOnSecretHash},
+		{Msg: "swaps on spend time", IndexFunc: IndexSwapsTableOnSpendTime},
+		{Msg: "swaps on addresses", IndexFunc: IndexSwapsTableOnAddresses},
+
+		// webhook_deliveries table
+		{Msg: "webhook deliveries", IndexFunc: IndexWebhookDeliveriesTable},
+
+		// mempool_history table
+		{Msg: "mempool history", IndexFunc: IndexMempoolHistoryTable},
 	}
 
 	for _, val := range allIndexes {
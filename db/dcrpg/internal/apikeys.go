@@ -0,0 +1,72 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "api_keys" and "api_key_usage"
+// tables.
+const (
+	// CreateAPIKeysTable creates the api_keys table. The id is a random string
+	// that identifies the key to the admin, while the key itself is only
+	// stored as the hex-encoded SHA-256 hash in key_hash. rate_limit is the
+	// sustained request rate in requests per second, burst is the size of the
+	// token bucket, and monthly_quota is the maximum number of requests per
+	// calendar month, with zero meaning no quota.
+	CreateAPIKeysTable = `CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		key_hash TEXT NOT NULL UNIQUE,
+		name TEXT NOT NULL,
+		rate_limit FLOAT8 NOT NULL,
+		burst INT4 NOT NULL,
+		monthly_quota INT8 NOT NULL,
+		created TIMESTAMPTZ NOT NULL,
+		revoked TIMESTAMPTZ
+	);`
+
+	// CreateAPIKeyUsageTable creates the api_key_usage table of the usage
+	// counters of each key, per calendar month and route group. limited is the
+	// number of requests refused by the key's rate limit or quota.
+	CreateAPIKeyUsageTable = `CREATE TABLE IF NOT EXISTS api_key_usage (
+		key_id TEXT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+		month DATE NOT NULL,
+		route_group TEXT NOT NULL,
+		requests INT8 NOT NULL DEFAULT 0,
+		limited INT8 NOT NULL DEFAULT 0,
+		PRIMARY KEY (key_id, month, route_group)
+	);`
+
+	InsertAPIKeyRow = `INSERT INTO api_keys (id, key_hash, name, rate_limit,
+		burst, monthly_quota, created)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	SelectAPIKeyByHash = `SELECT id, name, rate_limit, burst, monthly_quota,
+			created, revoked
+		FROM api_keys
+		WHERE key_hash = $1;`
+
+	SelectAPIKeys = `SELECT id, name, rate_limit, burst, monthly_quota,
+			created, revoked
+		FROM api_keys
+		ORDER BY created;`
+
+	RevokeAPIKey = `UPDATE api_keys SET revoked = $2
+		WHERE id = $1 AND revoked IS NULL;`
+
+	// UpsertAPIKeyUsage adds to the usage counters of a key for the month and
+	// route group.
+	UpsertAPIKeyUsage = `INSERT INTO api_key_usage (key_id, month, route_group,
+			requests, limited)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (key_id, month, route_group) DO UPDATE
+		SET requests = api_key_usage.requests + $4,
+			limited = api_key_usage.limited + $5;`
+
+	SelectAPIKeyUsageForMonth = `SELECT key_id, route_group, requests, limited
+		FROM api_key_usage
+		WHERE month = $1
+		ORDER BY key_id, route_group;`
+
+	SelectAPIKeyMonthRequests = `SELECT COALESCE(SUM(requests), 0)
+		FROM api_key_usage
+		WHERE key_id = $1 AND month = $2;`
+)
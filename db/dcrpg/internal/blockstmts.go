@@ -313,6 +313,16 @@ const (
 		WHERE time >= $1
 		ORDER BY time
 		LIMIT 1;`
+
+	SelectHeightByTime = `SELECT height
+		FROM blocks
+		WHERE time >= $1 AND is_mainchain
+		ORDER BY time
+		LIMIT 1;`
+
+	SelectBlockTimesByHeights = `SELECT height, time
+		FROM blocks
+		WHERE height = ANY($1) AND is_mainchain;`
 )
 
 func BlockInsertStatement(checked bool) string {
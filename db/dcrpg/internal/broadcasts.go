@@ -0,0 +1,61 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "tx_broadcasts" table.
+const (
+	// CreateTxBroadcastsTable creates the tx_broadcasts table of the
+	// transactions relayed to dcrd with the broadcast API, and their status.
+	// fee_rate is in atoms/kB. conflict_tx_hash is the transaction spending an
+	// input of a double spent transaction. A final broadcast is no longer
+	// tracked.
+	CreateTxBroadcastsTable = `CREATE TABLE IF NOT EXISTS tx_broadcasts (
+		tx_hash TEXT PRIMARY KEY,
+		tx_hex TEXT NOT NULL,
+		size INT4 NOT NULL,
+		fee INT8 NOT NULL,
+		fee_rate INT8 NOT NULL,
+		submitted TIMESTAMPTZ NOT NULL,
+		updated TIMESTAMPTZ NOT NULL,
+		status TEXT NOT NULL,
+		block_hash TEXT,
+		block_height INT8,
+		conflict_tx_hash TEXT,
+		final BOOLEAN NOT NULL DEFAULT FALSE
+	);`
+
+	// UpsertTxBroadcast inserts a new broadcast, or updates the status of a
+	// broadcast that is submitted again. The submission time is unchanged.
+	UpsertTxBroadcast = `INSERT INTO tx_broadcasts (tx_hash, tx_hex, size, fee,
+			fee_rate, submitted, updated, status, block_hash, block_height,
+			conflict_tx_hash, final)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (tx_hash) DO UPDATE
+		SET updated = $7, status = $8, block_hash = $9, block_height = $10,
+			conflict_tx_hash = $11, final = $12;`
+
+	selectTxBroadcast = `SELECT tx_hash, tx_hex, size, fee, fee_rate,
+			submitted, updated, status, block_hash, block_height,
+			conflict_tx_hash, final
+		FROM tx_broadcasts `
+
+	SelectTxBroadcast = selectTxBroadcast + `WHERE tx_hash = $1;`
+
+	// SelectTrackedTxBroadcasts selects the broadcasts that are not final,
+	// submitted after the given time.
+	SelectTrackedTxBroadcasts = selectTxBroadcast +
+		`WHERE NOT final AND submitted > $1
+		ORDER BY submitted;`
+
+	// SelectTxOutSpendStatus selects the value of a main chain transaction
+	// output, and the hash of the main chain transaction spending it, if any.
+	SelectTxOutSpendStatus = `SELECT vouts.value, spend_tx.tx_hash
+		FROM vouts
+		JOIN transactions ON transactions.tx_hash = vouts.tx_hash
+			AND transactions.tree = vouts.tx_tree
+		LEFT JOIN transactions AS spend_tx ON spend_tx.id = vouts.spend_tx_row_id
+		WHERE vouts.tx_hash = $1 AND vouts.tx_index = $2
+			AND transactions.is_mainchain AND transactions.is_valid
+		LIMIT 1;`
+)
@@ -66,6 +66,7 @@ const (
 	// proposal votes table
 
 	IndexOfProposalVotesTableOnProposalsID = "uix_proposal_votes"
+	IndexOfProposalVotesTableOnTicket      = "idx_proposal_votes_ticket"
 
 	// stats table
 
@@ -75,6 +76,24 @@ const (
 
 	IndexOfTreasuryTableOnTxHash = "uix_treasury_tx_hash"
 	IndexOfTreasuryTableOnHeight = "idx_treasury_height"
+
+	// swaps table
+
+	IndexOfSwapsTableOnSpendTx    = "uix_swaps_spend_tx"
+	IndexOfSwapsTableOnSecretHash = "idx_swaps_secret_hash"
+	IndexOfSwapsTableOnSpendTime  = "idx_swaps_spend_time"
+	IndexOfSwapsTableOnRecipient  = "idx_swaps_recipient"
+	IndexOfSwapsTableOnRefund     = "idx_swaps_refund"
+
+	// webhook_deliveries table
+
+	IndexOfWebhookDeliveriesTableOnPending     = "idx_webhook_deliveries_pending"
+	IndexOfWebhookDeliveriesTableOnBlockHeight = "idx_webhook_deliveries_block_height"
+	IndexOfWebhookDeliveriesTableOnWebhookID   = "idx_webhook_deliveries_webhook_id"
+
+	// mempool_history table
+
+	IndexOfMempoolHistoryTableOnDisposition = "idx_mempool_history_disposition"
 )
 
 // AddressesIndexNames are the names of the indexes on the addresses table.
@@ -84,35 +103,45 @@ var AddressesIndexNames = []string{IndexOfAddressTableOnAddress,
 
 // IndexDescriptions relate table index names to descriptions of the indexes.
 var IndexDescriptions = map[string]string{
-	IndexOfBlocksTableOnHash:               "blocks on hash",
-	IndexOfBlocksTableOnHeight:             "blocks on height",
-	IndexOfTransactionsTableOnHashes:       "transactions on block hash and transaction hash",
-	IndexOfTransactionsTableOnBlockInd:     "transactions on block hash, block index, and tx tree",
-	IndexOfTransactionsTableOnBlockHeight:  "transactions on block height",
-	IndexOfVinsTableOnVin:                  "vins on transaction hash and index",
-	IndexOfVinsTableOnPrevOut:              "vins on previous outpoint",
-	IndexOfVoutsTableOnTxHashInd:           "vouts on transaction hash and index",
-	IndexOfVoutsTableOnSpendTxID:           "vouts on spend_tx_row_id",
-	IndexOfAddressTableOnAddress:           "addresses table on address", // TODO: remove if it is redundant with IndexOfAddressTableOnVoutID
-	IndexOfAddressTableOnVoutID:            "addresses table on vout row id, address, and is_funding",
-	IndexOfAddressTableOnBlockTime:         "addresses table on block time",
-	IndexOfAddressTableOnTx:                "addresses table on transaction hash",
-	IndexOfAddressTableOnMatchingTx:        "addresses table on matching tx hash",
-	IndexOfTicketsTableOnHashes:            "tickets table on block hash and transaction hash",
-	IndexOfTicketsTableOnTxRowID:           "tickets table on transactions table row ID",
-	IndexOfTicketsTableOnPoolStatus:        "tickets table on pool status",
-	IndexOfVotesTableOnHashes:              "votes table on block hash and transaction hash",
-	IndexOfVotesTableOnBlockHash:           "votes table on block hash",
-	IndexOfVotesTableOnCandBlock:           "votes table on candidate block",
-	IndexOfVotesTableOnVersion:             "votes table on vote version",
-	IndexOfVotesTableOnHeight:              "votes table on height",
-	IndexOfVotesTableOnBlockTime:           "votes table on block time",
-	IndexOfMissesTableOnHashes:             "misses on ticket hash and block hash",
-	IndexOfAgendasTableOnName:              "agendas on agenda name",
-	IndexOfAgendaVotesTableOnRowIDs:        "agenda_votes on votes table row ID and agendas table row ID",
-	IndexOfProposalsTableOnToken:           "proposals on token and time",
-	IndexOfProposalVotesTableOnProposalsID: "proposal_votes on proposals row ID",
-	IndexOfHeightOnStatsTable:              "stats table on height",
-	IndexOfTreasuryTableOnTxHash:           "treasury table on tx hash",
-	IndexOfTreasuryTableOnHeight:           "treasury table on block height",
+	IndexOfBlocksTableOnHash:                   "blocks on hash",
+	IndexOfBlocksTableOnHeight:                 "blocks on height",
+	IndexOfTransactionsTableOnHashes:           "transactions on block hash and transaction hash",
+	IndexOfTransactionsTableOnBlockInd:         "transactions on block hash, block index, and tx tree",
+	IndexOfTransactionsTableOnBlockHeight:      "transactions on block height",
+	IndexOfVinsTableOnVin:                      "vins on transaction hash and index",
+	IndexOfVinsTableOnPrevOut:                  "vins on previous outpoint",
+	IndexOfVoutsTableOnTxHashInd:               "vouts on transaction hash and index",
+	IndexOfVoutsTableOnSpendTxID:               "vouts on spend_tx_row_id",
+	IndexOfAddressTableOnAddress:               "addresses table on address", // TODO: remove if it is redundant with IndexOfAddressTableOnVoutID
+	IndexOfAddressTableOnVoutID:                "addresses table on vout row id, address, and is_funding",
+	IndexOfAddressTableOnBlockTime:             "addresses table on block time",
+	IndexOfAddressTableOnTx:                    "addresses table on transaction hash",
+	IndexOfAddressTableOnMatchingTx:            "addresses table on matching tx hash",
+	IndexOfTicketsTableOnHashes:                "tickets table on block hash and transaction hash",
+	IndexOfTicketsTableOnTxRowID:               "tickets table on transactions table row ID",
+	IndexOfTicketsTableOnPoolStatus:            "tickets table on pool status",
+	IndexOfVotesTableOnHashes:                  "votes table on block hash and transaction hash",
+	IndexOfVotesTableOnBlockHash:               "votes table on block hash",
+	IndexOfVotesTableOnCandBlock:               "votes table on candidate block",
+	IndexOfVotesTableOnVersion:                 "votes table on vote version",
+	IndexOfVotesTableOnHeight:                  "votes table on height",
+	IndexOfVotesTableOnBlockTime:               "votes table on block time",
+	IndexOfMissesTableOnHashes:                 "misses on ticket hash and block hash",
+	IndexOfAgendasTableOnName:                  "agendas on agenda name",
+	IndexOfAgendaVotesTableOnRowIDs:            "agenda_votes on votes table row ID and agendas table row ID",
+	IndexOfProposalsTableOnToken:               "proposals on token and time",
+	IndexOfProposalVotesTableOnProposalsID:     "proposal_votes on proposals row ID",
+	IndexOfProposalVotesTableOnTicket:          "proposal_votes on ticket hash",
+	IndexOfHeightOnStatsTable:                  "stats table on height",
+	IndexOfTreasuryTableOnTxHash:               "treasury table on tx hash",
+	IndexOfTreasuryTableOnHeight:               "treasury table on block height",
+	IndexOfSwapsTableOnSpendTx:                 "swaps table on spending tx hash and input index",
+	IndexOfSwapsTableOnSecretHash:              "swaps table on secret hash",
+	IndexOfSwapsTableOnSpendTime:               "swaps table on spend time",
+	IndexOfSwapsTableOnRecipient:               "swaps table on recipient address",
+	IndexOfSwapsTableOnRefund:                  "swaps table on refund address",
+	IndexOfWebhookDeliveriesTableOnPending:     "webhook_deliveries table on next attempt of pending deliveries",
+	IndexOfWebhookDeliveriesTableOnBlockHeight: "webhook_deliveries table on block height of confirmed events",
+	IndexOfWebhookDeliveriesTableOnWebhookID:   "webhook_deliveries table on webhook ID",
+	IndexOfMempoolHistoryTableOnDisposition:    "mempool_history table on disposition and first seen time",
 }
@@ -0,0 +1,36 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "address_labels" table.
+const (
+	// CreateAddressLabelsTable creates the address_labels table of the labels
+	// of well-known addresses. The source is either "file", for labels loaded
+	// from the labels file, or "api", for labels set through the admin API.
+	CreateAddressLabelsTable = `CREATE TABLE IF NOT EXISTS address_labels (
+		address TEXT PRIMARY KEY,
+		label TEXT NOT NULL,
+		category TEXT NOT NULL,
+		source TEXT NOT NULL,
+		updated TIMESTAMPTZ NOT NULL
+	);`
+
+	DeleteFileAddressLabels = `DELETE FROM address_labels WHERE source = 'file';`
+
+	// InsertFileAddressLabel inserts a label from the labels file, unless the
+	// address already has a label set through the admin API.
+	InsertFileAddressLabel = `INSERT INTO address_labels (address, label, category, source, updated)
+		VALUES ($1, $2, $3, 'file', $4)
+		ON CONFLICT (address) DO NOTHING;`
+
+	UpsertAddressLabel = `INSERT INTO address_labels (address, label, category, source, updated)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (address) DO UPDATE
+		SET label = $2, category = $3, source = $4, updated = $5;`
+
+	DeleteAddressLabel = `DELETE FROM address_labels WHERE address = $1;`
+
+	SelectAddressLabels = `SELECT address, label, category, source, updated
+		FROM address_labels;`
+)
@@ -0,0 +1,87 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "mempool_history" table.
+const (
+	// CreateMempoolHistoryTable creates the mempool_history table of every
+	// transaction observed in mempool, with the time it was first seen, and
+	// its final disposition. fee_rate is in atoms/kB. resolved is the time the
+	// transaction left mempool, which is the block time for a mined
+	// transaction. conflict_tx_hash is the transaction spending an input of a
+	// conflicted transaction.
+	CreateMempoolHistoryTable = `CREATE TABLE IF NOT EXISTS mempool_history (
+		tx_hash TEXT PRIMARY KEY,
+		tx_type INT4 NOT NULL,
+		size INT4 NOT NULL,
+		fee INT8 NOT NULL,
+		fee_rate INT8 NOT NULL,
+		expiry INT8 NOT NULL,
+		first_seen TIMESTAMPTZ NOT NULL,
+		last_seen TIMESTAMPTZ NOT NULL,
+		disposition TEXT NOT NULL,
+		resolved TIMESTAMPTZ,
+		block_hash TEXT,
+		block_height INT8,
+		conflict_tx_hash TEXT
+	);`
+
+	// IndexMempoolHistoryOnDisposition indexes the transactions by disposition
+	// and first seen time, for the selection of the pending transactions and
+	// of the recently mined transactions for the confirmation times.
+	IndexMempoolHistoryOnDisposition = `CREATE INDEX ` + IndexOfMempoolHistoryTableOnDisposition +
+		` ON mempool_history(disposition, first_seen);`
+	DeindexMempoolHistoryOnDisposition = `DROP INDEX ` + IndexOfMempoolHistoryTableOnDisposition + ` CASCADE;`
+
+	// UpsertMempoolHistory inserts a new mempool transaction, or updates the
+	// disposition of a transaction already recorded. The earliest first_seen
+	// time is kept, for a transaction that returns to mempool after a reorg.
+	UpsertMempoolHistory = `INSERT INTO mempool_history (tx_hash, tx_type, size,
+			fee, fee_rate, expiry, first_seen, last_seen, disposition, resolved,
+			block_hash, block_height, conflict_tx_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tx_hash) DO UPDATE
+		SET first_seen = LEAST(mempool_history.first_seen, EXCLUDED.first_seen),
+			last_seen = GREATEST(mempool_history.last_seen, EXCLUDED.last_seen),
+			disposition = EXCLUDED.disposition, resolved = EXCLUDED.resolved,
+			block_hash = EXCLUDED.block_hash, block_height = EXCLUDED.block_height,
+			conflict_tx_hash = EXCLUDED.conflict_tx_hash;`
+
+	// ResolveMinedMempoolHistory sets the disposition ($1) of the
+	// transactions with disposition $2 that were mined in a main chain block,
+	// such as while dcrdata was not running.
+	ResolveMinedMempoolHistory = `UPDATE mempool_history
+		SET disposition = $1, resolved = transactions.block_time,
+			block_hash = transactions.block_hash,
+			block_height = transactions.block_height
+		FROM transactions
+		WHERE mempool_history.disposition = $2
+			AND transactions.tx_hash = mempool_history.tx_hash
+			AND transactions.is_mainchain;`
+
+	// SelectMempoolHistoryByDisposition selects the transactions with the
+	// given disposition.
+	SelectMempoolHistoryByDisposition = `SELECT tx_hash, tx_type, size, fee,
+			fee_rate, expiry, first_seen, last_seen, disposition, resolved,
+			block_hash, block_height, conflict_tx_hash
+		FROM mempool_history
+		WHERE disposition = $1;`
+
+	SelectMempoolFirstSeen = `SELECT first_seen FROM mempool_history
+		WHERE tx_hash = $1;`
+
+	// SelectConfirmationTimeBuckets selects the number and median time to
+	// confirmation in seconds of the regular transactions mined ($2) after
+	// being first seen in mempool after $3, in buckets of fee rate. $1 is the
+	// ascending array of the minimum fee rates of the buckets, and the bucket
+	// number is the 1-based index of the bucket's minimum fee rate.
+	SelectConfirmationTimeBuckets = `SELECT width_bucket(fee_rate, $1::INT8[]) AS bucket,
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY
+				GREATEST(EXTRACT(EPOCH FROM resolved - first_seen), 0))
+		FROM mempool_history
+		WHERE disposition = $2 AND tx_type = 0 AND first_seen > $3
+		GROUP BY bucket
+		ORDER BY bucket;`
+)
@@ -0,0 +1,75 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "miss_causes" table of the causes
+// attributed to the missed votes in the "misses" table.
+const (
+	// CreateMissCausesTable creates the miss_causes table of the cause of each
+	// miss in a block mined while the mempool was watched. vote_seen is the
+	// time the ticket's vote on the parent block was first seen in mempool.
+	// vsp_fee_address is the fee address of the VSP of the ticket.
+	CreateMissCausesTable = `CREATE TABLE IF NOT EXISTS miss_causes (
+		ticket_hash TEXT NOT NULL,
+		block_hash TEXT NOT NULL,
+		cause TEXT NOT NULL,
+		vote_seen TIMESTAMPTZ,
+		vsp_fee_address TEXT,
+		PRIMARY KEY (ticket_hash, block_hash)
+	);`
+
+	UpsertMissCause = `INSERT INTO miss_causes (ticket_hash, block_hash, cause,
+			vote_seen, vsp_fee_address)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (ticket_hash, block_hash) DO UPDATE
+		SET cause = EXCLUDED.cause, vote_seen = EXCLUDED.vote_seen,
+			vsp_fee_address = EXCLUDED.vsp_fee_address;`
+
+	// SelectTicketsFeeAddress selects the address of the first commitment
+	// output of each main chain ticket ($1), and whether the ticket is a
+	// legacy stake pool ticket, with a multisig stake submission script and a
+	// second commitment output. See SelectVSPTicketStats.
+	SelectTicketsFeeAddress = `SELECT tickets.tx_hash, fee.script_addresses[1],
+			tickets.is_multisig AND EXISTS (
+				SELECT 1 FROM vouts
+				WHERE vouts.tx_hash = tickets.tx_hash
+					AND vouts.tx_index = 3 AND vouts.tx_tree = 1)
+		FROM tickets
+		JOIN vouts AS fee ON fee.tx_hash = tickets.tx_hash
+			AND fee.tx_index = 1 AND fee.tx_tree = 1
+		WHERE tickets.tx_hash = ANY($1) AND tickets.is_mainchain;`
+
+	// selectMissedVotes selects the misses with their cause, or $1 for the
+	// misses without an attributed cause.
+	selectMissedVotes = `SELECT misses.ticket_hash, misses.block_hash,
+			misses.height, COALESCE(miss_causes.cause, $1),
+			COALESCE(EXTRACT(EPOCH FROM miss_causes.vote_seen), 0)::INT8,
+			COALESCE(miss_causes.vsp_fee_address, '')
+		FROM misses
+		LEFT JOIN miss_causes ON miss_causes.ticket_hash = misses.ticket_hash
+			AND miss_causes.block_hash = misses.block_hash `
+
+	// SelectMissedVotesInBlock selects the misses in the block ($2).
+	SelectMissedVotesInBlock = selectMissedVotes +
+		`WHERE misses.block_hash = $2
+		ORDER BY misses.ticket_hash;`
+
+	// SelectMissedVotesForTicket selects the main chain misses of the ticket
+	// ($2).
+	SelectMissedVotesForTicket = selectMissedVotes +
+		`JOIN blocks ON blocks.hash = misses.block_hash
+		WHERE misses.ticket_hash = $2 AND blocks.is_mainchain
+		ORDER BY misses.height;`
+
+	// SelectMissedVotesForAddress selects the most recent $3 main chain misses
+	// of the tickets with the stake submission or a commitment address $2.
+	SelectMissedVotesForAddress = selectMissedVotes +
+		`JOIN blocks ON blocks.hash = misses.block_hash
+		JOIN tickets ON tickets.tx_hash = misses.ticket_hash
+			AND tickets.is_mainchain
+		WHERE blocks.is_mainchain
+			AND misses.ticket_hash IN (` + selectTicketsForAddress + `$2)
+		ORDER BY misses.height DESC
+		LIMIT $3;`
+)
@@ -0,0 +1,149 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "politeia_proposals",
+// "politeia_proposal_versions", "politeia_status_changes" and
+// "politeia_vote_tallies" tables of the proposals polled from the Politeia API.
+// They are distinct from the "proposals" and "proposal_votes" tables of the
+// proposal votes parsed from the proposals repository.
+const (
+	// CreatePoliteiaProposalsTable creates the politeia_proposals table of the
+	// latest version of each proposal. data is the JSON encoding of the
+	// proposal record.
+	CreatePoliteiaProposalsTable = `CREATE TABLE IF NOT EXISTS politeia_proposals (
+		id SERIAL PRIMARY KEY,
+		token TEXT NOT NULL UNIQUE,
+		ref_id TEXT NOT NULL UNIQUE,
+		version TEXT NOT NULL,
+		timestamp INT8 NOT NULL,
+		state INT2 NOT NULL,
+		status INT2 NOT NULL,
+		vote_status INT2 NOT NULL,
+		data JSONB NOT NULL
+	);`
+
+	// CreatePoliteiaProposalVersionsTable creates the
+	// politeia_proposal_versions table of each version of the proposals, with
+	// the time it was first stored.
+	CreatePoliteiaProposalVersionsTable = `CREATE TABLE IF NOT EXISTS politeia_proposal_versions (
+		token TEXT NOT NULL,
+		version TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		data JSONB NOT NULL,
+		PRIMARY KEY (token, version)
+	);`
+
+	// CreatePoliteiaStatusChangesTable creates the politeia_status_changes
+	// table of the changes of the state, status or vote status of the
+	// proposals, with the time they were first stored.
+	CreatePoliteiaStatusChangesTable = `CREATE TABLE IF NOT EXISTS politeia_status_changes (
+		token TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		state INT2 NOT NULL,
+		status INT2 NOT NULL,
+		vote_status INT2 NOT NULL,
+		message TEXT NOT NULL,
+		PRIMARY KEY (token, time)
+	);`
+
+	// CreatePoliteiaVoteTalliesTable creates the politeia_vote_tallies table
+	// of the vote tally of the proposals at each poll of Politeia during and
+	// at the end of their vote.
+	CreatePoliteiaVoteTalliesTable = `CREATE TABLE IF NOT EXISTS politeia_vote_tallies (
+		token TEXT NOT NULL,
+		time TIMESTAMPTZ NOT NULL,
+		vote_status INT2 NOT NULL,
+		yes INT8 NOT NULL,
+		no INT8 NOT NULL,
+		total_votes INT8 NOT NULL,
+		eligible_votes INT8 NOT NULL,
+		PRIMARY KEY (token, time)
+	);`
+
+	// SelectPoliteiaProposalStatus selects the version, state, status and vote
+	// status of the stored proposal ($1), locking its row for the update.
+	SelectPoliteiaProposalStatus = `SELECT version, state, status, vote_status
+		FROM politeia_proposals
+		WHERE token = $1
+		FOR UPDATE;`
+
+	UpsertPoliteiaProposal = `INSERT INTO politeia_proposals (token, ref_id,
+			version, timestamp, state, status, vote_status, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (token) DO UPDATE
+		SET ref_id = $2, version = $3, timestamp = $4, state = $5, status = $6,
+			vote_status = $7, data = $8
+		RETURNING id;`
+
+	InsertPoliteiaProposalVersion = `INSERT INTO politeia_proposal_versions (token,
+			version, time, data)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token, version) DO NOTHING;`
+
+	InsertPoliteiaStatusChange = `INSERT INTO politeia_status_changes (token,
+			time, state, status, vote_status, message)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (token, time) DO NOTHING;`
+
+	InsertPoliteiaVoteTally = `INSERT INTO politeia_vote_tallies (token, time,
+			vote_status, yes, no, total_votes, eligible_votes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (token, time) DO NOTHING;`
+
+	selectPoliteiaProposals = `SELECT id, token, ref_id, version, timestamp,
+			state, status, vote_status, data
+		FROM politeia_proposals `
+
+	SelectPoliteiaProposalByToken = selectPoliteiaProposals + `WHERE token = $1;`
+
+	SelectPoliteiaProposalByRefID = selectPoliteiaProposals + `WHERE ref_id = $1;`
+
+	// SelectPoliteiaProposals selects $1 proposals, skipping $2, starting with
+	// the newest.
+	SelectPoliteiaProposals = selectPoliteiaProposals +
+		`ORDER BY timestamp DESC, id DESC
+		LIMIT $1 OFFSET $2;`
+
+	// SelectPoliteiaProposalsByVoteStatus selects $2 proposals with a vote
+	// status in $1, skipping $3, starting with the newest.
+	SelectPoliteiaProposalsByVoteStatus = selectPoliteiaProposals +
+		`WHERE vote_status = ANY($1)
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $2 OFFSET $3;`
+
+	SelectPoliteiaProposalsCount = `SELECT COUNT(*) FROM politeia_proposals;`
+
+	SelectPoliteiaProposalsCountByVoteStatus = `SELECT COUNT(*)
+		FROM politeia_proposals
+		WHERE vote_status = ANY($1);`
+
+	SelectPoliteiaProposalVersions = `SELECT version, time, data
+		FROM politeia_proposal_versions
+		WHERE token = $1
+		ORDER BY time;`
+
+	SelectPoliteiaStatusChanges = `SELECT time, state, status, vote_status,
+			message
+		FROM politeia_status_changes
+		WHERE token = $1
+		ORDER BY time;`
+
+	// SelectPoliteiaVoteTalliesHourly selects the last tally of the votes on
+	// the proposal ($1) polled in each hour, with the number of votes on the
+	// proposal in the proposal_votes table from the commits to the proposals
+	// repository up to the time of the tally.
+	SelectPoliteiaVoteTalliesHourly = `SELECT
+			DISTINCT ON (date_trunc('hour', tallies.time)) tallies.time,
+			tallies.vote_status, tallies.yes, tallies.no, tallies.total_votes,
+			tallies.eligible_votes, (
+				SELECT COUNT(*)
+				FROM proposal_votes
+				JOIN proposals ON proposals.id = proposal_votes.proposals_row_id
+				WHERE proposals.token = tallies.token
+					AND proposals.time <= tallies.time)
+		FROM politeia_vote_tallies AS tallies
+		WHERE tallies.token = $1
+		ORDER BY date_trunc('hour', tallies.time), tallies.time DESC;`
+)
@@ -0,0 +1,72 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "proposal_votes" table of the votes on
+// the Politeia proposals parsed from the commits to the proposals repository,
+// joined with the tickets that voted and the proposals polled from Politeia.
+// The votes of a ticket on a proposal in several commits are deduplicated to
+// the latest.
+const (
+	// selectProposalTicketVotes selects the latest vote of the tickets on the
+	// proposals, with the name and RefID of the proposals polled from Politeia.
+	selectProposalTicketVotes = `SELECT ticket, token, name, ref_id, choice, time
+		FROM (
+			SELECT DISTINCT ON (proposal_votes.ticket, proposals.token)
+				proposal_votes.ticket, proposals.token,
+				COALESCE(politeia_proposals.data->>'name', '') AS name,
+				COALESCE(politeia_proposals.ref_id, '') AS ref_id,
+				proposal_votes.choice, proposals.time
+			FROM proposal_votes
+			JOIN proposals ON proposals.id = proposal_votes.proposals_row_id
+			LEFT JOIN politeia_proposals
+				ON politeia_proposals.token = proposals.token `
+
+	// SelectProposalVotesForTicket selects the votes of the ticket ($1) on
+	// the proposals, the most recent first.
+	SelectProposalVotesForTicket = selectProposalTicketVotes +
+		`WHERE proposal_votes.ticket = $1
+			ORDER BY proposal_votes.ticket, proposals.token, proposals.time DESC
+		) AS votes
+		ORDER BY time DESC, token;`
+
+	// SelectProposalVotesForAddress selects the most recent $2 votes on the
+	// proposals of the main chain tickets with the stake submission or a
+	// commitment address $1.
+	SelectProposalVotesForAddress = selectProposalTicketVotes +
+		`WHERE proposal_votes.ticket IN (
+				SELECT tickets.tx_hash
+				FROM tickets
+				WHERE tickets.is_mainchain
+					AND tickets.tx_hash IN (` + selectTicketsForAddress + `$1))
+			ORDER BY proposal_votes.ticket, proposals.token, proposals.time DESC
+		) AS votes
+		ORDER BY time DESC, token, ticket
+		LIMIT $2;`
+
+	// proposalVoters selects the latest choice of each ticket that voted on
+	// the proposal ($1), with the stake submission address and purchase height
+	// of the main chain tickets, or -1 for the height of an unknown ticket.
+	proposalVoters = `SELECT DISTINCT ON (proposal_votes.ticket)
+			proposal_votes.ticket,
+			COALESCE(tickets.stakesubmission_address, '') AS address,
+			proposal_votes.choice, COALESCE(tickets.block_height, -1) AS ticket_height
+		FROM proposal_votes
+		JOIN proposals ON proposals.id = proposal_votes.proposals_row_id
+		LEFT JOIN tickets ON tickets.tx_hash = proposal_votes.ticket
+			AND tickets.is_mainchain
+		WHERE proposals.token = $1
+		ORDER BY proposal_votes.ticket, proposals.time DESC`
+
+	// SelectProposalVoters selects $2 of the tickets that voted on the
+	// proposal ($1), by ticket hash, after skipping $3.
+	SelectProposalVoters = proposalVoters + `
+		LIMIT $2 OFFSET $3;`
+
+	// SelectProposalVoterHeights counts the tickets that voted on the proposal
+	// ($1) by purchase height and choice.
+	SelectProposalVoterHeights = `SELECT ticket_height, choice, COUNT(*)
+		FROM (` + proposalVoters + `) AS voters
+		GROUP BY ticket_height, choice;`
+)
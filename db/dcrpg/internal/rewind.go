@@ -203,6 +203,9 @@ const (
 	DeleteMisses = `DELETE FROM misses
 		WHERE block_hash=$1;`
 
+	DeleteMissCauses = `DELETE FROM miss_causes
+		WHERE block_hash=$1;`
+
 	DeleteVotes = `DELETE FROM votes
 		WHERE block_hash=$1;`
 
@@ -0,0 +1,84 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "rich_list" table, and the temporary
+// "address_balances" table from which it is materialized.
+const (
+	// CreateRichListTable creates the rich_list table of the addresses with
+	// the largest balances of unspent outputs, ranked from 1. The table is
+	// replaced in full on each refresh, and height is the main chain height at
+	// the time of the refresh.
+	CreateRichListTable = `CREATE TABLE IF NOT EXISTS rich_list (
+		rank INT8 PRIMARY KEY,
+		address TEXT NOT NULL,
+		balance INT8 NOT NULL,
+		num_utxos INT8 NOT NULL,
+		height INT8 NOT NULL
+	);`
+
+	// CreateAddressBalancesTempTable computes the balance and number of
+	// unspent outputs of every address with unspent outputs in the main chain.
+	// The temporary table is dropped at the end of the transaction.
+	CreateAddressBalancesTempTable = `CREATE TEMP TABLE address_balances
+		ON COMMIT DROP AS
+		SELECT address, SUM(value)::INT8 AS balance, COUNT(*) AS num_utxos
+		FROM addresses
+		WHERE is_funding AND matching_tx_hash = '' AND valid_mainchain
+		GROUP BY address
+		HAVING SUM(value) > 0;`
+
+	DeleteRichList = `DELETE FROM rich_list;`
+
+	// InsertRichList materializes the top $1 addresses by balance at height
+	// $2. Ties are ranked by address for a stable order.
+	InsertRichList = `INSERT INTO rich_list (rank, address, balance, num_utxos, height)
+		SELECT ROW_NUMBER() OVER (ORDER BY balance DESC, address), address,
+			balance, num_utxos, $2
+		FROM address_balances
+		ORDER BY balance DESC, address
+		LIMIT $1;`
+
+	// SelectAddressDistribution computes the number of addresses with a
+	// balance, their total balance, the Gini coefficient of the balances, and
+	// the balances at the 50th, 90th, 99th and 99.9th percentiles.
+	SelectAddressDistribution = `WITH ranked AS (
+			SELECT balance::NUMERIC AS balance,
+				ROW_NUMBER() OVER (ORDER BY balance) AS i
+			FROM address_balances
+		)
+		SELECT COUNT(*), COALESCE(SUM(balance), 0)::INT8,
+			COALESCE(2 * SUM(i * balance) / NULLIF(COUNT(*) * SUM(balance), 0)
+				- (COUNT(*) + 1)::NUMERIC / NULLIF(COUNT(*), 0), 0)::FLOAT8,
+			PERCENTILE_DISC(0.5) WITHIN GROUP (ORDER BY balance)::INT8,
+			PERCENTILE_DISC(0.9) WITHIN GROUP (ORDER BY balance)::INT8,
+			PERCENTILE_DISC(0.99) WITHIN GROUP (ORDER BY balance)::INT8,
+			PERCENTILE_DISC(0.999) WITHIN GROUP (ORDER BY balance)::INT8
+		FROM ranked;`
+
+	// SelectAddressBalanceBuckets counts the addresses and sums their balances
+	// in power of ten buckets of the balance in DCR. Bucket b holds balances
+	// from 10^b to 10^(b+1) DCR, except that the first bucket, -3, holds all
+	// balances below 0.01 DCR, and the last, 6, all balances of 1 million DCR
+	// and above.
+	SelectAddressBalanceBuckets = `SELECT
+			GREATEST(LEAST(FLOOR(LOG(balance::NUMERIC / 1e8)), 6), -3)::INT4 AS bucket,
+			COUNT(*), SUM(balance)::INT8
+		FROM address_balances
+		GROUP BY bucket
+		ORDER BY bucket;`
+
+	// SelectRichListTopBalances sums the balances of the top 10, 100 and 1000
+	// addresses of the rich list.
+	SelectRichListTopBalances = `SELECT
+			COALESCE(SUM(balance) FILTER (WHERE rank <= 10), 0)::INT8,
+			COALESCE(SUM(balance) FILTER (WHERE rank <= 100), 0)::INT8,
+			COALESCE(SUM(balance) FILTER (WHERE rank <= 1000), 0)::INT8
+		FROM rich_list;`
+
+	SelectRichList = `SELECT rank, address, balance, num_utxos, height
+		FROM rich_list
+		ORDER BY rank
+		LIMIT $1;`
+)
@@ -100,12 +100,40 @@ const (
 	SelectTicketsTxDbIDsInBlock = `SELECT purchase_tx_db_id FROM tickets WHERE block_hash = $1;`
 	SelectTicketsForAddress     = `SELECT * FROM tickets WHERE stakesubmission_address = $1;`
 
+	// selectTicketsForAddress selects the hashes of the tickets with the stake
+	// submission (output 0) or a commitment (an odd output) address given by
+	// the parameter appended to it, through the index of the addresses table
+	// on address. The tickets may be side chain tickets.
+	selectTicketsForAddress = `SELECT addresses.tx_hash
+		FROM addresses
+		WHERE addresses.tx_type = 1 AND addresses.is_funding
+			AND (addresses.tx_vin_vout_index = 0 OR addresses.tx_vin_vout_index % 2 = 1)
+			AND addresses.address = `
+
 	forTxHashMainchainFirst    = ` WHERE tx_hash = $1 ORDER BY is_mainchain DESC;`
 	SelectTicketIDHeightByHash = `SELECT id, block_height FROM tickets` + forTxHashMainchainFirst
 	SelectTicketIDByHash       = `SELECT id FROM tickets` + forTxHashMainchainFirst
 	SelectTicketStatusByHash   = `SELECT id, spend_type, pool_status FROM tickets` + forTxHashMainchainFirst
 	SelectTicketInfoByHash     = `SELECT block_hash, block_height, spend_type, pool_status, spend_tx_db_id FROM tickets` + forTxHashMainchainFirst
 
+	// SelectTicketsInfoByHashes selects the purchase block, spend and pool
+	// statuses, spending transaction and block, and main chain miss block of
+	// each of the tickets in the array $1, preferring the main chain rows.
+	SelectTicketsInfoByHashes = `SELECT DISTINCT ON (tickets.tx_hash) tickets.tx_hash,
+			tickets.block_hash, tickets.block_height, tickets.spend_type, tickets.pool_status,
+			spends.block_hash, spends.block_height, spends.tx_hash,
+			misses.block_hash, misses.height
+		FROM tickets
+		LEFT JOIN transactions AS spends ON spends.id = tickets.spend_tx_db_id
+		LEFT JOIN (
+			SELECT misses.ticket_hash, misses.block_hash, misses.height
+			FROM misses
+			JOIN blocks ON misses.block_hash = blocks.hash
+			WHERE blocks.is_mainchain = TRUE
+		) AS misses ON misses.ticket_hash = tickets.tx_hash AND tickets.pool_status = 3
+		WHERE tickets.tx_hash = ANY($1)
+		ORDER BY tickets.tx_hash, tickets.is_mainchain DESC;`
+
 	SelectUnspentTickets = `SELECT id, tx_hash FROM tickets
 		WHERE spend_type = 0 AND is_mainchain = true;`
 
@@ -515,6 +543,11 @@ const (
 
 	DeindexProposalVotesTableOnProposalsID = `DROP INDEX ` + IndexOfProposalVotesTableOnProposalsID + ` CASCADE;`
 
+	IndexProposalVotesTableOnTicket = `CREATE INDEX ` + IndexOfProposalVotesTableOnTicket +
+		` ON proposal_votes(ticket);`
+
+	DeindexProposalVotesTableOnTicket = `DROP INDEX ` + IndexOfProposalVotesTableOnTicket + ` CASCADE;`
+
 	// Select
 
 	SelectProposalVotesChartData = `SELECT proposals.time,
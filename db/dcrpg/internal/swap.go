@@ -0,0 +1,129 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "swaps" table.
+const (
+	// CreateAtomicSwapTable creates the swaps table. Each row corresponds to a
+	// transaction input that spends (redeems or refunds) an atomic swap
+	// contract output. The secret is NULL for refunds.
+	CreateAtomicSwapTable = `CREATE TABLE IF NOT EXISTS swaps (
+		contract_tx TEXT,
+		contract_vout INT4,
+		spend_tx TEXT,
+		spend_vin INT4,
+		spend_height INT8,
+		spend_block_hash TEXT,
+		spend_time TIMESTAMPTZ NOT NULL,
+		contract_addr TEXT,
+		recipient_addr TEXT,
+		refund_addr TEXT,
+		value INT8,
+		secret_hash BYTEA,
+		secret BYTEA,
+		lock_time INT8,
+		is_refund BOOLEAN,
+		is_mainchain BOOLEAN
+	);`
+
+	IndexSwapsOnSpendTx   = `CREATE UNIQUE INDEX ` + IndexOfSwapsTableOnSpendTx + ` ON swaps(spend_tx, spend_vin, spend_block_hash);`
+	DeindexSwapsOnSpendTx = `DROP INDEX ` + IndexOfSwapsTableOnSpendTx + ` CASCADE;`
+
+	IndexSwapsOnSecretHash   = `CREATE INDEX ` + IndexOfSwapsTableOnSecretHash + ` ON swaps(secret_hash);`
+	DeindexSwapsOnSecretHash = `DROP INDEX ` + IndexOfSwapsTableOnSecretHash + ` CASCADE;`
+
+	IndexSwapsOnSpendTime   = `CREATE INDEX ` + IndexOfSwapsTableOnSpendTime + ` ON swaps(spend_time DESC);`
+	DeindexSwapsOnSpendTime = `DROP INDEX ` + IndexOfSwapsTableOnSpendTime + ` CASCADE;`
+
+	IndexSwapsOnRecipient   = `CREATE INDEX ` + IndexOfSwapsTableOnRecipient + ` ON swaps(recipient_addr);`
+	DeindexSwapsOnRecipient = `DROP INDEX ` + IndexOfSwapsTableOnRecipient + ` CASCADE;`
+
+	IndexSwapsOnRefund   = `CREATE INDEX ` + IndexOfSwapsTableOnRefund + ` ON swaps(refund_addr);`
+	DeindexSwapsOnRefund = `DROP INDEX ` + IndexOfSwapsTableOnRefund + ` CASCADE;`
+
+	UpdateSwapsMainchainByBlock = `UPDATE swaps
+		SET is_mainchain=$1
+		WHERE spend_block_hash=$2;`
+
+	DeleteSwapsByBlock = `DELETE FROM swaps WHERE spend_block_hash=$1;`
+
+	// InsertSwapRow inserts a new swaps row without checking for unique index
+	// conflicts. This should only be used before the unique indexes are
+	// created or there may be constraint violations (errors).
+	InsertSwapRow = `INSERT INTO swaps (
+		contract_tx, contract_vout, spend_tx, spend_vin, spend_height,
+		spend_block_hash, spend_time, contract_addr, recipient_addr, refund_addr,
+		value, secret_hash, secret, lock_time, is_refund, is_mainchain)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) `
+
+	// UpsertSwapRow is an upsert (insert or update on conflict). is_mainchain
+	// is updated as this might be a reorganization.
+	UpsertSwapRow = InsertSwapRow + `ON CONFLICT (spend_tx, spend_vin, spend_block_hash)
+		DO UPDATE SET is_mainchain = $16;`
+
+	// InsertSwapRowOnConflictDoNothing allows an INSERT with a DO NOTHING on
+	// conflict with a swap's unique spend index.
+	InsertSwapRowOnConflictDoNothing = InsertSwapRow + `ON CONFLICT (spend_tx, spend_vin, spend_block_hash)
+		DO NOTHING;`
+
+	selectSwapsColumns = `SELECT contract_tx, contract_vout, spend_tx, spend_vin,
+		spend_height, spend_time, contract_addr, recipient_addr, refund_addr,
+		value, secret_hash, secret, lock_time, is_refund
+		FROM swaps `
+
+	SelectSwaps = selectSwapsColumns +
+		`WHERE is_mainchain
+		ORDER BY spend_height DESC, spend_tx, spend_vin
+		LIMIT $1 OFFSET $2;`
+
+	SelectSwapsCount = `SELECT COUNT(*) FROM swaps WHERE is_mainchain;`
+
+	// SelectSwapsByAddress selects swaps where the address is either the
+	// contract's recipient (participant) or refund (initiator) address, or the
+	// P2SH address of the contract itself.
+	SelectSwapsByAddress = selectSwapsColumns +
+		`WHERE is_mainchain
+			AND (recipient_addr = $1 OR refund_addr = $1 OR contract_addr = $1)
+		ORDER BY spend_height DESC, spend_tx, spend_vin
+		LIMIT $2 OFFSET $3;`
+
+	SelectSwapsCountByAddress = `SELECT COUNT(*) FROM swaps
+		WHERE is_mainchain
+			AND (recipient_addr = $1 OR refund_addr = $1 OR contract_addr = $1);`
+
+	SelectSwapsBySecretHash = selectSwapsColumns +
+		`WHERE is_mainchain AND secret_hash = $1
+		ORDER BY spend_height DESC, spend_tx, spend_vin;`
+
+	SelectSwapsByTimeRange = selectSwapsColumns +
+		`WHERE is_mainchain AND spend_time >= $1 AND spend_time <= $2
+		ORDER BY spend_time DESC, spend_tx, spend_vin
+		LIMIT $3 OFFSET $4;`
+
+	// SelectP2SHSpendingBlocks selects the hashes of the main chain blocks
+	// containing regular transactions that spend a P2SH output. These are the
+	// only blocks that may contain swap contract redemptions or refunds.
+	SelectP2SHSpendingBlocks = `SELECT DISTINCT transactions.block_hash, transactions.block_height
+		FROM vins
+		JOIN vouts ON vouts.tx_hash = vins.prev_tx_hash
+			AND vouts.tx_index = vins.prev_tx_index
+		JOIN transactions ON transactions.tx_hash = vins.tx_hash
+			AND transactions.is_mainchain
+		WHERE vins.is_mainchain AND vins.tx_tree = 0
+			AND vouts.script_type = 'scripthash'
+		ORDER BY transactions.block_height;`
+)
+
+// MakeSwapInsertStatement returns the appropriate swaps insert statement for
+// the desired conflict checking and handling behavior. See
+// MakeTreasuryInsertStatement for the meaning of checked and updateOnConflict.
+func MakeSwapInsertStatement(checked, updateOnConflict bool) string {
+	if !checked {
+		return InsertSwapRow
+	}
+	if updateOnConflict {
+		return UpsertSwapRow
+	}
+	return InsertSwapRowOnConflictDoNothing
+}
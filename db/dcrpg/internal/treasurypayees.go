@@ -0,0 +1,50 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "treasury_payees" table of the outputs
+// of the treasury spends in the "treasury" table.
+const (
+	// CreateTreasuryPayeesTable creates the treasury_payees table of the
+	// address and value of each output of a tspend after the first, which is
+	// the tspend's OP_RETURN output. The block of a tspend is in the treasury
+	// table.
+	CreateTreasuryPayeesTable = `CREATE TABLE IF NOT EXISTS treasury_payees (
+		tx_hash TEXT NOT NULL,
+		tx_index INT4 NOT NULL,
+		address TEXT NOT NULL,
+		value INT8 NOT NULL,
+		PRIMARY KEY (tx_hash, tx_index)
+	);`
+
+	InsertTreasuryPayee = `INSERT INTO treasury_payees (tx_hash, tx_index,
+			address, value)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tx_hash, tx_index) DO NOTHING;`
+
+	// InsertTreasuryPayeesFromVouts inserts the payees of the tspends ($1) in
+	// the treasury table from their outputs in the vouts table.
+	InsertTreasuryPayeesFromVouts = `INSERT INTO treasury_payees (tx_hash,
+			tx_index, address, value)
+		SELECT DISTINCT ON (vouts.tx_hash, vouts.tx_index) vouts.tx_hash,
+			vouts.tx_index, vouts.script_addresses[1], vouts.value
+		FROM treasury
+		JOIN vouts ON vouts.tx_hash = treasury.tx_hash
+			AND vouts.tx_tree = 1 AND vouts.tx_index > 0
+		WHERE treasury.tx_type = $1
+			AND vouts.script_addresses[1] IS NOT NULL
+		ON CONFLICT (tx_hash, tx_index) DO NOTHING;`
+
+	// SelectTreasuryPayeeSpends selects the amount paid to each payee address
+	// by each main chain tspend, with the tspend's block time.
+	SelectTreasuryPayeeSpends = `SELECT treasury.block_time,
+			treasury_payees.tx_hash, treasury_payees.address,
+			SUM(treasury_payees.value)
+		FROM treasury_payees
+		JOIN treasury ON treasury.tx_hash = treasury_payees.tx_hash
+			AND treasury.is_mainchain
+		GROUP BY treasury.block_time, treasury_payees.tx_hash,
+			treasury_payees.address
+		ORDER BY treasury.block_time, treasury_payees.tx_hash;`
+)
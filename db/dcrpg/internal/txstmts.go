@@ -149,6 +149,15 @@ const (
 		FROM transactions WHERE tx_hash = $1
 		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC;`
 
+	// SelectFullTxsByHashes is like SelectFullTxsByHash, but for any of the
+	// transaction hashes in an array.
+	SelectFullTxsByHashes = `SELECT id, block_hash, block_height, block_time,
+			time, tx_type, version, tree, tx_hash, block_index, lock_time, expiry,
+			size, spent, sent, fees, mix_count, mix_denom, num_vin, vin_db_ids,
+			num_vout, vout_db_ids, is_valid, is_mainchain
+		FROM transactions WHERE tx_hash = ANY($1)
+		ORDER BY is_mainchain DESC, is_valid DESC, block_time DESC;`
+
 	SelectTxnsVinsByBlock = `SELECT vin_db_ids, is_valid, is_mainchain
 		FROM transactions WHERE block_hash = $1;`
 
@@ -270,6 +279,22 @@ const (
 		GROUP BY block_height
 		ORDER BY block_height;`
 
+	// SelectMinRegularFeeRatesAboveHeight selects the lowest fee rate, in
+	// atoms/byte, paid by a non-coinbase regular tree transaction in each main
+	// chain block above the given height. Blocks without such transactions
+	// have a zero minimum fee rate.
+	SelectMinRegularFeeRatesAboveHeight = `
+		SELECT blocks.height, COALESCE(MIN(transactions.fees::FLOAT8 / transactions.size), 0)
+		FROM blocks
+		LEFT JOIN transactions ON transactions.block_height = blocks.height
+			AND transactions.is_mainchain
+			AND transactions.tree = 0
+			AND transactions.block_index > 0
+		WHERE blocks.is_mainchain
+			AND blocks.height > $1
+		GROUP BY blocks.height
+		ORDER BY blocks.height DESC;`
+
 	SelectMixedTotalPerBlock = `
 		SELECT block_height AS block_height, 
 			SUM(mix_count * mix_denom) AS total_mixed
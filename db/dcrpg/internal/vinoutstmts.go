@@ -117,6 +117,8 @@ const (
 	SelectAllVinInfoByID             = `SELECT tx_hash, tx_index, tx_tree, is_valid, is_mainchain, block_time,
 		prev_tx_hash, prev_tx_index, prev_tx_tree, value_in, tx_type FROM vins WHERE id = $1;`
 	SelectVinVoutPairByID = `SELECT tx_hash, tx_index, prev_tx_hash, prev_tx_index FROM vins WHERE id = $1;`
+	SelectAllVinInfoByIDs = `SELECT id, tx_hash, tx_index, tx_tree, is_valid, is_mainchain, block_time,
+		prev_tx_hash, prev_tx_index, prev_tx_tree, value_in, tx_type FROM vins WHERE id = ANY($1);`
 
 	SelectUTXOsViaVinsMatch = `SELECT vouts.id, vouts.tx_hash, vouts.tx_index,   -- row ID and outpoint
 			vouts.script_addresses, vouts.value, vouts.mixed         -- value, addresses, and mixed flag of output
@@ -267,6 +269,8 @@ const (
 
 	SelectVoutIDByOutpoint = `SELECT id FROM vouts WHERE tx_hash=$1 and tx_index=$2;`
 	SelectVoutByID         = `SELECT * FROM vouts WHERE id=$1;`
+	SelectVoutsByIDs       = `SELECT id, tx_hash, tx_index, tx_tree, value, version, pkscript,
+		script_req_sigs, script_type, script_addresses, mixed FROM vouts WHERE id = ANY($1);`
 
 	RetrieveVoutValue  = `SELECT value FROM vouts WHERE tx_hash=$1 and tx_index=$2;`
 	RetrieveVoutValues = `SELECT value, tx_index, tx_tree FROM vouts WHERE tx_hash=$1;`
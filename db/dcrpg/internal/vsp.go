@@ -0,0 +1,42 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries attribute the tickets in the "tickets" table to VSPs by the
+// address of their first commitment output in the "vouts" table.
+const (
+	// SelectVSPTicketStats counts the main chain tickets by status for each
+	// VSP fee address, which is the address of the first commitment output
+	// (tx_index 1) of the ticket. The tickets attributed to a VSP either
+	// commit to one of the known fee addresses ($1), or are legacy stake pool
+	// tickets with a multisig stake submission script and a second commitment
+	// output (tx_index 3) for the staker. Unknown fee addresses with fewer
+	// than $4 tickets are omitted. Tickets mined at or below height $2 are
+	// mature. The vote latency is the number of blocks from maturity ($3
+	// blocks after purchase) to the vote.
+	SelectVSPTicketStats = `SELECT fee_address,
+			COUNT(*) FILTER (WHERE pool_status = 0 AND block_height > $2),
+			COUNT(*) FILTER (WHERE pool_status = 0 AND block_height <= $2),
+			COUNT(*) FILTER (WHERE pool_status = 1),
+			COUNT(*) FILTER (WHERE pool_status = 3),
+			COUNT(*) FILTER (WHERE pool_status = 2),
+			COUNT(*) FILTER (WHERE spend_type = 1),
+			COALESCE(AVG(spend_height - block_height - $3)
+				FILTER (WHERE pool_status = 1), 0)::FLOAT8
+		FROM (
+			SELECT fee.script_addresses[1] AS fee_address, tickets.block_height,
+				tickets.pool_status, tickets.spend_type, tickets.spend_height
+			FROM tickets
+			JOIN vouts AS fee ON fee.tx_hash = tickets.tx_hash
+				AND fee.tx_index = 1 AND fee.tx_tree = 1
+			WHERE tickets.is_mainchain
+				AND (fee.script_addresses[1] = ANY($1)
+					OR (tickets.is_multisig AND EXISTS (
+						SELECT 1 FROM vouts
+						WHERE vouts.tx_hash = tickets.tx_hash
+							AND vouts.tx_index = 3 AND vouts.tx_tree = 1)))
+		) AS vsp_tickets
+		GROUP BY fee_address
+		HAVING COUNT(*) >= $4 OR fee_address = ANY($1);`
+)
@@ -0,0 +1,174 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package internal
+
+// These queries relate primarily to the "webhooks", "webhook_addresses" and
+// "webhook_deliveries" tables.
+const (
+	// CreateWebhooksTable creates the webhooks table. The id is a random
+	// string that identifies the webhook to its owner, and the secret is the
+	// HMAC key used to sign deliveries.
+	CreateWebhooksTable = `CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		confirmations INT4 NOT NULL,
+		created TIMESTAMPTZ NOT NULL
+	);`
+
+	// CreateWebhookAddressesTable creates the webhook_addresses table of the
+	// addresses watched by each webhook.
+	CreateWebhookAddressesTable = `CREATE TABLE IF NOT EXISTS webhook_addresses (
+		address TEXT NOT NULL,
+		webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+		PRIMARY KEY (address, webhook_id)
+	);`
+
+	// CreateWebhookDeliveriesTable creates the webhook_deliveries table. Each
+	// row is an event for a webhook and its delivery state. The unique
+	// constraint prevents duplicate events when a block or transaction is
+	// processed more than once, while allowing the same transaction to be
+	// confirmed in different blocks across a reorg.
+	CreateWebhookDeliveriesTable = `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id SERIAL8 PRIMARY KEY,
+		webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+		event TEXT NOT NULL,
+		address TEXT NOT NULL,
+		tx_hash TEXT NOT NULL,
+		block_hash TEXT NOT NULL,
+		block_height INT8 NOT NULL,
+		confirmations INT4 NOT NULL,
+		created TIMESTAMPTZ NOT NULL,
+		status TEXT NOT NULL,
+		attempts INT4 NOT NULL DEFAULT 0,
+		next_attempt TIMESTAMPTZ NOT NULL,
+		response_code INT4 NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		UNIQUE (webhook_id, event, address, tx_hash, block_hash)
+	);`
+
+	IndexWebhookDeliveriesOnPending = `CREATE INDEX ` + IndexOfWebhookDeliveriesTableOnPending +
+		` ON webhook_deliveries(next_attempt) WHERE status = 'pending';`
+	DeindexWebhookDeliveriesOnPending = `DROP INDEX ` + IndexOfWebhookDeliveriesTableOnPending + ` CASCADE;`
+
+	IndexWebhookDeliveriesOnBlockHeight = `CREATE INDEX ` + IndexOfWebhookDeliveriesTableOnBlockHeight +
+		` ON webhook_deliveries(block_height) WHERE event = 'confirmed';`
+	DeindexWebhookDeliveriesOnBlockHeight = `DROP INDEX ` + IndexOfWebhookDeliveriesTableOnBlockHeight + ` CASCADE;`
+
+	IndexWebhookDeliveriesOnWebhookID = `CREATE INDEX ` + IndexOfWebhookDeliveriesTableOnWebhookID +
+		` ON webhook_deliveries(webhook_id, id DESC);`
+	DeindexWebhookDeliveriesOnWebhookID = `DROP INDEX ` + IndexOfWebhookDeliveriesTableOnWebhookID + ` CASCADE;`
+
+	// Webhook registration.
+
+	InsertWebhookRow = `INSERT INTO webhooks (id, url, secret, confirmations, created)
+		VALUES ($1, $2, $3, $4, $5);`
+
+	InsertWebhookAddressRow = `INSERT INTO webhook_addresses (address, webhook_id)
+		VALUES ($1, $2)
+		ON CONFLICT (address, webhook_id) DO NOTHING;`
+
+	SelectWebhook = `SELECT url, confirmations, created FROM webhooks WHERE id = $1;`
+
+	SelectWebhookAddresses = `SELECT address FROM webhook_addresses
+		WHERE webhook_id = $1
+		ORDER BY address;`
+
+	DeleteWebhook = `DELETE FROM webhooks WHERE id = $1;`
+
+	// Delivery creation. All events are inserted as pending with the first
+	// attempt due immediately ($N is the current time).
+
+	// InsertWebhookMempoolDeliveries creates a mempool event for each webhook
+	// watching the address ($1) for the transaction ($2).
+	InsertWebhookMempoolDeliveries = `INSERT INTO webhook_deliveries (webhook_id,
+			event, address, tx_hash, block_hash, block_height, confirmations,
+			created, status, next_attempt)
+		SELECT webhook_id, 'mempool', address, $2, '', 0, 0, $3::TIMESTAMPTZ,
+			'pending', $3::TIMESTAMPTZ
+		FROM webhook_addresses
+		WHERE address = $1
+		ON CONFLICT (webhook_id, event, address, tx_hash, block_hash) DO NOTHING;`
+
+	// InsertWebhookConfirmedDeliveries creates a confirmed event for each
+	// webhook watching an address that is funded or spent by a transaction in
+	// the main chain block with hash $1.
+	InsertWebhookConfirmedDeliveries = `INSERT INTO webhook_deliveries (webhook_id,
+			event, address, tx_hash, block_hash, block_height, confirmations,
+			created, status, next_attempt)
+		SELECT DISTINCT webhook_addresses.webhook_id, 'confirmed',
+			addresses.address, transactions.tx_hash, transactions.block_hash,
+			transactions.block_height, 1, $2::TIMESTAMPTZ, 'pending', $2::TIMESTAMPTZ
+		FROM transactions
+		JOIN addresses ON addresses.tx_hash = transactions.tx_hash
+		JOIN webhook_addresses ON webhook_addresses.address = addresses.address
+		WHERE transactions.block_hash = $1 AND transactions.is_mainchain
+		ON CONFLICT (webhook_id, event, address, tx_hash, block_hash) DO NOTHING;`
+
+	// InsertWebhookConfirmationsDeliveries creates a confirmations event for
+	// each confirmed event of a webhook requiring more than one confirmation
+	// whose transaction reaches the required number of confirmations with the
+	// main chain block at height $1.
+	InsertWebhookConfirmationsDeliveries = `INSERT INTO webhook_deliveries (webhook_id,
+			event, address, tx_hash, block_hash, block_height, confirmations,
+			created, status, next_attempt)
+		SELECT d.webhook_id, 'confirmations', d.address, d.tx_hash, d.block_hash,
+			d.block_height, webhooks.confirmations, $2::TIMESTAMPTZ, 'pending', $2::TIMESTAMPTZ
+		FROM webhook_deliveries d
+		JOIN webhooks ON webhooks.id = d.webhook_id
+		JOIN blocks ON blocks.hash = d.block_hash AND blocks.is_mainchain
+		WHERE d.event = 'confirmed' AND webhooks.confirmations > 1
+			AND d.block_height = $1::INT8 - webhooks.confirmations + 1
+		ON CONFLICT (webhook_id, event, address, tx_hash, block_hash) DO NOTHING;`
+
+	// InsertWebhookReorgDeliveries creates a reorg event for each confirmed
+	// event in the orphaned blocks with the hashes in the array $1.
+	InsertWebhookReorgDeliveries = `INSERT INTO webhook_deliveries (webhook_id,
+			event, address, tx_hash, block_hash, block_height, confirmations,
+			created, status, next_attempt)
+		SELECT webhook_id, 'reorg', address, tx_hash, block_hash, block_height,
+			0, $2::TIMESTAMPTZ, 'pending', $2::TIMESTAMPTZ
+		FROM webhook_deliveries
+		WHERE event = 'confirmed' AND block_hash = ANY($1)
+		ON CONFLICT (webhook_id, event, address, tx_hash, block_hash) DO NOTHING;`
+
+	// Delivery dispatch.
+
+	selectWebhookDeliveriesColumns = `SELECT id, webhook_id, event, address,
+		tx_hash, block_hash, block_height, confirmations, created, status,
+		attempts, next_attempt, response_code, last_error
+		FROM webhook_deliveries `
+
+	// SelectPendingWebhookDeliveries selects up to $2 pending deliveries due
+	// by the time $1, oldest first, with the webhook URL and secret.
+	SelectPendingWebhookDeliveries = `SELECT d.id, d.webhook_id, d.event,
+			d.address, d.tx_hash, d.block_hash, d.block_height, d.confirmations,
+			d.created, d.status, d.attempts, d.next_attempt, d.response_code,
+			d.last_error, webhooks.url, webhooks.secret
+		FROM webhook_deliveries d
+		JOIN webhooks ON webhooks.id = d.webhook_id
+		WHERE d.status = 'pending' AND d.next_attempt <= $1
+		ORDER BY d.id
+		LIMIT $2;`
+
+	UpdateWebhookDelivery = `UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt = $4, response_code = $5,
+			last_error = $6
+		WHERE id = $1;`
+
+	SelectWebhookDeliveries = selectWebhookDeliveriesColumns +
+		`WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3;`
+
+	SelectWebhookDeliveriesCount = `SELECT COUNT(*) FROM webhook_deliveries
+		WHERE webhook_id = $1;`
+
+	// ReplayWebhookDeliveries resets the deliveries for webhook $1 created at
+	// or after the time $2 so that they are sent again starting at time $3.
+	ReplayWebhookDeliveries = `UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt = $3,
+			response_code = 0, last_error = ''
+		WHERE webhook_id = $1 AND created >= $2;`
+)
@@ -0,0 +1,289 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// addressLabels is the in-memory copy of the address_labels table, with the
+// built-in labels of the project fund and configured exchange and VSP fee
+// addresses.
+type addressLabels struct {
+	mtx     sync.RWMutex
+	labels  map[string]*dbtypes.AddressLabel
+	builtIn map[string]*dbtypes.AddressLabel
+}
+
+func newAddressLabels(devAddress string, exchangeAddrs, vspAddrs map[string]string) *addressLabels {
+	builtIn := make(map[string]*dbtypes.AddressLabel, len(exchangeAddrs)+len(vspAddrs)+1)
+	for addr, name := range vspAddrs {
+		builtIn[addr] = &dbtypes.AddressLabel{
+			Address:  addr,
+			Label:    name,
+			Category: dbtypes.AddressTagVSP,
+			Source:   dbtypes.AddressLabelSourceBuiltIn,
+		}
+	}
+	for addr, name := range exchangeAddrs {
+		builtIn[addr] = &dbtypes.AddressLabel{
+			Address:  addr,
+			Label:    name,
+			Category: dbtypes.AddressTagExchange,
+			Source:   dbtypes.AddressLabelSourceBuiltIn,
+		}
+	}
+	if devAddress != "" {
+		builtIn[devAddress] = &dbtypes.AddressLabel{
+			Address:  devAddress,
+			Label:    "Legacy treasury",
+			Category: dbtypes.AddressTagDevFund,
+			Source:   dbtypes.AddressLabelSourceBuiltIn,
+		}
+	}
+	return &addressLabels{
+		labels:  make(map[string]*dbtypes.AddressLabel),
+		builtIn: builtIn,
+	}
+}
+
+// get returns the label of an address, or nil if it has none. The caller must
+// hold the lock.
+func (al *addressLabels) get(addr string) *dbtypes.AddressLabel {
+	if l, ok := al.labels[addr]; ok {
+		return l
+	}
+	return al.builtIn[addr]
+}
+
+// all returns every label, including the built-in labels of addresses without
+// a stored label, sorted by label and address.
+func (al *addressLabels) all() []*dbtypes.AddressLabel {
+	al.mtx.RLock()
+	labels := make([]*dbtypes.AddressLabel, 0, len(al.labels)+len(al.builtIn))
+	for _, l := range al.labels {
+		labels = append(labels, l)
+	}
+	for addr, l := range al.builtIn {
+		if _, ok := al.labels[addr]; !ok {
+			labels = append(labels, l)
+		}
+	}
+	al.mtx.RUnlock()
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Label == labels[j].Label {
+			return labels[i].Address < labels[j].Address
+		}
+		return labels[i].Label < labels[j].Label
+	})
+	return labels
+}
+
+// StoreFileAddressLabels replaces the labels from the labels file with the
+// given labels, keeping the labels set through the admin API.
+func StoreFileAddressLabels(ctx context.Context, db *sql.DB, labels []*dbtypes.AddressLabel, updated time.Time) error {
+	dbtx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbtx.Rollback() //nolint:errcheck
+
+	if _, err = dbtx.ExecContext(ctx, internal.DeleteFileAddressLabels); err != nil {
+		return err
+	}
+	stmt, err := dbtx.PrepareContext(ctx, internal.InsertFileAddressLabel)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, l := range labels {
+		if _, err = stmt.ExecContext(ctx, l.Address, l.Label, l.Category, updated); err != nil {
+			return err
+		}
+	}
+	return dbtx.Commit()
+}
+
+// RetrieveAddressLabels retrieves all rows of the address_labels table.
+func RetrieveAddressLabels(ctx context.Context, db *sql.DB) ([]*dbtypes.AddressLabel, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAddressLabels)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var labels []*dbtypes.AddressLabel
+	for rows.Next() {
+		var l dbtypes.AddressLabel
+		var updated time.Time
+		if err = rows.Scan(&l.Address, &l.Label, &l.Category, &l.Source, &updated); err != nil {
+			return nil, err
+		}
+		l.Updated = &dbtypes.TimeDef{T: updated}
+		labels = append(labels, &l)
+	}
+	return labels, rows.Err()
+}
+
+// UpsertAddressLabel inserts or replaces the label of an address.
+func UpsertAddressLabel(ctx context.Context, db *sql.DB, l *dbtypes.AddressLabel) error {
+	_, err := db.ExecContext(ctx, internal.UpsertAddressLabel, l.Address,
+		l.Label, l.Category, l.Source, l.Updated.T)
+	return err
+}
+
+// DeleteAddressLabel deletes the label of an address. The returned bool
+// indicates if the address had a label.
+func DeleteAddressLabel(ctx context.Context, db *sql.DB, addr string) (bool, error) {
+	res, err := db.ExecContext(ctx, internal.DeleteAddressLabel, addr)
+	if err != nil {
+		return false, err
+	}
+	N, err := res.RowsAffected()
+	return N > 0, err
+}
+
+// LoadAddressLabels loads the address labels into memory, first replacing the
+// stored labels from the labels file with the labels of file, if it is not
+// nil. LoadAddressLabels should be called on startup, after the tables are
+// created.
+func (pgb *ChainDB) LoadAddressLabels(file *dbtypes.AddressLabelsFile) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	if file != nil {
+		err := StoreFileAddressLabels(ctx, pgb.db, file.Labels, time.Now())
+		if err != nil {
+			return pgb.replaceCancelError(err)
+		}
+	}
+
+	labels, err := RetrieveAddressLabels(ctx, pgb.db)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	m := make(map[string]*dbtypes.AddressLabel, len(labels))
+	for _, l := range labels {
+		m[l.Address] = l
+	}
+	pgb.labels.mtx.Lock()
+	pgb.labels.labels = m
+	pgb.labels.mtx.Unlock()
+
+	log.Infof("Loaded %d address labels.", len(labels))
+	return nil
+}
+
+// AddressLabel returns the label of an address, or nil if it has none.
+func (pgb *ChainDB) AddressLabel(addr string) *dbtypes.AddressLabel {
+	pgb.labels.mtx.RLock()
+	defer pgb.labels.mtx.RUnlock()
+	return pgb.labels.get(addr)
+}
+
+// AddressLabels returns the labels of the given addresses that have one,
+// keyed by address.
+func (pgb *ChainDB) AddressLabels(addrs []string) map[string]*dbtypes.AddressLabel {
+	labels := make(map[string]*dbtypes.AddressLabel)
+	pgb.labels.mtx.RLock()
+	defer pgb.labels.mtx.RUnlock()
+	for _, addr := range addrs {
+		if l := pgb.labels.get(addr); l != nil {
+			labels[addr] = l
+		}
+	}
+	return labels
+}
+
+// addressLabelList returns the labels of the given addresses that have one,
+// in the order of the addresses.
+func (pgb *ChainDB) addressLabelList(addrs []string) []*dbtypes.AddressLabel {
+	var labels []*dbtypes.AddressLabel
+	pgb.labels.mtx.RLock()
+	defer pgb.labels.mtx.RUnlock()
+	for _, addr := range addrs {
+		if l := pgb.labels.get(addr); l != nil {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// AllAddressLabels returns every address label in the given category, or in
+// all categories if category is empty, sorted by label and address.
+func (pgb *ChainDB) AllAddressLabels(category string) []*dbtypes.AddressLabel {
+	labels := pgb.labels.all()
+	if category == "" {
+		return labels
+	}
+	filtered := labels[:0]
+	for _, l := range labels {
+		if l.Category == category {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+// SearchAddressLabels returns the address labels that contain the query,
+// ignoring case, sorted by label and address.
+func (pgb *ChainDB) SearchAddressLabels(query string) []*dbtypes.AddressLabel {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var matches []*dbtypes.AddressLabel
+	for _, l := range pgb.labels.all() {
+		if strings.Contains(strings.ToLower(l.Label), query) {
+			matches = append(matches, l)
+		}
+	}
+	return matches
+}
+
+// SetAddressLabel stores the label of an address, replacing any existing
+// label. The label's source and update time are set.
+func (pgb *ChainDB) SetAddressLabel(l *dbtypes.AddressLabel) error {
+	l.Source = dbtypes.AddressLabelSourceAPI
+	l.Updated = &dbtypes.TimeDef{T: time.Now()}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	if err := UpsertAddressLabel(ctx, pgb.db, l); err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	pgb.labels.mtx.Lock()
+	pgb.labels.labels[l.Address] = l
+	pgb.labels.mtx.Unlock()
+	return nil
+}
+
+// DeleteAddressLabel deletes the stored label of an address. The returned bool
+// indicates if the address had a stored label. A label deleted from the labels
+// file is restored the next time the file is loaded, and a built-in label
+// applies again once the stored label is deleted.
+func (pgb *ChainDB) DeleteAddressLabel(addr string) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	found, err := DeleteAddressLabel(ctx, pgb.db, addr)
+	if err != nil {
+		return false, pgb.replaceCancelError(err)
+	}
+
+	pgb.labels.mtx.Lock()
+	delete(pgb.labels.labels, addr)
+	pgb.labels.mtx.Unlock()
+	return found, nil
+}
@@ -0,0 +1,69 @@
+package dcrpg
+
+import (
+	"testing"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+func TestAddressLabels(t *testing.T) {
+	const devAddr, exAddr, vspAddr = "Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx",
+		"DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu", "DsfD7KYsJWdhkpsLp1fCSn3RnJt3fzXn7bq"
+
+	pgb := &ChainDB{
+		labels: newAddressLabels(devAddr, map[string]string{exAddr: "Exchange A"}, nil),
+	}
+	pgb.labels.labels[exAddr] = &dbtypes.AddressLabel{Address: exAddr,
+		Label: "Exchange B", Category: dbtypes.AddressTagExchange,
+		Source: dbtypes.AddressLabelSourceFile}
+	pgb.labels.labels[vspAddr] = &dbtypes.AddressLabel{Address: vspAddr,
+		Label: "Some VSP", Category: dbtypes.AddressTagVSP,
+		Source: dbtypes.AddressLabelSourceAPI}
+
+	// A stored label takes precedence over a built-in label.
+	if l := pgb.AddressLabel(exAddr); l == nil || l.Label != "Exchange B" {
+		t.Errorf("expected stored label for %s, got %+v", exAddr, l)
+	}
+	if l := pgb.AddressLabel(devAddr); l == nil || l.Category != dbtypes.AddressTagDevFund ||
+		l.Source != dbtypes.AddressLabelSourceBuiltIn {
+		t.Errorf("expected built-in label for %s, got %+v", devAddr, l)
+	}
+	if l := pgb.AddressLabel("DsSomeOtherAddress"); l != nil {
+		t.Errorf("expected no label, got %+v", l)
+	}
+
+	labels := pgb.AddressLabels([]string{vspAddr, "DsSomeOtherAddress", devAddr})
+	if len(labels) != 2 || labels[vspAddr] == nil || labels[devAddr] == nil {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	all := pgb.AllAddressLabels("")
+	var got []string
+	for _, l := range all {
+		got = append(got, l.Label)
+	}
+	want := []string{"Exchange B", "Legacy treasury", "Some VSP"}
+	if len(got) != len(want) {
+		t.Fatalf("expected labels %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected labels %v, got %v", want, got)
+			break
+		}
+	}
+
+	if vsps := pgb.AllAddressLabels(dbtypes.AddressTagVSP); len(vsps) != 1 || vsps[0].Address != vspAddr {
+		t.Errorf("unexpected VSP labels %v", vsps)
+	}
+
+	if matches := pgb.SearchAddressLabels(" exCHANGE "); len(matches) != 1 || matches[0].Address != exAddr {
+		t.Errorf("unexpected search matches %v", matches)
+	}
+	if matches := pgb.SearchAddressLabels("e"); len(matches) != 3 {
+		t.Errorf("expected 3 search matches, got %d", len(matches))
+	}
+	if matches := pgb.SearchAddressLabels(" "); matches != nil {
+		t.Errorf("expected no matches for an empty search, got %v", matches)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/chappjc/trylock"
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/lib/pq"
+)
+
+const (
+	// confirmationTimesRefreshInterval is the number of blocks between
+	// refreshes of the confirmation time chart data.
+	confirmationTimesRefreshInterval = 6
+
+	// confirmationTimesWindow is how far back the mempool transactions are
+	// included in the confirmation time chart data.
+	confirmationTimesWindow = 30 * 24 * time.Hour
+)
+
+// confirmationFeeRates are the minimum fee rates in atoms/kB of the buckets of
+// the confirmation time chart data. The default relay fee is 1e4 atoms/kB.
+var confirmationFeeRates = []int64{0, 1e4, 2e4, 5e4, 1e5, 2e5, 5e5, 1e6}
+
+// confirmationTimesState tracks the refreshes of the confirmation time chart
+// data.
+type confirmationTimesState struct {
+	refreshMtx trylock.Mutex
+	height     int64
+	refreshed  bool
+}
+
+func nullTimeDef(t dbtypes.TimeDef) sql.NullTime {
+	return sql.NullTime{Time: t.T.UTC(), Valid: !t.T.IsZero()}
+}
+
+// UpsertMempoolHistory stores the mempool transactions, or updates the
+// disposition of the transactions already stored.
+func UpsertMempoolHistory(ctx context.Context, db *sql.DB, txs []*dbtypes.MempoolHistoryTx) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, internal.UpsertMempoolHistory)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, tx := range txs {
+		_, err = stmt.ExecContext(ctx, tx.TxHash, tx.TxType, tx.Size, tx.Fee,
+			tx.FeeRate, int64(tx.Expiry), tx.FirstSeen, tx.LastSeen,
+			tx.Disposition, nullTimeDef(tx.Resolved),
+			sql.NullString{String: tx.BlockHash, Valid: tx.BlockHash != ""},
+			sql.NullInt64{Int64: tx.BlockHeight, Valid: tx.BlockHash != ""},
+			sql.NullString{String: tx.ConflictTx, Valid: tx.ConflictTx != ""})
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// RetrievePendingMempoolHistory retrieves the transactions that were in
+// mempool when last observed. The pending transactions that were since mined
+// in a main chain block, such as while dcrdata was not running, are first
+// resolved as mined, and are not retrieved.
+func RetrievePendingMempoolHistory(ctx context.Context, db *sql.DB) ([]*dbtypes.MempoolHistoryTx, error) {
+	_, err := db.ExecContext(ctx, internal.ResolveMinedMempoolHistory,
+		dbtypes.MempoolDispositionMined, dbtypes.MempoolDispositionMempool)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectMempoolHistoryByDisposition,
+		dbtypes.MempoolDispositionMempool)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var txs []*dbtypes.MempoolHistoryTx
+	for rows.Next() {
+		tx := new(dbtypes.MempoolHistoryTx)
+		var expiry int64
+		var resolved sql.NullTime
+		var blockHash, conflictTx sql.NullString
+		var blockHeight sql.NullInt64
+		err = rows.Scan(&tx.TxHash, &tx.TxType, &tx.Size, &tx.Fee, &tx.FeeRate,
+			&expiry, &tx.FirstSeen, &tx.LastSeen, &tx.Disposition, &resolved,
+			&blockHash, &blockHeight, &conflictTx)
+		if err != nil {
+			return nil, err
+		}
+		tx.Expiry = uint32(expiry)
+		if resolved.Valid {
+			tx.Resolved = dbtypes.NewTimeDef(resolved.Time)
+		}
+		tx.BlockHash = blockHash.String
+		tx.BlockHeight = blockHeight.Int64
+		tx.ConflictTx = conflictTx.String
+		txs = append(txs, tx)
+	}
+	return txs, rows.Err()
+}
+
+// RetrieveMempoolFirstSeen retrieves the time the transaction with the given
+// hash was first seen in mempool. sql.ErrNoRows is returned if the
+// transaction was not observed in mempool.
+func RetrieveMempoolFirstSeen(ctx context.Context, db *sql.DB, txHash string) (time.Time, error) {
+	var firstSeen dbtypes.TimeDef
+	err := db.QueryRowContext(ctx, internal.SelectMempoolFirstSeen, txHash).Scan(&firstSeen)
+	return firstSeen.T, err
+}
+
+// RetrieveConfirmationTimeBuckets retrieves the number and median time to
+// confirmation of the regular transactions first seen in mempool after the
+// given time and since mined, in buckets of fee rate. minFeeRates is the
+// ascending list of the minimum fee rates of the buckets. Every bucket is
+// returned, including the empty buckets.
+func RetrieveConfirmationTimeBuckets(ctx context.Context, db *sql.DB, minFeeRates []int64,
+	since time.Time) ([]dbtypes.ConfirmationTimeBucket, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectConfirmationTimeBuckets,
+		pq.Array(minFeeRates), dbtypes.MempoolDispositionMined, since)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	buckets := make([]dbtypes.ConfirmationTimeBucket, len(minFeeRates))
+	for i, minFeeRate := range minFeeRates {
+		buckets[i].MinFeeRate = minFeeRate
+	}
+	for rows.Next() {
+		var bucket int
+		var count int64
+		var median sql.NullFloat64
+		if err = rows.Scan(&bucket, &count, &median); err != nil {
+			return nil, err
+		}
+		// width_bucket returns 0 for a fee rate below the first minimum, which
+		// is not possible for a minimum of zero.
+		if bucket < 1 || bucket > len(buckets) {
+			continue
+		}
+		buckets[bucket-1].Count = count
+		buckets[bucket-1].MedianTime = median.Float64
+	}
+	return buckets, rows.Err()
+}
+
+// StoreMempoolHistory stores the mempool transactions, or updates their
+// dispositions.
+func (pgb *ChainDB) StoreMempoolHistory(txs []*dbtypes.MempoolHistoryTx) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpsertMempoolHistory(ctx, pgb.db, txs)
+	return pgb.replaceCancelError(err)
+}
+
+// PendingMempoolHistory retrieves the transactions that were in mempool when
+// last observed, and were not since mined in a main chain block.
+func (pgb *ChainDB) PendingMempoolHistory() ([]*dbtypes.MempoolHistoryTx, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	txs, err := RetrievePendingMempoolHistory(ctx, pgb.db)
+	return txs, pgb.replaceCancelError(err)
+}
+
+// MempoolFirstSeen retrieves the time the transaction with the given hash was
+// first seen in mempool. The returned bool indicates if the transaction was
+// observed in mempool.
+func (pgb *ChainDB) MempoolFirstSeen(txHash string) (time.Time, bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	firstSeen, err := RetrieveMempoolFirstSeen(ctx, pgb.db, txHash)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	return firstSeen, err == nil, pgb.replaceCancelError(err)
+}
+
+// RefreshConfirmationTimes refreshes the confirmation time chart data every
+// confirmationTimesRefreshInterval blocks, and on the first block after
+// startup. It returns without waiting if a refresh is already running.
+// RefreshConfirmationTimes satisfies the Saver of a blockdata.BlockTrigger.
+func (pgb *ChainDB) RefreshConfirmationTimes(_ string, height uint32) error {
+	if pgb.charts == nil {
+		return nil
+	}
+	if !pgb.confirmTimes.refreshMtx.TryLock() {
+		log.Debugf("Confirmation times refresh already running. Skipping block %d.", height)
+		return nil
+	}
+	defer pgb.confirmTimes.refreshMtx.Unlock()
+
+	if pgb.confirmTimes.refreshed &&
+		int64(height) < pgb.confirmTimes.height+confirmationTimesRefreshInterval {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	buckets, err := RetrieveConfirmationTimeBuckets(ctx, pgb.db, confirmationFeeRates,
+		time.Now().Add(-confirmationTimesWindow))
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	pgb.confirmTimes.height = int64(height)
+	pgb.confirmTimes.refreshed = true
+
+	pgb.charts.SetConfirmationTimes(buckets)
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/lib/pq"
+)
+
+// maxAddressMisses is the maximum number of misses of an address's tickets
+// retrieved by AddressMissCauses.
+const maxAddressMisses = 1000
+
+// VoteSightings provides the times votes were first seen in mempool. It is
+// satisfied by *mempool.MempoolMonitor.
+type VoteSightings interface {
+	// VoteFirstSeen returns the time the vote of the ticket on the block was
+	// first seen in mempool, and false if the vote was not seen.
+	VoteFirstSeen(ticket, blockHash string) (time.Time, bool)
+	// WatchingSince returns the time since which mempool is watched.
+	WatchingSince() time.Time
+}
+
+// UseVoteSightings assigns the VoteSightings used by AttributeMisses to
+// attribute the missed votes to their cause.
+func (pgb *ChainDB) UseVoteSightings(vs VoteSightings) {
+	pgb.voteSightings = vs
+}
+
+// UpsertMissCauses stores the causes of the missed votes.
+func UpsertMissCauses(ctx context.Context, db *sql.DB, misses []*dbtypes.MissedVote) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, internal.UpsertMissCause)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, m := range misses {
+		voteSeen := sql.NullTime{Time: time.Unix(m.VoteSeen, 0).UTC(), Valid: m.VoteSeen != 0}
+		_, err = stmt.ExecContext(ctx, m.TicketHash, m.BlockHash, m.Cause, voteSeen,
+			sql.NullString{String: m.VSPFeeAddress, Valid: m.VSPFeeAddress != ""})
+		if err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// retrieveTicketsFeeAddress retrieves the address of the first commitment
+// output of each of the main chain tickets, and whether the ticket is a legacy
+// stake pool ticket, by ticket hash.
+func retrieveTicketsFeeAddress(ctx context.Context, db *sql.DB, tickets []string) (map[string]string, map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTicketsFeeAddress, pq.Array(tickets))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRows(rows)
+
+	feeAddrs := make(map[string]string, len(tickets))
+	legacy := make(map[string]bool, len(tickets))
+	for rows.Next() {
+		var ticket string
+		var feeAddr sql.NullString
+		var isLegacy sql.NullBool
+		if err = rows.Scan(&ticket, &feeAddr, &isLegacy); err != nil {
+			return nil, nil, err
+		}
+		feeAddrs[ticket] = feeAddr.String
+		legacy[ticket] = isLegacy.Bool
+	}
+	return feeAddrs, legacy, rows.Err()
+}
+
+// RetrieveMissedVotes retrieves the missed votes with their cause selected by
+// the query, one of the internal.SelectMissedVotes* queries, with its args
+// following the cause of the misses without an attributed cause.
+func RetrieveMissedVotes(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*dbtypes.MissedVote, error) {
+	rows, err := db.QueryContext(ctx, query,
+		append([]interface{}{dbtypes.MissCauseUnknown}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var misses []*dbtypes.MissedVote
+	for rows.Next() {
+		m := new(dbtypes.MissedVote)
+		err = rows.Scan(&m.TicketHash, &m.BlockHash, &m.BlockHeight, &m.Cause,
+			&m.VoteSeen, &m.VSPFeeAddress)
+		if err != nil {
+			return nil, err
+		}
+		misses = append(misses, m)
+	}
+	return misses, rows.Err()
+}
+
+// missCause is the cause of a missed vote given whether the block with the miss
+// approved its parent, the time the ticket's vote on the parent was first seen
+// in mempool, zero if it was not seen, the time of the block with the miss, and
+// whether the ticket is of a VSP. A vote seen after the time of the block was
+// late, and one seen before was omitted by the miner.
+func missCause(parentApproved bool, voteSeen, blockTime time.Time, vspTicket bool) string {
+	switch {
+	case !parentApproved:
+		return dbtypes.MissCauseDisapproved
+	case !voteSeen.IsZero() && voteSeen.After(blockTime):
+		return dbtypes.MissCauseLate
+	case !voteSeen.IsZero():
+		return dbtypes.MissCauseOmitted
+	case vspTicket:
+		return dbtypes.MissCauseVSPOffline
+	default:
+		return dbtypes.MissCauseNotSeen
+	}
+}
+
+// AttributeMisses attributes the missed votes of the main chain block below
+// the height to their cause, and stores them. The misses of the block before
+// the new block are attributed so that the votes arriving late, after the
+// block with the misses, are seen. A ticket's vote is of a VSP if it commits
+// to a fee address labeled as a VSP address, or is a legacy stake pool ticket.
+// The misses are not attributed if there are no VoteSightings, or if the
+// parent block of the misses was mined before mempool was watched.
+// AttributeMisses satisfies the Saver of a blockdata.BlockTrigger.
+func (pgb *ChainDB) AttributeMisses(_ string, height uint32) error {
+	if pgb.voteSightings == nil {
+		return nil
+	}
+	missHeight := int64(height) - 1
+	if missHeight <= pgb.chainParams.StakeValidationHeight {
+		return nil
+	}
+	parentTime, err := pgb.BlockTimeByHeight(missHeight - 1)
+	if err != nil {
+		return fmt.Errorf("BlockTimeByHeight: %w", err)
+	}
+	if time.Unix(parentTime, 0).Before(pgb.voteSightings.WatchingSince()) {
+		return nil
+	}
+
+	blockHash, err := pgb.BlockHash(missHeight)
+	if err != nil {
+		return fmt.Errorf("BlockHash: %w", err)
+	}
+	blockTime, err := pgb.BlockTimeByHeight(missHeight)
+	if err != nil {
+		return fmt.Errorf("BlockTimeByHeight: %w", err)
+	}
+	tickets, err := pgb.BlockMissedVotes(blockHash)
+	if err != nil {
+		return fmt.Errorf("BlockMissedVotes: %w", err)
+	}
+	if len(tickets) == 0 {
+		return nil
+	}
+	parentHash, err := pgb.BlockHash(missHeight - 1)
+	if err != nil {
+		return fmt.Errorf("BlockHash: %w", err)
+	}
+	parentApproved, _, err := pgb.BlockFlags(parentHash)
+	if err != nil {
+		return fmt.Errorf("BlockFlags: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	feeAddrs, legacy, err := retrieveTicketsFeeAddress(ctx, pgb.db, tickets)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	addrs := make([]string, 0, len(feeAddrs))
+	for _, addr := range feeAddrs {
+		addrs = append(addrs, addr)
+	}
+	labels := pgb.AddressLabels(addrs)
+
+	misses := make([]*dbtypes.MissedVote, 0, len(tickets))
+	for _, ticket := range tickets {
+		m := &dbtypes.MissedVote{
+			TicketHash:  ticket,
+			BlockHash:   blockHash,
+			BlockHeight: missHeight,
+		}
+		feeAddr := feeAddrs[ticket]
+		if l := labels[feeAddr]; legacy[ticket] || (l != nil && l.Category == dbtypes.AddressTagVSP) {
+			m.VSPFeeAddress = feeAddr
+		}
+		seen, voteSeen := pgb.voteSightings.VoteFirstSeen(ticket, parentHash)
+		if voteSeen {
+			m.VoteSeen = seen.Unix()
+		} else {
+			seen = time.Time{}
+		}
+		m.Cause = missCause(parentApproved, seen, time.Unix(blockTime, 0), m.VSPFeeAddress != "")
+		misses = append(misses, m)
+	}
+	if err = UpsertMissCauses(ctx, pgb.db, misses); err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	log.Debugf("Attributed %d missed votes in block %d.", len(misses), missHeight)
+	return nil
+}
+
+// BlockMissCauses retrieves the missed votes of the block with their cause.
+func (pgb *ChainDB) BlockMissCauses(blockHash string) ([]*dbtypes.MissedVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	misses, err := RetrieveMissedVotes(ctx, pgb.db, internal.SelectMissedVotesInBlock, blockHash)
+	return misses, pgb.replaceCancelError(err)
+}
+
+// TicketMissCauses retrieves the main chain missed votes of the ticket with
+// their cause.
+func (pgb *ChainDB) TicketMissCauses(ticketHash string) ([]*dbtypes.MissedVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	misses, err := RetrieveMissedVotes(ctx, pgb.db, internal.SelectMissedVotesForTicket, ticketHash)
+	return misses, pgb.replaceCancelError(err)
+}
+
+// AddressMissCauses retrieves the most recent main chain missed votes, up to
+// 1000, of the tickets with the address as the stake submission or a
+// commitment address, with their cause.
+func (pgb *ChainDB) AddressMissCauses(address string) ([]*dbtypes.MissedVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	misses, err := RetrieveMissedVotes(ctx, pgb.db, internal.SelectMissedVotesForAddress,
+		address, maxAddressMisses)
+	return misses, pgb.replaceCancelError(err)
+}
@@ -0,0 +1,37 @@
+package dcrpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+func TestMissCause(t *testing.T) {
+	blockTime := time.Unix(1600000000, 0)
+	early := blockTime.Add(-time.Minute)
+	late := blockTime.Add(time.Second)
+	var unseen time.Time
+	tests := []struct {
+		parentApproved bool
+		voteSeen       time.Time
+		vspTicket      bool
+		want           string
+	}{
+		{false, late, true, dbtypes.MissCauseDisapproved},
+		{false, unseen, false, dbtypes.MissCauseDisapproved},
+		{true, late, true, dbtypes.MissCauseLate},
+		{true, late, false, dbtypes.MissCauseLate},
+		{true, early, true, dbtypes.MissCauseOmitted},
+		{true, early, false, dbtypes.MissCauseOmitted},
+		{true, blockTime, false, dbtypes.MissCauseOmitted},
+		{true, unseen, true, dbtypes.MissCauseVSPOffline},
+		{true, unseen, false, dbtypes.MissCauseNotSeen},
+	}
+	for _, tt := range tests {
+		if got := missCause(tt.parentApproved, tt.voteSeen, blockTime, tt.vspTicket); got != tt.want {
+			t.Errorf("missCause(%v, %v, %v) = %s, expected %s", tt.parentApproved,
+				tt.voteSeen, tt.vspTicket, got, tt.want)
+		}
+	}
+}
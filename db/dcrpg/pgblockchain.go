@@ -258,6 +258,7 @@ type ChainDB struct {
 	queryTimeout       time.Duration
 	db                 *sql.DB
 	mp                 rpcutils.MempoolAddressChecker
+	voteSightings      VoteSightings
 	chainParams        *chaincfg.Params
 	devAddress         string
 	dupChecks          bool
@@ -277,6 +278,12 @@ type ChainDB struct {
 	deployments        *ChainDeployments
 	piparser           ProposalsFetcher
 	proposalsSync      lastSync
+	labels             *addressLabels
+	treasuryPayees     map[string]string
+	richList           richListState
+	confirmTimes       confirmationTimesState
+	vspStats           vspStatsState
+	charts             *cache.ChartData
 	cockroach          bool
 	MPC                *mempool.MempoolDataCache
 	// BlockCache stores apitypes.BlockDataBasic and apitypes.StakeInfoExtended
@@ -473,6 +480,15 @@ type ChainDBCfg struct {
 	DevPrefetch, HidePGConfig         bool
 	AddrCacheRowCap, AddrCacheAddrCap int
 	AddrCacheUTXOByteCap              int
+	// ExchangeAddresses maps the addresses of known exchanges to the exchange
+	// names, which label the addresses unless they have a stored label.
+	ExchangeAddresses map[string]string
+	// VSPFeeAddresses maps the fee addresses of known VSPs to the VSP names,
+	// which label the addresses unless they have a stored label.
+	VSPFeeAddresses map[string]string
+	// TreasuryPayees maps the payee addresses of treasury spends to the
+	// tokens of the Politeia proposals they are paid for.
+	TreasuryPayees map[string]string
 }
 
 // NewChainDB constructs a cancellation-capable ChainDB for the given connection
@@ -585,7 +601,7 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		}
 		// Do upgrades required by meta table versioning.
 		log.Infof("DB schema version %v upgrading to version %v", dbVer, targetDatabaseVersion)
-		upgrader := NewUpgrader(ctx, db, client, stakeDB)
+		upgrader := NewUpgrader(ctx, db, client, stakeDB, params)
 		success, err := upgrader.UpgradeDatabase()
 		if err != nil {
 			return nil, fmt.Errorf("failed to upgrade database: %v", err)
@@ -755,12 +771,15 @@ func NewChainDB(ctx context.Context, cfg *ChainDBCfg, stakeDB *stakedb.StakeData
 		mixSetDiffs:        make(map[uint32]int64),
 		deployments:        new(ChainDeployments),
 		piparser:           parser,
-		cockroach:          cockroach,
-		MPC:                new(mempool.MempoolDataCache),
-		BlockCache:         apitypes.NewAPICache(1e4),
-		heightClients:      make([]chan uint32, 0),
-		shutdownDcrdata:    shutdown,
-		Client:             client,
+		labels: newAddressLabels(projectFundAddress, cfg.ExchangeAddresses,
+			cfg.VSPFeeAddresses),
+		treasuryPayees:  cfg.TreasuryPayees,
+		cockroach:       cockroach,
+		MPC:             new(mempool.MempoolDataCache),
+		BlockCache:      apitypes.NewAPICache(1e4),
+		heightClients:   make([]chan uint32, 0),
+		shutdownDcrdata: shutdown,
+		Client:          client,
 	}
 	chainDB.lastExplorerBlock.difficulties = make(map[int64]float64)
 
@@ -962,6 +981,9 @@ func (pgb *ChainDB) BlockChainDbIDNoCancel(hash string) (dbID uint64, err error)
 // RegisterCharts registers chart data fetchers and appenders with the provided
 // ChartData.
 func (pgb *ChainDB) RegisterCharts(charts *cache.ChartData) {
+	// The address distribution chart is set by RefreshRichList.
+	pgb.charts = charts
+
 	charts.AddUpdater(cache.ChartUpdater{
 		Tag:      "basic blocks",
 		Fetcher:  pgb.chartBlocks,
@@ -1305,11 +1327,20 @@ func (pgb *ChainDB) PiProposalsHistory() (int64, error) {
 }
 
 // ProposalVotes retrieves all the votes data associated with the provided token.
+// If no votes were parsed from the proposals repository, the votes data is
+// that of the hourly vote tallies polled from Politeia.
 func (pgb *ChainDB) ProposalVotes(proposalToken string) (*dbtypes.ProposalChartsData, error) {
 	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
 	defer cancel()
 	chartsData, err := retrieveProposalVotesData(ctx, pgb.db, proposalToken)
-	return chartsData, pgb.replaceCancelError(err)
+	if err != nil || len(chartsData.Time) > 0 {
+		return chartsData, pgb.replaceCancelError(err)
+	}
+	tallies, err := retrieveProposalTalliesHourly(ctx, pgb.db, proposalToken)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return talliesChartData(tallies), nil
 }
 
 // SpendingTransactions retrieves all transactions spending outpoints from the
@@ -1769,20 +1800,6 @@ func (pgb *ChainDB) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
 		return nil, pgb.replaceCancelError(err)
 	}
 
-	var vote, revocation *string
-	status := strings.ToLower(poolStatus.String())
-	maturity := purchaseBlock.Height + uint32(pgb.chainParams.TicketMaturity)
-	expiration := maturity + pgb.chainParams.TicketExpiry
-	if pgb.Height() < int64(maturity) {
-		status = "immature"
-	}
-	if spendStatus == dbtypes.TicketRevoked {
-		status = spendStatus.String()
-		revocation = &spendTxid
-	} else if spendStatus == dbtypes.TicketVoted {
-		vote = &spendTxid
-	}
-
 	if poolStatus == dbtypes.PoolStatusMissed {
 		hash, height, err := RetrieveMissForTicket(ctx, pgb.db, txid)
 		if err != nil {
@@ -1794,15 +1811,59 @@ func (pgb *ChainDB) GetTicketInfo(txid string) (*apitypes.TicketInfo, error) {
 		}
 	}
 
+	return pgb.ticketInfo(&ticketInfoRow{
+		spendStatus:   spendStatus,
+		poolStatus:    poolStatus,
+		purchaseBlock: purchaseBlock,
+		lotteryBlock:  lotteryBlock,
+		spendTxid:     spendTxid,
+	}), nil
+}
+
+// GetTicketsInfo retrieves the ticket info of each of the tickets that is
+// found, by ticket hash.
+func (pgb *ChainDB) GetTicketsInfo(txids []string) (map[string]*apitypes.TicketInfo, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	rows, err := retrieveTicketsInfo(ctx, pgb.db, txids)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	infos := make(map[string]*apitypes.TicketInfo, len(rows))
+	for txid, row := range rows {
+		infos[txid] = pgb.ticketInfo(row)
+	}
+	return infos, nil
+}
+
+// ticketInfo combines the spend and pool statuses of a ticket with the best
+// block height into the status of the ticket info.
+func (pgb *ChainDB) ticketInfo(row *ticketInfoRow) *apitypes.TicketInfo {
+	var vote, revocation *string
+	status := strings.ToLower(row.poolStatus.String())
+	maturity := row.purchaseBlock.Height + uint32(pgb.chainParams.TicketMaturity)
+	expiration := maturity + pgb.chainParams.TicketExpiry
+	if pgb.Height() < int64(maturity) {
+		status = "immature"
+	}
+	spendTxid := row.spendTxid
+	if row.spendStatus == dbtypes.TicketRevoked {
+		status = row.spendStatus.String()
+		revocation = &spendTxid
+	} else if row.spendStatus == dbtypes.TicketVoted {
+		vote = &spendTxid
+	}
+
 	return &apitypes.TicketInfo{
 		Status:           status,
-		PurchaseBlock:    purchaseBlock,
+		PurchaseBlock:    row.purchaseBlock,
 		MaturityHeight:   maturity,
 		ExpirationHeight: expiration,
-		LotteryBlock:     lotteryBlock,
+		LotteryBlock:     row.lotteryBlock,
 		Vote:             vote,
 		Revocation:       revocation,
-	}, nil
+	}
 }
 
 func (pgb *ChainDB) TSpendVotes(tspendID *chainhash.Hash) (*dbtypes.TreasurySpendVotes, error) {
@@ -1905,6 +1966,68 @@ func (pgb *ChainDB) TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtyp
 	return txns, nil
 }
 
+// RecentBlockMinFeeRates retrieves the lowest fee rate, in DCR/kB, paid by a
+// non-coinbase regular transaction in each of the n most recent main chain
+// blocks, starting with the best block. This satisfies the
+// mempool.BlockFeeRateSource interface.
+func (pgb *ChainDB) RecentBlockMinFeeRates(n int64) ([]float64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	_, height := pgb.BestBlock()
+	feeRates, err := RetrieveMinRegularFeeRates(ctx, pgb.db, height-n)
+	return feeRates, pgb.replaceCancelError(err)
+}
+
+// AtomicSwaps retrieves the most recently spent (redeemed or refunded) atomic
+// swap contracts.
+func (pgb *ChainDB) AtomicSwaps(n, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := RetrieveAtomicSwaps(ctx, pgb.db, n, offset)
+	return swaps, pgb.replaceCancelError(err)
+}
+
+// AtomicSwapsCount counts the spent atomic swap contracts. If address is not
+// empty, only the swaps involving that address are counted.
+func (pgb *ChainDB) AtomicSwapsCount(address string) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	count, err := RetrieveAtomicSwapsCount(ctx, pgb.db, address)
+	return count, pgb.replaceCancelError(err)
+}
+
+// AtomicSwapsForAddress retrieves the spent atomic swap contracts for which
+// the address is the contract, recipient, or refund address.
+func (pgb *ChainDB) AtomicSwapsForAddress(address string, n, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := RetrieveAtomicSwapsForAddress(ctx, pgb.db, address, n, offset)
+	return swaps, pgb.replaceCancelError(err)
+}
+
+// AtomicSwapsBySecretHash retrieves the spent atomic swap contracts locked by
+// the hex-encoded secret hash.
+func (pgb *ChainDB) AtomicSwapsBySecretHash(secretHash string) ([]*dbtypes.AtomicSwap, error) {
+	hash, err := hex.DecodeString(secretHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret hash: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := RetrieveAtomicSwapsBySecretHash(ctx, pgb.db, hash)
+	return swaps, pgb.replaceCancelError(err)
+}
+
+// AtomicSwapsInTimeRange retrieves the atomic swap contracts spent in blocks
+// mined between the start and end UNIX times (inclusive).
+func (pgb *ChainDB) AtomicSwapsInTimeRange(start, end, n, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	swaps, err := RetrieveAtomicSwapsByTimeRange(ctx, pgb.db, time.Unix(start, 0),
+		time.Unix(end, 0), n, offset)
+	return swaps, pgb.replaceCancelError(err)
+}
+
 func (pgb *ChainDB) updateProjectFundCache() error {
 	_, _, err := pgb.AddressHistoryAll(pgb.devAddress, 1, 0)
 	return err
@@ -2457,6 +2580,9 @@ FUNDING_TX_DUPLICATE_CHECK:
 				ReceivedTotal: dcrutil.Amount(fundingTx.Tx.TxOut[f.Index].Value).ToCoin(),
 				IsFunding:     true,
 			}
+			if conflict, found := addressUTXOs.DoubleSpends[f.Hash]; found {
+				addrTx.ConflictTx = conflict.String()
+			}
 			addrData.Transactions = append(addrData.Transactions, addrTx)
 		}
 		received += fundingTx.Tx.TxOut[f.Index].Value
@@ -2519,6 +2645,9 @@ SPENDING_TX_DUPLICATE_CHECK:
 				MatchedTx:      strprevhash,
 				MatchedTxIndex: previndex,
 			}
+			if conflict, found := addressUTXOs.DoubleSpends[f.TxSpending]; found {
+				addrTx.ConflictTx = conflict.String()
+			}
 			addrData.Transactions = append(addrData.Transactions, addrTx)
 		}
 
@@ -3384,9 +3513,89 @@ func (pgb *ChainDB) VoutsForTx(dbTx *dbtypes.Tx) ([]dbtypes.Vout, error) {
 	return vouts, pgb.replaceCancelError(err)
 }
 
+// TransactionsByHashes retrieves the transactions with the given hashes with a
+// single query, keyed by hash. When a transaction is in more than one block,
+// the one in a valid mainchain block is chosen. Transactions that are not found
+// are not in the map.
+func (pgb *ChainDB) TransactionsByHashes(txHashes []string) (map[string]*dbtypes.Tx, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	dbTxs, err := RetrieveDbTxsByHashes(ctx, pgb.db, txHashes)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	txns := make(map[string]*dbtypes.Tx, len(txHashes))
+	for _, dbTx := range dbTxs {
+		// The preferred rows are first.
+		if _, found := txns[dbTx.TxID]; !found {
+			txns[dbTx.TxID] = dbTx
+		}
+	}
+	return txns, nil
+}
+
+// VinsForTxs is like VinsForTx for many transactions, but with a single query
+// and without the previous outpoint's pkScripts. The vins of each transaction
+// are in the order of dbTxs.
+func (pgb *ChainDB) VinsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.VinTxProperty, error) {
+	var ids []uint64
+	for _, dbTx := range dbTxs {
+		ids = append(ids, dbTx.VinDbIds...)
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	vinMap, err := RetrieveVinsMapByIDs(ctx, pgb.db, ids)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	vins := make([][]dbtypes.VinTxProperty, len(dbTxs))
+	for i, dbTx := range dbTxs {
+		vins[i] = make([]dbtypes.VinTxProperty, 0, len(dbTx.VinDbIds))
+		for _, id := range dbTx.VinDbIds {
+			vin, found := vinMap[id]
+			if !found {
+				return nil, fmt.Errorf("vin with row id %d not found", id)
+			}
+			vins[i] = append(vins[i], *vin)
+		}
+	}
+	return vins, nil
+}
+
+// VoutsForTxs is like VoutsForTx for many transactions, but with a single
+// query. The vouts of each transaction are in the order of dbTxs.
+func (pgb *ChainDB) VoutsForTxs(dbTxs []*dbtypes.Tx) ([][]dbtypes.Vout, error) {
+	var ids []uint64
+	for _, dbTx := range dbTxs {
+		ids = append(ids, dbTx.VoutDbIds...)
+	}
+
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	voutMap, err := RetrieveVoutsMapByIDs(ctx, pgb.db, ids)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	vouts := make([][]dbtypes.Vout, len(dbTxs))
+	for i, dbTx := range dbTxs {
+		vouts[i] = make([]dbtypes.Vout, 0, len(dbTx.VoutDbIds))
+		for _, id := range dbTx.VoutDbIds {
+			vout, found := voutMap[id]
+			if !found {
+				return nil, fmt.Errorf("vout with row id %d not found", id)
+			}
+			vouts[i] = append(vouts[i], *vout)
+		}
+	}
+	return vouts, nil
+}
+
 func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 	tipHash := pgb.BestBlockHashStr()
-	var blocksMoved, txnsUpdated, vinsUpdated, votesUpdated, ticketsUpdated, treasuryTxnsUpdates, addrsUpdated int64
+	var blocksMoved, txnsUpdated, vinsUpdated, votesUpdated, ticketsUpdated, treasuryTxnsUpdates, swapsUpdated, addrsUpdated int64
 	for tipHash != mainRoot {
 		// 1. Block. Set is_mainchain=false on the tip block, return hash of
 		// previous block.
@@ -3472,6 +3681,17 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 		treasuryTxnsUpdates += rowsUpdated
 		log.Debugf("UpdateTreasuryMainchain: %v", time.Since(now))
 
+		// 9. Swaps. Sets is_mainchain=false on all swaps spent in the tip
+		// block.
+		now = time.Now()
+		rowsUpdated, err = UpdateSwapsMainchain(pgb.db, tipHash, false)
+		if err != nil {
+			log.Errorf("Failed to set swaps in block %s as sidechain: %v",
+				tipHash, err)
+		}
+		swapsUpdated += rowsUpdated
+		log.Debugf("UpdateSwapsMainchain: %v", time.Since(now))
+
 		// move on to next block
 		tipHash = previousHash
 
@@ -3484,8 +3704,8 @@ func (pgb *ChainDB) TipToSideChain(mainRoot string) (string, int64, error) {
 		pgb.bestBlock.mtx.Unlock()
 	}
 
-	log.Debugf("Reorg orphaned: %d blocks, %d txns, %d vins, %d addresses, %d votes, %d tickets, %d treasury txns",
-		blocksMoved, txnsUpdated, vinsUpdated, addrsUpdated, votesUpdated, ticketsUpdated, treasuryTxnsUpdates)
+	log.Debugf("Reorg orphaned: %d blocks, %d txns, %d vins, %d addresses, %d votes, %d tickets, %d treasury txns, %d swaps",
+		blocksMoved, txnsUpdated, vinsUpdated, addrsUpdated, votesUpdated, ticketsUpdated, treasuryTxnsUpdates, swapsUpdated)
 
 	return tipHash, blocksMoved, nil
 }
@@ -4184,6 +4404,15 @@ txns:
 				log.Tracef("Noted %d unrevoked newly-missed tickets.", numUnrevokedMisses)
 			}
 		} // updateTicketsSpendingInfo
+	} else {
+		// Atomic swaps: insert redemptions and refunds of swap contracts.
+		_, err = InsertSwaps(pgb.db, dbTransactions, dbTxVins, chainParams,
+			pgb.dupChecks, updateExistingRecords)
+		if err != nil && err != sql.ErrNoRows {
+			log.Error("InsertSwaps:", err)
+			txRes.err = err
+			return txRes
+		}
 	} // isStake
 
 	wg.Wait()
@@ -4811,6 +5040,15 @@ func (pgb *ChainDB) GetBlockHeaderByHash(hash string) (*wire.BlockHeader, error)
 // GetRawAPITransaction gets an *apitypes.Tx for a given transaction ID.
 func (pgb *ChainDB) GetRawAPITransaction(txid *chainhash.Hash) *apitypes.Tx {
 	tx, _ := pgb.getRawAPITransaction(txid)
+	if tx == nil {
+		return nil
+	}
+	firstSeen, found, err := pgb.MempoolFirstSeen(tx.TxID)
+	if err != nil {
+		log.Errorf("MempoolFirstSeen failed for %s: %v", tx.TxID, err)
+	} else if found {
+		tx.FirstSeen = firstSeen.Unix()
+	}
 	return tx
 }
 
@@ -4954,6 +5192,7 @@ func (pgb *ChainDB) GetAllTxOut(txid *chainhash.Hash) []*apitypes.TxOut {
 				Addresses: spk.Addresses,
 				CommitAmt: spk.CommitAmt,
 			},
+			Labels: pgb.addressLabelList(spk.Addresses),
 		})
 	}
 
@@ -5239,6 +5478,27 @@ func (pgb *ChainDB) GetSDiffRange(idx0, idx1 int) []float64 {
 	return sdiffs
 }
 
+// BlockHeightAtTime returns the height of the first mainchain block mined at or
+// after the UNIX time. The error is sql.ErrNoRows if there is no such block.
+func (pgb *ChainDB) BlockHeightAtTime(timestamp int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	height, err := RetrieveHeightByTime(ctx, pgb.db, timestamp)
+	return height, pgb.replaceCancelError(err)
+}
+
+// BlockTimes returns the UNIX time of each of the mainchain blocks at the
+// heights, by height.
+func (pgb *ChainDB) BlockTimes(heights []int64) (map[int64]int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	times, err := RetrieveBlockTimes(ctx, pgb.db, heights)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return times, nil
+}
+
 // SDiffRange returns an array of stake difficulties for block range
 // ind0 to ind1.
 func (pgb *ChainDB) SDiffRange(ind0, ind1 int64) ([]float64, error) {
@@ -6238,6 +6498,7 @@ func (pgb *ChainDB) GetMempool() []exptypes.MempoolTx {
 			TypeID:   int(txType),
 			VoteInfo: voteInfo,
 			Vin:      exptypes.MsgTxMempoolInputs(msgTx),
+			Expiry:   msgTx.Expiry,
 		})
 	}
 
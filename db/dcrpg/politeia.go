@@ -0,0 +1,273 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/lib/pq"
+)
+
+// StorePoliteiaProposal stores the latest version of the proposal polled at
+// the given time, and the tally of its votes if it is not nil. A new version of
+// the proposal, and a change of its state, status or vote status, are recorded
+// in its history. The ID of the stored proposal is returned.
+func StorePoliteiaProposal(ctx context.Context, db *sql.DB, p *dbtypes.PoliteiaProposal,
+	tally *dbtypes.ProposalVoteTally, polled time.Time) (int64, error) {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	var version string
+	var state, status, voteStatus int16
+	err = dbTx.QueryRowContext(ctx, internal.SelectPoliteiaProposalStatus, p.Token).
+		Scan(&version, &state, &status, &voteStatus)
+	isNew := err == sql.ErrNoRows
+	if err != nil && !isNew {
+		_ = dbTx.Rollback()
+		return 0, err
+	}
+
+	if isNew || version != p.Version {
+		_, err = dbTx.ExecContext(ctx, internal.InsertPoliteiaProposalVersion,
+			p.Token, p.Version, polled, p.Data)
+		if err != nil {
+			_ = dbTx.Rollback()
+			return 0, err
+		}
+	}
+	if isNew || state != p.State || status != p.Status || voteStatus != p.VoteStatus {
+		_, err = dbTx.ExecContext(ctx, internal.InsertPoliteiaStatusChange,
+			p.Token, polled, p.State, p.Status, p.VoteStatus, p.StatusMessage)
+		if err != nil {
+			_ = dbTx.Rollback()
+			return 0, err
+		}
+	}
+
+	var id int64
+	err = dbTx.QueryRowContext(ctx, internal.UpsertPoliteiaProposal, p.Token, p.RefID,
+		p.Version, p.Timestamp, p.State, p.Status, p.VoteStatus, p.Data).Scan(&id)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, err
+	}
+
+	if tally != nil {
+		_, err = dbTx.ExecContext(ctx, internal.InsertPoliteiaVoteTally, p.Token, polled,
+			tally.VoteStatus, tally.Yes, tally.No, tally.TotalVotes, tally.EligibleVotes)
+		if err != nil {
+			_ = dbTx.Rollback()
+			return 0, err
+		}
+	}
+
+	return id, dbTx.Commit()
+}
+
+// scanPoliteiaProposal scans a row of the selectPoliteiaProposals query.
+func scanPoliteiaProposal(row interface{ Scan(...interface{}) error }) (*dbtypes.PoliteiaProposal, error) {
+	p := new(dbtypes.PoliteiaProposal)
+	err := row.Scan(&p.ID, &p.Token, &p.RefID, &p.Version, &p.Timestamp,
+		&p.State, &p.Status, &p.VoteStatus, &p.Data)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RetrievePoliteiaProposal retrieves the proposal selected by the query, one of
+// internal.SelectPoliteiaProposalByToken or SelectPoliteiaProposalByRefID. If
+// it is not stored, sql.ErrNoRows is returned.
+func RetrievePoliteiaProposal(ctx context.Context, db *sql.DB, query, arg string) (*dbtypes.PoliteiaProposal, error) {
+	return scanPoliteiaProposal(db.QueryRowContext(ctx, query, arg))
+}
+
+// RetrievePoliteiaProposals retrieves count proposals, skipping offset,
+// starting with the newest, and the total number of proposals. If any vote
+// statuses are given, only the proposals with one of them are counted and
+// retrieved.
+func RetrievePoliteiaProposals(ctx context.Context, db *sql.DB, offset, count int64,
+	voteStatuses []int16) ([]*dbtypes.PoliteiaProposal, int64, error) {
+	var rows *sql.Rows
+	var total int64
+	var err error
+	if len(voteStatuses) == 0 {
+		err = db.QueryRowContext(ctx, internal.SelectPoliteiaProposalsCount).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+		rows, err = db.QueryContext(ctx, internal.SelectPoliteiaProposals, count, offset)
+	} else {
+		statuses := make([]int64, 0, len(voteStatuses))
+		for _, s := range voteStatuses {
+			statuses = append(statuses, int64(s))
+		}
+		err = db.QueryRowContext(ctx, internal.SelectPoliteiaProposalsCountByVoteStatus,
+			pq.Array(statuses)).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+		rows, err = db.QueryContext(ctx, internal.SelectPoliteiaProposalsByVoteStatus,
+			pq.Array(statuses), count, offset)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer closeRows(rows)
+
+	var proposals []*dbtypes.PoliteiaProposal
+	for rows.Next() {
+		p, err := scanPoliteiaProposal(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		proposals = append(proposals, p)
+	}
+	return proposals, total, rows.Err()
+}
+
+// RetrieveProposalHistory retrieves the versions, the status changes and the
+// hourly vote tallies of the proposal.
+func RetrieveProposalHistory(ctx context.Context, db *sql.DB, token string) (*dbtypes.ProposalHistory, error) {
+	history := &dbtypes.ProposalHistory{
+		Token:         token,
+		Versions:      []*dbtypes.ProposalVersion{},
+		StatusChanges: []*dbtypes.ProposalStatusChange{},
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectPoliteiaProposalVersions, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var t time.Time
+		v := new(dbtypes.ProposalVersion)
+		if err = rows.Scan(&v.Version, &t, &v.Proposal); err != nil {
+			return nil, err
+		}
+		v.Time = dbtypes.NewTimeDef(t)
+		history.Versions = append(history.Versions, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, internal.SelectPoliteiaStatusChanges, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var t time.Time
+		c := new(dbtypes.ProposalStatusChange)
+		if err = rows.Scan(&t, &c.State, &c.Status, &c.VoteStatus, &c.Message); err != nil {
+			return nil, err
+		}
+		c.Time = dbtypes.NewTimeDef(t)
+		history.StatusChanges = append(history.StatusChanges, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	history.Tallies, err = retrieveProposalTalliesHourly(ctx, db, token)
+	return history, err
+}
+
+// retrieveProposalTalliesHourly retrieves the last vote tally of the proposal
+// polled in each hour.
+func retrieveProposalTalliesHourly(ctx context.Context, db *sql.DB, token string) ([]*dbtypes.ProposalVoteTally, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectPoliteiaVoteTalliesHourly, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	tallies := []*dbtypes.ProposalVoteTally{}
+	for rows.Next() {
+		var t time.Time
+		tally := &dbtypes.ProposalVoteTally{Token: token}
+		err = rows.Scan(&t, &tally.VoteStatus, &tally.Yes, &tally.No, &tally.TotalVotes,
+			&tally.EligibleVotes, &tally.TicketVotes)
+		if err != nil {
+			return nil, err
+		}
+		tally.Time = dbtypes.NewTimeDef(t)
+		tallies = append(tallies, tally)
+	}
+	return tallies, rows.Err()
+}
+
+// talliesChartData converts the cumulative vote tallies into the votes cast
+// between the tallies, as in the proposal votes charts data from the commits
+// to the proposals repository.
+func talliesChartData(tallies []*dbtypes.ProposalVoteTally) *dbtypes.ProposalChartsData {
+	data := new(dbtypes.ProposalChartsData)
+	var yes, no int64
+	for _, tally := range tallies {
+		if tally.Yes == yes && tally.No == no {
+			continue
+		}
+		data.Yes = append(data.Yes, uint64(tally.Yes-yes))
+		data.No = append(data.No, uint64(tally.No-no))
+		data.Time = append(data.Time, tally.Time)
+		yes, no = tally.Yes, tally.No
+	}
+	return data
+}
+
+// StorePoliteiaProposal stores the latest version of the proposal, and the
+// tally of its votes if it is not nil, recording its new versions and status
+// changes in its history. The ID of the stored proposal is returned.
+func (pgb *ChainDB) StorePoliteiaProposal(p *dbtypes.PoliteiaProposal, tally *dbtypes.ProposalVoteTally) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	id, err := StorePoliteiaProposal(ctx, pgb.db, p, tally, time.Now())
+	return id, pgb.replaceCancelError(err)
+}
+
+// PoliteiaProposal retrieves the stored proposal with the token. If it is not
+// stored, sql.ErrNoRows is returned.
+func (pgb *ChainDB) PoliteiaProposal(token string) (*dbtypes.PoliteiaProposal, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	p, err := RetrievePoliteiaProposal(ctx, pgb.db, internal.SelectPoliteiaProposalByToken, token)
+	return p, pgb.replaceCancelError(err)
+}
+
+// PoliteiaProposalByRefID retrieves the stored proposal with the RefID. If it
+// is not stored, sql.ErrNoRows is returned.
+func (pgb *ChainDB) PoliteiaProposalByRefID(refID string) (*dbtypes.PoliteiaProposal, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	p, err := RetrievePoliteiaProposal(ctx, pgb.db, internal.SelectPoliteiaProposalByRefID, refID)
+	return p, pgb.replaceCancelError(err)
+}
+
+// PoliteiaProposals retrieves count stored proposals, skipping offset, starting
+// with the newest, and the total number of proposals, optionally only those
+// with one of the vote statuses.
+func (pgb *ChainDB) PoliteiaProposals(offset, count int64, voteStatuses []int16) ([]*dbtypes.PoliteiaProposal, int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	proposals, total, err := RetrievePoliteiaProposals(ctx, pgb.db, offset, count, voteStatuses)
+	return proposals, total, pgb.replaceCancelError(err)
+}
+
+// ProposalHistory retrieves the stored versions, status changes and hourly
+// vote tallies of the proposal with the token.
+func (pgb *ChainDB) ProposalHistory(token string) (*dbtypes.ProposalHistory, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	history, err := RetrieveProposalHistory(ctx, pgb.db, token)
+	return history, pgb.replaceCancelError(err)
+}
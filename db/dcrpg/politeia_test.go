@@ -0,0 +1,30 @@
+package dcrpg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+func TestTalliesChartData(t *testing.T) {
+	hour := func(h int) dbtypes.TimeDef {
+		return dbtypes.NewTimeDef(time.Date(2021, 5, 1, h, 0, 0, 0, time.UTC))
+	}
+	tallies := []*dbtypes.ProposalVoteTally{
+		{Time: hour(0)},
+		{Time: hour(1), Yes: 100, No: 20},
+		{Time: hour(2), Yes: 100, No: 20},
+		{Time: hour(3), Yes: 250, No: 30},
+	}
+	data := talliesChartData(tallies)
+	want := &dbtypes.ProposalChartsData{
+		Yes:  []uint64{100, 150},
+		No:   []uint64{20, 10},
+		Time: []dbtypes.TimeDef{hour(1), hour(3)},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %+v, expected %+v", data, want)
+	}
+}
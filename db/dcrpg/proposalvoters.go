@@ -0,0 +1,230 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// maxAddressProposalVotes is the maximum number of proposal votes of the
+// tickets of an address that are retrieved.
+const maxAddressProposalVotes = 1000
+
+// recentAddressProposalVotesTimeout is the timeout of the retrieval of the
+// most recent proposal votes of the tickets of an address, which is shorter
+// than the query timeout since they are an optional section of a page.
+const recentAddressProposalVotesTimeout = 5 * time.Second
+
+// proposalVoterAgeDays are the minimum ages in days of the tickets in each
+// range of the age breakdown of the voters on a proposal.
+var proposalVoterAgeDays = []int64{0, 7, 30, 60, 90, 120}
+
+// RetrieveProposalTicketVotes retrieves the votes of tickets on the Politeia
+// proposals selected by the query, internal.SelectProposalVotesForTicket or
+// SelectProposalVotesForAddress, with its args.
+func RetrieveProposalTicketVotes(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*dbtypes.ProposalTicketVote, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	votes := []*dbtypes.ProposalTicketVote{}
+	for rows.Next() {
+		var t time.Time
+		v := new(dbtypes.ProposalTicketVote)
+		if err = rows.Scan(&v.Ticket, &v.Token, &v.Name, &v.RefID, &v.Choice, &t); err != nil {
+			return nil, err
+		}
+		v.Time = dbtypes.NewTimeDef(t)
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+// retrieveProposalVoters retrieves limit of the tickets that voted on the
+// proposal, by ticket hash, after skipping offset.
+func retrieveProposalVoters(ctx context.Context, db *sql.DB, token string, limit, offset int64) ([]*dbtypes.ProposalVoter, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectProposalVoters, token, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	voters := []*dbtypes.ProposalVoter{}
+	for rows.Next() {
+		v := new(dbtypes.ProposalVoter)
+		if err = rows.Scan(&v.Ticket, &v.Address, &v.Choice, &v.TicketHeight); err != nil {
+			return nil, err
+		}
+		voters = append(voters, v)
+	}
+	return voters, rows.Err()
+}
+
+// proposalVoterHeight is the number of tickets purchased at a height, or -1 if
+// unknown, that voted on a proposal with a choice.
+type proposalVoterHeight struct {
+	height int64
+	choice string
+	count  int64
+}
+
+// retrieveProposalVoterHeights retrieves the number of tickets that voted on
+// the proposal by purchase height and choice.
+func retrieveProposalVoterHeights(ctx context.Context, db *sql.DB, token string) ([]proposalVoterHeight, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectProposalVoterHeights, token)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var heights []proposalVoterHeight
+	for rows.Next() {
+		var h proposalVoterHeight
+		if err = rows.Scan(&h.height, &h.choice, &h.count); err != nil {
+			return nil, err
+		}
+		heights = append(heights, h)
+	}
+	return heights, rows.Err()
+}
+
+// proposalVoteSummary is the vote summary in the data of a proposal polled from
+// Politeia.
+type proposalVoteSummary struct {
+	Votes struct {
+		TotalVotes         int64  `json:"totalvotes"`
+		Endheight          string `json:"endheight"`
+		NumOfEligibleVotes int64  `json:"numofeligiblevotes"`
+	} `json:"votes"`
+}
+
+// makeProposalVoters computes the participation in the vote on the proposal
+// from the purchase heights of its voters, and the eligible tickets, votes cast
+// and end height of the vote polled from Politeia. The age of a ticket at the
+// end of the vote is estimated from the number of blocks since its purchase and
+// the target time per block.
+func makeProposalVoters(token string, heights []proposalVoterHeight, eligible, cast,
+	endHeight int64, blockTime time.Duration) *dbtypes.ProposalVoters {
+	pv := &dbtypes.ProposalVoters{
+		Token:           token,
+		EligibleTickets: eligible,
+		VotesCast:       cast,
+		EndHeight:       endHeight,
+		AgeBreakdown:    make([]*dbtypes.ProposalVoterAges, len(proposalVoterAgeDays)),
+		Voters:          []*dbtypes.ProposalVoter{},
+	}
+	if eligible > 0 {
+		pv.Participation = 100 * float64(cast) / float64(eligible)
+	}
+	for i, minDays := range proposalVoterAgeDays {
+		pv.AgeBreakdown[i] = &dbtypes.ProposalVoterAges{MinDays: minDays}
+		if i+1 < len(proposalVoterAgeDays) {
+			pv.AgeBreakdown[i].MaxDays = proposalVoterAgeDays[i+1]
+		}
+	}
+
+	for _, h := range heights {
+		pv.ParsedVotes += h.count
+		if endHeight <= 0 || h.height < 0 || h.height > endHeight {
+			pv.UnknownAgeVotes += h.count
+			continue
+		}
+		age := time.Duration(endHeight-h.height) * blockTime
+		days := int64(age / (24 * time.Hour))
+		i := len(proposalVoterAgeDays) - 1
+		for days < proposalVoterAgeDays[i] {
+			i--
+		}
+		ages := pv.AgeBreakdown[i]
+		ages.Votes += h.count
+		switch {
+		case strings.EqualFold(h.choice, "yes"):
+			ages.Yes += h.count
+		case strings.EqualFold(h.choice, "no"):
+			ages.No += h.count
+		}
+	}
+	return pv
+}
+
+// TicketProposalVotes retrieves the votes of the ticket on the Politeia
+// proposals, parsed from the proposals repository, the most recent first.
+func (pgb *ChainDB) TicketProposalVotes(ticket string) ([]*dbtypes.ProposalTicketVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	votes, err := RetrieveProposalTicketVotes(ctx, pgb.db, internal.SelectProposalVotesForTicket, ticket)
+	return votes, pgb.replaceCancelError(err)
+}
+
+// AddressProposalVotes retrieves the most recent votes on the Politeia
+// proposals, up to 1000, of the tickets with the address as the stake
+// submission or a commitment address.
+func (pgb *ChainDB) AddressProposalVotes(address string) ([]*dbtypes.ProposalTicketVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	votes, err := RetrieveProposalTicketVotes(ctx, pgb.db, internal.SelectProposalVotesForAddress,
+		address, maxAddressProposalVotes)
+	return votes, pgb.replaceCancelError(err)
+}
+
+// RecentAddressProposalVotes retrieves the N most recent votes on the Politeia
+// proposals of the tickets with the address as the stake submission or a
+// commitment address. The retrieval times out after a few seconds.
+func (pgb *ChainDB) RecentAddressProposalVotes(address string, N int64) ([]*dbtypes.ProposalTicketVote, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, recentAddressProposalVotesTimeout)
+	defer cancel()
+	votes, err := RetrieveProposalTicketVotes(ctx, pgb.db, internal.SelectProposalVotesForAddress,
+		address, N)
+	return votes, pgb.replaceCancelError(err)
+}
+
+// ProposalVoters computes the participation in the vote on the Politeia
+// proposal with the token from the tickets that voted on it, parsed from the
+// proposals repository, and retrieves N of the voters, by ticket hash, after
+// skipping offset. The eligible tickets and votes cast are those of the
+// proposal polled from Politeia, if it was. sql.ErrNoRows is returned if the
+// proposal was neither polled from Politeia nor voted on.
+func (pgb *ChainDB) ProposalVoters(token string, N, offset int64) (*dbtypes.ProposalVoters, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	heights, err := retrieveProposalVoterHeights(ctx, pgb.db, token)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+
+	var summary proposalVoteSummary
+	p, err := RetrievePoliteiaProposal(ctx, pgb.db, internal.SelectPoliteiaProposalByToken, token)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, pgb.replaceCancelError(err)
+	}
+	if p == nil && len(heights) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if p != nil {
+		if err = json.Unmarshal(p.Data, &summary); err != nil {
+			log.Warnf("Invalid vote summary of proposal %s: %v", token, err)
+		}
+	}
+	endHeight, _ := strconv.ParseInt(summary.Votes.Endheight, 10, 64)
+
+	pv := makeProposalVoters(token, heights, summary.Votes.NumOfEligibleVotes,
+		summary.Votes.TotalVotes, endHeight, pgb.chainParams.TargetTimePerBlock)
+	if offset < pv.ParsedVotes {
+		pv.Voters, err = retrieveProposalVoters(ctx, pgb.db, token, N, offset)
+		if err != nil {
+			return nil, pgb.replaceCancelError(err)
+		}
+	}
+	return pv, nil
+}
@@ -0,0 +1,50 @@
+package dcrpg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMakeProposalVoters(t *testing.T) {
+	const day = 288 // blocks per day at 5 minutes per block
+	const end = 600000
+	heights := []proposalVoterHeight{
+		{end - 3*day, "Yes", 1},
+		{end - 10*day, "No", 1},
+		{end - 200*day, "yes", 2},
+		{-1, "Yes", 1},
+		{end - 7*day, "No", 1},
+		{end + 1, "No", 1},
+	}
+	pv := makeProposalVoters("token", heights, 40000, 10000, end, 5*time.Minute)
+	if pv.Token != "token" || pv.EligibleTickets != 40000 || pv.VotesCast != 10000 ||
+		pv.ParsedVotes != 7 || pv.Participation != 25 || pv.EndHeight != end ||
+		pv.UnknownAgeVotes != 2 || len(pv.Voters) != 0 {
+		t.Fatalf("unexpected proposal voters %+v", pv)
+	}
+
+	type ages struct{ minDays, maxDays, votes, yes, no int64 }
+	want := []ages{
+		{0, 7, 1, 1, 0},
+		{7, 30, 2, 0, 2},
+		{30, 60, 0, 0, 0},
+		{60, 90, 0, 0, 0},
+		{90, 120, 0, 0, 0},
+		{120, 0, 2, 2, 0},
+	}
+	if len(pv.AgeBreakdown) != len(want) {
+		t.Fatalf("expected %d age ranges, found %d", len(want), len(pv.AgeBreakdown))
+	}
+	for i, a := range pv.AgeBreakdown {
+		got := ages{a.MinDays, a.MaxDays, a.Votes, a.Yes, a.No}
+		if got != want[i] {
+			t.Errorf("age range %d: got %+v, expected %+v", i, got, want[i])
+		}
+	}
+
+	// Without the end of the vote, the ages are unknown.
+	pv = makeProposalVoters("token", heights, 0, 0, 0, 5*time.Minute)
+	if pv.UnknownAgeVotes != 7 || pv.Participation != 0 {
+		t.Fatalf("unexpected proposal voters %+v", pv)
+	}
+}
@@ -524,6 +524,16 @@ func InsertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistin
 		return err
 	}
 
+	// The payees of the tspends are not updated with their block, which is
+	// only in the treasury table.
+	payeeStmt, err := dbtx.Prepare(internal.InsertTreasuryPayee)
+	if err != nil {
+		log.Errorf("Treasury payee INSERT prepare: %v", err)
+		_ = stmt.Close()
+		_ = dbtx.Rollback()
+		return err
+	}
+
 	// Insert each treasury txn.
 	for _, tx := range dbTxns {
 		var value int64
@@ -545,19 +555,232 @@ func InsertTreasuryTxns(db *sql.DB, dbTxns []*dbtypes.Tx, checked, updateExistin
 				continue
 			}
 			_ = stmt.Close() // try, but we want the QueryRow error back
+			_ = payeeStmt.Close()
 			if errRoll := dbtx.Rollback(); errRoll != nil {
 				log.Errorf("Rollback failed: %v", errRoll)
 			}
 			return err
 		}
+
+		if tx.TxType != int16(stake.TxTypeTSpend) {
+			continue
+		}
+		// The first output of a tspend is an OP_RETURN, and the rest pay the
+		// payees.
+		for _, vout := range tx.Vouts {
+			if vout.TxIndex == 0 || len(vout.ScriptPubKeyData.Addresses) == 0 {
+				continue
+			}
+			_, err = payeeStmt.Exec(tx.TxID, vout.TxIndex,
+				vout.ScriptPubKeyData.Addresses[0], int64(vout.Value))
+			if err != nil {
+				_ = stmt.Close()
+				_ = payeeStmt.Close()
+				if errRoll := dbtx.Rollback(); errRoll != nil {
+					log.Errorf("Rollback failed: %v", errRoll)
+				}
+				return err
+			}
+		}
 	}
 
-	// Close prepared statement. Ignore errors as we'll Commit regardless.
+	// Close prepared statements. Ignore errors as we'll Commit regardless.
 	_ = stmt.Close()
+	_ = payeeStmt.Close()
 
 	return dbtx.Commit()
 }
 
+// RetrieveMinRegularFeeRates retrieves the lowest fee rate, in DCR/kB, paid by
+// a non-coinbase regular transaction in each main chain block above the given
+// height, starting with the best block.
+func RetrieveMinRegularFeeRates(ctx context.Context, db *sql.DB, height int64) ([]float64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectMinRegularFeeRatesAboveHeight, height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var feeRates []float64
+	for rows.Next() {
+		var blockHeight int64
+		var atomsPerByte float64
+		if err = rows.Scan(&blockHeight, &atomsPerByte); err != nil {
+			return nil, err
+		}
+		// atoms/B * 1000 B/kB / 1e8 atoms/DCR
+		feeRates = append(feeRates, atomsPerByte*1e-5)
+	}
+
+	return feeRates, rows.Err()
+}
+
+// --- swaps table ---
+
+// InsertSwaps locates the inputs of the given regular transactions that redeem
+// or refund an atomic swap contract, and inserts a swaps table row for each.
+// The number of swap rows inserted is returned.
+func InsertSwaps(db *sql.DB, dbTxns []*dbtypes.Tx, dbTxVins []dbtypes.VinTxPropertyARRAY,
+	params *chaincfg.Params, checked, updateExistingRecords bool) (int64, error) {
+	// Parse all of the input scripts before starting a DB transaction since
+	// the vast majority of blocks have no swaps.
+	type swapSpend struct {
+		tx    *dbtypes.Tx
+		vin   *dbtypes.VinTxProperty
+		spend *txhelpers.AtomicSwapSpend
+	}
+	var swaps []swapSpend
+	for it, tx := range dbTxns {
+		if tx.TxType != int16(stake.TxTypeRegular) {
+			continue
+		}
+		for iv := range dbTxVins[it] {
+			vin := &dbTxVins[it][iv]
+			if txhelpers.IsZeroHashStr(vin.PrevTxHash) {
+				continue
+			}
+			spend, err := txhelpers.ExtractSwapSpendFromInputScript(vin.ScriptHex, params)
+			if err != nil {
+				log.Warnf("Unable to parse input %s:%d for swap data: %v",
+					vin.TxID, vin.TxIndex, err)
+				continue
+			}
+			if spend == nil {
+				continue
+			}
+			swaps = append(swaps, swapSpend{tx, vin, spend})
+		}
+	}
+
+	if len(swaps) == 0 {
+		return 0, nil
+	}
+
+	dbtx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	// Prepare swap insert statement, optionally updating a row if it conflicts
+	// with the unique index on (spend_tx, spend_vin, spend_block_hash).
+	stmt, err := dbtx.Prepare(internal.MakeSwapInsertStatement(checked, updateExistingRecords))
+	if err != nil {
+		log.Errorf("Swap INSERT prepare: %v", err)
+		_ = dbtx.Rollback() // try, but we want the Prepare error back
+		return 0, err
+	}
+
+	var numInserted int64
+	for _, s := range swaps {
+		contract := s.spend.Contract
+		_, err = stmt.Exec(s.vin.PrevTxHash, s.vin.PrevTxIndex, s.vin.TxID,
+			s.vin.TxIndex, s.tx.BlockHeight, s.tx.BlockHash, s.tx.BlockTime,
+			contract.ContractAddress.String(), contract.RecipientAddress.String(),
+			contract.RefundAddress.String(), s.vin.ValueIn, contract.SecretHash[:],
+			s.spend.Secret, contract.Locktime, s.spend.IsRefund(), s.tx.IsMainchainBlock)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			_ = stmt.Close() // try, but we want the Exec error back
+			if errRoll := dbtx.Rollback(); errRoll != nil {
+				log.Errorf("Rollback failed: %v", errRoll)
+			}
+			return 0, err
+		}
+		numInserted++
+	}
+
+	// Close prepared statement. Ignore errors as we'll Commit regardless.
+	_ = stmt.Close()
+
+	return numInserted, dbtx.Commit()
+}
+
+// UpdateSwapsMainchain sets the is_mainchain column for the swaps spent in the
+// specified block.
+func UpdateSwapsMainchain(db SqlExecutor, blockHash string, isMainchain bool) (int64, error) {
+	return sqlExec(db, internal.UpdateSwapsMainchainByBlock,
+		"failed to update swaps is_mainchain: ", isMainchain, blockHash)
+}
+
+func scanAtomicSwapRows(rows *sql.Rows) ([]*dbtypes.AtomicSwap, error) {
+	defer closeRows(rows)
+
+	var swaps []*dbtypes.AtomicSwap
+	for rows.Next() {
+		var swap dbtypes.AtomicSwap
+		var secretHash, secret []byte
+		err := rows.Scan(&swap.ContractTx, &swap.ContractVout, &swap.SpendTx,
+			&swap.SpendVin, &swap.SpendHeight, &swap.SpendTime,
+			&swap.ContractAddress, &swap.RecipientAddress, &swap.RefundAddress,
+			&swap.Value, &secretHash, &secret, &swap.LockTime, &swap.IsRefund)
+		if err != nil {
+			return nil, err
+		}
+		swap.SecretHash = hex.EncodeToString(secretHash)
+		swap.Secret = hex.EncodeToString(secret)
+		swaps = append(swaps, &swap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return swaps, nil
+}
+
+// RetrieveAtomicSwaps retrieves the most recently spent atomic swap contracts.
+func RetrieveAtomicSwaps(ctx context.Context, db *sql.DB, N, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSwaps, N, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanAtomicSwapRows(rows)
+}
+
+// RetrieveAtomicSwapsForAddress retrieves the atomic swaps for which the given
+// address is the contract address, or the recipient or refund address.
+func RetrieveAtomicSwapsForAddress(ctx context.Context, db *sql.DB, address string, N, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSwapsByAddress, address, N, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanAtomicSwapRows(rows)
+}
+
+// RetrieveAtomicSwapsBySecretHash retrieves the atomic swaps with contracts
+// locked by the given secret hash. The contracts on both chains of a
+// cross-chain swap share the same secret hash.
+func RetrieveAtomicSwapsBySecretHash(ctx context.Context, db *sql.DB, secretHash []byte) ([]*dbtypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSwapsBySecretHash, secretHash)
+	if err != nil {
+		return nil, err
+	}
+	return scanAtomicSwapRows(rows)
+}
+
+// RetrieveAtomicSwapsByTimeRange retrieves the atomic swaps with contracts
+// spent in blocks mined in the given time range (inclusive).
+func RetrieveAtomicSwapsByTimeRange(ctx context.Context, db *sql.DB, start, end time.Time, N, offset int64) ([]*dbtypes.AtomicSwap, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectSwapsByTimeRange,
+		dbtypes.NewTimeDef(start), dbtypes.NewTimeDef(end), N, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanAtomicSwapRows(rows)
+}
+
+// RetrieveAtomicSwapsCount counts the atomic swaps, optionally only those for
+// the given address if it is not empty.
+func RetrieveAtomicSwapsCount(ctx context.Context, db *sql.DB, address string) (count int64, err error) {
+	if address == "" {
+		err = db.QueryRowContext(ctx, internal.SelectSwapsCount).Scan(&count)
+		return
+	}
+	err = db.QueryRowContext(ctx, internal.SelectSwapsCountByAddress, address).Scan(&count)
+	return
+}
+
 // InsertTickets takes a slice of *dbtypes.Tx and corresponding DB row IDs for
 // transactions, extracts the tickets, and inserts the tickets into the
 // database. Outputs are a slice of DB row IDs of the inserted tickets, and an
@@ -1268,6 +1491,59 @@ func RetrieveTicketInfoByHash(ctx context.Context, db *sql.DB, ticketHash string
 	return
 }
 
+// ticketInfoRow is the ticket info retrieved by retrieveTicketsInfo. The
+// lotteryBlock of a voted ticket is the block of the vote, and that of a missed
+// ticket the main chain block in which it was missed.
+type ticketInfoRow struct {
+	spendStatus   dbtypes.TicketSpendType
+	poolStatus    dbtypes.TicketPoolStatus
+	purchaseBlock *apitypes.TinyBlock
+	lotteryBlock  *apitypes.TinyBlock
+	spendTxid     string
+}
+
+// retrieveTicketsInfo retrieves the info of each of the tickets that is found,
+// by ticket hash.
+func retrieveTicketsInfo(ctx context.Context, db *sql.DB, ticketHashes []string) (map[string]*ticketInfoRow, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTicketsInfoByHashes, pq.Array(ticketHashes))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	infos := make(map[string]*ticketInfoRow, len(ticketHashes))
+	for rows.Next() {
+		var ticketHash string
+		var purchaseHeight uint32
+		var spendBlockHash, spendTxid, missBlockHash sql.NullString
+		var spendHeight, missHeight sql.NullInt64
+		info := &ticketInfoRow{purchaseBlock: new(apitypes.TinyBlock)}
+		err = rows.Scan(&ticketHash, &info.purchaseBlock.Hash, &purchaseHeight,
+			&info.spendStatus, &info.poolStatus, &spendBlockHash, &spendHeight,
+			&spendTxid, &missBlockHash, &missHeight)
+		if err != nil {
+			return nil, err
+		}
+		info.purchaseBlock.Height = purchaseHeight
+		if info.spendStatus != dbtypes.TicketUnspent {
+			info.spendTxid = spendTxid.String
+		}
+		if info.spendStatus == dbtypes.TicketVoted && spendBlockHash.Valid {
+			info.lotteryBlock = &apitypes.TinyBlock{
+				Hash:   spendBlockHash.String,
+				Height: uint32(spendHeight.Int64),
+			}
+		} else if missBlockHash.Valid {
+			info.lotteryBlock = &apitypes.TinyBlock{
+				Hash:   missBlockHash.String,
+				Height: uint32(missHeight.Int64),
+			}
+		}
+		infos[ticketHash] = info
+	}
+	return infos, rows.Err()
+}
+
 // RetrieveTicketIDsByHashes gets the db row IDs (primary keys) in the tickets
 // table for the given ticket purchase transaction hashes.
 func RetrieveTicketIDsByHashes(ctx context.Context, db *sql.DB, ticketHashes []string) (ids []uint64, err error) {
@@ -2615,6 +2891,59 @@ func RetrieveVoutsByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) ([]
 	return vouts, nil
 }
 
+// RetrieveVinsMapByIDs retrieves vin details for the rows of the vins table
+// specified by the provided row IDs, keyed by row ID, with a single query.
+func RetrieveVinsMapByIDs(ctx context.Context, db *sql.DB, vinDbIDs []uint64) (map[uint64]*dbtypes.VinTxProperty, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectAllVinInfoByIDs, pq.Array(vinDbIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	vins := make(map[uint64]*dbtypes.VinTxProperty, len(vinDbIDs))
+	for rows.Next() {
+		var id uint64
+		var vin dbtypes.VinTxProperty
+		err = rows.Scan(&id, &vin.TxID, &vin.TxIndex, &vin.TxTree, &vin.IsValid,
+			&vin.IsMainchain, &vin.Time, &vin.PrevTxHash, &vin.PrevTxIndex,
+			&vin.PrevTxTree, &vin.ValueIn, &vin.TxType)
+		if err != nil {
+			return nil, err
+		}
+		vins[id] = &vin
+	}
+	return vins, rows.Err()
+}
+
+// RetrieveVoutsMapByIDs retrieves vout details for the rows of the vouts table
+// specified by the provided row IDs, keyed by row ID, with a single query.
+func RetrieveVoutsMapByIDs(ctx context.Context, db *sql.DB, voutDbIDs []uint64) (map[uint64]*dbtypes.Vout, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectVoutsByIDs, pq.Array(voutDbIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	vouts := make(map[uint64]*dbtypes.Vout, len(voutDbIDs))
+	for rows.Next() {
+		var id uint64
+		var vout dbtypes.Vout
+		var addresses []string
+		err = rows.Scan(&id, &vout.TxHash, &vout.TxIndex, &vout.TxTree,
+			&vout.Value, &vout.Version, &vout.ScriptPubKey,
+			&vout.ScriptPubKeyData.ReqSigs, &vout.ScriptPubKeyData.Type,
+			pq.Array(&addresses), &vout.Mixed)
+		if err != nil {
+			return nil, err
+		}
+		if len(addresses) > 0 {
+			vout.ScriptPubKeyData.Addresses = addresses
+		}
+		vouts[id] = &vout
+	}
+	return vouts, rows.Err()
+}
+
 func RetrieveUTXOsByVinsJoin(ctx context.Context, db *sql.DB) ([]dbtypes.UTXO, error) {
 	return retrieveUTXOs(ctx, db, internal.SelectUTXOsViaVinsMatch)
 }
@@ -3211,6 +3540,38 @@ func RetrieveDbTxsByHash(ctx context.Context, db *sql.DB, txHash string) (ids []
 	return
 }
 
+// RetrieveDbTxsByHashes retrieves all the rows of the transactions table for
+// the given transaction hashes, with a single query. Transactions in valid and
+// mainchain blocks are first.
+func RetrieveDbTxsByHashes(ctx context.Context, db *sql.DB, txHashes []string) ([]*dbtypes.Tx, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectFullTxsByHashes, pq.Array(txHashes))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var dbTxs []*dbtypes.Tx
+	for rows.Next() {
+		var id uint64
+		var dbTx dbtypes.Tx
+		var vinids, voutids dbtypes.UInt64Array
+		err = rows.Scan(&id,
+			&dbTx.BlockHash, &dbTx.BlockHeight, &dbTx.BlockTime, &dbTx.Time,
+			&dbTx.TxType, &dbTx.Version, &dbTx.Tree, &dbTx.TxID, &dbTx.BlockIndex,
+			&dbTx.Locktime, &dbTx.Expiry, &dbTx.Size, &dbTx.Spent, &dbTx.Sent,
+			&dbTx.Fees, &dbTx.MixCount, &dbTx.MixDenom, &dbTx.NumVin, &vinids,
+			&dbTx.NumVout, &voutids, &dbTx.IsValid, &dbTx.IsMainchainBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		dbTx.VinDbIds = vinids
+		dbTx.VoutDbIds = voutids
+		dbTxs = append(dbTxs, &dbTx)
+	}
+	return dbTxs, rows.Err()
+}
+
 // RetrieveTxnsVinsByBlock retrieves for all the transactions in the specified
 // block the vin_db_ids arrays, is_valid, and is_mainchain. This function is
 // used by handleVinsTableMainchainupgrade, so it should not be subject to
@@ -4918,3 +5279,33 @@ func RetrieveDiff(ctx context.Context, db *sql.DB, timestamp int64) (float64, er
 	err := db.QueryRowContext(ctx, internal.SelectDiffByTime, tDef).Scan(&diff)
 	return diff, err
 }
+
+// RetrieveHeightByTime returns the height of the first mainchain block mined
+// after the provided UNIX timestamp.
+func RetrieveHeightByTime(ctx context.Context, db *sql.DB, timestamp int64) (int64, error) {
+	var height int64
+	tDef := dbtypes.NewTimeDefFromUNIX(timestamp)
+	err := db.QueryRowContext(ctx, internal.SelectHeightByTime, tDef).Scan(&height)
+	return height, err
+}
+
+// RetrieveBlockTimes returns the UNIX time of each of the mainchain blocks at
+// the heights, by height.
+func RetrieveBlockTimes(ctx context.Context, db *sql.DB, heights []int64) (map[int64]int64, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectBlockTimesByHeights, pq.Array(heights))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	times := make(map[int64]int64, len(heights))
+	for rows.Next() {
+		var height int64
+		var blockTime dbtypes.TimeDef
+		if err = rows.Scan(&height, &blockTime); err != nil {
+			return nil, err
+		}
+		times[height] = blockTime.UNIX()
+	}
+	return times, rows.Err()
+}
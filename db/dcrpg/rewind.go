@@ -33,6 +33,7 @@ package dcrpg
 //	9. Remove votes by block_hash
 //	10. Remove misses by block_hash
 //	11. Remove transactions[txdbids] and transactions[stxdbids]
+//	12. Remove swaps by spend_block_hash
 //
 // Use DeleteBlockData to delete all data across these tables for a certain block.
 
@@ -48,6 +49,10 @@ import (
 )
 
 func deleteMissesForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
+	_, err = sqlExec(dbTx, internal.DeleteMissCauses, "failed to delete miss causes", hash)
+	if err != nil {
+		return 0, err
+	}
 	return sqlExec(dbTx, internal.DeleteMisses, "failed to delete misses", hash)
 }
 
@@ -55,6 +60,10 @@ func deleteVotesForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err
 	return sqlExec(dbTx, internal.DeleteVotes, "failed to delete votes", hash)
 }
 
+func deleteSwapsForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
+	return sqlExec(dbTx, internal.DeleteSwapsByBlock, "failed to delete swaps", hash)
+}
+
 func deleteTicketsForBlock(dbTx SqlExecutor, hash string) (rowsDeleted int64, err error) {
 	return sqlExec(dbTx, internal.DeleteTicketsSimple, "failed to delete tickets", hash)
 }
@@ -165,7 +174,7 @@ func RetrieveTxsBestBlockMainchain(ctx context.Context, db *sql.DB) (height int6
 
 // DeleteBlockData removes all data for the specified block from every table.
 // Data are removed from tables in the following order: vins, vouts, addresses,
-// transactions, tickets, votes, misses, blocks, block_chain.
+// transactions, tickets, votes, misses, swaps, blocks, block_chain.
 // WARNING: When no indexes are present, these queries are VERY SLOW.
 func DeleteBlockData(ctx context.Context, db *sql.DB, hash string) (res dbtypes.DeletionSummary, err error) {
 	// The data purge is an all or nothing operation (no partial removal of
@@ -253,6 +262,14 @@ func DeleteBlockData(ctx context.Context, db *sql.DB, hash string) (res dbtypes.
 	}
 	res.Timings.Misses = time.Since(start).Nanoseconds()
 
+	start = time.Now()
+	if res.Swaps, err = deleteSwapsForBlock(dbTx, hash); err != nil {
+		err = fmt.Errorf(`deleteSwapsForBlock failed with "%v". Rollback: %v`,
+			err, dbTx.Rollback())
+		return
+	}
+	res.Timings.Swaps = time.Since(start).Nanoseconds()
+
 	start = time.Now()
 	if res.Blocks, err = deleteBlock(dbTx, hash); err != nil {
 		err = fmt.Errorf(`deleteBlock failed with "%v". Rollback: %v`,
@@ -0,0 +1,217 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/chappjc/trylock"
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+const (
+	// RichListSize is the number of addresses materialized in the rich_list
+	// table.
+	RichListSize = 10000
+
+	// richListRefreshInterval is the number of blocks between refreshes of
+	// the rich list and address distribution, which require aggregating the
+	// unspent outputs of every address.
+	richListRefreshInterval = 12
+)
+
+// richListState tracks the refreshes of the rich list and keeps the most
+// recent address distribution.
+type richListState struct {
+	refreshMtx   trylock.Mutex
+	mtx          sync.RWMutex
+	height       int64
+	distribution *dbtypes.AddressDistribution
+}
+
+// balanceBucketMin returns the minimum balance in atoms of a bucket of
+// internal.SelectAddressBalanceBuckets.
+func balanceBucketMin(bucket int) int64 {
+	if bucket <= -3 {
+		return 0
+	}
+	min := int64(1e8)
+	for ; bucket < 0; bucket++ {
+		min /= 10
+	}
+	for ; bucket > 0; bucket-- {
+		min *= 10
+	}
+	return min
+}
+
+// RefreshRichList materializes the top n addresses by balance in the rich_list
+// table and computes the distribution of the balances of all addresses, at the
+// given main chain height. The treasury balance is not set.
+func RefreshRichList(ctx context.Context, db *sql.DB, n, height int64) (*dbtypes.AddressDistribution, error) {
+	dbtx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer dbtx.Rollback() //nolint:errcheck
+
+	if _, err = dbtx.ExecContext(ctx, internal.CreateAddressBalancesTempTable); err != nil {
+		return nil, err
+	}
+	if _, err = dbtx.ExecContext(ctx, internal.DeleteRichList); err != nil {
+		return nil, err
+	}
+	if _, err = dbtx.ExecContext(ctx, internal.InsertRichList, n, height); err != nil {
+		return nil, err
+	}
+
+	dist := &dbtypes.AddressDistribution{Height: height}
+	var p50, p90, p99, p999 sql.NullInt64
+	err = dbtx.QueryRowContext(ctx, internal.SelectAddressDistribution).Scan(
+		&dist.NumAddresses, &dist.TotalBalance, &dist.Gini, &p50, &p90, &p99, &p999)
+	if err != nil {
+		return nil, err
+	}
+	dist.Percentiles = []dbtypes.BalancePercentile{
+		{Percentile: 50, Balance: p50.Int64},
+		{Percentile: 90, Balance: p90.Int64},
+		{Percentile: 99, Balance: p99.Int64},
+		{Percentile: 99.9, Balance: p999.Int64},
+	}
+
+	var top10, top100, top1000 int64
+	err = dbtx.QueryRowContext(ctx, internal.SelectRichListTopBalances).Scan(
+		&top10, &top100, &top1000)
+	if err != nil {
+		return nil, err
+	}
+	if dist.TotalBalance > 0 {
+		total := float64(dist.TotalBalance)
+		dist.Top10Share = float64(top10) / total
+		dist.Top100Share = float64(top100) / total
+		dist.Top1000Share = float64(top1000) / total
+	}
+
+	rows, err := dbtx.QueryContext(ctx, internal.SelectAddressBalanceBuckets)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var bucket int
+		var bb dbtypes.BalanceBucket
+		if err = rows.Scan(&bucket, &bb.NumAddresses, &bb.Balance); err != nil {
+			return nil, err
+		}
+		bb.MinBalance = balanceBucketMin(bucket)
+		dist.Buckets = append(dist.Buckets, bb)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return dist, dbtx.Commit()
+}
+
+// RetrieveRichList retrieves the top n addresses of the rich_list table.
+func RetrieveRichList(ctx context.Context, db *sql.DB, n int64) ([]*dbtypes.RichListEntry, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectRichList, n)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var entries []*dbtypes.RichListEntry
+	for rows.Next() {
+		var e dbtypes.RichListEntry
+		var height int64
+		if err = rows.Scan(&e.Rank, &e.Address, &e.Balance, &e.NumUTXOs, &height); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// RefreshRichList refreshes the rich list and address distribution every
+// richListRefreshInterval blocks, and on the first block after startup. It
+// returns without waiting if a refresh is already running. RefreshRichList
+// satisfies the Saver of a blockdata.BlockTrigger, which should be Async since
+// the refresh aggregates the unspent outputs of every address.
+func (pgb *ChainDB) RefreshRichList(_ string, height uint32) error {
+	if !pgb.richList.refreshMtx.TryLock() {
+		log.Debugf("Rich list refresh already running. Skipping block %d.", height)
+		return nil
+	}
+	defer pgb.richList.refreshMtx.Unlock()
+
+	pgb.richList.mtx.RLock()
+	lastHeight, hasDist := pgb.richList.height, pgb.richList.distribution != nil
+	pgb.richList.mtx.RUnlock()
+	if hasDist && int64(height) < lastHeight+richListRefreshInterval {
+		return nil
+	}
+
+	start := time.Now()
+	// The refresh may take longer than the query timeout of a regular request.
+	dist, err := RefreshRichList(pgb.ctx, pgb.db, RichListSize, int64(height))
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	treasury, err := pgb.TreasuryBalance()
+	if err != nil {
+		log.Warnf("Failed to get treasury balance for the rich list: %v", err)
+	} else {
+		dist.TreasuryBalance = treasury.Balance
+	}
+	log.Debugf("Refreshed the rich list at height %d in %v.", height, time.Since(start))
+
+	pgb.richList.mtx.Lock()
+	pgb.richList.height = int64(height)
+	pgb.richList.distribution = dist
+	pgb.richList.mtx.Unlock()
+
+	if pgb.charts != nil {
+		pgb.charts.SetAddressDistribution(dist.Buckets)
+	}
+	return nil
+}
+
+// AddressDistribution returns the address balance distribution computed by
+// the last rich list refresh, or nil if there has been no refresh since
+// startup.
+func (pgb *ChainDB) AddressDistribution() *dbtypes.AddressDistribution {
+	pgb.richList.mtx.RLock()
+	defer pgb.richList.mtx.RUnlock()
+	return pgb.richList.distribution
+}
+
+// RichList retrieves the top n addresses by balance as of the last rich list
+// refresh, tagged with the category and label of labeled addresses, with the
+// address distribution, which is nil if it has not been computed since
+// startup.
+func (pgb *ChainDB) RichList(n int64) ([]*dbtypes.RichListEntry, *dbtypes.AddressDistribution, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	entries, err := RetrieveRichList(ctx, pgb.db, n)
+	if err != nil {
+		return nil, nil, pgb.replaceCancelError(err)
+	}
+
+	dist := pgb.AddressDistribution()
+	for _, e := range entries {
+		if dist != nil && dist.TotalBalance > 0 {
+			e.Share = float64(e.Balance) / float64(dist.TotalBalance)
+		}
+		if l := pgb.AddressLabel(e.Address); l != nil {
+			e.Tag = l.Category
+			e.Label = l.Label
+		}
+	}
+	return entries, dist, nil
+}
@@ -0,0 +1,25 @@
+package dcrpg
+
+import (
+	"testing"
+)
+
+func TestBalanceBucketMin(t *testing.T) {
+	tests := []struct {
+		bucket int
+		want   int64
+	}{
+		{-5, 0},
+		{-3, 0},
+		{-2, 1e6},
+		{-1, 1e7},
+		{0, 1e8},
+		{1, 1e9},
+		{6, 1e14},
+	}
+	for _, tt := range tests {
+		if got := balanceBucketMin(tt.bucket); got != tt.want {
+			t.Errorf("balanceBucketMin(%d) = %d, want %d", tt.bucket, got, tt.want)
+		}
+	}
+}
@@ -30,6 +30,22 @@ var createTableStatements = [][2]string{
 	{"proposal_votes", internal.CreateProposalVotesTable},
 	{"stats", internal.CreateStatsTable},
 	{"treasury", internal.CreateTreasuryTable},
+	{"swaps", internal.CreateAtomicSwapTable},
+	{"webhooks", internal.CreateWebhooksTable},
+	{"webhook_addresses", internal.CreateWebhookAddressesTable},
+	{"webhook_deliveries", internal.CreateWebhookDeliveriesTable},
+	{"rich_list", internal.CreateRichListTable},
+	{"address_labels", internal.CreateAddressLabelsTable},
+	{"api_keys", internal.CreateAPIKeysTable},
+	{"api_key_usage", internal.CreateAPIKeyUsageTable},
+	{"tx_broadcasts", internal.CreateTxBroadcastsTable},
+	{"mempool_history", internal.CreateMempoolHistoryTable},
+	{"miss_causes", internal.CreateMissCausesTable},
+	{"treasury_payees", internal.CreateTreasuryPayeesTable},
+	{"politeia_proposals", internal.CreatePoliteiaProposalsTable},
+	{"politeia_proposal_versions", internal.CreatePoliteiaProposalVersionsTable},
+	{"politeia_status_changes", internal.CreatePoliteiaStatusChangesTable},
+	{"politeia_vote_tallies", internal.CreatePoliteiaVoteTalliesTable},
 }
 
 func createTableMap() map[string]string {
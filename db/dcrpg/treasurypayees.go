@@ -0,0 +1,149 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+// treasuryPayeeSpend is the amount paid to a payee address by a tspend.
+type treasuryPayeeSpend struct {
+	blockTime time.Time
+	txHash    string
+	address   string
+	amount    int64
+}
+
+// retrieveTreasuryPayeeSpends retrieves the amount paid to each payee address
+// by each main chain tspend, in block order.
+func retrieveTreasuryPayeeSpends(ctx context.Context, db *sql.DB) ([]*treasuryPayeeSpend, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectTreasuryPayeeSpends)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var spends []*treasuryPayeeSpend
+	for rows.Next() {
+		s := new(treasuryPayeeSpend)
+		if err = rows.Scan(&s.blockTime, &s.txHash, &s.address, &s.amount); err != nil {
+			return nil, err
+		}
+		spends = append(spends, s)
+	}
+	return spends, rows.Err()
+}
+
+// treasuryReportPeriod labels the period of the grouping containing the time,
+// in UTC.
+func treasuryReportPeriod(t time.Time, grouping string) string {
+	t = t.UTC()
+	if grouping == dbtypes.TreasuryReportQuarter {
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+	}
+	return t.Format("2006-01")
+}
+
+// makeTreasurySpendReport totals the payee spends by period of the grouping
+// and payee address, and by period and proposal. The payees map addresses to
+// proposal tokens. The rows are in period order, with the payees ordered by
+// address and the proposals by token.
+func makeTreasurySpendReport(spends []*treasuryPayeeSpend, grouping string, payees map[string]string) *dbtypes.TreasurySpendReport {
+	report := &dbtypes.TreasurySpendReport{
+		Grouping:  grouping,
+		Payees:    []*dbtypes.TreasuryPayeeSpend{},
+		Proposals: []*dbtypes.TreasuryProposalSpend{},
+	}
+
+	type key struct{ period, id string }
+	payeeSpends := make(map[key]*dbtypes.TreasuryPayeeSpend)
+	payeeTSpends := make(map[key]map[string]struct{})
+	propSpends := make(map[key]*dbtypes.TreasuryProposalSpend)
+	propPayees := make(map[key]map[string]struct{})
+	propTSpends := make(map[key]map[string]struct{})
+	for _, s := range spends {
+		period := treasuryReportPeriod(s.blockTime, grouping)
+		proposal := payees[s.address]
+
+		pk := key{period, s.address}
+		ps, found := payeeSpends[pk]
+		if !found {
+			ps = &dbtypes.TreasuryPayeeSpend{
+				Period:   period,
+				Address:  s.address,
+				Proposal: proposal,
+			}
+			payeeSpends[pk] = ps
+			payeeTSpends[pk] = make(map[string]struct{})
+		}
+		ps.Amount += s.amount
+		payeeTSpends[pk][s.txHash] = struct{}{}
+
+		prk := key{period, proposal}
+		prs, found := propSpends[prk]
+		if !found {
+			prs = &dbtypes.TreasuryProposalSpend{
+				Period:   period,
+				Proposal: proposal,
+			}
+			propSpends[prk] = prs
+			propPayees[prk] = make(map[string]struct{})
+			propTSpends[prk] = make(map[string]struct{})
+		}
+		prs.Amount += s.amount
+		propPayees[prk][s.address] = struct{}{}
+		propTSpends[prk][s.txHash] = struct{}{}
+	}
+
+	for k, ps := range payeeSpends {
+		ps.TSpends = int64(len(payeeTSpends[k]))
+		report.Payees = append(report.Payees, ps)
+	}
+	for k, prs := range propSpends {
+		prs.Payees = int64(len(propPayees[k]))
+		prs.TSpends = int64(len(propTSpends[k]))
+		report.Proposals = append(report.Proposals, prs)
+	}
+
+	// The periods are labeled so that they sort in time order.
+	sort.Slice(report.Payees, func(i, j int) bool {
+		a, b := report.Payees[i], report.Payees[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return a.Address < b.Address
+	})
+	sort.Slice(report.Proposals, func(i, j int) bool {
+		a, b := report.Proposals[i], report.Proposals[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return a.Proposal < b.Proposal
+	})
+	return report
+}
+
+// TreasurySpendReport retrieves the amounts paid by the main chain treasury
+// spends to each payee address, and for each Politeia proposal of the
+// configured payee addresses, in each month or quarter of the grouping, one of
+// dbtypes.TreasuryReportMonth or dbtypes.TreasuryReportQuarter.
+func (pgb *ChainDB) TreasurySpendReport(grouping string) (*dbtypes.TreasurySpendReport, error) {
+	if grouping != dbtypes.TreasuryReportMonth && grouping != dbtypes.TreasuryReportQuarter {
+		return nil, fmt.Errorf("invalid treasury report grouping %q", grouping)
+	}
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	spends, err := retrieveTreasuryPayeeSpends(ctx, pgb.db)
+	if err != nil {
+		return nil, pgb.replaceCancelError(err)
+	}
+	return makeTreasurySpendReport(spends, grouping, pgb.treasuryPayees), nil
+}
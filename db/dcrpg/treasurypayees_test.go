@@ -0,0 +1,67 @@
+package dcrpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+func TestMakeTreasurySpendReport(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2021, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	spends := []*treasuryPayeeSpend{
+		{date(4, 10), "ts1", "DsA", 100},
+		{date(4, 10), "ts1", "DsB", 200},
+		{date(5, 10), "ts2", "DsA", 300},
+		{date(5, 10), "ts2", "DsC", 50},
+		{date(7, 1), "ts3", "DsB", 400},
+	}
+	payees := map[string]string{"DsA": "prop1", "DsB": "prop1"}
+
+	report := makeTreasurySpendReport(spends, dbtypes.TreasuryReportMonth, payees)
+	type payeeRow struct {
+		period, address, proposal string
+		amount, tspends           int64
+	}
+	wantPayees := []payeeRow{
+		{"2021-04", "DsA", "prop1", 100, 1},
+		{"2021-04", "DsB", "prop1", 200, 1},
+		{"2021-05", "DsA", "prop1", 300, 1},
+		{"2021-05", "DsC", "", 50, 1},
+		{"2021-07", "DsB", "prop1", 400, 1},
+	}
+	if len(report.Payees) != len(wantPayees) {
+		t.Fatalf("got %d monthly payee rows, expected %d", len(report.Payees), len(wantPayees))
+	}
+	for i, ps := range report.Payees {
+		got := payeeRow{ps.Period, ps.Address, ps.Proposal, ps.Amount, ps.TSpends}
+		if got != wantPayees[i] {
+			t.Errorf("monthly payee row %d: got %+v, expected %+v", i, got, wantPayees[i])
+		}
+	}
+
+	report = makeTreasurySpendReport(spends, dbtypes.TreasuryReportQuarter, payees)
+	type proposalRow struct {
+		period, proposal        string
+		amount, payees, tspends int64
+	}
+	wantProposals := []proposalRow{
+		{"2021-Q2", "", 50, 1, 1},
+		{"2021-Q2", "prop1", 600, 2, 2},
+		{"2021-Q3", "prop1", 400, 1, 1},
+	}
+	if len(report.Proposals) != len(wantProposals) {
+		t.Fatalf("got %d quarterly proposal rows, expected %d", len(report.Proposals), len(wantProposals))
+	}
+	for i, prs := range report.Proposals {
+		got := proposalRow{prs.Period, prs.Proposal, prs.Amount, prs.Payees, prs.TSpends}
+		if got != wantProposals[i] {
+			t.Errorf("quarterly proposal row %d: got %+v, expected %+v", i, got, wantProposals[i])
+		}
+	}
+	if len(report.Payees) != 4 || report.Payees[0].TSpends != 2 || report.Payees[0].Amount != 400 {
+		t.Errorf("unexpected quarterly payee rows %+v", report.Payees)
+	}
+}
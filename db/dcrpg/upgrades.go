@@ -13,8 +13,10 @@ import (
 	"decred.org/dcrwallet/wallet/txrules"
 	"github.com/decred/dcrd/blockchain/stake/v3"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/wire"
 	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
 	"github.com/decred/dcrdata/v6/stakedb"
 	"github.com/decred/dcrdata/v6/txhelpers"
 	"github.com/lib/pq"
@@ -33,7 +35,7 @@ const (
 	// This includes changes such as creating tables, adding/deleting columns,
 	// adding/deleting indexes or any other operations that create, delete, or
 	// modify the definition of any database relation.
-	schemaVersion = 9
+	schemaVersion = 21
 
 	// maintVersion indicates when certain maintenance operations should be
 	// performed for the same compatVersion and schemaVersion. Such operations
@@ -170,15 +172,18 @@ type Upgrader struct {
 	db      *sql.DB
 	bg      BlockGetter
 	stakeDB *stakedb.StakeDatabase
+	params  *chaincfg.Params
 	ctx     context.Context
 }
 
 // NewUpgrader is a contructor for an Upgrader.
-func NewUpgrader(ctx context.Context, db *sql.DB, bg BlockGetter, stakeDB *stakedb.StakeDatabase) *Upgrader {
+func NewUpgrader(ctx context.Context, db *sql.DB, bg BlockGetter, stakeDB *stakedb.StakeDatabase,
+	params *chaincfg.Params) *Upgrader {
 	return &Upgrader{
 		db:      db,
 		bg:      bg,
 		stakeDB: stakeDB,
+		params:  params,
 		ctx:     ctx,
 	}
 }
@@ -383,7 +388,163 @@ func (u *Upgrader) compatVersion1Upgrades(current, target DatabaseVersion) (bool
 		fallthrough
 
 	case 9:
-		// Perform schema v9 maintenance.
+		err = u.upgradeSchema9to10()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.9.0 to 1.10.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 10:
+		err = u.upgradeSchema10to11()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.10.0 to 1.11.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 11:
+		err = u.upgradeSchema11to12()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.11.0 to 1.12.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 12:
+		err = u.upgradeSchema12to13()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.12.0 to 1.13.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 13:
+		err = u.upgradeSchema13to14()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.13.0 to 1.14.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 14:
+		err = u.upgradeSchema14to15()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.14.0 to 1.15.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 15:
+		err = u.upgradeSchema15to16()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.15.0 to 1.16.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 16:
+		err = u.upgradeSchema16to17()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.16.0 to 1.17.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 17:
+		err = u.upgradeSchema17to18()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.17.0 to 1.18.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 18:
+		err = u.upgradeSchema18to19()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.18.0 to 1.19.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 19:
+		err = u.upgradeSchema19to20()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.19.0 to 1.20.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 20:
+		err = u.upgradeSchema20to21()
+		if err != nil {
+			return false, fmt.Errorf("failed to upgrade 1.20.0 to 1.21.0: %v", err)
+		}
+		current.schema++
+		current.maint = 0
+		if storeVers(u.db, &current); err != nil {
+			return false, err
+		}
+
+		fallthrough
+
+	case 21:
+		// Perform schema v21 maintenance.
 
 		// No further upgrades.
 		return upgradeCheck()
@@ -414,6 +575,243 @@ func removeTableComments(db *sql.DB) {
 	}
 }
 
+func (u *Upgrader) upgradeSchema20to21() error {
+	log.Infof("Performing database upgrade 1.20.0 -> 1.21.0")
+
+	// Index the mempool history by disposition and first seen time for the
+	// selection of pending and recently mined transactions.
+	if err := IndexMempoolHistoryTable(u.db); err != nil {
+		return fmt.Errorf("IndexMempoolHistoryTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema19to20() error {
+	log.Infof("Performing database upgrade 1.19.0 -> 1.20.0")
+
+	// Index the proposal votes parsed from the proposals repository by ticket
+	// for the lookup of the proposal votes of tickets and addresses.
+	if err := IndexProposalVotesTableOnTicket(u.db); err != nil {
+		return fmt.Errorf("IndexProposalVotesTableOnTicket: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema18to19() error {
+	log.Infof("Performing database upgrade 1.18.0 -> 1.19.0")
+
+	// Create the tables of the proposals polled from Politeia and their
+	// history. The proposals are synced from Politeia on startup.
+	for _, pair := range [][2]string{
+		{"politeia_proposals", internal.CreatePoliteiaProposalsTable},
+		{"politeia_proposal_versions", internal.CreatePoliteiaProposalVersionsTable},
+		{"politeia_status_changes", internal.CreatePoliteiaStatusChangesTable},
+		{"politeia_vote_tallies", internal.CreatePoliteiaVoteTalliesTable},
+	} {
+		if _, err := u.db.Exec(pair[1]); err != nil {
+			return fmt.Errorf("failed to create %s table: %w", pair[0], err)
+		}
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema17to18() error {
+	log.Infof("Performing database upgrade 1.17.0 -> 1.18.0")
+
+	// Create the treasury_payees table of the outputs of the tspends, and
+	// fill it from the vouts of the tspends already stored.
+	_, err := u.db.Exec(internal.CreateTreasuryPayeesTable)
+	if err != nil {
+		return fmt.Errorf("CreateTreasuryPayeesTable: %w", err)
+	}
+	res, err := u.db.Exec(internal.InsertTreasuryPayeesFromVouts, int(stake.TxTypeTSpend))
+	if err != nil {
+		return fmt.Errorf("InsertTreasuryPayeesFromVouts: %w", err)
+	}
+	if N, err := res.RowsAffected(); err == nil {
+		log.Infof("Stored %d treasury spend payees.", N)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema16to17() error {
+	log.Infof("Performing database upgrade 1.16.0 -> 1.17.0")
+
+	// Create the miss_causes table of the causes of the missed votes.
+	_, err := u.db.Exec(internal.CreateMissCausesTable)
+	if err != nil {
+		return fmt.Errorf("CreateMissCausesTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema15to16() error {
+	log.Infof("Performing database upgrade 1.15.0 -> 1.16.0")
+
+	// Create the mempool_history table of the transactions observed in
+	// mempool.
+	_, err := u.db.Exec(internal.CreateMempoolHistoryTable)
+	if err != nil {
+		return fmt.Errorf("CreateMempoolHistoryTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema14to15() error {
+	log.Infof("Performing database upgrade 1.14.0 -> 1.15.0")
+
+	// Create the tx_broadcasts table of the transactions relayed by the
+	// broadcast API.
+	_, err := u.db.Exec(internal.CreateTxBroadcastsTable)
+	if err != nil {
+		return fmt.Errorf("CreateTxBroadcastsTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema13to14() error {
+	log.Infof("Performing database upgrade 1.13.0 -> 1.14.0")
+
+	// Create the API key tables. Keys are issued through the admin API.
+	_, err := u.db.Exec(internal.CreateAPIKeysTable)
+	if err != nil {
+		return fmt.Errorf("CreateAPIKeysTable: %w", err)
+	}
+	_, err = u.db.Exec(internal.CreateAPIKeyUsageTable)
+	if err != nil {
+		return fmt.Errorf("CreateAPIKeyUsageTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema12to13() error {
+	log.Infof("Performing database upgrade 1.12.0 -> 1.13.0")
+
+	// Create the address_labels table. It is populated from the labels file
+	// on startup.
+	_, err := u.db.Exec(internal.CreateAddressLabelsTable)
+	if err != nil {
+		return fmt.Errorf("CreateAddressLabelsTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema11to12() error {
+	log.Infof("Performing database upgrade 1.11.0 -> 1.12.0")
+
+	// Create the rich_list table. It is populated by the first refresh after
+	// startup.
+	_, err := u.db.Exec(internal.CreateRichListTable)
+	if err != nil {
+		return fmt.Errorf("CreateRichListTable: %w", err)
+	}
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema10to11() error {
+	log.Infof("Performing database upgrade 1.10.0 -> 1.11.0")
+
+	// Create the webhook tables and index the deliveries table. There is no
+	// data to backfill.
+	_, err := u.db.Exec(internal.CreateWebhooksTable)
+	if err != nil {
+		return fmt.Errorf("CreateWebhooksTable: %w", err)
+	}
+	_, err = u.db.Exec(internal.CreateWebhookAddressesTable)
+	if err != nil {
+		return fmt.Errorf("CreateWebhookAddressesTable: %w", err)
+	}
+	_, err = u.db.Exec(internal.CreateWebhookDeliveriesTable)
+	if err != nil {
+		return fmt.Errorf("CreateWebhookDeliveriesTable: %w", err)
+	}
+
+	if err = IndexWebhookDeliveriesTable(u.db); err != nil {
+		return fmt.Errorf("IndexWebhookDeliveriesTable: %w", err)
+	}
+
+	return nil
+}
+
+func (u *Upgrader) upgradeSchema9to10() error {
+	log.Infof("Performing database upgrade 1.9.0 -> 1.10.0")
+
+	// Create and index the swaps table.
+	_, err := u.db.Exec(internal.CreateAtomicSwapTable)
+	if err != nil {
+		return fmt.Errorf("CreateAtomicSwapTable: %w", err)
+	}
+
+	if err = IndexSwapsTableOnSpendTx(u.db); err != nil {
+		return fmt.Errorf("IndexSwapsTableOnSpendTx: %w", err)
+	}
+	if err = IndexSwapsTableOnSecretHash(u.db); err != nil {
+		return fmt.Errorf("IndexSwapsTableOnSecretHash: %w", err)
+	}
+	if err = IndexSwapsTableOnSpendTime(u.db); err != nil {
+		return fmt.Errorf("IndexSwapsTableOnSpendTime: %w", err)
+	}
+	if err = IndexSwapsTableOnAddresses(u.db); err != nil {
+		return fmt.Errorf("IndexSwapsTableOnAddresses: %w", err)
+	}
+
+	// Backfill the swaps table from the main chain blocks with transactions
+	// spending P2SH outputs. The input scripts are not stored in the DB, so
+	// the blocks are fetched from dcrd.
+	log.Infof("Locating blocks with P2SH spends. This will take a while...")
+	rows, err := u.db.QueryContext(u.ctx, internal.SelectP2SHSpendingBlocks)
+	if err != nil {
+		return fmt.Errorf("SelectP2SHSpendingBlocks: %w", err)
+	}
+
+	var blockHashes []string
+	var lastHeight int64
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash, &lastHeight); err != nil {
+			closeRows(rows)
+			return fmt.Errorf("SelectP2SHSpendingBlocks scan: %w", err)
+		}
+		blockHashes = append(blockHashes, hash)
+	}
+	if err = rows.Err(); err != nil {
+		closeRows(rows)
+		return fmt.Errorf("SelectP2SHSpendingBlocks: %w", err)
+	}
+	closeRows(rows)
+
+	log.Infof("Scanning %d blocks (up to height %d) for atomic swap redemptions and refunds...",
+		len(blockHashes), lastHeight)
+	var numSwaps int64
+	for i, hashStr := range blockHashes {
+		if i%5000 == 0 && i > 0 {
+			log.Infof("Scanned %d of %d blocks, found %d swaps so far.", i, len(blockHashes), numSwaps)
+		}
+
+		hash, err := chainhash.NewHashFromStr(hashStr)
+		if err != nil {
+			return fmt.Errorf("invalid block hash %s: %w", hashStr, err)
+		}
+		msgBlock, err := u.bg.GetBlock(u.ctx, hash)
+		if err != nil {
+			return fmt.Errorf("GetBlock(%s): %w", hashStr, err)
+		}
+
+		// The validity of the regular tree does not affect the swaps table.
+		dbTxns, _, dbTxVins := dbtypes.ExtractBlockTransactions(msgBlock,
+			wire.TxTreeRegular, u.params, true, true)
+		n, err := InsertSwaps(u.db, dbTxns, dbTxVins, u.params, true, false)
+		if err != nil {
+			return fmt.Errorf("InsertSwaps: %w", err)
+		}
+		numSwaps += n
+	}
+
+	log.Infof("Inserted %d atomic swap rows.", numSwaps)
+
+	return nil
+}
+
 func (u *Upgrader) upgradeSchema8to9() error {
 	log.Infof("Performing database upgrade 1.8.0 -> 1.9.0")
 
@@ -0,0 +1,176 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chappjc/trylock"
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/lib/pq"
+)
+
+const (
+	// vspStatsRefreshInterval is the number of blocks between refreshes of the
+	// VSP statistics, which require aggregating the tickets of every VSP.
+	vspStatsRefreshInterval = 24
+
+	// vspMinTickets is the minimum number of legacy stake pool tickets with
+	// the same unknown fee address to attribute them to a VSP, so that the
+	// few tickets of other multisig setups are not reported as VSPs.
+	vspMinTickets = 20
+)
+
+// vspStatsState tracks the refreshes of the VSP statistics and keeps the most
+// recent statistics.
+type vspStatsState struct {
+	refreshMtx trylock.Mutex
+	mtx        sync.RWMutex
+	stats      *dbtypes.VSPAnalytics
+}
+
+// RetrieveVSPTicketStats retrieves the ticket statistics of each VSP fee
+// address, which are the known fee addresses, and the fee addresses of at
+// least minTickets legacy stake pool tickets. The Name and Known fields are
+// not set.
+func RetrieveVSPTicketStats(ctx context.Context, db *sql.DB, knownAddrs []string,
+	height, ticketMaturity, minTickets int64) ([]*dbtypes.VSPStats, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectVSPTicketStats,
+		pq.Array(knownAddrs), height-ticketMaturity, ticketMaturity, minTickets)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	var stats []*dbtypes.VSPStats
+	for rows.Next() {
+		var feeAddr string
+		s := new(dbtypes.VSPStats)
+		err = rows.Scan(&feeAddr, &s.Immature, &s.Live, &s.Voted, &s.Missed,
+			&s.Expired, &s.Revoked, &s.AvgVoteLatency)
+		if err != nil {
+			return nil, err
+		}
+		s.FeeAddresses = []string{feeAddr}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// vspTickets is the number of tickets attributed to a VSP.
+func vspTickets(s *dbtypes.VSPStats) int64 {
+	return s.Immature + s.Live + s.Voted + s.Missed + s.Expired
+}
+
+// mergeVSPStats combines the statistics of the fee addresses with the same
+// VSP label, and sets the miss rates. The statistics of unlabeled fee
+// addresses are kept separate. The VSPs are sorted by their number of
+// tickets.
+func mergeVSPStats(stats []*dbtypes.VSPStats, labels map[string]*dbtypes.AddressLabel) []*dbtypes.VSPStats {
+	merged := make([]*dbtypes.VSPStats, 0, len(stats))
+	byName := make(map[string]*dbtypes.VSPStats)
+	for _, s := range stats {
+		l := labels[s.FeeAddresses[0]]
+		if l == nil || l.Category != dbtypes.AddressTagVSP {
+			merged = append(merged, s)
+			continue
+		}
+		s.Known = true
+		s.Name = l.Label
+		if s.Name == "" {
+			s.Name = s.FeeAddresses[0]
+		}
+		vsp := byName[s.Name]
+		if vsp == nil {
+			byName[s.Name] = s
+			merged = append(merged, s)
+			continue
+		}
+		// The average latency is weighted by the number of votes.
+		if voted := vsp.Voted + s.Voted; voted > 0 {
+			vsp.AvgVoteLatency = (vsp.AvgVoteLatency*float64(vsp.Voted) +
+				s.AvgVoteLatency*float64(s.Voted)) / float64(voted)
+		}
+		vsp.FeeAddresses = append(vsp.FeeAddresses, s.FeeAddresses...)
+		vsp.Immature += s.Immature
+		vsp.Live += s.Live
+		vsp.Voted += s.Voted
+		vsp.Missed += s.Missed
+		vsp.Expired += s.Expired
+		vsp.Revoked += s.Revoked
+	}
+
+	for _, s := range merged {
+		if s.Voted+s.Missed > 0 {
+			s.MissRate = float64(s.Missed) / float64(s.Voted+s.Missed)
+		}
+		sort.Strings(s.FeeAddresses)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		ti, tj := vspTickets(merged[i]), vspTickets(merged[j])
+		if ti == tj {
+			return merged[i].FeeAddresses[0] < merged[j].FeeAddresses[0]
+		}
+		return ti > tj
+	})
+	return merged
+}
+
+// RefreshVSPStats refreshes the VSP statistics every vspStatsRefreshInterval
+// blocks, and on the first block after startup. It returns without waiting if
+// a refresh is already running. RefreshVSPStats satisfies the Saver of a
+// blockdata.BlockTrigger, which should be Async since the refresh aggregates
+// the tickets of every VSP.
+func (pgb *ChainDB) RefreshVSPStats(_ string, height uint32) error {
+	if !pgb.vspStats.refreshMtx.TryLock() {
+		log.Debugf("VSP statistics refresh already running. Skipping block %d.", height)
+		return nil
+	}
+	defer pgb.vspStats.refreshMtx.Unlock()
+
+	if last := pgb.VSPStats(); last != nil && int64(height) < last.Height+vspStatsRefreshInterval {
+		return nil
+	}
+
+	knownAddrs := make([]string, 0)
+	for _, l := range pgb.AllAddressLabels(dbtypes.AddressTagVSP) {
+		knownAddrs = append(knownAddrs, l.Address)
+	}
+
+	start := time.Now()
+	// The refresh may take longer than the query timeout of a regular request.
+	stats, err := RetrieveVSPTicketStats(pgb.ctx, pgb.db, knownAddrs, int64(height),
+		int64(pgb.chainParams.TicketMaturity), vspMinTickets)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+	feeAddrs := make([]string, 0, len(stats))
+	for _, s := range stats {
+		feeAddrs = append(feeAddrs, s.FeeAddresses[0])
+	}
+	vsps := mergeVSPStats(stats, pgb.AddressLabels(feeAddrs))
+	log.Debugf("Refreshed the statistics of %d VSPs at height %d in %v.", len(vsps),
+		height, time.Since(start))
+
+	pgb.vspStats.mtx.Lock()
+	pgb.vspStats.stats = &dbtypes.VSPAnalytics{
+		Height: int64(height),
+		VSPs:   vsps,
+	}
+	pgb.vspStats.mtx.Unlock()
+	return nil
+}
+
+// VSPStats returns the VSP statistics computed by the last refresh, or nil if
+// there has been no refresh since startup.
+func (pgb *ChainDB) VSPStats() *dbtypes.VSPAnalytics {
+	pgb.vspStats.mtx.RLock()
+	defer pgb.vspStats.mtx.RUnlock()
+	return pgb.vspStats.stats
+}
@@ -0,0 +1,36 @@
+package dcrpg
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+func TestMergeVSPStats(t *testing.T) {
+	stats := []*dbtypes.VSPStats{
+		{FeeAddresses: []string{"DsFeeA2"}, Live: 5, Voted: 30, Missed: 10, AvgVoteLatency: 100},
+		{FeeAddresses: []string{"DsUnknown"}, Live: 55, Voted: 10},
+		{FeeAddresses: []string{"DsFeeA1"}, Live: 5, Voted: 10, AvgVoteLatency: 200, Revoked: 1},
+		{FeeAddresses: []string{"DsExchange"}, Live: 1},
+	}
+	labels := map[string]*dbtypes.AddressLabel{
+		"DsFeeA1":    {Address: "DsFeeA1", Label: "VSP A", Category: dbtypes.AddressTagVSP},
+		"DsFeeA2":    {Address: "DsFeeA2", Label: "VSP A", Category: dbtypes.AddressTagVSP},
+		"DsExchange": {Address: "DsExchange", Label: "Exchange", Category: dbtypes.AddressTagExchange},
+	}
+
+	got := mergeVSPStats(stats, labels)
+	want := []*dbtypes.VSPStats{
+		{FeeAddresses: []string{"DsUnknown"}, Live: 55, Voted: 10},
+		{Name: "VSP A", FeeAddresses: []string{"DsFeeA1", "DsFeeA2"}, Known: true,
+			Live: 10, Voted: 40, Missed: 10, Revoked: 1, MissRate: 0.2, AvgVoteLatency: 125},
+		{FeeAddresses: []string{"DsExchange"}, Live: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Logf("got %+v", got[i])
+		}
+		t.Fatalf("unexpected merged stats")
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package dcrpg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/decred/dcrdata/db/dcrpg/v6/internal"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// InsertWebhook inserts a webhook and its watched addresses.
+func InsertWebhook(ctx context.Context, db *sql.DB, wh *dbtypes.Webhook) error {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	_, err = dbTx.ExecContext(ctx, internal.InsertWebhookRow, wh.ID, wh.URL,
+		wh.Secret, wh.Confirmations, wh.Created)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, internal.InsertWebhookAddressRow)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return err
+	}
+	for _, addr := range wh.Addresses {
+		if _, err = stmt.ExecContext(ctx, addr, wh.ID); err != nil {
+			_ = stmt.Close()
+			_ = dbTx.Rollback()
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return dbTx.Commit()
+}
+
+// RetrieveWebhook retrieves the webhook with the given ID and its watched
+// addresses, but not its secret. sql.ErrNoRows is returned if there is no such
+// webhook.
+func RetrieveWebhook(ctx context.Context, db *sql.DB, id string) (*dbtypes.Webhook, error) {
+	wh := &dbtypes.Webhook{ID: id}
+	err := db.QueryRowContext(ctx, internal.SelectWebhook, id).Scan(&wh.URL,
+		&wh.Confirmations, &wh.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectWebhookAddresses, id)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+
+	for rows.Next() {
+		var addr string
+		if err = rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		wh.Addresses = append(wh.Addresses, addr)
+	}
+
+	return wh, rows.Err()
+}
+
+// DeleteWebhook deletes the webhook with the given ID, including its watched
+// addresses and deliveries. The returned bool indicates if the webhook existed.
+func DeleteWebhook(ctx context.Context, db *sql.DB, id string) (bool, error) {
+	res, err := db.ExecContext(ctx, internal.DeleteWebhook, id)
+	if err != nil {
+		return false, err
+	}
+	N, err := res.RowsAffected()
+	return N > 0, err
+}
+
+// InsertWebhookMempoolDeliveries creates a mempool event delivery for each
+// webhook watching the address, which is involved in the mempool transaction.
+func InsertWebhookMempoolDeliveries(ctx context.Context, db *sql.DB, address,
+	txHash string, now time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, internal.InsertWebhookMempoolDeliveries,
+		address, txHash, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// InsertWebhookBlockDeliveries creates the event deliveries for a new main
+// chain block: a confirmed event for each transaction in the block involving a
+// watched address, and a confirmations event for each previously mined
+// transaction that reaches its webhook's required confirmations with the
+// block.
+func InsertWebhookBlockDeliveries(ctx context.Context, db *sql.DB, blockHash string,
+	height int64, now time.Time) (int64, error) {
+	dbTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin database transaction: %w", err)
+	}
+
+	res, err := dbTx.ExecContext(ctx, internal.InsertWebhookConfirmedDeliveries,
+		blockHash, now)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, err
+	}
+	numConfirmed, _ := res.RowsAffected()
+
+	res, err = dbTx.ExecContext(ctx, internal.InsertWebhookConfirmationsDeliveries,
+		height, now)
+	if err != nil {
+		_ = dbTx.Rollback()
+		return 0, err
+	}
+	numConfirmations, _ := res.RowsAffected()
+
+	return numConfirmed + numConfirmations, dbTx.Commit()
+}
+
+// InsertWebhookReorgDeliveries creates a reorg event delivery for each
+// confirmed event in the orphaned blocks.
+func InsertWebhookReorgDeliveries(ctx context.Context, db *sql.DB, orphanedBlocks []string,
+	now time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, internal.InsertWebhookReorgDeliveries,
+		pq.Array(orphanedBlocks), now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanWebhookDeliveryRows(rows *sql.Rows, withWebhook bool) ([]*dbtypes.WebhookDelivery, error) {
+	defer closeRows(rows)
+
+	var deliveries []*dbtypes.WebhookDelivery
+	for rows.Next() {
+		var d dbtypes.WebhookDelivery
+		dest := []interface{}{&d.ID, &d.WebhookID, &d.Event, &d.Address,
+			&d.TxHash, &d.BlockHash, &d.BlockHeight, &d.Confirmations,
+			&d.Created, &d.Status, &d.Attempts, &d.NextAttempt,
+			&d.ResponseCode, &d.LastError}
+		if withWebhook {
+			dest = append(dest, &d.URL, &d.Secret)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RetrievePendingWebhookDeliveries retrieves up to limit pending deliveries
+// that are due by the given time, oldest first, including the URL and secret
+// of their webhooks.
+func RetrievePendingWebhookDeliveries(ctx context.Context, db *sql.DB, now time.Time,
+	limit int) ([]*dbtypes.WebhookDelivery, error) {
+	rows, err := db.QueryContext(ctx, internal.SelectPendingWebhookDeliveries,
+		now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanWebhookDeliveryRows(rows, true)
+}
+
+// UpdateWebhookDelivery stores the delivery state (status, attempts, next
+// attempt time, response code and last error) of a delivery.
+func UpdateWebhookDelivery(ctx context.Context, db *sql.DB, d *dbtypes.WebhookDelivery) error {
+	_, err := db.ExecContext(ctx, internal.UpdateWebhookDelivery, d.ID,
+		d.Status, d.Attempts, d.NextAttempt, d.ResponseCode, d.LastError)
+	return err
+}
+
+// RetrieveWebhookDeliveries retrieves the webhook's most recent deliveries,
+// and the total number of deliveries for the webhook.
+func RetrieveWebhookDeliveries(ctx context.Context, db *sql.DB, id string, N,
+	offset int64) ([]*dbtypes.WebhookDelivery, int64, error) {
+	var total int64
+	err := db.QueryRowContext(ctx, internal.SelectWebhookDeliveriesCount, id).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.QueryContext(ctx, internal.SelectWebhookDeliveries, id, N, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	deliveries, err := scanWebhookDeliveryRows(rows, false)
+	return deliveries, total, err
+}
+
+// ReplayWebhookDeliveries resets the webhook's deliveries created at or after
+// the since time to pending so that they are sent again, regardless of their
+// previous delivery state.
+func ReplayWebhookDeliveries(ctx context.Context, db *sql.DB, id string, since,
+	now time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, internal.ReplayWebhookDeliveries, id, since, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CreateWebhook stores a new webhook. The webhook's ID, secret and creation
+// time must be set by the caller.
+func (pgb *ChainDB) CreateWebhook(wh *dbtypes.Webhook) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := InsertWebhook(ctx, pgb.db, wh)
+	return pgb.replaceCancelError(err)
+}
+
+// Webhook retrieves a webhook and its watched addresses. A nil webhook and nil
+// error are returned if there is no webhook with the given ID.
+func (pgb *ChainDB) Webhook(id string) (*dbtypes.Webhook, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	wh, err := RetrieveWebhook(ctx, pgb.db, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return wh, pgb.replaceCancelError(err)
+}
+
+// DeleteWebhook deletes a webhook and its deliveries. The returned bool
+// indicates if the webhook existed.
+func (pgb *ChainDB) DeleteWebhook(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	found, err := DeleteWebhook(ctx, pgb.db, id)
+	return found, pgb.replaceCancelError(err)
+}
+
+// WebhookDeliveries retrieves the N most recent deliveries for a webhook,
+// skipping offset deliveries, and the total number of deliveries.
+func (pgb *ChainDB) WebhookDeliveries(id string, N, offset int64) ([]*dbtypes.WebhookDelivery, int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	deliveries, total, err := RetrieveWebhookDeliveries(ctx, pgb.db, id, N, offset)
+	return deliveries, total, pgb.replaceCancelError(err)
+}
+
+// ReplayWebhookDeliveries schedules a webhook's deliveries created since the
+// given time to be sent again, returning the number of deliveries replayed.
+func (pgb *ChainDB) ReplayWebhookDeliveries(id string, since time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	N, err := ReplayWebhookDeliveries(ctx, pgb.db, id, since, time.Now())
+	return N, pgb.replaceCancelError(err)
+}
+
+// InsertWebhookMempoolDeliveries creates the deliveries for a mempool
+// transaction involving the address.
+func (pgb *ChainDB) InsertWebhookMempoolDeliveries(address, txHash string) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	N, err := InsertWebhookMempoolDeliveries(ctx, pgb.db, address, txHash, time.Now())
+	return N, pgb.replaceCancelError(err)
+}
+
+// InsertWebhookBlockDeliveries creates the deliveries for a new main chain
+// block, which must already be stored.
+func (pgb *ChainDB) InsertWebhookBlockDeliveries(blockHash string, height int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	N, err := InsertWebhookBlockDeliveries(ctx, pgb.db, blockHash, height, time.Now())
+	return N, pgb.replaceCancelError(err)
+}
+
+// PendingWebhookDeliveries retrieves up to limit deliveries that are due to be
+// sent.
+func (pgb *ChainDB) PendingWebhookDeliveries(limit int) ([]*dbtypes.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	deliveries, err := RetrievePendingWebhookDeliveries(ctx, pgb.db, time.Now(), limit)
+	return deliveries, pgb.replaceCancelError(err)
+}
+
+// UpdateWebhookDelivery stores the result of a delivery attempt.
+func (pgb *ChainDB) UpdateWebhookDelivery(d *dbtypes.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+	err := UpdateWebhookDelivery(ctx, pgb.db, d)
+	return pgb.replaceCancelError(err)
+}
+
+// webhooksReorg creates the webhook deliveries for a chain reorganization
+// after the side chain has been connected: a reorg event for each confirmed
+// event in the orphaned blocks, followed by the confirmed and confirmations
+// events for each of the blocks of the new main chain.
+func (pgb *ChainDB) webhooksReorg(reorg *txhelpers.ReorgData) error {
+	ctx, cancel := context.WithTimeout(pgb.ctx, pgb.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	orphaned := make([]string, 0, len(reorg.OldChain))
+	for i := range reorg.OldChain {
+		orphaned = append(orphaned, reorg.OldChain[i].String())
+	}
+	numReorg, err := InsertWebhookReorgDeliveries(ctx, pgb.db, orphaned, now)
+	if err != nil {
+		return pgb.replaceCancelError(err)
+	}
+
+	// NewChain does not include the common ancestor.
+	height := int64(reorg.NewChainHeight) - int64(len(reorg.NewChain))
+	var numConnected int64
+	for i := range reorg.NewChain {
+		height++
+		N, err := InsertWebhookBlockDeliveries(ctx, pgb.db,
+			reorg.NewChain[i].String(), height, now)
+		if err != nil {
+			return pgb.replaceCancelError(err)
+		}
+		numConnected += N
+	}
+
+	if numReorg > 0 || numConnected > 0 {
+		log.Infof("Created %d webhook reorg deliveries and %d deliveries for "+
+			"the new main chain blocks.", numReorg, numConnected)
+	}
+	return nil
+}
@@ -294,6 +294,7 @@ type TicketInfo struct {
 	PoolStatus           string
 	SpendStatus          string
 	LotteryBlock         string  // If the ticket was chosen to vote, it was chosen to vote in this block.
+	MissCause            string  // The cause of the miss of a missed ticket.
 	TicketPoolSize       int64   // Total number of ticket in the pool
 	TicketExpiry         int64   // Total number of blocks before a ticket expires
 	TicketExpiryDaysLeft float64 // Approximate days left before the given ticket expires
@@ -454,7 +455,7 @@ type BlockInfo struct {
 	Tickets               []*TrimmedTxInfo
 	Revs                  []*TrimmedTxInfo
 	Votes                 []*TrimmedTxInfo
-	Misses                []string
+	Misses                []*dbtypes.MissedVote
 	Nonce                 uint32
 	VoteBits              uint16
 	FinalState            string
@@ -1000,6 +1001,9 @@ type MempoolTx struct {
 	Type     string    `json:"Type"`
 	TypeID   int       `json:"typeID"` // stake package types
 	VoteInfo *VoteInfo `json:"vote_info,omitempty"`
+	// Expiry is the height after which the transaction may not be mined, or
+	// zero if it does not expire.
+	Expiry uint32 `json:"expiry,omitempty"`
 }
 
 func (mpt *MempoolTx) DeepCopy() *MempoolTx {
@@ -4,7 +4,7 @@ go 1.15
 
 require (
 	decred.org/dcrwallet v1.7.0
-	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/davecgh/go-spew v1.1.1
 	github.com/decred/base58 v1.0.3
 	github.com/decred/dcrd/blockchain/stake/v3 v3.0.0
 	github.com/decred/dcrd/blockchain/standalone/v2 v2.0.0
@@ -13,6 +13,7 @@ require (
 	github.com/decred/dcrd/database/v2 v2.0.2
 	github.com/decred/dcrd/dcrec v1.0.0
 	github.com/decred/dcrd/dcrutil/v3 v3.0.0
+	github.com/decred/dcrd/hdkeychain/v3 v3.0.0
 	github.com/decred/dcrd/rpc/jsonrpc/types/v2 v2.3.0
 	github.com/decred/dcrd/rpcclient/v6 v6.0.2
 	github.com/decred/dcrd/txscript/v3 v3.0.0
@@ -20,4 +21,5 @@ require (
 	github.com/decred/slog v1.1.0
 	github.com/dgraph-io/badger v1.6.2
 	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
+	gopkg.in/yaml.v2 v2.3.0
 )
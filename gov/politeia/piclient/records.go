@@ -0,0 +1,403 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package piclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"strings"
+	"sync"
+
+	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+// The routes of the records, ticketvote and comments APIs used by Client.
+const (
+	RouteRecordsInventory = "/api/records/v1/inventory"
+	RouteRecords          = "/api/records/v1/records"
+	RouteTimestamps       = "/api/records/v1/timestamps"
+	RouteVoteSummaries    = "/api/ticketvote/v1/summaries"
+	RouteCommentCounts    = "/api/comments/v1/count"
+
+	// RouteVersion is the www v1 version route, which also sets the CSRF
+	// token required by the POST routes.
+	RouteVersion = "/api/v1/version"
+)
+
+// The page sizes of the records, ticketvote and comments APIs.
+const (
+	InventoryPageSize     = 20
+	RecordsPageSize       = 5
+	VoteSummariesPageSize = 5
+	CommentCountsPageSize = 10
+)
+
+// csrfHeader is the header carrying the CSRF token of politeiawww.
+const csrfHeader = "X-Csrf-Token"
+
+// Client retrieves proposals from the records, ticketvote and comments APIs of
+// politeiawww, which replace the legacy www v1 API. All their routes are
+// POSTs requiring the CSRF token and cookie that politeiawww sets on a GET.
+type Client struct {
+	client *http.Client
+	host   string
+
+	csrfMtx sync.Mutex
+	csrf    string
+}
+
+// NewClient creates a Client for the politeiawww at host, e.g.
+// https://proposals.decred.org. A cookie jar is added to a copy of the http
+// client if it has none.
+func NewClient(client *http.Client, host string) (*Client, error) {
+	if client == nil {
+		return nil, fmt.Errorf("invalid http client was passed")
+	}
+	if host == "" {
+		return nil, fmt.Errorf("empty API URL is not supported")
+	}
+	if client.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		c := *client
+		c.Jar = jar
+		client = &c
+	}
+	return &Client{
+		client: client,
+		host:   strings.TrimSuffix(host, "/"),
+	}, nil
+}
+
+// csrfToken returns the CSRF token, requesting a new one if there is none or
+// renew is true.
+func (c *Client) csrfToken(renew bool) (string, error) {
+	c.csrfMtx.Lock()
+	defer c.csrfMtx.Unlock()
+	if c.csrf != "" && !renew {
+		return c.csrf, nil
+	}
+
+	response, err := c.client.Get(c.host + RouteVersion)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %v", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("request (%s) failed with status code: %s",
+			RouteVersion, response.Status)
+	}
+
+	c.csrf = response.Header.Get(csrfHeader)
+	if c.csrf == "" {
+		return "", fmt.Errorf("no CSRF token was returned by %s", RouteVersion)
+	}
+	return c.csrf, nil
+}
+
+// post makes a POST request of the JSON encoded request to the route, and
+// decodes the reply into reply. The CSRF token is renewed once if it is
+// rejected.
+func (c *Client) post(route string, request, reply interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var response *http.Response
+	for renew := false; ; renew = true {
+		csrf, err := c.csrfToken(renew)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest(http.MethodPost, c.host+route, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(csrfHeader, csrf)
+		response, err = c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %v", err)
+		}
+		if response.StatusCode != http.StatusForbidden || renew {
+			break
+		}
+		response.Body.Close()
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1<<10))
+		return fmt.Errorf("request (%s) failed with status code: %s: %s",
+			route, response.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(response.Body).Decode(reply)
+}
+
+// batches splits the tokens into batches of up to size tokens.
+func batches(tokens []string, size int) [][]string {
+	var b [][]string
+	for len(tokens) > size {
+		b = append(b, tokens[:size])
+		tokens = tokens[size:]
+	}
+	if len(tokens) > 0 {
+		b = append(b, tokens)
+	}
+	return b
+}
+
+// Inventory returns the tokens of the vetted records with the status, one of
+// pitypes.RecordStatusPublic, RecordStatusCensored or RecordStatusArchived,
+// newest first.
+func (c *Client) Inventory(status uint32) ([]string, error) {
+	name, ok := pitypes.RecordStatuses[status]
+	if !ok {
+		return nil, fmt.Errorf("invalid record status %d", status)
+	}
+	var tokens []string
+	for page := uint32(1); ; page++ {
+		request := pitypes.RecordsInventory{
+			State:  pitypes.RecordStateVetted,
+			Status: status,
+			Page:   page,
+		}
+		var reply pitypes.RecordsInventoryReply
+		if err := c.post(RouteRecordsInventory, request, &reply); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, reply.Vetted[name]...)
+		if len(reply.Vetted[name]) < InventoryPageSize {
+			return tokens, nil
+		}
+	}
+}
+
+// Records returns the latest version of the records with the tokens, by token,
+// including only their proposal metadata file of all their files.
+func (c *Client) Records(tokens []string) (map[string]*pitypes.Record, error) {
+	records := make(map[string]*pitypes.Record, len(tokens))
+	for _, batch := range batches(tokens, RecordsPageSize) {
+		request := pitypes.Records{
+			Requests: make([]pitypes.RecordRequest, 0, len(batch)),
+		}
+		for _, token := range batch {
+			request.Requests = append(request.Requests, pitypes.RecordRequest{
+				Token:     token,
+				Filenames: []string{pitypes.ProposalMetadataFile},
+			})
+		}
+		var reply pitypes.RecordsReply
+		if err := c.post(RouteRecords, request, &reply); err != nil {
+			return nil, err
+		}
+		for token := range reply.Records {
+			r := reply.Records[token]
+			records[token] = &r
+		}
+	}
+	return records, nil
+}
+
+// VoteSummaries returns the vote summaries of the records with the tokens, by
+// token.
+func (c *Client) VoteSummaries(tokens []string) (map[string]*pitypes.VoteSummary, error) {
+	summaries := make(map[string]*pitypes.VoteSummary, len(tokens))
+	for _, batch := range batches(tokens, VoteSummariesPageSize) {
+		var reply pitypes.VoteSummariesReply
+		err := c.post(RouteVoteSummaries, pitypes.VoteSummaries{Tokens: batch}, &reply)
+		if err != nil {
+			return nil, err
+		}
+		for token := range reply.Summaries {
+			s := reply.Summaries[token]
+			summaries[token] = &s
+		}
+	}
+	return summaries, nil
+}
+
+// CommentCounts returns the number of comments on the records with the
+// tokens, by token.
+func (c *Client) CommentCounts(tokens []string) (map[string]uint32, error) {
+	counts := make(map[string]uint32, len(tokens))
+	for _, batch := range batches(tokens, CommentCountsPageSize) {
+		var reply pitypes.CommentCountsReply
+		err := c.post(RouteCommentCounts, pitypes.CommentCounts{Tokens: batch}, &reply)
+		if err != nil {
+			return nil, err
+		}
+		for token, count := range reply.Counts {
+			counts[token] = count
+		}
+	}
+	return counts, nil
+}
+
+// Timestamps returns the timestamps of a version of the record with the token,
+// proving when its metadata and files were anchored in the Decred blockchain.
+// A version of 0 requests the latest version.
+func (c *Client) Timestamps(token string, version uint32) (*pitypes.TimestampsReply, error) {
+	var reply pitypes.TimestampsReply
+	request := pitypes.Timestamps{Token: token, Version: version}
+	if err := c.post(RouteTimestamps, request, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// Proposals returns the proposals with the tokens, with their vote summaries
+// and numbers of comments, in the order of the tokens. Tokens without a record
+// are skipped.
+func (c *Client) Proposals(tokens []string) ([]*pitypes.ProposalInfo, error) {
+	records, err := c.Records(tokens)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := c.VoteSummaries(tokens)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := c.CommentCounts(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	proposals := make([]*pitypes.ProposalInfo, 0, len(records))
+	for _, token := range tokens {
+		r, found := records[token]
+		if !found {
+			continue
+		}
+		p, err := proposalInfo(token, r, summaries[token], counts[token])
+		if err != nil {
+			return nil, fmt.Errorf("invalid proposal %s: %v", token, err)
+		}
+		proposals = append(proposals, p)
+	}
+	return proposals, nil
+}
+
+// proposalStatus maps the record statuses to the www v1 proposal statuses.
+var proposalStatus = map[uint32]piapi.PropStatusT{
+	pitypes.RecordStatusUnreviewed: piapi.PropStatusNotReviewed,
+	pitypes.RecordStatusPublic:     piapi.PropStatusPublic,
+	pitypes.RecordStatusCensored:   piapi.PropStatusCensored,
+	pitypes.RecordStatusArchived:   piapi.PropStatusAbandoned,
+}
+
+// voteStatus maps the ticketvote vote statuses to the www v1 vote statuses.
+var voteStatus = map[uint32]piapi.PropVoteStatusT{
+	pitypes.TicketVoteStatusUnauthorized: piapi.PropVoteStatusNotAuthorized,
+	pitypes.TicketVoteStatusAuthorized:   piapi.PropVoteStatusAuthorized,
+	pitypes.TicketVoteStatusStarted:      piapi.PropVoteStatusStarted,
+	pitypes.TicketVoteStatusFinished:     piapi.PropVoteStatusFinished,
+	pitypes.TicketVoteStatusApproved:     piapi.PropVoteStatusFinished,
+	pitypes.TicketVoteStatusRejected:     piapi.PropVoteStatusFinished,
+	pitypes.TicketVoteStatusIneligible:   piapi.PropVoteStatusDoesntExist,
+}
+
+// proposalInfo converts a proposal record, its vote summary, which may be nil,
+// and its number of comments into the ProposalInfo of the www v1 API.
+func proposalInfo(token string, r *pitypes.Record, summary *pitypes.VoteSummary,
+	comments uint32) (*pitypes.ProposalInfo, error) {
+	p := &pitypes.ProposalInfo{
+		State:            pitypes.ProposalStateType(r.State),
+		Status:           pitypes.ProposalStatusType(proposalStatus[r.Status]),
+		Timestamp:        uint64(r.Timestamp),
+		Username:         r.Username,
+		Version:          strconv.FormatUint(uint64(r.Version), 10),
+		NumComments:      int32(comments),
+		CensorshipRecord: r.CensorshipRecord,
+	}
+	if p.TokenVal == "" {
+		p.TokenVal = token
+	}
+
+	for _, f := range r.Files {
+		if f.Name != pitypes.ProposalMetadataFile {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %v", f.Name, err)
+		}
+		var pm pitypes.ProposalMetadataPayload
+		if err = json.Unmarshal(payload, &pm); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", f.Name, err)
+		}
+		p.Name = pm.Name
+	}
+
+	for _, md := range r.Metadata {
+		if md.PluginID != pitypes.UserPluginID {
+			continue
+		}
+		switch md.StreamID {
+		case pitypes.UserMetadataStreamID:
+			var um pitypes.UserMetadata
+			if err := json.Unmarshal([]byte(md.Payload), &um); err != nil {
+				return nil, fmt.Errorf("invalid user metadata: %v", err)
+			}
+			p.UserID, p.PublicKey, p.Signature = um.UserID, um.PublicKey, um.Signature
+		case pitypes.StatusChangesStreamID:
+			// The stream is the concatenated status changes, oldest first.
+			dec := json.NewDecoder(strings.NewReader(md.Payload))
+			for dec.More() {
+				var sc pitypes.StatusChange
+				if err := dec.Decode(&sc); err != nil {
+					return nil, fmt.Errorf("invalid status changes: %v", err)
+				}
+				p.StatusChangeMsg = sc.Reason
+				switch sc.Status {
+				case pitypes.RecordStatusPublic:
+					p.PublishedDate = uint64(sc.Timestamp)
+				case pitypes.RecordStatusCensored:
+					p.CensoredDate = uint64(sc.Timestamp)
+				case pitypes.RecordStatusArchived:
+					p.AbandonedDate = uint64(sc.Timestamp)
+				}
+			}
+		}
+	}
+
+	p.ProposalVotes = pitypes.ProposalVotes{
+		Token:      p.TokenVal,
+		VoteStatus: pitypes.VoteStatusType(piapi.PropVoteStatusNotAuthorized),
+	}
+	if summary == nil {
+		return p, nil
+	}
+	p.VoteStatus = pitypes.VoteStatusType(voteStatus[summary.Status])
+	p.NumOfEligibleVotes = int64(summary.EligibleTickets)
+	p.QuorumPercentage = summary.QuorumPercentage
+	p.PassPercentage = summary.PassPercentage
+	if summary.EndBlockHeight > 0 {
+		p.Endheight = strconv.FormatUint(uint64(summary.EndBlockHeight), 10)
+	}
+	for _, res := range summary.Results {
+		p.VoteResults = append(p.VoteResults, pitypes.Results{
+			Option: pitypes.VoteOption{
+				OptionID:    res.ID,
+				Description: res.Description,
+				Bits:        int32(res.VoteBit),
+			},
+			VotesReceived: int64(res.Votes),
+		})
+		p.TotalVotes += int64(res.Votes)
+	}
+	return p, nil
+}
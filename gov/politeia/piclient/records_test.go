@@ -0,0 +1,358 @@
+package piclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+// piServer is a stand-in for politeiawww serving the records, ticketvote and
+// comments routes. Like politeiawww, it requires the CSRF token set by a GET in
+// a header and a cookie of the POSTs, and rejects batches over the page sizes.
+type piServer struct {
+	mtx       sync.Mutex
+	csrf      string
+	requests  map[string]int
+	inventory map[uint32][]string
+	records   map[string]pitypes.Record
+	summaries map[string]pitypes.VoteSummary
+	counts    map[string]uint32
+}
+
+func newPiServer() *piServer {
+	return &piServer{
+		csrf:      "csrf-1",
+		requests:  make(map[string]int),
+		inventory: make(map[uint32][]string),
+		records:   make(map[string]pitypes.Record),
+		summaries: make(map[string]pitypes.VoteSummary),
+		counts:    make(map[string]uint32),
+	}
+}
+
+func (s *piServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.requests[r.URL.Path]++
+
+	if r.Method == http.MethodGet {
+		if r.URL.Path != RouteVersion {
+			http.NotFound(w, r)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "_gorilla_csrf", Value: s.csrf, Path: "/"})
+		w.Header().Set(csrfHeader, s.csrf)
+		fmt.Fprint(w, `{"version":1,"route":"/v1"}`)
+		return
+	}
+
+	cookie, err := r.Cookie("_gorilla_csrf")
+	if err != nil || cookie.Value != s.csrf || r.Header.Get(csrfHeader) != s.csrf {
+		http.Error(w, "Forbidden - CSRF token invalid", http.StatusForbidden)
+		return
+	}
+
+	var reply interface{}
+	tooMany := func(n, size int) bool {
+		if n > size {
+			http.Error(w, `{"errorcode":4}`, http.StatusBadRequest)
+			return true
+		}
+		return false
+	}
+	switch r.URL.Path {
+	case RouteRecordsInventory:
+		var req pitypes.RecordsInventory
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tokens := s.inventory[req.Status]
+		start := int(req.Page-1) * InventoryPageSize
+		if start > len(tokens) {
+			start = len(tokens)
+		}
+		end := start + InventoryPageSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		reply = pitypes.RecordsInventoryReply{
+			Vetted: map[string][]string{pitypes.RecordStatuses[req.Status]: tokens[start:end]},
+		}
+	case RouteRecords:
+		var req pitypes.Records
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if tooMany(len(req.Requests), RecordsPageSize) {
+			return
+		}
+		records := make(map[string]pitypes.Record)
+		for _, rr := range req.Requests {
+			if rec, found := s.records[rr.Token]; found {
+				records[rr.Token] = rec
+			}
+		}
+		reply = pitypes.RecordsReply{Records: records}
+	case RouteTimestamps:
+		var req pitypes.Timestamps
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply = pitypes.TimestampsReply{
+			RecordMetadata: pitypes.Timestamp{
+				Data:       fmt.Sprintf(`{"token":%q,"version":%d}`, req.Token, req.Version),
+				TxID:       "e2f6b5a3c6c84de7bd7c79f0ad3e0c5bb1b3b2e4cfa0b5d4ae0c8c1f1a9b7f21",
+				MerkleRoot: "a1b2",
+			},
+		}
+	case RouteVoteSummaries, RouteCommentCounts:
+		var req struct {
+			Tokens []string `json:"tokens"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == RouteVoteSummaries {
+			if tooMany(len(req.Tokens), VoteSummariesPageSize) {
+				return
+			}
+			summaries := make(map[string]pitypes.VoteSummary)
+			for _, token := range req.Tokens {
+				if vs, found := s.summaries[token]; found {
+					summaries[token] = vs
+				}
+			}
+			reply = pitypes.VoteSummariesReply{Summaries: summaries}
+		} else {
+			if tooMany(len(req.Tokens), CommentCountsPageSize) {
+				return
+			}
+			counts := make(map[string]uint32)
+			for _, token := range req.Tokens {
+				counts[token] = s.counts[token]
+			}
+			reply = pitypes.CommentCountsReply{Counts: counts}
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(reply)
+}
+
+// testRecord creates a public proposal record with the token and name.
+func testRecord(token, name string) pitypes.Record {
+	pm, _ := json.Marshal(pitypes.ProposalMetadataPayload{Name: name, Amount: 1000})
+	return pitypes.Record{
+		State:     pitypes.RecordStateVetted,
+		Status:    pitypes.RecordStatusPublic,
+		Version:   2,
+		Timestamp: 1600000000,
+		Username:  "alice",
+		Metadata: []pitypes.MetadataStream{{
+			PluginID: pitypes.UserPluginID,
+			StreamID: pitypes.UserMetadataStreamID,
+			Payload:  `{"userid":"u1","publickey":"pk1","signature":"sig1"}`,
+		}, {
+			PluginID: pitypes.UserPluginID,
+			StreamID: pitypes.StatusChangesStreamID,
+			Payload: `{"token":"` + token + `","version":1,"status":1,"timestamp":1590000000}` +
+				`{"token":"` + token + `","version":1,"status":2,"reason":"ok","timestamp":1590000100}`,
+		}},
+		Files: []pitypes.AttachmentFile{{
+			Name:    pitypes.ProposalMetadataFile,
+			Payload: base64.StdEncoding.EncodeToString(pm),
+		}},
+		CensorshipRecord: pitypes.CensorshipRecord{TokenVal: token, MerkleRoot: "m", Signature: "s"},
+	}
+}
+
+func TestClientProposals(t *testing.T) {
+	s := newPiServer()
+	var tokens []string
+	for i := 0; i < 12; i++ {
+		token := fmt.Sprintf("%016x", i)
+		tokens = append(tokens, token)
+		s.records[token] = testRecord(token, "Proposal "+token)
+		s.counts[token] = uint32(i)
+	}
+	s.summaries[tokens[0]] = pitypes.VoteSummary{
+		Status:           pitypes.TicketVoteStatusApproved,
+		EndBlockHeight:   500000,
+		EligibleTickets:  40000,
+		QuorumPercentage: 20,
+		PassPercentage:   60,
+		Results: []pitypes.VoteOptionResult{
+			{ID: "no", Description: "Don't approve proposal", VoteBit: 1, Votes: 754},
+			{ID: "yes", Description: "Approve proposal", VoteBit: 2, Votes: 11991},
+		},
+	}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	client, err := NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposals, err := client.Proposals(append(tokens, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proposals) != len(tokens) {
+		t.Fatalf("expected %d proposals, found %d", len(tokens), len(proposals))
+	}
+	for i, p := range proposals {
+		if p.TokenVal != tokens[i] {
+			t.Fatalf("expected proposal %d to be %s, found %s", i, tokens[i], p.TokenVal)
+		}
+	}
+
+	// The tokens are batched by the page sizes, and the CSRF token is
+	// requested once.
+	expectedRequests := map[string]int{
+		RouteVersion:       1,
+		RouteRecords:       3,
+		RouteVoteSummaries: 3,
+		RouteCommentCounts: 2,
+	}
+	if !reflect.DeepEqual(s.requests, expectedRequests) {
+		t.Fatalf("expected requests %v, found %v", expectedRequests, s.requests)
+	}
+
+	p := proposals[0]
+	if p.Name != "Proposal "+tokens[0] || p.State != pitypes.VettedState ||
+		p.Status != pitypes.ProposalStatusType(piapi.PropStatusPublic) ||
+		p.Version != "2" || p.Timestamp != 1600000000 || p.Username != "alice" ||
+		p.UserID != "u1" || p.PublicKey != "pk1" || p.Signature != "sig1" ||
+		p.PublishedDate != 1590000100 || p.StatusChangeMsg != "ok" || p.NumComments != 0 {
+		t.Fatalf("unexpected proposal %+v", p)
+	}
+	votes := p.ProposalVotes
+	if votes.Token != tokens[0] || votes.VoteStatus != pitypes.VoteStatusType(piapi.PropVoteStatusFinished) ||
+		votes.TotalVotes != 12745 || votes.NumOfEligibleVotes != 40000 || votes.Endheight != "500000" ||
+		votes.QuorumPercentage != 20 || votes.PassPercentage != 60 || len(votes.VoteResults) != 2 ||
+		votes.VoteResults[1].Option.OptionID != "yes" || votes.VoteResults[1].VotesReceived != 11991 {
+		t.Fatalf("unexpected proposal votes %+v", votes)
+	}
+
+	p = proposals[11]
+	if p.NumComments != 11 || p.VoteStatus != pitypes.VoteStatusType(piapi.PropVoteStatusNotAuthorized) ||
+		p.Endheight != "" {
+		t.Fatalf("unexpected proposal %+v", p)
+	}
+}
+
+func TestClientInventory(t *testing.T) {
+	s := newPiServer()
+	for i := 0; i < 45; i++ {
+		s.inventory[pitypes.RecordStatusPublic] = append(s.inventory[pitypes.RecordStatusPublic],
+			fmt.Sprintf("%016x", i))
+	}
+	s.inventory[pitypes.RecordStatusArchived] = []string{"a"}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	client, err := NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := client.Inventory(pitypes.RecordStatusPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, s.inventory[pitypes.RecordStatusPublic]) {
+		t.Fatalf("expected tokens %v, found %v", s.inventory[pitypes.RecordStatusPublic], tokens)
+	}
+	if s.requests[RouteRecordsInventory] != 3 {
+		t.Fatalf("expected 3 inventory requests, found %d", s.requests[RouteRecordsInventory])
+	}
+
+	tokens, err = client.Inventory(pitypes.RecordStatusArchived)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"a"}) {
+		t.Fatalf("expected the archived tokens, found %v", tokens)
+	}
+
+	if _, err = client.Inventory(9); err == nil {
+		t.Fatal("expected an error for an invalid status")
+	}
+}
+
+func TestClientCSRF(t *testing.T) {
+	s := newPiServer()
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	client, err := NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.CommentCounts([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An expired CSRF token is renewed.
+	s.mtx.Lock()
+	s.csrf = "csrf-2"
+	s.mtx.Unlock()
+	if _, err = client.CommentCounts([]string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if s.requests[RouteVersion] != 2 || s.requests[RouteCommentCounts] != 3 {
+		t.Fatalf("unexpected requests %v", s.requests)
+	}
+
+	// Failed requests are reported with their status.
+	err = client.post("/api/unknown", struct{}{}, &struct{}{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a not found error, found %v", err)
+	}
+}
+
+func TestClientTimestamps(t *testing.T) {
+	server := httptest.NewServer(newPiServer())
+	defer server.Close()
+
+	client, err := NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := client.Timestamps("abc", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.RecordMetadata.Data != `{"token":"abc","version":2}` || ts.RecordMetadata.TxID == "" {
+		t.Fatalf("unexpected timestamps %+v", ts)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	if _, err := NewClient(nil, "http://localhost"); err == nil {
+		t.Fatal("expected an error for a nil client")
+	}
+	if _, err := NewClient(http.DefaultClient, ""); err == nil {
+		t.Fatal("expected an error for an empty URL")
+	}
+	c, err := NewClient(http.DefaultClient, "http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.host != "http://localhost" || c.client.Jar == nil || http.DefaultClient.Jar != nil {
+		t.Fatalf("unexpected client %+v", c)
+	}
+}
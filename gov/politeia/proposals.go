@@ -6,88 +6,79 @@
 package politeia
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
-	"github.com/asdine/storm/v3"
-	"github.com/asdine/storm/v3/q"
 	"github.com/decred/dcrdata/gov/v4/politeia/piclient"
 	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
-	"github.com/decred/dcrdata/v6/semver"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
 	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
 )
 
-var (
-	// errDef defines the default error returned if the proposals db was not
-	// initialized correctly.
-	errDef = fmt.Errorf("ProposalDB was not initialized correctly")
+// errDef defines the default error returned if the proposals db was not
+// initialized correctly.
+var errDef = fmt.Errorf("ProposalDB was not initialized correctly")
+
+// ProposalStore stores the proposals polled from Politeia and their history.
+// The retrieval methods return sql.ErrNoRows if the proposal is not stored. It
+// is satisfied by *dcrpg.ChainDB.
+type ProposalStore interface {
+	// StorePoliteiaProposal stores the latest version of the proposal, and the
+	// tally of its votes if it is not nil, recording its new versions and
+	// status changes in its history. The ID of the stored proposal is
+	// returned.
+	StorePoliteiaProposal(p *dbtypes.PoliteiaProposal, tally *dbtypes.ProposalVoteTally) (int64, error)
+	PoliteiaProposal(token string) (*dbtypes.PoliteiaProposal, error)
+	PoliteiaProposalByRefID(refID string) (*dbtypes.PoliteiaProposal, error)
+	// PoliteiaProposals retrieves count proposals, skipping offset, starting
+	// with the newest, and the total number of proposals, optionally only
+	// those with one of the vote statuses.
+	PoliteiaProposals(offset, count int64, voteStatuses []int16) ([]*dbtypes.PoliteiaProposal, int64, error)
+}
 
-	// dbVersion is the current required version of the proposals.db.
-	dbVersion = semver.NewSemver(2, 0, 0)
+// The Politeia APIs that the proposals can be synced from.
+const (
+	// WWWAPI is the legacy www v1 API.
+	WWWAPI = "v1"
+	// RecordsAPI is the records, ticketvote and comments APIs that replace
+	// the www v1 API.
+	RecordsAPI = "v2"
 )
 
-// dbinfo defines the property that holds the db version.
-const dbinfo = "_proposals.db_"
-
 // ProposalDB defines the common data needed to query the proposals db.
 type ProposalDB struct {
 	lastSync   int64 // atomic
-	dbP        *storm.DB
+	store      ProposalStore
 	client     *http.Client
 	APIURLpath string
+	// records is the client of the records API, or nil to sync from the www
+	// v1 API at APIURLpath.
+	records *piclient.Client
 }
 
-// NewProposalsDB opens an exiting database or creates a new DB instance with
-// the provided file name. Returns an initialized instance of proposals DB, http
-// client and the formatted politeia API URL path to be used. It also checks the
-// db version, Reindexes the db if need be and sets the required db version.
-func NewProposalsDB(politeiaURL, dbPath string) (*ProposalDB, error) {
+// NewProposalsDB creates a ProposalDB that stores the proposals polled from
+// the Politeia API at politeiaURL in the ProposalStore. api selects the API
+// the proposals are synced from, WWWAPI or RecordsAPI. It also creates the
+// http client and the formatted politeia API URL path to be used.
+func NewProposalsDB(politeiaURL, api string, store ProposalStore) (*ProposalDB, error) {
 	if politeiaURL == "" {
 		return nil, fmt.Errorf("missing politeia API URL")
 	}
 
-	if dbPath == "" {
-		return nil, fmt.Errorf("missing db path")
-	}
-
-	_, err := os.Stat(dbPath)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
-	}
-
-	db, err := storm.Open(dbPath)
-	if err != nil {
-		return nil, err
-	}
-
-	// Checks if the correct db version has been set.
-	var version string
-	err = db.Get(dbinfo, "version", &version)
-	if err != nil && err != storm.ErrNotFound {
-		return nil, err
+	if store == nil {
+		return nil, fmt.Errorf("missing proposal store")
 	}
 
-	if version != dbVersion.String() {
-		// Attempt to delete the ProposalInfo bucket.
-		if err = db.Drop(&pitypes.ProposalInfo{}); err != nil {
-			// If error due bucket not found was returned, ignore it.
-			if !strings.Contains(err.Error(), "not found") {
-				return nil, fmt.Errorf("delete bucket struct failed: %v", err)
-			}
-		}
-
-		// Set the required db version.
-		err = db.Set(dbinfo, "version", dbVersion.String())
-		if err != nil {
-			return nil, err
-		}
-		log.Infof("proposals.db version %v was set", dbVersion)
+	if api != WWWAPI && api != RecordsAPI {
+		return nil, fmt.Errorf("invalid politeia API %q", api)
 	}
 
 	// Create the http client used to query the API endpoints.
@@ -104,21 +95,20 @@ func NewProposalsDB(politeiaURL, dbPath string) (*ProposalDB, error) {
 	versionedPath := fmt.Sprintf("%s/api/v%d", politeiaURL, piapi.PoliteiaWWWAPIVersion)
 
 	proposalDB := &ProposalDB{
-		dbP:        db,
+		store:      store,
 		client:     c,
 		APIURLpath: versionedPath,
 	}
 
-	return proposalDB, nil
-}
-
-// Close closes the proposal DB instance created passed if it not nil.
-func (db *ProposalDB) Close() error {
-	if db == nil || db.dbP == nil {
-		return nil
+	if api == RecordsAPI {
+		var err error
+		proposalDB.records, err = piclient.NewClient(c, politeiaURL)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return db.dbP.Close()
+	return proposalDB, nil
 }
 
 // generateCustomID generates a custom ID that is used to reference the proposals
@@ -191,74 +181,178 @@ func (db *ProposalDB) fetchAPIData(URLParams string) (pitypes.Proposals, error)
 	return publicProposals, nil
 }
 
-// saveProposals adds the proposals data to the db.
+// fetchNewRecords returns the public and archived proposals from the records
+// API that are not stored yet, oldest first.
+func (db *ProposalDB) fetchNewRecords() (pitypes.Proposals, error) {
+	var publicProposals pitypes.Proposals
+	var tokens []string
+	for _, status := range []uint32{pitypes.RecordStatusPublic, pitypes.RecordStatusArchived} {
+		inventory, err := db.records.Inventory(status)
+		if err != nil {
+			return publicProposals, err
+		}
+		for _, token := range inventory {
+			_, err = db.store.PoliteiaProposal(token)
+			if err == sql.ErrNoRows {
+				tokens = append(tokens, token)
+				continue
+			}
+			if err != nil {
+				return publicProposals, err
+			}
+		}
+	}
+
+	proposals, err := db.records.Proposals(tokens)
+	if err != nil {
+		return publicProposals, err
+	}
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return proposals[i].Timestamp < proposals[j].Timestamp
+	})
+	publicProposals.Data = proposals
+	return publicProposals, nil
+}
+
+// retrieveProposals retrieves the latest version of the proposals with the
+// tokens, with their vote status, by token. The proposals that could not be
+// retrieved are logged and omitted.
+func (db *ProposalDB) retrieveProposals(tokens []string) map[string]*pitypes.ProposalInfo {
+	proposals := make(map[string]*pitypes.ProposalInfo, len(tokens))
+	if db.records != nil {
+		records, err := db.records.Proposals(tokens)
+		if err != nil {
+			log.Errorf("Retrieving proposal records failed: %v", err)
+		}
+		for _, pi := range records {
+			proposals[pi.TokenVal] = pi
+		}
+		return proposals
+	}
+
+	for _, token := range tokens {
+		proposal, err := piclient.RetrieveProposalByToken(db.client, db.APIURLpath, token)
+		if err != nil {
+			log.Errorf("RetrieveProposalByToken failed: %v ", err)
+			continue
+		}
+		proposals[token] = proposal.Data
+	}
+	return proposals
+}
+
+// encodeProposal encodes the proposal for the ProposalStore.
+func encodeProposal(pi *pitypes.ProposalInfo) (*dbtypes.PoliteiaProposal, error) {
+	data, err := json.Marshal(pi)
+	if err != nil {
+		return nil, err
+	}
+	return &dbtypes.PoliteiaProposal{
+		ID:            int64(pi.ID),
+		Token:         pi.TokenVal,
+		RefID:         pi.RefID,
+		Version:       pi.Version,
+		Timestamp:     int64(pi.Timestamp),
+		State:         int16(pi.State),
+		Status:        int16(pi.Status),
+		VoteStatus:    int16(pi.VoteStatus),
+		StatusMessage: pi.StatusChangeMsg,
+		Data:          data,
+	}, nil
+}
+
+// decodeProposal decodes the proposal from the ProposalStore.
+func decodeProposal(p *dbtypes.PoliteiaProposal) (*pitypes.ProposalInfo, error) {
+	pi := new(pitypes.ProposalInfo)
+	if err := json.Unmarshal(p.Data, pi); err != nil {
+		return nil, fmt.Errorf("invalid stored proposal %s: %v", p.Token, err)
+	}
+	pi.ID = int(p.ID)
+	pi.RefID = p.RefID
+	return pi, nil
+}
+
+// voteTally is the tally of the votes on the proposal, or nil if its vote has
+// not started.
+func voteTally(pi *pitypes.ProposalInfo) *dbtypes.ProposalVoteTally {
+	switch piapi.PropVoteStatusT(pi.VoteStatus) {
+	case piapi.PropVoteStatusStarted, piapi.PropVoteStatusFinished:
+	default:
+		return nil
+	}
+	tally := &dbtypes.ProposalVoteTally{
+		Token:         pi.TokenVal,
+		VoteStatus:    int16(pi.VoteStatus),
+		TotalVotes:    pi.TotalVotes,
+		EligibleVotes: pi.NumOfEligibleVotes,
+	}
+	for _, result := range pi.VoteResults {
+		switch result.Option.OptionID {
+		case "yes":
+			tally.Yes = result.VotesReceived
+		case "no":
+			tally.No = result.VotesReceived
+		}
+	}
+	return tally
+}
+
+// storeProposal stores the proposal, and the tally of its votes, and sets its
+// ID.
+func (db *ProposalDB) storeProposal(pi *pitypes.ProposalInfo) error {
+	p, err := encodeProposal(pi)
+	if err != nil {
+		return err
+	}
+	id, err := db.store.StorePoliteiaProposal(p, voteTally(pi))
+	if err != nil {
+		return err
+	}
+	pi.ID = int(id)
+	return nil
+}
+
+// uniqueRefID generates the RefID of the proposal from its name. If another
+// proposal has the same name, integers are appended to the RefID until it is
+// unique.
+func (db *ProposalDB) uniqueRefID(pi *pitypes.ProposalInfo) (string, error) {
+	// Attempt to find a unique RefID for a max of 5 times.
+	const maxLoop = 5
+
+	baseID, err := generateCustomID(pi.Name)
+	if err != nil {
+		return "", err
+	}
+	for k := 0; k <= maxLoop; k++ {
+		refID := baseID
+		if k > 0 {
+			refID += strconv.Itoa(k)
+		}
+		p, err := db.store.PoliteiaProposalByRefID(refID)
+		if err == sql.ErrNoRows || (err == nil && p.Token == pi.TokenVal) {
+			return refID, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no unique RefID for proposal %s", pi.TokenVal)
+}
+
+// saveProposals adds the proposals data to the db. A proposal that is already
+// stored is updated, and its RefID is regenerated from its possibly edited
+// name.
 func (db *ProposalDB) saveProposals(publicProposals pitypes.Proposals) (int, error) {
 	var proposalsSaved int
-	// Attempt to save a given a given item for a max of 5 times.
-	const maxLoop = 5
 
 	// Save all the proposals
 	for i, val := range publicProposals.Data {
 		var err error
-		if val.RefID, err = generateCustomID(val.Name); err != nil {
-			return 0, err
-		}
-
-		err = db.dbP.Save(val)
-
-		// When a duplicate CensorshipRecord struct is detected this "already exists"
-		// error is thrown, this means that some edits were made to an older version
-		// of the proposal and its fixed by updating the new changes. In another
-		// case that is more rare, is that the current proposal could have a Name
-		// that generates a RefID similar to one already in the db and appending
-		// integers to it till it becomes unique is the solution.
-		if err == storm.ErrAlreadyExists {
-			var data *pitypes.ProposalInfo
-			// Check if the proposal token already exists in the db.
-			data, err = db.proposal("TokenVal", val.TokenVal)
-			if err == nil && data != nil {
-				// The proposal token already exists thus trigger an update with
-				// the latest details.
-				valCopy := *val
-				valCopy.ID = data.ID
-				suffixStr := ""
-
-				for k := 1; k <= maxLoop; k++ {
-					valCopy.RefID += suffixStr
-					// Attempt to update the old entry.
-					err = db.dbP.Update(&valCopy)
-					if err == storm.ErrAlreadyExists {
-						suffixStr = strconv.Itoa(k)
-						continue
-					}
-					if err != nil {
-						log.Error("storm DB update failed: %v", err)
-					}
-					break
-				}
-			}
-
-			// First try wasn't successful if err != nil.
-			if err != nil {
-				for c := 1; c <= maxLoop; c++ {
-					// Drop the previously assigned ID.
-					val.ID = 0
-
-					val.RefID += strconv.Itoa(c)
-					// Attempt to save a new entry.
-					err = db.dbP.Save(val)
-					if err == storm.ErrAlreadyExists {
-						continue
-					}
-					if err != nil {
-						log.Error("storm DB save failed: %v", err)
-					}
-					break
-				}
-			}
+		if val.RefID, err = db.uniqueRefID(val); err != nil {
+			return i, fmt.Errorf("save operation failed: %v", err)
 		}
 
-		if err != nil {
+		if err = db.storeProposal(val); err != nil {
 			return i, fmt.Errorf("save operation failed: %v", err)
 		}
 
@@ -269,76 +363,73 @@ func (db *ProposalDB) saveProposals(publicProposals pitypes.Proposals) (int, err
 	return proposalsSaved, nil
 }
 
+// decodeProposals decodes the proposals from the ProposalStore.
+func decodeProposals(stored []*dbtypes.PoliteiaProposal) ([]*pitypes.ProposalInfo, error) {
+	proposals := make([]*pitypes.ProposalInfo, 0, len(stored))
+	for _, p := range stored {
+		pi, err := decodeProposal(p)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, pi)
+	}
+	return proposals, nil
+}
+
 // AllProposals fetches all the proposals data saved to the db.
 func (db *ProposalDB) AllProposals(offset, rowsCount int,
 	filterByVoteStatus ...int) (proposals []*pitypes.ProposalInfo,
 	totalCount int, err error) {
-	if db == nil || db.dbP == nil {
+	if db == nil || db.store == nil {
 		return nil, 0, errDef
 	}
 
-	var query storm.Query
+	var voteStatuses []int16
 	if len(filterByVoteStatus) > 0 {
 		// Filter by the votes status
-		query = db.dbP.Select(q.Eq("VoteStatus",
-			pitypes.VoteStatusType(filterByVoteStatus[0])))
-	} else {
-		query = db.dbP.Select()
-	}
-
-	// Count the proposals based on the query created above.
-	totalCount, err = query.Count(&pitypes.ProposalInfo{})
-	if err != nil {
-		return
+		voteStatuses = []int16{int16(filterByVoteStatus[0])}
 	}
 
 	// Return the proposals listing starting with the newest.
-	err = query.Skip(offset).Limit(rowsCount).Reverse().OrderBy("Timestamp").
-		Find(&proposals)
-	if err != nil && err != storm.ErrNotFound {
+	stored, total, err := db.store.PoliteiaProposals(int64(offset), int64(rowsCount), voteStatuses)
+	if err != nil {
 		log.Errorf("Failed to fetch data from Proposals DB: %v", err)
-	} else {
-		err = nil
+		return nil, 0, err
 	}
-
-	return
+	proposals, err = decodeProposals(stored)
+	return proposals, int(total), err
 }
 
 // ProposalByToken returns the single proposal identified by the provided token.
 func (db *ProposalDB) ProposalByToken(proposalToken string) (*pitypes.ProposalInfo, error) {
-	if db == nil || db.dbP == nil {
+	if db == nil || db.store == nil {
 		return nil, errDef
 	}
 
-	return db.proposal("TokenVal", proposalToken)
+	p, err := db.store.PoliteiaProposal(proposalToken)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProposal(p)
 }
 
 // ProposalByRefID returns the single proposal identified by the provided refID.
 // RefID is generated from the proposal name and used as the descriptive part of
 // the URL to proposal details page on the /proposal page.
 func (db *ProposalDB) ProposalByRefID(RefID string) (*pitypes.ProposalInfo, error) {
-	if db == nil || db.dbP == nil {
+	if db == nil || db.store == nil {
 		return nil, errDef
 	}
 
-	return db.proposal("RefID", RefID)
-}
-
-// proposal runs the query with searchBy and searchTerm parameters provided and
-// returns the result.
-func (db *ProposalDB) proposal(searchBy, searchTerm string) (*pitypes.ProposalInfo, error) {
-	var pInfo pitypes.ProposalInfo
-	err := db.dbP.Select(q.Eq(searchBy, searchTerm)).Limit(1).First(&pInfo)
+	p, err := db.store.PoliteiaProposalByRefID(RefID)
 	if err != nil {
-		log.Errorf("Failed to fetch data from Proposals DB: %v", err)
 		return nil, err
 	}
-
-	return &pInfo, nil
+	return decodeProposal(p)
 }
 
 // LastProposalsSync returns the last time a sync to update the proposals was run
-// but not necessarily the last time updates were synced in proposals.db.
+// but not necessarily the last time updates were stored.
 func (db *ProposalDB) LastProposalsSync() int64 {
 	return atomic.LoadInt64(&db.lastSync)
 }
@@ -346,7 +437,7 @@ func (db *ProposalDB) LastProposalsSync() int64 {
 // CheckProposalsUpdates updates the proposal changes if they exist and updates
 // them to the proposal db.
 func (db *ProposalDB) CheckProposalsUpdates() error {
-	if db == nil || db.dbP == nil {
+	if db == nil || db.store == nil {
 		return errDef
 	}
 
@@ -361,16 +452,22 @@ func (db *ProposalDB) CheckProposalsUpdates() error {
 
 	// Retrieve and update any new proposals created since the previous
 	// proposals were stored in the db.
-	lastProposal, err := db.lastSavedProposal()
-	if err != nil && err != storm.ErrNotFound {
-		return fmt.Errorf("lastSavedProposal failed: %v", err)
-	}
+	var publicProposals pitypes.Proposals
+	if db.records != nil {
+		publicProposals, err = db.fetchNewRecords()
+	} else {
+		var lastProposal []*dbtypes.PoliteiaProposal
+		lastProposal, _, err = db.store.PoliteiaProposals(0, 1, nil)
+		if err != nil {
+			return fmt.Errorf("lastSavedProposal failed: %v", err)
+		}
 
-	var queryParam string
-	if len(lastProposal) > 0 && lastProposal[0].TokenVal != "" {
-		queryParam = fmt.Sprintf("?before=%s", lastProposal[0].TokenVal)
+		var queryParam string
+		if len(lastProposal) > 0 && lastProposal[0].Token != "" {
+			queryParam = fmt.Sprintf("?before=%s", lastProposal[0].Token)
+		}
+		publicProposals, err = db.fetchAPIData(queryParam)
 	}
-	publicProposals, err := db.fetchAPIData(queryParam)
 	if err != nil {
 		return err
 	}
@@ -388,73 +485,77 @@ func (db *ProposalDB) CheckProposalsUpdates() error {
 	return nil
 }
 
-func (db *ProposalDB) lastSavedProposal() (lastP []*pitypes.ProposalInfo, err error) {
-	err = db.dbP.Select().Limit(1).OrderBy("Timestamp").Reverse().Find(&lastP)
-	return
-}
-
 // Proposals whose vote statuses are either NotAuthorized, Authorized or Started
 // are considered to be in progress. Data for the in progress proposals is
 // fetched from Politeia API. From the newly fetched proposals data, db update
 // is only made for the vote statuses without NotAuthorized status out of all
-// the new votes statuses fetched.
+// the new votes statuses fetched. The vote tally of the proposals whose vote
+// has started is stored at every poll, even if it has not changed.
 func (db *ProposalDB) updateInProgressProposals() (int, error) {
 	// statuses defines a list of vote statuses whose proposals may need an update.
-	statuses := []pitypes.VoteStatusType{
-		pitypes.VoteStatusType(piapi.PropVoteStatusNotAuthorized),
-		pitypes.VoteStatusType(piapi.PropVoteStatusAuthorized),
-		pitypes.VoteStatusType(piapi.PropVoteStatusStarted),
-	}
-
-	var inProgress []*pitypes.ProposalInfo
-	err := db.dbP.Select(
-		q.Or(
-			q.Eq("VoteStatus", statuses[0]),
-			q.Eq("VoteStatus", statuses[1]),
-			q.Eq("VoteStatus", statuses[2]),
-		),
-	).Find(&inProgress)
-	// Return an error only if the said error is not 'not found' error.
-	if err != nil && err != storm.ErrNotFound {
+	statuses := []int16{
+		int16(piapi.PropVoteStatusNotAuthorized),
+		int16(piapi.PropVoteStatusAuthorized),
+		int16(piapi.PropVoteStatusStarted),
+	}
+
+	_, total, err := db.store.PoliteiaProposals(0, 0, statuses)
+	if err != nil {
 		return 0, err
 	}
+	stored, _, err := db.store.PoliteiaProposals(0, total, statuses)
+	if err != nil {
+		return 0, err
+	}
+	inProgress, err := decodeProposals(stored)
+	if err != nil {
+		return 0, err
+	}
+
+	tokens := make([]string, 0, len(inProgress))
+	for _, val := range inProgress {
+		tokens = append(tokens, val.TokenVal)
+	}
+	proposals := db.retrieveProposals(tokens)
 
 	// count defines the number of total updated records.
 	var count int
 
 	for _, val := range inProgress {
-		proposal, err := piclient.RetrieveProposalByToken(db.client, db.APIURLpath, val.TokenVal)
+		proposal, found := proposals[val.TokenVal]
 		// Do not update if:
-		// 1. piclient.RetrieveProposalByToken returned an error
-		if err != nil {
-			// Since the proposal tokens being updated here are already in the
-			// proposals.db. Do not return errors found since they will still be
-			// updated when the data is available.
-			log.Errorf("RetrieveProposalByToken failed: %v ", err)
+		// 1. The proposal could not be retrieved. Since the proposal tokens
+		// being updated here are already stored, they will still be updated
+		// when the data is available.
+		if !found {
 			continue
 		}
 
-		proposal.Data.ID = val.ID
-		proposal.Data.RefID = val.RefID
+		proposal.ID = val.ID
+		proposal.RefID = val.RefID
 
-		// 2. The new proposal data has not changed.
-		if val.IsEqual(proposal.Data) {
+		// 2. The new proposal data has not changed, and its vote has not
+		// started.
+		changed := !val.IsEqual(proposal)
+		if !changed && voteTally(proposal) == nil {
 			continue
 		}
 
-		// 4. Some or all data returned was empty or invalid.
-		if proposal.Data.TokenVal == "" || proposal.Data.TotalVotes < val.TotalVotes {
+		// 3. Some or all data returned was empty or invalid.
+		if proposal.TokenVal == "" || proposal.TotalVotes < val.TotalVotes {
 			// Should help detect when API changes are effected on Politeia's end.
 			log.Warnf("invalid or empty data entries were returned for %v", val.TokenVal)
 			continue
 		}
 
-		err = db.dbP.Update(proposal.Data)
+		err = db.storeProposal(proposal)
 		if err != nil {
 			return 0, fmt.Errorf("Update for %s failed with error: %v ", val.TokenVal, err)
 		}
 
-		count++
+		if changed {
+			count++
+		}
 	}
 	return count, nil
 }
@@ -1,24 +1,99 @@
 package politeia
 
 import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
-	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"testing"
 
-	"github.com/asdine/storm/v3"
+	"github.com/decred/dcrdata/gov/v4/politeia/piclient"
 	"github.com/decred/dcrdata/gov/v4/politeia/types"
 	pitypes "github.com/decred/dcrdata/gov/v4/politeia/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
 	piapi "github.com/decred/politeia/politeiawww/api/www/v1"
 )
 
-var db *storm.DB
-var tempDir string
+// memStore is an in-memory ProposalStore. Like the PostgreSQL store, it
+// requires the RefIDs to be unique.
+type memStore struct {
+	proposals []*dbtypes.PoliteiaProposal
+	tallies   []*dbtypes.ProposalVoteTally
+}
+
+func (s *memStore) StorePoliteiaProposal(p *dbtypes.PoliteiaProposal, tally *dbtypes.ProposalVoteTally) (int64, error) {
+	pc := *p
+	for _, sp := range s.proposals {
+		if sp.RefID == p.RefID && sp.Token != p.Token {
+			return 0, fmt.Errorf("duplicate RefID %s", p.RefID)
+		}
+	}
+	if tally != nil {
+		s.tallies = append(s.tallies, tally)
+	}
+	for i, sp := range s.proposals {
+		if sp.Token == p.Token {
+			pc.ID = sp.ID
+			s.proposals[i] = &pc
+			return pc.ID, nil
+		}
+	}
+	pc.ID = int64(len(s.proposals) + 1)
+	s.proposals = append(s.proposals, &pc)
+	return pc.ID, nil
+}
+
+func (s *memStore) find(match func(*dbtypes.PoliteiaProposal) bool) (*dbtypes.PoliteiaProposal, error) {
+	for _, p := range s.proposals {
+		if match(p) {
+			return p, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memStore) PoliteiaProposal(token string) (*dbtypes.PoliteiaProposal, error) {
+	return s.find(func(p *dbtypes.PoliteiaProposal) bool { return p.Token == token })
+}
+
+func (s *memStore) PoliteiaProposalByRefID(refID string) (*dbtypes.PoliteiaProposal, error) {
+	return s.find(func(p *dbtypes.PoliteiaProposal) bool { return p.RefID == refID })
+}
+
+func (s *memStore) PoliteiaProposals(offset, count int64, voteStatuses []int16) ([]*dbtypes.PoliteiaProposal, int64, error) {
+	var proposals []*dbtypes.PoliteiaProposal
+	for _, p := range s.proposals {
+		match := len(voteStatuses) == 0
+		for _, vs := range voteStatuses {
+			match = match || p.VoteStatus == vs
+		}
+		if match {
+			proposals = append(proposals, p)
+		}
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Timestamp != proposals[j].Timestamp {
+			return proposals[i].Timestamp > proposals[j].Timestamp
+		}
+		return proposals[i].ID > proposals[j].ID
+	})
+	total := int64(len(proposals))
+	if offset > total {
+		offset = total
+	}
+	if offset+count < total {
+		total = offset + count
+	}
+	return proposals[offset:total], int64(len(proposals)), nil
+}
+
+var store = new(memStore)
 
 // initial sample proposal made.
 var firstProposal = &pitypes.ProposalInfo{
@@ -42,46 +117,27 @@ var firstProposal = &pitypes.ProposalInfo{
 	AbandonedDate: 1543946266,
 }
 
-// TestMain sets up the temporary db needed for testing
+// TestMain sets up the store needed for testing
 func TestMain(m *testing.M) {
-	var err error
-	tempDir, err = ioutil.TempDir(os.TempDir(), "offchain")
-	if err != nil {
-		panic(err)
-	}
-
-	db, err = storm.Open(filepath.Join(tempDir, "test.db"))
-	if err != nil {
-		panic(err)
-	}
-
 	//  Save the first sample proposal
-	err = db.Save(firstProposal)
+	err := (&ProposalDB{store: store}).storeProposal(firstProposal)
 	if err != nil {
 		panic(err)
 	}
 
-	returnVal := m.Run()
-
-	defer db.Close()
-	defer os.RemoveAll(tempDir) // clean up
-
-	os.Exit(returnVal)
+	os.Exit(m.Run())
 }
 
-// TestNewProposalsDB tests creating a new storm db and a http client instance.
+// TestNewProposalsDB tests creating a new proposals db and a http client
+// instance.
 func TestNewProposalsDB(t *testing.T) {
-	var count int
 	inputURLPath := "https://proposals.decred.org"
 	expectedPath := "https://proposals.decred.org/api/v1"
-	getDbPath := func() string {
-		count++
-		return filepath.Join(tempDir, fmt.Sprintf("test%v.db", count))
-	}
 
 	type testData struct {
 		politeiaAPIURL string
-		dbPath         string
+		api            string
+		store          ProposalStore
 
 		// Checks if the db was created and its instance referenced returned.
 		IsdbInstance bool
@@ -91,25 +147,40 @@ func TestNewProposalsDB(t *testing.T) {
 	td := []testData{
 		{
 			politeiaAPIURL: "",
-			dbPath:         "",
+			store:          nil,
 			IsdbInstance:   false,
 			errMsg:         "missing politeia API URL",
 		},
 		{
 			politeiaAPIURL: inputURLPath,
-			dbPath:         "",
+			store:          nil,
 			IsdbInstance:   false,
-			errMsg:         "missing db path",
+			errMsg:         "missing proposal store",
 		},
 		{
 			politeiaAPIURL: "",
-			dbPath:         getDbPath(),
+			store:          new(memStore),
 			IsdbInstance:   false,
 			errMsg:         "missing politeia API URL",
 		},
 		{
 			politeiaAPIURL: inputURLPath,
-			dbPath:         getDbPath(),
+			api:            "v3",
+			store:          new(memStore),
+			IsdbInstance:   false,
+			errMsg:         `invalid politeia API "v3"`,
+		},
+		{
+			politeiaAPIURL: inputURLPath,
+			api:            WWWAPI,
+			store:          new(memStore),
+			IsdbInstance:   true,
+			errMsg:         "",
+		},
+		{
+			politeiaAPIURL: inputURLPath,
+			api:            RecordsAPI,
+			store:          new(memStore),
 			IsdbInstance:   true,
 			errMsg:         "",
 		},
@@ -117,7 +188,7 @@ func TestNewProposalsDB(t *testing.T) {
 
 	for i, data := range td {
 		t.Run("Test_#"+strconv.Itoa(i), func(t *testing.T) {
-			result, err := NewProposalsDB(data.politeiaAPIURL, data.dbPath)
+			result, err := NewProposalsDB(data.politeiaAPIURL, data.api, data.store)
 
 			var expectedErrMsg string
 			if err != nil {
@@ -137,21 +208,18 @@ func TestNewProposalsDB(t *testing.T) {
 					t.Fatal("expected the http client not to be nil but was nil")
 				}
 
-				if result.dbP == nil {
-					t.Fatal("expected the db instance not to be nil but was nil")
+				if result.store == nil {
+					t.Fatal("expected the store not to be nil but was nil")
+				}
+
+				if (result.records != nil) != (data.api == RecordsAPI) {
+					t.Fatalf("expected the records client to be set only for the %s API", RecordsAPI)
 				}
 			} else if result != nil {
 				// The result should be nil since the incorrect inputs resulted
 				// to an error being returned and a nil proposalDB instance.
 				t.Fatalf("expect the returned result to be nil but was not nil")
 			}
-
-			// expects to find the correspnding db at the given path.
-			if data.IsdbInstance {
-				if _, err := os.Stat(data.dbPath); os.IsNotExist(err) {
-					t.Fatalf("expected to find the corresponding db at '%v' path but did not.", data.dbPath)
-				}
-			}
 		})
 	}
 }
@@ -281,7 +349,7 @@ var mockedPayload = &pitypes.ProposalInfo{
 func TestStuff(t *testing.T) {
 	server := mockServer()
 	newDBInstance := &ProposalDB{
-		dbP:        db,
+		store:      store,
 		client:     server.Client(),
 		APIURLpath: server.URL,
 	}
@@ -294,6 +362,16 @@ func TestStuff(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected no error to be returned but found '%v'", err)
 		}
+
+		// The vote of the new proposal is finished, and its tally is stored.
+		if len(store.tallies) != 1 {
+			t.Fatalf("expected to find one vote tally but found %d", len(store.tallies))
+		}
+		tally := store.tallies[0]
+		if tally.Token != mockedPayload.TokenVal || tally.Yes != 11991 || tally.No != 754 ||
+			tally.TotalVotes != 12745 || tally.EligibleVotes != 40958 {
+			t.Fatalf("unexpected vote tally %+v", tally)
+		}
 	})
 
 	// Testing the retrieval of all proposals
@@ -383,6 +461,124 @@ func TestStuff(t *testing.T) {
 	})
 }
 
+// mockRecordsServer mocks the records, ticketvote and comments APIs of
+// politeiawww, with a public proposal whose vote has started with yes votes,
+// and an archived proposal. The records requested are counted.
+func mockRecordsServer(yes *uint64, recordsRequested *int) *httptest.Server {
+	const public = "d3d3f4c34b5c0c9b57c3fd4b9d4ad0e4a3b7eb3e2f7e65a1e4ffa8c0a6b9c1d2"
+	const archived = "a1a1f4c34b5c0c9b57c3fd4b9d4ad0e4a3b7eb3e2f7e65a1e4ffa8c0a6b9c1d2"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("X-Csrf-Token", "csrf")
+			return
+		}
+		if r.Header.Get("X-Csrf-Token") != "csrf" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var resp string
+		switch r.URL.Path {
+		case piclient.RouteRecordsInventory:
+			var req pitypes.RecordsInventory
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			switch req.Status {
+			case pitypes.RecordStatusPublic:
+				resp = `{"vetted":{"public":["` + public + `"]}}`
+			case pitypes.RecordStatusArchived:
+				resp = `{"vetted":{"archived":["` + archived + `"]}}`
+			}
+		case piclient.RouteRecords:
+			var req pitypes.Records
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			records := make(map[string]interface{})
+			for _, rr := range req.Requests {
+				*recordsRequested++
+				status, name, ts := pitypes.RecordStatusPublic, "Public proposal", 1600000000
+				if rr.Token == archived {
+					status, name, ts = pitypes.RecordStatusArchived, "Archived proposal", 1500000000
+				}
+				pm := base64.StdEncoding.EncodeToString([]byte(`{"name":"` + name + `"}`))
+				records[rr.Token] = map[string]interface{}{
+					"state":            pitypes.RecordStateVetted,
+					"status":           status,
+					"version":          1,
+					"timestamp":        ts,
+					"files":            []interface{}{map[string]string{"name": "proposalmetadata.json", "payload": pm}},
+					"censorshiprecord": map[string]string{"token": rr.Token},
+				}
+			}
+			b, _ := json.Marshal(map[string]interface{}{"records": records})
+			resp = string(b)
+		case piclient.RouteVoteSummaries:
+			resp = fmt.Sprintf(`{"summaries":{"%s":{"status":3,"endblockheight":600000,
+				"eligibletickets":40000,"quorumpercentage":20,"passpercentage":60,
+				"results":[{"id":"no","votebit":1,"votes":10},{"id":"yes","votebit":2,"votes":%d}]}}}`,
+				public, *yes)
+		case piclient.RouteCommentCounts:
+			resp = `{"counts":{"` + public + `":3}}`
+		}
+		w.Write([]byte(resp))
+	}))
+}
+
+// TestCheckRecordsUpdates tests syncing the proposals from the records API.
+func TestCheckRecordsUpdates(t *testing.T) {
+	yes := uint64(100)
+	var recordsRequested int
+	server := mockRecordsServer(&yes, &recordsRequested)
+	defer server.Close()
+
+	recordsStore := new(memStore)
+	records, err := piclient.NewClient(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &ProposalDB{store: recordsStore, client: server.Client(), records: records}
+
+	if err = db.CheckProposalsUpdates(); err != nil {
+		t.Fatal(err)
+	}
+	proposals, count, err := db.AllProposals(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || len(proposals) != 2 {
+		t.Fatalf("expected to find two proposals but found %d", count)
+	}
+	p := proposals[0]
+	if p.Name != "Public proposal" || p.RefID != "public-proposal" || p.NumComments != 3 ||
+		p.VoteStatus != pitypes.VoteStatusType(piapi.PropVoteStatusStarted) || p.TotalVotes != 110 {
+		t.Fatalf("unexpected public proposal %+v", p)
+	}
+	p = proposals[1]
+	if p.Name != "Archived proposal" || p.ID != 1 ||
+		p.Status != pitypes.ProposalStatusType(piapi.PropStatusAbandoned) {
+		t.Fatalf("unexpected archived proposal %+v", p)
+	}
+
+	// The started vote is tallied at every poll. Only the in progress
+	// proposals, including the archived proposal whose vote is not
+	// authorized, are retrieved again.
+	yes = 150
+	if err = db.CheckProposalsUpdates(); err != nil {
+		t.Fatal(err)
+	}
+	if recordsRequested != 4 {
+		t.Fatalf("expected 4 records to be requested but found %d", recordsRequested)
+	}
+	if len(recordsStore.tallies) != 2 || recordsStore.tallies[1].Yes != 150 ||
+		recordsStore.tallies[1].TotalVotes != 160 || recordsStore.tallies[1].EligibleVotes != 40000 {
+		t.Fatalf("unexpected vote tallies %+v", recordsStore.tallies)
+	}
+	p, err = db.ProposalByRefID("public-proposal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.TotalVotes != 160 {
+		t.Fatalf("expected the updated total votes 160 but found %d", p.TotalVotes)
+	}
+}
+
 func TestGenerateCustomID(t *testing.T) {
 	type testData struct {
 		title    string
@@ -431,7 +627,7 @@ func TestGenerateCustomID(t *testing.T) {
 
 // TestSaveProposals tests the functionality of saveProposals method.
 func TestSaveProposals(t *testing.T) {
-	newDB := &ProposalDB{dbP: db}
+	newDB := &ProposalDB{store: store}
 
 	copy1FirstProposal := *firstProposal
 	copy2FirstProposal := *firstProposal
@@ -0,0 +1,208 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package types
+
+// The types in this file are those of the records, ticketvote and comments
+// APIs of politeiawww, which replace the legacy www v1 API.
+// https://github.com/decred/politeia/tree/master/politeiawww/api
+
+// Record states of the records API.
+const (
+	RecordStateUnvetted uint32 = 1
+	RecordStateVetted   uint32 = 2
+)
+
+// Record statuses of the records API. RecordStatuses are the names of the
+// statuses in the records inventory.
+const (
+	RecordStatusUnreviewed uint32 = 1
+	RecordStatusPublic     uint32 = 2
+	RecordStatusCensored   uint32 = 3
+	RecordStatusArchived   uint32 = 4
+)
+
+var RecordStatuses = map[uint32]string{
+	RecordStatusUnreviewed: "unreviewed",
+	RecordStatusPublic:     "public",
+	RecordStatusCensored:   "censored",
+	RecordStatusArchived:   "archived",
+}
+
+// Vote statuses of the ticketvote API. A finished vote is approved, rejected or
+// ineligible in newer versions of the API.
+const (
+	TicketVoteStatusUnauthorized uint32 = 1
+	TicketVoteStatusAuthorized   uint32 = 2
+	TicketVoteStatusStarted      uint32 = 3
+	TicketVoteStatusFinished     uint32 = 4
+	TicketVoteStatusApproved     uint32 = 5
+	TicketVoteStatusRejected     uint32 = 6
+	TicketVoteStatusIneligible   uint32 = 7
+)
+
+// The record metadata streams and files used for proposals.
+const (
+	UserPluginID          = "usermd"
+	UserMetadataStreamID  = 1
+	StatusChangesStreamID = 2
+	ProposalMetadataFile  = "proposalmetadata.json"
+)
+
+// RecordsInventory requests a page of the tokens of the records with a state
+// and status, newest first.
+type RecordsInventory struct {
+	State  uint32 `json:"state"`
+	Status uint32 `json:"status"`
+	Page   uint32 `json:"page"`
+}
+
+// RecordsInventoryReply is the reply to RecordsInventory. The tokens are
+// mapped by the name of their status.
+type RecordsInventoryReply struct {
+	Unvetted map[string][]string `json:"unvetted"`
+	Vetted   map[string][]string `json:"vetted"`
+}
+
+// RecordRequest requests a record, with only the named files if any.
+type RecordRequest struct {
+	Token     string   `json:"token"`
+	Filenames []string `json:"filenames,omitempty"`
+}
+
+// Records requests a batch of records.
+type Records struct {
+	Requests []RecordRequest `json:"requests"`
+}
+
+// RecordsReply is the reply to Records, with the records by token.
+type RecordsReply struct {
+	Records map[string]Record `json:"records"`
+}
+
+// Record is the latest version of a record.
+type Record struct {
+	State            uint32           `json:"state"`
+	Status           uint32           `json:"status"`
+	Version          uint32           `json:"version"`
+	Timestamp        int64            `json:"timestamp"`
+	Username         string           `json:"username"`
+	Metadata         []MetadataStream `json:"metadata"`
+	Files            []AttachmentFile `json:"files"`
+	CensorshipRecord CensorshipRecord `json:"censorshiprecord"`
+}
+
+// MetadataStream is a metadata stream of a record, with a JSON encoded
+// payload, or several concatenated for the streams of events.
+type MetadataStream struct {
+	PluginID string `json:"pluginid"`
+	StreamID uint32 `json:"streamid"`
+	Payload  string `json:"payload"`
+}
+
+// UserMetadata is the payload of the user metadata stream of a record.
+type UserMetadata struct {
+	UserID    string `json:"userid"`
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+}
+
+// StatusChange is a status change in the status changes metadata stream of a
+// record.
+type StatusChange struct {
+	Token     string `json:"token"`
+	Version   uint32 `json:"version"`
+	Status    uint32 `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ProposalMetadataPayload is the base64 encoded JSON payload of the
+// proposal metadata file of a proposal record.
+type ProposalMetadataPayload struct {
+	Name      string `json:"name"`
+	Amount    uint64 `json:"amount,omitempty"`
+	StartDate int64  `json:"startdate,omitempty"`
+	EndDate   int64  `json:"enddate,omitempty"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+// Timestamps requests the timestamps of a version of a record.
+type Timestamps struct {
+	Token   string `json:"token"`
+	Version uint32 `json:"version,omitempty"`
+}
+
+// TimestampsReply is the reply to Timestamps, with the timestamps of the
+// record metadata, the metadata streams by plugin ID and stream ID, and the
+// files by name.
+type TimestampsReply struct {
+	RecordMetadata Timestamp                       `json:"recordmetadata"`
+	Metadata       map[string]map[uint32]Timestamp `json:"metadata"`
+	Files          map[string]Timestamp            `json:"files"`
+}
+
+// Timestamp is the proof that data was anchored in a transaction of the Decred
+// blockchain. TxID is empty until the data is anchored.
+type Timestamp struct {
+	Data       string  `json:"data"`
+	Digest     string  `json:"digest"`
+	TxID       string  `json:"txid"`
+	MerkleRoot string  `json:"merkleroot"`
+	Proofs     []Proof `json:"proofs"`
+}
+
+// Proof is an inclusion proof of a Timestamp.
+type Proof struct {
+	Type       string   `json:"type"`
+	Digest     string   `json:"digest"`
+	MerkleRoot string   `json:"merkleroot"`
+	MerklePath []string `json:"merklepath"`
+	ExtraData  string   `json:"extradata"`
+}
+
+// VoteSummaries requests the vote summaries of a batch of records.
+type VoteSummaries struct {
+	Tokens []string `json:"tokens"`
+}
+
+// VoteSummariesReply is the reply to VoteSummaries, with the summaries by
+// token.
+type VoteSummariesReply struct {
+	Summaries map[string]VoteSummary `json:"summaries"`
+}
+
+// VoteSummary summarizes the vote on a record.
+type VoteSummary struct {
+	Type             uint32             `json:"type"`
+	Status           uint32             `json:"status"`
+	Duration         uint32             `json:"duration"`
+	StartBlockHeight uint32             `json:"startblockheight"`
+	StartBlockHash   string             `json:"startblockhash"`
+	EndBlockHeight   uint32             `json:"endblockheight"`
+	EligibleTickets  uint32             `json:"eligibletickets"`
+	QuorumPercentage uint32             `json:"quorumpercentage"`
+	PassPercentage   uint32             `json:"passpercentage"`
+	Results          []VoteOptionResult `json:"results"`
+	BestBlock        uint32             `json:"bestblock"`
+}
+
+// VoteOptionResult is the number of votes for a vote option.
+type VoteOptionResult struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	VoteBit     uint64 `json:"votebit"`
+	Votes       uint64 `json:"votes"`
+}
+
+// CommentCounts requests the number of comments on a batch of records.
+type CommentCounts struct {
+	Tokens []string `json:"tokens"`
+}
+
+// CommentCountsReply is the reply to CommentCounts, with the counts by token.
+type CommentCountsReply struct {
+	Counts map[string]uint32 `json:"counts"`
+}
@@ -14,11 +14,11 @@ const windowSize = 2016
 
 // ProposalInfo holds the proposal details as document here
 // https://github.com/decred/politeia/blob/master/politeiawww/api/www/v1/api.md#user-proposals.
-// It also holds the votes status details. The ID field is auto incremented by
-// the db. A proposal can now be uniquely identified by the RefID value and the
-// the contents on the CensorShipRecord struct.
+// It also holds the votes status details. The ID field is assigned by the db
+// when the proposal is first stored. A proposal can now be uniquely identified
+// by the RefID value and the the contents on the CensorShipRecord struct.
 type ProposalInfo struct {
-	ID              int                `json:"id" storm:"id,increment"`
+	ID              int                `json:"id"`
 	Name            string             `json:"name"`
 	State           ProposalStateType  `json:"state"`
 	Status          ProposalStatusType `json:"status"`
@@ -30,18 +30,16 @@ type ProposalInfo struct {
 	Version         string             `json:"version"`
 	NumComments     int32              `json:"numcomments"`
 	StatusChangeMsg string             `json:"statuschangemessage"`
-	PublishedDate   uint64             `json:"publishedat" storm:"index"`
+	PublishedDate   uint64             `json:"publishedat"`
 	CensoredDate    uint64             `json:"censoredat"`
 	AbandonedDate   uint64             `json:"abandonedat"`
 	// RefID was added to create an easily readable part of the URL that helps
-	// to reference the proposals details page. Storm db ignores entries with
-	// duplicate pk but returns "ErrAlreadyExists" error if the field other than
-	// the pk has the tag "unique".
-	RefID string `storm:"unique"`
-	// "unique" tag helps to detect when a single proposal instance wants to be
-	// pushed to the db as two different instances instead of one. This bug
-	// happened due to edits made to a proposal title thus new RefID was created.
-	CensorshipRecord `json:"censorshiprecord" storm:"unique"`
+	// to reference the proposals details page. It is unique among the stored
+	// proposals.
+	RefID string
+	// The token of the CensorshipRecord identifies a proposal across edits
+	// that change its title, and thus its RefID.
+	CensorshipRecord `json:"censorshiprecord"`
 	ProposalVotes    `json:"votes"`
 	// Files           []AttachmentFile   `json:"files"`
 }
@@ -59,7 +57,7 @@ type Proposal struct {
 // CensorshipRecord is an entry that was created when the proposal was submitted.
 // https://github.com/decred/politeia/blob/master/politeiawww/api/www/v1/api.md#censorship-record
 type CensorshipRecord struct {
-	TokenVal   string `json:"token" storm:"unique"`
+	TokenVal   string `json:"token"`
 	MerkleRoot string `json:"merkle"`
 	Signature  string `json:"signature"`
 }
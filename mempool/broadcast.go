@@ -0,0 +1,485 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"decred.org/dcrwallet/wallet/txrules"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/wire"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/blockdata"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+const (
+	// BroadcastTrackConfirmations is the number of confirmations after which
+	// a mined transaction broadcast is no longer tracked.
+	BroadcastTrackConfirmations = 6
+	// BroadcastTrackDuration is the time after its submission that a
+	// transaction broadcast is tracked if it is not mined.
+	BroadcastTrackDuration = 72 * time.Hour
+
+	broadcastSendTimeout = 10 * time.Second
+	broadcastRelayBuffer = 1024
+)
+
+// BroadcastStore is the persistent storage of the transaction broadcasts, and
+// the source of the main chain transaction outputs and height. It is satisfied
+// by *dcrpg.ChainDB.
+type BroadcastStore interface {
+	StoreTxBroadcast(b *dbtypes.TxBroadcast) error
+	TxBroadcast(txHash string) (*dbtypes.TxBroadcast, error)
+	TrackedTxBroadcasts(since time.Time) ([]*dbtypes.TxBroadcast, error)
+	TxOutSpendStatus(txHash string, index uint32) (value int64, spendTx string, found bool, err error)
+	Height() int64
+}
+
+// BroadcastNode is the dcrd node that relays the transactions, and knows the
+// outputs of the transactions in mempool. It is satisfied by *rpcclient.Client.
+type BroadcastNode interface {
+	SendRawTransaction(ctx context.Context, tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error)
+	GetTxOut(ctx context.Context, txHash *chainhash.Hash, index uint32, mempool bool) (*chainjson.GetTxOutResult, error)
+}
+
+// outpoint identifies a transaction output by the transaction hash and output
+// index, like the inputs of the exptypes.MempoolTx.
+type outpoint struct {
+	hash  string
+	index uint32
+}
+
+// trackedTx is a transaction broadcast that is tracked, with the outpoints
+// spent by the transaction.
+type trackedTx struct {
+	*dbtypes.TxBroadcast
+	inputs []outpoint
+}
+
+// Broadcaster relays transactions to dcrd after validating them, and tracks
+// the relayed transactions through mempool acceptance and mining, or eviction
+// from mempool and double spends. The status of each transaction is stored in
+// the BroadcastStore. Broadcaster is a MempoolDataSaver, updated with each
+// mempool snapshot, and a blockdata.BlockDataSaver that must be run after the
+// block is stored by the BroadcastStore.
+type Broadcaster struct {
+	mtx      sync.Mutex
+	params   *chaincfg.Params
+	store    BroadcastStore
+	node     BroadcastNode
+	relayFee dcrutil.Amount
+	tracked  map[string]*trackedTx
+	// The transactions in mempool and the outpoints they spend, from the last
+	// mempool snapshot and the new transaction signals since.
+	mempoolTxs map[string]struct{}
+	spenders   map[outpoint]string
+	hubRelay   chan pstypes.HubMessage
+}
+
+// NewBroadcaster creates a new Broadcaster. Run must be called to track the
+// relayed transactions as they enter mempool.
+func NewBroadcaster(params *chaincfg.Params, store BroadcastStore, node BroadcastNode) *Broadcaster {
+	return &Broadcaster{
+		params:     params,
+		store:      store,
+		node:       node,
+		relayFee:   txrules.DefaultRelayFeePerKb,
+		tracked:    make(map[string]*trackedTx),
+		mempoolTxs: make(map[string]struct{}),
+		spenders:   make(map[outpoint]string),
+		hubRelay:   make(chan pstypes.HubMessage, broadcastRelayBuffer),
+	}
+}
+
+// HubRelay returns the channel on which the mempool monitor signals new
+// mempool transactions (pstypes.SigNewTx). Other signals are ignored.
+func (bc *Broadcaster) HubRelay() chan<- pstypes.HubMessage {
+	return bc.hubRelay
+}
+
+func rejected(txHash, format string, args ...interface{}) *apitypes.TxBroadcast {
+	return &apitypes.TxBroadcast{
+		TxID:    txHash,
+		Status:  dbtypes.TxBroadcastRejected,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Broadcast validates the hex-encoded transaction, and relays it to dcrd. The
+// transaction must be standard, pay at least the minimum relay fee rate, and
+// spend outputs that are unspent in the main chain or created by transactions
+// in mempool. A transaction that fails validation or is rejected by dcrd has
+// the TxBroadcastRejected status, with the reason in the message. An error is
+// only returned for the failure to check the inputs.
+func (bc *Broadcaster) Broadcast(txHex string) (*apitypes.TxBroadcast, error) {
+	msgTx, err := txhelpers.MsgTxFromHex(txHex)
+	if err != nil {
+		return rejected("", "invalid transaction: %v", err), nil
+	}
+	hash := msgTx.TxHash().String()
+
+	// A transaction that is already in mempool or mined is not relayed again.
+	prev, err := bc.store.TxBroadcast(hash)
+	if err != nil {
+		return nil, fmt.Errorf("TxBroadcast: %w", err)
+	}
+	if prev != nil && (prev.Status == dbtypes.TxBroadcastMempool || prev.Status == dbtypes.TxBroadcastMined) {
+		return bc.apiBroadcast(prev), nil
+	}
+
+	nextHeight := bc.store.Height() + 1
+	treasuryActive := txhelpers.IsTreasuryActive(bc.params.Net, nextHeight)
+	txType := stake.DetermineTxType(msgTx, treasuryActive)
+	switch txType {
+	case stake.TxTypeSSGen, stake.TxTypeTSpend, stake.TxTypeTreasuryBase:
+		return rejected(hash, "%s transactions are not relayed",
+			txhelpers.TxTypeToString(int(txType))), nil
+	}
+	if err = txhelpers.CheckTxStandard(msgTx, treasuryActive, bc.relayFee); err != nil {
+		return rejected(hash, "transaction is not standard: %v", err), nil
+	}
+	if msgTx.Expiry != wire.NoExpiryValue && int64(msgTx.Expiry) <= nextHeight {
+		return rejected(hash, "transaction expired at height %d", msgTx.Expiry), nil
+	}
+
+	// The inputs must be unspent outputs of main chain transactions, or of
+	// transactions in mempool according to dcrd.
+	var totalIn int64
+	for i, txIn := range msgTx.TxIn {
+		op := txIn.PreviousOutPoint
+		bc.mtx.Lock()
+		spender := bc.spenders[outpoint{op.Hash.String(), op.Index}]
+		bc.mtx.Unlock()
+		if spender != "" && spender != hash {
+			return rejected(hash, "input %d is already spent by mempool transaction %s",
+				i, spender), nil
+		}
+
+		value, spendTx, found, err := bc.store.TxOutSpendStatus(op.Hash.String(), op.Index)
+		if err != nil {
+			return nil, fmt.Errorf("TxOutSpendStatus: %w", err)
+		}
+		if found {
+			if spendTx == hash {
+				return rejected(hash, "transaction is already mined"), nil
+			}
+			if spendTx != "" {
+				return rejected(hash, "input %d is already spent by transaction %s",
+					i, spendTx), nil
+			}
+			totalIn += value
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), broadcastSendTimeout)
+		txOut, err := bc.node.GetTxOut(ctx, &op.Hash, op.Index, true)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("GetTxOut: %w", err)
+		}
+		if txOut == nil {
+			return rejected(hash, "input %d spends an unknown or spent output %v", i, op), nil
+		}
+		amt, err := dcrutil.NewAmount(txOut.Value)
+		if err != nil {
+			return rejected(hash, "input %d has an invalid value: %v", i, err), nil
+		}
+		totalIn += int64(amt)
+	}
+
+	size := msgTx.SerializeSize()
+	totalOut := int64(txhelpers.TotalOutFromMsgTx(msgTx))
+	fee := totalIn - totalOut
+	if fee < 0 {
+		return rejected(hash, "total output value of %v is more than the total "+
+			"input value of %v", dcrutil.Amount(totalOut), dcrutil.Amount(totalIn)), nil
+	}
+	// Revocations may be free.
+	minFee := txrules.FeeForSerializeSize(bc.relayFee, size)
+	if txType != stake.TxTypeSSRtx && dcrutil.Amount(fee) < minFee {
+		return rejected(hash, "fee of %v is less than the minimum relay fee of %v "+
+			"(%v/kB)", dcrutil.Amount(fee), minFee, bc.relayFee), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), broadcastSendTimeout)
+	defer cancel()
+	if _, err = bc.node.SendRawTransaction(ctx, msgTx, false); err != nil {
+		return rejected(hash, "dcrd rejected the transaction: %v", err), nil
+	}
+
+	now := dbtypes.NewTimeDef(time.Now())
+	b := &dbtypes.TxBroadcast{
+		TxHash:    hash,
+		TxHex:     txHex,
+		Size:      int32(size),
+		Fee:       fee,
+		FeeRate:   txhelpers.FeeRate(totalIn, totalOut, int64(size)),
+		Submitted: now,
+		Updated:   now,
+		Status:    dbtypes.TxBroadcastSubmitted,
+	}
+	if prev != nil {
+		b.Submitted = prev.Submitted
+	}
+
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	if _, found := bc.mempoolTxs[hash]; found {
+		b.Status = dbtypes.TxBroadcastMempool
+	}
+	t := &trackedTx{TxBroadcast: b, inputs: txInputs(msgTx)}
+	bc.tracked[hash] = t
+	// The transaction was relayed, so report the status even if it is not
+	// stored.
+	if err = bc.store.StoreTxBroadcast(b); err != nil {
+		log.Errorf("Failed to store broadcast of transaction %s: %v", hash, err)
+	}
+	log.Debugf("Relayed transaction %s.", hash)
+
+	return bc.apiBroadcast(b), nil
+}
+
+func txInputs(msgTx *wire.MsgTx) []outpoint {
+	inputs := make([]outpoint, 0, len(msgTx.TxIn))
+	for _, txIn := range msgTx.TxIn {
+		op := txIn.PreviousOutPoint
+		inputs = append(inputs, outpoint{op.Hash.String(), op.Index})
+	}
+	return inputs
+}
+
+// BroadcastStatus returns the status of the broadcast of the transaction with
+// the given hash. A nil status is returned if the transaction was not relayed
+// with Broadcast.
+func (bc *Broadcaster) BroadcastStatus(txHash string) (*apitypes.TxBroadcast, error) {
+	b, err := bc.store.TxBroadcast(txHash)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return bc.apiBroadcast(b), nil
+}
+
+// apiBroadcast converts the stored broadcast to its status in the API.
+func (bc *Broadcaster) apiBroadcast(b *dbtypes.TxBroadcast) *apitypes.TxBroadcast {
+	res := &apitypes.TxBroadcast{
+		TxID:         b.TxHash,
+		Status:       b.Status,
+		Size:         b.Size,
+		Fee:          dcrutil.Amount(b.Fee).ToCoin(),
+		FeeRate:      dcrutil.Amount(b.FeeRate).ToCoin(),
+		Submitted:    b.Submitted.UNIX(),
+		Updated:      b.Updated.UNIX(),
+		BlockHash:    b.BlockHash,
+		BlockHeight:  b.BlockHeight,
+		ConflictTxID: b.ConflictTx,
+		Tracked: !b.Final && (b.Status == dbtypes.TxBroadcastMined ||
+			time.Since(b.Submitted.T) < BroadcastTrackDuration),
+	}
+	if b.Status == dbtypes.TxBroadcastMined {
+		if height := bc.store.Height(); height >= b.BlockHeight {
+			res.Confirmations = height - b.BlockHeight + 1
+		}
+	}
+	return res
+}
+
+// update sets the status of a tracked transaction, and stores it. A final
+// transaction is no longer tracked. The caller must hold bc.mtx.
+func (bc *Broadcaster) update(t *trackedTx, status, blockHash string, blockHeight int64,
+	conflictTx string, final bool) {
+	if t.Status == status && t.BlockHash == blockHash && t.ConflictTx == conflictTx &&
+		t.Final == final {
+		return
+	}
+	t.Status, t.BlockHash, t.BlockHeight = status, blockHash, blockHeight
+	t.ConflictTx, t.Final = conflictTx, final
+	t.Updated = dbtypes.NewTimeDef(time.Now())
+	if final {
+		delete(bc.tracked, t.TxHash)
+	}
+	if err := bc.store.StoreTxBroadcast(t.TxBroadcast); err != nil {
+		log.Errorf("Failed to store broadcast of transaction %s: %v", t.TxHash, err)
+	}
+	log.Debugf("Relayed transaction %s status: %s.", t.TxHash, status)
+}
+
+// conflict returns the transaction other than the tracked transaction that
+// spends one of its inputs according to the spenders map, or an empty string.
+func (t *trackedTx) conflict(spenders map[outpoint]string) string {
+	for _, op := range t.inputs {
+		if spender, found := spenders[op]; found && spender != t.TxHash {
+			return spender
+		}
+	}
+	return ""
+}
+
+// Run loads the tracked transaction broadcasts, and processes the new mempool
+// transaction signals until the context is canceled. It should be launched as
+// a goroutine.
+func (bc *Broadcaster) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	broadcasts, err := bc.store.TrackedTxBroadcasts(time.Now().Add(-BroadcastTrackDuration))
+	if err != nil {
+		log.Errorf("Unable to load the tracked transaction broadcasts: %v", err)
+	}
+	bc.mtx.Lock()
+	for _, b := range broadcasts {
+		msgTx, err := txhelpers.MsgTxFromHex(b.TxHex)
+		if err != nil {
+			log.Errorf("Invalid stored transaction %s: %v", b.TxHash, err)
+			continue
+		}
+		if _, found := bc.tracked[b.TxHash]; !found {
+			bc.tracked[b.TxHash] = &trackedTx{TxBroadcast: b, inputs: txInputs(msgTx)}
+		}
+	}
+	bc.mtx.Unlock()
+	log.Debugf("Tracking %d transaction broadcasts.", len(broadcasts))
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Transaction broadcaster stopped.")
+			return
+		case msg := <-bc.hubRelay:
+			if msg.Signal != pstypes.SigNewTx {
+				continue
+			}
+			tx, ok := msg.Msg.(*exptypes.MempoolTx)
+			if !ok {
+				log.Errorf("sigNewTx did not store a *MempoolTx in Msg.")
+				continue
+			}
+			bc.newMempoolTx(tx)
+		}
+	}
+}
+
+// newMempoolTx adds a new mempool transaction to the mempool inventory, and
+// updates the status of the tracked transactions it is or conflicts with.
+func (bc *Broadcaster) newMempoolTx(tx *exptypes.MempoolTx) {
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+
+	bc.mempoolTxs[tx.Hash] = struct{}{}
+	spenders := make(map[outpoint]string, len(tx.Vin))
+	for _, in := range tx.Vin {
+		op := outpoint{in.TxId, in.Outdex}
+		bc.spenders[op] = tx.Hash
+		spenders[op] = tx.Hash
+	}
+
+	if t := bc.tracked[tx.Hash]; t != nil {
+		bc.update(t, dbtypes.TxBroadcastMempool, "", 0, "", false)
+		return
+	}
+	for _, t := range bc.tracked {
+		if t.Status == dbtypes.TxBroadcastMined {
+			continue
+		}
+		if conflict := t.conflict(spenders); conflict != "" {
+			bc.update(t, dbtypes.TxBroadcastDoubleSpent, "", 0, conflict, false)
+		}
+	}
+}
+
+// StoreMPData replaces the mempool inventory with the mempool snapshot, and
+// updates the status of the tracked transactions. A transaction submitted
+// before the snapshot that is no longer in mempool, and is not mined, was
+// either double spent or evicted. This satisfies the MempoolDataSaver
+// interface.
+func (bc *Broadcaster) StoreMPData(stakeData *StakeData, txs []exptypes.MempoolTx, _ *exptypes.MempoolInfo) {
+	mempoolTxs := make(map[string]struct{}, len(txs))
+	spenders := make(map[outpoint]string)
+	for i := range txs {
+		tx := &txs[i]
+		mempoolTxs[tx.Hash] = struct{}{}
+		for _, in := range tx.Vin {
+			spenders[outpoint{in.TxId, in.Outdex}] = tx.Hash
+		}
+	}
+
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	bc.mempoolTxs, bc.spenders = mempoolTxs, spenders
+
+	for hash, t := range bc.tracked {
+		if _, found := mempoolTxs[hash]; found {
+			// Back in mempool after a reorg or after eviction.
+			bc.update(t, dbtypes.TxBroadcastMempool, "", 0, "", false)
+			continue
+		}
+		if t.Status == dbtypes.TxBroadcastMined || !t.Submitted.T.Before(stakeData.Time) {
+			continue
+		}
+		if conflict := t.conflict(spenders); conflict != "" {
+			bc.update(t, dbtypes.TxBroadcastDoubleSpent, "", 0, conflict, false)
+			continue
+		}
+		if t.Status != dbtypes.TxBroadcastDoubleSpent {
+			bc.update(t, dbtypes.TxBroadcastEvicted, "", 0, "", false)
+		}
+	}
+}
+
+// Store updates the status of the tracked transactions mined in, or double
+// spent by, a new main chain block. The transactions that are mined with
+// BroadcastTrackConfirmations, or double spent in a block, are no longer
+// tracked. This satisfies blockdata.BlockDataSaver.
+func (bc *Broadcaster) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	height := int64(msgBlock.Header.Height)
+	blockHash := msgBlock.BlockHash().String()
+
+	mined := make(map[string]struct{})
+	spenders := make(map[outpoint]string)
+	for _, txs := range [][]*wire.MsgTx{msgBlock.Transactions, msgBlock.STransactions} {
+		for _, tx := range txs {
+			hash := tx.TxHash().String()
+			mined[hash] = struct{}{}
+			for _, op := range txInputs(tx) {
+				spenders[op] = hash
+			}
+		}
+	}
+
+	bc.mtx.Lock()
+	defer bc.mtx.Unlock()
+	for hash, t := range bc.tracked {
+		if _, found := mined[hash]; found {
+			bc.update(t, dbtypes.TxBroadcastMined, blockHash, height, "", false)
+			continue
+		}
+		if conflict := t.conflict(spenders); conflict != "" {
+			bc.update(t, dbtypes.TxBroadcastDoubleSpent, "", 0, conflict, true)
+			continue
+		}
+		switch {
+		case t.Status == dbtypes.TxBroadcastMined && t.BlockHeight >= height:
+			// The block with the transaction was orphaned by a reorg.
+			bc.update(t, dbtypes.TxBroadcastSubmitted, "", 0, "", false)
+		case t.Status == dbtypes.TxBroadcastMined &&
+			height-t.BlockHeight+1 >= BroadcastTrackConfirmations:
+			bc.update(t, dbtypes.TxBroadcastMined, t.BlockHash, t.BlockHeight, "", true)
+		case t.Status != dbtypes.TxBroadcastMined &&
+			time.Since(t.Submitted.T) > BroadcastTrackDuration:
+			// Stop tracking, without changing the stored status.
+			delete(bc.tracked, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package mempool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	"github.com/decred/dcrd/txscript/v3"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+type txOutStatus struct {
+	value   int64
+	spendTx string
+}
+
+type memBroadcastStore struct {
+	broadcasts map[string]dbtypes.TxBroadcast
+	outs       map[outpoint]txOutStatus
+	height     int64
+}
+
+func (s *memBroadcastStore) StoreTxBroadcast(b *dbtypes.TxBroadcast) error {
+	s.broadcasts[b.TxHash] = *b
+	return nil
+}
+
+func (s *memBroadcastStore) TxBroadcast(txHash string) (*dbtypes.TxBroadcast, error) {
+	b, found := s.broadcasts[txHash]
+	if !found {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+func (s *memBroadcastStore) TrackedTxBroadcasts(since time.Time) ([]*dbtypes.TxBroadcast, error) {
+	return nil, nil
+}
+
+func (s *memBroadcastStore) TxOutSpendStatus(txHash string, index uint32) (int64, string, bool, error) {
+	out, found := s.outs[outpoint{txHash, index}]
+	return out.value, out.spendTx, found, nil
+}
+
+func (s *memBroadcastStore) Height() int64 {
+	return s.height
+}
+
+type stubNode struct {
+	sent   []*wire.MsgTx
+	reject bool
+}
+
+func (n *stubNode) SendRawTransaction(_ context.Context, tx *wire.MsgTx, _ bool) (*chainhash.Hash, error) {
+	if n.reject {
+		return nil, errors.New("rejected")
+	}
+	n.sent = append(n.sent, tx)
+	hash := tx.TxHash()
+	return &hash, nil
+}
+
+func (n *stubNode) GetTxOut(context.Context, *chainhash.Hash, uint32, bool) (*chainjson.GetTxOutResult, error) {
+	return nil, nil
+}
+
+func TestBroadcaster(t *testing.T) {
+	pkScript := append([]byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20},
+		bytes.Repeat([]byte{0x01}, 20)...)
+	pkScript = append(pkScript, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+
+	newTx := func(prevHash chainhash.Hash, out int64) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		prevOut := wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular)
+		tx.AddTxIn(wire.NewTxIn(prevOut, 1e8, []byte{txscript.OP_DATA_1, 1}))
+		tx.AddTxOut(wire.NewTxOut(out, pkScript))
+		return tx
+	}
+	txHex := func(tx *wire.MsgTx) string {
+		s, err := txhelpers.MsgTxToHex(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	funding, spent := chainhash.Hash{1}, chainhash.Hash{2}
+	store := &memBroadcastStore{
+		broadcasts: make(map[string]dbtypes.TxBroadcast),
+		outs: map[outpoint]txOutStatus{
+			{funding.String(), 0}: {value: 1e8},
+			{spent.String(), 0}:   {value: 1e8, spendTx: "spender"},
+		},
+		height: 100,
+	}
+	node := new(stubNode)
+	bc := NewBroadcaster(chaincfg.SimNetParams(), store, node)
+
+	// Rejected transactions are not relayed.
+	for name, hex := range map[string]string{
+		"invalid hex":  "zz",
+		"spent input":  txHex(newTx(spent, 9e7)),
+		"unknown":      txHex(newTx(chainhash.Hash{3}, 9e7)),
+		"low fee":      txHex(newTx(funding, 1e8-10)),
+		"overspending": txHex(newTx(funding, 2e8)),
+	} {
+		res, err := bc.Broadcast(hex)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res.Status != dbtypes.TxBroadcastRejected || res.Message == "" {
+			t.Errorf("%s: unexpected status %+v", name, res)
+		}
+	}
+	if len(node.sent) != 0 || len(store.broadcasts) != 0 {
+		t.Fatalf("rejected transactions were relayed")
+	}
+
+	tx := newTx(funding, 9e7)
+	hash := tx.TxHash().String()
+	res, err := bc.Broadcast(txHex(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != dbtypes.TxBroadcastSubmitted || res.Fee != 0.1 || !res.Tracked {
+		t.Fatalf("unexpected status %+v", res)
+	}
+	if len(node.sent) != 1 {
+		t.Fatalf("transaction was not relayed")
+	}
+
+	status := func(want string) *dbtypes.TxBroadcast {
+		t.Helper()
+		b := store.broadcasts[hash]
+		if b.Status != want {
+			t.Fatalf("status %q, expected %q", b.Status, want)
+		}
+		return &b
+	}
+
+	mempoolTx := exptypes.MempoolTx{Hash: hash, Vin: exptypes.MsgTxMempoolInputs(tx)}
+	bc.newMempoolTx(&mempoolTx)
+	status(dbtypes.TxBroadcastMempool)
+
+	// Missing from a later mempool snapshot.
+	stakeData := &StakeData{Time: time.Now().Add(time.Second)}
+	bc.StoreMPData(stakeData, nil, nil)
+	status(dbtypes.TxBroadcastEvicted)
+
+	// Back in mempool, then mined.
+	bc.StoreMPData(stakeData, []exptypes.MempoolTx{mempoolTx}, nil)
+	status(dbtypes.TxBroadcastMempool)
+	block := &wire.MsgBlock{Header: wire.BlockHeader{Height: 101}}
+	block.AddTransaction(tx)
+	if err = bc.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	store.height = 101
+	b := status(dbtypes.TxBroadcastMined)
+	if b.BlockHeight != 101 || b.Final {
+		t.Errorf("unexpected broadcast %+v", b)
+	}
+	if res, _ = bc.BroadcastStatus(hash); res.Confirmations != 1 {
+		t.Errorf("%d confirmations, expected 1", res.Confirmations)
+	}
+
+	// Mined with enough confirmations.
+	next := &wire.MsgBlock{Header: wire.BlockHeader{Height: 100 + BroadcastTrackConfirmations}}
+	if err = bc.Store(nil, next); err != nil {
+		t.Fatal(err)
+	}
+	if b = status(dbtypes.TxBroadcastMined); !b.Final || len(bc.tracked) != 0 {
+		t.Errorf("mined transaction is still tracked")
+	}
+
+	// A transaction double spent in a block.
+	store.outs[outpoint{chainhash.Hash{4}.String(), 0}] = txOutStatus{value: 1e8}
+	tx = newTx(chainhash.Hash{4}, 9e7)
+	hash = tx.TxHash().String()
+	if _, err = bc.Broadcast(txHex(tx)); err != nil {
+		t.Fatal(err)
+	}
+	doubleSpend := newTx(chainhash.Hash{4}, 8e7)
+	block = &wire.MsgBlock{Header: wire.BlockHeader{Height: 200}}
+	block.AddTransaction(doubleSpend)
+	if err = bc.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	b = status(dbtypes.TxBroadcastDoubleSpent)
+	if b.ConflictTx != doubleSpend.TxHash().String() || !b.Final {
+		t.Errorf("unexpected broadcast %+v", b)
+	}
+
+	// A transaction rejected by dcrd is not stored.
+	node.reject = true
+	store.outs[outpoint{chainhash.Hash{5}.String(), 0}] = txOutStatus{value: 1e8}
+	tx = newTx(chainhash.Hash{5}, 9e7)
+	if res, _ = bc.Broadcast(txHex(tx)); res.Status != dbtypes.TxBroadcastRejected {
+		t.Errorf("unexpected status %+v", res)
+	}
+	if _, found := store.broadcasts[tx.TxHash().String()]; found {
+		t.Errorf("rejected transaction was stored")
+	}
+}
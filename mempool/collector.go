@@ -148,6 +148,7 @@ func (t *MempoolDataCollector) mempoolTxns() ([]exptypes.MempoolTx, txhelpers.Me
 			Type:     txhelpers.TxTypeToString(int(txType)),
 			TypeID:   int(txType),
 			VoteInfo: voteInfo,
+			Expiry:   msgTx.Expiry,
 		})
 	}
 
@@ -0,0 +1,317 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v3"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v6/blockdata"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+// DoubleSpendRetention is how long a double spend is kept after it is detected.
+const DoubleSpendRetention = 24 * time.Hour
+
+// doubleSpendStore tracks the mempool transactions spending each previous
+// outpoint, and the double spends detected when another transaction spends
+// the same outpoint. Votes are not tracked since the votes on different blocks
+// spend the same ticket.
+type doubleSpendStore struct {
+	mtx sync.RWMutex
+	// spenders maps the outpoints spent in mempool to the hash of the first
+	// transaction seen spending them.
+	spenders map[outpoint]string
+	// byTx maps the hashes of both transactions of each double spend to it.
+	byTx map[string][]*pstypes.DoubleSpend
+}
+
+func newDoubleSpendStore() *doubleSpendStore {
+	return &doubleSpendStore{
+		spenders: make(map[outpoint]string),
+		byTx:     make(map[string][]*pstypes.DoubleSpend),
+	}
+}
+
+// addressesFunc returns the addresses paid by the outputs of the mempool
+// transaction with the given hash.
+type addressesFunc func(txHash string) []string
+
+// txOutAddresses returns the addresses paid by the outputs of the transaction
+// with the given hash in the TxnsStore.
+func txOutAddresses(txnsStore txhelpers.TxnsStore, txHash string, params *chaincfg.Params) []string {
+	hash, err := chainhash.NewHashFromStr(txHash)
+	if err != nil {
+		return nil
+	}
+	txData := txnsStore[*hash]
+	if txData == nil {
+		return nil
+	}
+	addrs := make([]string, 0, len(txData.Tx.TxOut))
+	seen := make(map[string]struct{}, len(txData.Tx.TxOut))
+	for _, txOut := range txData.Tx.TxOut {
+		_, txOutAddrs, _, err := txscript.ExtractPkScriptAddrs(txOut.Version,
+			txOut.PkScript, params, true)
+		if err != nil {
+			continue
+		}
+		for _, addr := range txOutAddrs {
+			a := addr.Address()
+			if _, found := seen[a]; !found {
+				seen[a] = struct{}{}
+				addrs = append(addrs, a)
+			}
+		}
+	}
+	return addrs
+}
+
+// add records a new double spend, or updates a recorded double spend of the
+// same transactions when the competing spend is mined. A copy of the double
+// spend is returned if it is new or updated, otherwise nil. The caller must
+// hold the lock.
+func (d *doubleSpendStore) add(ds *pstypes.DoubleSpend) *pstypes.DoubleSpend {
+	for _, old := range d.byTx[ds.TxID] {
+		if old.Outpoint != ds.Outpoint || old.ConflictTxID != ds.ConflictTxID {
+			continue
+		}
+		if old.Mined || !ds.Mined {
+			return nil
+		}
+		old.Mined, old.BlockHash, old.BlockHeight = true, ds.BlockHash, ds.BlockHeight
+		old.Time = ds.Time
+		updated := *old
+		return &updated
+	}
+	d.byTx[ds.TxID] = append(d.byTx[ds.TxID], ds)
+	d.byTx[ds.ConflictTxID] = append(d.byTx[ds.ConflictTxID], ds)
+	added := *ds
+	return &added
+}
+
+// spend records the outpoints spent by a new mempool transaction, and returns
+// the new double spends of the outpoints already spent by another mempool
+// transaction.
+func (d *doubleSpendStore) spend(txHash string, inputs []outpoint, addresses addressesFunc) []*pstypes.DoubleSpend {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	var doubleSpends []*pstypes.DoubleSpend
+	now := time.Now().Unix()
+	for _, op := range inputs {
+		spender, found := d.spenders[op]
+		if !found {
+			d.spenders[op] = txHash
+			continue
+		}
+		if spender == txHash {
+			continue
+		}
+		ds := d.add(&pstypes.DoubleSpend{
+			Outpoint:     fmt.Sprintf("%s:%d", op.hash, op.index),
+			TxID:         spender,
+			ConflictTxID: txHash,
+			Addresses:    addresses(spender),
+			Time:         now,
+		})
+		if ds != nil {
+			doubleSpends = append(doubleSpends, ds)
+		}
+	}
+	return doubleSpends
+}
+
+// mined checks the inputs of a transaction mined in a new block against the
+// outpoints spent in mempool, and returns the new double spends of the
+// mempool transactions spending the same outpoints.
+func (d *doubleSpendStore) mined(msgTx *wire.MsgTx, blockHash string, blockHeight int64,
+	addresses addressesFunc) []*pstypes.DoubleSpend {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	var doubleSpends []*pstypes.DoubleSpend
+	txHash := msgTx.TxHash().String()
+	now := time.Now().Unix()
+	for _, op := range txInputs(msgTx) {
+		spender, found := d.spenders[op]
+		if !found || spender == txHash {
+			continue
+		}
+		ds := d.add(&pstypes.DoubleSpend{
+			Outpoint:     fmt.Sprintf("%s:%d", op.hash, op.index),
+			TxID:         spender,
+			ConflictTxID: txHash,
+			Mined:        true,
+			BlockHash:    blockHash,
+			BlockHeight:  blockHeight,
+			Addresses:    addresses(spender),
+			Time:         now,
+		})
+		if ds != nil {
+			doubleSpends = append(doubleSpends, ds)
+		}
+	}
+	return doubleSpends
+}
+
+// refresh replaces the spent outpoints with those of a mempool snapshot, and
+// returns the new double spends of the outpoints spent by a different
+// transaction in the snapshot than before, such as a transaction replacing
+// one evicted from mempool. The double spends detected before
+// DoubleSpendRetention are removed.
+func (d *doubleSpendStore) refresh(txs []exptypes.MempoolTx, addresses addressesFunc) []*pstypes.DoubleSpend {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	var doubleSpends []*pstypes.DoubleSpend
+	now := time.Now().Unix()
+	spenders := make(map[outpoint]string, len(d.spenders))
+	// The snapshot is sorted newest first, and the first spender is kept.
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx := &txs[i]
+		if tx.TypeID == int(stake.TxTypeSSGen) {
+			continue
+		}
+		for _, in := range tx.Vin {
+			op := outpoint{in.TxId, in.Outdex}
+			spender, found := spenders[op]
+			if !found {
+				spenders[op] = tx.Hash
+				spender, found = d.spenders[op]
+			}
+			if !found || spender == tx.Hash {
+				continue
+			}
+			ds := d.add(&pstypes.DoubleSpend{
+				Outpoint:     fmt.Sprintf("%s:%d", op.hash, op.index),
+				TxID:         spender,
+				ConflictTxID: tx.Hash,
+				Addresses:    addresses(spender),
+				Time:         now,
+			})
+			if ds != nil {
+				doubleSpends = append(doubleSpends, ds)
+			}
+		}
+	}
+	d.spenders = spenders
+
+	expired := now - int64(DoubleSpendRetention/time.Second)
+	for txHash, dss := range d.byTx {
+		kept := dss[:0]
+		for _, ds := range dss {
+			if ds.Time > expired {
+				kept = append(kept, ds)
+			}
+		}
+		if len(kept) == 0 {
+			delete(d.byTx, txHash)
+			continue
+		}
+		d.byTx[txHash] = kept
+	}
+	return doubleSpends
+}
+
+// doubleSpends returns copies of the double spends of the transaction.
+func (d *doubleSpendStore) doubleSpends(txHash string) []*pstypes.DoubleSpend {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	dss := d.byTx[txHash]
+	if len(dss) == 0 {
+		return nil
+	}
+	copies := make([]*pstypes.DoubleSpend, 0, len(dss))
+	for _, ds := range dss {
+		c := *ds
+		copies = append(copies, &c)
+	}
+	return copies
+}
+
+// conflicts maps the hashes of the unconfirmed transactions in the TxnsStore
+// with a double spend to the hash of the competing transaction.
+func (d *doubleSpendStore) conflicts(txnsStore txhelpers.TxnsStore) map[chainhash.Hash]chainhash.Hash {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	var conflicts map[chainhash.Hash]chainhash.Hash
+	for hash, txData := range txnsStore {
+		if txData == nil || txData.Confirmed() {
+			continue
+		}
+		dss := d.byTx[hash.String()]
+		if len(dss) == 0 {
+			continue
+		}
+		other := dss[0].ConflictTxID
+		if other == hash.String() {
+			other = dss[0].TxID
+		}
+		otherHash, err := chainhash.NewHashFromStr(other)
+		if err != nil {
+			continue
+		}
+		if conflicts == nil {
+			conflicts = make(map[chainhash.Hash]chainhash.Hash)
+		}
+		conflicts[hash] = *otherHash
+	}
+	return conflicts
+}
+
+// DoubleSpends returns the double spends of the transaction with the given
+// hash detected within the last DoubleSpendRetention, either as the double
+// spent mempool transaction or as the competing spend.
+func (p *MempoolMonitor) DoubleSpends(txHash string) []*pstypes.DoubleSpend {
+	return p.doubleSpends.doubleSpends(txHash)
+}
+
+// signalDoubleSpends logs and signals the double spends to the hub relays.
+func (p *MempoolMonitor) signalDoubleSpends(doubleSpends []*pstypes.DoubleSpend) {
+	for _, ds := range doubleSpends {
+		if ds.Mined {
+			log.Warnf("Mempool transaction %s double spent by %s mined in block %d (%s).",
+				ds.TxID, ds.ConflictTxID, ds.BlockHeight, ds.Outpoint)
+		} else {
+			log.Warnf("Mempool transaction %s double spent by %s (%s).",
+				ds.TxID, ds.ConflictTxID, ds.Outpoint)
+		}
+		p.hubSend(pstypes.SigDoubleSpend, ds, time.Second*10)
+	}
+}
+
+// Store checks the transactions of a new block for double spends of the
+// outpoints spent in mempool, which must be done before BlockHandler refreshes
+// the mempool. This satisfies blockdata.BlockDataSaver.
+func (p *MempoolMonitor) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	blockHash := msgBlock.BlockHash().String()
+	height := int64(msgBlock.Header.Height)
+
+	// The mempool transactions are in the txnsStore, which is modified by
+	// TxHandler with the inventory locked.
+	p.mtx.RLock()
+	p.inventory.Lock()
+	addresses := func(txHash string) []string {
+		return txOutAddresses(p.txnsStore, txHash, p.params)
+	}
+	var doubleSpends []*pstypes.DoubleSpend
+	for _, txns := range [][]*wire.MsgTx{msgBlock.Transactions, msgBlock.STransactions} {
+		for _, msgTx := range txns {
+			doubleSpends = append(doubleSpends,
+				p.doubleSpends.mined(msgTx, blockHash, height, addresses)...)
+		}
+	}
+	p.inventory.Unlock()
+	p.mtx.RUnlock()
+
+	p.signalDoubleSpends(doubleSpends)
+	return nil
+}
@@ -0,0 +1,140 @@
+package mempool
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v3"
+	"github.com/decred/dcrd/wire"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+	"github.com/decred/dcrdata/v6/txhelpers"
+)
+
+func TestDoubleSpends(t *testing.T) {
+	params := chaincfg.SimNetParams()
+	pkScript := append([]byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20},
+		bytes.Repeat([]byte{0x01}, 20)...)
+	pkScript = append(pkScript, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+
+	newTx := func(prevHash chainhash.Hash, out int64) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		prevOut := wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular)
+		tx.AddTxIn(wire.NewTxIn(prevOut, 1e8, []byte{txscript.OP_DATA_1, 1}))
+		tx.AddTxOut(wire.NewTxOut(out, pkScript))
+		return tx
+	}
+
+	hubRelay := make(chan pstypes.HubMessage, 16)
+	p := &MempoolMonitor{
+		inventory:    new(exptypes.MempoolInfo),
+		txnsStore:    make(txhelpers.TxnsStore),
+		doubleSpends: newDoubleSpendStore(),
+		params:       params,
+		signalOuts:   []chan<- pstypes.HubMessage{hubRelay},
+	}
+	addresses := func(txHash string) []string {
+		return txOutAddresses(p.txnsStore, txHash, params)
+	}
+	signaled := func() []*pstypes.DoubleSpend {
+		var dss []*pstypes.DoubleSpend
+		for {
+			select {
+			case msg := <-hubRelay:
+				if msg.Signal != pstypes.SigDoubleSpend || !msg.IsValid() {
+					t.Fatalf("unexpected signal %v", msg.Signal)
+				}
+				dss = append(dss, msg.Msg.(*pstypes.DoubleSpend))
+			default:
+				return dss
+			}
+		}
+	}
+
+	// A second mempool transaction spending the same outpoint.
+	first, second := newTx(chainhash.Hash{1}, 9e7), newTx(chainhash.Hash{1}, 8e7)
+	firstHash, secondHash := first.TxHash(), second.TxHash()
+	p.txnsStore[firstHash] = &txhelpers.TxWithBlockData{Tx: first}
+	p.txnsStore[secondHash] = &txhelpers.TxWithBlockData{Tx: second}
+	if dss := p.doubleSpends.spend(firstHash.String(), txInputs(first), addresses); len(dss) != 0 {
+		t.Fatalf("unexpected double spends %v", dss)
+	}
+	dss := p.doubleSpends.spend(secondHash.String(), txInputs(second), addresses)
+	if len(dss) != 1 {
+		t.Fatalf("%d double spends, expected 1", len(dss))
+	}
+	ds, spentOutpoint := dss[0], chainhash.Hash{1}.String()+":0"
+	if ds.TxID != firstHash.String() || ds.ConflictTxID != secondHash.String() ||
+		ds.Mined || ds.Outpoint != spentOutpoint || len(ds.Addresses) != 1 {
+		t.Errorf("unexpected double spend %+v", ds)
+	}
+	for _, hash := range []string{firstHash.String(), secondHash.String()} {
+		if len(p.DoubleSpends(hash)) != 1 {
+			t.Errorf("double spend of %s not found", hash)
+		}
+	}
+	if dss = p.doubleSpends.spend(secondHash.String(), txInputs(second), addresses); len(dss) != 0 {
+		t.Errorf("double spend detected again")
+	}
+
+	// The competing spend is mined.
+	block := &wire.MsgBlock{Header: wire.BlockHeader{Height: 100}}
+	block.AddTransaction(second)
+	if err := p.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	dss = signaled()
+	if len(dss) != 1 || !dss[0].Mined || dss[0].BlockHeight != 100 ||
+		dss[0].BlockHash != block.BlockHash().String() {
+		t.Fatalf("unexpected mined double spends %v", dss)
+	}
+	if dss = p.DoubleSpends(firstHash.String()); len(dss) != 1 || !dss[0].Mined {
+		t.Errorf("double spend was not updated")
+	}
+
+	// A transaction mined in a block is not a double spend of itself, and a
+	// block spending an outpoint not spent in mempool has no double spends.
+	block = &wire.MsgBlock{Header: wire.BlockHeader{Height: 101}}
+	block.AddTransaction(first)
+	block.AddTransaction(newTx(chainhash.Hash{2}, 9e7))
+	if err := p.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	if dss = signaled(); len(dss) != 0 {
+		t.Errorf("unexpected double spends %v", dss)
+	}
+
+	// A mempool snapshot in which a different transaction spends the outpoint,
+	// with votes spending the same ticket.
+	replacement := newTx(chainhash.Hash{1}, 7e7)
+	vote := exptypes.MempoolTx{Hash: "vote1", TypeID: int(stake.TxTypeSSGen),
+		Vin: []exptypes.MempoolInput{{TxId: chainhash.Hash{3}.String()}}}
+	vote2 := vote
+	vote2.Hash = "vote2"
+	txs := []exptypes.MempoolTx{
+		{Hash: replacement.TxHash().String(), Vin: exptypes.MsgTxMempoolInputs(replacement)},
+		vote, vote2,
+	}
+	dss = p.doubleSpends.refresh(txs, addresses)
+	if len(dss) != 1 || dss[0].TxID != firstHash.String() ||
+		dss[0].ConflictTxID != replacement.TxHash().String() {
+		t.Fatalf("unexpected double spends %v", dss)
+	}
+	if dss = p.doubleSpends.refresh(txs, addresses); len(dss) != 0 {
+		t.Errorf("double spend detected again")
+	}
+
+	// The unconfirmed transactions of an address are flagged.
+	conflicts := p.doubleSpends.conflicts(txhelpers.TxnsStore{
+		firstHash: p.txnsStore[firstHash],
+		chainhash.Hash{2}: {Tx: newTx(chainhash.Hash{4}, 1e8), BlockHeight: 10,
+			BlockHash: chainhash.Hash{5}.String()},
+	})
+	if len(conflicts) != 1 || conflicts[firstHash] != secondHash {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrwallet/wallet/txrules"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/v3"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+const (
+	// DefaultFeeEstimatorBlocks is the default number of recent blocks with
+	// fee rates considered by the FeeEstimator.
+	DefaultFeeEstimatorBlocks = 144
+
+	// feeEstimateProbability is the targeted probability of a transaction
+	// paying the estimated fee rate being mined within the target number of
+	// blocks.
+	feeEstimateProbability = 0.95
+)
+
+// FeeEstimateTargets are the confirmation targets, in blocks, for which fee
+// rates are estimated.
+var FeeEstimateTargets = []int{1, 2, 6}
+
+// BlockFeeRateSource provides the fee rates paid by regular transactions in
+// recent blocks.
+type BlockFeeRateSource interface {
+	// RecentBlockMinFeeRates returns the lowest fee rate, in DCR/kB, paid by a
+	// non-coinbase regular transaction in each of the n most recent main chain
+	// blocks. Blocks without such transactions have a zero fee rate.
+	RecentBlockMinFeeRates(n int64) ([]float64, error)
+}
+
+// FeeEstimator estimates the fee rates required for a regular transaction to
+// be mined within several target numbers of blocks. It combines the fee rate
+// distribution of the regular transactions in mempool with the lowest fee
+// rates accepted in recent blocks. FeeEstimator is a MempoolDataSaver, and the
+// estimates are updated each time the mempool data is stored.
+type FeeEstimator struct {
+	mtx           sync.RWMutex
+	source        BlockFeeRateSource
+	numBlocks     int64
+	blockCapacity int32
+	minRelayRate  float64
+	blockHeight   int64
+	blockRates    []float64 // sorted ascending
+	estimates     *apitypes.FeeEstimates
+	signalOuts    []chan<- pstypes.HubMessage
+}
+
+// NewFeeEstimator creates a new FeeEstimator using the fee rates of the
+// numBlocks most recent blocks from the BlockFeeRateSource. New estimates are
+// sent to the signalOuts with the SigFeeEstimate signal.
+func NewFeeEstimator(params *chaincfg.Params, source BlockFeeRateSource, numBlocks int64,
+	signalOuts []chan<- pstypes.HubMessage) *FeeEstimator {
+	if numBlocks <= 0 {
+		numBlocks = DefaultFeeEstimatorBlocks
+	}
+	// The stake transactions have their own per-block limits and use a small
+	// fraction of the block, so allocate the full block size to regular
+	// transactions.
+	blockCapacity := int32(params.MaximumBlockSizes[len(params.MaximumBlockSizes)-1])
+	return &FeeEstimator{
+		source:        source,
+		numBlocks:     numBlocks,
+		blockCapacity: blockCapacity,
+		minRelayRate:  txrules.DefaultRelayFeePerKb.ToCoin(),
+		blockHeight:   -1,
+		signalOuts:    signalOuts,
+	}
+}
+
+// FeeEstimates returns the most recent fee estimates. The returned value
+// should not be modified.
+func (fe *FeeEstimator) FeeEstimates() *apitypes.FeeEstimates {
+	fe.mtx.RLock()
+	defer fe.mtx.RUnlock()
+	return fe.estimates
+}
+
+// StoreMPData updates the fee estimates with the given mempool transactions,
+// refreshing the recent block fee rates if there is a new best block. This
+// satisfies the MempoolDataSaver interface.
+func (fe *FeeEstimator) StoreMPData(stakeData *StakeData, txs []exptypes.MempoolTx, _ *exptypes.MempoolInfo) {
+	height := stakeData.LatestBlock.Height
+
+	fe.mtx.Lock()
+	if height != fe.blockHeight {
+		blockRates, err := fe.source.RecentBlockMinFeeRates(fe.numBlocks)
+		if err != nil {
+			log.Errorf("Unable to retrieve recent block fee rates: %v", err)
+		} else {
+			sort.Float64s(blockRates)
+			fe.blockRates = blockRates
+			fe.blockHeight = height
+		}
+	}
+
+	estimates := &apitypes.FeeEstimates{
+		Height:    height,
+		Time:      stakeData.Time.Unix(),
+		Estimates: estimateFees(txs, fe.blockRates, fe.blockCapacity, fe.minRelayRate),
+	}
+	fe.estimates = estimates
+	fe.mtx.Unlock()
+
+	log.Debugf("Updated fee estimates at height %d.", height)
+
+	for _, sigout := range fe.signalOuts {
+		select {
+		case sigout <- pstypes.HubMessage{Signal: pstypes.SigFeeEstimate, Msg: estimates}:
+		case <-time.After(10 * time.Second):
+			log.Errorf("send to signalOuts (%v) failed: Timeout waiting for WebsocketHub.",
+				pstypes.SigFeeEstimate)
+		}
+	}
+}
+
+// estimateFees computes the fee estimates for each of the FeeEstimateTargets.
+// For a target of k blocks, the mempool fee rate is the rate of the regular
+// transaction that would no longer fit in the next k blocks if mempool
+// transactions were mined in order of decreasing fee rate. The blocks fee rate
+// is the lowest rate r for which a transaction would have been accepted in at
+// least one of k consecutive blocks with probability feeEstimateProbability,
+// assuming each recent block would have accepted it if r was not below the
+// block's minimum fee rate. blockRates must be sorted in ascending order.
+func estimateFees(txs []exptypes.MempoolTx, blockRates []float64, blockCapacity int32,
+	minRelayRate float64) []apitypes.FeeEstimate {
+	// Only regular transactions compete for the regular block space.
+	regular := make([]*exptypes.MempoolTx, 0, len(txs))
+	for i := range txs {
+		if txs[i].TypeID == int(stake.TxTypeRegular) && !txs[i].Coinbase {
+			regular = append(regular, &txs[i])
+		}
+	}
+	sort.Slice(regular, func(i, j int) bool {
+		return regular[i].FeeRate > regular[j].FeeRate
+	})
+
+	estimates := make([]apitypes.FeeEstimate, 0, len(FeeEstimateTargets))
+	for _, target := range FeeEstimateTargets {
+		// Mempool backlog.
+		var mempoolRate float64
+		var cumSize int64
+		capacity := int64(target) * int64(blockCapacity)
+		for _, tx := range regular {
+			cumSize += int64(tx.Size)
+			if cumSize > capacity {
+				mempoolRate = tx.FeeRate
+				break
+			}
+		}
+
+		// Recent blocks. For a block acceptance fraction F, the probability of
+		// being mined within k blocks is 1-(1-F)^k.
+		var blocksRate float64
+		if n := len(blockRates); n > 0 {
+			f := 1 - math.Pow(1-feeEstimateProbability, 1/float64(target))
+			idx := int(math.Ceil(f*float64(n))) - 1
+			if idx < 0 {
+				idx = 0
+			}
+			blocksRate = blockRates[idx]
+		}
+
+		feeRate := math.Max(minRelayRate, math.Max(mempoolRate, blocksRate))
+
+		// Confidence is estimated from the fraction of recent blocks that
+		// would have accepted the fee rate, and is zero without block data.
+		var confidence float64
+		if n := len(blockRates); n > 0 {
+			accepted := sort.Search(n, func(i int) bool {
+				return blockRates[i] > feeRate
+			})
+			frac := float64(accepted) / float64(n)
+			confidence = 1 - math.Pow(1-frac, float64(target))
+		}
+
+		estimates = append(estimates, apitypes.FeeEstimate{
+			TargetBlocks:   target,
+			FeeRate:        feeRate,
+			Confidence:     confidence,
+			MempoolFeeRate: mempoolRate,
+			BlocksFeeRate:  blocksRate,
+		})
+	}
+
+	return estimates
+}
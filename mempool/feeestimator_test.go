@@ -0,0 +1,69 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+)
+
+func TestEstimateFees(t *testing.T) {
+	const minRelay = 0.0001
+
+	// An uncongested mempool and no block data gives the relay fee.
+	ests := estimateFees(nil, nil, 1000, minRelay)
+	if len(ests) != len(FeeEstimateTargets) {
+		t.Fatalf("expected %d estimates, got %d", len(FeeEstimateTargets), len(ests))
+	}
+	for _, est := range ests {
+		if est.FeeRate != minRelay {
+			t.Errorf("target %d: expected relay fee rate, got %f", est.TargetBlocks, est.FeeRate)
+		}
+		if est.Confidence != 0 {
+			t.Errorf("target %d: expected zero confidence, got %f", est.TargetBlocks, est.Confidence)
+		}
+	}
+
+	// Three blocks' worth of regular transactions at decreasing fee rates,
+	// plus a vote that should be ignored.
+	txs := []exptypes.MempoolTx{
+		{TypeID: int(stake.TxTypeSSGen), FeeRate: 1, Size: 100000},
+		{TypeID: int(stake.TxTypeRegular), FeeRate: 0.0010, Size: 1000},
+		{TypeID: int(stake.TxTypeRegular), FeeRate: 0.0005, Size: 1000},
+		{TypeID: int(stake.TxTypeRegular), FeeRate: 0.0003, Size: 1000},
+	}
+	// Most recent blocks accepted the relay fee, a few required more.
+	blockRates := make([]float64, 0, 100)
+	for i := 0; i < 90; i++ {
+		blockRates = append(blockRates, 0.0001)
+	}
+	for i := 0; i < 10; i++ {
+		blockRates = append(blockRates, 0.0004)
+	}
+
+	ests = estimateFees(txs, blockRates, 1000, minRelay)
+	want := []struct {
+		mempoolRate, blocksRate, feeRate float64
+	}{
+		{0.0005, 0.0004, 0.0005}, // 1 block: second tx does not fit
+		{0.0003, 0.0001, 0.0003}, // 2 blocks: third tx does not fit
+		{0, 0.0001, 0.0001},      // 6 blocks: everything fits
+	}
+	for i, est := range ests {
+		if est.MempoolFeeRate != want[i].mempoolRate {
+			t.Errorf("target %d: expected mempool rate %f, got %f", est.TargetBlocks,
+				want[i].mempoolRate, est.MempoolFeeRate)
+		}
+		if est.BlocksFeeRate != want[i].blocksRate {
+			t.Errorf("target %d: expected blocks rate %f, got %f", est.TargetBlocks,
+				want[i].blocksRate, est.BlocksFeeRate)
+		}
+		if est.FeeRate != want[i].feeRate {
+			t.Errorf("target %d: expected fee rate %f, got %f", est.TargetBlocks,
+				want[i].feeRate, est.FeeRate)
+		}
+		if est.Confidence < 0.95 || est.Confidence > 1 {
+			t.Errorf("target %d: unexpected confidence %f", est.TargetBlocks, est.Confidence)
+		}
+	}
+}
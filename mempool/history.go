@@ -0,0 +1,344 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v6/blockdata"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+const historyRelayBuffer = 1024
+
+// HistoryStore is the persistent storage of the mempool history. It is
+// satisfied by *dcrpg.ChainDB.
+type HistoryStore interface {
+	StoreMempoolHistory(txs []*dbtypes.MempoolHistoryTx) error
+	PendingMempoolHistory() ([]*dbtypes.MempoolHistoryTx, error)
+}
+
+// historyTx is a transaction in the mempool history, with the outpoints spent
+// by the transaction.
+type historyTx struct {
+	*dbtypes.MempoolHistoryTx
+	inputs []outpoint
+}
+
+// newHistoryTx creates the mempool history record of a new mempool
+// transaction. The first seen time is the time the transaction entered the
+// mempool of dcrd, if known.
+func newHistoryTx(tx *exptypes.MempoolTx, now time.Time) *historyTx {
+	firstSeen := now
+	if tx.Time > 0 && tx.Time < now.Unix() {
+		firstSeen = time.Unix(tx.Time, 0)
+	}
+	var fee, feeRate int64
+	if amt, err := dcrutil.NewAmount(tx.Fees); err == nil && amt > 0 {
+		fee = int64(amt)
+		if tx.Size > 0 {
+			feeRate = fee * 1000 / int64(tx.Size)
+		}
+	}
+	inputs := make([]outpoint, 0, len(tx.Vin))
+	for _, in := range tx.Vin {
+		inputs = append(inputs, outpoint{in.TxId, in.Outdex})
+	}
+	return &historyTx{
+		MempoolHistoryTx: &dbtypes.MempoolHistoryTx{
+			TxHash:      tx.Hash,
+			TxType:      tx.TypeID,
+			Size:        tx.Size,
+			Fee:         fee,
+			FeeRate:     feeRate,
+			Expiry:      tx.Expiry,
+			FirstSeen:   dbtypes.NewTimeDef(firstSeen),
+			LastSeen:    dbtypes.NewTimeDef(now),
+			Disposition: dbtypes.MempoolDispositionMempool,
+		},
+		inputs: inputs,
+	}
+}
+
+// HistoryRecorder records every transaction observed in mempool in the
+// HistoryStore, with the time it was first seen, and its final disposition
+// when it leaves mempool: mined, expired, conflicted or evicted. The
+// HistoryRecorder is a MempoolDataSaver, updated with each mempool snapshot,
+// and a blockdata.BlockDataSaver that must be run before the mempool snapshot
+// that follows a new block.
+type HistoryRecorder struct {
+	mtx   sync.Mutex
+	store HistoryStore
+	// pending are the transactions in mempool. departed are the transactions
+	// missing from the last mempool snapshot, which are kept until the next
+	// block in case they were mined in a block not yet stored.
+	pending  map[string]*historyTx
+	departed map[string]*historyTx
+	loaded   bool
+	hubRelay chan pstypes.HubMessage
+}
+
+// NewHistoryRecorder creates a new HistoryRecorder. Run must be called to
+// record the new mempool transactions as they are signaled.
+func NewHistoryRecorder(store HistoryStore) *HistoryRecorder {
+	return &HistoryRecorder{
+		store:    store,
+		pending:  make(map[string]*historyTx),
+		departed: make(map[string]*historyTx),
+		hubRelay: make(chan pstypes.HubMessage, historyRelayBuffer),
+	}
+}
+
+// HubRelay returns the channel on which the mempool monitor signals new
+// mempool transactions (pstypes.SigNewTx) and double spends
+// (pstypes.SigDoubleSpend). Other signals are ignored.
+func (hr *HistoryRecorder) HubRelay() chan<- pstypes.HubMessage {
+	return hr.hubRelay
+}
+
+// storeTxs stores the history records, logging any error.
+func (hr *HistoryRecorder) storeTxs(txs []*dbtypes.MempoolHistoryTx) {
+	if err := hr.store.StoreMempoolHistory(txs); err != nil {
+		log.Errorf("Failed to store the history of %d mempool transactions: %v", len(txs), err)
+	}
+}
+
+// resolve sets the final disposition of a transaction that left mempool.
+func resolve(t *historyTx, disposition string, resolved time.Time) {
+	t.Disposition = disposition
+	t.Resolved = dbtypes.NewTimeDef(resolved)
+}
+
+// Run processes the new mempool transaction and double spend signals until
+// the context is canceled. It should be launched as a goroutine.
+func (hr *HistoryRecorder) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Mempool history recorder stopped.")
+			return
+		case msg := <-hr.hubRelay:
+			switch msg.Signal {
+			case pstypes.SigNewTx:
+				tx, ok := msg.Msg.(*exptypes.MempoolTx)
+				if !ok {
+					log.Errorf("sigNewTx did not store a *MempoolTx in Msg.")
+					continue
+				}
+				hr.newMempoolTx(tx)
+			case pstypes.SigDoubleSpend:
+				ds, ok := msg.Msg.(*pstypes.DoubleSpend)
+				if !ok {
+					log.Errorf("sigDoubleSpend did not store a *DoubleSpend in Msg.")
+					continue
+				}
+				hr.doubleSpend(ds)
+			}
+		}
+	}
+}
+
+// newMempoolTx records a new mempool transaction.
+func (hr *HistoryRecorder) newMempoolTx(tx *exptypes.MempoolTx) {
+	hr.mtx.Lock()
+	if _, found := hr.pending[tx.Hash]; found {
+		hr.mtx.Unlock()
+		return
+	}
+	// A departed transaction may return to mempool.
+	delete(hr.departed, tx.Hash)
+	t := newHistoryTx(tx, time.Now())
+	hr.pending[tx.Hash] = t
+	rec := *t.MempoolHistoryTx
+	hr.mtx.Unlock()
+
+	hr.storeTxs([]*dbtypes.MempoolHistoryTx{&rec})
+}
+
+// doubleSpend records the competing spend of a double spent transaction. The
+// transaction is conflicted if the competing spend is mined, or if it already
+// left mempool.
+func (hr *HistoryRecorder) doubleSpend(ds *pstypes.DoubleSpend) {
+	hr.mtx.Lock()
+	var rec dbtypes.MempoolHistoryTx
+	if t := hr.pending[ds.TxID]; t != nil {
+		t.ConflictTx = ds.ConflictTxID
+		if !ds.Mined {
+			hr.mtx.Unlock()
+			return
+		}
+		resolve(t, dbtypes.MempoolDispositionConflicted, time.Now())
+		delete(hr.pending, ds.TxID)
+		rec = *t.MempoolHistoryTx
+	} else if t := hr.departed[ds.TxID]; t != nil {
+		t.ConflictTx = ds.ConflictTxID
+		if t.Disposition == dbtypes.MempoolDispositionConflicted {
+			hr.mtx.Unlock()
+			return
+		}
+		t.Disposition = dbtypes.MempoolDispositionConflicted
+		rec = *t.MempoolHistoryTx
+	} else {
+		hr.mtx.Unlock()
+		return
+	}
+	hr.mtx.Unlock()
+
+	hr.storeTxs([]*dbtypes.MempoolHistoryTx{&rec})
+}
+
+// StoreMPData records the transactions in the mempool snapshot that were not
+// yet recorded, and resolves the transactions seen before the snapshot that
+// are no longer in mempool. A transaction with an input spent by another
+// transaction in the snapshot, or with a recorded double spend, is
+// conflicted. A transaction at its expiry height is expired, and any other is
+// evicted. This satisfies the MempoolDataSaver interface.
+func (hr *HistoryRecorder) StoreMPData(stakeData *StakeData, txs []exptypes.MempoolTx, _ *exptypes.MempoolInfo) {
+	now := time.Now()
+	inMempool := make(map[string]struct{}, len(txs))
+	spenders := make(map[outpoint]string)
+	for i := range txs {
+		tx := &txs[i]
+		inMempool[tx.Hash] = struct{}{}
+		for _, in := range tx.Vin {
+			spenders[outpoint{in.TxId, in.Outdex}] = tx.Hash
+		}
+	}
+	nextHeight := stakeData.LatestBlock.Height + 1
+
+	hr.mtx.Lock()
+	var recs []*dbtypes.MempoolHistoryTx
+	for i := range txs {
+		tx := &txs[i]
+		if t := hr.pending[tx.Hash]; t != nil {
+			t.LastSeen = dbtypes.NewTimeDef(now)
+			continue
+		}
+		delete(hr.departed, tx.Hash)
+		t := newHistoryTx(tx, now)
+		hr.pending[tx.Hash] = t
+		rec := *t.MempoolHistoryTx
+		recs = append(recs, &rec)
+	}
+
+	for hash, t := range hr.pending {
+		if _, found := inMempool[hash]; found || !t.FirstSeen.T.Before(stakeData.Time) {
+			continue
+		}
+		for _, op := range t.inputs {
+			if spender, found := spenders[op]; found && spender != hash {
+				t.ConflictTx = spender
+				break
+			}
+		}
+		switch {
+		case t.ConflictTx != "":
+			resolve(t, dbtypes.MempoolDispositionConflicted, now)
+		case t.Expiry != 0 && nextHeight >= int64(t.Expiry):
+			resolve(t, dbtypes.MempoolDispositionExpired, now)
+		default:
+			resolve(t, dbtypes.MempoolDispositionEvicted, now)
+		}
+		delete(hr.pending, hash)
+		hr.departed[hash] = t
+		rec := *t.MempoolHistoryTx
+		recs = append(recs, &rec)
+	}
+	hr.mtx.Unlock()
+
+	if len(recs) > 0 {
+		hr.storeTxs(recs)
+	}
+}
+
+// loadPending adds the transactions that were in mempool when last recorded,
+// such as before a restart, to the pending transactions. The caller must hold
+// hr.mtx.
+func (hr *HistoryRecorder) loadPending() {
+	hr.loaded = true
+	txs, err := hr.store.PendingMempoolHistory()
+	if err != nil {
+		log.Errorf("Unable to load the pending mempool history: %v", err)
+		return
+	}
+	for _, tx := range txs {
+		if _, found := hr.pending[tx.TxHash]; !found {
+			hr.pending[tx.TxHash] = &historyTx{MempoolHistoryTx: tx}
+		}
+	}
+	log.Debugf("Loaded %d pending mempool history transactions.", len(txs))
+}
+
+// Store resolves the pending and departed transactions mined in a new main
+// chain block, or with an input spent by a transaction in the block. The
+// pending transactions stored before startup are loaded with the first block,
+// which is stored after the initial sync. This satisfies
+// blockdata.BlockDataSaver.
+func (hr *HistoryRecorder) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	height := int64(msgBlock.Header.Height)
+	blockHash := msgBlock.BlockHash().String()
+	blockTime := msgBlock.Header.Timestamp
+
+	mined := make(map[string]struct{})
+	spenders := make(map[outpoint]string)
+	for _, txs := range [][]*wire.MsgTx{msgBlock.Transactions, msgBlock.STransactions} {
+		for _, tx := range txs {
+			hash := tx.TxHash().String()
+			mined[hash] = struct{}{}
+			for _, op := range txInputs(tx) {
+				spenders[op] = hash
+			}
+		}
+	}
+
+	hr.mtx.Lock()
+	if !hr.loaded {
+		hr.loadPending()
+	}
+	var recs []*dbtypes.MempoolHistoryTx
+	for _, txns := range []map[string]*historyTx{hr.pending, hr.departed} {
+		for hash, t := range txns {
+			if _, found := mined[hash]; found {
+				resolve(t, dbtypes.MempoolDispositionMined, blockTime)
+				t.BlockHash, t.BlockHeight, t.ConflictTx = blockHash, height, ""
+			} else if conflict := t.conflict(spenders); conflict != "" &&
+				t.Disposition != dbtypes.MempoolDispositionConflicted {
+				resolve(t, dbtypes.MempoolDispositionConflicted, blockTime)
+				t.ConflictTx = conflict
+			} else {
+				continue
+			}
+			delete(hr.pending, hash)
+			rec := *t.MempoolHistoryTx
+			recs = append(recs, &rec)
+		}
+	}
+	// The departed transactions not mined in the next block stay resolved.
+	hr.departed = make(map[string]*historyTx)
+	hr.mtx.Unlock()
+
+	if len(recs) > 0 {
+		hr.storeTxs(recs)
+	}
+	return nil
+}
+
+// conflict returns the transaction other than the history transaction that
+// spends one of its inputs according to the spenders map, or an empty string.
+func (t *historyTx) conflict(spenders map[outpoint]string) string {
+	for _, op := range t.inputs {
+		if spender, found := spenders[op]; found && spender != t.TxHash {
+			return spender
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+type testHistoryStore struct {
+	stored  map[string]dbtypes.MempoolHistoryTx
+	pending []*dbtypes.MempoolHistoryTx
+}
+
+func (s *testHistoryStore) StoreMempoolHistory(txs []*dbtypes.MempoolHistoryTx) error {
+	for _, tx := range txs {
+		s.stored[tx.TxHash] = *tx
+	}
+	return nil
+}
+
+func (s *testHistoryStore) PendingMempoolHistory() ([]*dbtypes.MempoolHistoryTx, error) {
+	return s.pending, nil
+}
+
+func TestHistoryRecorder(t *testing.T) {
+	store := &testHistoryStore{
+		stored: make(map[string]dbtypes.MempoolHistoryTx),
+		pending: []*dbtypes.MempoolHistoryTx{{
+			TxHash:      "restarted",
+			Disposition: dbtypes.MempoolDispositionMempool,
+		}},
+	}
+	hr := NewHistoryRecorder(store)
+
+	newTx := func(prevHash chainhash.Hash) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0, wire.TxTreeRegular), 1e8, nil))
+		tx.AddTxOut(wire.NewTxOut(9e7, nil))
+		return tx
+	}
+	mempoolTx := func(msgTx *wire.MsgTx, seen time.Time) exptypes.MempoolTx {
+		return exptypes.MempoolTx{
+			Hash:   msgTx.TxHash().String(),
+			Fees:   0.0001,
+			Size:   250,
+			Time:   seen.Unix(),
+			Vin:    exptypes.MsgTxMempoolInputs(msgTx),
+			Expiry: msgTx.Expiry,
+		}
+	}
+	disposition := func(hash string) string {
+		t.Helper()
+		rec, found := store.stored[hash]
+		if !found {
+			t.Fatalf("transaction %s not stored", hash)
+		}
+		return rec.Disposition
+	}
+
+	seen := time.Now().Add(-time.Hour)
+	mined, evicted, expiring := newTx(chainhash.Hash{1}), newTx(chainhash.Hash{2}), newTx(chainhash.Hash{3})
+	expiring.Expiry = 101
+	conflicted, replacement := newTx(chainhash.Hash{4}), newTx(chainhash.Hash{4})
+	replacement.AddTxOut(wire.NewTxOut(1, nil))
+	doubleSpent := newTx(chainhash.Hash{5})
+
+	// New transactions and a snapshot.
+	minedTx := mempoolTx(mined, seen)
+	hr.newMempoolTx(&minedTx)
+	rec := store.stored[mined.TxHash().String()]
+	if rec.Disposition != dbtypes.MempoolDispositionMempool || rec.FeeRate != 4e4 ||
+		rec.Fee != 1e4 || rec.FirstSeen.UNIX() != seen.Unix() {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+	stakeData := &StakeData{LatestBlock: BlockID{Height: 99}, Time: time.Now()}
+	hr.StoreMPData(stakeData, []exptypes.MempoolTx{
+		mempoolTx(mined, seen), mempoolTx(evicted, seen), mempoolTx(expiring, seen),
+		mempoolTx(conflicted, seen), mempoolTx(doubleSpent, seen),
+	}, nil)
+	if len(store.stored) != 5 {
+		t.Fatalf("%d transactions stored, expected 5", len(store.stored))
+	}
+
+	// A block mines a transaction. The stored pending transactions are loaded.
+	block := &wire.MsgBlock{Header: wire.BlockHeader{Height: 100, Timestamp: time.Unix(1e9, 0)}}
+	block.AddTransaction(mined)
+	if err := hr.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	rec = store.stored[mined.TxHash().String()]
+	if rec.Disposition != dbtypes.MempoolDispositionMined || rec.BlockHeight != 100 ||
+		rec.BlockHash != block.BlockHash().String() || rec.Resolved.UNIX() != 1e9 {
+		t.Fatalf("unexpected mined record %+v", rec)
+	}
+	if _, found := hr.pending["restarted"]; !found {
+		t.Fatalf("pending transaction not loaded")
+	}
+
+	// A double spend in mempool, and the next snapshot without the evicted,
+	// expired and conflicted transactions.
+	hr.doubleSpend(&pstypes.DoubleSpend{TxID: doubleSpent.TxHash().String(), ConflictTxID: "other"})
+	stakeData = &StakeData{LatestBlock: BlockID{Height: 100}, Time: time.Now()}
+	hr.StoreMPData(stakeData, []exptypes.MempoolTx{mempoolTx(replacement, time.Now())}, nil)
+	for hash, want := range map[string]string{
+		evicted.TxHash().String():     dbtypes.MempoolDispositionEvicted,
+		expiring.TxHash().String():    dbtypes.MempoolDispositionExpired,
+		conflicted.TxHash().String():  dbtypes.MempoolDispositionConflicted,
+		doubleSpent.TxHash().String(): dbtypes.MempoolDispositionConflicted,
+		"restarted":                   dbtypes.MempoolDispositionEvicted,
+		replacement.TxHash().String(): dbtypes.MempoolDispositionMempool,
+	} {
+		if got := disposition(hash); got != want {
+			t.Errorf("transaction %s disposition %s, expected %s", hash, got, want)
+		}
+	}
+	if rec = store.stored[conflicted.TxHash().String()]; rec.ConflictTx != replacement.TxHash().String() {
+		t.Errorf("conflict %s, expected %s", rec.ConflictTx, replacement.TxHash())
+	}
+
+	// An evicted transaction mined in the next block.
+	block = &wire.MsgBlock{Header: wire.BlockHeader{Height: 101}}
+	block.AddTransaction(evicted)
+	if err := hr.Store(nil, block); err != nil {
+		t.Fatal(err)
+	}
+	if got := disposition(evicted.TxHash().String()); got != dbtypes.MempoolDispositionMined {
+		t.Errorf("evicted transaction disposition %s, expected mined", got)
+	}
+	if len(hr.departed) != 0 || len(hr.pending) != 1 {
+		t.Errorf("%d departed and %d pending transactions, expected 0 and 1",
+			len(hr.departed), len(hr.pending))
+	}
+}
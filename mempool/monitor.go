@@ -41,19 +41,24 @@ type MempoolAddressStore struct {
 // perform the collection and parsing, and an optional []MempoolDataSaver is
 // used to to forward the data to arbitrary destinations. The last block's
 // height, hash, and time are kept in memory in order to properly process votes
-// in mempool.
+// in mempool. The outpoints spent in mempool are tracked to detect double
+// spends by other mempool transactions and by new blocks, for which the
+// MempoolMonitor must also be a blockdata.BlockDataSaver. The time each vote on
+// a recent block was first seen is kept to attribute missed votes.
 type MempoolMonitor struct {
-	mtx        sync.RWMutex
-	ctx        context.Context
-	mpoolInfo  MempoolInfo
-	inventory  *exptypes.MempoolInfo
-	addrMap    MempoolAddressStore
-	txnsStore  txhelpers.TxnsStore
-	lastBlock  BlockID
-	params     *chaincfg.Params
-	collector  *MempoolDataCollector
-	dataSavers []MempoolDataSaver
-	client     txhelpers.VerboseTransactionGetter
+	mtx           sync.RWMutex
+	ctx           context.Context
+	mpoolInfo     MempoolInfo
+	inventory     *exptypes.MempoolInfo
+	addrMap       MempoolAddressStore
+	txnsStore     txhelpers.TxnsStore
+	doubleSpends  *doubleSpendStore
+	voteSightings *voteSightings
+	lastBlock     BlockID
+	params        *chaincfg.Params
+	collector     *MempoolDataCollector
+	dataSavers    []MempoolDataSaver
+	client        txhelpers.VerboseTransactionGetter
 
 	// Outgoing message
 	signalOuts []chan<- pstypes.HubMessage
@@ -70,12 +75,14 @@ func NewMempoolMonitor(ctx context.Context, collector *MempoolDataCollector,
 
 	// Make the skeleton MempoolMonitor.
 	p := &MempoolMonitor{
-		ctx:        ctx,
-		params:     params,
-		collector:  collector,
-		dataSavers: savers,
-		client:     client,
-		signalOuts: signalOuts,
+		ctx:           ctx,
+		doubleSpends:  newDoubleSpendStore(),
+		voteSightings: newVoteSightings(time.Now()),
+		params:        params,
+		collector:     collector,
+		dataSavers:    savers,
+		client:        client,
+		signalOuts:    signalOuts,
 	}
 
 	if initialStore {
@@ -207,6 +214,15 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 		MemPoolTime: rawTx.Time,
 	}
 
+	// Check for double spends of the outpoints spent by other mempool
+	// transactions. Votes on different blocks spend the same ticket.
+	var doubleSpends []*pstypes.DoubleSpend
+	if txType != stake.TxTypeSSGen {
+		doubleSpends = p.doubleSpends.spend(hash, txInputs(msgTx), func(txHash string) []string {
+			return txOutAddresses(p.txnsStore, txHash, p.params)
+		})
+	}
+
 	log.Tracef("New transaction (%s: %s) added %d new and %d previous outpoints, "+
 		"%d out addrs (%d new), %d prev out addrs (%d new).",
 		txTypeStr, hash, newOuts, newPrevOuts,
@@ -235,6 +251,7 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 				TSpends:     exptypes.ConvertTSpendVotes(tspendVotes),
 			}
 			voteInfo.ForLastBlock = voteInfo.VotesOnBlock(p.lastBlock.Hash.String())
+			p.voteSightings.see(voteInfo, rawTx.Time, time.Now())
 		}
 	}
 
@@ -260,6 +277,7 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 		Type:     txTypeStr,
 		TypeID:   int(txType),
 		VoteInfo: voteInfo,
+		Expiry:   msgTx.Expiry,
 	}
 
 	// Maintain a separate total that excludes votes for sidechain
@@ -362,6 +380,7 @@ func (p *MempoolMonitor) TxHandler(rawTx *chainjson.TxRawResult) error {
 	// Broadcast the new transaction.
 	log.Tracef("Signaling new tx to hub relays...")
 	p.hubSend(pstypes.SigNewTx, &tx, time.Second*10)
+	p.signalDoubleSpends(doubleSpends)
 	return nil
 }
 
@@ -404,6 +423,23 @@ func (p *MempoolMonitor) Refresh() (*StakeData, []exptypes.MempoolTx, *exptypes.
 	p.mpoolInfo.LastCollectTime = stakeData.Time
 	p.mpoolInfo.NumTicketPurchasesInMempool = stakeData.Ticketfees.FeeInfoMempool.Number
 
+	// Check for outpoints spent by a different transaction than before, such
+	// as a transaction replacing one evicted from mempool.
+	doubleSpends := p.doubleSpends.refresh(txs, func(txHash string) []string {
+		if addrs := txOutAddresses(txnsStore, txHash, p.params); addrs != nil {
+			return addrs
+		}
+		return txOutAddresses(p.txnsStore, txHash, p.params)
+	})
+
+	// Record the sightings of the votes, including those that arrived while
+	// the mempool was not being watched by TxHandler.
+	now := time.Now()
+	for i := range txs {
+		p.voteSightings.see(txs[i].VoteInfo, txs[i].Time, now)
+	}
+	p.voteSightings.prune(stakeData.LatestBlock.Height)
+
 	// Store the current best block info.
 	p.lastBlock = stakeData.LatestBlock
 	if p.inventory != nil {
@@ -413,6 +449,8 @@ func (p *MempoolMonitor) Refresh() (*StakeData, []exptypes.MempoolTx, *exptypes.
 	p.txnsStore = txnsStore
 	p.mtx.Unlock()
 
+	p.signalDoubleSpends(doubleSpends)
+
 	p.addrMap.mtx.Lock()
 	p.addrMap.store = addrOuts
 	p.addrMap.mtx.Unlock()
@@ -451,8 +489,10 @@ func (p *MempoolMonitor) CollectAndStore() error {
 // UnconfirmedTxnsForAddress indexes (1) outpoints in mempool that pay to the
 // given address, (2) previous outpoint being consumed that paid to the address,
 // and (3) all relevant transactions. See txhelpers.AddressOutpoints for more
-// information. The number of unconfirmed transactions is also returned. This
-// satisfies the rpcutils.MempoolAddressChecker interface for MempoolMonitor.
+// information. The unconfirmed transactions with a detected double spend are
+// flagged in the DoubleSpends map. The number of unconfirmed transactions is
+// also returned. This satisfies the rpcutils.MempoolAddressChecker interface
+// for MempoolMonitor.
 func (p *MempoolMonitor) UnconfirmedTxnsForAddress(address string) (*txhelpers.AddressOutpoints, int64, error) {
 	p.addrMap.mtx.Lock()
 	defer p.addrMap.mtx.Unlock()
@@ -522,5 +562,8 @@ func (p *MempoolMonitor) UnconfirmedTxnsForAddress(address string) (*txhelpers.A
 		outs.TxnsStore[hash] = txData
 	}
 
+	// Flag the transactions with a double spend.
+	outs.DoubleSpends = p.doubleSpends.conflicts(outs.TxnsStore)
+
 	return outs, int64(len(outs.TxnsStore)), nil
 }
@@ -0,0 +1,340 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/blockchain/standalone/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/blockdata"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+// maxLoadedTSpends is the maximum number of mined tspends loaded from the
+// TSpendSource when the TSpendTracker starts.
+const maxLoadedTSpends = 100
+
+// TSpendSource provides the vote tallies of treasury spends, and the treasury
+// spends mined in the main chain. It is satisfied by *dcrpg.ChainDB.
+type TSpendSource interface {
+	TSpendVotes(tspendID *chainhash.Hash) (*dbtypes.TreasurySpendVotes, error)
+	TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error)
+}
+
+// tallyTSpend updates the status, quorum, approval and projected outcome of the
+// tspend from its votes and voting window as of the best block at the height.
+// The quorum and the required approval are those checked by consensus when
+// the tspend is mined.
+func tallyTSpend(ts *apitypes.TSpendProgress, height int64, params *chaincfg.Params) {
+	windowBlocks := int64(params.TreasuryVoteInterval * params.TreasuryVoteIntervalMultiplier)
+	ts.MaxVotes = int64(params.TicketsPerBlock) * windowBlocks
+	ts.Quorum = ts.MaxVotes * int64(params.TreasuryVoteQuorumMultiplier) /
+		int64(params.TreasuryVoteQuorumDivisor)
+	ts.RequiredApproval = float64(params.TreasuryVoteRequiredMultiplier) /
+		float64(params.TreasuryVoteRequiredDivisor)
+
+	cast := ts.YesVotes + ts.NoVotes
+	ts.Approval = 0
+	if cast > 0 {
+		ts.Approval = float64(ts.YesVotes) / float64(cast)
+	}
+	ts.Approved = cast >= ts.Quorum && ts.YesVotes >= cast*
+		int64(params.TreasuryVoteRequiredMultiplier)/int64(params.TreasuryVoteRequiredDivisor)
+
+	switch {
+	case ts.BlockHash != "":
+		ts.Status, ts.Projected = apitypes.TSpendMined, apitypes.TSpendPass
+		return
+	case height >= ts.VoteEnd:
+		// The tspend may be mined in the block at the end of the window at
+		// the latest.
+		ts.Status, ts.Projected = apitypes.TSpendExpired, apitypes.TSpendFail
+		return
+	case height < ts.VoteStart:
+		ts.Status = apitypes.TSpendMempool
+	default:
+		ts.Status = apitypes.TSpendVoting
+	}
+
+	elapsed := height - ts.VoteStart
+	switch {
+	case ts.Approved:
+		ts.Projected = apitypes.TSpendPass
+	case cast == 0 || elapsed <= 0:
+		ts.Projected = ""
+	default:
+		// The votes of the rest of the window are cast at the same rate and
+		// with the same approval.
+		projectedCast := float64(cast) * float64(ts.VoteEnd-ts.VoteStart) / float64(elapsed)
+		if projectedCast >= float64(ts.Quorum) && ts.Approval >= ts.RequiredApproval {
+			ts.Projected = apitypes.TSpendPass
+		} else {
+			ts.Projected = apitypes.TSpendFail
+		}
+	}
+}
+
+// setTSpendWindow sets the voting window of the tspend from its expiry, for
+// the tspends whose votes are not available.
+func setTSpendWindow(ts *apitypes.TSpendProgress, params *chaincfg.Params) {
+	start, end, err := standalone.CalcTSpendWindow(uint32(ts.Expiry),
+		params.TreasuryVoteInterval, params.TreasuryVoteIntervalMultiplier)
+	if err != nil {
+		log.Warnf("Invalid expiry of tspend %s: %v", ts.TxID, err)
+		return
+	}
+	ts.VoteStart, ts.VoteEnd = int64(start), int64(end)
+}
+
+// TSpendTracker follows each treasury spend (tspend) from mempool through its
+// voting window to the block that mines it, or its expiry at the end of the
+// window. The votes are tallied with each new block, and the changes in the
+// progress of each tspend are signaled with the SigTSpend signal. The mined
+// and expired tspends are tracked for one voting window after their end. The
+// TSpendTracker is a MempoolDataSaver, updated with each mempool snapshot, and
+// a blockdata.BlockDataSaver.
+type TSpendTracker struct {
+	mtx        sync.RWMutex
+	source     TSpendSource
+	params     *chaincfg.Params
+	height     int64
+	tallied    int64
+	loaded     bool
+	tspends    map[string]*apitypes.TSpendProgress
+	signalOuts []chan<- pstypes.HubMessage
+}
+
+// NewTSpendTracker creates a new TSpendTracker using the TSpendSource for the
+// vote tallies. The changes in the progress of the tspends are sent to the
+// signalOuts.
+func NewTSpendTracker(source TSpendSource, params *chaincfg.Params, signalOuts []chan<- pstypes.HubMessage) *TSpendTracker {
+	return &TSpendTracker{
+		source:     source,
+		params:     params,
+		tspends:    make(map[string]*apitypes.TSpendProgress),
+		signalOuts: signalOuts,
+	}
+}
+
+// windowBlocks is the number of blocks of a voting window.
+func (t *TSpendTracker) windowBlocks() int64 {
+	return int64(t.params.TreasuryVoteInterval * t.params.TreasuryVoteIntervalMultiplier)
+}
+
+// loadMined adds the tspends mined within the last voting window, such as
+// before a restart, and returns their hashes. The caller must hold t.mtx.
+func (t *TSpendTracker) loadMined(height int64) []string {
+	t.loaded = true
+	txns, err := t.source.TreasuryTxns(maxLoadedTSpends, 0, stake.TxTypeTSpend)
+	if err != nil {
+		log.Errorf("Unable to load the mined tspends: %v", err)
+		return nil
+	}
+	var hashes []string
+	for _, tx := range txns {
+		if height-tx.BlockHeight > t.windowBlocks() {
+			break
+		}
+		if _, found := t.tspends[tx.TxID]; found {
+			continue
+		}
+		t.tspends[tx.TxID] = &apitypes.TSpendProgress{
+			TxID:        tx.TxID,
+			Amount:      dcrutil.Amount(-tx.Amount).ToCoin(),
+			BlockHash:   tx.BlockHash,
+			BlockHeight: tx.BlockHeight,
+		}
+		hashes = append(hashes, tx.TxID)
+	}
+	log.Debugf("Loaded %d mined tspends.", len(hashes))
+	return hashes
+}
+
+// tally retrieves the votes of the tspends from the TSpendSource, updates their
+// progress as of the height, and returns copies of the tspends whose progress
+// changed.
+func (t *TSpendTracker) tally(height int64, hashes []string) []*apitypes.TSpendProgress {
+	votes := make(map[string]*dbtypes.TreasurySpendVotes, len(hashes))
+	for _, txid := range hashes {
+		hash, err := chainhash.NewHashFromStr(txid)
+		if err != nil {
+			continue
+		}
+		tsv, err := t.source.TSpendVotes(hash)
+		if err != nil {
+			log.Warnf("Unable to retrieve the votes of tspend %s: %v", txid, err)
+			continue
+		}
+		votes[txid] = tsv
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	var changed []*apitypes.TSpendProgress
+	for _, txid := range hashes {
+		ts := t.tspends[txid]
+		if ts == nil {
+			continue
+		}
+		old := *ts
+		if tsv := votes[txid]; tsv != nil {
+			ts.Expiry, ts.VoteStart, ts.VoteEnd = tsv.Expiry, tsv.VoteStart, tsv.VoteEnd
+			ts.YesVotes, ts.NoVotes = tsv.YesVotes, tsv.NoVotes
+		} else if ts.VoteEnd == 0 && ts.Expiry != 0 {
+			setTSpendWindow(ts, t.params)
+		}
+		tallyTSpend(ts, height, t.params)
+		if *ts != old {
+			updated := *ts
+			changed = append(changed, &updated)
+		}
+	}
+	return changed
+}
+
+// signal sends the changes in the progress of the tspends to the signalOuts.
+func (t *TSpendTracker) signal(changed []*apitypes.TSpendProgress) {
+	for _, ts := range changed {
+		log.Debugf("Tspend %s is %s with %d yes and %d no votes.", ts.TxID,
+			ts.Status, ts.YesVotes, ts.NoVotes)
+		for _, sigout := range t.signalOuts {
+			select {
+			case sigout <- pstypes.HubMessage{Signal: pstypes.SigTSpend, Msg: ts}:
+			case <-time.After(10 * time.Second):
+				log.Errorf("send to signalOuts (%v) failed: Timeout waiting for WebsocketHub.",
+					pstypes.SigTSpend)
+			}
+		}
+	}
+}
+
+// StoreMPData tracks the new tspends in the mempool snapshot, and tallies the
+// votes of the tspends that are not mined or expired when the snapshot follows
+// a new block. A tracked tspend that is mined, and is in mempool again after
+// its block was disconnected, is tracked as unmined. The mined and expired
+// tspends are dropped one voting window after their end. This satisfies the
+// MempoolDataSaver interface.
+func (t *TSpendTracker) StoreMPData(stakeData *StakeData, txs []exptypes.MempoolTx, _ *exptypes.MempoolInfo) {
+	height := stakeData.LatestBlock.Height
+
+	t.mtx.Lock()
+	var hashes []string
+	for i := range txs {
+		tx := &txs[i]
+		if tx.TypeID != int(stake.TxTypeTSpend) {
+			continue
+		}
+		ts := t.tspends[tx.Hash]
+		switch {
+		case ts == nil:
+			t.tspends[tx.Hash] = &apitypes.TSpendProgress{
+				TxID:      tx.Hash,
+				Amount:    tx.TotalOut,
+				FirstSeen: tx.Time,
+				Expiry:    int64(tx.Expiry),
+			}
+			hashes = append(hashes, tx.Hash)
+		case ts.BlockHash != "":
+			ts.BlockHash, ts.BlockHeight = "", 0
+			hashes = append(hashes, tx.Hash)
+		}
+	}
+	if height != t.tallied {
+		t.tallied = height
+		for txid, ts := range t.tspends {
+			switch ts.Status {
+			case apitypes.TSpendMempool, apitypes.TSpendVoting:
+				hashes = append(hashes, txid)
+			case apitypes.TSpendMined, apitypes.TSpendExpired:
+				end := ts.VoteEnd
+				if ts.BlockHeight > 0 {
+					end = ts.BlockHeight
+				}
+				if height-end > t.windowBlocks() {
+					delete(t.tspends, txid)
+				}
+			}
+		}
+	}
+	t.height = height
+	t.mtx.Unlock()
+
+	t.signal(t.tally(height, hashes))
+}
+
+// Store records the tspends mined in a new main chain block, including those
+// never seen in mempool. The tspends mined before startup are loaded with the
+// first block, which is stored after the initial sync. This satisfies
+// blockdata.BlockDataSaver.
+func (t *TSpendTracker) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	height := int64(msgBlock.Header.Height)
+	blockHash := msgBlock.BlockHash().String()
+
+	t.mtx.Lock()
+	var loaded []string
+	if !t.loaded {
+		loaded = t.loadMined(height)
+	}
+	var hashes []string
+	for _, tx := range msgBlock.STransactions {
+		if stake.DetermineTxType(tx, true) != stake.TxTypeTSpend {
+			continue
+		}
+		txid := tx.TxHash().String()
+		ts := t.tspends[txid]
+		if ts == nil {
+			var amount int64
+			for _, txOut := range tx.TxOut {
+				amount += txOut.Value
+			}
+			ts = &apitypes.TSpendProgress{
+				TxID:   txid,
+				Amount: dcrutil.Amount(amount).ToCoin(),
+				Expiry: int64(tx.Expiry),
+			}
+			t.tspends[txid] = ts
+		}
+		ts.BlockHash, ts.BlockHeight = blockHash, height
+		hashes = append(hashes, txid)
+	}
+	t.height = height
+	t.mtx.Unlock()
+
+	t.tally(height, loaded)
+	t.signal(t.tally(height, hashes))
+	return nil
+}
+
+// TSpends returns the progress of the tracked tspends as of the best block,
+// sorted by the end of their voting window, latest first.
+func (t *TSpendTracker) TSpends() *apitypes.TSpends {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	tspends := make([]*apitypes.TSpendProgress, 0, len(t.tspends))
+	for _, ts := range t.tspends {
+		c := *ts
+		tspends = append(tspends, &c)
+	}
+	sort.Slice(tspends, func(i, j int) bool {
+		if tspends[i].VoteEnd != tspends[j].VoteEnd {
+			return tspends[i].VoteEnd > tspends[j].VoteEnd
+		}
+		return tspends[i].TxID < tspends[j].TxID
+	})
+	return &apitypes.TSpends{
+		Height:  t.height,
+		TSpends: tspends,
+	}
+}
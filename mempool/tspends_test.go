@@ -0,0 +1,129 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+type testTSpendSource struct {
+	votes map[string]*dbtypes.TreasurySpendVotes
+	mined []*dbtypes.TreasuryTx
+}
+
+func (s *testTSpendSource) TSpendVotes(tspendID *chainhash.Hash) (*dbtypes.TreasurySpendVotes, error) {
+	tsv := *s.votes[tspendID.String()]
+	return &tsv, nil
+}
+
+func (s *testTSpendSource) TreasuryTxns(n, offset int64, txType stake.TxType) ([]*dbtypes.TreasuryTx, error) {
+	return s.mined, nil
+}
+
+func TestTallyTSpend(t *testing.T) {
+	p := chaincfg.MainNetParams()
+	// A window of 3456 blocks with 5 votes per block.
+	const start, end = 10368, 13824
+	tests := []struct {
+		name      string
+		height    int64
+		yes, no   int64
+		mined     bool
+		status    string
+		approved  bool
+		projected string
+	}{
+		{"before window", start - 1, 0, 0, false, apitypes.TSpendMempool, false, ""},
+		{"no votes yet", start + 10, 0, 0, false, apitypes.TSpendVoting, false, ""},
+		// 1000 votes in a quarter of the window project 4000 votes, above the
+		// quorum of 3456, with 75% approval.
+		{"passing", start + 864, 750, 250, false, apitypes.TSpendVoting, false, apitypes.TSpendPass},
+		{"low approval", start + 864, 500, 500, false, apitypes.TSpendVoting, false, apitypes.TSpendFail},
+		{"low turnout", start + 864, 300, 0, false, apitypes.TSpendVoting, false, apitypes.TSpendFail},
+		{"approved", start + 2000, 3000, 500, false, apitypes.TSpendVoting, true, apitypes.TSpendPass},
+		{"expired", end, 3000, 2500, false, apitypes.TSpendExpired, false, apitypes.TSpendFail},
+		{"mined", start + 2304, 3000, 500, true, apitypes.TSpendMined, true, apitypes.TSpendPass},
+	}
+	for _, tt := range tests {
+		ts := &apitypes.TSpendProgress{
+			VoteStart: start,
+			VoteEnd:   end,
+			YesVotes:  tt.yes,
+			NoVotes:   tt.no,
+		}
+		if tt.mined {
+			ts.BlockHash = "block"
+		}
+		tallyTSpend(ts, tt.height, p)
+		if ts.Status != tt.status || ts.Approved != tt.approved || ts.Projected != tt.projected {
+			t.Errorf("%s: status %s, approved %v, projected %q, expected %s, %v, %q", tt.name,
+				ts.Status, ts.Approved, ts.Projected, tt.status, tt.approved, tt.projected)
+		}
+		if ts.MaxVotes != 17280 || ts.Quorum != 3456 || ts.RequiredApproval != 0.6 {
+			t.Errorf("%s: max votes %d, quorum %d, required approval %v", tt.name,
+				ts.MaxVotes, ts.Quorum, ts.RequiredApproval)
+		}
+	}
+}
+
+func TestTSpendTracker(t *testing.T) {
+	p := chaincfg.MainNetParams()
+	const txid = "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"
+	tsv := &dbtypes.TreasurySpendVotes{
+		Hash:      txid,
+		Expiry:    13826,
+		VoteStart: 10368,
+		VoteEnd:   13824,
+	}
+	source := &testTSpendSource{votes: map[string]*dbtypes.TreasurySpendVotes{txid: tsv}}
+	sigs := make(chan pstypes.HubMessage, 8)
+	tracker := NewTSpendTracker(source, p, []chan<- pstypes.HubMessage{sigs})
+
+	snapshot := func(height int64) {
+		tracker.StoreMPData(&StakeData{LatestBlock: BlockID{Height: height}, Time: time.Now()},
+			[]exptypes.MempoolTx{{Hash: txid, TypeID: int(stake.TxTypeTSpend), TotalOut: 100, Expiry: 13826}}, nil)
+	}
+	next := func() *apitypes.TSpendProgress {
+		select {
+		case msg := <-sigs:
+			return msg.Msg.(*apitypes.TSpendProgress)
+		default:
+			return nil
+		}
+	}
+
+	// A new tspend in mempool before its window is signaled once.
+	snapshot(10000)
+	if ts := next(); ts == nil || ts.Status != apitypes.TSpendMempool || ts.Amount != 100 {
+		t.Fatalf("unexpected new tspend signal %+v", ts)
+	}
+	snapshot(10000)
+	if ts := next(); ts != nil {
+		t.Errorf("unchanged tspend signaled %+v", ts)
+	}
+
+	// The tally of the next block is signaled.
+	tsv.YesVotes, tsv.NoVotes = 500, 100
+	snapshot(10500)
+	if ts := next(); ts == nil || ts.Status != apitypes.TSpendVoting || ts.YesVotes != 500 {
+		t.Fatalf("unexpected tally signal %+v", ts)
+	}
+
+	// The tspend is dropped one voting window after it expires.
+	snapshot(13824)
+	if ts := next(); ts == nil || ts.Status != apitypes.TSpendExpired {
+		t.Fatalf("unexpected expiry signal %+v", ts)
+	}
+	tracker.StoreMPData(&StakeData{LatestBlock: BlockID{Height: 13824 + 3457}}, nil, nil)
+	if tspends := tracker.TSpends(); len(tspends.TSpends) != 0 || tspends.Height != 13824+3457 {
+		t.Errorf("expired tspend not dropped: %+v", tspends)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package mempool
+
+import (
+	"sync"
+	"time"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+)
+
+// voteSightingBlocks is the number of blocks below the best block whose votes'
+// sightings are kept.
+const voteSightingBlocks = 64
+
+// voteKey identifies the vote of a ticket on a block.
+type voteKey struct {
+	ticket string
+	block  string
+}
+
+// voteSighting is the time a vote was first seen in mempool, and the height of
+// the block it votes on.
+type voteSighting struct {
+	firstSeen time.Time
+	height    int64
+}
+
+// voteSightings tracks the time the votes of each ticket on recent blocks
+// were first seen in mempool, so that the misses of the tickets whose votes
+// were not mined can be told from the misses of tickets that did not vote.
+type voteSightings struct {
+	mtx   sync.RWMutex
+	since time.Time
+	seen  map[voteKey]voteSighting
+}
+
+func newVoteSightings(since time.Time) *voteSightings {
+	return &voteSightings{
+		since: since,
+		seen:  make(map[voteKey]voteSighting),
+	}
+}
+
+// see records the sighting of the vote, keeping the earliest time the vote was
+// seen. The time of the vote's first sighting is firstSeen if set, and now
+// otherwise.
+func (vs *voteSightings) see(vote *exptypes.VoteInfo, firstSeen int64, now time.Time) {
+	if vote == nil {
+		return
+	}
+	t := now
+	if firstSeen > 0 && firstSeen < now.Unix() {
+		t = time.Unix(firstSeen, 0)
+	}
+	key := voteKey{vote.TicketSpent, vote.Validation.Hash}
+
+	vs.mtx.Lock()
+	defer vs.mtx.Unlock()
+	if s, found := vs.seen[key]; found && !t.Before(s.firstSeen) {
+		return
+	}
+	vs.seen[key] = voteSighting{t, vote.Validation.Height}
+}
+
+// prune forgets the sightings of votes on blocks more than voteSightingBlocks
+// below the height.
+func (vs *voteSightings) prune(height int64) {
+	vs.mtx.Lock()
+	defer vs.mtx.Unlock()
+	for key, s := range vs.seen {
+		if s.height < height-voteSightingBlocks {
+			delete(vs.seen, key)
+		}
+	}
+}
+
+// firstSeen returns the time the vote of the ticket on the block was first
+// seen, and false if the vote was not seen.
+func (vs *voteSightings) firstSeen(ticket, blockHash string) (time.Time, bool) {
+	vs.mtx.RLock()
+	defer vs.mtx.RUnlock()
+	s, found := vs.seen[voteKey{ticket, blockHash}]
+	return s.firstSeen, found
+}
+
+// VoteFirstSeen returns the time the vote of the ticket on the block with the
+// hash was first seen in mempool, and false if the vote was not seen. The
+// votes on blocks more than 64 blocks below the best block are forgotten.
+func (p *MempoolMonitor) VoteFirstSeen(ticket, blockHash string) (time.Time, bool) {
+	return p.voteSightings.firstSeen(ticket, blockHash)
+}
+
+// WatchingSince returns the time the MempoolMonitor started watching mempool.
+// Votes cast before this time were not seen.
+func (p *MempoolMonitor) WatchingSince() time.Time {
+	return p.voteSightings.since
+}
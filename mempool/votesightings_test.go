@@ -0,0 +1,48 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+)
+
+func TestVoteSightings(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	vs := newVoteSightings(now.Add(-time.Hour))
+
+	vote := func(ticket, block string, height int64) *exptypes.VoteInfo {
+		return &exptypes.VoteInfo{
+			Validation:  exptypes.BlockValidation{Hash: block, Height: height},
+			TicketSpent: ticket,
+		}
+	}
+
+	// The mempool time of the vote is its first sighting, and a later sighting
+	// of the same vote does not change it.
+	vs.see(vote("t1", "b100", 100), now.Unix()-60, now)
+	vs.see(vote("t1", "b100", 100), 0, now.Add(time.Minute))
+	// A vote without a mempool time is seen now.
+	vs.see(vote("t2", "b100", 100), 0, now)
+	vs.see(nil, 0, now)
+
+	if seen, ok := vs.firstSeen("t1", "b100"); !ok || !seen.Equal(now.Add(-time.Minute)) {
+		t.Errorf("vote first seen %v (%v), expected %v", seen, ok, now.Add(-time.Minute))
+	}
+	if seen, ok := vs.firstSeen("t2", "b100"); !ok || !seen.Equal(now) {
+		t.Errorf("vote first seen %v (%v), expected %v", seen, ok, now)
+	}
+	// The vote of the ticket on another block was not seen.
+	if _, ok := vs.firstSeen("t1", "b101"); ok {
+		t.Errorf("unseen vote reported as seen")
+	}
+
+	vs.see(vote("t3", "b150", 150), 0, now)
+	vs.prune(100 + voteSightingBlocks + 1)
+	if _, ok := vs.firstSeen("t1", "b100"); ok {
+		t.Errorf("vote sighting not pruned")
+	}
+	if _, ok := vs.firstSeen("t3", "b150"); !ok {
+		t.Errorf("recent vote sighting pruned")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/decred/slog"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	exptypes "github.com/decred/dcrdata/v6/explorer/types"
 	"github.com/decred/dcrdata/v6/pubsub/psclient"
 	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
@@ -54,7 +55,7 @@ func main() {
 
 	// Subscribe/unsubscribe to several events.
 	var currentSubs []string
-	allSubs := []string{"ping", "newtxs", "newblock", "mempool", "address:Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx", "address"}
+	allSubs := []string{"ping", "newtxs", "newblock", "mempool", "feeestimate", "doublespend", "tspend", "address:Dcur2mcGjmENx4DhNqDctW5wJCVyT3Qeqkx", "address"}
 	subscribe := func(newsubs []string) error {
 		for _, sub := range newsubs {
 			if subd, _ := strInSlice(currentSubs, sub); subd {
@@ -227,6 +228,20 @@ func main() {
 			t := time.Unix(m.Time, 0)
 			log.Printf("Message (%s): MempoolShort(numTx=%d, time=%v)",
 				msg.EventId, m.NumAll, t)
+		case *apitypes.FeeEstimates:
+			for _, fe := range m.Estimates {
+				log.Printf("Message (%s): FeeEstimate(target=%d, feeRate=%.8f, confidence=%.2f)",
+					msg.EventId, fe.TargetBlocks, fe.FeeRate, fe.Confidence)
+			}
+		case *pstypes.DoubleSpend:
+			log.Printf("Message (%s): DoubleSpend(txid=%s, conflict=%s, mined=%v)",
+				msg.EventId, m.TxID, m.ConflictTxID, m.Mined)
+		case *pstypes.TicketSetUpdate:
+			log.Printf("Message (%s): TicketSetUpdate(set=%s, height=%d, transitions=%d)",
+				msg.EventId, m.SetID, m.BlockHeight, len(m.Transitions))
+		case *apitypes.TSpendProgress:
+			log.Printf("Message (%s): TSpendProgress(txid=%s, status=%s, yes=%d, no=%d)",
+				msg.EventId, m.TxID, m.Status, m.YesVotes, m.NoVotes)
 		case *pstypes.TxList:
 			log.Printf("Message (%s): TxList(len=%d)", msg.EventId, len(*m))
 		case *pstypes.AddressMessage:
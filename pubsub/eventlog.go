@@ -0,0 +1,136 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"encoding/json"
+	"sync"
+
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+// EventLogSize is the number of the most recent events kept in the event log
+// for clients resuming an event stream.
+const EventLogSize = 512
+
+// loggedEvent is an event of the event log, with the message pushed to the
+// subscribed clients.
+type loggedEvent struct {
+	id     uint64
+	signal pstypes.HubSignal
+	// address is the address of a SigAddressTx event.
+	address string
+	// ticketSet is the ticket set ID of a SigTicketSet event.
+	ticketSet string
+	msg       json.RawMessage
+}
+
+// subscribed checks if the client is subscribed to the event.
+func (e *loggedEvent) subscribed(cl *client) bool {
+	msg := pstypes.HubMessage{Signal: e.signal}
+	switch e.signal {
+	case sigAddressTx:
+		msg.Msg = &pstypes.AddressMessage{Address: e.address}
+	case sigTicketSet:
+		msg.Msg = &pstypes.TicketSetUpdate{SetID: e.ticketSet}
+	}
+	return cl.isSubscribed(msg)
+}
+
+// eventLog is a bounded log of the subscription events signaled by the
+// WebsocketHub. The events are numbered in order, starting after the given
+// start ID.
+type eventLog struct {
+	mtx    sync.RWMutex
+	events []*loggedEvent
+	size   int
+	lastID uint64
+	// encode returns the message of an event pushed to clients.
+	encode func(pstypes.HubMessage) (json.RawMessage, bool)
+}
+
+func newEventLog(size int, start uint64, encode func(pstypes.HubMessage) (json.RawMessage, bool)) *eventLog {
+	return &eventLog{
+		events: make([]*loggedEvent, 0, size),
+		size:   size,
+		lastID: start,
+		encode: encode,
+	}
+}
+
+// add logs the event, and returns its ID. Events that are not subscription
+// based are not logged, and their ID is zero. A SigNewTx event is logged as a
+// SigNewTxs event of the one transaction.
+func (l *eventLog) add(hubMsg pstypes.HubMessage) uint64 {
+	e := &loggedEvent{signal: hubMsg.Signal}
+	switch hubMsg.Signal {
+	case sigNewBlock, sigMempoolUpdate, sigFeeEstimate, sigDoubleSpend, sigTSpend:
+	case sigAddressTx:
+		e.address = hubMsg.Msg.(*pstypes.AddressMessage).Address
+	case sigTicketSet:
+		e.ticketSet = hubMsg.Msg.(*pstypes.TicketSetUpdate).SetID
+	case sigNewTx:
+		e.signal = sigNewTxs
+	default:
+		return 0
+	}
+	msg, ok := l.encode(hubMsg)
+	if !ok {
+		return 0
+	}
+	e.msg = msg
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.lastID++
+	e.id = l.lastID
+	if len(l.events) == l.size {
+		copy(l.events, l.events[1:])
+		l.events = l.events[:l.size-1]
+	}
+	l.events = append(l.events, e)
+	return e.id
+}
+
+// since returns the logged events after the event with the given ID, oldest
+// first. complete is false if some of the events after id are no longer in
+// the log.
+func (l *eventLog) since(id uint64) (events []*loggedEvent, complete bool) {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if id >= l.lastID {
+		return nil, id == l.lastID
+	}
+	if len(l.events) == 0 {
+		return nil, false
+	}
+	oldest := l.events[0].id
+	if id+1 < oldest {
+		return append(events, l.events...), false
+	}
+	return append(events, l.events[id+1-oldest:]...), true
+}
+
+// replay pushes the logged events after the event with the given ID that the
+// client is subscribed to, oldest first. last is the ID of the last logged
+// event, or zero if there are none after id. n is the number of events pushed,
+// and complete is false if some of the events after id are no longer in the
+// log.
+func (l *eventLog) replay(cl *client, id uint64, push func(*pstypes.WebSocketMessage, uint64) error) (last uint64, n int, complete bool, err error) {
+	events, complete := l.since(id)
+	for _, e := range events {
+		if e.subscribed(cl) {
+			err = push(&pstypes.WebSocketMessage{
+				EventId: e.signal.String(),
+				Message: e.msg,
+			}, e.id)
+			if err != nil {
+				return last, n, complete, err
+			}
+			n++
+		}
+		last = e.id
+	}
+	return last, n, complete, nil
+}
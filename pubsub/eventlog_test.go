@@ -0,0 +1,64 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"testing"
+
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+func TestEventLog(t *testing.T) {
+	l := newEventLog(3, 0, func(pstypes.HubMessage) (json.RawMessage, bool) {
+		return json.RawMessage(`{}`), true
+	})
+
+	if id := l.add(pstypes.HubMessage{Signal: sigPingAndUserCount}); id != 0 {
+		t.Errorf("ping logged with ID %d", id)
+	}
+	if events, complete := l.since(0); len(events) != 0 || !complete {
+		t.Errorf("empty log returned %d events, complete %v", len(events), complete)
+	}
+
+	for i := uint64(1); i <= 4; i++ {
+		msg := pstypes.HubMessage{Signal: sigNewBlock}
+		if i == 4 {
+			msg = pstypes.HubMessage{Signal: sigNewTx}
+		}
+		if id := l.add(msg); id != i {
+			t.Fatalf("event logged with ID %d, expected %d", id, i)
+		}
+	}
+
+	tests := []struct {
+		since    uint64
+		ids      []uint64
+		complete bool
+	}{
+		{0, []uint64{2, 3, 4}, false},
+		{1, []uint64{2, 3, 4}, true},
+		{3, []uint64{4}, true},
+		{4, nil, true},
+		{9, nil, false},
+	}
+	for _, tt := range tests {
+		events, complete := l.since(tt.since)
+		if complete != tt.complete {
+			t.Errorf("since(%d): complete %v, expected %v", tt.since, complete, tt.complete)
+		}
+		if len(events) != len(tt.ids) {
+			t.Errorf("since(%d): %d events, expected %d", tt.since, len(events), len(tt.ids))
+			continue
+		}
+		for i, e := range events {
+			if e.id != tt.ids[i] {
+				t.Errorf("since(%d): event %d has ID %d, expected %d", tt.since, i, e.id, tt.ids[i])
+			}
+		}
+	}
+
+	// SigNewTx is logged as SigNewTxs.
+	events, _ := l.since(3)
+	if events[0].signal != sigNewTxs {
+		t.Errorf("new tx logged as %v", events[0].signal)
+	}
+}
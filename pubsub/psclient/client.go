@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	exptypes "github.com/decred/dcrdata/v6/explorer/types"
 	pubsub "github.com/decred/dcrdata/v6/pubsub"
 	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
@@ -78,6 +82,21 @@ func newServerVersionMsg(reqID int64) []byte {
 	return verMsg
 }
 
+// newResumeMsg creates a new resume message with EventId set to "resume", and
+// request message content with the sequence number of the last event received
+// for the specified reqID.
+func newResumeMsg(seq uint64, reqID int64) []byte {
+	resumeMsg, err := json.Marshal(pstypes.WebSocketMessage{
+		EventId: "resume",
+		Message: makeRequestMsg(strconv.FormatUint(seq, 10), reqID),
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to json.Marshal a WebSocketMessage: %v", err))
+	}
+
+	return resumeMsg
+}
+
 // newPingMsg creates a new ping message with EventId set to "ping", and request
 // message content generated for the specified reqID.
 func newPingMsg(reqID int64) []byte {
@@ -95,18 +114,35 @@ func newPingMsg(reqID int64) []byte {
 const (
 	DefaultReadTimeout  = pubsub.PingInterval * 10 / 9
 	DefaultWriteTimeout = 5 * time.Second
+
+	// minReconnectDelay and maxReconnectDelay bound the delay between
+	// reconnection attempts, which doubles after each failed attempt.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
 )
 
 // Opts defines the psclient Client options.
 type Opts struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// Reconnect makes a Client created with New reconnect to the server when
+	// the connection is lost, restoring its subscriptions. The events missed
+	// while disconnected are replayed if the server still has them, otherwise
+	// a "gap" message is received first.
+	Reconnect bool
+	// APIKey is the API key sent when connecting, if set, for the rate limit
+	// and quota of the key instead of the per-IP rate limit.
+	APIKey string
 }
 
 // Client wraps a *websocket.Conn.
 type Client struct {
+	// Conn is replaced when the Client reconnects.
 	*websocket.Conn
 	ourConn       bool
+	url           string
+	apiKey        string
+	reconnect     bool
 	readTimeout   time.Duration
 	writeTimeout  time.Duration
 	reqMtx        sync.Mutex
@@ -114,31 +150,55 @@ type Client struct {
 	nextRequestID int64
 	requests      map[int64]chan *pstypes.ResponseMessage
 	sendMtx       sync.Mutex
+	subsMtx       sync.Mutex
+	subs          map[string]struct{}
+	lastSeq       uint64 // atomic
 	ctx           context.Context
 	shutdown      context.CancelFunc
 }
 
-// New creates a new Client from a URL.
-func New(url string, ctx context.Context, opts *Opts) (*Client, error) {
-	ws, err := websocket.Dial(url, "", "/")
+// dial connects to the pubsub server at url, sending the API key if it is
+// set.
+func dial(url, apiKey string) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(url, "/")
 	if err != nil {
 		return nil, err
 	}
+	if apiKey != "" {
+		config.Header.Set("X-API-Key", apiKey)
+	}
+	return websocket.DialConfig(config)
+}
 
+// New creates a new Client from a URL.
+func New(url string, ctx context.Context, opts *Opts) (*Client, error) {
 	readTimeout, writeTimeout := DefaultReadTimeout, DefaultWriteTimeout
+	var reconnect bool
+	var apiKey string
 	if opts != nil {
 		readTimeout = opts.ReadTimeout
 		writeTimeout = opts.WriteTimeout
+		reconnect = opts.Reconnect
+		apiKey = opts.APIKey
+	}
+
+	ws, err := dial(url, apiKey)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx, shutdown := context.WithCancel(ctx)
 	cl := &Client{
 		Conn:         ws,
 		ourConn:      true,
+		url:          url,
+		apiKey:       apiKey,
+		reconnect:    reconnect,
 		readTimeout:  readTimeout,
 		writeTimeout: writeTimeout,
 		recvMsgChan:  make(chan *ClientMessage, 16),
 		requests:     make(map[int64]chan *pstypes.ResponseMessage),
+		subs:         make(map[string]struct{}),
 		ctx:          ctx,
 		shutdown:     shutdown,
 	}
@@ -153,18 +213,25 @@ func New(url string, ctx context.Context, opts *Opts) (*Client, error) {
 	}
 	log.Infof("Server pubsub version: %s\n", serverVer)
 
-	// Ensure the server's pubsub version (actual) is compatible with the
-	// client's version (required). This allows the client to have a high minor
-	// version for equal major versions.
+	if err = checkServerVersion(serverVer); err != nil {
+		cl.Stop()
+		return nil, err
+	}
+
+	return cl, nil
+}
+
+// checkServerVersion ensures the server's pubsub version (actual) is
+// compatible with the client's version (required). This allows the client to
+// have a high minor version for equal major versions.
+func checkServerVersion(serverVer *pstypes.Ver) error {
 	clientSemVer := Version()
 	serverSemVer := semver.NewSemver(serverVer.Major, serverVer.Minor, serverVer.Patch)
 	if !semver.Compatible(clientSemVer, serverSemVer) {
-		cl.Stop()
-		return nil, fmt.Errorf("server pubsub version is %v, but client is version %v",
+		return fmt.Errorf("server pubsub version is %v, but client is version %v",
 			serverSemVer, clientSemVer)
 	}
-
-	return cl, nil
+	return nil
 }
 
 // NewFromConn creates a new Client from a *websocket.Conn.
@@ -186,6 +253,7 @@ func NewFromConn(ws *websocket.Conn, ctx context.Context, opts *Opts) *Client {
 		writeTimeout: writeTimeout,
 		recvMsgChan:  make(chan *ClientMessage, 16),
 		requests:     make(map[int64]chan *pstypes.ResponseMessage),
+		subs:         make(map[string]struct{}),
 		ctx:          ctx,
 		shutdown:     shutdown,
 	}
@@ -205,7 +273,10 @@ func (c *Client) Stop() {
 
 	// Close the websocket connection.
 	if c.ourConn {
-		if err := c.Conn.Close(); err != nil {
+		c.sendMtx.Lock()
+		ws := c.Conn
+		c.sendMtx.Unlock()
+		if err := ws.Close(); err != nil {
 			log.Errorf("Failed to Close websocket connection: %v", err)
 		}
 	}
@@ -216,6 +287,23 @@ func (c *Client) Stop() {
 type ClientMessage struct {
 	EventId string
 	Message interface{}
+	// Seq is the sequence number of a subscription event. See
+	// pstypes.WebSocketMessage.
+	Seq uint64
+}
+
+// Gap is the Message of a ClientMessage with EventId "gap", received after
+// the Client reconnected when some of the events it missed are no longer
+// available from the server.
+type Gap struct {
+	// LastSeq is the sequence number of the last event received before the
+	// gap.
+	LastSeq uint64
+}
+
+// LastSeq returns the sequence number of the last subscription event received.
+func (c *Client) LastSeq() uint64 {
+	return atomic.LoadUint64(&c.lastSeq)
 }
 
 // Receive gets a receive-only *ClientMessage channel, through which all
@@ -247,6 +335,9 @@ func (c *Client) receiver() {
 			// Even a timeout should close shutdown the client since that
 			// indicates pings from the server did not arrive in time.
 			log.Errorf("ReceiveMsg failed: %v", err)
+			if c.reconnect && c.ourConn && c.reconnectLoop() {
+				continue
+			}
 			return
 		}
 
@@ -265,18 +356,20 @@ func (c *Client) receiver() {
 				log.Errorf("receiver failed to find request ID %d", m.RequestId)
 				continue
 			}
-			go func() {
-				respChan <- m // unbuffered
-				close(respChan)
-				c.deleteRequestID(m.RequestId)
-			}()
+			respChan <- m // buffered for the one response
+			c.deleteRequestID(m.RequestId)
 			continue
 		case *pstypes.HangUp:
-			log.Infof("The server is hanging up on us! Shutting down.")
 			c.recvMsgChan <- &ClientMessage{
 				EventId: resp.EventId,
 				Message: msg,
 			}
+			if c.reconnect && c.ourConn {
+				// Reconnect when the server closes the connection.
+				log.Infof("The server is hanging up on us!")
+				continue
+			}
+			log.Infof("The server is hanging up on us! Shutting down.")
 			return
 		case string:
 			// generic "message"
@@ -300,13 +393,172 @@ func (c *Client) receiver() {
 			continue
 		}
 
-		c.recvMsgChan <- &ClientMessage{
+		c.deliver(&ClientMessage{
 			EventId: resp.EventId,
 			Message: msg,
+			Seq:     resp.Seq,
+		})
+	}
+}
+
+// deliver sends the message on recvMsgChan, dropping subscription events that
+// were already received.
+func (c *Client) deliver(cm *ClientMessage) {
+	if cm.Seq != 0 {
+		if cm.Seq <= c.LastSeq() {
+			log.Debugf("Dropping repeated %s event %d.", cm.EventId, cm.Seq)
+			return
+		}
+		atomic.StoreUint64(&c.lastSeq, cm.Seq)
+	}
+	c.recvMsgChan <- cm
+}
+
+// reconnectLoop reconnects to the server, waiting longer after each failed
+// attempt. reconnectLoop returns false if the Client is stopped first.
+func (c *Client) reconnectLoop() bool {
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+
+		err := c.resume()
+		if err == nil {
+			return true
+		}
+		log.Errorf("Failed to reconnect: %v", err)
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
 		}
 	}
 }
 
+// resume replaces the Client's connection with a new one, restores the
+// subscriptions, and requests the events missed since the last one received.
+// The events received before the response to the resume request, both
+// replayed and new, are delivered in order of their sequence numbers. resume
+// is only called from the receiver goroutine, which reads the responses to its
+// requests directly.
+func (c *Client) resume() error {
+	ws, err := dial(c.url, c.apiKey)
+	if err != nil {
+		return err
+	}
+	c.sendMtx.Lock()
+	oldWS := c.Conn
+	c.Conn = ws
+	c.sendMtx.Unlock()
+	oldWS.Close()
+	if c.ctx.Err() != nil {
+		// Stop may have closed the old connection.
+		ws.Close()
+		return c.ctx.Err()
+	}
+	log.Infof("Reconnected to %s.", c.url)
+
+	// Messages received while resuming.
+	var held []*ClientMessage
+
+	request := func(msg []byte, reqID int64) (*pstypes.ResponseMessage, error) {
+		defer c.deleteRequestID(reqID)
+		if err := c.send(msg); err != nil {
+			return nil, err
+		}
+		for {
+			resp, err := c.receiveMsg()
+			if err != nil {
+				return nil, err
+			}
+			msg, err := DecodeMsg(resp)
+			if err != nil {
+				log.Errorf("Failed to decode message: %v", err)
+				continue
+			}
+			rm, ok := msg.(*pstypes.ResponseMessage)
+			if !ok {
+				held = append(held, &ClientMessage{
+					EventId: resp.EventId,
+					Message: msg,
+					Seq:     resp.Seq,
+				})
+				continue
+			}
+			if rm.RequestId == reqID {
+				return rm, nil
+			}
+			// A response to a request of another goroutine.
+			if respChan := c.responseChan(rm.RequestId); respChan != nil {
+				respChan <- rm
+			}
+		}
+	}
+
+	_, reqID := c.newResponseChan()
+	resp, err := request(newServerVersionMsg(reqID), reqID)
+	if err != nil {
+		return err
+	}
+	var ver pstypes.Ver
+	if err = json.Unmarshal([]byte(resp.Data), &ver); err != nil {
+		return fmt.Errorf("failed to decode server version response: %v", err)
+	}
+	if err = checkServerVersion(&ver); err != nil {
+		return err
+	}
+
+	for _, event := range c.subscriptions() {
+		_, reqID = c.newResponseChan()
+		resp, err = request(newSubscribeMsg(event, reqID), reqID)
+		if err != nil {
+			return err
+		}
+		if !resp.Success {
+			log.Errorf("Failed to subscribe to %s: %s", event, resp.Data)
+		}
+	}
+
+	lastSeq := c.LastSeq()
+	var res pstypes.ResumeResult
+	if lastSeq != 0 {
+		_, reqID = c.newResponseChan()
+		resp, err = request(newResumeMsg(lastSeq, reqID), reqID)
+		if err != nil {
+			return err
+		}
+		if resp.Success {
+			err = json.Unmarshal([]byte(resp.Data), &res)
+		}
+		if !resp.Success || err != nil {
+			log.Errorf("Failed to resume after event %d: %s", lastSeq, resp.Data)
+		}
+	}
+
+	if lastSeq != 0 && !res.Complete {
+		log.Warnf("Some events after %d were lost.", lastSeq)
+		c.recvMsgChan <- &ClientMessage{
+			EventId: "gap",
+			Message: &Gap{LastSeq: lastSeq},
+		}
+		// The server may have restarted, so accept any sequence number.
+		atomic.StoreUint64(&c.lastSeq, 0)
+	} else {
+		log.Infof("Resumed after event %d, %d events replayed.", lastSeq, res.Replayed)
+	}
+
+	sort.SliceStable(held, func(i, j int) bool {
+		return held[i].Seq < held[j].Seq
+	})
+	for _, cm := range held {
+		c.deliver(cm)
+	}
+	return nil
+}
+
 func (c *Client) send(msg []byte) error {
 	c.sendMtx.Lock()
 	defer c.sendMtx.Unlock()
@@ -333,7 +585,7 @@ func (c *Client) newResponseChan() (chan *pstypes.ResponseMessage, int64) {
 	c.reqMtx.Lock()
 	reqID := c.nextRequestID
 	c.nextRequestID++
-	respChan := make(chan *pstypes.ResponseMessage)
+	respChan := make(chan *pstypes.ResponseMessage, 1)
 	c.requests[reqID] = respChan
 	c.reqMtx.Unlock()
 	return respChan, reqID
@@ -376,6 +628,11 @@ func (c *Client) Subscribe(event string) (*pstypes.ResponseMessage, error) {
 	if !ok {
 		return nil, fmt.Errorf("Response channel closed.")
 	}
+	if resp.Success {
+		c.subsMtx.Lock()
+		c.subs[event] = struct{}{}
+		c.subsMtx.Unlock()
+	}
 
 	// Read the response.
 	return resp, nil
@@ -401,11 +658,28 @@ func (c *Client) Unsubscribe(event string) (*pstypes.ResponseMessage, error) {
 
 	// Wait for a response with the requestID.
 	resp := <-respChan
+	if resp.Success {
+		c.subsMtx.Lock()
+		delete(c.subs, event)
+		c.subsMtx.Unlock()
+	}
 
 	// Read the response.
 	return resp, nil
 }
 
+// subscriptions returns the events the Client is subscribed to.
+func (c *Client) subscriptions() []string {
+	c.subsMtx.Lock()
+	defer c.subsMtx.Unlock()
+	events := make([]string, 0, len(c.subs))
+	for event := range c.subs {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	return events
+}
+
 // ServerVersion sends a server version query, and returns the response.
 func (c *Client) ServerVersion() (*pstypes.Ver, error) {
 	respChan, reqID := c.newResponseChan()
@@ -510,6 +784,22 @@ func DecodeMsg(msg *pstypes.WebSocketMessage) (interface{}, error) {
 		var mpshort exptypes.MempoolShort
 		err := json.Unmarshal(msg.Message, &mpshort)
 		return &mpshort, err
+	case "feeestimate":
+		var fe apitypes.FeeEstimates
+		err := json.Unmarshal(msg.Message, &fe)
+		return &fe, err
+	case "doublespend":
+		var ds pstypes.DoubleSpend
+		err := json.Unmarshal(msg.Message, &ds)
+		return &ds, err
+	case "tickets":
+		var tsu pstypes.TicketSetUpdate
+		err := json.Unmarshal(msg.Message, &tsu)
+		return &tsu, err
+	case "tspend":
+		var ts apitypes.TSpendProgress
+		err := json.Unmarshal(msg.Message, &ts)
+		return &ts, err
 	default:
 		return nil, fmt.Errorf("unrecognized event type")
 	}
@@ -618,3 +908,59 @@ func DecodeMsgNewAddressTx(msg *pstypes.WebSocketMessage) (*pstypes.AddressMessa
 	}
 	return am, nil
 }
+
+// DecodeMsgFeeEstimate attempts to decode the Message content of the given
+// WebSocketMessage as a feeestimate message (*apitypes.FeeEstimates).
+func DecodeMsgFeeEstimate(msg *pstypes.WebSocketMessage) (*apitypes.FeeEstimates, error) {
+	fe, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	estimates, ok := fe.(*apitypes.FeeEstimates)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *apitypes.FeeEstimates")
+	}
+	return estimates, nil
+}
+
+// DecodeMsgDoubleSpend attempts to decode the Message content of the given
+// WebSocketMessage as a doublespend message (*pstypes.DoubleSpend).
+func DecodeMsgDoubleSpend(msg *pstypes.WebSocketMessage) (*pstypes.DoubleSpend, error) {
+	ds, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	doubleSpend, ok := ds.(*pstypes.DoubleSpend)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *pstypes.DoubleSpend")
+	}
+	return doubleSpend, nil
+}
+
+// DecodeMsgTicketSetUpdate attempts to decode the Message content of the given
+// WebSocketMessage as a tickets message (*pstypes.TicketSetUpdate).
+func DecodeMsgTicketSetUpdate(msg *pstypes.WebSocketMessage) (*pstypes.TicketSetUpdate, error) {
+	tsu, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	update, ok := tsu.(*pstypes.TicketSetUpdate)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *pstypes.TicketSetUpdate")
+	}
+	return update, nil
+}
+
+// DecodeMsgTSpend attempts to decode the Message content of the given
+// WebSocketMessage as a tspend message (*apitypes.TSpendProgress).
+func DecodeMsgTSpend(msg *pstypes.WebSocketMessage) (*apitypes.TSpendProgress, error) {
+	ts, err := DecodeMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	tspend, ok := ts.(*apitypes.TSpendProgress)
+	if !ok {
+		return nil, fmt.Errorf("content of Message was not of type *apitypes.TSpendProgress")
+	}
+	return tspend, nil
+}
@@ -1,10 +1,20 @@
 package psclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/decred/dcrd/chaincfg/v3"
+	chainjson "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	"github.com/decred/dcrdata/v6/pubsub"
 	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
 )
 
@@ -843,3 +853,113 @@ func Test_newUnsubscribeMsg(t *testing.T) {
 			b, expectedMsg)
 	}
 }
+
+func Test_newResumeMsg(t *testing.T) {
+	expectedMsg := `{"event":"resume","message":{"request_id":789,"message":"1234"}}`
+
+	b := newResumeMsg(1234, 789)
+	if string(b) != expectedMsg {
+		t.Errorf("Wrong message. Got \"%s\", expected \"%s\".",
+			b, expectedMsg)
+	}
+}
+
+// hubDataSource is a minimal data source of a pubsub.PubSubHub.
+type hubDataSource struct{}
+
+func (hubDataSource) GetExplorerBlock(hash string) *exptypes.BlockInfo { return nil }
+func (hubDataSource) DecodeRawTransaction(txhex string) (*chainjson.TxRawResult, error) {
+	return nil, errors.New("not implemented")
+}
+func (hubDataSource) SendRawTransaction(txhex string) (string, error) {
+	return "", errors.New("not implemented")
+}
+func (hubDataSource) GetChainParams() *chaincfg.Params   { return chaincfg.MainNetParams() }
+func (hubDataSource) GetMempool() []exptypes.MempoolTx   { return nil }
+func (hubDataSource) Difficulty(timestamp int64) float64 { return 0 }
+func (hubDataSource) BlockSubsidy(height int64, voters uint16) *chainjson.GetBlockSubsidyResult {
+	return nil
+}
+
+func TestClientReconnect(t *testing.T) {
+	const addr = "DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC"
+
+	psh, err := pubsub.NewPubSubHub(hubDataSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer psh.StopWebsocketHub()
+	srv := httptest.NewServer(http.HandlerFunc(psh.WebSocketHandler))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cl, err := New("ws"+strings.TrimPrefix(srv.URL, "http"), ctx, &Opts{
+		ReadTimeout:  DefaultReadTimeout,
+		WriteTimeout: DefaultWriteTimeout,
+		Reconnect:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Stop()
+	if _, err = cl.Subscribe("address:" + addr); err != nil {
+		t.Fatal(err)
+	}
+
+	signal := func(txHash string) {
+		psh.HubRelay() <- pstypes.HubMessage{
+			Signal: pstypes.SigAddressTx,
+			Msg:    &pstypes.AddressMessage{Address: addr, TxHash: txHash},
+		}
+	}
+	receive := func() *ClientMessage {
+		t.Helper()
+		for {
+			select {
+			case msg := <-cl.Receive():
+				if msg.EventId != "ping" {
+					return msg
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("timed out waiting for a message")
+			}
+		}
+	}
+	receiveTx := func(txHash string) uint64 {
+		t.Helper()
+		msg := receive()
+		am, ok := msg.Message.(*pstypes.AddressMessage)
+		if !ok || am.TxHash != txHash || msg.Seq == 0 {
+			t.Fatalf("unexpected message %s %v (seq %d), expected tx %s",
+				msg.EventId, msg.Message, msg.Seq, txHash)
+		}
+		return msg.Seq
+	}
+
+	signal("a")
+	seqA := receiveTx("a")
+
+	// The events signaled while disconnected are replayed.
+	cl.Conn.Close()
+	signal("b")
+	signal("c")
+	seqB := receiveTx("b")
+	seqC := receiveTx("c")
+	if seqA >= seqB || seqB >= seqC {
+		t.Errorf("sequence numbers %d, %d, %d not increasing", seqA, seqB, seqC)
+	}
+
+	// A gap is reported when the missed events are no longer available.
+	cl.Conn.Close()
+	for i := 0; i < pubsub.EventLogSize+1; i++ {
+		signal(strconv.Itoa(i))
+	}
+	msg := receive()
+	if gap, ok := msg.Message.(*Gap); msg.EventId != "gap" || !ok || gap.LastSeq != seqC {
+		t.Fatalf("unexpected message %s %v, expected a gap after %d", msg.EventId, msg.Message, seqC)
+	}
+	for i := 1; i < pubsub.EventLogSize+1; i++ {
+		receiveTx(strconv.Itoa(i))
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
@@ -19,6 +20,7 @@ import (
 	"github.com/decred/dcrd/txscript/v3"
 	"github.com/decred/dcrd/wire"
 
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	"github.com/decred/dcrdata/v6/blockdata"
 	"github.com/decred/dcrdata/v6/db/dbtypes"
 	exptypes "github.com/decred/dcrdata/v6/explorer/types"
@@ -29,7 +31,7 @@ import (
 	"golang.org/x/net/websocket"
 )
 
-var version = semver.NewSemver(3, 2, 0)
+var version = semver.NewSemver(3, 3, 0)
 
 // Version indicates the semantic version of the pubsub module.
 func Version() semver.Semver {
@@ -76,12 +78,17 @@ type State struct {
 type connection struct {
 	sync.WaitGroup
 	ws     *websocket.Conn
+	push   pushConn
 	client *clientHubSpoke
 }
 
 // PubSubHub manages the collection and distribution of block chain and mempool
 // data to WebSocket clients.
 type PubSubHub struct {
+	// wsConns and sseConns are the numbers of websocket and Server-Sent
+	// Events connections, accessed atomically. They are first for alignment.
+	wsConns    int64
+	sseConns   int64
 	sourceBase wsDataSource
 	wsHub      *WebsocketHub
 	state      *State
@@ -131,6 +138,11 @@ func NewPubSubHub(dataSource wsDataSource) (*PubSubHub, error) {
 	}
 
 	psh.wsHub = NewWebsocketHub()
+	// Start the event sequence at the current time in microseconds so that it
+	// keeps increasing across restarts, and a client resuming after a restart
+	// sees a gap instead of unrelated events.
+	psh.wsHub.events = newEventLog(EventLogSize, uint64(time.Now().UnixNano()/1e3),
+		psh.encodeLoggedEvent)
 	go psh.wsHub.Run()
 
 	return psh, nil
@@ -161,6 +173,12 @@ func (psh *PubSubHub) HubRelay() chan pstypes.HubMessage {
 	return psh.wsHub.HubRelay
 }
 
+// NumConnections returns the numbers of websocket and Server-Sent Events
+// connections.
+func (psh *PubSubHub) NumConnections() (websockets, sse int64) {
+	return atomic.LoadInt64(&psh.wsConns), atomic.LoadInt64(&psh.sseConns)
+}
+
 // MempoolInventory safely retrieves the current mempool inventory.
 func (psh *PubSubHub) MempoolInventory() *exptypes.MempoolInfo {
 	psh.invsMtx.RLock()
@@ -346,6 +364,38 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 			respMsg.Data = string(b)
 			respMsg.Success = true
 
+		case "resume":
+			seq, err := strconv.ParseUint(reqEvent, 10, 64)
+			if err != nil {
+				log.Debugf("Invalid resume sequence number: %.40s...", reqEvent)
+				respMsg.Data = "error: invalid sequence number"
+				break
+			}
+			if psh.wsHub.events == nil {
+				respMsg.Data = "error: events are not logged"
+				break
+			}
+
+			// Replay the missed events before the response.
+			var res pstypes.ResumeResult
+			res.Replayed, res.Complete, err = conn.push.replay(psh.wsHub.events, conn.client.cl, seq)
+			if err != nil {
+				log.Debugf("Failed to replay events: %v", err)
+				return
+			}
+			log.Debugf("Client resumed after %d, replayed %d events (complete = %v).",
+				seq, res.Replayed, res.Complete)
+
+			var b []byte
+			b, err = json.Marshal(res)
+			if err != nil {
+				log.Warn("Invalid JSON message: ", err)
+				respMsg.Data = "error: Could not encode JSON message"
+				break
+			}
+			respMsg.Data = string(b)
+			respMsg.Success = true
+
 		case "ping":
 			log.Tracef("We've been pinged!")
 			// No response to ping
@@ -383,25 +433,230 @@ func (psh *PubSubHub) receiveLoop(conn *connection) {
 	} // for {
 }
 
+// pushConn is the connection of a client that the send loop pushes events to.
+type pushConn interface {
+	// push sends the message of the event with the given event log ID, which
+	// is zero for events that are not logged. Logged events already replayed
+	// are not sent again.
+	push(msg *pstypes.WebSocketMessage, id uint64) error
+	// replay sends the logged events after the one with the given ID that the
+	// client is subscribed to. See (*eventLog).replay.
+	replay(l *eventLog, cl *client, id uint64) (n int, complete bool, err error)
+	// close closes the connection.
+	close()
+}
+
+// wsPushConn pushes events over a websocket connection. The ID of each logged
+// event is sent as the Seq of the message.
+type wsPushConn struct {
+	ws  *websocket.Conn
+	mtx sync.Mutex
+	// skip is the ID of the last event replayed.
+	skip uint64
+}
+
+func (c *wsPushConn) push(msg *pstypes.WebSocketMessage, id uint64) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if id != 0 && id <= c.skip {
+		return nil
+	}
+	return c.send(msg, id)
+}
+
+func (c *wsPushConn) send(msg *pstypes.WebSocketMessage, id uint64) error {
+	msg.Seq = id
+	err := c.ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err != nil && !pstypes.IsWSClosedErr(err) {
+		log.Warnf("SetWriteDeadline failed: %v", err)
+	}
+	return websocket.JSON.Send(c.ws, msg)
+}
+
+func (c *wsPushConn) replay(l *eventLog, cl *client, id uint64) (int, bool, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	last, n, complete, err := l.replay(cl, id, c.send)
+	if last > c.skip {
+		c.skip = last
+	}
+	return n, complete, err
+}
+
+func (c *wsPushConn) close() {
+	closeWS(c.ws)
+}
+
+// encodeEvent returns the message pushed to clients for the signal. The
+// transactions of a SigNewTxs signal are taken from the tx buffer of the
+// client, cl, and the ID of the event is that of the last transaction. A
+// SigNewTx signal, which is only encoded for the event log, is encoded as a
+// SigNewTxs message of the one transaction. ok is false if there is nothing to
+// send.
+func (psh *PubSubHub) encodeEvent(sig pstypes.HubMessage, cl *client) (msg json.RawMessage, id uint64, ok bool) {
+	buff := new(bytes.Buffer)
+	enc := json.NewEncoder(buff)
+	id = sig.ID
+
+	switch sig.Signal {
+	case sigAddressTx:
+		// sig was already validated, but do it again here in case the
+		// type changed without changing the type assertion here.
+		am, ok := sig.Msg.(*pstypes.AddressMessage)
+		if !ok {
+			log.Errorf("sigAddressTx did not store a *AddressMessage in Msg.")
+			return nil, 0, false
+		}
+		err := enc.Encode(am)
+		if err != nil {
+			log.Warnf("Encode(AddressMessage) failed: %v", err)
+		}
+
+	case sigNewBlock:
+		psh.state.mtx.RLock()
+		if psh.state.BlockInfo == nil {
+			psh.state.mtx.RUnlock()
+			return nil, id, true // send empty message
+		}
+		err := enc.Encode(exptypes.WebsocketBlock{
+			Block: psh.state.BlockInfo,
+			Extra: psh.state.GeneralInfo,
+		})
+		psh.state.mtx.RUnlock()
+		if err != nil {
+			log.Warnf("Encode(WebsocketBlock) failed: %v", err)
+		}
+
+	case sigMempoolUpdate:
+		// You probably want the sigNewTxs event. sigMempoolUpdate sends
+		// a summary of mempool contents, and the NumLatestMempoolTxns
+		// latest transactions.
+		inv := psh.MempoolInventory()
+		if inv == nil {
+			return nil, id, true // send empty message
+		}
+		inv.RLock()
+		err := enc.Encode(inv.MempoolShort)
+		inv.RUnlock()
+		if err != nil {
+			log.Warnf("Encode(MempoolShort) failed: %v", err)
+		}
+
+	case sigFeeEstimate:
+		fe, ok := sig.Msg.(*apitypes.FeeEstimates)
+		if !ok {
+			log.Errorf("sigFeeEstimate did not store a *FeeEstimates in Msg.")
+			return nil, 0, false
+		}
+		err := enc.Encode(fe)
+		if err != nil {
+			log.Warnf("Encode(FeeEstimates) failed: %v", err)
+		}
+
+	case sigDoubleSpend:
+		ds, ok := sig.Msg.(*pstypes.DoubleSpend)
+		if !ok {
+			log.Errorf("sigDoubleSpend did not store a *DoubleSpend in Msg.")
+			return nil, 0, false
+		}
+		err := enc.Encode(ds)
+		if err != nil {
+			log.Warnf("Encode(DoubleSpend) failed: %v", err)
+		}
+
+	case sigTicketSet:
+		tsu, ok := sig.Msg.(*pstypes.TicketSetUpdate)
+		if !ok {
+			log.Errorf("sigTicketSet did not store a *TicketSetUpdate in Msg.")
+			return nil, 0, false
+		}
+		err := enc.Encode(tsu)
+		if err != nil {
+			log.Warnf("Encode(TicketSetUpdate) failed: %v", err)
+		}
+
+	case sigTSpend:
+		ts, ok := sig.Msg.(*apitypes.TSpendProgress)
+		if !ok {
+			log.Errorf("sigTSpend did not store a *TSpendProgress in Msg.")
+			return nil, 0, false
+		}
+		err := enc.Encode(ts)
+		if err != nil {
+			log.Warnf("Encode(TSpendProgress) failed: %v", err)
+		}
+
+	case sigPingAndUserCount:
+		// ping and send user count
+		return json.RawMessage(strconv.Itoa(psh.wsHub.NumClients())), 0, true // No quotes as this is a JSON integer
+
+	case sigNewTx:
+		tx, ok := sig.Msg.(*exptypes.MempoolTx)
+		if !ok {
+			return nil, 0, false
+		}
+		err := enc.Encode([]*exptypes.MempoolTx{tx})
+		if err != nil {
+			log.Warnf("Encode([]*exptypes.MempoolTx) failed: %v", err)
+		}
+
+	case sigNewTxs:
+		// Marshal this client's tx buffer if it is not empty.
+		cl.newTxs.Lock()
+		if len(cl.newTxs.t) == 0 {
+			cl.newTxs.Unlock()
+			return nil, 0, false
+		}
+		err := enc.Encode(cl.newTxs.t)
+		id = cl.newTxs.lastID
+
+		// Reinit the tx buffer.
+		cl.newTxs.t = make(pstypes.TxList, 0, NewTxBufferSize)
+		cl.newTxs.Unlock()
+		if err != nil {
+			log.Warnf("Encode([]*exptypes.MempoolTx) failed: %v", err)
+		}
+
+	case sigByeNow:
+		return []byte(`"The dcrdata server is shutting down. Bye!"`), 0, true
+
+	// case sigSyncStatus:
+	// 	err := enc.Encode(explorer.SyncStatus())
+	// 	if err != nil {
+	// 		log.Warnf("Encode(SyncStatus()) failed: %v", err)
+	// 	}
+
+	default:
+		log.Errorf("Not sending a %v to the client.", sig)
+		return nil, 0, false
+	}
+
+	return buff.Bytes(), id, true
+}
+
+// encodeLoggedEvent returns the message of an event in the event log.
+func (psh *PubSubHub) encodeLoggedEvent(sig pstypes.HubMessage) (json.RawMessage, bool) {
+	msg, _, ok := psh.encodeEvent(sig, nil)
+	return msg, ok
+}
+
 // sendLoop receives signals from WebSocketHub via the connections unique signal
-// channel, and sends the relevant data to the client. sendLoop will return when
-// conn.client.c is closed. On return, the websocket connection, conn.ws, will
-// be closed, thus forcing the same connection's receiveLoop to return.
+// channel, and pushes the relevant data to the client. sendLoop will return
+// when conn.client.c is closed. On return, the client's connection, conn.push,
+// will be closed, thus forcing the same connection's receiveLoop to return.
 func (psh *PubSubHub) sendLoop(conn *connection) {
 	// Use this client's unique channel to receive signals from the
 	// WebSocketHub, which broadcasts signals to all clients.
 	updateSigChan := *conn.client.c
 	clientData := conn.client.cl
-	buff := new(bytes.Buffer)
 
 	// sendLoop should be started after conn.Add(1), and before a conn.Wait().
 	defer conn.Done()
 
 	// If returning because the WebSocketHub sent a quit signal, the receive
 	// loop may still be waiting for a message, so it is necessary to close the
-	// websocket.Conn in this case.
-	ws := conn.ws
-	defer closeWS(ws)
+	// connection in this case.
+	defer conn.push.close()
 
 loop:
 	for sig := range updateSigChan {
@@ -427,118 +682,29 @@ loop:
 
 		log.Tracef("signaling client %d with %s", clientData.id, sig)
 
-		// Respond to the websocket client.
+		msg, id, ok := psh.encodeEvent(sig, clientData)
+		if !ok {
+			continue loop
+		}
+		if sig.Signal == sigAddressTx {
+			log.Debugf("Sending sigAddressTx to client %d: %s", clientData.id, sig.Msg)
+		}
+
+		// Respond to the client.
 		pushMsg := pstypes.WebSocketMessage{
 			EventId: sig.Signal.String(),
-			// Message is set in switch statement below.
+			Message: msg,
 		}
 
-		// JSON encoder for the Message.
-		buff.Reset()
-		enc := json.NewEncoder(buff)
-
-		switch sig.Signal {
-		case sigAddressTx:
-			// sig was already validated, but do it again here in case the
-			// type changed without changing the type assertion here.
-			am, ok := sig.Msg.(*pstypes.AddressMessage)
-			if !ok {
-				log.Errorf("sigAddressTx did not store a *AddressMessage in Msg.")
-				continue loop
-			}
-			err := enc.Encode(am)
-			if err != nil {
-				log.Warnf("Encode(AddressMessage) failed: %v", err)
-			}
-
-			log.Debugf("Sending sigAddressTx to client %d: %s", clientData.id, am)
-
-			pushMsg.Message = buff.Bytes()
-		case sigNewBlock:
-			psh.state.mtx.RLock()
-			if psh.state.BlockInfo == nil {
-				psh.state.mtx.RUnlock()
-				break // from switch to send empty message
-			}
-			err := enc.Encode(exptypes.WebsocketBlock{
-				Block: psh.state.BlockInfo,
-				Extra: psh.state.GeneralInfo,
-			})
-			psh.state.mtx.RUnlock()
-			if err != nil {
-				log.Warnf("Encode(WebsocketBlock) failed: %v", err)
-			}
-
-			pushMsg.Message = buff.Bytes()
-
-		case sigMempoolUpdate:
-			// You probably want the sigNewTxs event. sigMempoolUpdate sends
-			// a summary of mempool contents, and the NumLatestMempoolTxns
-			// latest transactions.
-			inv := psh.MempoolInventory()
-			if inv == nil {
-				break // from switch to send empty message
-			}
-			inv.RLock()
-			err := enc.Encode(inv.MempoolShort)
-			inv.RUnlock()
-			if err != nil {
-				log.Warnf("Encode(MempoolShort) failed: %v", err)
-			}
-
-			pushMsg.Message = buff.Bytes()
-
-		case sigPingAndUserCount:
-			// ping and send user count
-			pushMsg.Message = json.RawMessage(strconv.Itoa(psh.wsHub.NumClients())) // No quotes as this is a JSON integer
-
-		case sigNewTxs:
-			// Marshal this client's tx buffer if it is not empty.
-			clientData.newTxs.Lock()
-			if len(clientData.newTxs.t) == 0 {
-				clientData.newTxs.Unlock()
-				continue loop // break sigselect
-			}
-			err := enc.Encode(clientData.newTxs.t)
-
-			// Reinit the tx buffer.
-			clientData.newTxs.t = make(pstypes.TxList, 0, NewTxBufferSize)
-			clientData.newTxs.Unlock()
-			if err != nil {
-				log.Warnf("Encode([]*exptypes.MempoolTx) failed: %v", err)
-			}
-
-			pushMsg.Message = buff.Bytes()
-
-		case sigByeNow:
-			pushMsg.Message = []byte(`"The dcrdata server is shutting down. Bye!"`)
-			log.Tracef("Sending %v", string(pushMsg.Message))
-
-		// case sigSyncStatus:
-		// 	err := enc.Encode(explorer.SyncStatus())
-		// 	if err != nil {
-		// 		log.Warnf("Encode(SyncStatus()) failed: %v", err)
-		// 	}
-		// 	pushMsg.Message = buff.String()
-
-		default:
-			log.Errorf("Not sending a %v to the client.", sig)
-			continue loop // break sigselect
-		} // switch sig
-
 		// Send the message.
-		err := ws.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
-		if err != nil && !pstypes.IsWSClosedErr(err) {
-			log.Warnf("SetWriteDeadline failed: %v", err)
-		}
-		if err = websocket.JSON.Send(ws, pushMsg); err != nil {
+		if err := conn.push.push(&pushMsg, id); err != nil {
 			// Do not log the error if the connection is just closed.
 			if !pstypes.IsWSClosedErr(err) {
 				log.Debugf("Failed to encode WebSocketMessage (push) %v: %v", sig, err)
 			}
 			// If the send failed, the client is probably gone, quit the
 			// send loop, unregistering the client from the websocket hub.
-			log.Errorf("websocket.JSON.Send of %v type message failed: %v", sig, err)
+			log.Errorf("Push of %v type message failed: %v", sig, err)
 			return
 		}
 	} // for range { a.k.a. loop:
@@ -556,6 +722,9 @@ func (psh *PubSubHub) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 		// Set the max payload size for this connection.
 		ws.MaxPayloadBytes = psh.wsHub.requestLimit
 
+		atomic.AddInt64(&psh.wsConns, 1)
+		defer atomic.AddInt64(&psh.wsConns, -1)
+
 		// The receive loop will be sitting on websocket.JSON.Receive, while the
 		// send loop will be waiting for signals from the WebSocketHub. One must
 		// close the other depending on whether the connection was closed/lost,
@@ -568,6 +737,7 @@ func (psh *PubSubHub) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 		conn := &connection{
 			client: ch,
 			ws:     ws,
+			push:   &wsPushConn{ws: ws},
 		}
 
 		// Start listening for websocket messages from client, returning when
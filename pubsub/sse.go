@@ -0,0 +1,275 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+// ssePushConn pushes events to a Server-Sent Events stream. The stream is
+// written to a hijacked connection when the ResponseWriter supports it, so that
+// the server's write timeout does not end the stream, and otherwise to the
+// ResponseWriter, flushing after each event.
+type ssePushConn struct {
+	w     *bufio.Writer
+	flush func() error
+	// conn is the hijacked connection, if any.
+	conn net.Conn
+	mtx  sync.Mutex
+	// skip is the ID of the last event replayed.
+	skip uint64
+	// gone is closed when the client disconnects.
+	gone <-chan struct{}
+}
+
+// startSSE begins the event stream response.
+func startSSE(w http.ResponseWriter, r *http.Request) (*ssePushConn, error) {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// Ask nginx not to buffer the stream.
+	h.Set("X-Accel-Buffering", "no")
+
+	if hj, ok := w.(http.Hijacker); ok && r.ProtoMajor == 1 {
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			return nil, err
+		}
+		// Clear the deadlines set by the server.
+		if err = conn.SetDeadline(time.Time{}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		h.Set("Connection", "close")
+		fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", http.StatusOK, http.StatusText(http.StatusOK))
+		h.Write(rw)
+		rw.WriteString("\r\n")
+		if err = rw.Flush(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		// The client sends nothing more, so a read returns when it is gone.
+		gone := make(chan struct{})
+		go func() {
+			io.Copy(ioutil.Discard, rw.Reader)
+			close(gone)
+		}()
+
+		return &ssePushConn{
+			w:     rw.Writer,
+			flush: rw.Writer.Flush,
+			conn:  conn,
+			gone:  gone,
+		}, nil
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming not supported")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	bw := bufio.NewWriter(w)
+	return &ssePushConn{
+		w: bw,
+		flush: func() error {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+			return nil
+		},
+		gone: r.Context().Done(),
+	}, nil
+}
+
+// writeComment writes a comment line, which clients ignore.
+func (c *ssePushConn) writeComment(comment string) error {
+	fmt.Fprintf(c.w, ": %s\n\n", comment)
+	return c.flush()
+}
+
+func (c *ssePushConn) push(msg *pstypes.WebSocketMessage, id uint64) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if id != 0 && id <= c.skip {
+		return nil
+	}
+	return c.send(msg, id)
+}
+
+func (c *ssePushConn) send(msg *pstypes.WebSocketMessage, id uint64) error {
+	if c.conn != nil {
+		err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err != nil {
+			log.Warnf("SetWriteDeadline failed: %v", err)
+		}
+	}
+	if id != 0 {
+		fmt.Fprintf(c.w, "id: %d\n", id)
+	}
+	fmt.Fprintf(c.w, "event: %s\n", msg.EventId)
+	data := bytes.TrimRight(msg.Message, "\n")
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fmt.Fprintf(c.w, "data: %s\n", line)
+	}
+	c.w.WriteString("\n")
+	return c.flush()
+}
+
+func (c *ssePushConn) replay(l *eventLog, cl *client, id uint64) (int, bool, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	last, n, complete, err := l.replay(cl, id, c.send)
+	if last > c.skip {
+		c.skip = last
+	}
+	return n, complete, err
+}
+
+func (c *ssePushConn) close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// SSEHandler is the http.HandlerFunc for Server-Sent Events streams of pubsub
+// events, an alternative to the websocket connections of WebSocketHandler for
+// clients that cannot use websockets. The events are given by the sub query
+// parameter, a comma-separated list of subscriptions such as
+// "newblock,mempool,address:Dsxx". Each event has the name of its signal, and
+// the same JSON data as the message of a websocket event. The ping event with
+// the number of connected clients is always sent. Subscription events have an
+// ID, and a client reconnecting with the Last-Event-ID header (or the
+// lastEventId query parameter) first receives the events it missed that are
+// still in the event log.
+func (psh *PubSubHub) SSEHandler(w http.ResponseWriter, r *http.Request) {
+	// Subscribe the client before registering it so that no event is missed
+	// between registration and subscription.
+	cl := newClient()
+	for _, sub := range strings.Split(r.URL.Query().Get("sub"), ",") {
+		if sub == "" {
+			continue
+		}
+		sig, sigMsg, valid := pstypes.ValidateSubscription(sub)
+		if !valid {
+			http.Error(w, "invalid subscription "+sub, http.StatusBadRequest)
+			return
+		}
+		if _, err := cl.subscribe(pstypes.HubMessage{Signal: sig, Msg: sigMsg}); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid last event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	select {
+	case <-psh.wsHub.killed:
+		http.Error(w, "The pubsub hub is stopped.", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	sc, err := startSSE(w, r)
+	if err != nil {
+		log.Errorf("Failed to start event stream: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer sc.close()
+
+	atomic.AddInt64(&psh.sseConns, 1)
+	defer atomic.AddInt64(&psh.sseConns, -1)
+
+	// Register the client with the WebSocketHub. The hub waits on killed when
+	// unregistering the client.
+	c := make(hubSpoke, 16)
+	ch := &clientHubSpoke{
+		cl: cl,
+		c:  &c,
+	}
+	select {
+	case psh.wsHub.Register <- ch:
+	case <-psh.wsHub.killed:
+		return
+	}
+	defer close(cl.killed)
+	defer cl.unsubscribeAll()
+
+	unregister := func() {
+		select {
+		case psh.wsHub.Unregister <- ch.c:
+		case <-psh.wsHub.killed:
+		}
+	}
+
+	// Replay the logged events after the last one the client received. Live
+	// events already replayed are skipped.
+	if lastEventID != "" && psh.wsHub.events != nil {
+		var complete bool
+		_, complete, err = sc.replay(psh.wsHub.events, cl, lastID)
+		if err == nil && !complete {
+			err = sc.writeComment(fmt.Sprintf("some events after %d are no longer available", lastID))
+		}
+		if err != nil {
+			log.Debugf("Failed to replay events: %v", err)
+			unregister()
+			return
+		}
+	}
+
+	// Push events until the client is gone or the hub unregisters it.
+	conn := &connection{
+		client: ch,
+		push:   sc,
+	}
+	sendDone := make(chan struct{})
+	conn.Add(1)
+	go func() {
+		psh.sendLoop(conn)
+		close(sendDone)
+	}()
+
+	select {
+	case <-sendDone:
+	case <-sc.gone:
+		// sendLoop returns when the hub closes the client's spoke.
+		select {
+		case psh.wsHub.Unregister <- ch.c:
+		case <-sendDone:
+		}
+		<-sendDone
+	}
+}
@@ -0,0 +1,130 @@
+package pubsub
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+type sseEvent struct {
+	id, event, data string
+}
+
+// readSSEEvent reads the next event of the stream, skipping comments.
+func readSSEEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if e.event != "" {
+				return e
+			}
+		case strings.HasPrefix(line, "id: "):
+			e.id = line[4:]
+		case strings.HasPrefix(line, "event: "):
+			e.event = line[7:]
+		case strings.HasPrefix(line, "data: "):
+			e.data += line[6:]
+		}
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	const addr = "DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC"
+
+	psh := &PubSubHub{
+		wsHub: NewWebsocketHub(),
+		state: &State{
+			BlockInfo: &exptypes.BlockInfo{},
+		},
+	}
+	psh.wsHub.events = newEventLog(EventLogSize, 0, psh.encodeLoggedEvent)
+	go psh.wsHub.Run()
+	defer psh.wsHub.Stop()
+
+	srv := httptest.NewServer(http.HandlerFunc(psh.SSEHandler))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?sub=newblock,foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid subscription status %d", resp.StatusCode)
+	}
+
+	connect := func(lastEventID string) (*bufio.Reader, func()) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"?sub=newblock,address:"+addr, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("unexpected Content-Type %q", ct)
+		}
+		return bufio.NewReader(resp.Body), func() { resp.Body.Close() }
+	}
+
+	waitClients := func(n int) {
+		t.Helper()
+		for i := 0; psh.wsHub.NumClients() != n; i++ {
+			if i == 100 {
+				t.Fatalf("%d clients, expected %d", psh.wsHub.NumClients(), n)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	r, disconnect := connect("")
+	waitClients(1)
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigNewBlock}
+	e := readSSEEvent(t, r)
+	if e.id != "1" || e.event != "newblock" || !strings.HasPrefix(e.data, "{") {
+		t.Errorf("unexpected new block event %+v", e)
+	}
+	disconnect()
+	waitClients(0)
+
+	// Events signaled while disconnected are replayed on reconnect.
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigAddressTx,
+		Msg: &pstypes.AddressMessage{Address: "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu", TxHash: "a"}}
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigAddressTx,
+		Msg: &pstypes.AddressMessage{Address: addr, TxHash: "b"}}
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigNewBlock}
+
+	r, disconnect = connect("1")
+	defer disconnect()
+	e = readSSEEvent(t, r)
+	if e.id != "3" || e.event != "address" || !strings.Contains(e.data, `"b"`) {
+		t.Errorf("unexpected address event %+v", e)
+	}
+	e = readSSEEvent(t, r)
+	if e.id != "4" || e.event != "newblock" {
+		t.Errorf("unexpected new block event %+v", e)
+	}
+
+	// Then the live events.
+	waitClients(1)
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigNewBlock}
+	e = readSSEEvent(t, r)
+	if e.id != "5" || e.event != "newblock" {
+		t.Errorf("unexpected new block event %+v", e)
+	}
+}
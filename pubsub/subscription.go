@@ -0,0 +1,124 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package pubsub
+
+import (
+	"errors"
+	"sync"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+// Subscription is an in-process subscription to the events signaled by the
+// WebsocketHub. It is registered with the hub like a websocket client, but the
+// events are delivered on a channel rather than over a connection.
+type Subscription struct {
+	// C receives the events. Unlike the signals sent to websocket clients, the
+	// Msg of each event carries its data: a []*exptypes.MempoolTx for
+	// SigNewTxs, a *exptypes.WebsocketBlock for SigNewBlock, a
+	// *pstypes.AddressMessage for SigAddressTx, and a *pstypes.TicketSetUpdate
+	// for SigTicketSet. C is closed when the subscription is closed or the hub
+	// stops.
+	C <-chan pstypes.HubMessage
+
+	psh      *PubSubHub
+	ch       *clientHubSpoke
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+// Subscribe registers a new in-process subscription to the given events with
+// the WebsocketHub. For SigAddressTx, Msg must be a *pstypes.AddressMessage
+// with the Address to watch, and for SigTicketSet, a *pstypes.TicketSetUpdate
+// with the SetID of the ticket set to watch. Use Close to unregister the
+// subscription.
+func (psh *PubSubHub) Subscribe(msgs ...pstypes.HubMessage) (*Subscription, error) {
+	// Subscribe the client before registering it so that no event is missed
+	// between registration and subscription.
+	cl := newClient()
+	for _, msg := range msgs {
+		if _, err := cl.subscribe(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	c := make(hubSpoke, 16)
+	ch := &clientHubSpoke{
+		cl: cl,
+		c:  &c,
+	}
+	select {
+	case psh.wsHub.Register <- ch:
+	case <-psh.wsHub.killed:
+		return nil, errors.New("the websocket hub is stopped")
+	}
+
+	out := make(chan pstypes.HubMessage, 16)
+	sub := &Subscription{
+		C:    out,
+		psh:  psh,
+		ch:   ch,
+		quit: make(chan struct{}),
+	}
+	go sub.relay(out)
+	return sub, nil
+}
+
+// relay fills in the data of each event signaled on the client's hub spoke and
+// sends it on out. relay returns when the hub closes the spoke.
+func (sub *Subscription) relay(out chan<- pstypes.HubMessage) {
+	// The hub waits on killed when unregistering the client.
+	defer close(sub.ch.cl.killed)
+	defer close(out)
+
+	clientData := sub.ch.cl
+	for sig := range *sub.ch.c {
+		switch sig.Signal {
+		case sigByeNow, sigPingAndUserCount:
+			continue
+		case sigNewTxs:
+			clientData.newTxs.Lock()
+			txs := []*exptypes.MempoolTx(clientData.newTxs.t)
+			clientData.newTxs.t = make(pstypes.TxList, 0, NewTxBufferSize)
+			clientData.newTxs.Unlock()
+			if len(txs) == 0 {
+				continue
+			}
+			sig.Msg = txs
+		case sigNewBlock:
+			sub.psh.state.mtx.RLock()
+			if sub.psh.state.BlockInfo == nil {
+				sub.psh.state.mtx.RUnlock()
+				continue
+			}
+			sig.Msg = &exptypes.WebsocketBlock{
+				Block: sub.psh.state.BlockInfo,
+				Extra: sub.psh.state.GeneralInfo,
+			}
+			sub.psh.state.mtx.RUnlock()
+		}
+
+		select {
+		case out <- sig:
+		case <-sub.quit:
+			// The subscriber is gone. Keep reading until the hub closes the
+			// spoke.
+		}
+	}
+}
+
+// Close unregisters the subscription from the WebsocketHub. C is closed once
+// the hub has unregistered the subscription. Close may be called more than
+// once.
+func (sub *Subscription) Close() {
+	sub.quitOnce.Do(func() {
+		close(sub.quit)
+		select {
+		case sub.psh.wsHub.Unregister <- sub.ch.c:
+		case <-sub.psh.wsHub.killed:
+			// The hub has stopped and unregistered all clients.
+		}
+	})
+}
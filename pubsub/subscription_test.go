@@ -0,0 +1,76 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+
+	exptypes "github.com/decred/dcrdata/v6/explorer/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+func TestSubscription(t *testing.T) {
+	const addr = "DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC"
+
+	psh := &PubSubHub{
+		wsHub: NewWebsocketHub(),
+		state: &State{
+			BlockInfo: new(exptypes.BlockInfo),
+		},
+	}
+	go psh.wsHub.Run()
+	defer psh.wsHub.Stop()
+
+	sub, err := psh.Subscribe(
+		pstypes.HubMessage{Signal: sigNewBlock},
+		pstypes.HubMessage{Signal: sigNewTxs},
+		pstypes.HubMessage{Signal: sigAddressTx, Msg: &pstypes.AddressMessage{Address: addr}},
+	)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	receive := func() pstypes.HubMessage {
+		t.Helper()
+		select {
+		case msg := <-sub.C:
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+		}
+		return pstypes.HubMessage{}
+	}
+
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigNewBlock}
+	msg := receive()
+	if wb, ok := msg.Msg.(*exptypes.WebsocketBlock); msg.Signal != sigNewBlock || !ok || wb.Block == nil {
+		t.Errorf("unexpected new block event %v (%T)", msg.Signal, msg.Msg)
+	}
+
+	// Not a watched address.
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigAddressTx,
+		Msg: &pstypes.AddressMessage{Address: "DsUZxxoHJSty8DCfwfartwTYbuhmVct7tJu", TxHash: "a"}}
+	psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigAddressTx,
+		Msg: &pstypes.AddressMessage{Address: addr, TxHash: "b"}}
+	msg = receive()
+	if am, ok := msg.Msg.(*pstypes.AddressMessage); !ok || am.TxHash != "b" {
+		t.Errorf("unexpected address event %v", msg)
+	}
+
+	// A full tx buffer is sent immediately.
+	for i := 0; i < NewTxBufferSize; i++ {
+		psh.wsHub.HubRelay <- pstypes.HubMessage{Signal: sigNewTx,
+			Msg: &exptypes.MempoolTx{Hash: "tx"}}
+	}
+	msg = receive()
+	if txs, ok := msg.Msg.([]*exptypes.MempoolTx); msg.Signal != sigNewTxs || !ok || len(txs) != NewTxBufferSize {
+		t.Errorf("unexpected new txs event %v", msg)
+	}
+
+	sub.Close()
+	sub.Close()
+	for range sub.C {
+	}
+	if n := psh.wsHub.NumClients(); n != 0 {
+		t.Errorf("expected no clients after Close, got %d", n)
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/decred/base58"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	exptypes "github.com/decred/dcrdata/v6/explorer/types"
 )
 
@@ -59,6 +61,11 @@ func IsTemporaryErr(err error) bool {
 type WebSocketMessage struct {
 	EventId string          `json:"event"`
 	Message json.RawMessage `json:"message"`
+	// Seq is the sequence number of a subscription event sent by the server.
+	// Sequence numbers increase with each event signaled by the server, so
+	// the events a client receives are not numbered consecutively. Seq is
+	// omitted from other messages.
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 type AddressMessage struct {
@@ -66,6 +73,56 @@ type AddressMessage struct {
 	TxHash  string `json:"transaction"`
 }
 
+// DoubleSpend describes two transactions spending the same previous outpoint.
+// TxID is the mempool transaction seen first, and ConflictTxID is the competing
+// spend, which is either another mempool transaction, or a transaction mined
+// in the block at BlockHeight if Mined is true. Addresses are the addresses
+// paid by the outputs of the double spent transaction, TxID.
+type DoubleSpend struct {
+	Outpoint     string   `json:"outpoint"`
+	TxID         string   `json:"txid"`
+	ConflictTxID string   `json:"conflict_txid"`
+	Mined        bool     `json:"mined"`
+	BlockHash    string   `json:"block_hash,omitempty"`
+	BlockHeight  int64    `json:"block_height,omitempty"`
+	Addresses    []string `json:"addresses"`
+	Time         int64    `json:"time"`
+}
+
+// TicketSetUpdate is the status transitions of the tickets of a registered
+// ticket set in a block connected to, or disconnected from, the main chain.
+// The transitions of a disconnected block undo those of the block when it was
+// connected. In a subscription to the events of a ticket set, only SetID is
+// set.
+type TicketSetUpdate struct {
+	SetID        string             `json:"set_id"`
+	BlockHash    string             `json:"block_hash,omitempty"`
+	BlockHeight  int64              `json:"block_height,omitempty"`
+	Disconnected bool               `json:"disconnected,omitempty"`
+	Transitions  []TicketTransition `json:"transitions,omitempty"`
+}
+
+// TicketTransition is a change in the status of a ticket. The statuses are
+// those of the ticket info of the API: unmined, immature, live, voted, missed,
+// expired and revoked.
+type TicketTransition struct {
+	Ticket string `json:"ticket"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// ticketSetIDLen is the length of the hexadecimal ID of a ticket set.
+const ticketSetIDLen = 32
+
+// ValidTicketSetID checks if the string has the format of a ticket set ID.
+func ValidTicketSetID(id string) bool {
+	if len(id) != ticketSetIDLen {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
 type RequestMessage struct {
 	RequestId int64  `json:"request_id"`
 	Message   string `json:"message"`
@@ -78,6 +135,16 @@ type ResponseMessage struct {
 	Data           string `json:"data"`
 }
 
+// ResumeResult is the Data of the response to a resume request, which
+// replays the subscription events after the sequence number in the request.
+type ResumeResult struct {
+	// Replayed is the number of events replayed before the response.
+	Replayed int `json:"replayed"`
+	// Complete is false if some of the events after the sequence number are
+	// no longer in the server's replay buffer, or the server restarted.
+	Complete bool `json:"complete"`
+}
+
 func (am AddressMessage) String() string {
 	return am.Address + ":" + am.TxHash
 }
@@ -106,6 +173,10 @@ const (
 	SigSyncStatus
 	SigByeNow
 	SigUnknown
+	SigFeeEstimate
+	SigDoubleSpend
+	SigTicketSet
+	SigTSpend
 )
 
 var Subscriptions = map[string]HubSignal{
@@ -115,6 +186,10 @@ var Subscriptions = map[string]HubSignal{
 	"newtxs":         SigNewTxs,
 	"address":        SigAddressTx,
 	"blockchainSync": SigSyncStatus,
+	"feeestimate":    SigFeeEstimate,
+	"doublespend":    SigDoubleSpend,
+	"tickets":        SigTicketSet,
+	"tspend":         SigTSpend,
 }
 
 // Event type field for an event.
@@ -134,6 +209,10 @@ var eventIDs = map[HubSignal]string{
 	SigSyncStatus:       "blockchainSync",
 	SigByeNow:           "bye",
 	SigUnknown:          "unknown",
+	SigFeeEstimate:      "feeestimate",
+	SigDoubleSpend:      "doublespend",
+	SigTicketSet:        "tickets",
+	SigTSpend:           "tspend",
 }
 
 func ValidateSubscription(event string) (sub HubSignal, msg interface{}, valid bool) {
@@ -160,6 +239,13 @@ func ValidateSubscription(event string) (sub HubSignal, msg interface{}, valid b
 		msg = &AddressMessage{
 			Address: msgStr,
 		}
+	case SigTicketSet:
+		if !ValidTicketSetID(msgStr) {
+			return SigUnknown, nil, false
+		}
+		msg = &TicketSetUpdate{
+			SetID: msgStr,
+		}
 	default:
 		// Other signals do not have a message.
 		if msgStr != "" {
@@ -186,6 +272,9 @@ func (s HubSignal) IsValid() bool {
 type HubMessage struct {
 	Signal HubSignal
 	Msg    interface{}
+	// ID is the sequence number of the event in the pubsub event log, or zero
+	// if the event is not logged.
+	ID uint64
 }
 
 func (m HubMessage) IsValid() bool {
@@ -202,6 +291,14 @@ func (m HubMessage) IsValid() bool {
 		_, ok = m.Msg.(*exptypes.MempoolTx)
 	case SigNewTxs:
 		_, ok = m.Msg.([]*exptypes.MempoolTx)
+	case SigFeeEstimate:
+		_, ok = m.Msg.(*apitypes.FeeEstimates)
+	case SigDoubleSpend:
+		_, ok = m.Msg.(*DoubleSpend)
+	case SigTicketSet:
+		_, ok = m.Msg.(*TicketSetUpdate)
+	case SigTSpend:
+		_, ok = m.Msg.(*apitypes.TSpendProgress)
 	}
 
 	return ok
@@ -224,6 +321,15 @@ func (m HubMessage) String() string {
 	case SigNewTxs:
 		txs := m.Msg.([]*exptypes.MempoolTx)
 		sigStr += ":len=" + strconv.Itoa(len(txs))
+	case SigDoubleSpend:
+		ds := m.Msg.(*DoubleSpend)
+		sigStr += ":" + ds.TxID + ":" + ds.ConflictTxID
+	case SigTicketSet:
+		tsu := m.Msg.(*TicketSetUpdate)
+		sigStr += ":" + tsu.SetID + ":len=" + strconv.Itoa(len(tsu.Transitions))
+	case SigTSpend:
+		ts := m.Msg.(*apitypes.TSpendProgress)
+		sigStr += ":" + ts.TxID + ":" + ts.Status
 	}
 
 	return sigStr
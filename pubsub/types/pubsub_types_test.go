@@ -3,6 +3,7 @@ package types
 import (
 	"testing"
 
+	apitypes "github.com/decred/dcrdata/v6/api/types"
 	exptypes "github.com/decred/dcrdata/v6/explorer/types"
 )
 
@@ -56,6 +57,22 @@ func TestHubMessage_String(t *testing.T) {
 			HubMessage{Signal: SigNewTxs, Msg: []*exptypes.MempoolTx{{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}}},
 			"newtxs:len=1",
 		},
+		{
+			"ok tickets",
+			HubMessage{Signal: SigTicketSet, Msg: &TicketSetUpdate{
+				SetID:       "0123456789abcdef0123456789abcdef",
+				Transitions: []TicketTransition{{Ticket: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}},
+			}},
+			"tickets:0123456789abcdef0123456789abcdef:len=1",
+		},
+		{
+			"ok tspend",
+			HubMessage{Signal: SigTSpend, Msg: &apitypes.TSpendProgress{
+				TxID:   "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7",
+				Status: apitypes.TSpendVoting,
+			}},
+			"tspend:4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7:voting",
+		},
 		{
 			"wrong Msg type newtx",
 			HubMessage{Signal: SigNewTx, Msg: exptypes.MempoolTx{Hash: "4811246cb13f6e74c8c661242064664aba79e0baaae273c320b884cf461b28d7"}},
@@ -0,0 +1,470 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+// Package webhook provides durable delivery of address watch events to
+// registered callback URLs. Events are stored with their delivery state, and
+// delivery is retried with exponential backoff until it succeeds or the
+// maximum number of attempts is reached.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/decred/dcrd/wire"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/blockdata"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+const (
+	// SignatureHeader is the HTTP header with the hex-encoded HMAC-SHA256 of
+	// the request body, keyed by the webhook secret, prefixed with "sha256=".
+	SignatureHeader = "X-Dcrdata-Signature"
+	// EventHeader is the HTTP header with the event type of a delivery.
+	EventHeader = "X-Dcrdata-Event"
+	// DeliveryHeader is the HTTP header with the ID of a delivery.
+	DeliveryHeader = "X-Dcrdata-Delivery"
+
+	// MaxAttempts is the number of attempts after which a delivery that has
+	// not succeeded is marked as failed. It may still be replayed.
+	MaxAttempts = 16
+	// MaxAddresses is the maximum number of addresses watched by a webhook.
+	MaxAddresses = 1000
+	// MaxConfirmations is the maximum number of confirmations a webhook may
+	// request to be notified of.
+	MaxConfirmations = 256
+	// DefaultConfirmations is the number of confirmations used when a
+	// registration does not specify one.
+	DefaultConfirmations = 6
+
+	retryBaseDelay  = 30 * time.Second
+	retryMaxDelay   = 6 * time.Hour
+	pollInterval    = 15 * time.Second
+	deliveryTimeout = 10 * time.Second
+	batchSize       = 100
+	hubRelayBuffer  = 1024
+)
+
+// ErrPrivateAddress is the error of a webhook URL, or a connection to its
+// host, with a loopback, private, link-local or otherwise non-public address.
+var ErrPrivateAddress = errors.New("webhook host is not a public address")
+
+// privateNets are the networks of the addresses that webhooks may not be
+// delivered to. IPv4-mapped IPv6 addresses are matched by the IPv4 networks.
+var privateNets = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",       // this network
+		"10.0.0.0/8",      // private
+		"100.64.0.0/10",   // carrier-grade NAT
+		"127.0.0.0/8",     // loopback
+		"169.254.0.0/16",  // link-local, including cloud metadata services
+		"172.16.0.0/12",   // private
+		"192.0.0.0/24",    // IETF protocol assignments
+		"192.0.2.0/24",    // documentation
+		"192.168.0.0/16",  // private
+		"198.18.0.0/15",   // benchmarking
+		"198.51.100.0/24", // documentation
+		"203.0.113.0/24",  // documentation
+		"224.0.0.0/4",     // multicast
+		"240.0.0.0/4",     // reserved and broadcast
+		"::/128",          // unspecified
+		"::1/128",         // loopback
+		"64:ff9b::/96",    // NAT64, which may map to private IPv4 addresses
+		"2001:db8::/32",   // documentation
+		"fc00::/7",        // unique local
+		"fe80::/10",       // link-local
+		"ff00::/8",        // multicast
+	}
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPublicIP checks that the IP address is not in a loopback, private,
+// link-local or otherwise non-public network.
+func isPublicIP(ip net.IP) bool {
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPublicHost rejects the host of a webhook URL if it is a non-public IP
+// address or a name of the local host. Other names are checked when they are
+// resolved for each connection.
+func checkPublicHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicDialControl is the net.Dialer Control function of the dispatcher. It
+// refuses connections to non-public addresses. Since it checks the address of
+// each connection after the host name is resolved, a name that resolves to a
+// private address, even if it did not when the webhook was registered, cannot
+// be used to reach the local network.
+func publicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// Store is the persistent storage of the webhook deliveries. It is satisfied
+// by *dcrpg.ChainDB.
+type Store interface {
+	InsertWebhookMempoolDeliveries(address, txHash string) (int64, error)
+	InsertWebhookBlockDeliveries(blockHash string, height int64) (int64, error)
+	PendingWebhookDeliveries(limit int) ([]*dbtypes.WebhookDelivery, error)
+	UpdateWebhookDelivery(d *dbtypes.WebhookDelivery) error
+}
+
+// NewWebhook validates a registration request, and creates a webhook with a
+// random ID and secret. The URL must not have a loopback, private or
+// link-local host. The addresses should be validated by the caller.
+func NewWebhook(req *apitypes.WebhookRequest) (*dbtypes.Webhook, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q", req.URL)
+	}
+	if err = checkPublicHost(u.Hostname()); err != nil {
+		return nil, err
+	}
+	if len(req.Addresses) == 0 || len(req.Addresses) > MaxAddresses {
+		return nil, fmt.Errorf("between 1 and %d addresses are required", MaxAddresses)
+	}
+	confs := req.Confirmations
+	if confs == 0 {
+		confs = DefaultConfirmations
+	}
+	if confs < 1 || confs > MaxConfirmations {
+		return nil, fmt.Errorf("confirmations must be between 1 and %d", MaxConfirmations)
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dbtypes.Webhook{
+		ID:            id,
+		URL:           u.String(),
+		Secret:        secret,
+		Addresses:     req.Addresses,
+		Confirmations: confs,
+		Created:       dbtypes.NewTimeDef(time.Now()),
+	}, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Signature computes the value of the SignatureHeader for a request body
+// signed with the webhook secret.
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the value of the SignatureHeader of a delivery
+// request. Receivers may use this to authenticate deliveries.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Signature(secret, body)), []byte(signature))
+}
+
+// RetryDelay is the delay before the next attempt of a delivery that has
+// failed the given number of attempts. The delay doubles with each attempt.
+func RetryDelay(attempts int32) time.Duration {
+	delay := retryBaseDelay
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Dispatcher creates webhook deliveries from mempool and block events, and
+// sends the pending deliveries stored in the Store. Since deliveries are only
+// marked as delivered after a successful response, pending deliveries are sent
+// after a restart. Reorg deliveries are created by the Store itself.
+type Dispatcher struct {
+	store    Store
+	client   *http.Client
+	hubRelay chan pstypes.HubMessage
+	wake     chan struct{}
+}
+
+// NewDispatcher creates a new Dispatcher. Run must be called to process the
+// events and send the deliveries. The deliveries are only sent to public
+// addresses.
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store: store,
+		client: &http.Client{
+			Timeout: deliveryTimeout,
+			// Only connect to public addresses, without a proxy, which
+			// would otherwise connect on behalf of the dispatcher.
+			Transport: &http.Transport{
+				DialContext: (&net.Dialer{
+					Timeout:   deliveryTimeout,
+					KeepAlive: 30 * time.Second,
+					Control:   publicDialControl,
+				}).DialContext,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   deliveryTimeout,
+				ExpectContinueTimeout: time.Second,
+			},
+			// Do not follow redirects to other hosts.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		hubRelay: make(chan pstypes.HubMessage, hubRelayBuffer),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// HubRelay returns the channel on which the mempool monitor signals new
+// address transactions (pstypes.SigAddressTx). Other signals are ignored.
+func (d *Dispatcher) HubRelay() chan<- pstypes.HubMessage {
+	return d.hubRelay
+}
+
+// Store creates the deliveries for a new main chain block. Store satisfies
+// blockdata.BlockDataSaver, and it must be run after the block is stored in
+// the DB.
+func (d *Dispatcher) Store(_ *blockdata.BlockData, msgBlock *wire.MsgBlock) error {
+	hash := msgBlock.BlockHash().String()
+	n, err := d.store.InsertWebhookBlockDeliveries(hash, int64(msgBlock.Header.Height))
+	if err != nil {
+		return fmt.Errorf("InsertWebhookBlockDeliveries: %w", err)
+	}
+	if n > 0 {
+		log.Debugf("Created %d webhook deliveries for block %s.", n, hash)
+		d.signal()
+	}
+	return nil
+}
+
+// signal wakes the delivery loop without blocking.
+func (d *Dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run processes the address transaction signals and sends the pending
+// deliveries until the context is canceled. It should be launched as a
+// goroutine.
+func (d *Dispatcher) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Send the deliveries left pending from a previous run.
+	d.signal()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debugf("Webhook dispatcher stopped.")
+			return
+		case msg := <-d.hubRelay:
+			if msg.Signal != pstypes.SigAddressTx {
+				continue
+			}
+			am, ok := msg.Msg.(*pstypes.AddressMessage)
+			if !ok {
+				log.Errorf("sigAddressTx did not store a *AddressMessage in Msg.")
+				continue
+			}
+			n, err := d.store.InsertWebhookMempoolDeliveries(am.Address, am.TxHash)
+			if err != nil {
+				log.Errorf("InsertWebhookMempoolDeliveries: %v", err)
+				continue
+			}
+			if n > 0 {
+				d.signal()
+			}
+		case <-ticker.C:
+			d.sendPending(ctx)
+		case <-d.wake:
+			d.sendPending(ctx)
+		}
+	}
+}
+
+// sendPending sends the deliveries that are due, in batches. The deliveries
+// for each webhook are sent in order, concurrently with the deliveries for
+// other webhooks. When a delivery fails, the webhook's remaining deliveries in
+// the batch are left for a later attempt.
+func (d *Dispatcher) sendPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := d.store.PendingWebhookDeliveries(batchSize)
+		if err != nil {
+			log.Errorf("PendingWebhookDeliveries: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		byWebhook := make(map[string][]*dbtypes.WebhookDelivery)
+		var order []string
+		for _, dl := range deliveries {
+			if _, found := byWebhook[dl.WebhookID]; !found {
+				order = append(order, dl.WebhookID)
+			}
+			byWebhook[dl.WebhookID] = append(byWebhook[dl.WebhookID], dl)
+		}
+
+		var wg sync.WaitGroup
+		var numSent int
+		var sentMtx sync.Mutex
+		for _, id := range order {
+			wg.Add(1)
+			go func(dls []*dbtypes.WebhookDelivery) {
+				defer wg.Done()
+				for _, dl := range dls {
+					ok := d.deliver(ctx, dl)
+					sentMtx.Lock()
+					numSent++
+					sentMtx.Unlock()
+					if !ok {
+						return
+					}
+				}
+			}(byWebhook[id])
+		}
+		wg.Wait()
+
+		// Stop if nothing was attempted or the last batch was partial,
+		// otherwise there may be more due.
+		if numSent == 0 || len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt to send the delivery, and stores the result. The
+// returned bool indicates if the delivery succeeded.
+func (d *Dispatcher) deliver(ctx context.Context, dl *dbtypes.WebhookDelivery) bool {
+	dl.Attempts++
+	code, err := d.post(ctx, dl)
+	dl.ResponseCode = int32(code)
+	switch {
+	case err == nil:
+		dl.Status = dbtypes.WebhookDeliveryDelivered
+		dl.LastError = ""
+		log.Tracef("Delivered webhook %s event %d (%s).", dl.WebhookID, dl.ID, dl.Event)
+	case dl.Attempts >= MaxAttempts:
+		dl.Status = dbtypes.WebhookDeliveryFailed
+		dl.LastError = err.Error()
+		log.Debugf("Webhook %s delivery %d failed after %d attempts: %v",
+			dl.WebhookID, dl.ID, dl.Attempts, err)
+	default:
+		dl.NextAttempt = dbtypes.NewTimeDef(time.Now().Add(RetryDelay(dl.Attempts)))
+		dl.LastError = err.Error()
+		log.Tracef("Webhook %s delivery %d attempt %d failed: %v",
+			dl.WebhookID, dl.ID, dl.Attempts, err)
+	}
+
+	if errU := d.store.UpdateWebhookDelivery(dl); errU != nil {
+		log.Errorf("UpdateWebhookDelivery: %v", errU)
+	}
+	return err == nil
+}
+
+// post sends the signed event to the webhook URL. Any response other than a
+// 2xx status code is an error.
+func (d *Dispatcher) post(ctx context.Context, dl *dbtypes.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(&apitypes.WebhookEvent{
+		DeliveryID:    dl.ID,
+		WebhookID:     dl.WebhookID,
+		Event:         dl.Event,
+		Address:       dl.Address,
+		TxID:          dl.TxHash,
+		BlockHash:     dl.BlockHash,
+		BlockHeight:   dl.BlockHeight,
+		Confirmations: dl.Confirmations,
+		Created:       dl.Created.UNIX(),
+		Attempt:       dl.Attempts,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Signature(dl.Secret, body))
+	req.Header.Set(EventHeader, dl.Event)
+	req.Header.Set(DeliveryHeader, fmt.Sprint(dl.ID))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	// Drain (a limited amount of) the body so the connection may be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("response status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/dcrdata/v6/db/dbtypes"
+)
+
+type testStore struct {
+	mtx     sync.Mutex
+	updates []dbtypes.WebhookDelivery
+}
+
+func (s *testStore) InsertWebhookMempoolDeliveries(string, string) (int64, error) {
+	return 0, nil
+}
+
+func (s *testStore) InsertWebhookBlockDeliveries(string, int64) (int64, error) {
+	return 0, nil
+}
+
+func (s *testStore) PendingWebhookDeliveries(int) ([]*dbtypes.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *testStore) UpdateWebhookDelivery(d *dbtypes.WebhookDelivery) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.updates = append(s.updates, *d)
+	return nil
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"event":"mempool"}`)
+	sig := Signature("secret", body)
+	if !VerifySignature("secret", body, sig) {
+		t.Errorf("signature %s not verified", sig)
+	}
+	if VerifySignature("other", body, sig) {
+		t.Errorf("signature %s verified with the wrong secret", sig)
+	}
+	if VerifySignature("secret", []byte(`{"event":"reorg"}`), sig) {
+		t.Errorf("signature %s verified with the wrong body", sig)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{MaxAttempts, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := RetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	addrs := []string{"DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC"}
+	tests := []struct {
+		name    string
+		req     apitypes.WebhookRequest
+		wantErr bool
+	}{
+		{"ok", apitypes.WebhookRequest{URL: "https://example.com/hook", Addresses: addrs}, false},
+		{"ok confs", apitypes.WebhookRequest{URL: "http://example.com", Addresses: addrs, Confirmations: 1}, false},
+		{"bad scheme", apitypes.WebhookRequest{URL: "ftp://example.com", Addresses: addrs}, true},
+		{"no host", apitypes.WebhookRequest{URL: "https://", Addresses: addrs}, true},
+		{"no addresses", apitypes.WebhookRequest{URL: "https://example.com"}, true},
+		{"loopback", apitypes.WebhookRequest{URL: "http://127.0.0.1:9108/hook", Addresses: addrs}, true},
+		{"localhost", apitypes.WebhookRequest{URL: "http://localhost/hook", Addresses: addrs}, true},
+		{"private", apitypes.WebhookRequest{URL: "https://192.168.1.1", Addresses: addrs}, true},
+		{"metadata", apitypes.WebhookRequest{URL: "http://169.254.169.254/latest", Addresses: addrs}, true},
+		{"ipv6 loopback", apitypes.WebhookRequest{URL: "http://[::1]:8080", Addresses: addrs}, true},
+		{"public ip", apitypes.WebhookRequest{URL: "https://1.1.1.1/hook", Addresses: addrs}, false},
+		{"too many confs", apitypes.WebhookRequest{URL: "https://example.com", Addresses: addrs,
+			Confirmations: MaxConfirmations + 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wh, err := NewWebhook(&tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewWebhook error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(wh.ID) != 32 || len(wh.Secret) != 64 {
+				t.Errorf("bad ID %q or secret %q", wh.ID, wh.Secret)
+			}
+			if tt.req.Confirmations == 0 && wh.Confirmations != DefaultConfirmations {
+				t.Errorf("got %d confirmations, want %d", wh.Confirmations, DefaultConfirmations)
+			}
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"1.1.1.1", true},
+		{"8.8.4.4", true},
+		{"2606:4700:4700::1111", true},
+		{"0.0.0.0", false},
+		{"10.1.2.3", false},
+		{"100.64.0.1", false},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"172.31.255.255", false},
+		{"172.32.0.1", true},
+		{"192.168.0.1", false},
+		{"224.0.0.1", false},
+		{"255.255.255.255", false},
+		{"::", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"ff02::1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	const secret = "secret"
+	var status = http.StatusOK
+	var got apitypes.WebhookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !VerifySignature(secret, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("bad signature")
+		}
+		if r.Header.Get(EventHeader) != dbtypes.WebhookEventConfirmed {
+			t.Errorf("bad event header %q", r.Header.Get(EventHeader))
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	store := new(testStore)
+	d := NewDispatcher(store)
+	dl := &dbtypes.WebhookDelivery{
+		ID:            7,
+		WebhookID:     "abcd",
+		Event:         dbtypes.WebhookEventConfirmed,
+		Address:       "DsfX4WrSecUwGoRd9B7Lz1JjYssYaVKnjGC",
+		TxHash:        "e58ff2e8d0c6d3d0d5f8cbd1c3d06c5ebc3fd7d5b3f1b0e9bde3d9c1a0b1c2d3",
+		BlockHeight:   100,
+		Confirmations: 1,
+		Status:        dbtypes.WebhookDeliveryPending,
+		URL:           srv.URL,
+		Secret:        secret,
+	}
+
+	// The dispatcher refuses to connect to the loopback test server.
+	if _, err := d.post(context.Background(), dl); !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("post to %s: got error %v, want ErrPrivateAddress", srv.URL, err)
+	}
+	d.client.Transport = http.DefaultTransport
+
+	// A failed attempt is rescheduled.
+	status = http.StatusInternalServerError
+	if d.deliver(context.Background(), dl) {
+		t.Fatal("delivery succeeded with a 500 response")
+	}
+	last := store.updates[len(store.updates)-1]
+	if last.Status != dbtypes.WebhookDeliveryPending || last.Attempts != 1 ||
+		last.ResponseCode != 500 || last.LastError == "" {
+		t.Errorf("unexpected state after failed attempt: %+v", last)
+	}
+	if time.Until(last.NextAttempt.T) < RetryDelay(1)-time.Second {
+		t.Errorf("next attempt %v not delayed", last.NextAttempt.T)
+	}
+
+	// The next attempt succeeds.
+	status = http.StatusNoContent
+	if !d.deliver(context.Background(), dl) {
+		t.Fatal("delivery failed with a 204 response")
+	}
+	last = store.updates[len(store.updates)-1]
+	if last.Status != dbtypes.WebhookDeliveryDelivered || last.Attempts != 2 || last.LastError != "" {
+		t.Errorf("unexpected state after successful attempt: %+v", last)
+	}
+	if got.DeliveryID != 7 || got.Attempt != 2 || got.TxID != dl.TxHash {
+		t.Errorf("unexpected event %+v", got)
+	}
+
+	// The delivery fails after the last attempt.
+	status = http.StatusNotFound
+	dl.Attempts = MaxAttempts - 1
+	d.deliver(context.Background(), dl)
+	last = store.updates[len(store.updates)-1]
+	if last.Status != dbtypes.WebhookDeliveryFailed {
+		t.Errorf("got status %s after the last attempt, want failed", last.Status)
+	}
+}
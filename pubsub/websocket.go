@@ -45,12 +45,18 @@ var (
 	sigNewTxs           = pstypes.SigNewTxs
 	sigAddressTx        = pstypes.SigAddressTx
 	sigSyncStatus       = pstypes.SigSyncStatus
+	sigFeeEstimate      = pstypes.SigFeeEstimate
+	sigDoubleSpend      = pstypes.SigDoubleSpend
+	sigTicketSet        = pstypes.SigTicketSet
+	sigTSpend           = pstypes.SigTSpend
 	sigByeNow           = pstypes.SigByeNow
 )
 
 type txList struct {
 	sync.Mutex
 	t pstypes.TxList
+	// lastID is the event log ID of the last transaction added.
+	lastID uint64
 }
 
 func newTxList(cap int) *txList {
@@ -59,10 +65,11 @@ func newTxList(cap int) *txList {
 	return txl
 }
 
-func (tl *txList) addTxToBuffer(tx *exptypes.MempoolTx) (readyToSend bool) {
+func (tl *txList) addTxToBuffer(tx *exptypes.MempoolTx, id uint64) (readyToSend bool) {
 	tl.Lock()
 	defer tl.Unlock()
 	tl.t = append(tl.t, tx)
+	tl.lastID = id
 	if len(tl.t) >= NewTxBufferSize {
 		readyToSend = true
 	}
@@ -84,6 +91,8 @@ type WebsocketHub struct {
 	killed             chan struct{}
 	requestLimit       int
 	ready              atomic.Value
+	// events is the log of the events signaled to clients, if set.
+	events *eventLog
 }
 
 func (wsh *WebsocketHub) TimeToSendTxBuffer() bool {
@@ -107,21 +116,24 @@ func (wsh *WebsocketHub) SetReady(ready bool) {
 }
 
 type client struct {
-	mtx    sync.RWMutex
-	id     uint64
-	subs   map[pstypes.HubSignal]struct{}
-	addrs  map[string]struct{}
-	killed chan struct{}
-	newTxs *txList
+	mtx   sync.RWMutex
+	id    uint64
+	subs  map[pstypes.HubSignal]struct{}
+	addrs map[string]struct{}
+	// ticketSets are the IDs of the ticket sets watched by the client.
+	ticketSets map[string]struct{}
+	killed     chan struct{}
+	newTxs     *txList
 }
 
 func newClient() *client {
 	return &client{
-		id:     newClientID(),
-		subs:   make(map[pstypes.HubSignal]struct{}, 16),
-		addrs:  make(map[string]struct{}, 16),
-		killed: make(chan struct{}),
-		newTxs: newTxList(NewTxBufferSize),
+		id:         newClientID(),
+		subs:       make(map[pstypes.HubSignal]struct{}, 16),
+		addrs:      make(map[string]struct{}, 16),
+		ticketSets: make(map[string]struct{}),
+		killed:     make(chan struct{}),
+		newTxs:     newTxList(NewTxBufferSize),
 	}
 }
 
@@ -142,6 +154,13 @@ func (c *client) isSubscribed(msg pstypes.HubMessage) bool {
 			return false
 		}
 		_, subd = c.addrs[am.Address]
+	case pstypes.SigTicketSet:
+		tsu, ok := msg.Msg.(*pstypes.TicketSetUpdate)
+		if !ok {
+			log.Errorf("not a TicketSetUpdate (SigTicketSet): %T", msg.Msg)
+			return false
+		}
+		_, subd = c.ticketSets[tsu.SetID]
 	default:
 	}
 
@@ -159,6 +178,12 @@ func (c *client) subscribe(msg pstypes.HubMessage) (bool, error) {
 			return false, fmt.Errorf("msg.Msg not a string (SigAddressTx): %T", msg.Msg)
 		}
 		c.addrs[am.Address] = struct{}{}
+	case pstypes.SigTicketSet:
+		tsu, ok := msg.Msg.(*pstypes.TicketSetUpdate)
+		if !ok {
+			return false, fmt.Errorf("msg.Msg not a TicketSetUpdate (SigTicketSet): %T", msg.Msg)
+		}
+		c.ticketSets[tsu.SetID] = struct{}{}
 	case sigPingAndUserCount, sigByeNow, sigDecodeTx, sigSentTx, sigSubscribe, sigUnsubscribe:
 		// These are not subscription-based events, do not clutter the subs map.
 		return false, nil
@@ -185,6 +210,15 @@ func (c *client) unsubscribe(msg pstypes.HubMessage) error {
 		if len(c.addrs) == 0 {
 			delete(c.subs, pstypes.SigAddressTx)
 		}
+	case pstypes.SigTicketSet:
+		tsu, ok := msg.Msg.(*pstypes.TicketSetUpdate)
+		if !ok {
+			return fmt.Errorf("msg.Msg not a TicketSetUpdate (SigTicketSet): %T", msg.Msg)
+		}
+		delete(c.ticketSets, tsu.SetID)
+		if len(c.ticketSets) == 0 {
+			delete(c.subs, pstypes.SigTicketSet)
+		}
 	default:
 		delete(c.subs, msg.Signal)
 	}
@@ -201,6 +235,9 @@ func (c *client) unsubscribeAll() {
 	for addr := range c.addrs {
 		delete(c.addrs, addr)
 	}
+	for id := range c.ticketSets {
+		delete(c.ticketSets, id)
+	}
 }
 
 // NewWebsocketHub creates a new WebsocketHub.
@@ -417,6 +454,17 @@ func (wsh *WebsocketHub) Run() {
 				log.Debugf("wsh.HubRelay closed.")
 				return
 			}
+			if !hubMsg.IsValid() {
+				log.Warnf("Invalid message on HubRelay: %s", hubMsg)
+				break
+			}
+
+			// Log the event for clients resuming later, even with no
+			// clients now.
+			if wsh.events != nil {
+				hubMsg.ID = wsh.events.add(hubMsg)
+			}
+
 			// Number of connected clients
 			clientsCount := len(wsh.clients)
 
@@ -425,11 +473,6 @@ func (wsh *WebsocketHub) Run() {
 				break
 			}
 
-			if !hubMsg.IsValid() {
-				log.Warnf("Invalid message on HubRelay: %s", hubMsg)
-				break
-			}
-
 			switch hubMsg.Signal {
 			case sigNewBlock:
 				// Do not log when explorer update status is active.
@@ -459,7 +502,7 @@ func (wsh *WebsocketHub) Run() {
 				log.Tracef("Received new tx %s. Queueing in each client's send buffer...", newTx.Hash)
 				// Only signal clients if there are tx buffers ready to send or
 				// the ticker has fired.
-				if !(wsh.maybeSendTxns(newTx) || wsh.TimeToSendTxBuffer()) {
+				if !(wsh.maybeSendTxns(newTx, hubMsg.ID) || wsh.TimeToSendTxBuffer()) {
 					break
 				}
 
@@ -470,11 +513,26 @@ func (wsh *WebsocketHub) Run() {
 				// PubSubHub with a nil slice to be a valid message.
 				hubMsg.Signal = sigNewTxs
 				hubMsg.Msg = ([]*exptypes.MempoolTx)(nil) // PubSubHub accesses each client's own slice.
+				// The ID of the buffered transactions is that of the last
+				// one in each client's buffer.
+				hubMsg.ID = 0
 			case sigSubscribe, sigUnsubscribe:
 				log.Warnf("sigSubscribe and sigUnsubscribe are not broadcastable events.")
 				continue // break events
 			case sigSyncStatus:
 				// TODO
+			case sigFeeEstimate:
+				log.Debugf("Signaling fee estimates to %d websocket clients.", clientsCount)
+			case sigDoubleSpend:
+				log.Debugf("Signaling double spend to %d websocket clients.", clientsCount)
+			case sigTSpend:
+				log.Debugf("Signaling tspend progress to %d websocket clients.", clientsCount)
+			case sigTicketSet:
+				tsu, ok := hubMsg.Msg.(*pstypes.TicketSetUpdate)
+				if !ok || tsu == nil {
+					log.Errorf("sigTicketSet did not store a *TicketSetUpdate in Msg.")
+					continue
+				}
 			case sigByeNow:
 				log.Infof("Warning all %d clients of impending hang-up.", len(wsh.clients))
 				// Broadcast "bye" to all clients (not a subscription).
@@ -524,10 +582,10 @@ func (wsh *WebsocketHub) Run() {
 // maybeSendTxns adds a mempool transaction to the client broadcast buffer. If
 // the buffer is at capacity, a goroutine is launched to signal for the
 // transactions to be sent to the clients.
-func (wsh *WebsocketHub) maybeSendTxns(tx *exptypes.MempoolTx) (someReadyToSend bool) {
+func (wsh *WebsocketHub) maybeSendTxns(tx *exptypes.MempoolTx, id uint64) (someReadyToSend bool) {
 	// addTxToBuffer adds the transaction to each client's tx buffer, and
 	// indicates if at least one client has a buffer at or above the send limit.
-	someReadyToSend = wsh.addTxToBuffer(tx)
+	someReadyToSend = wsh.addTxToBuffer(tx, id)
 	if someReadyToSend {
 		// Reset the "time to send" ticker since the event loop is about send.
 		wsh.bufferTickerChan <- tickerSigReset
@@ -535,11 +593,12 @@ func (wsh *WebsocketHub) maybeSendTxns(tx *exptypes.MempoolTx) (someReadyToSend
 	return
 }
 
-// addTxToBuffer adds a tx to each client's tx buffer. The return boolean value
-// indicates if at least one buffer is ready to be sent.
-func (wsh *WebsocketHub) addTxToBuffer(tx *exptypes.MempoolTx) (someReadyToSend bool) {
+// addTxToBuffer adds a tx to each client's tx buffer, with its event log ID.
+// The return boolean value indicates if at least one buffer is ready to be
+// sent.
+func (wsh *WebsocketHub) addTxToBuffer(tx *exptypes.MempoolTx, id uint64) (someReadyToSend bool) {
 	for _, client := range wsh.clients {
-		someReadyToSend = client.newTxs.addTxToBuffer(tx)
+		someReadyToSend = client.newTxs.addTxToBuffer(tx, id)
 	}
 	return
 }
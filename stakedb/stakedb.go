@@ -110,6 +110,11 @@ type StakeDatabase struct {
 	// WaitForHeight. The clients' channels are stored in heightWaiters.
 	waitMtx       sync.Mutex
 	heightWaiters map[int64][]chan *chainhash.Hash
+
+	// clients may subscribe to the ticket status transitions of the connected
+	// and disconnected blocks via SubscribeTicketDiffs.
+	diffMtx     sync.Mutex
+	diffClients []chan *TicketDiff
 }
 
 const (
@@ -582,7 +587,19 @@ func (db *StakeDatabase) ConnectBlock(block *dcrutil.Block) error {
 	db.poolInfo.Set(*block.Hash(), pib)
 
 	// Append this ticket pool diff
-	return db.PoolDB.Append(poolDiff, bestNodeHeight+1)
+	if err = db.PoolDB.Append(poolDiff, bestNodeHeight+1); err != nil {
+		return err
+	}
+
+	if db.hasDiffClients() {
+		purchased, _ := txhelpers.TicketsInBlock(block)
+		db.signalTicketDiff(&TicketDiff{
+			Height:      height,
+			Hash:        *block.Hash(),
+			Transitions: blockTicketTransitions(db.BestNode.UndoData(), purchased, false),
+		})
+	}
+	return nil
 }
 
 func (db *StakeDatabase) connectBlock(block *dcrutil.Block, spent []chainhash.Hash,
@@ -708,6 +725,16 @@ func (db *StakeDatabase) disconnectBlock(neglectCache bool) error {
 	log.Tracef("Disconnecting block %d.", childHeight)
 	childUndoData := append(stake.UndoTicketDataSlice(nil), db.BestNode.UndoData()...)
 
+	// The ticket transitions of the child block are reversed, so they must be
+	// computed before the child block is forgotten.
+	var diff *TicketDiff
+	if db.hasDiffClients() {
+		diff, err = db.disconnectedTicketDiff(int64(childHeight), childUndoData)
+		if err != nil {
+			return err
+		}
+	}
+
 	// previous best node
 	hB, errx := parentBlock.BlockHeaderBytes()
 	if errx != nil {
@@ -729,10 +756,38 @@ func (db *StakeDatabase) disconnectBlock(neglectCache bool) error {
 	}
 	db.BestNode = parentStakeNode
 
-	return db.StakeDB.Update(func(dbTx database.Tx) error {
+	err = db.StakeDB.Update(func(dbTx database.Tx) error {
 		return stake.WriteDisconnectedBestNode(dbTx, parentStakeNode,
 			*parentBlock.Hash(), childUndoData)
 	})
+	if err != nil {
+		return err
+	}
+
+	if diff != nil {
+		db.signalTicketDiff(diff)
+	}
+	return nil
+}
+
+// disconnectedTicketDiff creates the TicketDiff of the current best block,
+// which is about to be disconnected, from the block's undo data.
+func (db *StakeDatabase) disconnectedTicketDiff(height int64, undo stake.UndoTicketDataSlice) (*TicketDiff, error) {
+	_, hash, err := db.dbState()
+	if err != nil {
+		return nil, err
+	}
+	block, err := db.getBlock(hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get block %v: %v", hash, err)
+	}
+	purchased, _ := txhelpers.TicketsInBlock(block)
+	return &TicketDiff{
+		Height:       height,
+		Hash:         *hash,
+		Disconnected: true,
+		Transitions:  blockTicketTransitions(undo, purchased, true),
+	}, nil
 }
 
 // DisconnectBlocks disconnects N blocks from the head of the chain.
@@ -0,0 +1,147 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package stakedb
+
+import (
+	"context"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+// The ticket statuses of the transitions in a TicketDiff. These are the same
+// statuses reported by the ticket info of the API.
+const (
+	TicketStatusUnmined  = "unmined"
+	TicketStatusImmature = "immature"
+	TicketStatusLive     = "live"
+	TicketStatusVoted    = "voted"
+	TicketStatusMissed   = "missed"
+	TicketStatusExpired  = "expired"
+	TicketStatusRevoked  = "revoked"
+)
+
+// ticketDiffBufferSize is the capacity of the channels returned by
+// SubscribeTicketDiffs.
+const ticketDiffBufferSize = 256
+
+// TicketTransition is the change in status of a ticket in a block.
+type TicketTransition struct {
+	Ticket chainhash.Hash
+	From   string
+	To     string
+}
+
+// TicketDiff is the ticket status transitions of a block connected to or
+// disconnected from the stake database. The transitions of a disconnected
+// block are those of the block when it was connected, reversed.
+type TicketDiff struct {
+	Height       int64
+	Hash         chainhash.Hash
+	Disconnected bool
+	Transitions  []TicketTransition
+}
+
+// SubscribeTicketDiffs returns a channel on which the TicketDiff of every
+// block connected to or disconnected from the stake database is sent, until
+// the context is canceled. The stake database does not wait for the
+// subscriber: a diff is dropped, with an error logged, if the channel's buffer
+// is full, so the subscriber must receive the diffs promptly.
+func (db *StakeDatabase) SubscribeTicketDiffs(ctx context.Context) <-chan *TicketDiff {
+	c := make(chan *TicketDiff, ticketDiffBufferSize)
+	db.diffMtx.Lock()
+	db.diffClients = append(db.diffClients, c)
+	db.diffMtx.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		db.unsubscribeTicketDiffs(c)
+	}()
+	return c
+}
+
+// unsubscribeTicketDiffs stops sending the ticket diffs on the channel.
+func (db *StakeDatabase) unsubscribeTicketDiffs(c chan *TicketDiff) {
+	db.diffMtx.Lock()
+	defer db.diffMtx.Unlock()
+	for i := range db.diffClients {
+		if db.diffClients[i] == c {
+			db.diffClients = append(db.diffClients[:i], db.diffClients[i+1:]...)
+			return
+		}
+	}
+}
+
+// hasDiffClients checks if there are subscribers to the ticket diffs, so that
+// they are not computed for nobody.
+func (db *StakeDatabase) hasDiffClients() bool {
+	db.diffMtx.Lock()
+	defer db.diffMtx.Unlock()
+	return len(db.diffClients) > 0
+}
+
+// signalTicketDiff sends the TicketDiff to the subscribers without blocking.
+// The diff is dropped for a subscriber that is ticketDiffBufferSize diffs
+// behind.
+func (db *StakeDatabase) signalTicketDiff(diff *TicketDiff) {
+	db.diffMtx.Lock()
+	defer db.diffMtx.Unlock()
+	for _, c := range db.diffClients {
+		select {
+		case c <- diff:
+		default:
+			log.Errorf("Ticket diff subscriber is %d blocks behind. Dropped the diff of block %d (%v).",
+				ticketDiffBufferSize, diff.Height, diff.Hash)
+		}
+	}
+}
+
+// undoTicketStatuses returns the status of a ticket before and after a block
+// given the ticket's flags in the block's undo data. The undo data of a block
+// has the flags of each ticket after the block, for the tickets that matured,
+// voted, missed, expired or were revoked in the block.
+func undoTicketStatuses(missed, revoked, spent, expired bool) (before, after string) {
+	switch {
+	case revoked:
+		if expired {
+			return TicketStatusExpired, TicketStatusRevoked
+		}
+		return TicketStatusMissed, TicketStatusRevoked
+	case spent:
+		return TicketStatusLive, TicketStatusVoted
+	case expired:
+		return TicketStatusLive, TicketStatusExpired
+	case missed:
+		return TicketStatusLive, TicketStatusMissed
+	default:
+		return TicketStatusImmature, TicketStatusLive
+	}
+}
+
+// blockTicketTransitions returns the ticket transitions of a block from the
+// block's undo data and the tickets purchased in the block. If disconnected is
+// true, the transitions are reversed.
+func blockTicketTransitions(undo stake.UndoTicketDataSlice, purchased []chainhash.Hash,
+	disconnected bool) []TicketTransition {
+	transitions := make([]TicketTransition, 0, len(undo)+len(purchased))
+	add := func(ticket chainhash.Hash, before, after string) {
+		if disconnected {
+			before, after = after, before
+		}
+		transitions = append(transitions, TicketTransition{
+			Ticket: ticket,
+			From:   before,
+			To:     after,
+		})
+	}
+	for i := range purchased {
+		add(purchased[i], TicketStatusUnmined, TicketStatusImmature)
+	}
+	for i := range undo {
+		u := &undo[i]
+		before, after := undoTicketStatuses(u.Missed, u.Revoked, u.Spent, u.Expired)
+		add(u.TicketHash, before, after)
+	}
+	return transitions
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package stakedb
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+const (
+	// MaxTicketSetSize is the maximum number of tickets in a ticket set.
+	MaxTicketSetSize = 5000
+
+	// maxTicketSets is the maximum number of ticket sets tracked. The least
+	// recently registered or requested set is dropped to track a new set.
+	maxTicketSets = 256
+)
+
+// TicketInfoSource provides the ticket info of many tickets.
+type TicketInfoSource interface {
+	// GetTicketsInfo returns the ticket info of each of the tickets that is
+	// found, by ticket hash.
+	GetTicketsInfo(txids []string) (map[string]*apitypes.TicketInfo, error)
+}
+
+// ticketSet is a ticket set tracked by the TicketSetTracker.
+type ticketSet struct {
+	tickets  []chainhash.Hash
+	lastUsed time.Time
+}
+
+// trackedTicket is the status of a ticket of one or more ticket sets.
+type trackedTicket struct {
+	status string
+	sets   map[string]struct{}
+}
+
+// TicketSetTracker tracks the status of the tickets of registered ticket sets,
+// and signals the status transitions of the tickets of each set with the
+// SigTicketSet signal. The transitions are those of the TicketDiffs of the
+// StakeDatabase, so dashboards and VSPs watching many tickets do not need to
+// request the info of every ticket each block.
+type TicketSetTracker struct {
+	mtx        sync.Mutex
+	source     TicketInfoSource
+	sets       map[string]*ticketSet
+	tickets    map[chainhash.Hash]*trackedTicket
+	signalOuts []chan<- pstypes.HubMessage
+}
+
+// NewTicketSetTracker creates a new TicketSetTracker using the TicketInfoSource
+// for the initial status of the tickets of each new ticket set. The status
+// transitions are sent to the signalOuts.
+func NewTicketSetTracker(source TicketInfoSource, signalOuts []chan<- pstypes.HubMessage) *TicketSetTracker {
+	return &TicketSetTracker{
+		source:     source,
+		sets:       make(map[string]*ticketSet),
+		tickets:    make(map[chainhash.Hash]*trackedTicket),
+		signalOuts: signalOuts,
+	}
+}
+
+// ticketSetID is the ID of the ticket set with the sorted tickets, which is
+// the same for every registration of the same tickets.
+func ticketSetID(tickets []chainhash.Hash) string {
+	h := sha256.New()
+	for i := range tickets {
+		h.Write(tickets[i][:])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// snapshot returns the current status of the tickets of the set. The
+// TicketSetTracker must be locked.
+func (tst *TicketSetTracker) snapshot(id string, set *ticketSet) *apitypes.TicketSet {
+	statuses := make(map[string]string, len(set.tickets))
+	for _, ticket := range set.tickets {
+		statuses[ticket.String()] = tst.tickets[ticket].status
+	}
+	return &apitypes.TicketSet{
+		ID:      id,
+		Tickets: statuses,
+	}
+}
+
+// Register starts tracking the set of tickets, and returns the ticket set with
+// the current status of each ticket. Registering the same tickets again
+// returns the same ticket set. Tickets that are not mined have the unmined
+// status.
+func (tst *TicketSetTracker) Register(tickets []string) (*apitypes.TicketSet, error) {
+	hashes := make([]chainhash.Hash, 0, len(tickets))
+	seen := make(map[chainhash.Hash]struct{}, len(tickets))
+	for _, ticket := range tickets {
+		hash, err := chainhash.NewHashFromStr(ticket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticket hash %q: %v", ticket, err)
+		}
+		if _, found := seen[*hash]; found {
+			continue
+		}
+		seen[*hash] = struct{}{}
+		hashes = append(hashes, *hash)
+	}
+	if len(hashes) == 0 || len(hashes) > MaxTicketSetSize {
+		return nil, fmt.Errorf("a ticket set has from 1 to %d tickets, not %d",
+			MaxTicketSetSize, len(hashes))
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	id := ticketSetID(hashes)
+
+	tst.mtx.Lock()
+	if set := tst.sets[id]; set != nil {
+		set.lastUsed = time.Now()
+		defer tst.mtx.Unlock()
+		return tst.snapshot(id, set), nil
+	}
+	tst.mtx.Unlock()
+
+	// The status of the tickets of a new set is retrieved without holding the
+	// lock. A transition in a block connected meanwhile is only reflected by
+	// the set if the retrieved status includes it.
+	txids := make([]string, 0, len(hashes))
+	for i := range hashes {
+		txids = append(txids, hashes[i].String())
+	}
+	infos, err := tst.source.GetTicketsInfo(txids)
+	if err != nil {
+		return nil, err
+	}
+
+	tst.mtx.Lock()
+	defer tst.mtx.Unlock()
+	if set := tst.sets[id]; set != nil {
+		// Registered concurrently.
+		set.lastUsed = time.Now()
+		return tst.snapshot(id, set), nil
+	}
+	if len(tst.sets) >= maxTicketSets {
+		tst.dropLeastRecentlyUsed()
+	}
+	set := &ticketSet{
+		tickets:  hashes,
+		lastUsed: time.Now(),
+	}
+	tst.sets[id] = set
+	for i, hash := range hashes {
+		// A ticket of another tracked set keeps its tracked status.
+		tt := tst.tickets[hash]
+		if tt == nil {
+			tt = &trackedTicket{
+				status: TicketStatusUnmined,
+				sets:   make(map[string]struct{}, 1),
+			}
+			if info := infos[txids[i]]; info != nil {
+				tt.status = info.Status
+			}
+			tst.tickets[hash] = tt
+		}
+		tt.sets[id] = struct{}{}
+	}
+	log.Debugf("Tracking ticket set %s of %d tickets.", id, len(hashes))
+	return tst.snapshot(id, set), nil
+}
+
+// dropLeastRecentlyUsed stops tracking the ticket set that was registered or
+// requested least recently. The TicketSetTracker must be locked.
+func (tst *TicketSetTracker) dropLeastRecentlyUsed() {
+	var oldestID string
+	var oldest *ticketSet
+	for id, set := range tst.sets {
+		if oldest == nil || set.lastUsed.Before(oldest.lastUsed) {
+			oldestID, oldest = id, set
+		}
+	}
+	if oldest == nil {
+		return
+	}
+	for _, hash := range oldest.tickets {
+		tt := tst.tickets[hash]
+		delete(tt.sets, oldestID)
+		if len(tt.sets) == 0 {
+			delete(tst.tickets, hash)
+		}
+	}
+	delete(tst.sets, oldestID)
+	log.Debugf("Dropped ticket set %s.", oldestID)
+}
+
+// TicketSet returns the ticket set with the current status of each ticket, or
+// nil if there is no tracked ticket set with the ID.
+func (tst *TicketSetTracker) TicketSet(id string) *apitypes.TicketSet {
+	tst.mtx.Lock()
+	defer tst.mtx.Unlock()
+	set := tst.sets[id]
+	if set == nil {
+		return nil
+	}
+	set.lastUsed = time.Now()
+	return tst.snapshot(id, set)
+}
+
+// processDiff updates the status of the tracked tickets with the transitions of
+// the TicketDiff, and returns the transitions of each affected ticket set.
+func (tst *TicketSetTracker) processDiff(diff *TicketDiff) []*pstypes.TicketSetUpdate {
+	tst.mtx.Lock()
+	defer tst.mtx.Unlock()
+
+	updates := make(map[string]*pstypes.TicketSetUpdate)
+	for i := range diff.Transitions {
+		tr := &diff.Transitions[i]
+		tt := tst.tickets[tr.Ticket]
+		if tt == nil || tt.status == tr.To {
+			continue
+		}
+		tt.status = tr.To
+		transition := pstypes.TicketTransition{
+			Ticket: tr.Ticket.String(),
+			From:   tr.From,
+			To:     tr.To,
+		}
+		for id := range tt.sets {
+			update := updates[id]
+			if update == nil {
+				update = &pstypes.TicketSetUpdate{
+					SetID:        id,
+					BlockHash:    diff.Hash.String(),
+					BlockHeight:  diff.Height,
+					Disconnected: diff.Disconnected,
+				}
+				updates[id] = update
+			}
+			update.Transitions = append(update.Transitions, transition)
+		}
+	}
+
+	sorted := make([]*pstypes.TicketSetUpdate, 0, len(updates))
+	for _, update := range updates {
+		sorted = append(sorted, update)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].SetID < sorted[j].SetID
+	})
+	return sorted
+}
+
+// Run updates the tracked tickets with the TicketDiffs received on diffs, such
+// as those from StakeDatabase.SubscribeTicketDiffs, and signals the transitions
+// of each ticket set until the context is canceled or diffs is closed. It
+// should be launched as a goroutine.
+func (tst *TicketSetTracker) Run(ctx context.Context, wg *sync.WaitGroup, diffs <-chan *TicketDiff) {
+	defer wg.Done()
+
+	for {
+		select {
+		case diff, ok := <-diffs:
+			if !ok {
+				return
+			}
+			for _, update := range tst.processDiff(diff) {
+				tst.signal(ctx, update)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// signal sends the ticket set update to the signalOuts.
+func (tst *TicketSetTracker) signal(ctx context.Context, update *pstypes.TicketSetUpdate) {
+	for _, sigout := range tst.signalOuts {
+		select {
+		case sigout <- pstypes.HubMessage{Signal: pstypes.SigTicketSet, Msg: update}:
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Second):
+			log.Errorf("send to signalOuts (%v) failed: Timeout waiting for WebsocketHub.",
+				pstypes.SigTicketSet)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package stakedb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	apitypes "github.com/decred/dcrdata/v6/api/types"
+	pstypes "github.com/decred/dcrdata/v6/pubsub/types"
+)
+
+func TestBlockTicketTransitions(t *testing.T) {
+	undo := stake.UndoTicketDataSlice{
+		{TicketHash: chainhash.Hash{1}, Spent: true},
+		{TicketHash: chainhash.Hash{2}, Missed: true},
+		{TicketHash: chainhash.Hash{3}, Missed: true, Expired: true},
+		{TicketHash: chainhash.Hash{4}, Missed: true, Revoked: true},
+		{TicketHash: chainhash.Hash{5}, Missed: true, Expired: true, Revoked: true},
+		{TicketHash: chainhash.Hash{6}},
+	}
+	purchased := []chainhash.Hash{{7}}
+
+	want := []TicketTransition{
+		{chainhash.Hash{7}, TicketStatusUnmined, TicketStatusImmature},
+		{chainhash.Hash{1}, TicketStatusLive, TicketStatusVoted},
+		{chainhash.Hash{2}, TicketStatusLive, TicketStatusMissed},
+		{chainhash.Hash{3}, TicketStatusLive, TicketStatusExpired},
+		{chainhash.Hash{4}, TicketStatusMissed, TicketStatusRevoked},
+		{chainhash.Hash{5}, TicketStatusExpired, TicketStatusRevoked},
+		{chainhash.Hash{6}, TicketStatusImmature, TicketStatusLive},
+	}
+	if got := blockTicketTransitions(undo, purchased, false); !reflect.DeepEqual(got, want) {
+		t.Fatalf("connected block transitions %v, expected %v", got, want)
+	}
+
+	for i := range want {
+		want[i].From, want[i].To = want[i].To, want[i].From
+	}
+	if got := blockTicketTransitions(undo, purchased, true); !reflect.DeepEqual(got, want) {
+		t.Fatalf("disconnected block transitions %v, expected %v", got, want)
+	}
+}
+
+type testTicketInfoSource map[string]*apitypes.TicketInfo
+
+func (s testTicketInfoSource) GetTicketsInfo(txids []string) (map[string]*apitypes.TicketInfo, error) {
+	infos := make(map[string]*apitypes.TicketInfo)
+	for _, txid := range txids {
+		if info := s[txid]; info != nil {
+			infos[txid] = info
+		}
+	}
+	return infos, nil
+}
+
+func TestTicketSetTracker(t *testing.T) {
+	live, immature, unmined := chainhash.Hash{1}, chainhash.Hash{2}, chainhash.Hash{3}
+	source := testTicketInfoSource{
+		live.String():     {Status: TicketStatusLive},
+		immature.String(): {Status: TicketStatusImmature},
+	}
+	tst := NewTicketSetTracker(source, nil)
+
+	set, err := tst.Register([]string{live.String(), immature.String(), unmined.String(), live.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStatuses := map[string]string{
+		live.String():     TicketStatusLive,
+		immature.String(): TicketStatusImmature,
+		unmined.String():  TicketStatusUnmined,
+	}
+	if !reflect.DeepEqual(set.Tickets, wantStatuses) || !pstypes.ValidTicketSetID(set.ID) {
+		t.Fatalf("unexpected ticket set %+v", set)
+	}
+	// The same tickets in any order are the same set.
+	again, err := tst.Register([]string{unmined.String(), immature.String(), live.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != set.ID {
+		t.Fatalf("ticket set ID %s, expected %s", again.ID, set.ID)
+	}
+	// A second set sharing a ticket.
+	other, err := tst.Register([]string{live.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := &TicketDiff{
+		Height: 100,
+		Hash:   chainhash.Hash{100},
+		Transitions: []TicketTransition{
+			{live, TicketStatusLive, TicketStatusVoted},
+			{immature, TicketStatusImmature, TicketStatusLive},
+			{chainhash.Hash{9}, TicketStatusLive, TicketStatusMissed},
+		},
+	}
+	updates := tst.processDiff(diff)
+	if len(updates) != 2 {
+		t.Fatalf("%d ticket set updates, expected 2", len(updates))
+	}
+	for _, update := range updates {
+		switch update.SetID {
+		case set.ID:
+			if len(update.Transitions) != 2 || update.BlockHeight != 100 || update.Disconnected {
+				t.Errorf("unexpected update %+v", update)
+			}
+		case other.ID:
+			want := []pstypes.TicketTransition{{Ticket: live.String(),
+				From: TicketStatusLive, To: TicketStatusVoted}}
+			if !reflect.DeepEqual(update.Transitions, want) {
+				t.Errorf("unexpected transitions %+v", update.Transitions)
+			}
+		default:
+			t.Errorf("update of unknown set %s", update.SetID)
+		}
+	}
+	if status := tst.TicketSet(set.ID).Tickets[live.String()]; status != TicketStatusVoted {
+		t.Errorf("ticket status %s, expected voted", status)
+	}
+
+	// The same diff again changes nothing, and the disconnected block reverts
+	// the transitions.
+	if updates = tst.processDiff(diff); len(updates) != 0 {
+		t.Errorf("%d ticket set updates of a processed diff", len(updates))
+	}
+	disconnect := &TicketDiff{Height: 100, Hash: chainhash.Hash{100}, Disconnected: true}
+	for _, tr := range diff.Transitions {
+		disconnect.Transitions = append(disconnect.Transitions,
+			TicketTransition{tr.Ticket, tr.To, tr.From})
+	}
+	tst.processDiff(disconnect)
+	if got := tst.TicketSet(set.ID).Tickets; !reflect.DeepEqual(got, wantStatuses) {
+		t.Errorf("ticket statuses %v after disconnect, expected %v", got, wantStatuses)
+	}
+
+	// Dropping the least recently used set keeps the shared ticket.
+	tst.TicketSet(set.ID)
+	tst.dropLeastRecentlyUsed()
+	if tst.TicketSet(other.ID) != nil || tst.TicketSet(set.ID) == nil || tst.tickets[live] == nil {
+		t.Fatalf("least recently used set not dropped")
+	}
+
+	if _, err = tst.Register([]string{"nothash"}); err == nil {
+		t.Errorf("no error registering an invalid ticket hash")
+	}
+}
+
+func TestSignalTicketDiff(t *testing.T) {
+	db := new(StakeDatabase)
+	ctx, cancel := context.WithCancel(context.Background())
+	diffs := db.SubscribeTicketDiffs(ctx)
+
+	// A subscriber that falls behind does not block the stake database.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < ticketDiffBufferSize+10; i++ {
+			db.signalTicketDiff(&TicketDiff{Height: int64(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("signalTicketDiff blocked on a full subscriber")
+	}
+	if len(diffs) != ticketDiffBufferSize {
+		t.Fatalf("got %d diffs, expected %d", len(diffs), ticketDiffBufferSize)
+	}
+	if diff := <-diffs; diff.Height != 0 {
+		t.Fatalf("got the diff of block %d first, expected 0", diff.Height)
+	}
+
+	// The subscriber is removed when its context is canceled.
+	cancel()
+	for i := 0; db.hasDiffClients(); i++ {
+		if i == 100 {
+			t.Fatal("subscriber not removed after its context was canceled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"fmt"
+
+	"decred.org/dcrwallet/wallet/txrules"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/txscript/v3"
+	"github.com/decred/dcrd/wire"
+)
+
+const (
+	// MaxStandardTxSize is the maximum serialized size of a transaction that
+	// dcrd relays by default.
+	MaxStandardTxSize = 100000
+
+	// MaxStandardSigScriptSize is the maximum size of a signature script that
+	// dcrd considers standard. This is enough for a 15-of-15 CHECKMULTISIG
+	// pay-to-script-hash redeem script with its signatures.
+	MaxStandardSigScriptSize = 1650
+)
+
+// CheckTxStandard performs the context-free checks of a transaction that dcrd
+// makes before accepting it to mempool: the basic sanity of the inputs and
+// outputs, and the standardness of the transaction version, size, signature
+// scripts, and output scripts. Regular transaction outputs paying less than
+// the dust limit for the relay fee rate, in atoms/kB, are rejected. The checks
+// that require the previous outputs, such as the fee and the signatures, are
+// not performed.
+func CheckTxStandard(msgTx *wire.MsgTx, treasuryActive bool, relayFeePerKb dcrutil.Amount) error {
+	if len(msgTx.TxIn) == 0 {
+		return fmt.Errorf("transaction has no inputs")
+	}
+	if len(msgTx.TxOut) == 0 {
+		return fmt.Errorf("transaction has no outputs")
+	}
+
+	maxVersion := wire.TxVersionSeqLock
+	if treasuryActive {
+		maxVersion = wire.TxVersionTreasury
+	}
+	if msgTx.Version < 1 || msgTx.Version > maxVersion {
+		return fmt.Errorf("transaction version %d is not standard", msgTx.Version)
+	}
+
+	if size := msgTx.SerializeSize(); size > MaxStandardTxSize {
+		return fmt.Errorf("transaction size of %d bytes is larger than the "+
+			"maximum of %d bytes", size, MaxStandardTxSize)
+	}
+
+	txType := stake.DetermineTxType(msgTx, treasuryActive)
+
+	spent := make(map[wire.OutPoint]struct{}, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		if _, found := spent[txIn.PreviousOutPoint]; found {
+			return fmt.Errorf("input %d spends the same outpoint as a previous input", i)
+		}
+		spent[txIn.PreviousOutPoint] = struct{}{}
+
+		if size := len(txIn.SignatureScript); size > MaxStandardSigScriptSize {
+			return fmt.Errorf("input %d signature script size of %d bytes is "+
+				"larger than the maximum of %d bytes", i, size, MaxStandardSigScriptSize)
+		}
+		if !txscript.IsPushOnlyScript(txIn.SignatureScript) {
+			return fmt.Errorf("input %d signature script is not push only", i)
+		}
+	}
+
+	var totalOut int64
+	var numNullData int
+	for i, txOut := range msgTx.TxOut {
+		if txOut.Value < 0 || txOut.Value > dcrutil.MaxAmount {
+			return fmt.Errorf("output %d value of %d atoms is out of range", i, txOut.Value)
+		}
+		totalOut += txOut.Value
+		if totalOut > dcrutil.MaxAmount {
+			return fmt.Errorf("total output value is out of range")
+		}
+
+		class := txscript.GetScriptClass(txOut.Version, txOut.PkScript, treasuryActive)
+		switch class {
+		case txscript.NonStandardTy:
+			return fmt.Errorf("output %d script is not standard", i)
+		case txscript.NullDataTy:
+			numNullData++
+			if numNullData > 1 && txType == stake.TxTypeRegular {
+				return fmt.Errorf("transaction has more than one null data output")
+			}
+			continue
+		}
+
+		if txType == stake.TxTypeRegular && txrules.IsDustOutput(txOut, relayFeePerKb) {
+			return fmt.Errorf("output %d value of %d atoms is dust", i, txOut.Value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"bytes"
+	"testing"
+
+	"decred.org/dcrwallet/wallet/txrules"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/txscript/v3"
+	"github.com/decred/dcrd/wire"
+)
+
+func TestCheckTxStandard(t *testing.T) {
+	// A P2PKH output script.
+	pkScript := append([]byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20},
+		bytes.Repeat([]byte{0x01}, 20)...)
+	pkScript = append(pkScript, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+	nullData := []byte{txscript.OP_RETURN, txscript.OP_DATA_4, 1, 2, 3, 4}
+
+	newTx := func() *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		prevOut := wire.NewOutPoint(&chainhash.Hash{1}, 0, wire.TxTreeRegular)
+		tx.AddTxIn(wire.NewTxIn(prevOut, 1e8, []byte{txscript.OP_DATA_1, 1}))
+		tx.AddTxOut(wire.NewTxOut(5e7, pkScript))
+		return tx
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(tx *wire.MsgTx)
+		wantErr bool
+	}{
+		{"standard", func(tx *wire.MsgTx) {}, false},
+		{"null data", func(tx *wire.MsgTx) {
+			tx.AddTxOut(wire.NewTxOut(0, nullData))
+		}, false},
+		{"no inputs", func(tx *wire.MsgTx) { tx.TxIn = nil }, true},
+		{"no outputs", func(tx *wire.MsgTx) { tx.TxOut = nil }, true},
+		{"version", func(tx *wire.MsgTx) { tx.Version = 7 }, true},
+		{"duplicate input", func(tx *wire.MsgTx) {
+			tx.AddTxIn(wire.NewTxIn(&tx.TxIn[0].PreviousOutPoint, 1e8, nil))
+		}, true},
+		{"sig script size", func(tx *wire.MsgTx) {
+			tx.TxIn[0].SignatureScript = bytes.Repeat([]byte{txscript.OP_1}, MaxStandardSigScriptSize+1)
+		}, true},
+		{"sig script not push only", func(tx *wire.MsgTx) {
+			tx.TxIn[0].SignatureScript = []byte{txscript.OP_CHECKSIG}
+		}, true},
+		{"nonstandard output", func(tx *wire.MsgTx) {
+			tx.TxOut[0].PkScript = []byte{txscript.OP_CHECKSIG, txscript.OP_CHECKSIG}
+		}, true},
+		{"two null data outputs", func(tx *wire.MsgTx) {
+			tx.AddTxOut(wire.NewTxOut(0, nullData))
+			tx.AddTxOut(wire.NewTxOut(0, nullData))
+		}, true},
+		{"dust", func(tx *wire.MsgTx) { tx.TxOut[0].Value = 100 }, true},
+		{"output range", func(tx *wire.MsgTx) { tx.TxOut[0].Value = dcrutil.MaxAmount + 1 }, true},
+		{"size", func(tx *wire.MsgTx) {
+			for tx.SerializeSize() <= MaxStandardTxSize {
+				tx.AddTxOut(wire.NewTxOut(5e7, pkScript))
+			}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := newTx()
+			tt.modify(tx)
+			err := CheckTxStandard(tx, true, txrules.DefaultRelayFeePerKb)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckTxStandard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
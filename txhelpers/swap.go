@@ -20,6 +20,7 @@ type AtomicSwapContractPushes struct {
 	RecipientAddress  dcrutil.Address `json:"recipient_address"`
 	RefundAddress     dcrutil.Address `json:"refund_address"`
 	Locktime          int64           `json:"locktime"`
+	SecretHash        [32]byte        `json:"secret_hash"`
 	FormattedLocktime string          `json:"formatted_locktime"`
 }
 
@@ -33,9 +34,29 @@ type AtomicSwap struct {
 	RefundAddress     string  `json:"refund_address"`
 	Locktime          int64   `json:"locktime"`
 	FormattedLocktime string  `json:"formatted_locktime"`
+	SecretHash        string  `json:"secret_hash"`
 
 	RedemptionTxRef string `json:"redemption_txref"`
 	RedeemedBy      string `json:"redeemed_by"`
+	Secret          string `json:"secret,omitempty"`
+}
+
+// AtomicSwapSpend describes a transaction input that spends an atomic swap
+// contract output, either redeeming it with the secret or refunding it after
+// the contract's lock time.
+type AtomicSwapSpend struct {
+	Contract       *AtomicSwapContractPushes
+	ContractScript []byte
+	RedeemedBy     string
+	// Secret is the preimage of the contract's secret hash, revealed by the
+	// participant's redemption. Secret is nil for refunds.
+	Secret []byte
+}
+
+// IsRefund indicates if the contract was spent by the initiator, refunding the
+// contract value.
+func (s *AtomicSwapSpend) IsRefund() bool {
+	return s.RedeemedBy == AtomicSwapInitiator
 }
 
 // TxAtomicSwaps defines information about completed atomic swaps that are
@@ -68,13 +89,33 @@ func ExtractSwapDataFromInputScript(inputScriptHex string, params *chaincfg.Para
 		return nil, nil, "", fmt.Errorf("error decoding txin script: %v", err)
 	}
 
+	spend, err := ExtractSwapSpendFromInputScript(inputScript, params)
+	if spend == nil || err != nil {
+		return nil, nil, "", err
+	}
+
+	return spend.Contract, spend.ContractScript, spend.RedeemedBy, nil
+}
+
+// ExtractSwapSpendFromInputScript checks if the provided signature script
+// spends an atomic swap contract, and returns the contract details along with
+// the identity of the redeemer and, for a participant's redemption, the secret.
+// Returns (nil, nil) if the script does not spend a contract. Returns a non-nil
+// error if the script could not be parsed.
+func ExtractSwapSpendFromInputScript(inputScript []byte, params *chaincfg.Params) (*AtomicSwapSpend, error) {
 	var redeemerOpCode byte
-	var contract []byte
+	var contract, secret []byte
 
 	const scriptVersion = 0
 	tokenizer := txscript.MakeScriptTokenizer(scriptVersion, inputScript)
 	var tokenIndex = 0
 	for tokenizer.Next() {
+		// token at index 2 holds the secret for a redemption by the
+		// participant
+		if tokenIndex == 2 && tokenizer.Data() != nil {
+			secret = tokenizer.Data()
+		}
+
 		// token at index 2 or 3 should hold the redeemer opcode
 		// if there's no data at any of those indices
 		if (tokenIndex == 2 || tokenIndex == 3) && tokenizer.Data() == nil {
@@ -91,22 +132,22 @@ func ExtractSwapDataFromInputScript(inputScriptHex string, params *chaincfg.Para
 		tokenIndex++
 	}
 	if err := tokenizer.Err(); err != nil {
-		return nil, nil, "", fmt.Errorf("error parsing input script: %v", err)
+		return nil, fmt.Errorf("error parsing input script: %v", err)
 	}
 
 	if contract == nil || !tokenizer.Done() {
 		// script should contain contract as the last data
 		// if contract has been extracted, tokenizer.Done() should be true
-		return nil, nil, "", nil
+		return nil, nil
 	}
 
 	// validate the contract script by attempting to parse it for contract info.
 	contractData, err := ParseAtomicSwapContract(contract, params)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
 	if contractData == nil {
-		return nil, nil, "", nil // not a contract script
+		return nil, nil // not a contract script
 	}
 
 	swapRedeemer := AtomicSwapUnknownEntity
@@ -116,7 +157,16 @@ func ExtractSwapDataFromInputScript(inputScriptHex string, params *chaincfg.Para
 		swapRedeemer = AtomicSwapParticipant
 	}
 
-	return contractData, contract, swapRedeemer, nil
+	if swapRedeemer != AtomicSwapParticipant {
+		secret = nil
+	}
+
+	return &AtomicSwapSpend{
+		Contract:       contractData,
+		ContractScript: contract,
+		RedeemedBy:     swapRedeemer,
+		Secret:         secret,
+	}, nil
 }
 
 // ParseAtomicSwapContract checks if the provided script is an atomic swap
@@ -157,6 +207,7 @@ func ParseAtomicSwapContract(script []byte, params *chaincfg.Params) (*AtomicSwa
 		RecipientAddress:  recipientAddr,
 		RefundAddress:     refundAddr,
 		Locktime:          contractDataPushes.LockTime,
+		SecretHash:        contractDataPushes.SecretHash,
 		FormattedLocktime: formattedLockTime,
 	}, nil
 }
@@ -174,23 +225,31 @@ func CheckTxInputForSwapInfo(txraw *chainjson.TxRawResult, inputIndex uint32, pa
 		return nil, nil
 	}
 
-	contractData, contractScript, redeemer, err := ExtractSwapDataFromInputScript(input.ScriptSig.Hex, params)
-	if contractData == nil || err != nil {
+	inputScript, err := hex.DecodeString(input.ScriptSig.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding txin script: %v", err)
+	}
+
+	spend, err := ExtractSwapSpendFromInputScript(inputScript, params)
+	if spend == nil || err != nil {
 		return nil, err
 	}
+	contractData := spend.Contract
 
 	return &AtomicSwap{
 		ContractTxRef:     fmt.Sprintf("%s:%d", input.Txid, input.Vout),
-		Contract:          fmt.Sprintf("%x", contractScript),
+		Contract:          fmt.Sprintf("%x", spend.ContractScript),
 		ContractValue:     input.AmountIn,
 		ContractAddress:   contractData.ContractAddress.String(),
 		RecipientAddress:  contractData.RecipientAddress.String(),
 		RefundAddress:     contractData.RefundAddress.String(),
 		Locktime:          contractData.Locktime,
 		FormattedLocktime: contractData.FormattedLocktime,
+		SecretHash:        hex.EncodeToString(contractData.SecretHash[:]),
 
 		RedemptionTxRef: fmt.Sprintf("%s:%d", txraw.Txid, inputIndex),
-		RedeemedBy:      redeemer,
+		RedeemedBy:      spend.RedeemedBy,
+		Secret:          hex.EncodeToString(spend.Secret),
 	}, nil
 }
 
@@ -0,0 +1,99 @@
+package txhelpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v3"
+)
+
+func buildSwapContract(t *testing.T, secretHash []byte, lockTime int64) []byte {
+	t.Helper()
+	recipient := bytes.Repeat([]byte{0x01}, 20)
+	refund := bytes.Repeat([]byte{0x02}, 20)
+	contract, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_IF).
+		AddOp(txscript.OP_SIZE).AddInt64(32).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_SHA256).AddData(secretHash).AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(recipient).
+		AddOp(txscript.OP_ELSE).
+		AddInt64(lockTime).AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(refund).
+		AddOp(txscript.OP_ENDIF).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("failed to build contract: %v", err)
+	}
+	return contract
+}
+
+func TestExtractSwapSpendFromInputScript(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	secret := bytes.Repeat([]byte{0x42}, 32)
+	secretHash := sha256.Sum256(secret)
+	const lockTime = 1600000000
+	contract := buildSwapContract(t, secretHash[:], lockTime)
+
+	sig := bytes.Repeat([]byte{0x30}, 71)
+	pubKey := bytes.Repeat([]byte{0x03}, 33)
+
+	redeemScript, err := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).
+		AddData(secret).AddOp(txscript.OP_TRUE).AddData(contract).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundScript, err := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).
+		AddOp(txscript.OP_FALSE).AddData(contract).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2pkhScript, err := txscript.NewScriptBuilder().AddData(sig).AddData(pubKey).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		script     []byte
+		wantSwap   bool
+		wantRefund bool
+		wantSecret []byte
+	}{
+		{"redeem", redeemScript, true, false, secret},
+		{"refund", refundScript, true, true, nil},
+		{"not a swap", p2pkhScript, false, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spend, err := ExtractSwapSpendFromInputScript(tt.script, params)
+			if err != nil {
+				t.Fatalf("ExtractSwapSpendFromInputScript error: %v", err)
+			}
+			if (spend != nil) != tt.wantSwap {
+				t.Fatalf("expected swap %v, got %v", tt.wantSwap, spend != nil)
+			}
+			if spend == nil {
+				return
+			}
+			if spend.IsRefund() != tt.wantRefund {
+				t.Errorf("expected refund %v, got %v", tt.wantRefund, spend.IsRefund())
+			}
+			if !bytes.Equal(spend.Secret, tt.wantSecret) {
+				t.Errorf("expected secret %x, got %x", tt.wantSecret, spend.Secret)
+			}
+			if spend.Contract.SecretHash != secretHash {
+				t.Errorf("expected secret hash %x, got %x", secretHash, spend.Contract.SecretHash)
+			}
+			if spend.Contract.Locktime != lockTime {
+				t.Errorf("expected lock time %d, got %d", lockTime, spend.Contract.Locktime)
+			}
+			if !bytes.Equal(spend.ContractScript, contract) {
+				t.Errorf("contract script mismatch")
+			}
+		})
+	}
+}
@@ -217,12 +217,15 @@ func (t *TxWithBlockData) Confirmed() bool {
 
 // AddressOutpoints collects spendable and spent transactions outpoints paying
 // to a certain address. The transactions referenced by the outpoints are stored
-// for quick access.
+// for quick access. DoubleSpends maps the hashes of the address's unconfirmed
+// transactions that have a detected double spend to the hash of the competing
+// transaction.
 type AddressOutpoints struct {
-	Address   string
-	Outpoints []*wire.OutPoint
-	PrevOuts  []PrevOut
-	TxnsStore map[chainhash.Hash]*TxWithBlockData
+	Address      string
+	Outpoints    []*wire.OutPoint
+	PrevOuts     []PrevOut
+	TxnsStore    map[chainhash.Hash]*TxWithBlockData
+	DoubleSpends map[chainhash.Hash]chainhash.Hash
 }
 
 // NewAddressOutpoints creates a new AddressOutpoints, initializing the
@@ -0,0 +1,80 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/hdkeychain/v3"
+)
+
+// The branches of a BIP0044 account. Payments are received to addresses on
+// the external branch, and change is sent to addresses on the internal branch.
+const (
+	ExternalBranch uint32 = 0
+	InternalBranch uint32 = 1
+)
+
+// DerivedAddress is a P2PKH address derived from an account extended key at
+// the given branch and child index.
+type DerivedAddress struct {
+	Address string
+	Branch  uint32
+	Index   uint32
+}
+
+// ParseAccountXpub decodes an account-level extended public key for the given
+// network. Extended private keys are rejected.
+func ParseAccountXpub(xpub string, params *chaincfg.Params) (*hdkeychain.ExtendedKey, error) {
+	key, err := hdkeychain.NewKeyFromString(xpub, params)
+	if err != nil {
+		return nil, err
+	}
+	if key.IsPrivate() {
+		return nil, fmt.Errorf("extended key is private")
+	}
+	return key, nil
+}
+
+// BranchKey derives the extended public key of a branch of the account.
+func BranchKey(account *hdkeychain.ExtendedKey, branch uint32) (*hdkeychain.ExtendedKey, error) {
+	if branch != ExternalBranch && branch != InternalBranch {
+		return nil, fmt.Errorf("invalid branch %d", branch)
+	}
+	return account.Child(branch)
+}
+
+// DeriveAddresses derives the P2PKH addresses of count children of the branch
+// key starting at index start. As with wallets, indexes for which no valid
+// child key exists are skipped, so fewer than count addresses may be returned.
+func DeriveAddresses(branchKey *hdkeychain.ExtendedKey, branch, start, count uint32,
+	params *chaincfg.Params) ([]DerivedAddress, error) {
+	if start+count < start || start+count > hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("child index out of range")
+	}
+	addrs := make([]DerivedAddress, 0, count)
+	for i := start; i < start+count; i++ {
+		child, err := branchKey.Child(i)
+		if err == hdkeychain.ErrInvalidChild {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkHash := dcrutil.Hash160(child.SerializedPubKey())
+		addr, err := dcrutil.NewAddressPubKeyHash(pkHash, params, dcrec.STEcdsaSecp256k1)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, DerivedAddress{
+			Address: addr.Address(),
+			Branch:  branch,
+			Index:   i,
+		})
+	}
+	return addrs, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021, The Decred developers
+// See LICENSE for details.
+
+package txhelpers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/hdkeychain/v3"
+)
+
+// testAccountKey derives the private key of account 0 (m/44'/coin'/0') from a
+// fixed seed.
+func testAccountKey(t *testing.T, params *chaincfg.Params) *hdkeychain.ExtendedKey {
+	t.Helper()
+	seed := bytes.Repeat([]byte{0x5a}, hdkeychain.RecommendedSeedLen)
+	key, err := hdkeychain.NewMaster(seed, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []uint32{44, params.SLIP0044CoinType, 0} {
+		key, err = key.Child(hdkeychain.HardenedKeyStart + i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return key
+}
+
+func TestParseAccountXpub(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	acct := testAccountKey(t, params)
+
+	if _, err := ParseAccountXpub(acct.Neuter().String(), params); err != nil {
+		t.Errorf("failed to parse xpub: %v", err)
+	}
+	if _, err := ParseAccountXpub(acct.String(), params); err == nil {
+		t.Errorf("private key accepted")
+	}
+	if _, err := ParseAccountXpub(acct.Neuter().String(), chaincfg.TestNet3Params()); err == nil {
+		t.Errorf("mainnet key accepted for testnet")
+	}
+	if _, err := ParseAccountXpub("dpubnonsense", params); err == nil {
+		t.Errorf("invalid key accepted")
+	}
+}
+
+func TestDeriveAddresses(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	acct := testAccountKey(t, params)
+	xpub, err := ParseAccountXpub(acct.Neuter().String(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, branch := range []uint32{ExternalBranch, InternalBranch} {
+		branchKey, err := BranchKey(xpub, branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs, err := DeriveAddresses(branchKey, branch, 5, 3, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(addrs) != 3 {
+			t.Fatalf("got %d addresses, want 3", len(addrs))
+		}
+
+		// Addresses derived from the public key must match those of the
+		// private key at the same path.
+		privBranch, err := acct.Child(branch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, da := range addrs {
+			if da.Branch != branch || da.Index != uint32(5+j) {
+				t.Errorf("address %d has path %d/%d", j, da.Branch, da.Index)
+			}
+			child, err := privBranch.Child(da.Index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := dcrutil.NewAddressPubKeyHash(dcrutil.Hash160(child.SerializedPubKey()),
+				params, dcrec.STEcdsaSecp256k1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if da.Address != want.Address() {
+				t.Errorf("address %d/%d is %s, want %s", branch, da.Index, da.Address, want.Address())
+			}
+			if da.Address[:2] != "Ds" {
+				t.Errorf("address %s is not a mainnet P2PKH address", da.Address)
+			}
+		}
+	}
+
+	if _, err := BranchKey(xpub, 2); err == nil {
+		t.Errorf("invalid branch accepted")
+	}
+	branchKey, _ := BranchKey(xpub, ExternalBranch)
+	if _, err := DeriveAddresses(branchKey, ExternalBranch, hdkeychain.HardenedKeyStart-1, 2, params); err == nil {
+		t.Errorf("hardened child index accepted")
+	}
+}